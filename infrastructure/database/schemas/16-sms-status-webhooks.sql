@@ -0,0 +1,55 @@
+-- SMS Status Callback Webhooks Schema
+-- Date: 2026-10-18
+-- Purpose: Per-customer signed webhook delivery of message status transitions
+-- Used by: services/smpp-gateway/internal/webhook
+
+-- ============================================================================
+-- CUSTOMER WEBHOOKS - Status callback endpoint per customer
+-- ============================================================================
+-- customer_id is the customer UUID (messaging.customer_sms_auth.customer_id), the SMPP session CustomerID
+
+CREATE TABLE IF NOT EXISTS messaging.customer_webhooks (
+    customer_id VARCHAR(100) PRIMARY KEY,
+
+    url VARCHAR(500) NOT NULL,
+    secret VARCHAR(255) NOT NULL,  -- HMAC-SHA256 signing secret
+
+    is_active BOOLEAN DEFAULT TRUE,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE messaging.customer_webhooks IS 'Status callback webhook endpoint and signing secret per customer';
+COMMENT ON COLUMN messaging.customer_webhooks.secret IS 'Shared secret for X-Warp-Signature: t=<unix>,v1=<hex hmac-sha256(t + "." + body)>';
+
+-- ============================================================================
+-- WEBHOOK DEAD LETTERS - Callbacks that exhausted their retries
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS messaging.webhook_dead_letters (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    customer_id VARCHAR(100) NOT NULL,
+    message_id VARCHAR(100) NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    payload JSONB NOT NULL,
+
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    replayed_at TIMESTAMPTZ  -- Set when an admin replays the callback
+);
+
+CREATE INDEX idx_webhook_dead_letters_pending ON messaging.webhook_dead_letters(customer_id, created_at DESC) WHERE replayed_at IS NULL;
+CREATE INDEX idx_webhook_dead_letters_message ON messaging.webhook_dead_letters(message_id);
+
+COMMENT ON TABLE messaging.webhook_dead_letters IS 'Status callbacks that failed after all retries; replayable via the smpp-gateway admin API';
+
+-- ============================================================================
+-- GRANTS
+-- ============================================================================
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON messaging.customer_webhooks TO warp_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON messaging.webhook_dead_letters TO warp_app;
@@ -0,0 +1,76 @@
+-- Customer SMPP Bind Credentials Schema
+-- Date: 2026-10-18
+-- Purpose: Customer SMPP system_ids managed through the API gateway admin API
+-- Used by: services/api-gateway (writes), services/smpp-gateway (bind authentication,
+--          per-system_id bind limit and throughput)
+
+-- ============================================================================
+-- CUSTOMER SMS AUTH - SMPP system_id credentials per customer
+-- ============================================================================
+-- Migrates the account_id-keyed table created in schema/06_messaging.sql. A
+-- customer may hold several system_ids (e.g. separate binds for marketing and
+-- OTP traffic), so the one-row-per-account constraint is dropped. Legacy rows
+-- without a customer_id are kept but cannot bind until they are reassigned.
+
+ALTER TABLE messaging.customer_sms_auth
+    ADD COLUMN IF NOT EXISTS customer_id UUID REFERENCES accounts.customers(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS password_rotated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES auth.users(id);
+
+ALTER TABLE messaging.customer_sms_auth DROP CONSTRAINT IF EXISTS customer_sms_auth_account_id_key;
+ALTER TABLE messaging.customer_sms_auth ALTER COLUMN auth_type SET DEFAULT 'SMPP';
+
+-- Fill legacy NULLs before tightening the SMPP columns
+UPDATE messaging.customer_sms_auth SET smpp_allowed_ips = '{}' WHERE smpp_allowed_ips IS NULL;
+UPDATE messaging.customer_sms_auth SET smpp_max_binds = 2 WHERE smpp_max_binds IS NULL OR smpp_max_binds <= 0;
+UPDATE messaging.customer_sms_auth SET smpp_throughput = 100 WHERE smpp_throughput IS NULL OR smpp_throughput <= 0;
+UPDATE messaging.customer_sms_auth SET active = FALSE WHERE active IS NULL;
+UPDATE messaging.customer_sms_auth SET created_at = NOW() WHERE created_at IS NULL;
+
+ALTER TABLE messaging.customer_sms_auth
+    ALTER COLUMN smpp_allowed_ips SET DEFAULT '{}',
+    ALTER COLUMN smpp_allowed_ips SET NOT NULL,
+    ALTER COLUMN smpp_max_binds SET NOT NULL,
+    ALTER COLUMN smpp_throughput SET NOT NULL,
+    ALTER COLUMN active SET NOT NULL,
+    ALTER COLUMN created_at SET NOT NULL;
+
+ALTER TABLE messaging.customer_sms_auth DROP CONSTRAINT IF EXISTS customer_sms_auth_max_binds_check;
+ALTER TABLE messaging.customer_sms_auth ADD CONSTRAINT customer_sms_auth_max_binds_check
+    CHECK (smpp_max_binds > 0);
+ALTER TABLE messaging.customer_sms_auth DROP CONSTRAINT IF EXISTS customer_sms_auth_throughput_check;
+ALTER TABLE messaging.customer_sms_auth ADD CONSTRAINT customer_sms_auth_throughput_check
+    CHECK (smpp_throughput > 0);
+
+-- New credentials must be customer-owned SMPP binds (system_id max 15 chars per
+-- SMPP v3.4); NOT VALID leaves legacy account rows in place
+ALTER TABLE messaging.customer_sms_auth DROP CONSTRAINT IF EXISTS customer_sms_auth_smpp_bind_check;
+ALTER TABLE messaging.customer_sms_auth ADD CONSTRAINT customer_sms_auth_smpp_bind_check
+    CHECK (
+        customer_id IS NOT NULL
+        AND smpp_system_id IS NOT NULL
+        AND char_length(smpp_system_id) <= 15
+        AND smpp_password_hash IS NOT NULL
+    ) NOT VALID;
+
+CREATE INDEX IF NOT EXISTS idx_customer_sms_auth_customer ON messaging.customer_sms_auth(customer_id);
+CREATE INDEX IF NOT EXISTS idx_customer_sms_auth_active ON messaging.customer_sms_auth(smpp_system_id) WHERE active = TRUE;
+
+DROP TRIGGER IF EXISTS update_customer_sms_auth_timestamp ON messaging.customer_sms_auth;
+CREATE TRIGGER update_customer_sms_auth_timestamp
+    BEFORE UPDATE ON messaging.customer_sms_auth
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE messaging.customer_sms_auth IS 'Customer SMPP bind credentials, limits and source IP restrictions';
+COMMENT ON COLUMN messaging.customer_sms_auth.smpp_password_hash IS 'bcrypt hash; the plaintext is only returned once on create or rotate';
+COMMENT ON COLUMN messaging.customer_sms_auth.smpp_allowed_ips IS 'Allowed bind source IPs/CIDRs; empty array allows any source';
+COMMENT ON COLUMN messaging.customer_sms_auth.smpp_max_binds IS 'Concurrent binds allowed for this system_id across all gateway replicas';
+COMMENT ON COLUMN messaging.customer_sms_auth.smpp_throughput IS 'submit_sm per second allowed for this system_id';
+
+-- ============================================================================
+-- GRANTS
+-- ============================================================================
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON messaging.customer_sms_auth TO warp_app;
@@ -0,0 +1,96 @@
+-- Trunk SIP Digest Credentials Schema for WARP Platform
+-- Date: 2026-10-18
+-- Purpose: SIP digest (username/password) authentication for DIGEST and BOTH trunk groups
+-- Used by: services/api-gateway (writes, syncs to Redis), Kamailio auth_db (reads from Redis)
+
+-- =============================================================================
+-- TRUNK CREDENTIALS TABLE
+-- =============================================================================
+-- Each trunk group with auth_type DIGEST or BOTH may hold several credentials
+-- (e.g. one per PBX). Only HA1 hashes are stored; the password is returned
+-- once on create or rotate.
+
+CREATE TABLE IF NOT EXISTS accounts.trunk_credentials (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    trunk_group_id UUID NOT NULL REFERENCES accounts.trunk_groups(id) ON DELETE CASCADE,
+
+    -- SIP digest identity
+    username VARCHAR(64) NOT NULL,
+    realm VARCHAR(128) NOT NULL,
+    ha1 CHAR(32) NOT NULL,   -- MD5(username:realm:password)
+    ha1b CHAR(32) NOT NULL,  -- MD5(username@realm:realm:password)
+    description TEXT,
+
+    -- Status
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE'
+        CHECK (status IN ('ACTIVE', 'REVOKED')),
+    last_rotated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID REFERENCES auth.users(id)
+);
+
+-- A username can be reused once the credential holding it is revoked
+CREATE UNIQUE INDEX idx_trunk_credentials_username ON accounts.trunk_credentials(username, realm) WHERE status = 'ACTIVE';
+CREATE INDEX idx_trunk_credentials_trunk ON accounts.trunk_credentials(trunk_group_id);
+
+CREATE TRIGGER update_trunk_credentials_timestamp
+    BEFORE UPDATE ON accounts.trunk_credentials
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- =============================================================================
+-- ENFORCEMENT: IP_ACL-only trunks never hold active credentials
+-- =============================================================================
+
+CREATE OR REPLACE FUNCTION accounts.check_trunk_credential_auth_type()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status = 'ACTIVE' AND EXISTS (
+        SELECT 1 FROM accounts.trunk_groups
+        WHERE id = NEW.trunk_group_id AND auth_type = 'IP_ACL'
+    ) THEN
+        RAISE EXCEPTION 'trunk group % uses IP_ACL authentication and cannot hold digest credentials', NEW.trunk_group_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trunk_credential_auth_type_check
+    BEFORE INSERT OR UPDATE ON accounts.trunk_credentials
+    FOR EACH ROW
+    EXECUTE FUNCTION accounts.check_trunk_credential_auth_type();
+
+-- Switching a trunk to IP_ACL revokes its credentials
+CREATE OR REPLACE FUNCTION accounts.revoke_trunk_credentials_on_ip_acl()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.auth_type = 'IP_ACL' AND OLD.auth_type <> 'IP_ACL' THEN
+        UPDATE accounts.trunk_credentials
+        SET status = 'REVOKED', revoked_at = NOW()
+        WHERE trunk_group_id = NEW.id AND status = 'ACTIVE';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trunk_group_ip_acl_revoke_credentials
+    AFTER UPDATE OF auth_type ON accounts.trunk_groups
+    FOR EACH ROW
+    EXECUTE FUNCTION accounts.revoke_trunk_credentials_on_ip_acl();
+
+COMMENT ON TABLE accounts.trunk_credentials IS 'SIP digest credentials for DIGEST/BOTH trunk groups (synced to Redis for Kamailio auth_db)';
+COMMENT ON COLUMN accounts.trunk_credentials.ha1 IS 'MD5(username:realm:password); the plaintext is only returned once on create or rotate';
+COMMENT ON COLUMN accounts.trunk_credentials.ha1b IS 'MD5(username@realm:realm:password) for clients that send user@domain as the digest username';
+
+-- Redis key format: subscriber:entry:{username}:{realm}
+-- Hash fields: {username, domain, ha1, ha1b, password: "", tag: customer BAN, trunk_id}
+
+-- =============================================================================
+-- GRANTS
+-- =============================================================================
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON accounts.trunk_credentials TO warp_app;
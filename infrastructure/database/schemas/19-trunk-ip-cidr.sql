@@ -0,0 +1,87 @@
+-- Trunk IP ACL: IPv6, canonical CIDR, port/transport and overlap protection
+-- Date: 2026-10-18
+-- Purpose: Allow IPv6 prefixes, per-entry SIP port/transport, and prevent two
+--          customers from claiming overlapping ranges (breaks Kamailio tag attribution)
+-- Used by: services/api-gateway (writes, syncs to Redis), Kamailio permissions (reads from Redis)
+
+-- =============================================================================
+-- NETMASK: 0-32 for IPv4, 0-128 for IPv6
+-- =============================================================================
+
+ALTER TABLE accounts.trunk_ips DROP CONSTRAINT IF EXISTS trunk_ips_netmask_check;
+ALTER TABLE accounts.trunk_ips ADD CONSTRAINT trunk_ips_netmask_check CHECK (
+    netmask >= 0 AND netmask <= CASE WHEN family(ip_address) = 4 THEN 32 ELSE 128 END
+);
+
+-- Store the network address of each range (10.1.2.3/24 -> 10.1.2.0/24) with no
+-- mask on the INET itself; netmask holds the prefix length
+UPDATE accounts.trunk_ips
+SET ip_address = host(network(set_masklen(ip_address, netmask)))::inet
+WHERE ip_address <> host(network(set_masklen(ip_address, netmask)))::inet;
+
+ALTER TABLE accounts.trunk_ips DROP CONSTRAINT IF EXISTS trunk_ips_canonical_check;
+ALTER TABLE accounts.trunk_ips ADD CONSTRAINT trunk_ips_canonical_check CHECK (
+    ip_address = host(network(set_masklen(ip_address, netmask)))::inet
+);
+
+-- =============================================================================
+-- PORT / TRANSPORT
+-- =============================================================================
+-- Existing entries keep the previous hard-coded 5060/any behaviour
+
+ALTER TABLE accounts.trunk_ips
+    ADD COLUMN IF NOT EXISTS port INTEGER NOT NULL DEFAULT 5060 CHECK (port >= 0 AND port <= 65535),
+    ADD COLUMN IF NOT EXISTS transport VARCHAR(8) NOT NULL DEFAULT 'any'
+        CHECK (transport IN ('any', 'udp', 'tcp', 'tls', 'sctp'));
+
+COMMENT ON COLUMN accounts.trunk_ips.netmask IS 'CIDR prefix length (0-32 IPv4, 0-128 IPv6)';
+COMMENT ON COLUMN accounts.trunk_ips.port IS 'Source SIP port to allow (0 = any port)';
+COMMENT ON COLUMN accounts.trunk_ips.transport IS 'SIP transport to allow: any, udp, tcp, tls, sctp';
+
+-- =============================================================================
+-- ENFORCEMENT: no overlapping ranges across customers
+-- =============================================================================
+-- The API checks first to return a clear conflict error; this trigger closes
+-- the race between two concurrent inserts. Ranges within the same customer may
+-- overlap (they resolve to the same BAN tag).
+
+CREATE INDEX IF NOT EXISTS idx_trunk_ips_range ON accounts.trunk_ips
+    USING gist (set_masklen(ip_address, netmask) inet_ops);
+
+CREATE OR REPLACE FUNCTION accounts.check_trunk_ip_overlap()
+RETURNS TRIGGER AS $$
+DECLARE
+    new_customer UUID;
+    conflict TEXT;
+BEGIN
+    -- Serialize ACL writes so concurrent inserts see each other
+    PERFORM pg_advisory_xact_lock(hashtext('accounts.trunk_ips'));
+
+    SELECT customer_id INTO new_customer
+    FROM accounts.trunk_groups WHERE id = NEW.trunk_group_id;
+
+    SELECT set_masklen(ti.ip_address, ti.netmask)::text INTO conflict
+    FROM accounts.trunk_ips ti
+    JOIN accounts.trunk_groups tg ON tg.id = ti.trunk_group_id
+    WHERE ti.id <> NEW.id
+      AND tg.customer_id <> new_customer
+      AND set_masklen(ti.ip_address, ti.netmask) && set_masklen(NEW.ip_address, NEW.netmask)
+    LIMIT 1;
+
+    IF conflict IS NOT NULL THEN
+        RAISE EXCEPTION 'trunk IP range %/% overlaps % registered to another customer',
+            host(NEW.ip_address), NEW.netmask, conflict
+            USING ERRCODE = 'exclusion_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trunk_ip_overlap_check ON accounts.trunk_ips;
+CREATE TRIGGER trunk_ip_overlap_check
+    BEFORE INSERT OR UPDATE OF ip_address, netmask, trunk_group_id ON accounts.trunk_ips
+    FOR EACH ROW
+    EXECUTE FUNCTION accounts.check_trunk_ip_overlap();
+
+-- Redis key format: address:entry:{id}
+-- Hash fields: {grp: "100", ip_addr, mask, port (0 = any), proto (any/udp/tcp/tls/sctp), tag: customer BAN}
@@ -0,0 +1,91 @@
+-- Trunk Termination Targets Schema for WARP Platform
+-- Date: 2026-10-18
+-- Purpose: Where inbound calls for a customer's trunk are delivered (SIP URIs with
+--          priority/weight and failover), plus OPTIONS health check settings
+-- Used by: services/api-gateway (writes, publishes resolved routing to Redis), Kamailio (reads from Redis)
+
+-- =============================================================================
+-- TRUNK TARGETS TABLE
+-- =============================================================================
+-- Targets are tried in ascending priority; targets sharing a priority are
+-- load-balanced by weight. Numbers with trunk_id set inherit the trunk's targets.
+
+CREATE TABLE IF NOT EXISTS accounts.trunk_targets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    trunk_group_id UUID NOT NULL REFERENCES accounts.trunk_groups(id) ON DELETE CASCADE,
+
+    -- Destination
+    sip_uri VARCHAR(255) NOT NULL,          -- sip:pbx.example.com:5060 or sips:10.0.0.1
+    transport VARCHAR(8) NOT NULL DEFAULT 'udp'
+        CHECK (transport IN ('udp', 'tcp', 'tls')),
+    priority INTEGER NOT NULL DEFAULT 10 CHECK (priority >= 1 AND priority <= 100),
+    weight INTEGER NOT NULL DEFAULT 100 CHECK (weight >= 1 AND weight <= 1000),
+
+    -- Metadata
+    description VARCHAR(255),
+    enabled BOOLEAN DEFAULT true,
+
+    -- Timestamps
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID REFERENCES auth.users(id),
+
+    -- Constraints: No duplicate targets within same trunk group
+    UNIQUE(trunk_group_id, sip_uri, transport)
+);
+
+CREATE INDEX idx_trunk_targets_trunk_group ON accounts.trunk_targets(trunk_group_id) WHERE enabled = true;
+
+CREATE TRIGGER update_trunk_targets_timestamp
+    BEFORE UPDATE ON accounts.trunk_targets
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE accounts.trunk_targets IS 'SIP termination targets for inbound calls delivered to a customer trunk';
+COMMENT ON COLUMN accounts.trunk_targets.priority IS 'Lower is tried first (1-100)';
+COMMENT ON COLUMN accounts.trunk_targets.weight IS 'Relative share among targets with the same priority (1-1000)';
+
+-- =============================================================================
+-- TRUNK ROUTING SETTINGS TABLE
+-- =============================================================================
+-- One optional row per trunk group; a missing row means the defaults below
+
+CREATE TABLE IF NOT EXISTS accounts.trunk_routing_settings (
+    trunk_group_id UUID PRIMARY KEY REFERENCES accounts.trunk_groups(id) ON DELETE CASCADE,
+
+    -- Failover: try the next target on these final responses or on timeout
+    failover_response_codes INTEGER[] NOT NULL DEFAULT '{500,502,503,504}',
+    failover_on_timeout BOOLEAN NOT NULL DEFAULT true,
+    invite_timeout_seconds INTEGER NOT NULL DEFAULT 8
+        CHECK (invite_timeout_seconds >= 2 AND invite_timeout_seconds <= 60),
+
+    -- SIP OPTIONS health checks
+    options_enabled BOOLEAN NOT NULL DEFAULT true,
+    options_interval_seconds INTEGER NOT NULL DEFAULT 30
+        CHECK (options_interval_seconds >= 10 AND options_interval_seconds <= 300),
+    options_failure_threshold INTEGER NOT NULL DEFAULT 3
+        CHECK (options_failure_threshold >= 1 AND options_failure_threshold <= 10),
+    options_recovery_threshold INTEGER NOT NULL DEFAULT 2
+        CHECK (options_recovery_threshold >= 1 AND options_recovery_threshold <= 10),
+
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TRIGGER update_trunk_routing_settings_timestamp
+    BEFORE UPDATE ON accounts.trunk_routing_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE accounts.trunk_routing_settings IS 'Failover and OPTIONS health settings for trunk termination targets';
+COMMENT ON COLUMN accounts.trunk_routing_settings.options_failure_threshold IS 'Consecutive failed OPTIONS before a target is marked down';
+COMMENT ON COLUMN accounts.trunk_routing_settings.options_recovery_threshold IS 'Consecutive successful OPTIONS before a down target is used again';
+
+-- Redis key format: trunk:routing:{trunk_id}
+-- Value: JSON {trunk_id, customer_ban, targets: [{uri, transport, priority, weight}], failover, health, updated_at}
+
+-- =============================================================================
+-- GRANTS
+-- =============================================================================
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON accounts.trunk_targets TO warp_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON accounts.trunk_routing_settings TO warp_app;
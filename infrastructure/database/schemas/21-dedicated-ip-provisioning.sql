@@ -0,0 +1,46 @@
+-- Dedicated IP Provisioning Workflow for WARP Platform
+-- Date: 2026-10-18
+-- Purpose: Track provisioning of premium customer dedicated ingress IPs through
+--          PROVISIONING -> ACTIVE -> DEPROVISIONING -> DELETED (or FAILED)
+-- Used by: services/api-gateway (drives the state machine, writes customer:dedicated_ip:{ip} to Redis)
+
+-- The IP is only known once the cloud provider has allocated it
+ALTER TABLE accounts.customer_dedicated_ips ALTER COLUMN ip_address DROP NOT NULL;
+
+ALTER TABLE accounts.customer_dedicated_ips DROP CONSTRAINT IF EXISTS customer_dedicated_ips_status_check;
+ALTER TABLE accounts.customer_dedicated_ips ADD CONSTRAINT customer_dedicated_ips_status_check
+    CHECK (status IN ('PROVISIONING', 'ACTIVE', 'DEPROVISIONING', 'DELETED', 'FAILED'));
+
+-- An address may be reallocated by the provider after release, so uniqueness
+-- only applies to live rows
+ALTER TABLE accounts.customer_dedicated_ips DROP CONSTRAINT IF EXISTS customer_dedicated_ips_ip_address_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customer_dedicated_ips_live_ip
+    ON accounts.customer_dedicated_ips(ip_address)
+    WHERE status NOT IN ('DELETED', 'FAILED');
+
+-- One live dedicated IP per customer per region; deleted/failed rows are history
+ALTER TABLE accounts.customer_dedicated_ips DROP CONSTRAINT IF EXISTS customer_dedicated_ips_customer_id_region_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customer_dedicated_ips_live
+    ON accounts.customer_dedicated_ips(customer_id, region)
+    WHERE status NOT IN ('DELETED', 'FAILED');
+
+ALTER TABLE accounts.customer_dedicated_ips
+    ADD COLUMN IF NOT EXISTS provider VARCHAR(20) NOT NULL DEFAULT 'gcp',
+    ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_error TEXT,
+    ADD COLUMN IF NOT EXISTS requested_by UUID REFERENCES auth.users(id),
+    ADD COLUMN IF NOT EXISTS activated_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ DEFAULT NOW();
+
+CREATE TRIGGER update_customer_dedicated_ips_timestamp
+    BEFORE UPDATE ON accounts.customer_dedicated_ips
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON COLUMN accounts.customer_dedicated_ips.gcp_address_name IS 'Provider address resource name (reserved static IP)';
+COMMENT ON COLUMN accounts.customer_dedicated_ips.provider IS 'Cloud address provider: gcp, fake (development)';
+COMMENT ON COLUMN accounts.customer_dedicated_ips.attempts IS 'Provider calls made for the current state (reset on state change)';
+
+-- Redis key format: customer:dedicated_ip:{ip} -> customer BAN (set on ACTIVE, removed on DEPROVISIONING)
+
+GRANT SELECT, INSERT, UPDATE ON accounts.customer_dedicated_ips TO warp_app;
@@ -0,0 +1,64 @@
+-- Number Purchase Saga for WARP Platform
+-- Date: 2026-10-18
+-- Purpose: Durable intent records so a purchase never leaves SOA and
+--          numbers.assigned_numbers silently diverged
+-- Used by: services/api-gateway (NumberService.PurchaseNumbers and the purchase repair worker)
+--
+-- Saga per telephone number:
+--   PENDING       intent persisted, SOA not yet called
+--   SOA_ASSIGNED  SOA reports IN_USE for the customer, local row not yet written
+--   COMPLETED     local row written (assigned_number_id set)
+--   FAILED        SOA refused the assignment; nothing to undo
+--   COMPENSATED   local write failed and the number was released in SOA
+--   REPAIR        outcome unknown or compensation failed; retried by the repair worker
+--   REPAIR_FAILED repair gave up after max attempts; needs an operator
+
+CREATE TABLE IF NOT EXISTS numbers.purchase_intents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID NOT NULL REFERENCES accounts.customers(id) ON DELETE RESTRICT,
+    number VARCHAR(20) NOT NULL,         -- E.164 format
+
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'SOA_ASSIGNED', 'COMPLETED', 'FAILED', 'COMPENSATED', 'REPAIR', 'REPAIR_FAILED')),
+
+    -- Requested configuration, replayed if repair finds the number already local
+    voice_enabled BOOLEAN DEFAULT FALSE,
+    sms_enabled BOOLEAN DEFAULT FALSE,
+    trunk_id UUID,
+    campaign_id UUID,
+
+    soa_number_id VARCHAR(100),
+    assigned_number_id UUID REFERENCES numbers.assigned_numbers(id),
+
+    -- Repair tracking
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ,
+
+    created_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+-- At most one in-flight purchase per number
+CREATE UNIQUE INDEX IF NOT EXISTS idx_purchase_intents_inflight
+    ON numbers.purchase_intents(number)
+    WHERE status IN ('PENDING', 'SOA_ASSIGNED', 'REPAIR');
+
+CREATE INDEX IF NOT EXISTS idx_purchase_intents_repair
+    ON numbers.purchase_intents(status, next_attempt_at)
+    WHERE status IN ('PENDING', 'SOA_ASSIGNED', 'REPAIR', 'REPAIR_FAILED');
+CREATE INDEX IF NOT EXISTS idx_purchase_intents_customer
+    ON numbers.purchase_intents(customer_id, created_at DESC);
+
+DROP TRIGGER IF EXISTS trg_purchase_intents_updated_at ON numbers.purchase_intents;
+CREATE TRIGGER trg_purchase_intents_updated_at
+    BEFORE UPDATE ON numbers.purchase_intents
+    FOR EACH ROW
+    EXECUTE FUNCTION numbers.update_assigned_numbers_timestamp();
+
+COMMENT ON TABLE numbers.purchase_intents IS 'Per-number purchase saga state; repaired in the background so SOA and assigned_numbers converge';
+COMMENT ON COLUMN numbers.purchase_intents.next_attempt_at IS 'When the repair worker may next retry (REPAIR only)';
+
+GRANT SELECT, INSERT, UPDATE ON numbers.purchase_intents TO warp_app;
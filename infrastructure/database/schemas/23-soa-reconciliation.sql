@@ -0,0 +1,82 @@
+-- SOA <-> Local Number Inventory Reconciliation for WARP Platform
+-- Date: 2026-10-18
+-- Purpose: Run history and drift findings of the scheduled reconciler that pages
+--          our SPID's SOA inventory and compares it with numbers.assigned_numbers
+-- Used by: services/api-gateway (NumberService.ReconcileInventory)
+--
+-- Drift types:
+--   PORTED_OUT      number ported away in SOA, still active locally   (safe: released locally)
+--   NOT_IN_USE      SOA shows AVAILABLE/RESERVED/QUARANTINE, active locally
+--   OWNER_MISMATCH  SOA assigned to a different customer than the local row
+--   LRN_MISMATCH    SOA LRN is not one of our home LRNs
+--   MISSING_LOCAL   SOA IN_USE for a customer but no active local row
+--   MISSING_IN_SOA  active locally but absent from our SPID's SOA inventory
+-- Only PORTED_OUT is auto-fixed; everything else is flagged for review. A dry
+-- run records findings (PORTED_OUT flagged too) but changes no number.
+
+CREATE TABLE IF NOT EXISTS numbers.soa_reconcile_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    trigger VARCHAR(20) NOT NULL CHECK (trigger IN ('manual', 'periodic')),
+    status VARCHAR(20) NOT NULL DEFAULT 'RUNNING' CHECK (status IN ('RUNNING', 'COMPLETED', 'FAILED')),
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+
+    soa_numbers INTEGER NOT NULL DEFAULT 0,     -- SOA inventory entries scanned
+    local_numbers INTEGER NOT NULL DEFAULT 0,   -- Active local numbers
+    matched INTEGER NOT NULL DEFAULT 0,         -- In sync
+    auto_fixed INTEGER NOT NULL DEFAULT 0,
+    flagged INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    duration_ms BIGINT
+);
+
+ALTER TABLE numbers.soa_reconcile_runs ADD COLUMN IF NOT EXISTS dry_run BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_soa_reconcile_runs_started ON numbers.soa_reconcile_runs(started_at DESC);
+
+CREATE TABLE IF NOT EXISTS numbers.soa_drift (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    number VARCHAR(20) NOT NULL,
+    drift_type VARCHAR(20) NOT NULL
+        CHECK (drift_type IN ('PORTED_OUT', 'NOT_IN_USE', 'OWNER_MISMATCH', 'LRN_MISMATCH', 'MISSING_LOCAL', 'MISSING_IN_SOA')),
+    action VARCHAR(20) NOT NULL CHECK (action IN ('AUTO_FIXED', 'FLAGGED')),
+
+    -- Local side (NULL for MISSING_LOCAL)
+    assigned_number_id UUID REFERENCES numbers.assigned_numbers(id),
+    customer_id UUID REFERENCES accounts.customers(id),
+
+    -- SOA side (NULL for MISSING_IN_SOA)
+    soa_status VARCHAR(20),
+    soa_application_id VARCHAR(100),
+    soa_lrn VARCHAR(10),
+    soa_port_direction VARCHAR(10),
+    detail TEXT,
+
+    first_seen_run_id UUID NOT NULL REFERENCES numbers.soa_reconcile_runs(id),
+    last_seen_run_id UUID NOT NULL REFERENCES numbers.soa_reconcile_runs(id),
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    occurrences INTEGER NOT NULL DEFAULT 1,
+
+    -- Resolution: AUTO_FIXED (by the reconciler), CLEARED (no longer seen), ACKNOWLEDGED (operator)
+    resolved_at TIMESTAMPTZ,
+    resolution VARCHAR(20) CHECK (resolution IN ('AUTO_FIXED', 'CLEARED', 'ACKNOWLEDGED')),
+    resolved_by UUID REFERENCES auth.users(id),
+    resolution_note TEXT
+);
+
+-- One open finding per number and drift type; repeated runs bump occurrences
+CREATE UNIQUE INDEX IF NOT EXISTS idx_soa_drift_open
+    ON numbers.soa_drift(number, drift_type)
+    WHERE resolved_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_soa_drift_last_seen ON numbers.soa_drift(last_seen_at DESC);
+CREATE INDEX IF NOT EXISTS idx_soa_drift_customer ON numbers.soa_drift(customer_id) WHERE customer_id IS NOT NULL;
+
+COMMENT ON TABLE numbers.soa_reconcile_runs IS 'History of SOA <-> assigned_numbers reconciliation runs';
+COMMENT ON TABLE numbers.soa_drift IS 'Drift findings between SOA inventory and assigned_numbers (open rows need review)';
+
+GRANT SELECT, INSERT, UPDATE ON numbers.soa_reconcile_runs TO warp_app;
+GRANT SELECT, INSERT, UPDATE ON numbers.soa_drift TO warp_app;
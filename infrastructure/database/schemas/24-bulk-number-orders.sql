@@ -0,0 +1,84 @@
+-- Bulk Number Orders for WARP Platform
+-- Date: 2026-10-18
+-- Purpose: Asynchronous bulk ordering so large orders are not bound by the
+--          HTTP request (serial SOA calls with retry/backoff exceed WriteTimeout)
+-- Used by: services/api-gateway (NumberService bulk order dispatcher)
+--
+-- Order lifecycle: QUEUED -> RUNNING -> COMPLETED | PARTIAL | FAILED | CANCELLED
+-- Item lifecycle:  PENDING -> RESERVED -> PURCHASED
+--                  PENDING -> SKIPPED    (search candidate already taken)
+--                  * -> FAILED | CANCELLED | RELEASED (rolled back, accept_partial = false)
+--
+-- Orders are claimed with FOR UPDATE SKIP LOCKED and kept alive by heartbeat_at,
+-- so any api-gateway replica can pick up an order whose worker died.
+
+CREATE TABLE IF NOT EXISTS numbers.bulk_orders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID NOT NULL REFERENCES accounts.customers(id) ON DELETE RESTRICT,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'QUEUED'
+        CHECK (status IN ('QUEUED', 'RUNNING', 'COMPLETED', 'PARTIAL', 'FAILED', 'CANCELLED')),
+
+    -- Selection: explicit TNs (items created on submit) or search criteria + quantity
+    search_criteria JSONB,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    accept_partial BOOLEAN NOT NULL DEFAULT TRUE,
+
+    -- Features applied to every purchased number
+    voice_enabled BOOLEAN DEFAULT FALSE,
+    sms_enabled BOOLEAN DEFAULT FALSE,
+    trunk_id UUID,
+    campaign_id UUID,
+
+    cancel_requested BOOLEAN NOT NULL DEFAULT FALSE,
+    error TEXT,
+
+    created_by UUID,
+    reserved_by VARCHAR(255),            -- SOA reservation owner (user email)
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    heartbeat_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_bulk_orders_customer ON numbers.bulk_orders(customer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_bulk_orders_claim ON numbers.bulk_orders(status, created_at)
+    WHERE status IN ('QUEUED', 'RUNNING');
+
+DROP TRIGGER IF EXISTS trg_bulk_orders_updated_at ON numbers.bulk_orders;
+CREATE TRIGGER trg_bulk_orders_updated_at
+    BEFORE UPDATE ON numbers.bulk_orders
+    FOR EACH ROW
+    EXECUTE FUNCTION numbers.update_assigned_numbers_timestamp();
+
+CREATE TABLE IF NOT EXISTS numbers.bulk_order_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES numbers.bulk_orders(id) ON DELETE CASCADE,
+    number VARCHAR(20) NOT NULL,         -- E.164 format
+
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'RESERVED', 'PURCHASED', 'SKIPPED', 'FAILED', 'CANCELLED', 'RELEASED')),
+    assigned_number_id UUID REFERENCES numbers.assigned_numbers(id),
+    error TEXT,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE (order_id, number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bulk_order_items_order ON numbers.bulk_order_items(order_id, status);
+
+DROP TRIGGER IF EXISTS trg_bulk_order_items_updated_at ON numbers.bulk_order_items;
+CREATE TRIGGER trg_bulk_order_items_updated_at
+    BEFORE UPDATE ON numbers.bulk_order_items
+    FOR EACH ROW
+    EXECUTE FUNCTION numbers.update_assigned_numbers_timestamp();
+
+COMMENT ON TABLE numbers.bulk_orders IS 'Asynchronous bulk number orders (search + quantity or explicit TNs)';
+COMMENT ON TABLE numbers.bulk_order_items IS 'Per-TN progress of a bulk number order';
+COMMENT ON COLUMN numbers.bulk_orders.accept_partial IS 'FALSE = all-or-nothing: a short order is rolled back and FAILED';
+
+GRANT SELECT, INSERT, UPDATE ON numbers.bulk_orders TO warp_app;
+GRANT SELECT, INSERT, UPDATE ON numbers.bulk_order_items TO warp_app;
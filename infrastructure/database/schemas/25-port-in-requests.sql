@@ -0,0 +1,174 @@
+-- Port-In Requests for WARP Platform
+-- Date: 2026-10-18
+-- Purpose: Bring numbers.port_requests / port_request_numbers (legacy schema/03_numbers.sql)
+--          onto customer_id and a port-in state machine, and add LOA/CSR document attachments
+-- Used by: services/api-gateway (NumberService port-in workflow)
+--
+-- Status lifecycle:
+--   DRAFT -> SUBMITTED -> FOC_RECEIVED -> ACTIVATED
+--   SUBMITTED | FOC_RECEIVED -> REJECTED -> SUBMITTED (corrected and resubmitted)
+--   DRAFT | SUBMITTED | FOC_RECEIVED | REJECTED -> CANCELLED
+--
+-- Activation creates numbers.assigned_numbers rows (with the request's trunk
+-- routing) and links them from port_request_numbers.assigned_number_id.
+
+-- ============================================================================
+-- numbers.port_requests
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS numbers.port_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID REFERENCES accounts.customers(id),
+
+    port_type VARCHAR(20) NOT NULL DEFAULT 'PORT_IN',
+    requested_foc_date DATE,
+    actual_foc_date DATE,
+
+    losing_carrier VARCHAR(100),
+    losing_carrier_ocn VARCHAR(4),
+    winning_carrier VARCHAR(100),
+    winning_carrier_ocn VARCHAR(4),
+
+    authorized_person VARCHAR(255) NOT NULL,
+    authorized_person_title VARCHAR(100),
+
+    status VARCHAR(20) DEFAULT 'DRAFT',
+    lsr_id VARCHAR(100),
+    pon VARCHAR(100),
+
+    rejection_reason TEXT,
+    rejection_count INTEGER DEFAULT 0,
+
+    notes TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    submitted_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ
+);
+
+-- Legacy installs reference the deprecated accounts.accounts; ownership is customer_id now
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'numbers' AND table_name = 'port_requests' AND column_name = 'account_id'
+    ) THEN
+        ALTER TABLE numbers.port_requests ALTER COLUMN account_id DROP NOT NULL;
+    END IF;
+END $$;
+
+-- Legacy installs use the port_status enum (PENDING ... COMPLETED); move to the new states
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'numbers' AND table_name = 'port_requests'
+        AND column_name = 'status' AND data_type = 'USER-DEFINED'
+    ) THEN
+        ALTER TABLE numbers.port_requests ALTER COLUMN status DROP DEFAULT;
+        ALTER TABLE numbers.port_requests ALTER COLUMN status TYPE VARCHAR(20)
+            USING CASE status::text
+                WHEN 'PENDING' THEN 'DRAFT'
+                WHEN 'COMPLETED' THEN 'ACTIVATED'
+                ELSE status::text
+            END;
+        ALTER TABLE numbers.port_requests ALTER COLUMN status SET DEFAULT 'DRAFT';
+    END IF;
+END $$;
+
+ALTER TABLE numbers.port_requests DROP CONSTRAINT IF EXISTS port_requests_status_check;
+ALTER TABLE numbers.port_requests ADD CONSTRAINT port_requests_status_check
+    CHECK (status IN ('DRAFT', 'SUBMITTED', 'FOC_RECEIVED', 'ACTIVATED', 'REJECTED', 'CANCELLED'));
+
+-- End user and routing applied to the numbers on activation
+ALTER TABLE numbers.port_requests
+    ADD COLUMN IF NOT EXISTS end_user_name VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS service_address JSONB,
+    ADD COLUMN IF NOT EXISTS trunk_id UUID,
+    ADD COLUMN IF NOT EXISTS voice_enabled BOOLEAN DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS sms_enabled BOOLEAN DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS foc_received_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS created_by UUID,
+    ADD COLUMN IF NOT EXISTS updated_by UUID;
+
+CREATE INDEX IF NOT EXISTS idx_port_requests_customer ON numbers.port_requests(customer_id);
+CREATE INDEX IF NOT EXISTS idx_port_requests_status ON numbers.port_requests(status);
+
+DROP TRIGGER IF EXISTS update_port_requests_updated_at ON numbers.port_requests;
+DROP TRIGGER IF EXISTS trg_port_requests_updated_at ON numbers.port_requests;
+CREATE TRIGGER trg_port_requests_updated_at
+    BEFORE UPDATE ON numbers.port_requests
+    FOR EACH ROW
+    EXECUTE FUNCTION numbers.update_assigned_numbers_timestamp();
+
+-- ============================================================================
+-- numbers.port_request_numbers
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS numbers.port_request_numbers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    port_request_id UUID NOT NULL REFERENCES numbers.port_requests(id) ON DELETE CASCADE,
+    number VARCHAR(20) NOT NULL,
+
+    is_btn BOOLEAN DEFAULT FALSE,
+    btn VARCHAR(20),
+
+    current_provider VARCHAR(100),
+    account_number VARCHAR(100),
+    pin_passcode VARCHAR(50),
+
+    ported_successfully BOOLEAN,
+    error_message TEXT,
+
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- The losing carrier PIN is stored sealed (AES-256-GCM, base64) by the API gateway
+ALTER TABLE numbers.port_request_numbers ALTER COLUMN pin_passcode TYPE TEXT;
+
+-- Portability pre-check results (SOA/LRN) and the number created on activation
+ALTER TABLE numbers.port_request_numbers
+    ADD COLUMN IF NOT EXISTS portability VARCHAR(20)
+        CHECK (portability IN ('PORTABLE', 'NOT_PORTABLE', 'UNVERIFIED')),
+    ADD COLUMN IF NOT EXISTS portability_reason TEXT,
+    ADD COLUMN IF NOT EXISTS current_spid VARCHAR(10),
+    ADD COLUMN IF NOT EXISTS current_lrn VARCHAR(10),
+    ADD COLUMN IF NOT EXISTS rate_center VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS state VARCHAR(2),
+    ADD COLUMN IF NOT EXISTS checked_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS assigned_number_id UUID REFERENCES numbers.assigned_numbers(id),
+    ADD COLUMN IF NOT EXISTS activated_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_port_request_numbers_port_id ON numbers.port_request_numbers(port_request_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_port_request_numbers_unique
+    ON numbers.port_request_numbers(port_request_id, number);
+CREATE INDEX IF NOT EXISTS idx_port_request_numbers_number ON numbers.port_request_numbers(number);
+
+-- ============================================================================
+-- numbers.port_request_documents - LOA, CSR and bill copies
+-- ============================================================================
+-- Files live in object storage; this table records what was attached
+
+CREATE TABLE IF NOT EXISTS numbers.port_request_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    port_request_id UUID NOT NULL REFERENCES numbers.port_requests(id) ON DELETE CASCADE,
+
+    document_type VARCHAR(10) NOT NULL CHECK (document_type IN ('LOA', 'CSR', 'BILL', 'OTHER')),
+    file_name VARCHAR(255) NOT NULL,
+    file_url TEXT NOT NULL,
+    content_type VARCHAR(100),
+    size_bytes BIGINT,
+
+    uploaded_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_port_request_documents_request ON numbers.port_request_documents(port_request_id);
+
+COMMENT ON TABLE numbers.port_request_documents IS 'Documents (LOA, CSR, bill copy) attached to a port request';
+COMMENT ON COLUMN numbers.port_request_numbers.pin_passcode IS 'Losing carrier account PIN, sealed with PORT_PIN_KEY; never returned by the API';
+COMMENT ON COLUMN numbers.port_request_numbers.portability IS 'Pre-check result: PORTABLE, NOT_PORTABLE, or UNVERIFIED (not known to SOA/LRN)';
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON numbers.port_requests TO warp_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON numbers.port_request_numbers TO warp_app;
+GRANT SELECT, INSERT, DELETE ON numbers.port_request_documents TO warp_app;
@@ -0,0 +1,100 @@
+-- E911 Address Management for WARP Platform
+-- Date: 2026-10-18
+-- Purpose: Customer-owned E911 addresses validated through an E911 provider,
+--          and per-number provisioning status
+-- Used by: services/api-gateway (NumberService E911 management)
+--
+-- Address lifecycle: PENDING -> VALIDATED | INVALID (re-validated on every edit)
+-- Number provisioning: PROVISIONED | FAILED | DEPROVISIONED (numbers.e911_provisioning)
+--
+-- assigned_numbers.e911_enabled / e911_address_id mirror the PROVISIONED row and
+-- are only written by the provisioning flow.
+
+-- ============================================================================
+-- numbers.e911_addresses
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS numbers.e911_addresses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID REFERENCES accounts.customers(id) ON DELETE RESTRICT,
+
+    name VARCHAR(255) NOT NULL,
+    address_line1 VARCHAR(255) NOT NULL,
+    address_line2 VARCHAR(255),
+    city VARCHAR(100) NOT NULL,
+    state VARCHAR(2) NOT NULL,
+    postal_code VARCHAR(10) NOT NULL,
+    country VARCHAR(2) DEFAULT 'US',
+
+    location_info VARCHAR(255),
+
+    validated BOOLEAN DEFAULT FALSE,
+    validation_date TIMESTAMPTZ,
+    msag_valid BOOLEAN,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Legacy installs (schema/03_numbers.sql) reference the deprecated accounts.accounts
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'numbers' AND table_name = 'e911_addresses' AND column_name = 'account_id'
+    ) THEN
+        ALTER TABLE numbers.e911_addresses ALTER COLUMN account_id DROP NOT NULL;
+    END IF;
+END $$;
+
+ALTER TABLE numbers.e911_addresses
+    ADD COLUMN IF NOT EXISTS customer_id UUID REFERENCES accounts.customers(id) ON DELETE RESTRICT,
+    ADD COLUMN IF NOT EXISTS validation_status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (validation_status IN ('PENDING', 'VALIDATED', 'INVALID')),
+    ADD COLUMN IF NOT EXISTS validation_message TEXT,
+    ADD COLUMN IF NOT EXISTS provider VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS provider_address_id VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS created_by UUID,
+    ADD COLUMN IF NOT EXISTS updated_by UUID;
+
+CREATE INDEX IF NOT EXISTS idx_e911_addresses_customer ON numbers.e911_addresses(customer_id);
+
+DROP TRIGGER IF EXISTS update_e911_addresses_updated_at ON numbers.e911_addresses;
+DROP TRIGGER IF EXISTS trg_e911_addresses_updated_at ON numbers.e911_addresses;
+CREATE TRIGGER trg_e911_addresses_updated_at
+    BEFORE UPDATE ON numbers.e911_addresses
+    FOR EACH ROW
+    EXECUTE FUNCTION numbers.update_assigned_numbers_timestamp();
+
+-- ============================================================================
+-- numbers.e911_provisioning - provider status per assigned number
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS numbers.e911_provisioning (
+    number_id UUID PRIMARY KEY REFERENCES numbers.assigned_numbers(id) ON DELETE CASCADE,
+    address_id UUID REFERENCES numbers.e911_addresses(id),
+
+    status VARCHAR(20) NOT NULL
+        CHECK (status IN ('PROVISIONED', 'FAILED', 'DEPROVISIONED')),
+    provider VARCHAR(50) NOT NULL,
+    error TEXT,
+
+    provisioned_at TIMESTAMPTZ,
+    updated_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_e911_provisioning_address ON numbers.e911_provisioning(address_id);
+
+DROP TRIGGER IF EXISTS trg_e911_provisioning_updated_at ON numbers.e911_provisioning;
+CREATE TRIGGER trg_e911_provisioning_updated_at
+    BEFORE UPDATE ON numbers.e911_provisioning
+    FOR EACH ROW
+    EXECUTE FUNCTION numbers.update_assigned_numbers_timestamp();
+
+COMMENT ON TABLE numbers.e911_provisioning IS 'E911 provider provisioning status per assigned number';
+COMMENT ON COLUMN numbers.e911_addresses.provider_address_id IS 'Address reference at the E911 provider, set once VALIDATED';
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON numbers.e911_addresses TO warp_app;
+GRANT SELECT, INSERT, UPDATE ON numbers.e911_provisioning TO warp_app;
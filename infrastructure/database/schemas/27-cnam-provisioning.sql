@@ -0,0 +1,49 @@
+-- CNAM Provisioning for WARP Platform
+-- Date: 2026-10-18
+-- Purpose: Per-number status of pushing outbound caller ID names to the
+--          CNAM/LIDB provider
+-- Used by: services/api-gateway (NumberService CNAM updates and the CNAM worker)
+--
+-- assigned_numbers.cnam_enabled / cnam_display_name hold the desired state.
+-- Each change queues one row here, which the CNAM worker pushes to the provider:
+--   PENDING      queued or waiting for a retry (next_attempt_at)
+--   PROVISIONED  display name set at the provider (action SET)
+--   REMOVED      display name removed at the provider (action REMOVE)
+--   FAILED       retries exhausted; the next change or a bulk update requeues it
+
+CREATE TABLE IF NOT EXISTS numbers.cnam_provisioning (
+    number_id UUID PRIMARY KEY REFERENCES numbers.assigned_numbers(id) ON DELETE CASCADE,
+
+    action VARCHAR(10) NOT NULL CHECK (action IN ('SET', 'REMOVE')),
+    display_name VARCHAR(15),            -- NULL for REMOVE
+
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'PROVISIONED', 'REMOVED', 'FAILED')),
+    provider VARCHAR(50),
+
+    -- Retry tracking
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ DEFAULT NOW(),
+
+    provisioned_at TIMESTAMPTZ,
+    updated_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    CONSTRAINT cnam_set_has_name CHECK (action = 'REMOVE' OR display_name IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cnam_provisioning_due
+    ON numbers.cnam_provisioning(next_attempt_at)
+    WHERE status = 'PENDING';
+
+DROP TRIGGER IF EXISTS trg_cnam_provisioning_updated_at ON numbers.cnam_provisioning;
+CREATE TRIGGER trg_cnam_provisioning_updated_at
+    BEFORE UPDATE ON numbers.cnam_provisioning
+    FOR EACH ROW
+    EXECUTE FUNCTION numbers.update_assigned_numbers_timestamp();
+
+COMMENT ON TABLE numbers.cnam_provisioning IS 'CNAM/LIDB provisioning status per assigned number';
+
+GRANT SELECT, INSERT, UPDATE ON numbers.cnam_provisioning TO warp_app;
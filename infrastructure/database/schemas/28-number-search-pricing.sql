@@ -0,0 +1,77 @@
+-- Number Search Cache and Pricing for WARP Platform
+-- Date: 2026-10-18
+-- Purpose: Short-TTL cache of SOA availability searches, and the list prices
+--          shown on search results
+-- Used by: services/api-gateway (NumberService.SearchAvailableNumbers)
+--
+-- numbers.search_cache rows are keyed by search type (QUERY, BLOCK) and a
+-- SHA-256 of the normalized criteria; results are stored unpriced so rate
+-- changes show up immediately.
+--
+-- billing.number_rates: rates without a rate plan are list prices. The most
+-- specific match for a number applies (rate center, then state, then type).
+
+-- ============================================================================
+-- numbers.search_cache
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS numbers.search_cache (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    search_type VARCHAR(50) NOT NULL,    -- QUERY, BLOCK
+    search_value VARCHAR(100) NOT NULL,  -- SHA-256 of the normalized criteria
+
+    available_count INTEGER DEFAULT 0,
+    numbers JSONB DEFAULT '[]',
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    expires_at TIMESTAMPTZ DEFAULT (NOW() + INTERVAL '1 minute'),
+
+    UNIQUE(search_type, search_value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_cache_expires ON numbers.search_cache(expires_at);
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON numbers.search_cache TO warp_app;
+
+-- ============================================================================
+-- billing.number_rates
+-- ============================================================================
+
+CREATE SCHEMA IF NOT EXISTS billing;
+
+-- Legacy installs (schema/07_billing.sql) already have this table with a
+-- number_type enum and a required rate plan
+CREATE TABLE IF NOT EXISTS billing.number_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rate_plan_id UUID,                   -- NULL = list price
+
+    number_type VARCHAR(20) NOT NULL,    -- DID, TOLL_FREE
+    country_code VARCHAR(3) DEFAULT 'USA',
+    state VARCHAR(2),
+    rate_center VARCHAR(100),
+
+    monthly_rate DECIMAL(10,2) NOT NULL,
+    setup_fee DECIMAL(10,2) DEFAULT 0.00,
+    port_in_fee DECIMAL(10,2) DEFAULT 0.00,
+    includes_channels INTEGER,
+
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+ALTER TABLE billing.number_rates ALTER COLUMN rate_plan_id DROP NOT NULL;
+
+-- List prices matching the standard-wholesale plan seed
+INSERT INTO billing.number_rates (number_type, country_code, monthly_rate, setup_fee, port_in_fee)
+SELECT 'DID', 'USA', 1.00, 0.00, 5.00
+WHERE NOT EXISTS (
+    SELECT 1 FROM billing.number_rates WHERE rate_plan_id IS NULL AND number_type::text = 'DID'
+);
+
+INSERT INTO billing.number_rates (number_type, country_code, monthly_rate, setup_fee, port_in_fee)
+SELECT 'TOLL_FREE', 'USA', 2.00, 0.00, 0.00
+WHERE NOT EXISTS (
+    SELECT 1 FROM billing.number_rates WHERE rate_plan_id IS NULL AND number_type::text = 'TOLL_FREE'
+);
+
+GRANT USAGE ON SCHEMA billing TO warp_app;
+GRANT SELECT ON billing.number_rates TO warp_app;
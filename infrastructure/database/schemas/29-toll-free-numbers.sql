@@ -0,0 +1,93 @@
+-- Toll-Free Numbers for WARP Platform
+-- Date: 2026-10-18
+-- Purpose: Toll-free numbers reserved and provisioned through the SMS/800
+--          registry under our RespOrg, and their messaging verification
+-- Used by: services/api-gateway (NumberService toll-free flow)
+--
+-- Lifecycle: RESERVED -> ACTIVE -> RELEASED, or RESERVED -> EXPIRED when the
+-- registry hold lapses. An ACTIVE record links its numbers.assigned_numbers row
+-- (number_type TOLL_FREE); releasing the number releases the record.
+--
+-- Messaging verification: NOT_SUBMITTED -> PENDING -> VERIFIED | REJECTED
+-- (a REJECTED number may be resubmitted).
+
+-- ============================================================================
+-- numbers.toll_free
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS numbers.toll_free (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID REFERENCES accounts.customers(id) ON DELETE RESTRICT,
+    number VARCHAR(20) UNIQUE NOT NULL,  -- E.164
+
+    -- RespOrg information
+    resp_org_id VARCHAR(10),             -- Controlling RespOrg ID
+    resp_org_change_date DATE,
+
+    -- Vanity information
+    vanity_pattern VARCHAR(20),
+    is_vanity BOOLEAN DEFAULT FALSE,
+
+    -- Registry status (SPARE, RESERVED, WORKING, ...)
+    somos_status VARCHAR(50),
+    somos_last_updated TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Legacy installs (schema/03_numbers.sql) reference the deprecated
+-- accounts.accounts and numbers.inventory
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = 'numbers' AND table_name = 'toll_free' AND column_name = 'account_id'
+    ) THEN
+        ALTER TABLE numbers.toll_free ALTER COLUMN account_id DROP NOT NULL;
+    END IF;
+END $$;
+
+ALTER TABLE numbers.toll_free DROP CONSTRAINT IF EXISTS toll_free_number_fkey;
+ALTER TABLE numbers.toll_free ALTER COLUMN resp_org_id DROP NOT NULL;
+
+ALTER TABLE numbers.toll_free
+    ADD COLUMN IF NOT EXISTS customer_id UUID REFERENCES accounts.customers(id) ON DELETE RESTRICT,
+    ADD COLUMN IF NOT EXISTS number_id UUID REFERENCES numbers.assigned_numbers(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'RESERVED'
+        CHECK (status IN ('RESERVED', 'ACTIVE', 'RELEASED', 'EXPIRED')),
+    ADD COLUMN IF NOT EXISTS provider VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS reserved_until TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS last_error TEXT,
+
+    -- Toll-free messaging verification
+    ADD COLUMN IF NOT EXISTS messaging_verification_status VARCHAR(20) NOT NULL DEFAULT 'NOT_SUBMITTED'
+        CHECK (messaging_verification_status IN ('NOT_SUBMITTED', 'PENDING', 'VERIFIED', 'REJECTED')),
+    ADD COLUMN IF NOT EXISTS messaging_verification_id VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS messaging_verification JSONB,  -- Submitted business information
+    ADD COLUMN IF NOT EXISTS messaging_rejection_reason TEXT,
+    ADD COLUMN IF NOT EXISTS messaging_submitted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS messaging_updated_at TIMESTAMPTZ,
+
+    ADD COLUMN IF NOT EXISTS created_by UUID,
+    ADD COLUMN IF NOT EXISTS updated_by UUID;
+
+CREATE INDEX IF NOT EXISTS idx_toll_free_customer ON numbers.toll_free(customer_id, status);
+CREATE INDEX IF NOT EXISTS idx_toll_free_number_id ON numbers.toll_free(number_id);
+CREATE INDEX IF NOT EXISTS idx_toll_free_reserved_until ON numbers.toll_free(reserved_until)
+    WHERE status = 'RESERVED';
+CREATE INDEX IF NOT EXISTS idx_toll_free_verification_pending ON numbers.toll_free(messaging_updated_at)
+    WHERE messaging_verification_status = 'PENDING';
+
+DROP TRIGGER IF EXISTS update_toll_free_updated_at ON numbers.toll_free;
+DROP TRIGGER IF EXISTS trg_toll_free_updated_at ON numbers.toll_free;
+CREATE TRIGGER trg_toll_free_updated_at
+    BEFORE UPDATE ON numbers.toll_free
+    FOR EACH ROW
+    EXECUTE FUNCTION numbers.update_assigned_numbers_timestamp();
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON numbers.toll_free TO warp_app;
+
+COMMENT ON TABLE numbers.toll_free IS 'Toll-free numbers under our RespOrg in the SMS/800 registry';
+COMMENT ON COLUMN numbers.toll_free.number_id IS 'Assigned number while ACTIVE';
+COMMENT ON COLUMN numbers.toll_free.messaging_verification_status IS 'NOT_SUBMITTED, PENDING, VERIFIED, REJECTED';
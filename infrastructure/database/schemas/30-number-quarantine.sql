@@ -0,0 +1,62 @@
+-- Number Release Quarantine for WARP Platform
+-- Date: 2026-10-18
+-- Purpose: Age released numbers before they go back to SOA, so a number
+--          released by mistake can be restored and a freshly released number
+--          is not reassigned while callers still dial it
+-- Used by: services/api-gateway (NumberService release, restore and the
+--          quarantine worker)
+--
+-- Releasing a number deactivates its assigned_numbers row (billing stops and
+-- inbound routing is removed) and moves it to QUARANTINE in SOA:
+--   QUARANTINE  aging until release_after; the original customer may restore it
+--   RESTORED    reactivated (IN_USE in SOA) with its routing, campaign, E911 and CNAM intact
+--   RELEASED    release_after passed and the worker released it upstream, or it
+--               had been ported out meanwhile
+--   FAILED      SOA rejected the release or it kept failing; needs manual follow-up
+--
+-- A failed upstream release stays QUARANTINE and is retried at next_attempt_at.
+-- A number ported out while quarantined cannot be restored.
+
+CREATE TABLE IF NOT EXISTS numbers.number_quarantine (
+    number_id UUID PRIMARY KEY REFERENCES numbers.assigned_numbers(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL REFERENCES accounts.customers(id) ON DELETE RESTRICT,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'QUARANTINE'
+        CHECK (status IN ('QUARANTINE', 'RESTORED', 'RELEASED', 'FAILED')),
+    release_reason VARCHAR(100),
+
+    quarantined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    release_after TIMESTAMPTZ NOT NULL,
+    quarantined_by UUID,
+
+    restored_at TIMESTAMPTZ,
+    restored_by UUID,
+    released_at TIMESTAMPTZ,             -- Released upstream by the worker
+
+    -- Retry tracking for the upstream release
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ DEFAULT NOW(),
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_number_quarantine_customer
+    ON numbers.number_quarantine(customer_id)
+    WHERE status = 'QUARANTINE';
+
+CREATE INDEX IF NOT EXISTS idx_number_quarantine_due
+    ON numbers.number_quarantine(release_after)
+    WHERE status = 'QUARANTINE';
+
+DROP TRIGGER IF EXISTS trg_number_quarantine_updated_at ON numbers.number_quarantine;
+CREATE TRIGGER trg_number_quarantine_updated_at
+    BEFORE UPDATE ON numbers.number_quarantine
+    FOR EACH ROW
+    EXECUTE FUNCTION numbers.update_assigned_numbers_timestamp();
+
+COMMENT ON TABLE numbers.number_quarantine IS 'Released numbers aging before they are returned to SOA';
+COMMENT ON COLUMN numbers.number_quarantine.release_after IS 'End of the aging period; restorable until then';
+
+GRANT SELECT, INSERT, UPDATE ON numbers.number_quarantine TO warp_app;
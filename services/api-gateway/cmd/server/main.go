@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -14,7 +16,10 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/ringer-warp/api-gateway/internal/auth"
 	"github.com/ringer-warp/api-gateway/internal/claude"
+	"github.com/ringer-warp/api-gateway/internal/cloudip"
+	"github.com/ringer-warp/api-gateway/internal/cnam"
 	"github.com/ringer-warp/api-gateway/internal/database"
+	"github.com/ringer-warp/api-gateway/internal/e911"
 	"github.com/ringer-warp/api-gateway/internal/email"
 	"github.com/ringer-warp/api-gateway/internal/gatekeeper"
 	"github.com/ringer-warp/api-gateway/internal/handlers"
@@ -22,10 +27,12 @@ import (
 	"github.com/ringer-warp/api-gateway/internal/invitation"
 	"github.com/ringer-warp/api-gateway/internal/middleware"
 	"github.com/ringer-warp/api-gateway/internal/repository"
+	"github.com/ringer-warp/api-gateway/internal/secrets"
 	"github.com/ringer-warp/api-gateway/internal/services"
 	"github.com/ringer-warp/api-gateway/internal/soa"
 	"github.com/ringer-warp/api-gateway/internal/tcr"
 	"github.com/ringer-warp/api-gateway/internal/tincomply"
+	"github.com/ringer-warp/api-gateway/internal/tollfree"
 	"github.com/ringer-warp/api-gateway/internal/trunk"
 	"go.uber.org/zap"
 )
@@ -93,6 +100,7 @@ func main() {
 	hubspotSyncRepo := repository.NewHubSpotSyncRepository(db)
 	invitationRepo := repository.NewInvitationRepository(db)
 	trunkRepo := repository.NewTrunkRepository(db)
+	smppBindRepo := repository.NewSMPPBindRepository(db)
 
 	// Initialize gatekeeper
 	gk := gatekeeper.NewGatekeeper(permRepo, logger)
@@ -183,10 +191,48 @@ func main() {
 	log.Println("✅ Invitation system initialized")
 
 	// Initialize trunk management system
-	trunkService := trunk.NewService(trunkRepo, customerRepo, redisClient)
+	trunkService := trunk.NewService(trunkRepo, customerRepo, redisClient, logger)
+	if os.Getenv("TRUNK_ACL_ALLOW_PRIVATE") == "true" {
+		// Lab/dev only: accept RFC 1918 and other reserved ranges in trunk ACLs
+		trunkService.SetAllowPrivateTrunkIPs(true)
+		log.Printf("⚠️  Trunk ACLs accept private and reserved ranges (TRUNK_ACL_ALLOW_PRIVATE)")
+	}
 	trunkHandler := handlers.NewTrunkHandler(trunkService, customerRepo)
 
-	log.Println("✅ Trunk management system initialized")
+	// Keep the Kamailio address table in Redis reconciled with Postgres
+	reconcileInterval := 5 * time.Minute
+	if v := os.Getenv("KAMAILIO_RECONCILE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			reconcileInterval = d
+		} else {
+			log.Printf("⚠️  Invalid KAMAILIO_RECONCILE_INTERVAL %q, using %s", v, reconcileInterval)
+		}
+	}
+	trunkService.StartAddressReconciler(context.Background(), reconcileInterval)
+
+	log.Printf("✅ Trunk management system initialized (address reconciler every %s)", reconcileInterval)
+
+	// Dedicated ingress IP provisioning (premium customers)
+	switch provider := os.Getenv("DEDICATED_IP_PROVIDER"); provider {
+	case "gcp":
+		if projectID := os.Getenv("GCP_PROJECT_ID"); projectID != "" {
+			trunkService.SetAddressProvider(cloudip.NewGCPProvider(projectID))
+		} else {
+			log.Printf("⚠️  DEDICATED_IP_PROVIDER=gcp requires GCP_PROJECT_ID - dedicated IP provisioning disabled")
+		}
+	case "fake":
+		trunkService.SetAddressProvider(cloudip.NewFakeProvider())
+		log.Printf("⚠️  Dedicated IPs use the in-memory fake provider (DEDICATED_IP_PROVIDER=fake)")
+	case "":
+		log.Printf("⚠️  DEDICATED_IP_PROVIDER not set - dedicated IP provisioning disabled")
+	default:
+		log.Printf("⚠️  Unknown DEDICATED_IP_PROVIDER %q - dedicated IP provisioning disabled", provider)
+	}
+	trunkService.StartDedicatedIPWorker(context.Background(), 15*time.Second)
+
+	// Initialize customer SMPP bind management (credentials in DB, sessions via smpp-gateway)
+	smppBindService := services.NewSMPPBindService(smppBindRepo, customerRepo, logger)
+	smppBindHandler := handlers.NewSMPPBindHandler(smppBindService, customerRepo, smppProxyHandler, logger)
 
 	// Initialize Number Inventory system (JIT provisioning from SOA)
 	soaAPIToken := os.Getenv("SOA_API_TOKEN")
@@ -204,6 +250,130 @@ func main() {
 		numberService := services.NewNumberService(numberRepo, soaClient, logger)
 		numberHandler = handlers.NewNumberHandler(numberService, logger)
 
+		// Publish inbound DID routes for the SIP edge and keep them reconciled
+		numberService.SetRouteStore(redisClient)
+		routeReconcileInterval := 15 * time.Minute
+		if v := os.Getenv("DID_ROUTE_RECONCILE_INTERVAL"); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d > 0 {
+				routeReconcileInterval = d
+			} else {
+				log.Printf("⚠️  Invalid DID_ROUTE_RECONCILE_INTERVAL %q, using %s", v, routeReconcileInterval)
+			}
+		}
+		numberService.StartDIDRouteReconciler(context.Background(), routeReconcileInterval)
+
+		// Short-lived number search cache (0 disables it)
+		if v := os.Getenv("NUMBER_SEARCH_CACHE_TTL"); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+				numberService.SetSearchCacheTTL(d)
+			} else {
+				log.Printf("⚠️  Invalid NUMBER_SEARCH_CACHE_TTL %q, using default", v)
+			}
+		}
+
+		// Converge purchases whose SOA assignment and local record diverged
+		numberService.StartPurchaseRepairWorker(context.Background(), time.Minute)
+
+		// Process asynchronous bulk number orders
+		if v := os.Getenv("BULK_ORDER_CONCURRENCY"); v != "" {
+			if n, err := strconv.Atoi(v); err == nil {
+				numberService.SetBulkOrderConcurrency(n)
+			} else {
+				log.Printf("⚠️  Invalid BULK_ORDER_CONCURRENCY %q, using default", v)
+			}
+		}
+		numberService.StartBulkOrderDispatcher(context.Background(), 5*time.Second)
+
+		// Released numbers age in quarantine before returning to SOA (0 releases immediately)
+		if v := os.Getenv("NUMBER_QUARANTINE_PERIOD"); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+				numberService.SetQuarantinePeriod(d)
+			} else {
+				log.Printf("⚠️  Invalid NUMBER_QUARANTINE_PERIOD %q, using default", v)
+			}
+		}
+		numberService.StartQuarantineWorker(context.Background(), 5*time.Minute)
+
+		// Losing-carrier PINs on port requests are sealed at rest (32-byte key, base64)
+		if v := os.Getenv("PORT_PIN_KEY"); v != "" {
+			if pinCipher, err := secrets.NewCipherFromBase64(v); err == nil {
+				numberService.SetPortPINCipher(pinCipher)
+			} else {
+				log.Printf("⚠️  Invalid PORT_PIN_KEY (%v) - port requests with a PIN are refused", err)
+			}
+		} else {
+			log.Printf("⚠️  PORT_PIN_KEY not set - port requests with a PIN are refused")
+		}
+
+		// E911 address validation and number provisioning
+		switch provider := os.Getenv("E911_PROVIDER"); provider {
+		case "fake":
+			numberService.SetE911Provider(e911.NewFakeProvider())
+			log.Printf("⚠️  E911 uses the in-memory fake provider (E911_PROVIDER=fake)")
+		case "":
+			log.Printf("⚠️  E911_PROVIDER not set - E911 provisioning disabled")
+		default:
+			log.Printf("⚠️  Unknown E911_PROVIDER %q - E911 provisioning disabled", provider)
+		}
+		if os.Getenv("E911_REQUIRED_FOR_VOICE") == "true" {
+			numberService.SetE911Policy(true)
+			log.Println("✅ Voice-enabled numbers require a validated E911 address")
+		}
+
+		// Outbound caller ID names pushed to the CNAM/LIDB provider
+		switch provider := os.Getenv("CNAM_PROVIDER"); provider {
+		case "fake":
+			numberService.SetCNAMProvider(cnam.NewFakeProvider())
+			numberService.StartCNAMWorker(context.Background(), 30*time.Second)
+			log.Printf("⚠️  CNAM uses the in-memory fake provider (CNAM_PROVIDER=fake)")
+		case "":
+			log.Printf("⚠️  CNAM_PROVIDER not set - CNAM display names are stored but not provisioned")
+		default:
+			log.Printf("⚠️  Unknown CNAM_PROVIDER %q - CNAM display names are stored but not provisioned", provider)
+		}
+
+		// Toll-free numbers through the SMS/800 registry under our RespOrg
+		var respOrgIDs []string
+		for _, id := range strings.Split(os.Getenv("TOLL_FREE_RESPORG_IDS"), ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				respOrgIDs = append(respOrgIDs, id)
+			}
+		}
+		switch provider := os.Getenv("TOLL_FREE_PROVIDER"); {
+		case provider == "":
+			log.Printf("⚠️  TOLL_FREE_PROVIDER not set - toll-free provisioning disabled")
+		case len(respOrgIDs) == 0:
+			log.Printf("⚠️  TOLL_FREE_RESPORG_IDS not set - toll-free provisioning disabled")
+		case provider == "fake":
+			numberService.SetTollFreeProvider(tollfree.NewFakeProvider(), respOrgIDs)
+			numberService.StartTollFreeWorker(context.Background(), 5*time.Minute)
+			log.Printf("⚠️  Toll-free uses the in-memory fake provider (TOLL_FREE_PROVIDER=fake)")
+		default:
+			log.Printf("⚠️  Unknown TOLL_FREE_PROVIDER %q - toll-free provisioning disabled", provider)
+		}
+
+		// Periodically reconcile our SPID's SOA inventory with assigned numbers
+		if soaSPID := os.Getenv("SOA_SPID"); soaSPID != "" {
+			var homeLRNs []string
+			if v := os.Getenv("SOA_HOME_LRNS"); v != "" {
+				homeLRNs = strings.Split(v, ",")
+			}
+			numberService.SetReconcileIdentity(soaSPID, homeLRNs)
+
+			soaReconcileInterval := 6 * time.Hour
+			if v := os.Getenv("SOA_RECONCILE_INTERVAL"); v != "" {
+				if d, err := time.ParseDuration(v); err == nil && d > 0 {
+					soaReconcileInterval = d
+				} else {
+					log.Printf("⚠️  Invalid SOA_RECONCILE_INTERVAL %q, using %s", v, soaReconcileInterval)
+				}
+			}
+			numberService.StartInventoryReconciler(context.Background(), soaReconcileInterval)
+			log.Printf("✅ SOA inventory reconciler started for SPID %s (every %s)", soaSPID, soaReconcileInterval)
+		} else {
+			log.Println("⚠️  SOA inventory reconciliation disabled (SOA_SPID not set)")
+		}
+
 		log.Println("✅ Number inventory system initialized (JIT provisioning)")
 	} else {
 		log.Println("⚠️  Number inventory disabled (SOA_API_TOKEN not set)")
@@ -453,6 +623,7 @@ func main() {
 			// Trunk Management (admin-scoped by customer ID or BAN)
 			admin.POST("/customers/:customerId/trunks", trunkHandler.CreateTrunkGroup)
 			admin.GET("/customers/:customerId/trunks", trunkHandler.ListTrunkGroups)
+			admin.GET("/customers/:customerId/trunks/utilization", trunkHandler.GetTrunkUtilization)
 			admin.GET("/customers/:customerId/trunks/:trunk_id", trunkHandler.GetTrunkGroup)
 			admin.PUT("/customers/:customerId/trunks/:trunk_id", trunkHandler.UpdateTrunkGroup)
 			admin.DELETE("/customers/:customerId/trunks/:trunk_id", trunkHandler.DeleteTrunkGroup)
@@ -463,13 +634,70 @@ func main() {
 			admin.PUT("/customers/:customerId/trunks/:trunk_id/ips/:ip_id", trunkHandler.UpdateTrunkIP)
 			admin.DELETE("/customers/:customerId/trunks/:trunk_id/ips/:ip_id", trunkHandler.DeleteTrunkIP)
 
+			// Trunk SIP Digest Credentials (admin-scoped)
+			admin.POST("/customers/:customerId/trunks/:trunk_id/credentials", trunkHandler.CreateTrunkCredential)
+			admin.GET("/customers/:customerId/trunks/:trunk_id/credentials", trunkHandler.ListTrunkCredentials)
+			admin.POST("/customers/:customerId/trunks/:trunk_id/credentials/:credential_id/rotate", trunkHandler.RotateTrunkCredential)
+			admin.DELETE("/customers/:customerId/trunks/:trunk_id/credentials/:credential_id", trunkHandler.RevokeTrunkCredential)
+
+			// Trunk Termination Targets / Routing (admin-scoped)
+			admin.GET("/customers/:customerId/trunks/:trunk_id/routing", trunkHandler.GetTrunkRouting)
+			admin.PUT("/customers/:customerId/trunks/:trunk_id/routing/settings", trunkHandler.UpdateTrunkRoutingSettings)
+			admin.POST("/customers/:customerId/trunks/:trunk_id/targets", trunkHandler.CreateTrunkTarget)
+			admin.PUT("/customers/:customerId/trunks/:trunk_id/targets/:target_id", trunkHandler.UpdateTrunkTarget)
+			admin.DELETE("/customers/:customerId/trunks/:trunk_id/targets/:target_id", trunkHandler.DeleteTrunkTarget)
+
+			// Dedicated ingress IPs (admin-scoped)
+			admin.POST("/customers/:customerId/dedicated-ips", trunkHandler.RequestDedicatedIP)
+			admin.GET("/customers/:customerId/dedicated-ips", trunkHandler.ListDedicatedIPs)
+			admin.GET("/customers/:customerId/dedicated-ips/:ip_id", trunkHandler.GetDedicatedIP)
+			admin.DELETE("/customers/:customerId/dedicated-ips/:ip_id", trunkHandler.DeprovisionDedicatedIP)
+
 			// Utility endpoints
 			admin.POST("/trunks/sync-redis", trunkHandler.SyncAllTrunkIPs)
+			admin.GET("/trunks/sync-redis", trunkHandler.GetTrunkIPSyncStatus)
+
+			// Customer SMPP Bind Management (admin-scoped)
+			admin.POST("/customers/:customerId/smpp-binds", smppBindHandler.CreateBind)
+			admin.GET("/customers/:customerId/smpp-binds", smppBindHandler.ListBinds)
+			admin.GET("/customers/:customerId/smpp-binds/:bind_id", smppBindHandler.GetBind)
+			admin.PUT("/customers/:customerId/smpp-binds/:bind_id", smppBindHandler.UpdateBind)
+			admin.POST("/customers/:customerId/smpp-binds/:bind_id/rotate-password", smppBindHandler.RotatePassword)
+			admin.POST("/customers/:customerId/smpp-binds/:bind_id/disable", smppBindHandler.DisableBind)
+
+			// Live SMPP sessions (proxied to smpp-gateway)
+			admin.GET("/customers/:customerId/smpp-sessions", smppBindHandler.ListSessions)
+			admin.POST("/customers/:customerId/smpp-sessions/:system_id/unbind", smppBindHandler.ForceUnbind)
 
 			// TCR Webhook Management (if enabled)
 			if tcrWebhookHandler != nil {
 				admin.POST("/webhooks/reprocess", tcrWebhookHandler.ReprocessUnprocessedEvents)
 			}
+
+			// Number inventory repair and reconciliation (if number inventory enabled)
+			if numberHandler != nil {
+				admin.GET("/numbers/purchase-intents", numberHandler.ListPurchaseIntents)
+				admin.POST("/numbers/purchase-intents/:id/retry", numberHandler.RetryPurchaseIntent)
+
+				// SOA <-> local inventory reconciliation
+				admin.POST("/numbers/reconcile", numberHandler.StartInventoryReconcile)
+				admin.GET("/numbers/reconcile/runs", numberHandler.ListReconcileRuns)
+				admin.GET("/numbers/reconcile/drift", numberHandler.GetDriftReport)
+				admin.POST("/numbers/reconcile/drift/:id/acknowledge", numberHandler.AcknowledgeDrift)
+
+				// Inbound DID routes published to Redis
+				admin.POST("/numbers/routes/sync", numberHandler.SyncDIDRoutes)
+				admin.GET("/numbers/routes/sync", numberHandler.GetDIDRouteSyncStatus)
+
+				// Port-in carrier milestones
+				admin.GET("/numbers/ports", numberHandler.AdminListPortRequests)
+				admin.POST("/numbers/ports/:id/foc", numberHandler.RecordPortFOC)
+				admin.POST("/numbers/ports/:id/reject", numberHandler.RejectPortRequest)
+				admin.POST("/numbers/ports/:id/activate", numberHandler.ActivatePortRequest)
+
+				// Toll-free RespOrg management
+				admin.POST("/numbers/:id/toll-free/resporg", numberHandler.ChangeTollFreeRespOrg)
+			}
 		}
 
 		// HubSpot Sync (if enabled)
@@ -489,9 +717,19 @@ func main() {
 		{
 			customerTrunks.GET("", trunkHandler.CustomerListTrunks)
 			customerTrunks.POST("", trunkHandler.CustomerCreateTrunk)
+			customerTrunks.GET("/utilization", trunkHandler.CustomerGetTrunkUtilization)
 			customerTrunks.GET("/:trunk_id", trunkHandler.CustomerGetTrunk)
 			customerTrunks.POST("/:trunk_id/ips", trunkHandler.CustomerAddTrunkIP)
 			customerTrunks.DELETE("/:trunk_id/ips/:ip_id", trunkHandler.CustomerDeleteTrunkIP)
+			customerTrunks.POST("/:trunk_id/credentials", trunkHandler.CustomerCreateTrunkCredential)
+			customerTrunks.GET("/:trunk_id/credentials", trunkHandler.CustomerListTrunkCredentials)
+			customerTrunks.POST("/:trunk_id/credentials/:credential_id/rotate", trunkHandler.CustomerRotateTrunkCredential)
+			customerTrunks.DELETE("/:trunk_id/credentials/:credential_id", trunkHandler.CustomerRevokeTrunkCredential)
+			customerTrunks.GET("/:trunk_id/routing", trunkHandler.CustomerGetTrunkRouting)
+			customerTrunks.PUT("/:trunk_id/routing/settings", trunkHandler.CustomerUpdateTrunkRoutingSettings)
+			customerTrunks.POST("/:trunk_id/targets", trunkHandler.CustomerCreateTrunkTarget)
+			customerTrunks.PUT("/:trunk_id/targets/:target_id", trunkHandler.CustomerUpdateTrunkTarget)
+			customerTrunks.DELETE("/:trunk_id/targets/:target_id", trunkHandler.CustomerDeleteTrunkTarget)
 		}
 
 		// Network Information (public utility endpoints for customer configuration)
@@ -511,16 +749,62 @@ func main() {
 				numbers.POST("/reserve", numberHandler.ReserveNumbers)
 				numbers.POST("/purchase", numberHandler.PurchaseNumbers)
 
+				// Asynchronous bulk orders
+				numbers.POST("/orders", numberHandler.CreateBulkOrder)
+				numbers.GET("/orders", numberHandler.ListBulkOrders)
+				numbers.GET("/orders/:order_id", numberHandler.GetBulkOrder)
+				numbers.POST("/orders/:order_id/cancel", numberHandler.CancelBulkOrder)
+
+				// E911 addresses
+				numbers.POST("/e911/addresses", numberHandler.CreateE911Address)
+				numbers.GET("/e911/addresses", numberHandler.ListE911Addresses)
+				numbers.GET("/e911/addresses/:address_id", numberHandler.GetE911Address)
+				numbers.PATCH("/e911/addresses/:address_id", numberHandler.UpdateE911Address)
+				numbers.DELETE("/e911/addresses/:address_id", numberHandler.DeleteE911Address)
+				numbers.POST("/e911/addresses/:address_id/validate", numberHandler.ValidateE911Address)
+
+				// CNAM
+				numbers.POST("/cnam/bulk", numberHandler.BulkUpdateCNAM)
+
+				// Toll-free (SMS/800 RespOrg)
+				numbers.GET("/toll-free", numberHandler.ListTollFreeNumbers)
+				numbers.GET("/toll-free/search", numberHandler.SearchTollFreeNumbers)
+				numbers.POST("/toll-free/reserve", numberHandler.ReserveTollFreeNumbers)
+				numbers.POST("/toll-free/provision", numberHandler.ProvisionTollFreeNumbers)
+
 				// Inventory Management
 				numbers.GET("", numberHandler.ListNumbers)
 				numbers.GET("/summary", numberHandler.GetInventorySummary)
+				numbers.GET("/quarantine", numberHandler.ListQuarantinedNumbers)
 
 				// Individual Number Operations
 				numbers.GET("/:id", numberHandler.GetNumber)
 				numbers.GET("/tn/:tn", numberHandler.GetNumberByTN)
 				numbers.PATCH("/:id", numberHandler.UpdateNumber)
 				numbers.POST("/:id/release", numberHandler.ReleaseNumber)
+				numbers.POST("/:id/restore", numberHandler.RestoreNumber)
 				numbers.POST("/:id/sync", numberHandler.SyncNumber)
+				numbers.GET("/:id/e911", numberHandler.GetNumberE911)
+				numbers.POST("/:id/e911", numberHandler.ProvisionNumberE911)
+				numbers.DELETE("/:id/e911", numberHandler.DeprovisionNumberE911)
+				numbers.GET("/:id/cnam", numberHandler.GetNumberCNAM)
+				numbers.GET("/:id/toll-free", numberHandler.GetNumberTollFree)
+				numbers.POST("/:id/toll-free/verification", numberHandler.SubmitTollFreeVerification)
+				numbers.POST("/:id/toll-free/sync", numberHandler.SyncTollFreeRegistry)
+			}
+
+			// Port-in requests (LNP)
+			porting := v1.Group("/porting/projects")
+			{
+				porting.POST("", numberHandler.CreatePortRequest)
+				porting.GET("", numberHandler.ListPortRequests)
+				porting.POST("/check", numberHandler.CheckPortability)
+				porting.GET("/:id", numberHandler.GetPortRequest)
+				porting.PATCH("/:id", numberHandler.UpdatePortRequest)
+				porting.POST("/:id/documents", numberHandler.AttachPortDocument)
+				porting.DELETE("/:id/documents/:document_id", numberHandler.DeletePortDocument)
+				porting.POST("/:id/submit", numberHandler.SubmitPortRequest)
+				porting.POST("/:id/cancel", numberHandler.CancelPortRequest)
 			}
 		}
 
@@ -0,0 +1,95 @@
+package cloudip
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// FakeProvider is an in-memory Provider for local development and tests.
+// Addresses become READY after PendingPolls calls to Get and are allocated
+// sequentially from 198.51.100.0/24 (TEST-NET-2).
+type FakeProvider struct {
+	// PendingPolls is how many Get calls report PENDING before READY
+	PendingPolls int
+	// FailReserve, when set, is returned by every Reserve call
+	FailReserve error
+
+	mu        sync.Mutex
+	addresses map[string]*fakeAddress
+	next      int
+}
+
+type fakeAddress struct {
+	Address
+	polls int
+}
+
+// NewFakeProvider creates an empty fake provider
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		addresses: make(map[string]*fakeAddress),
+		next:      10,
+	}
+}
+
+// Name implements Provider
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+// Reserve implements Provider
+func (f *FakeProvider) Reserve(ctx context.Context, name, region string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.FailReserve != nil {
+		return f.FailReserve
+	}
+	key := region + "/" + name
+	if _, ok := f.addresses[key]; ok {
+		return nil
+	}
+	if f.next > 254 {
+		return fmt.Errorf("fake address pool exhausted")
+	}
+
+	f.addresses[key] = &fakeAddress{Address: Address{
+		Name:   name,
+		Region: region,
+		Status: StatusPending,
+	}}
+	return nil
+}
+
+// Get implements Provider
+func (f *FakeProvider) Get(ctx context.Context, name, region string) (*Address, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	addr, ok := f.addresses[region+"/"+name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	if addr.Status == StatusPending {
+		addr.polls++
+		if addr.polls > f.PendingPolls {
+			addr.IP = fmt.Sprintf("198.51.100.%d", f.next)
+			addr.Status = StatusReady
+			f.next++
+		}
+	}
+
+	copied := addr.Address
+	return &copied, nil
+}
+
+// Release implements Provider
+func (f *FakeProvider) Release(ctx context.Context, name, region string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.addresses, region+"/"+name)
+	return nil
+}
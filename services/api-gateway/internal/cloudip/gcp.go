@@ -0,0 +1,204 @@
+package cloudip
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	gcpComputeBaseURL = "https://compute.googleapis.com/compute/v1"
+	gcpTokenURL       = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	gcpTimeout        = 30 * time.Second
+)
+
+// GCPProvider reserves regional static external addresses through the
+// Compute Engine REST API, authenticating with the workload's service
+// account from the metadata server (Workload Identity on GKE).
+type GCPProvider struct {
+	project    string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewGCPProvider creates a provider reserving addresses in project
+func NewGCPProvider(project string) *GCPProvider {
+	return &GCPProvider{
+		project:    project,
+		httpClient: &http.Client{Timeout: gcpTimeout},
+	}
+}
+
+// GCPError represents an error response from the Compute API
+type GCPError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements the error interface
+func (e *GCPError) Error() string {
+	return fmt.Sprintf("GCP compute API error (HTTP %d): %s", e.StatusCode, e.Message)
+}
+
+// Name implements Provider
+func (g *GCPProvider) Name() string {
+	return "gcp"
+}
+
+// Reserve implements Provider
+func (g *GCPProvider) Reserve(ctx context.Context, name, region string) error {
+	body := map[string]string{
+		"name":        name,
+		"addressType": "EXTERNAL",
+		"networkTier": "PREMIUM",
+		"description": "WARP dedicated customer ingress IP",
+	}
+
+	err := g.do(ctx, http.MethodPost, g.addressesPath(region), body, nil)
+	if apiErr, ok := err.(*GCPError); ok && apiErr.StatusCode == http.StatusConflict {
+		return nil // Already reserved
+	}
+	return err
+}
+
+// Get implements Provider
+func (g *GCPProvider) Get(ctx context.Context, name, region string) (*Address, error) {
+	var resp struct {
+		Name    string `json:"name"`
+		Address string `json:"address"`
+		Status  string `json:"status"` // RESERVING, RESERVED, IN_USE
+	}
+
+	err := g.do(ctx, http.MethodGet, g.addressesPath(region)+"/"+url.PathEscape(name), nil, &resp)
+	if apiErr, ok := err.(*GCPError); ok && apiErr.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	addr := &Address{Name: resp.Name, Region: region, IP: resp.Address, Status: StatusPending}
+	if resp.Status != "RESERVING" && resp.Address != "" {
+		addr.Status = StatusReady
+	}
+	return addr, nil
+}
+
+// Release implements Provider
+func (g *GCPProvider) Release(ctx context.Context, name, region string) error {
+	err := g.do(ctx, http.MethodDelete, g.addressesPath(region)+"/"+url.PathEscape(name), nil, nil)
+	if apiErr, ok := err.(*GCPError); ok && apiErr.StatusCode == http.StatusNotFound {
+		return nil // Already released
+	}
+	return err
+}
+
+func (g *GCPProvider) addressesPath(region string) string {
+	return fmt.Sprintf("/projects/%s/regions/%s/addresses", url.PathEscape(g.project), url.PathEscape(region))
+}
+
+// do executes an authenticated Compute API request, decoding the response into out
+func (g *GCPProvider) do(ctx context.Context, method, path string, body, out interface{}) error {
+	token, err := g.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		reqBody = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, gcpComputeBaseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("GCP compute request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		var errResp struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(respBody, &errResp)
+		msg := errResp.Error.Message
+		if msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		return &GCPError{StatusCode: resp.StatusCode, Message: msg}
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to decode GCP response: %w", err)
+		}
+	}
+	return nil
+}
+
+// accessToken returns a cached OAuth token from the metadata server
+func (g *GCPProvider) accessToken(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.token != "" && time.Now().Before(g.tokenExpiry) {
+		return g.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpTokenURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch GCP access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch GCP access token: HTTP %d", resp.StatusCode)
+	}
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("failed to decode GCP access token: %w", err)
+	}
+
+	g.token = tok.AccessToken
+	// Refresh a minute early
+	g.tokenExpiry = time.Now().Add(time.Duration(tok.ExpiresIn)*time.Second - time.Minute)
+	return g.token, nil
+}
@@ -0,0 +1,42 @@
+// Package cloudip reserves and releases static external IP addresses with a
+// cloud provider. Premium customers get a dedicated ingress IP; the SIP edge
+// identifies them by destination address instead of source ACL.
+//
+// Reservation is asynchronous at every provider we use: Reserve starts it and
+// Get is polled until the address is READY.
+package cloudip
+
+import (
+	"context"
+	"errors"
+)
+
+// Address states reported by providers
+const (
+	StatusPending = "PENDING" // Reservation in progress, no IP yet
+	StatusReady   = "READY"   // IP allocated
+)
+
+// ErrNotFound indicates the provider has no address with that name
+var ErrNotFound = errors.New("cloud address not found")
+
+// Address is a static external address held with the provider
+type Address struct {
+	Name   string
+	Region string
+	IP     string // Empty while pending
+	Status string // PENDING, READY
+}
+
+// Provider reserves static external addresses. Implementations must be
+// idempotent: reserving an existing name or releasing a missing one succeeds.
+type Provider interface {
+	// Name identifies the provider in records and logs (e.g. "gcp", "fake")
+	Name() string
+	// Reserve starts reserving a static address called name in region
+	Reserve(ctx context.Context, name, region string) error
+	// Get returns the address, or ErrNotFound once released
+	Get(ctx context.Context, name, region string) (*Address, error)
+	// Release starts releasing the address
+	Release(ctx context.Context, name, region string) error
+}
@@ -0,0 +1,48 @@
+package cnam
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeProvider is an in-memory Provider for local development and tests
+type FakeProvider struct {
+	// FailSet, when set, is returned by every SetDisplayName call
+	FailSet error
+
+	mu    sync.Mutex
+	names map[string]string // tn -> display name
+}
+
+// NewFakeProvider creates an empty fake provider
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		names: make(map[string]string),
+	}
+}
+
+// Name implements Provider
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+// SetDisplayName implements Provider
+func (f *FakeProvider) SetDisplayName(ctx context.Context, tn string, displayName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.FailSet != nil {
+		return f.FailSet
+	}
+	f.names[tn] = displayName
+	return nil
+}
+
+// RemoveDisplayName implements Provider
+func (f *FakeProvider) RemoveDisplayName(ctx context.Context, tn string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.names, tn)
+	return nil
+}
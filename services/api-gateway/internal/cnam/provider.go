@@ -0,0 +1,58 @@
+// Package cnam provisions outbound caller ID names (CNAM) for telephone
+// numbers in a CNAM/LIDB database. Terminating carriers dip that database to
+// show the name, so a number's display name only reaches callers once it has
+// been provisioned there.
+package cnam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxDisplayNameLength is the LIDB limit on a CNAM display name
+const MaxDisplayNameLength = 15
+
+// ErrInvalidDisplayName indicates a display name LIDB would not accept
+var ErrInvalidDisplayName = errors.New("invalid CNAM display name")
+
+// Provider stores display names in a CNAM/LIDB database. Both calls are
+// idempotent: SetDisplayName replaces any existing name, and
+// RemoveDisplayName succeeds for numbers that have none.
+type Provider interface {
+	// Name identifies the provider in records and logs (e.g. "fake")
+	Name() string
+	// SetDisplayName provisions tn's outbound display name
+	SetDisplayName(ctx context.Context, tn string, displayName string) error
+	// RemoveDisplayName removes tn's CNAM record
+	RemoveDisplayName(ctx context.Context, tn string) error
+}
+
+// NormalizeDisplayName trims and collapses whitespace in a display name and
+// checks it against LIDB rules: at most 15 characters of letters, digits,
+// spaces and the punctuation , . - & '
+func NormalizeDisplayName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+
+	if name == "" {
+		return "", fmt.Errorf("%w: display name is empty", ErrInvalidDisplayName)
+	}
+	if len(name) > MaxDisplayNameLength {
+		return "", fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidDisplayName, name, MaxDisplayNameLength)
+	}
+	for _, r := range name {
+		if !isDisplayNameRune(r) {
+			return "", fmt.Errorf("%w: character %q is not allowed", ErrInvalidDisplayName, r)
+		}
+	}
+	return name, nil
+}
+
+func isDisplayNameRune(r rune) bool {
+	switch {
+	case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+		return true
+	}
+	return strings.ContainsRune(" ,.-&'", r)
+}
@@ -0,0 +1,40 @@
+package cnam
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeDisplayName(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "Ringer", want: "Ringer"},
+		{in: "  Acme   Widgets ", want: "Acme Widgets"},
+		{in: "O'Brien & Sons", want: "O'Brien & Sons"},
+		{in: "Smith, J.-P.", want: "Smith, J.-P."},
+		{in: "Line\t2", want: "Line 2"},
+		{in: "ABCDEFGHIJKLMNO", want: "ABCDEFGHIJKLMNO"}, // Exactly 15
+
+		{in: "", wantErr: true},
+		{in: "   ", wantErr: true},
+		{in: "ABCDEFGHIJKLMNOP", wantErr: true}, // 16
+		{in: "Acme!", wantErr: true},
+		{in: "Acme/Widgets", wantErr: true},
+		{in: "Café", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeDisplayName(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidDisplayName) {
+				t.Errorf("NormalizeDisplayName(%q) error = %v, want ErrInvalidDisplayName", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeDisplayName(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package e911
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	fakeStatePattern  = regexp.MustCompile(`^[A-Z]{2}$`)
+	fakePostalPattern = regexp.MustCompile(`^\d{5}(-\d{4})?$`)
+)
+
+// FakeProvider is an in-memory Provider for local development and tests.
+// Complete US addresses validate; any address line or city containing
+// "INVALID" fails MSAG validation, so rejections can be exercised.
+type FakeProvider struct {
+	// FailProvision, when set, is returned by every ProvisionNumber call
+	FailProvision error
+
+	mu          sync.Mutex
+	provisioned map[string]string // tn -> provider address ID
+}
+
+// NewFakeProvider creates an empty fake provider
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		provisioned: make(map[string]string),
+	}
+}
+
+// Name implements Provider
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+// ValidateAddress implements Provider
+func (f *FakeProvider) ValidateAddress(ctx context.Context, addr Address) (*ValidationResult, error) {
+	n := Address{
+		Name:         strings.TrimSpace(addr.Name),
+		AddressLine1: strings.ToUpper(strings.TrimSpace(addr.AddressLine1)),
+		AddressLine2: strings.ToUpper(strings.TrimSpace(addr.AddressLine2)),
+		City:         strings.ToUpper(strings.TrimSpace(addr.City)),
+		State:        strings.ToUpper(strings.TrimSpace(addr.State)),
+		PostalCode:   strings.TrimSpace(addr.PostalCode),
+		Country:      strings.ToUpper(strings.TrimSpace(addr.Country)),
+		LocationInfo: strings.TrimSpace(addr.LocationInfo),
+	}
+	if n.Country == "" {
+		n.Country = "US"
+	}
+
+	switch {
+	case n.AddressLine1 == "" || n.City == "":
+		return &ValidationResult{Message: "street address and city are required"}, nil
+	case n.Country != "US":
+		return &ValidationResult{Message: "only US addresses are supported"}, nil
+	case !fakeStatePattern.MatchString(n.State):
+		return &ValidationResult{Message: "state must be a 2-letter code"}, nil
+	case !fakePostalPattern.MatchString(n.PostalCode):
+		return &ValidationResult{Message: "postal code must be a 5 or 9 digit ZIP code"}, nil
+	case strings.Contains(n.AddressLine1+" "+n.City, "INVALID"):
+		return &ValidationResult{Message: "address not found in MSAG"}, nil
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{n.AddressLine1, n.AddressLine2, n.City, n.State, n.PostalCode}, "|")))
+	id := "fake-" + hex.EncodeToString(sum[:8])
+
+	return &ValidationResult{
+		Valid:             true,
+		MSAGValid:         true,
+		Normalized:        &n,
+		ProviderAddressID: id,
+	}, nil
+}
+
+// ProvisionNumber implements Provider
+func (f *FakeProvider) ProvisionNumber(ctx context.Context, tn string, providerAddressID string, callerName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.FailProvision != nil {
+		return f.FailProvision
+	}
+	if !strings.HasPrefix(providerAddressID, "fake-") {
+		return fmt.Errorf("unknown address %s", providerAddressID)
+	}
+	f.provisioned[tn] = providerAddressID
+	return nil
+}
+
+// DeprovisionNumber implements Provider
+func (f *FakeProvider) DeprovisionNumber(ctx context.Context, tn string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.provisioned, tn)
+	return nil
+}
@@ -0,0 +1,45 @@
+// Package e911 validates emergency service addresses and provisions
+// telephone numbers to them with an E911 provider. A number is only routed
+// to the right PSAP once its address is MSAG-validated and provisioned.
+package e911
+
+import (
+	"context"
+)
+
+// Address is a dispatchable location for emergency services
+type Address struct {
+	Name         string // Business or resident name shown to the PSAP
+	AddressLine1 string
+	AddressLine2 string
+	City         string
+	State        string // 2-letter code
+	PostalCode   string
+	Country      string // 2-letter code, US if empty
+	LocationInfo string // Floor, suite, etc.
+}
+
+// ValidationResult is the outcome of validating an address
+type ValidationResult struct {
+	Valid     bool
+	MSAGValid bool   // Matched the Master Street Address Guide
+	Message   string // Why the address is invalid, or provider notes
+	// Normalized is the address as the provider will store it (nil if invalid)
+	Normalized *Address
+	// ProviderAddressID references the validated address at the provider
+	ProviderAddressID string
+}
+
+// Provider validates addresses and provisions numbers to them.
+// ProvisionNumber replaces any existing provisioning of the number, and
+// DeprovisionNumber succeeds for numbers that are not provisioned.
+type Provider interface {
+	// Name identifies the provider in records and logs (e.g. "fake")
+	Name() string
+	// ValidateAddress checks an address against the MSAG
+	ValidateAddress(ctx context.Context, addr Address) (*ValidationResult, error)
+	// ProvisionNumber associates tn with a validated address
+	ProvisionNumber(ctx context.Context, tn string, providerAddressID string, callerName string) error
+	// DeprovisionNumber removes tn's E911 record
+	DeprovisionNumber(ctx context.Context, tn string) error
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/trunk"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// DEDICATED IP ENDPOINTS (Admin only)
+// ============================================================================
+// Admin: /v1/admin/customers/{customerId}/dedicated-ips
+
+// RequestDedicatedIP starts provisioning a dedicated ingress IP for a customer
+// POST /v1/admin/customers/{customerId}/dedicated-ips
+func (h *TrunkHandler) RequestDedicatedIP(c *gin.Context) {
+	customerID, ok := h.adminCustomerID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RequestDedicatedIPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	requestedBy, _ := uuid.Parse(c.GetString("user_id"))
+
+	ip, err := h.trunkService.RequestDedicatedIP(c.Request.Context(), customerID, req, requestedBy)
+	if err != nil {
+		writeDedicatedIPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":      "Dedicated IP provisioning started",
+		"dedicated_ip": ip,
+	})
+}
+
+// ListDedicatedIPs lists a customer's dedicated IPs, including released ones
+// GET /v1/admin/customers/{customerId}/dedicated-ips
+func (h *TrunkHandler) ListDedicatedIPs(c *gin.Context) {
+	customerID, ok := h.adminCustomerID(c)
+	if !ok {
+		return
+	}
+
+	ips, err := h.trunkService.ListDedicatedIPs(c.Request.Context(), customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dedicated_ips": ips,
+		"count":         len(ips),
+	})
+}
+
+// GetDedicatedIP returns a dedicated IP and its provisioning status
+// GET /v1/admin/customers/{customerId}/dedicated-ips/{ip_id}
+func (h *TrunkHandler) GetDedicatedIP(c *gin.Context) {
+	customerID, ok := h.adminCustomerID(c)
+	if !ok {
+		return
+	}
+	ipID, ok := parseUUIDParam(c, "ip_id", "Invalid dedicated IP ID")
+	if !ok {
+		return
+	}
+
+	ip, err := h.trunkService.GetDedicatedIP(c.Request.Context(), ipID, customerID)
+	if err != nil {
+		writeDedicatedIPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ip)
+}
+
+// DeprovisionDedicatedIP removes the Redis mapping and releases the address
+// DELETE /v1/admin/customers/{customerId}/dedicated-ips/{ip_id}
+func (h *TrunkHandler) DeprovisionDedicatedIP(c *gin.Context) {
+	customerID, ok := h.adminCustomerID(c)
+	if !ok {
+		return
+	}
+	ipID, ok := parseUUIDParam(c, "ip_id", "Invalid dedicated IP ID")
+	if !ok {
+		return
+	}
+
+	ip, err := h.trunkService.DeprovisionDedicatedIP(c.Request.Context(), ipID, customerID)
+	if err != nil {
+		writeDedicatedIPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":      "Dedicated IP deprovisioning started",
+		"dedicated_ip": ip,
+	})
+}
+
+// writeDedicatedIPError maps dedicated IP errors to HTTP statuses
+func writeDedicatedIPError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, trunk.ErrDedicatedIPNotFound), errors.Is(err, trunk.ErrCustomerNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, trunk.ErrDedicatedIPExists), errors.Is(err, trunk.ErrDedicatedIPInvalidState):
+		status = http.StatusConflict
+	case errors.Is(err, trunk.ErrDedicatedIPUnavailable):
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
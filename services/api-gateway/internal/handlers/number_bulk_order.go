@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/services"
+	"go.uber.org/zap"
+)
+
+// CreateBulkOrder godoc
+// @Summary Submit a bulk number order
+// @Description Queue an asynchronous order for explicit numbers or for a quantity of numbers matching search criteria. Poll the returned order for progress.
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Param request body models.CreateBulkOrderRequest true "Bulk order"
+// @Success 202 {object} models.APIResponse{data=models.BulkNumberOrder}
+// @Failure 400 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/orders [post]
+func (h *NumberHandler) CreateBulkOrder(c *gin.Context) {
+	var req models.CreateBulkOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	customerID, err := h.getCustomerID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.NewErrorResponse("CUSTOMER_ACCESS_ERROR", err.Error()))
+		return
+	}
+
+	createdBy, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	// Get user email for reservation tracking
+	userEmail, _ := c.Get("user_email")
+	reservedBy := "unknown"
+	if email, ok := userEmail.(string); ok {
+		reservedBy = email
+	}
+
+	order, err := h.numberService.CreateBulkOrder(c.Request.Context(), &req, customerID, createdBy, reservedBy)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidBulkOrder) {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+			return
+		}
+		if isE911Error(err) {
+			h.writeE911Error(c, err, uuid.Nil)
+			return
+		}
+		h.logger.Error("Failed to create bulk order", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("CREATE_FAILED", "Failed to create bulk order"))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.NewSuccessResponse(order))
+}
+
+// ListBulkOrders godoc
+// @Summary List bulk number orders
+// @Description Get the customer's bulk number orders with progress counts, newest first
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Param limit query int false "Max results" default(20)
+// @Success 200 {object} models.APIResponse{data=[]models.BulkNumberOrder}
+// @Failure 500 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/orders [get]
+func (h *NumberHandler) ListBulkOrders(c *gin.Context) {
+	customerID, err := h.getCustomerID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.NewErrorResponse("CUSTOMER_ACCESS_ERROR", err.Error()))
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	orders, err := h.numberService.ListBulkOrders(c.Request.Context(), customerID, limit)
+	if err != nil {
+		h.logger.Error("Failed to list bulk orders", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("QUERY_FAILED", "Failed to retrieve bulk orders"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(gin.H{
+		"orders": orders,
+		"count":  len(orders),
+	}))
+}
+
+// GetBulkOrder godoc
+// @Summary Get a bulk number order
+// @Description Get a bulk number order with per-number status
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Param order_id path string true "Order ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.BulkNumberOrder}
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/orders/{order_id} [get]
+func (h *NumberHandler) GetBulkOrder(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("order_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid order ID format"))
+		return
+	}
+
+	order, err := h.numberService.GetBulkOrder(c.Request.Context(), orderID, h.getCustomerFilter(c))
+	if err != nil {
+		h.writeBulkOrderError(c, err, orderID)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(order))
+}
+
+// CancelBulkOrder godoc
+// @Summary Cancel a bulk number order
+// @Description Stop a queued or running order. Reserved numbers are released; numbers already purchased are kept.
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Param order_id path string true "Order ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.BulkNumberOrder}
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/orders/{order_id}/cancel [post]
+func (h *NumberHandler) CancelBulkOrder(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("order_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid order ID format"))
+		return
+	}
+
+	order, err := h.numberService.CancelBulkOrder(c.Request.Context(), orderID, h.getCustomerFilter(c))
+	if err != nil {
+		h.writeBulkOrderError(c, err, orderID)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(order))
+}
+
+func (h *NumberHandler) writeBulkOrderError(c *gin.Context, err error, orderID uuid.UUID) {
+	switch {
+	case errors.Is(err, services.ErrBulkOrderNotFound):
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("NOT_FOUND", "Bulk order not found"))
+	case errors.Is(err, services.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, models.NewErrorResponse("ACCESS_DENIED", "You don't have access to this order"))
+	case errors.Is(err, services.ErrBulkOrderFinished):
+		c.JSON(http.StatusConflict, models.NewErrorResponse("ORDER_FINISHED", err.Error()))
+	default:
+		h.logger.Error("Bulk order request failed", zap.Error(err), zap.String("order_id", orderID.String()))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "Failed to process bulk order"))
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/cnam"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/services"
+	"go.uber.org/zap"
+)
+
+// GetNumberCNAM godoc
+// @Summary Get a number's CNAM status
+// @Description Get the CNAM/LIDB provisioning status of an assigned number's outbound display name
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Param id path string true "Number ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.NumberCNAMProvisioning}
+// @Failure 404 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/{id}/cnam [get]
+func (h *NumberHandler) GetNumberCNAM(c *gin.Context) {
+	numberID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid number ID format"))
+		return
+	}
+
+	p, err := h.numberService.GetNumberCNAM(c.Request.Context(), numberID, h.getCustomerFilter(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNumberNotFound), errors.Is(err, services.ErrCNAMStatusNotFound):
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("NOT_FOUND", err.Error()))
+		case errors.Is(err, services.ErrAccessDenied):
+			c.JSON(http.StatusForbidden, models.NewErrorResponse("ACCESS_DENIED", "You don't have access to this number"))
+		default:
+			h.logger.Error("Failed to get CNAM status", zap.Error(err), zap.String("number_id", numberID.String()))
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "Failed to get CNAM status"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(p))
+}
+
+// BulkUpdateCNAM godoc
+// @Summary Update CNAM on many numbers
+// @Description Change the outbound display name (max 15 characters: letters, digits, spaces and , . - & ') or CNAM flag of up to 1000 numbers. Each number is accepted or rejected on its own; accepted changes are provisioned asynchronously. Unchanged numbers with CNAM enabled are provisioned again.
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Param request body models.BulkCNAMUpdateRequest true "CNAM updates"
+// @Success 202 {object} models.APIResponse{data=[]models.CNAMUpdateResult}
+// @Failure 400 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/cnam/bulk [post]
+func (h *NumberHandler) BulkUpdateCNAM(c *gin.Context) {
+	var req models.BulkCNAMUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	updatedBy, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	results, err := h.numberService.BulkUpdateCNAM(c.Request.Context(), &req, h.getCustomerFilter(c), updatedBy)
+	if err != nil {
+		if errors.Is(err, services.ErrCNAMNotConfigured) {
+			c.JSON(http.StatusServiceUnavailable, models.NewErrorResponse("CNAM_NOT_CONFIGURED", err.Error()))
+			return
+		}
+		h.logger.Error("Bulk CNAM update failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("UPDATE_FAILED", "Failed to update CNAM"))
+		return
+	}
+
+	rejected := 0
+	for _, r := range results {
+		if r.Status == services.CNAMRejected {
+			rejected++
+		}
+	}
+
+	c.JSON(http.StatusAccepted, models.NewSuccessResponse(gin.H{
+		"results":  results,
+		"accepted": len(results) - rejected,
+		"rejected": rejected,
+	}))
+}
+
+// isInvalidCNAM reports whether err is a rejected CNAM display name
+func isInvalidCNAM(err error) bool {
+	return errors.Is(err, cnam.ErrInvalidDisplayName)
+}
@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/services"
+	"go.uber.org/zap"
+)
+
+// CreateE911Address godoc
+// @Summary Create an E911 address
+// @Description Store an emergency service address and validate it with the E911 provider. Addresses the provider rejects are kept as INVALID with the reason.
+// @Tags E911
+// @Accept json
+// @Produce json
+// @Param request body models.CreateE911AddressRequest true "E911 address"
+// @Success 201 {object} models.APIResponse{data=models.E911Address}
+// @Failure 400 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/e911/addresses [post]
+func (h *NumberHandler) CreateE911Address(c *gin.Context) {
+	var req models.CreateE911AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	customerID, err := h.getCustomerID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.NewErrorResponse("CUSTOMER_ACCESS_ERROR", err.Error()))
+		return
+	}
+
+	createdBy, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	addr, err := h.numberService.CreateE911Address(c.Request.Context(), &req, customerID, createdBy)
+	if err != nil {
+		h.writeE911Error(c, err, uuid.Nil)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse(addr))
+}
+
+// ListE911Addresses godoc
+// @Summary List E911 addresses
+// @Description Get the customer's E911 addresses
+// @Tags E911
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.APIResponse{data=[]models.E911Address}
+// @Failure 500 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/e911/addresses [get]
+func (h *NumberHandler) ListE911Addresses(c *gin.Context) {
+	customerID, err := h.getCustomerID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.NewErrorResponse("CUSTOMER_ACCESS_ERROR", err.Error()))
+		return
+	}
+
+	addresses, err := h.numberService.ListE911Addresses(c.Request.Context(), customerID)
+	if err != nil {
+		h.logger.Error("Failed to list E911 addresses", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("LIST_FAILED", "Failed to list E911 addresses"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(addresses))
+}
+
+// GetE911Address godoc
+// @Summary Get an E911 address
+// @Description Get an E911 address with its validation status
+// @Tags E911
+// @Accept json
+// @Produce json
+// @Param address_id path string true "Address ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.E911Address}
+// @Failure 404 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/e911/addresses/{address_id} [get]
+func (h *NumberHandler) GetE911Address(c *gin.Context) {
+	id, ok := h.parseE911AddressID(c)
+	if !ok {
+		return
+	}
+
+	addr, err := h.numberService.GetE911Address(c.Request.Context(), id, h.getCustomerFilter(c))
+	if err != nil {
+		h.writeE911Error(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(addr))
+}
+
+// UpdateE911Address godoc
+// @Summary Update an E911 address
+// @Description Change an E911 address. It is validated again and numbers provisioned to it are re-provisioned; changes the provider rejects are refused while numbers use the address.
+// @Tags E911
+// @Accept json
+// @Produce json
+// @Param address_id path string true "Address ID (UUID)"
+// @Param request body models.UpdateE911AddressRequest true "Address changes"
+// @Success 200 {object} models.APIResponse{data=models.E911Address}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 422 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/e911/addresses/{address_id} [patch]
+func (h *NumberHandler) UpdateE911Address(c *gin.Context) {
+	id, ok := h.parseE911AddressID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateE911AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	updatedBy, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	addr, err := h.numberService.UpdateE911Address(c.Request.Context(), id, &req, h.getCustomerFilter(c), updatedBy)
+	if err != nil {
+		h.writeE911Error(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(addr))
+}
+
+// ValidateE911Address godoc
+// @Summary Re-validate an E911 address
+// @Description Run E911 provider validation on an address again
+// @Tags E911
+// @Accept json
+// @Produce json
+// @Param address_id path string true "Address ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.E911Address}
+// @Failure 404 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/e911/addresses/{address_id}/validate [post]
+func (h *NumberHandler) ValidateE911Address(c *gin.Context) {
+	id, ok := h.parseE911AddressID(c)
+	if !ok {
+		return
+	}
+
+	addr, err := h.numberService.ValidateE911Address(c.Request.Context(), id, h.getCustomerFilter(c))
+	if err != nil {
+		h.writeE911Error(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(addr))
+}
+
+// DeleteE911Address godoc
+// @Summary Delete an E911 address
+// @Description Delete an E911 address no active number is provisioned to
+// @Tags E911
+// @Accept json
+// @Produce json
+// @Param address_id path string true "Address ID (UUID)"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/e911/addresses/{address_id} [delete]
+func (h *NumberHandler) DeleteE911Address(c *gin.Context) {
+	id, ok := h.parseE911AddressID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.numberService.DeleteE911Address(c.Request.Context(), id, h.getCustomerFilter(c)); err != nil {
+		h.writeE911Error(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(gin.H{"message": "E911 address deleted"}))
+}
+
+// GetNumberE911 godoc
+// @Summary Get a number's E911 status
+// @Description Get the E911 provisioning status of an assigned number
+// @Tags E911
+// @Accept json
+// @Produce json
+// @Param id path string true "Number ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.NumberE911Provisioning}
+// @Failure 404 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/{id}/e911 [get]
+func (h *NumberHandler) GetNumberE911(c *gin.Context) {
+	numberID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid number ID format"))
+		return
+	}
+
+	p, err := h.numberService.GetNumberE911(c.Request.Context(), numberID, h.getCustomerFilter(c))
+	if err != nil {
+		h.writeE911Error(c, err, numberID)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(p))
+}
+
+// ProvisionNumberE911 godoc
+// @Summary Provision a number for E911
+// @Description Provision an assigned number to one of the customer's validated E911 addresses
+// @Tags E911
+// @Accept json
+// @Produce json
+// @Param id path string true "Number ID (UUID)"
+// @Param request body models.ProvisionE911Request true "Address to provision to"
+// @Success 200 {object} models.APIResponse{data=models.NumberE911Provisioning}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 502 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/{id}/e911 [post]
+func (h *NumberHandler) ProvisionNumberE911(c *gin.Context) {
+	numberID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid number ID format"))
+		return
+	}
+
+	var req models.ProvisionE911Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	updatedBy, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	p, err := h.numberService.ProvisionNumberE911(c.Request.Context(), numberID, req.AddressID, h.getCustomerFilter(c), updatedBy)
+	if err != nil {
+		h.writeE911Error(c, err, numberID)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(p))
+}
+
+// DeprovisionNumberE911 godoc
+// @Summary Deprovision a number from E911
+// @Description Remove a number's E911 record. When policy requires E911 for voice, voice must be disabled first.
+// @Tags E911
+// @Accept json
+// @Produce json
+// @Param id path string true "Number ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.NumberE911Provisioning}
+// @Failure 404 {object} models.APIResponse
+// @Failure 422 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/{id}/e911 [delete]
+func (h *NumberHandler) DeprovisionNumberE911(c *gin.Context) {
+	numberID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid number ID format"))
+		return
+	}
+
+	updatedBy, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	p, err := h.numberService.DeprovisionNumberE911(c.Request.Context(), numberID, h.getCustomerFilter(c), updatedBy)
+	if err != nil {
+		h.writeE911Error(c, err, numberID)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(p))
+}
+
+func (h *NumberHandler) parseE911AddressID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("address_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid address ID format"))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// isE911Error reports whether err is an E911 error writeE911Error maps to a
+// client status, for handlers that otherwise use their own error mapping
+func isE911Error(err error) bool {
+	return errors.Is(err, services.ErrE911Required) ||
+		errors.Is(err, services.ErrE911AddressNotFound) ||
+		errors.Is(err, services.ErrE911AddressNotValidated) ||
+		errors.Is(err, services.ErrE911NotConfigured) ||
+		errors.Is(err, services.ErrE911ProvisionFailed)
+}
+
+func (h *NumberHandler) writeE911Error(c *gin.Context, err error, id uuid.UUID) {
+	switch {
+	case errors.Is(err, services.ErrE911AddressNotFound), errors.Is(err, services.ErrNumberNotFound):
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("NOT_FOUND", err.Error()))
+	case errors.Is(err, services.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, models.NewErrorResponse("ACCESS_DENIED", "You don't have access to this resource"))
+	case errors.Is(err, services.ErrE911AddressInUse):
+		c.JSON(http.StatusConflict, models.NewErrorResponse("ADDRESS_IN_USE", err.Error()))
+	case errors.Is(err, services.ErrE911AddressInvalid), errors.Is(err, services.ErrE911AddressNotValidated):
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("ADDRESS_NOT_VALIDATED", err.Error()))
+	case errors.Is(err, services.ErrE911Required):
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("E911_REQUIRED", err.Error()))
+	case errors.Is(err, services.ErrE911ProvisionFailed):
+		c.JSON(http.StatusBadGateway, models.NewErrorResponse("E911_PROVISION_FAILED", err.Error()))
+	case errors.Is(err, services.ErrE911NotConfigured):
+		c.JSON(http.StatusServiceUnavailable, models.NewErrorResponse("E911_NOT_CONFIGURED", err.Error()))
+	default:
+		h.logger.Error("E911 request failed", zap.Error(err), zap.String("id", id.String()))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "Failed to process E911 request"))
+	}
+}
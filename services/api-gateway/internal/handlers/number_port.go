@@ -0,0 +1,498 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/services"
+	"go.uber.org/zap"
+)
+
+// CheckPortability godoc
+// @Summary Pre-check numbers for porting
+// @Description Check whether numbers can be ported in, using SOA LERG/LSMS data
+// @Tags Porting
+// @Accept json
+// @Produce json
+// @Param request body models.PortabilityCheckRequest true "Numbers to check"
+// @Success 200 {object} models.APIResponse{data=[]models.PortabilityCheckResult}
+// @Failure 400 {object} models.APIResponse
+// @Failure 502 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /porting/projects/check [post]
+func (h *NumberHandler) CheckPortability(c *gin.Context) {
+	var req models.PortabilityCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	results, err := h.numberService.CheckPortability(c.Request.Context(), req.Numbers)
+	if err != nil {
+		h.logger.Error("Portability check failed", zap.Error(err))
+		c.JSON(http.StatusBadGateway, models.NewErrorResponse("SOA_ERROR", "Failed to check portability"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(gin.H{
+		"results": results,
+		"count":   len(results),
+	}))
+}
+
+// CreatePortRequest godoc
+// @Summary Create a port-in request
+// @Description Create a DRAFT port-in request; its numbers are pre-checked for portability
+// @Tags Porting
+// @Accept json
+// @Produce json
+// @Param request body models.CreatePortRequestRequest true "Port request"
+// @Success 201 {object} models.APIResponse{data=models.PortRequest}
+// @Failure 400 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /porting/projects [post]
+func (h *NumberHandler) CreatePortRequest(c *gin.Context) {
+	var req models.CreatePortRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	customerID, err := h.getCustomerID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.NewErrorResponse("CUSTOMER_ACCESS_ERROR", err.Error()))
+		return
+	}
+
+	createdBy, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	pr, err := h.numberService.CreatePortRequest(c.Request.Context(), &req, customerID, createdBy)
+	if err != nil {
+		h.logger.Error("Failed to create port request", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("CREATE_FAILED", "Failed to create port request"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse(pr))
+}
+
+// ListPortRequests godoc
+// @Summary List port-in requests
+// @Description Get port-in requests of accessible customers, newest first
+// @Tags Porting
+// @Accept json
+// @Produce json
+// @Param status query string false "DRAFT, SUBMITTED, FOC_RECEIVED, ACTIVATED, REJECTED, CANCELLED"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Success 200 {object} models.APIResponse{data=[]models.PortRequest}
+// @Failure 500 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /porting/projects [get]
+func (h *NumberHandler) ListPortRequests(c *gin.Context) {
+	h.listPortRequests(c, h.getCustomerFilter(c))
+}
+
+// AdminListPortRequests godoc
+// @Summary List all port-in requests
+// @Description Get port-in requests across all customers, newest first (Admin only)
+// @Tags Porting (Admin)
+// @Accept json
+// @Produce json
+// @Param status query string false "DRAFT, SUBMITTED, FOC_RECEIVED, ACTIVATED, REJECTED, CANCELLED"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Success 200 {object} models.APIResponse{data=[]models.PortRequest}
+// @Failure 500 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /admin/numbers/ports [get]
+func (h *NumberHandler) AdminListPortRequests(c *gin.Context) {
+	h.listPortRequests(c, nil)
+}
+
+func (h *NumberHandler) listPortRequests(c *gin.Context, customerFilter []uuid.UUID) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	requests, total, err := h.numberService.ListPortRequests(c.Request.Context(), customerFilter, c.Query("status"), page, perPage)
+	if err != nil {
+		h.logger.Error("Failed to list port requests", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("QUERY_FAILED", "Failed to retrieve port requests"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(gin.H{
+		"port_requests":  requests,
+		"total_elements": total,
+		"page":           page,
+		"size":           perPage,
+	}))
+}
+
+// GetPortRequest godoc
+// @Summary Get a port-in request
+// @Description Get a port-in request with its numbers, pre-check results and documents
+// @Tags Porting
+// @Accept json
+// @Produce json
+// @Param id path string true "Port request ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.PortRequest}
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /porting/projects/{id} [get]
+func (h *NumberHandler) GetPortRequest(c *gin.Context) {
+	id, ok := h.parsePortRequestID(c)
+	if !ok {
+		return
+	}
+
+	pr, err := h.numberService.GetPortRequest(c.Request.Context(), id, h.getCustomerFilter(c))
+	if err != nil {
+		h.writePortRequestError(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(pr))
+}
+
+// UpdatePortRequest godoc
+// @Summary Update a port-in request
+// @Description Change a DRAFT or REJECTED port-in request. Numbers, when given, replace the current numbers.
+// @Tags Porting
+// @Accept json
+// @Produce json
+// @Param id path string true "Port request ID (UUID)"
+// @Param request body models.UpdatePortRequestRequest true "Changes"
+// @Success 200 {object} models.APIResponse{data=models.PortRequest}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /porting/projects/{id} [patch]
+func (h *NumberHandler) UpdatePortRequest(c *gin.Context) {
+	id, ok := h.parsePortRequestID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdatePortRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	userID, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	pr, err := h.numberService.UpdatePortRequest(c.Request.Context(), id, &req, h.getCustomerFilter(c), userID)
+	if err != nil {
+		h.writePortRequestError(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(pr))
+}
+
+// AttachPortDocument godoc
+// @Summary Attach a port document
+// @Description Attach an LOA, CSR or bill copy (already uploaded to storage) to a port-in request
+// @Tags Porting
+// @Accept json
+// @Produce json
+// @Param id path string true "Port request ID (UUID)"
+// @Param request body models.AttachPortDocumentRequest true "Document"
+// @Success 201 {object} models.APIResponse{data=models.PortRequestDocument}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /porting/projects/{id}/documents [post]
+func (h *NumberHandler) AttachPortDocument(c *gin.Context) {
+	id, ok := h.parsePortRequestID(c)
+	if !ok {
+		return
+	}
+
+	var req models.AttachPortDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	userID, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	doc, err := h.numberService.AttachPortDocument(c.Request.Context(), id, &req, h.getCustomerFilter(c), userID)
+	if err != nil {
+		h.writePortRequestError(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse(doc))
+}
+
+// DeletePortDocument godoc
+// @Summary Remove a port document
+// @Description Remove a document from a DRAFT or REJECTED port-in request
+// @Tags Porting
+// @Accept json
+// @Produce json
+// @Param id path string true "Port request ID (UUID)"
+// @Param document_id path string true "Document ID (UUID)"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /porting/projects/{id}/documents/{document_id} [delete]
+func (h *NumberHandler) DeletePortDocument(c *gin.Context) {
+	id, ok := h.parsePortRequestID(c)
+	if !ok {
+		return
+	}
+
+	documentID, err := uuid.Parse(c.Param("document_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid document ID format"))
+		return
+	}
+
+	if err := h.numberService.DeletePortDocument(c.Request.Context(), id, documentID, h.getCustomerFilter(c)); err != nil {
+		h.writePortRequestError(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(gin.H{"message": "Document removed"}))
+}
+
+// SubmitPortRequest godoc
+// @Summary Submit a port-in request
+// @Description Submit a DRAFT or corrected REJECTED request. Requires an LOA; numbers are re-checked for portability.
+// @Tags Porting
+// @Accept json
+// @Produce json
+// @Param id path string true "Port request ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.PortRequest}
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 422 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /porting/projects/{id}/submit [post]
+func (h *NumberHandler) SubmitPortRequest(c *gin.Context) {
+	id, ok := h.parsePortRequestID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	pr, err := h.numberService.SubmitPortRequest(c.Request.Context(), id, h.getCustomerFilter(c), userID)
+	if err != nil {
+		h.writePortRequestError(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(pr))
+}
+
+// CancelPortRequest godoc
+// @Summary Cancel a port-in request
+// @Description Cancel a port-in request that has not been activated
+// @Tags Porting
+// @Accept json
+// @Produce json
+// @Param id path string true "Port request ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.PortRequest}
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /porting/projects/{id}/cancel [post]
+func (h *NumberHandler) CancelPortRequest(c *gin.Context) {
+	id, ok := h.parsePortRequestID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	pr, err := h.numberService.CancelPortRequest(c.Request.Context(), id, h.getCustomerFilter(c), userID)
+	if err != nil {
+		h.writePortRequestError(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(pr))
+}
+
+// RecordPortFOC godoc
+// @Summary Record port FOC
+// @Description Record the losing carrier's Firm Order Commitment date for a submitted port (Admin only)
+// @Tags Porting (Admin)
+// @Accept json
+// @Produce json
+// @Param id path string true "Port request ID (UUID)"
+// @Param request body models.RecordPortFOCRequest true "FOC details"
+// @Success 200 {object} models.APIResponse{data=models.PortRequest}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /admin/numbers/ports/{id}/foc [post]
+func (h *NumberHandler) RecordPortFOC(c *gin.Context) {
+	id, ok := h.parsePortRequestID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RecordPortFOCRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	userID, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	pr, err := h.numberService.RecordPortFOC(c.Request.Context(), id, &req, userID)
+	if err != nil {
+		h.writePortRequestError(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(pr))
+}
+
+// RejectPortRequest godoc
+// @Summary Reject a port-in request
+// @Description Record a losing carrier rejection; the customer may correct and resubmit (Admin only)
+// @Tags Porting (Admin)
+// @Accept json
+// @Produce json
+// @Param id path string true "Port request ID (UUID)"
+// @Param request body models.RejectPortRequestRequest true "Rejection reason"
+// @Success 200 {object} models.APIResponse{data=models.PortRequest}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /admin/numbers/ports/{id}/reject [post]
+func (h *NumberHandler) RejectPortRequest(c *gin.Context) {
+	id, ok := h.parsePortRequestID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RejectPortRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	userID, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	pr, err := h.numberService.RejectPortRequest(c.Request.Context(), id, req.Reason, userID)
+	if err != nil {
+		h.writePortRequestError(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(pr))
+}
+
+// ActivatePortRequest godoc
+// @Summary Activate a port-in request
+// @Description Complete a port on its FOC date: assign the numbers in SOA and create assigned numbers with the request's routing. Retrying activates only the numbers that failed. (Admin only)
+// @Tags Porting (Admin)
+// @Accept json
+// @Produce json
+// @Param id path string true "Port request ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.PortRequest}
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Failure 502 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /admin/numbers/ports/{id}/activate [post]
+func (h *NumberHandler) ActivatePortRequest(c *gin.Context) {
+	id, ok := h.parsePortRequestID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	pr, err := h.numberService.ActivatePortRequest(c.Request.Context(), id, userID)
+	if err != nil {
+		h.writePortRequestError(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(pr))
+}
+
+func (h *NumberHandler) parsePortRequestID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid port request ID format"))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *NumberHandler) writePortRequestError(c *gin.Context, err error, id uuid.UUID) {
+	switch {
+	case errors.Is(err, services.ErrPortRequestNotFound), errors.Is(err, services.ErrPortDocumentNotFound):
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("NOT_FOUND", err.Error()))
+	case errors.Is(err, services.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, models.NewErrorResponse("ACCESS_DENIED", "You don't have access to this port request"))
+	case errors.Is(err, services.ErrPortRequestInvalidState):
+		c.JSON(http.StatusConflict, models.NewErrorResponse("INVALID_STATE", err.Error()))
+	case errors.Is(err, services.ErrPortRequestIncomplete):
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("PORT_NOT_READY", err.Error()))
+	case errors.Is(err, services.ErrPortActivationIncomplete):
+		c.JSON(http.StatusBadGateway, models.NewErrorResponse("ACTIVATION_INCOMPLETE", err.Error()))
+	case errors.Is(err, services.ErrPortPINNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, models.NewErrorResponse("PIN_NOT_CONFIGURED", err.Error()))
+	default:
+		h.logger.Error("Port request operation failed", zap.Error(err), zap.String("port_request_id", id.String()))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "Failed to process port request"))
+	}
+}
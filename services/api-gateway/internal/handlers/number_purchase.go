@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/services"
+	"go.uber.org/zap"
+)
+
+// ListPurchaseIntents godoc
+// @Summary List number purchase intents
+// @Description List purchase saga records, e.g. status=REPAIR_FAILED for purchases needing an operator (Admin only)
+// @Tags Numbers (Admin)
+// @Accept json
+// @Produce json
+// @Param status query string false "PENDING, SOA_ASSIGNED, COMPLETED, FAILED, COMPENSATED, REPAIR, REPAIR_FAILED"
+// @Param limit query int false "Max results" default(100)
+// @Success 200 {object} models.APIResponse{data=[]models.NumberPurchaseIntent}
+// @Failure 500 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /admin/numbers/purchase-intents [get]
+func (h *NumberHandler) ListPurchaseIntents(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	intents, err := h.numberService.ListPurchaseIntents(c.Request.Context(), c.Query("status"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list purchase intents", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("QUERY_FAILED", "Failed to retrieve purchase intents"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(gin.H{
+		"intents": intents,
+		"count":   len(intents),
+	}))
+}
+
+// RetryPurchaseIntent godoc
+// @Summary Retry a failed purchase repair
+// @Description Requeue a REPAIR_FAILED purchase intent and attempt repair immediately (Admin only)
+// @Tags Numbers (Admin)
+// @Accept json
+// @Produce json
+// @Param id path string true "Purchase intent ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.NumberPurchaseIntent}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /admin/numbers/purchase-intents/{id}/retry [post]
+func (h *NumberHandler) RetryPurchaseIntent(c *gin.Context) {
+	intentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid purchase intent ID format"))
+		return
+	}
+
+	intent, err := h.numberService.RetryPurchaseIntent(c.Request.Context(), intentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPurchaseIntentNotFound):
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("NOT_FOUND", err.Error()))
+		case errors.Is(err, services.ErrPurchaseIntentNotRetryable):
+			c.JSON(http.StatusConflict, models.NewErrorResponse("NOT_RETRYABLE", err.Error()))
+		default:
+			h.logger.Error("Failed to retry purchase intent", zap.Error(err), zap.String("intent_id", intentID.String()))
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse("RETRY_FAILED", "Failed to retry purchase intent"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(intent))
+}
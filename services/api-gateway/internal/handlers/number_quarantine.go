@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/services"
+	"go.uber.org/zap"
+)
+
+// ListQuarantinedNumbers godoc
+// @Summary List quarantined numbers
+// @Description List released numbers still in their aging period, soonest release first. These can be restored until release_after.
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.APIResponse{data=[]models.NumberQuarantine}
+// @Failure 500 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/quarantine [get]
+func (h *NumberHandler) ListQuarantinedNumbers(c *gin.Context) {
+	quarantined, err := h.numberService.ListQuarantinedNumbers(c.Request.Context(), h.getCustomerFilter(c))
+	if err != nil {
+		h.logger.Error("Failed to list quarantined numbers", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "Failed to list quarantined numbers"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(quarantined))
+}
+
+// RestoreNumber godoc
+// @Summary Restore a released number
+// @Description Reactivate a number released within the quarantine period, with its routing, messaging campaign, E911 and CNAM unchanged. Only the customer that released it can restore it.
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Param id path string true "Number ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.AssignedNumber}
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/{id}/restore [post]
+func (h *NumberHandler) RestoreNumber(c *gin.Context) {
+	numberID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid number ID format"))
+		return
+	}
+
+	restoredBy, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	number, err := h.numberService.RestoreNumber(c.Request.Context(), numberID, h.getCustomerFilter(c), restoredBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNumberNotFound):
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("NOT_FOUND", "Number not found"))
+		case errors.Is(err, services.ErrAccessDenied):
+			c.JSON(http.StatusForbidden, models.NewErrorResponse("ACCESS_DENIED", "You don't have access to this number"))
+		case errors.Is(err, services.ErrNotQuarantined):
+			c.JSON(http.StatusConflict, models.NewErrorResponse("NOT_QUARANTINED", err.Error()))
+		case errors.Is(err, services.ErrNumberPortedOut):
+			c.JSON(http.StatusConflict, models.NewErrorResponse("PORTED_OUT", err.Error()))
+		default:
+			h.logger.Error("Failed to restore number", zap.Error(err), zap.String("number_id", numberID.String()))
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse("RESTORE_FAILED", "Failed to restore number"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(number))
+}
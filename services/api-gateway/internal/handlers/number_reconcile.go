@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/services"
+	"go.uber.org/zap"
+)
+
+// StartInventoryReconcile godoc
+// @Summary Reconcile SOA inventory
+// @Description Start a background reconciliation of our SPID's SOA inventory against assigned numbers (Admin only)
+// @Tags Numbers (Admin)
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "Record drift without changing any number"
+// @Success 202 {object} models.APIResponse{data=models.SOAReconcileRun}
+// @Failure 409 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /admin/numbers/reconcile [post]
+func (h *NumberHandler) StartInventoryReconcile(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	run, err := h.numberService.StartReconcile(c.Request.Context(), services.ReconcileTriggerManual, dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrReconcileInProgress):
+			c.JSON(http.StatusConflict, models.NewErrorResponse("RECONCILE_IN_PROGRESS", err.Error()))
+		case errors.Is(err, services.ErrReconcileNotConfigured):
+			c.JSON(http.StatusServiceUnavailable, models.NewErrorResponse("RECONCILE_NOT_CONFIGURED", err.Error()))
+		default:
+			h.logger.Error("Failed to start SOA reconciliation", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse("RECONCILE_FAILED", "Failed to start reconciliation"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.NewSuccessResponse(run))
+}
+
+// ListReconcileRuns godoc
+// @Summary List SOA reconciliation runs
+// @Description Get the history of SOA inventory reconciliation runs, newest first (Admin only)
+// @Tags Numbers (Admin)
+// @Accept json
+// @Produce json
+// @Param limit query int false "Max results" default(20)
+// @Success 200 {object} models.APIResponse{data=[]models.SOAReconcileRun}
+// @Failure 500 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /admin/numbers/reconcile/runs [get]
+func (h *NumberHandler) ListReconcileRuns(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	runs, err := h.numberService.ListReconcileRuns(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to list reconcile runs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("QUERY_FAILED", "Failed to retrieve reconciliation runs"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(gin.H{
+		"runs":  runs,
+		"count": len(runs),
+	}))
+}
+
+// GetDriftReport godoc
+// @Summary SOA drift report
+// @Description Get open drift counts by type and drift findings between SOA and assigned numbers (Admin only)
+// @Tags Numbers (Admin)
+// @Accept json
+// @Produce json
+// @Param status query string false "open (default) or all"
+// @Param type query string false "PORTED_OUT, NOT_IN_USE, OWNER_MISMATCH, LRN_MISMATCH, MISSING_LOCAL, MISSING_IN_SOA"
+// @Param limit query int false "Max findings" default(100)
+// @Success 200 {object} models.APIResponse{data=models.SOADriftReport}
+// @Failure 500 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /admin/numbers/reconcile/drift [get]
+func (h *NumberHandler) GetDriftReport(c *gin.Context) {
+	openOnly := c.DefaultQuery("status", "open") != "all"
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	report, err := h.numberService.GetDriftReport(c.Request.Context(), openOnly, c.Query("type"), limit)
+	if err != nil {
+		h.logger.Error("Failed to build drift report", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("QUERY_FAILED", "Failed to retrieve drift report"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(report))
+}
+
+// AcknowledgeDrift godoc
+// @Summary Acknowledge a drift finding
+// @Description Close a flagged drift finding after review (Admin only)
+// @Tags Numbers (Admin)
+// @Accept json
+// @Produce json
+// @Param id path string true "Drift finding ID (UUID)"
+// @Param request body models.AcknowledgeDriftRequest false "Resolution note"
+// @Success 200 {object} models.APIResponse{data=models.SOADrift}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /admin/numbers/reconcile/drift/{id}/acknowledge [post]
+func (h *NumberHandler) AcknowledgeDrift(c *gin.Context) {
+	driftID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid drift ID format"))
+		return
+	}
+
+	var req models.AcknowledgeDriftRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+			return
+		}
+	}
+
+	userID, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	drift, err := h.numberService.AcknowledgeDrift(c.Request.Context(), driftID, userID, req.Note)
+	if err != nil {
+		if errors.Is(err, services.ErrDriftNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("NOT_FOUND", err.Error()))
+			return
+		}
+		h.logger.Error("Failed to acknowledge drift", zap.Error(err), zap.String("drift_id", driftID.String()))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("UPDATE_FAILED", "Failed to acknowledge drift"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(drift))
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/services"
+	"go.uber.org/zap"
+)
+
+// SyncDIDRoutes godoc
+// @Summary Reconcile published DID routes
+// @Description Make the inbound DID routes in Redis match active, voice-enabled numbers: missing routes are published, changed routes rewritten and stale routes removed (Admin only)
+// @Tags Numbers (Admin)
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "Report drift without changing Redis"
+// @Success 200 {object} models.APIResponse{data=models.DIDRouteReconcileReport}
+// @Failure 500 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /admin/numbers/routes/sync [post]
+func (h *NumberHandler) SyncDIDRoutes(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	report, err := h.numberService.ReconcileDIDRoutes(c.Request.Context(), services.ReconcileTriggerManual, dryRun)
+	if err != nil {
+		if errors.Is(err, services.ErrRouteStoreNotConfigured) {
+			c.JSON(http.StatusServiceUnavailable, models.NewErrorResponse("ROUTES_NOT_CONFIGURED", err.Error()))
+			return
+		}
+		h.logger.Error("DID route reconciliation failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("RECONCILE_FAILED", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(report))
+}
+
+// GetDIDRouteSyncStatus godoc
+// @Summary Get the last DID route reconciliation
+// @Description Get the report of the most recent DID route reconciliation (Admin only)
+// @Tags Numbers (Admin)
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.APIResponse{data=models.DIDRouteReconcileReport}
+// @Failure 404 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /admin/numbers/routes/sync [get]
+func (h *NumberHandler) GetDIDRouteSyncStatus(c *gin.Context) {
+	report := h.numberService.LastDIDRouteReconcileReport()
+	if report == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("NOT_FOUND", "No DID route reconciliation has run yet"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(report))
+}
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/services"
+	"github.com/ringer-warp/api-gateway/internal/tollfree"
+	"go.uber.org/zap"
+)
+
+// SearchTollFreeNumbers godoc
+// @Summary Search toll-free numbers
+// @Description Search the SMS/800 registry for spare toll-free numbers, with list prices
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Param prefix query string false "Toll-free area code (800, 833, 844, 855, 866, 877, 888)"
+// @Param contains query string false "Digits or vanity letters after the prefix (e.g., FLOWERS)"
+// @Param quantity query int false "Number of results (1-100)" default(10)
+// @Success 200 {object} models.APIResponse{data=[]models.NumberSearchResult}
+// @Failure 400 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/toll-free/search [get]
+func (h *NumberHandler) SearchTollFreeNumbers(c *gin.Context) {
+	req := &models.SearchTollFreeRequest{
+		Prefix:   c.Query("prefix"),
+		Contains: c.Query("contains"),
+	}
+	if v := c.Query("quantity"); v != "" {
+		quantity, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", "quantity must be a number"))
+			return
+		}
+		req.Quantity = quantity
+	}
+
+	results, err := h.numberService.SearchTollFreeNumbers(c.Request.Context(), req)
+	if err != nil {
+		h.writeTollFreeError(c, err, uuid.Nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(results))
+}
+
+// ReserveTollFreeNumbers godoc
+// @Summary Reserve toll-free numbers
+// @Description Reserve spare toll-free numbers in the SMS/800 registry under our RespOrg. Each number is reserved or rejected on its own; reservations are held until provisioned or the registry hold lapses.
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Param request body models.ReserveTollFreeRequest true "Numbers to reserve"
+// @Success 200 {object} models.APIResponse{data=[]models.TollFreeReservationResult}
+// @Failure 400 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/toll-free/reserve [post]
+func (h *NumberHandler) ReserveTollFreeNumbers(c *gin.Context) {
+	var req models.ReserveTollFreeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	customerID, err := h.getCustomerID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.NewErrorResponse("CUSTOMER_ACCESS_ERROR", err.Error()))
+		return
+	}
+
+	createdBy, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	results, err := h.numberService.ReserveTollFreeNumbers(c.Request.Context(), &req, customerID, createdBy)
+	if err != nil {
+		h.writeTollFreeError(c, err, uuid.Nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(results))
+}
+
+// ProvisionTollFreeNumbers godoc
+// @Summary Provision toll-free numbers
+// @Description Activate reserved toll-free numbers in the SMS/800 registry and assign them to the customer. A number that failed part way is completed by provisioning it again.
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Param request body models.ProvisionTollFreeRequest true "Reserved numbers to provision"
+// @Success 201 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/toll-free/provision [post]
+func (h *NumberHandler) ProvisionTollFreeNumbers(c *gin.Context) {
+	var req models.ProvisionTollFreeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	customerID, err := h.getCustomerID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.NewErrorResponse("CUSTOMER_ACCESS_ERROR", err.Error()))
+		return
+	}
+
+	createdBy, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	provisioned, errs := h.numberService.ProvisionTollFreeNumbers(c.Request.Context(), &req, customerID, createdBy)
+
+	response := gin.H{
+		"numbers": provisioned,
+		"count":   len(provisioned),
+	}
+
+	if len(errs) > 0 {
+		errorMsgs := make([]string, len(errs))
+		for i, err := range errs {
+			errorMsgs[i] = err.Error()
+		}
+		response["errors"] = errorMsgs
+		response["partial"] = true
+	}
+
+	if len(provisioned) == 0 && len(errs) == 1 && errors.Is(errs[0], services.ErrTollFreeNotConfigured) {
+		h.writeTollFreeError(c, errs[0], uuid.Nil)
+		return
+	}
+
+	if len(provisioned) == 0 && len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("PROVISION_FAILED", "Failed to provision any toll-free numbers"))
+		return
+	}
+
+	h.logger.Info("Toll-free numbers provisioned",
+		zap.Int("count", len(provisioned)),
+		zap.String("customer_id", customerID.String()),
+	)
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse(response))
+}
+
+// ListTollFreeNumbers godoc
+// @Summary List toll-free numbers
+// @Description List the customer's toll-free reservations and numbers with their RespOrg and messaging verification status
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Param status query string false "RESERVED, ACTIVE, RELEASED or EXPIRED"
+// @Success 200 {object} models.APIResponse{data=[]models.TollFreeNumber}
+// @Failure 500 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/toll-free [get]
+func (h *NumberHandler) ListTollFreeNumbers(c *gin.Context) {
+	customerID, err := h.getCustomerID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.NewErrorResponse("CUSTOMER_ACCESS_ERROR", err.Error()))
+		return
+	}
+
+	numbers, err := h.numberService.ListTollFreeNumbers(c.Request.Context(), customerID, c.Query("status"))
+	if err != nil {
+		h.logger.Error("Failed to list toll-free numbers", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "Failed to list toll-free numbers"))
+		return
+	}
+	if numbers == nil {
+		numbers = []models.TollFreeNumber{}
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(numbers))
+}
+
+// GetNumberTollFree godoc
+// @Summary Get a number's toll-free record
+// @Description Get an assigned toll-free number's RespOrg, registry status and messaging verification status
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Param id path string true "Number ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.TollFreeNumber}
+// @Failure 404 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/{id}/toll-free [get]
+func (h *NumberHandler) GetNumberTollFree(c *gin.Context) {
+	numberID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid number ID format"))
+		return
+	}
+
+	tf, err := h.numberService.GetNumberTollFree(c.Request.Context(), numberID, h.getCustomerFilter(c))
+	if err != nil {
+		h.writeTollFreeError(c, err, numberID)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(tf))
+}
+
+// SubmitTollFreeVerification godoc
+// @Summary Submit toll-free messaging verification
+// @Description Submit an active toll-free number for messaging verification. The status moves from PENDING to VERIFIED or REJECTED; a rejected number may be resubmitted.
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Param id path string true "Number ID (UUID)"
+// @Param request body models.TollFreeVerificationRequest true "Business information"
+// @Success 202 {object} models.APIResponse{data=models.TollFreeNumber}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/{id}/toll-free/verification [post]
+func (h *NumberHandler) SubmitTollFreeVerification(c *gin.Context) {
+	numberID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid number ID format"))
+		return
+	}
+
+	var req models.TollFreeVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	updatedBy, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	tf, err := h.numberService.SubmitTollFreeVerification(c.Request.Context(), numberID, &req, h.getCustomerFilter(c), updatedBy)
+	if err != nil {
+		h.writeTollFreeError(c, err, numberID)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.NewSuccessResponse(tf))
+}
+
+// SyncTollFreeRegistry godoc
+// @Summary Refresh a toll-free number from the registry
+// @Description Read the number's registry status and controlling RespOrg from SMS/800 and store them
+// @Tags Numbers
+// @Accept json
+// @Produce json
+// @Param id path string true "Number ID (UUID)"
+// @Success 200 {object} models.APIResponse{data=models.TollFreeNumber}
+// @Failure 404 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /numbers/{id}/toll-free/sync [post]
+func (h *NumberHandler) SyncTollFreeRegistry(c *gin.Context) {
+	numberID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid number ID format"))
+		return
+	}
+
+	updatedBy, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	tf, err := h.numberService.SyncTollFreeRegistry(c.Request.Context(), numberID, h.getCustomerFilter(c), updatedBy)
+	if err != nil {
+		h.writeTollFreeError(c, err, numberID)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(tf))
+}
+
+// ChangeTollFreeRespOrg godoc
+// @Summary Change a toll-free number's RespOrg
+// @Description Move an active toll-free number to another of the platform's RespOrg IDs (Admin only)
+// @Tags Numbers (Admin)
+// @Accept json
+// @Produce json
+// @Param id path string true "Number ID (UUID)"
+// @Param request body models.ChangeRespOrgRequest true "Target RespOrg"
+// @Success 200 {object} models.APIResponse{data=models.TollFreeNumber}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /admin/numbers/{id}/toll-free/resporg [post]
+func (h *NumberHandler) ChangeTollFreeRespOrg(c *gin.Context) {
+	numberID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid number ID format"))
+		return
+	}
+
+	var req models.ChangeRespOrgRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	updatedBy, err := h.getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "User ID not found"))
+		return
+	}
+
+	tf, err := h.numberService.ChangeTollFreeRespOrg(c.Request.Context(), numberID, req.RespOrgID, updatedBy)
+	if err != nil {
+		h.writeTollFreeError(c, err, numberID)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(tf))
+}
+
+// writeTollFreeError maps toll-free service errors to responses
+func (h *NumberHandler) writeTollFreeError(c *gin.Context, err error, id uuid.UUID) {
+	switch {
+	case errors.Is(err, services.ErrNumberNotFound), errors.Is(err, services.ErrTollFreeNotFound):
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("NOT_FOUND", err.Error()))
+	case errors.Is(err, services.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, models.NewErrorResponse("ACCESS_DENIED", "You don't have access to this number"))
+	case errors.Is(err, services.ErrInvalidSearch), errors.Is(err, services.ErrNotTollFree), errors.Is(err, services.ErrInvalidRespOrg):
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+	case errors.Is(err, services.ErrTollFreeNotActive), errors.Is(err, services.ErrTollFreeVerificationInProgress),
+		errors.Is(err, tollfree.ErrNumberUnavailable):
+		c.JSON(http.StatusConflict, models.NewErrorResponse("INVALID_STATE", err.Error()))
+	case errors.Is(err, services.ErrTollFreeNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, models.NewErrorResponse("TOLL_FREE_NOT_CONFIGURED", err.Error()))
+	default:
+		h.logger.Error("Toll-free request failed", zap.Error(err), zap.String("id", id.String()))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", "Failed to process toll-free request"))
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -28,7 +29,7 @@ func NewNumberHandler(numberService *services.NumberService, logger *zap.Logger)
 
 // SearchNumbers godoc
 // @Summary Search available numbers
-// @Description Search for available telephone numbers from SOA inventory (JIT)
+// @Description Search for available telephone numbers from SOA inventory (JIT). Results are cached briefly and priced from the number rate table.
 // @Tags Numbers
 // @Accept json
 // @Produce json
@@ -37,8 +38,10 @@ func NewNumberHandler(numberService *services.NumberService, logger *zap.Logger)
 // @Param state query string false "State code (e.g., CO)"
 // @Param lata query string false "LATA number"
 // @Param rate_center query string false "Rate center name"
+// @Param contains query string false "Digits or vanity letters anywhere in the number (e.g., 4357 or HELP)"
+// @Param consecutive query int false "Find blocks of this many consecutive numbers (2-100)"
 // @Param page query int false "Page number" default(1)
-// @Param size query int false "Page size" default(50)
+// @Param size query int false "Page size, or number of blocks with consecutive" default(50)
 // @Success 200 {object} models.APIResponse{data=models.NumberSearchResponse}
 // @Failure 400 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
@@ -52,6 +55,15 @@ func (h *NumberHandler) SearchNumbers(c *gin.Context) {
 		State:      c.Query("state"),
 		LATA:       c.Query("lata"),
 		RateCenter: c.Query("rate_center"),
+		Contains:   c.Query("contains"),
+	}
+	if v := c.Query("consecutive"); v != "" {
+		consecutive, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", "consecutive must be a number"))
+			return
+		}
+		req.Consecutive = consecutive
 	}
 
 	// Pagination
@@ -69,6 +81,10 @@ func (h *NumberHandler) SearchNumbers(c *gin.Context) {
 	// Execute search
 	result, err := h.numberService.SearchAvailableNumbers(c.Request.Context(), req)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidSearch) {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_REQUEST", err.Error()))
+			return
+		}
 		h.logger.Error("Number search failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("SEARCH_FAILED", "Failed to search available numbers"))
 		return
@@ -184,6 +200,11 @@ func (h *NumberHandler) PurchaseNumbers(c *gin.Context) {
 		response["partial"] = true
 	}
 
+	if len(purchased) == 0 && len(errors) == 1 && isE911Error(errors[0]) {
+		h.writeE911Error(c, errors[0], uuid.Nil)
+		return
+	}
+
 	if len(purchased) == 0 && len(errors) > 0 {
 		c.JSON(http.StatusBadRequest, models.NewErrorResponse("PURCHASE_FAILED", "Failed to purchase any numbers"))
 		return
@@ -361,6 +382,14 @@ func (h *NumberHandler) UpdateNumber(c *gin.Context) {
 			c.JSON(http.StatusNotFound, models.NewErrorResponse("NOT_FOUND", "Number not found"))
 			return
 		}
+		if isE911Error(err) {
+			h.writeE911Error(c, err, numberID)
+			return
+		}
+		if isInvalidCNAM(err) {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_CNAM", err.Error()))
+			return
+		}
 		h.logger.Error("Failed to update number", zap.Error(err), zap.String("number_id", numberID.String()))
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("UPDATE_FAILED", "Failed to update number"))
 		return
@@ -371,7 +400,7 @@ func (h *NumberHandler) UpdateNumber(c *gin.Context) {
 
 // ReleaseNumber godoc
 // @Summary Release a number
-// @Description Release an assigned number back to the pool
+// @Description Release an assigned number back to the pool. Inbound routing stops immediately; if a quarantine period is configured the number is held and can be restored until it ends.
 // @Tags Numbers
 // @Accept json
 // @Produce json
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/repository"
+	"github.com/ringer-warp/api-gateway/internal/services"
+	"go.uber.org/zap"
+)
+
+// SMPPBindHandler manages customer SMPP system_ids and their live sessions.
+// Credentials live in PostgreSQL; sessions are read from (and unbound on) the SMPP gateway.
+type SMPPBindHandler struct {
+	bindService  *services.SMPPBindService
+	customerRepo *repository.CustomerRepository
+	proxy        *SMPPProxyHandler
+	logger       *zap.Logger
+}
+
+// NewSMPPBindHandler creates a new SMPPBindHandler instance
+func NewSMPPBindHandler(bindService *services.SMPPBindService, customerRepo *repository.CustomerRepository, proxy *SMPPProxyHandler, logger *zap.Logger) *SMPPBindHandler {
+	return &SMPPBindHandler{
+		bindService:  bindService,
+		customerRepo: customerRepo,
+		proxy:        proxy,
+		logger:       logger,
+	}
+}
+
+// CreateBind godoc
+// @Summary Create customer SMPP system_id
+// @Description Create an SMPP system_id with a generated password (returned only once)
+// @Tags SMPP
+// @Accept json
+// @Produce json
+// @Param customerId path string true "Customer ID or BAN"
+// @Param request body models.CreateSMPPBindRequest true "Bind settings"
+// @Success 201 {object} models.APIResponse{data=models.SMPPBindWithPassword}
+// @Security BearerAuth
+// @Router /v1/admin/customers/{customerId}/smpp-binds [post]
+func (h *SMPPBindHandler) CreateBind(c *gin.Context) {
+	customerID, ok := h.resolveCustomerID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateSMPPBindRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("VALIDATION_ERROR", err.Error()))
+		return
+	}
+
+	createdBy, _ := uuid.Parse(c.GetString("user_id"))
+
+	bind, err := h.bindService.CreateBind(c.Request.Context(), customerID, req, createdBy)
+	if err != nil {
+		h.writeServiceError(c, err, "Failed to create SMPP bind")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse(bind))
+}
+
+// ListBinds godoc
+// @Summary List customer SMPP system_ids
+// @Tags SMPP
+// @Produce json
+// @Param customerId path string true "Customer ID or BAN"
+// @Success 200 {object} models.APIResponse{data=[]models.SMPPBind}
+// @Security BearerAuth
+// @Router /v1/admin/customers/{customerId}/smpp-binds [get]
+func (h *SMPPBindHandler) ListBinds(c *gin.Context) {
+	customerID, ok := h.resolveCustomerID(c)
+	if !ok {
+		return
+	}
+
+	binds, err := h.bindService.ListBinds(c.Request.Context(), customerID)
+	if err != nil {
+		h.writeServiceError(c, err, "Failed to list SMPP binds")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(binds))
+}
+
+// GetBind godoc
+// @Summary Get customer SMPP system_id
+// @Tags SMPP
+// @Produce json
+// @Param customerId path string true "Customer ID or BAN"
+// @Param bind_id path string true "Bind ID"
+// @Success 200 {object} models.APIResponse{data=models.SMPPBind}
+// @Security BearerAuth
+// @Router /v1/admin/customers/{customerId}/smpp-binds/{bind_id} [get]
+func (h *SMPPBindHandler) GetBind(c *gin.Context) {
+	customerID, bindID, ok := h.resolveBindParams(c)
+	if !ok {
+		return
+	}
+
+	bind, err := h.bindService.GetBind(c.Request.Context(), bindID, customerID)
+	if err != nil {
+		h.writeServiceError(c, err, "Failed to get SMPP bind")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(bind))
+}
+
+// UpdateBind godoc
+// @Summary Update customer SMPP system_id
+// @Description Update allowed IPs, max binds, throughput or active status
+// @Tags SMPP
+// @Accept json
+// @Produce json
+// @Param customerId path string true "Customer ID or BAN"
+// @Param bind_id path string true "Bind ID"
+// @Param request body models.UpdateSMPPBindRequest true "Fields to update"
+// @Success 200 {object} models.APIResponse{data=models.SMPPBind}
+// @Security BearerAuth
+// @Router /v1/admin/customers/{customerId}/smpp-binds/{bind_id} [put]
+func (h *SMPPBindHandler) UpdateBind(c *gin.Context) {
+	customerID, bindID, ok := h.resolveBindParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSMPPBindRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("VALIDATION_ERROR", err.Error()))
+		return
+	}
+
+	bind, err := h.bindService.UpdateBind(c.Request.Context(), bindID, customerID, req)
+	if err != nil {
+		h.writeServiceError(c, err, "Failed to update SMPP bind")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(bind))
+}
+
+// RotatePassword godoc
+// @Summary Rotate SMPP password
+// @Description Generate a new password (returned only once). Live sessions stay bound until they reconnect.
+// @Tags SMPP
+// @Produce json
+// @Param customerId path string true "Customer ID or BAN"
+// @Param bind_id path string true "Bind ID"
+// @Success 200 {object} models.APIResponse{data=models.SMPPBindWithPassword}
+// @Security BearerAuth
+// @Router /v1/admin/customers/{customerId}/smpp-binds/{bind_id}/rotate-password [post]
+func (h *SMPPBindHandler) RotatePassword(c *gin.Context) {
+	customerID, bindID, ok := h.resolveBindParams(c)
+	if !ok {
+		return
+	}
+
+	bind, err := h.bindService.RotatePassword(c.Request.Context(), bindID, customerID)
+	if err != nil {
+		h.writeServiceError(c, err, "Failed to rotate SMPP password")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(bind))
+}
+
+// DisableBind godoc
+// @Summary Disable SMPP system_id
+// @Description Deactivate the system_id and unbind its live session on the SMPP gateway
+// @Tags SMPP
+// @Produce json
+// @Param customerId path string true "Customer ID or BAN"
+// @Param bind_id path string true "Bind ID"
+// @Success 200 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /v1/admin/customers/{customerId}/smpp-binds/{bind_id}/disable [post]
+func (h *SMPPBindHandler) DisableBind(c *gin.Context) {
+	customerID, bindID, ok := h.resolveBindParams(c)
+	if !ok {
+		return
+	}
+
+	bind, err := h.bindService.DisableBind(c.Request.Context(), bindID, customerID)
+	if err != nil {
+		h.writeServiceError(c, err, "Failed to disable SMPP bind")
+		return
+	}
+
+	// Kick any live session; 404 just means nothing was bound
+	path := fmt.Sprintf("/api/v1/admin/sessions/unbind/%s", url.PathEscape(bind.SystemID))
+	status, _, proxyErr := h.proxy.forwardRequest("POST", path, nil)
+	unbound := proxyErr == nil && status == http.StatusOK
+	if proxyErr != nil {
+		h.logger.Warn("Failed to unbind disabled SMPP session",
+			zap.String("system_id", bind.SystemID),
+			zap.String("error", proxyErr.message),
+		)
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(gin.H{
+		"bind":            bind,
+		"session_unbound": unbound,
+	}))
+}
+
+// ListSessions godoc
+// @Summary List live SMPP sessions for a customer
+// @Description Proxied from the SMPP gateway, filtered to the customer's system_ids
+// @Tags SMPP
+// @Produce json
+// @Param customerId path string true "Customer ID or BAN"
+// @Success 200 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /v1/admin/customers/{customerId}/smpp-sessions [get]
+func (h *SMPPBindHandler) ListSessions(c *gin.Context) {
+	customerID, ok := h.resolveCustomerID(c)
+	if !ok {
+		return
+	}
+
+	binds, err := h.bindService.ListBinds(c.Request.Context(), customerID)
+	if err != nil {
+		h.writeServiceError(c, err, "Failed to list SMPP binds")
+		return
+	}
+
+	if len(binds) == 0 {
+		c.JSON(http.StatusOK, gin.H{"success": true, "data": []interface{}{}, "count": 0})
+		return
+	}
+
+	systemIDs := make([]string, 0, len(binds))
+	for _, bind := range binds {
+		systemIDs = append(systemIDs, bind.SystemID)
+	}
+
+	path := "/api/v1/admin/sessions?system_id=" + url.QueryEscape(strings.Join(systemIDs, ","))
+	h.proxy.proxyRequest(c, "GET", path, nil)
+}
+
+// ForceUnbind godoc
+// @Summary Force-unbind a customer SMPP session
+// @Description Sends unbind to the customer's session on the SMPP gateway and closes the connection
+// @Tags SMPP
+// @Produce json
+// @Param customerId path string true "Customer ID or BAN"
+// @Param system_id path string true "SMPP system_id"
+// @Success 200 {object} models.APIResponse
+// @Security BearerAuth
+// @Router /v1/admin/customers/{customerId}/smpp-sessions/{system_id}/unbind [post]
+func (h *SMPPBindHandler) ForceUnbind(c *gin.Context) {
+	customerID, ok := h.resolveCustomerID(c)
+	if !ok {
+		return
+	}
+	systemID := c.Param("system_id")
+
+	binds, err := h.bindService.ListBinds(c.Request.Context(), customerID)
+	if err != nil {
+		h.writeServiceError(c, err, "Failed to list SMPP binds")
+		return
+	}
+
+	owned := false
+	for _, bind := range binds {
+		if bind.SystemID == systemID {
+			owned = true
+			break
+		}
+	}
+	if !owned {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("NOT_FOUND", "system_id not found for customer"))
+		return
+	}
+
+	path := fmt.Sprintf("/api/v1/admin/sessions/unbind/%s", url.PathEscape(systemID))
+	h.proxy.proxyRequest(c, "POST", path, nil)
+}
+
+// resolveCustomerID parses the customerId path param (UUID or BAN), writing an error response on failure
+func (h *SMPPBindHandler) resolveCustomerID(c *gin.Context) (uuid.UUID, bool) {
+	customerIDStr := c.Param("customerId")
+
+	customerID, err := uuid.Parse(customerIDStr)
+	if err != nil {
+		// If not a UUID, try to lookup by BAN
+		customer, err := h.customerRepo.GetByBAN(c.Request.Context(), customerIDStr)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("NOT_FOUND", "Customer not found"))
+			return uuid.Nil, false
+		}
+		customerID = customer.ID
+	}
+
+	return customerID, true
+}
+
+// resolveBindParams parses the customerId and bind_id path params
+func (h *SMPPBindHandler) resolveBindParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	customerID, ok := h.resolveCustomerID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	bindID, err := uuid.Parse(c.Param("bind_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("INVALID_ID", "Invalid bind ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return customerID, bindID, true
+}
+
+// writeServiceError maps SMPPBindService errors to HTTP responses
+func (h *SMPPBindHandler) writeServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrSMPPBindNotFound), errors.Is(err, services.ErrAccessDenied):
+		// Don't reveal binds belonging to other customers
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("NOT_FOUND", "SMPP bind not found"))
+	case errors.Is(err, services.ErrSystemIDTaken):
+		c.JSON(http.StatusConflict, models.NewErrorResponse("SYSTEM_ID_TAKEN", err.Error()))
+	case errors.Is(err, services.ErrInvalidAllowedIP):
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("VALIDATION_ERROR", err.Error()))
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("INTERNAL_ERROR", message))
+	}
+}
@@ -77,31 +77,45 @@ func (h *SMPPProxyHandler) GetStats(c *gin.Context) {
 
 // proxyRequest forwards request to SMPP Gateway
 func (h *SMPPProxyHandler) proxyRequest(c *gin.Context, method, path string, body io.Reader) {
+	statusCode, bodyBytes, err := h.forwardRequest(method, path, body)
+	if err != nil {
+		c.JSON(err.status, models.NewErrorResponse(err.code, err.message))
+		return
+	}
+
+	// Forward response with same status code
+	c.Data(statusCode, "application/json", bodyBytes)
+}
+
+// proxyError describes why a request could not be forwarded
+type proxyError struct {
+	status  int
+	code    string
+	message string
+}
+
+// forwardRequest sends a request to SMPP Gateway and returns its raw response
+func (h *SMPPProxyHandler) forwardRequest(method, path string, body io.Reader) (int, []byte, *proxyError) {
 	url := h.smppGatewayURL + path
 
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("PROXY_ERROR", "Failed to create request"))
-		return
+		return 0, nil, &proxyError{http.StatusInternalServerError, "PROXY_ERROR", "Failed to create request"}
 	}
 
 	// Forward request
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		c.JSON(http.StatusServiceUnavailable, models.NewErrorResponse("GATEWAY_UNAVAILABLE", "SMPP Gateway is not reachable"))
-		return
+		return 0, nil, &proxyError{http.StatusServiceUnavailable, "GATEWAY_UNAVAILABLE", "SMPP Gateway is not reachable"}
 	}
 	defer resp.Body.Close()
 
 	// Read response
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("PROXY_ERROR", "Failed to read response"))
-		return
+		return 0, nil, &proxyError{http.StatusInternalServerError, "PROXY_ERROR", "Failed to read response"}
 	}
 
-	// Forward response with same status code
-	c.Data(resp.StatusCode, "application/json", bodyBytes)
+	return resp.StatusCode, bodyBytes, nil
 }
-
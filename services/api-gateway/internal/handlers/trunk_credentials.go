@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/trunk"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// TRUNK DIGEST CREDENTIAL ENDPOINTS
+// ============================================================================
+// Admin:    /v1/admin/customers/{customerId}/trunks/{trunk_id}/credentials
+// Customer: /v1/customers/trunks/{trunk_id}/credentials
+
+// CreateTrunkCredential creates a SIP digest credential (Admin only)
+// POST /v1/admin/customers/{customerId}/trunks/{trunk_id}/credentials
+func (h *TrunkHandler) CreateTrunkCredential(c *gin.Context) {
+	if customerID, ok := h.adminCustomerID(c); ok {
+		h.createTrunkCredential(c, customerID)
+	}
+}
+
+// ListTrunkCredentials lists a trunk's digest credentials (Admin only)
+// GET /v1/admin/customers/{customerId}/trunks/{trunk_id}/credentials
+func (h *TrunkHandler) ListTrunkCredentials(c *gin.Context) {
+	if customerID, ok := h.adminCustomerID(c); ok {
+		h.listTrunkCredentials(c, customerID)
+	}
+}
+
+// RotateTrunkCredential generates a new password for a credential (Admin only)
+// POST /v1/admin/customers/{customerId}/trunks/{trunk_id}/credentials/{credential_id}/rotate
+func (h *TrunkHandler) RotateTrunkCredential(c *gin.Context) {
+	if customerID, ok := h.adminCustomerID(c); ok {
+		h.rotateTrunkCredential(c, customerID)
+	}
+}
+
+// RevokeTrunkCredential revokes a credential (Admin only)
+// DELETE /v1/admin/customers/{customerId}/trunks/{trunk_id}/credentials/{credential_id}
+func (h *TrunkHandler) RevokeTrunkCredential(c *gin.Context) {
+	if customerID, ok := h.adminCustomerID(c); ok {
+		h.revokeTrunkCredential(c, customerID)
+	}
+}
+
+// CustomerCreateTrunkCredential creates a digest credential on the customer's trunk
+// POST /v1/customers/trunks/{trunk_id}/credentials
+func (h *TrunkHandler) CustomerCreateTrunkCredential(c *gin.Context) {
+	if customerID, ok := selfCustomerID(c); ok {
+		h.createTrunkCredential(c, customerID)
+	}
+}
+
+// CustomerListTrunkCredentials lists the digest credentials on the customer's trunk
+// GET /v1/customers/trunks/{trunk_id}/credentials
+func (h *TrunkHandler) CustomerListTrunkCredentials(c *gin.Context) {
+	if customerID, ok := selfCustomerID(c); ok {
+		h.listTrunkCredentials(c, customerID)
+	}
+}
+
+// CustomerRotateTrunkCredential generates a new password for a credential on the customer's trunk
+// POST /v1/customers/trunks/{trunk_id}/credentials/{credential_id}/rotate
+func (h *TrunkHandler) CustomerRotateTrunkCredential(c *gin.Context) {
+	if customerID, ok := selfCustomerID(c); ok {
+		h.rotateTrunkCredential(c, customerID)
+	}
+}
+
+// CustomerRevokeTrunkCredential revokes a credential on the customer's trunk
+// DELETE /v1/customers/trunks/{trunk_id}/credentials/{credential_id}
+func (h *TrunkHandler) CustomerRevokeTrunkCredential(c *gin.Context) {
+	if customerID, ok := selfCustomerID(c); ok {
+		h.revokeTrunkCredential(c, customerID)
+	}
+}
+
+func (h *TrunkHandler) createTrunkCredential(c *gin.Context, customerID uuid.UUID) {
+	trunkID, ok := parseUUIDParam(c, "trunk_id", "Invalid trunk ID")
+	if !ok {
+		return
+	}
+
+	var req models.CreateTrunkCredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	createdBy, _ := uuid.Parse(c.GetString("user_id"))
+
+	cred, err := h.trunkService.CreateTrunkCredential(c.Request.Context(), trunkID, customerID, req, createdBy)
+	if err != nil {
+		writeTrunkCredentialError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Credential created successfully",
+		"credential": cred,
+		"note":       "Store the password now; it cannot be retrieved later",
+	})
+}
+
+func (h *TrunkHandler) listTrunkCredentials(c *gin.Context, customerID uuid.UUID) {
+	trunkID, ok := parseUUIDParam(c, "trunk_id", "Invalid trunk ID")
+	if !ok {
+		return
+	}
+
+	creds, err := h.trunkService.ListTrunkCredentials(c.Request.Context(), trunkID, customerID)
+	if err != nil {
+		writeTrunkCredentialError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"trunk_id":    trunkID,
+		"credentials": creds,
+	})
+}
+
+func (h *TrunkHandler) rotateTrunkCredential(c *gin.Context, customerID uuid.UUID) {
+	trunkID, ok := parseUUIDParam(c, "trunk_id", "Invalid trunk ID")
+	if !ok {
+		return
+	}
+	credID, ok := parseUUIDParam(c, "credential_id", "Invalid credential ID")
+	if !ok {
+		return
+	}
+
+	cred, err := h.trunkService.RotateTrunkCredential(c.Request.Context(), trunkID, credID, customerID)
+	if err != nil {
+		writeTrunkCredentialError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Credential password rotated",
+		"credential": cred,
+		"note":       "Update the password on your PBX; the old password stops working immediately",
+	})
+}
+
+func (h *TrunkHandler) revokeTrunkCredential(c *gin.Context, customerID uuid.UUID) {
+	trunkID, ok := parseUUIDParam(c, "trunk_id", "Invalid trunk ID")
+	if !ok {
+		return
+	}
+	credID, ok := parseUUIDParam(c, "credential_id", "Invalid credential ID")
+	if !ok {
+		return
+	}
+
+	if err := h.trunkService.RevokeTrunkCredential(c.Request.Context(), trunkID, credID, customerID); err != nil {
+		writeTrunkCredentialError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Credential revoked successfully"})
+}
+
+// adminCustomerID resolves the customerId path parameter (UUID or BAN)
+func (h *TrunkHandler) adminCustomerID(c *gin.Context) (uuid.UUID, bool) {
+	customerIDStr := c.Param("customerId")
+	if customerID, err := uuid.Parse(customerIDStr); err == nil {
+		return customerID, true
+	}
+
+	customer, err := h.customerRepo.GetByBAN(c.Request.Context(), customerIDStr)
+	if err != nil || customer == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return uuid.Nil, false
+	}
+	return customer.ID, true
+}
+
+// selfCustomerID returns the authenticated user's customer (set by Gatekeeper middleware)
+func selfCustomerID(c *gin.Context) (uuid.UUID, bool) {
+	customerID, err := uuid.Parse(c.GetString("customer_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "No customer context"})
+		return uuid.Nil, false
+	}
+	return customerID, true
+}
+
+// parseUUIDParam parses a UUID path parameter, answering 400 with message if invalid
+func parseUUIDParam(c *gin.Context, name, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// writeTrunkCredentialError maps trunk credential errors to HTTP statuses
+func writeTrunkCredentialError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, trunk.ErrTrunkAccessDenied), errors.Is(err, trunk.ErrCredentialNotFound),
+		errors.Is(err, trunk.ErrCustomerNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, trunk.ErrDigestNotAllowed), errors.Is(err, trunk.ErrInvalidCredentialUsername):
+		status = http.StatusBadRequest
+	case errors.Is(err, trunk.ErrCredentialUsernameTaken), errors.Is(err, trunk.ErrCredentialRevoked):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/trunk"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// TRUNK ROUTING (TERMINATION TARGET) ENDPOINTS
+// ============================================================================
+// Admin:    /v1/admin/customers/{customerId}/trunks/{trunk_id}/routing|targets
+// Customer: /v1/customers/trunks/{trunk_id}/routing|targets
+
+// GetTrunkRouting returns a trunk's targets and routing settings (Admin only)
+// GET /v1/admin/customers/{customerId}/trunks/{trunk_id}/routing
+func (h *TrunkHandler) GetTrunkRouting(c *gin.Context) {
+	if customerID, ok := h.adminCustomerID(c); ok {
+		h.getTrunkRouting(c, customerID)
+	}
+}
+
+// UpdateTrunkRoutingSettings updates failover and health settings (Admin only)
+// PUT /v1/admin/customers/{customerId}/trunks/{trunk_id}/routing/settings
+func (h *TrunkHandler) UpdateTrunkRoutingSettings(c *gin.Context) {
+	if customerID, ok := h.adminCustomerID(c); ok {
+		h.updateTrunkRoutingSettings(c, customerID)
+	}
+}
+
+// CreateTrunkTarget adds a termination target (Admin only)
+// POST /v1/admin/customers/{customerId}/trunks/{trunk_id}/targets
+func (h *TrunkHandler) CreateTrunkTarget(c *gin.Context) {
+	if customerID, ok := h.adminCustomerID(c); ok {
+		h.createTrunkTarget(c, customerID)
+	}
+}
+
+// UpdateTrunkTarget updates a termination target (Admin only)
+// PUT /v1/admin/customers/{customerId}/trunks/{trunk_id}/targets/{target_id}
+func (h *TrunkHandler) UpdateTrunkTarget(c *gin.Context) {
+	if customerID, ok := h.adminCustomerID(c); ok {
+		h.updateTrunkTarget(c, customerID)
+	}
+}
+
+// DeleteTrunkTarget removes a termination target (Admin only)
+// DELETE /v1/admin/customers/{customerId}/trunks/{trunk_id}/targets/{target_id}
+func (h *TrunkHandler) DeleteTrunkTarget(c *gin.Context) {
+	if customerID, ok := h.adminCustomerID(c); ok {
+		h.deleteTrunkTarget(c, customerID)
+	}
+}
+
+// CustomerGetTrunkRouting returns routing for the customer's trunk
+// GET /v1/customers/trunks/{trunk_id}/routing
+func (h *TrunkHandler) CustomerGetTrunkRouting(c *gin.Context) {
+	if customerID, ok := selfCustomerID(c); ok {
+		h.getTrunkRouting(c, customerID)
+	}
+}
+
+// CustomerUpdateTrunkRoutingSettings updates failover and health settings on the customer's trunk
+// PUT /v1/customers/trunks/{trunk_id}/routing/settings
+func (h *TrunkHandler) CustomerUpdateTrunkRoutingSettings(c *gin.Context) {
+	if customerID, ok := selfCustomerID(c); ok {
+		h.updateTrunkRoutingSettings(c, customerID)
+	}
+}
+
+// CustomerCreateTrunkTarget adds a termination target to the customer's trunk
+// POST /v1/customers/trunks/{trunk_id}/targets
+func (h *TrunkHandler) CustomerCreateTrunkTarget(c *gin.Context) {
+	if customerID, ok := selfCustomerID(c); ok {
+		h.createTrunkTarget(c, customerID)
+	}
+}
+
+// CustomerUpdateTrunkTarget updates a termination target on the customer's trunk
+// PUT /v1/customers/trunks/{trunk_id}/targets/{target_id}
+func (h *TrunkHandler) CustomerUpdateTrunkTarget(c *gin.Context) {
+	if customerID, ok := selfCustomerID(c); ok {
+		h.updateTrunkTarget(c, customerID)
+	}
+}
+
+// CustomerDeleteTrunkTarget removes a termination target from the customer's trunk
+// DELETE /v1/customers/trunks/{trunk_id}/targets/{target_id}
+func (h *TrunkHandler) CustomerDeleteTrunkTarget(c *gin.Context) {
+	if customerID, ok := selfCustomerID(c); ok {
+		h.deleteTrunkTarget(c, customerID)
+	}
+}
+
+func (h *TrunkHandler) getTrunkRouting(c *gin.Context, customerID uuid.UUID) {
+	trunkID, ok := parseUUIDParam(c, "trunk_id", "Invalid trunk ID")
+	if !ok {
+		return
+	}
+
+	routing, err := h.trunkService.GetTrunkRouting(c.Request.Context(), trunkID, customerID)
+	if err != nil {
+		writeTrunkRoutingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, routing)
+}
+
+func (h *TrunkHandler) updateTrunkRoutingSettings(c *gin.Context, customerID uuid.UUID) {
+	trunkID, ok := parseUUIDParam(c, "trunk_id", "Invalid trunk ID")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTrunkRoutingSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.trunkService.UpdateTrunkRoutingSettings(c.Request.Context(), trunkID, customerID, req)
+	if err != nil {
+		writeTrunkRoutingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *TrunkHandler) createTrunkTarget(c *gin.Context, customerID uuid.UUID) {
+	trunkID, ok := parseUUIDParam(c, "trunk_id", "Invalid trunk ID")
+	if !ok {
+		return
+	}
+
+	var req models.CreateTrunkTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	createdBy, _ := uuid.Parse(c.GetString("user_id"))
+
+	target, err := h.trunkService.CreateTrunkTarget(c.Request.Context(), trunkID, customerID, req, createdBy)
+	if err != nil {
+		writeTrunkRoutingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Trunk target added successfully",
+		"target":  target,
+	})
+}
+
+func (h *TrunkHandler) updateTrunkTarget(c *gin.Context, customerID uuid.UUID) {
+	trunkID, ok := parseUUIDParam(c, "trunk_id", "Invalid trunk ID")
+	if !ok {
+		return
+	}
+	targetID, ok := parseUUIDParam(c, "target_id", "Invalid target ID")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTrunkTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, err := h.trunkService.UpdateTrunkTarget(c.Request.Context(), trunkID, targetID, customerID, req)
+	if err != nil {
+		writeTrunkRoutingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Trunk target updated successfully",
+		"target":  target,
+	})
+}
+
+func (h *TrunkHandler) deleteTrunkTarget(c *gin.Context, customerID uuid.UUID) {
+	trunkID, ok := parseUUIDParam(c, "trunk_id", "Invalid trunk ID")
+	if !ok {
+		return
+	}
+	targetID, ok := parseUUIDParam(c, "target_id", "Invalid target ID")
+	if !ok {
+		return
+	}
+
+	if err := h.trunkService.DeleteTrunkTarget(c.Request.Context(), trunkID, targetID, customerID); err != nil {
+		writeTrunkRoutingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Trunk target deleted successfully"})
+}
+
+// writeTrunkRoutingError maps trunk routing errors to HTTP statuses
+func writeTrunkRoutingError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, trunk.ErrTrunkAccessDenied), errors.Is(err, trunk.ErrTrunkTargetNotFound),
+		errors.Is(err, trunk.ErrCustomerNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, trunk.ErrInvalidSIPURI), errors.Is(err, trunk.ErrTrunkTargetNotRoutable),
+		errors.Is(err, trunk.ErrTooManyTrunkTargets):
+		status = http.StatusBadRequest
+	case errors.Is(err, trunk.ErrTrunkTargetExists):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
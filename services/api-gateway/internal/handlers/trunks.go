@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/ringer-warp/api-gateway/internal/models"
@@ -244,7 +245,7 @@ func (h *TrunkHandler) AddTrunkIP(c *gin.Context) {
 
 	ip, err := h.trunkService.AddTrunkIP(c.Request.Context(), trunkID, customerID, req, createdBy)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeTrunkIPError(c, err)
 		return
 	}
 
@@ -474,7 +475,7 @@ func (h *TrunkHandler) CustomerAddTrunkIP(c *gin.Context) {
 
 	ip, err := h.trunkService.AddTrunkIP(c.Request.Context(), trunkID, customerID, req, createdBy)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeTrunkIPError(c, err)
 		return
 	}
 
@@ -551,16 +552,75 @@ func (h *TrunkHandler) GetCustomerIngressIPs(c *gin.Context) {
 	})
 }
 
-// SyncAllTrunkIPs manually triggers a full sync of trunk IPs to Redis (Admin only)
-// POST /v1/admin/trunks/sync-redis
+// SyncAllTrunkIPs reconciles the Kamailio address table in Redis with Postgres (Admin only)
+// POST /v1/admin/trunks/sync-redis?dry_run=true
 func (h *TrunkHandler) SyncAllTrunkIPs(c *gin.Context) {
-	err := h.trunkService.SyncAllTrunkIPsToRedis(c.Request.Context())
+	dryRun := c.Query("dry_run") == "true"
+
+	report, err := h.trunkService.ReconcileAddresses(c.Request.Context(), trunk.ReconcileTriggerManual, dryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":  err.Error(),
+			"report": report,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetTrunkIPSyncStatus returns the report of the last address table reconciliation (Admin only)
+// GET /v1/admin/trunks/sync-redis
+func (h *TrunkHandler) GetTrunkIPSyncStatus(c *gin.Context) {
+	report := h.trunkService.LastAddressReconcileReport()
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No reconciliation has run yet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetTrunkUtilization returns a customer's trunk limits and live usage (Admin only)
+// GET /v1/admin/customers/{customerId}/trunks/utilization
+func (h *TrunkHandler) GetTrunkUtilization(c *gin.Context) {
+	if customerID, ok := h.adminCustomerID(c); ok {
+		h.writeTrunkUtilization(c, customerID)
+	}
+}
+
+// CustomerGetTrunkUtilization returns the authenticated customer's trunk limits and live usage
+// GET /v1/customers/trunks/utilization
+func (h *TrunkHandler) CustomerGetTrunkUtilization(c *gin.Context) {
+	if customerID, ok := selfCustomerID(c); ok {
+		h.writeTrunkUtilization(c, customerID)
+	}
+}
+
+func (h *TrunkHandler) writeTrunkUtilization(c *gin.Context, customerID uuid.UUID) {
+	report, err := h.trunkService.GetTrunkUtilization(c.Request.Context(), customerID)
+	if errors.Is(err, trunk.ErrCustomerNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "All trunk IPs synced to Redis successfully",
-	})
+	c.JSON(http.StatusOK, report)
+}
+
+// writeTrunkIPError maps trunk ACL errors to HTTP statuses
+func writeTrunkIPError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, trunk.ErrInvalidTrunkIP), errors.Is(err, trunk.ErrTrunkIPNotRoutable):
+		status = http.StatusBadRequest
+	case errors.Is(err, trunk.ErrCustomerNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, trunk.ErrTrunkIPOverlap), errors.Is(err, trunk.ErrTrunkIPExists):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
 }
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NumberCNAMProvisioning is the CNAM/LIDB provider status of an assigned number
+type NumberCNAMProvisioning struct {
+	NumberID      uuid.UUID  `json:"number_id" db:"number_id"`
+	Number        string     `json:"number" db:"number"`
+	Action        string     `json:"action" db:"action"` // SET, REMOVE
+	DisplayName   *string    `json:"display_name,omitempty" db:"display_name"`
+	Status        string     `json:"status" db:"status"` // PENDING, PROVISIONED, REMOVED, FAILED
+	Provider      *string    `json:"provider,omitempty" db:"provider"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	LastError     *string    `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	ProvisionedAt *time.Time `json:"provisioned_at,omitempty" db:"provisioned_at"`
+	UpdatedBy     *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ============================================================================
+// Request Types
+// ============================================================================
+
+// CNAMUpdate is one number's change in a bulk CNAM update. Omitted fields
+// keep their current value.
+type CNAMUpdate struct {
+	NumberID    uuid.UUID `json:"number_id" binding:"required"`
+	Enabled     *bool     `json:"cnam_enabled"`
+	DisplayName *string   `json:"cnam_display_name"`
+}
+
+// BulkCNAMUpdateRequest represents a request to change CNAM on many numbers
+type BulkCNAMUpdateRequest struct {
+	Updates []CNAMUpdate `json:"updates" binding:"required,min=1,max=1000,dive"`
+}
+
+// CNAMUpdateResult is the outcome of one number in a bulk CNAM update
+type CNAMUpdateResult struct {
+	NumberID uuid.UUID `json:"number_id"`
+	Status   string    `json:"status"` // Provisioning status, or REJECTED
+	Error    string    `json:"error,omitempty"`
+}
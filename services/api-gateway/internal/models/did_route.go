@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DIDRouteEntry is the inbound routing of one active, voice-enabled number
+type DIDRouteEntry struct {
+	NumberID                 uuid.UUID
+	Number                   string
+	CustomerID               uuid.UUID
+	CustomerBAN              string
+	TrunkID                  *uuid.UUID
+	VoiceDestination         *string
+	VoiceFailoverDestination *string
+	VoiceRoutingType         *string
+}
+
+// DIDRouteReconcileReport summarizes one reconciliation of the DID routes
+// published in Redis against Postgres
+type DIDRouteReconcileReport struct {
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	DurationMs  int64     `json:"duration_ms"`
+	Trigger     string    `json:"trigger"` // "manual" or "periodic"
+	DryRun      bool      `json:"dry_run"`
+	Desired     int       `json:"desired"`  // Routes expected from Postgres
+	Existing    int       `json:"existing"` // Routes found in Redis
+	Added       int       `json:"added"`
+	Updated     int       `json:"updated"`
+	Removed     int       `json:"removed"`
+	Unchanged   int       `json:"unchanged"`
+	Drift       int       `json:"drift"` // Added + Updated + Removed
+	RemovedKeys []string  `json:"removed_keys,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
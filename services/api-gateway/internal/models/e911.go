@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// E911Address represents a customer's emergency service address (numbers.e911_addresses)
+type E911Address struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	CustomerID   uuid.UUID `json:"customer_id" db:"customer_id"`
+	Name         string    `json:"name" db:"name"`
+	AddressLine1 string    `json:"address_line1" db:"address_line1"`
+	AddressLine2 *string   `json:"address_line2,omitempty" db:"address_line2"`
+	City         string    `json:"city" db:"city"`
+	State        string    `json:"state" db:"state"`
+	PostalCode   string    `json:"postal_code" db:"postal_code"`
+	Country      string    `json:"country" db:"country"`
+	LocationInfo *string   `json:"location_info,omitempty" db:"location_info"` // Floor, suite, etc.
+
+	// Validation
+	ValidationStatus  string     `json:"validation_status" db:"validation_status"` // PENDING, VALIDATED, INVALID
+	ValidationMessage *string    `json:"validation_message,omitempty" db:"validation_message"`
+	MSAGValid         *bool      `json:"msag_valid,omitempty" db:"msag_valid"`
+	ValidatedAt       *time.Time `json:"validated_at,omitempty" db:"validation_date"`
+	Provider          *string    `json:"provider,omitempty" db:"provider"`
+	ProviderAddressID *string    `json:"provider_address_id,omitempty" db:"provider_address_id"`
+
+	// Audit
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// NumberE911Provisioning is the E911 provider status of an assigned number
+type NumberE911Provisioning struct {
+	NumberID      uuid.UUID  `json:"number_id" db:"number_id"`
+	AddressID     *uuid.UUID `json:"address_id,omitempty" db:"address_id"`
+	Status        string     `json:"status" db:"status"` // PROVISIONED, FAILED, DEPROVISIONED
+	Provider      string     `json:"provider" db:"provider"`
+	Error         *string    `json:"error,omitempty" db:"error"`
+	ProvisionedAt *time.Time `json:"provisioned_at,omitempty" db:"provisioned_at"`
+	UpdatedBy     *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ============================================================================
+// Request Types
+// ============================================================================
+
+// CreateE911AddressRequest represents a request to create an E911 address
+type CreateE911AddressRequest struct {
+	Name         string  `json:"name" binding:"required,max=255"`
+	AddressLine1 string  `json:"address_line1" binding:"required,max=255"`
+	AddressLine2 *string `json:"address_line2,omitempty" binding:"omitempty,max=255"`
+	City         string  `json:"city" binding:"required,max=100"`
+	State        string  `json:"state" binding:"required,len=2"`
+	PostalCode   string  `json:"postal_code" binding:"required,max=10"`
+	Country      string  `json:"country,omitempty" binding:"omitempty,len=2"` // Defaults to US
+	LocationInfo *string `json:"location_info,omitempty" binding:"omitempty,max=255"`
+}
+
+// UpdateE911AddressRequest represents changes to an E911 address; the
+// address is validated again and provisioned numbers are updated
+type UpdateE911AddressRequest struct {
+	Name         *string `json:"name,omitempty" binding:"omitempty,max=255"`
+	AddressLine1 *string `json:"address_line1,omitempty" binding:"omitempty,max=255"`
+	AddressLine2 *string `json:"address_line2,omitempty" binding:"omitempty,max=255"`
+	City         *string `json:"city,omitempty" binding:"omitempty,max=100"`
+	State        *string `json:"state,omitempty" binding:"omitempty,len=2"`
+	PostalCode   *string `json:"postal_code,omitempty" binding:"omitempty,max=10"`
+	LocationInfo *string `json:"location_info,omitempty" binding:"omitempty,max=255"`
+}
+
+// ProvisionE911Request represents a request to provision a number to an E911 address
+type ProvisionE911Request struct {
+	AddressID uuid.UUID `json:"address_id" binding:"required"`
+}
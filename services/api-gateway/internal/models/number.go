@@ -126,6 +126,12 @@ type SearchNumbersRequest struct {
 	RateCenter string `json:"rate_center" form:"rate_center"` // Rate center
 	Page       int    `json:"page" form:"page"`
 	Size       int    `json:"size" form:"size"`
+
+	// Contains matches digits anywhere in the number; letters are mapped to
+	// the phone keypad for vanity searches (e.g. "FLOWERS" -> 3569377)
+	Contains string `json:"contains,omitempty" form:"contains"`
+	// Consecutive, when set, returns blocks of this many sequential numbers
+	Consecutive int `json:"consecutive,omitempty" form:"consecutive"`
 }
 
 // ReserveNumberRequest represents a request to reserve a number
@@ -144,6 +150,162 @@ type PurchaseNumberRequest struct {
 	CampaignID   *uuid.UUID `json:"campaign_id"`
 }
 
+// CreateBulkOrderRequest represents a request to order numbers asynchronously.
+// Exactly one of Numbers or Search must be given; Quantity is required with Search.
+type CreateBulkOrderRequest struct {
+	Numbers  []string              `json:"numbers" binding:"omitempty,max=1000"` // E.164 format
+	Search   *SearchNumbersRequest `json:"search"`
+	Quantity int                   `json:"quantity" binding:"omitempty,min=1,max=1000"`
+
+	// Partial acceptance: false makes the order all-or-nothing
+	AcceptPartial *bool `json:"accept_partial"`
+
+	// Features applied to every purchased number
+	VoiceEnabled bool       `json:"voice_enabled"`
+	SMSEnabled   bool       `json:"sms_enabled"`
+	TrunkID      *uuid.UUID `json:"trunk_id"`
+	CampaignID   *uuid.UUID `json:"campaign_id"`
+}
+
+// BulkNumberOrder is an asynchronous number order and its progress
+type BulkNumberOrder struct {
+	ID             uuid.UUID             `json:"id" db:"id"`
+	CustomerID     uuid.UUID             `json:"customer_id" db:"customer_id"`
+	Status         string                `json:"status" db:"status"` // QUEUED, RUNNING, COMPLETED, PARTIAL, FAILED, CANCELLED
+	SearchCriteria *SearchNumbersRequest `json:"search_criteria,omitempty" db:"search_criteria"`
+	Quantity       int                   `json:"quantity" db:"quantity"`
+	AcceptPartial  bool                  `json:"accept_partial" db:"accept_partial"`
+
+	VoiceEnabled bool       `json:"voice_enabled" db:"voice_enabled"`
+	SMSEnabled   bool       `json:"sms_enabled" db:"sms_enabled"`
+	TrunkID      *uuid.UUID `json:"trunk_id,omitempty" db:"trunk_id"`
+	CampaignID   *uuid.UUID `json:"campaign_id,omitempty" db:"campaign_id"`
+
+	CancelRequested bool    `json:"cancel_requested" db:"cancel_requested"`
+	Error           *string `json:"error,omitempty" db:"error"`
+
+	// Item counts by status
+	Progress BulkOrderProgress `json:"progress"`
+
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	ReservedBy  *string    `json:"-" db:"reserved_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+
+	Items []BulkOrderItem `json:"items,omitempty"`
+}
+
+// BulkOrderProgress counts a bulk order's items by status
+type BulkOrderProgress struct {
+	Pending   int `json:"pending"`
+	Reserved  int `json:"reserved"`
+	Purchased int `json:"purchased"`
+	Skipped   int `json:"skipped"`
+	Failed    int `json:"failed"`
+	Cancelled int `json:"cancelled"`
+	Released  int `json:"released"`
+}
+
+// BulkOrderItem is one telephone number within a bulk order
+type BulkOrderItem struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	Number           string     `json:"number" db:"number"`
+	Status           string     `json:"status" db:"status"` // PENDING, RESERVED, PURCHASED, SKIPPED, FAILED, CANCELLED, RELEASED
+	AssignedNumberID *uuid.UUID `json:"assigned_number_id,omitempty" db:"assigned_number_id"`
+	Error            *string    `json:"error,omitempty" db:"error"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// NumberPurchaseIntent tracks one telephone number through the purchase saga
+// (see services.NumberService.PurchaseNumbers)
+type NumberPurchaseIntent struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	CustomerID uuid.UUID `json:"customer_id" db:"customer_id"`
+	Number     string    `json:"number" db:"number"`
+	Status     string    `json:"status" db:"status"` // PENDING, SOA_ASSIGNED, COMPLETED, FAILED, COMPENSATED, REPAIR, REPAIR_FAILED
+
+	// Requested configuration
+	VoiceEnabled bool       `json:"voice_enabled" db:"voice_enabled"`
+	SMSEnabled   bool       `json:"sms_enabled" db:"sms_enabled"`
+	TrunkID      *uuid.UUID `json:"trunk_id,omitempty" db:"trunk_id"`
+	CampaignID   *uuid.UUID `json:"campaign_id,omitempty" db:"campaign_id"`
+
+	SOANumberID      *string    `json:"soa_number_id,omitempty" db:"soa_number_id"`
+	AssignedNumberID *uuid.UUID `json:"assigned_number_id,omitempty" db:"assigned_number_id"`
+
+	// Repair tracking
+	Attempts      int        `json:"attempts" db:"attempts"`
+	LastError     *string    `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// SOAReconcileRun records one reconciliation of SOA inventory against
+// assigned numbers (see services.NumberService.ReconcileInventory)
+type SOAReconcileRun struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	Trigger      string     `json:"trigger" db:"trigger"` // "manual" or "periodic"
+	Status       string     `json:"status" db:"status"`   // RUNNING, COMPLETED, FAILED
+	DryRun       bool       `json:"dry_run" db:"dry_run"` // Findings recorded, numbers left unchanged
+	SOANumbers   int        `json:"soa_numbers" db:"soa_numbers"`
+	LocalNumbers int        `json:"local_numbers" db:"local_numbers"`
+	Matched      int        `json:"matched" db:"matched"`
+	AutoFixed    int        `json:"auto_fixed" db:"auto_fixed"`
+	Flagged      int        `json:"flagged" db:"flagged"`
+	Error        *string    `json:"error,omitempty" db:"error"`
+	StartedAt    time.Time  `json:"started_at" db:"started_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	DurationMs   *int64     `json:"duration_ms,omitempty" db:"duration_ms"`
+}
+
+// SOADrift is a difference between SOA inventory and an assigned number.
+// Open (unresolved) findings are deduplicated per number and drift type.
+type SOADrift struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	Number    string    `json:"number" db:"number"`
+	DriftType string    `json:"drift_type" db:"drift_type"` // PORTED_OUT, NOT_IN_USE, OWNER_MISMATCH, LRN_MISMATCH, MISSING_LOCAL, MISSING_IN_SOA
+	Action    string    `json:"action" db:"action"`         // AUTO_FIXED, FLAGGED
+
+	AssignedNumberID *uuid.UUID `json:"assigned_number_id,omitempty" db:"assigned_number_id"`
+	CustomerID       *uuid.UUID `json:"customer_id,omitempty" db:"customer_id"`
+
+	SOAStatus        *string `json:"soa_status,omitempty" db:"soa_status"`
+	SOAApplicationID *string `json:"soa_application_id,omitempty" db:"soa_application_id"`
+	SOALRN           *string `json:"soa_lrn,omitempty" db:"soa_lrn"`
+	SOAPortDirection *string `json:"soa_port_direction,omitempty" db:"soa_port_direction"`
+	Detail           string  `json:"detail" db:"detail"`
+
+	FirstSeenRunID uuid.UUID `json:"first_seen_run_id" db:"first_seen_run_id"`
+	LastSeenRunID  uuid.UUID `json:"last_seen_run_id" db:"last_seen_run_id"`
+	FirstSeenAt    time.Time `json:"first_seen_at" db:"first_seen_at"`
+	LastSeenAt     time.Time `json:"last_seen_at" db:"last_seen_at"`
+	Occurrences    int       `json:"occurrences" db:"occurrences"`
+
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	Resolution     *string    `json:"resolution,omitempty" db:"resolution"` // AUTO_FIXED, CLEARED, ACKNOWLEDGED
+	ResolvedBy     *uuid.UUID `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolutionNote *string    `json:"resolution_note,omitempty" db:"resolution_note"`
+}
+
+// SOADriftReport lists drift findings with open counts per drift type
+type SOADriftReport struct {
+	OpenByType map[string]int64 `json:"open_by_type"`
+	OpenTotal  int64            `json:"open_total"`
+	LastRun    *SOAReconcileRun `json:"last_run,omitempty"`
+	Drift      []SOADrift       `json:"drift"`
+}
+
+// AcknowledgeDriftRequest represents an operator closing a flagged drift finding
+type AcknowledgeDriftRequest struct {
+	Note string `json:"note"`
+}
+
 // NumberInventorySummary represents aggregate statistics for a customer's numbers
 type NumberInventorySummary struct {
 	CustomerID         uuid.UUID `json:"customer_id"`
@@ -164,16 +326,38 @@ type NumberSearchResult struct {
 	State           string  `json:"state"`
 	LATA            int     `json:"lata,omitempty"`
 	RateCenter      string  `json:"rate_center,omitempty"`
-	MonthlyRate     float64 `json:"monthly_rate,omitempty"`
+	NumberType      string  `json:"number_type"` // DID, TOLL_FREE
+	MonthlyRate     float64 `json:"monthly_rate"`
+	SetupFee        float64 `json:"setup_fee"`
+}
+
+// NumberBlock is a run of sequential available numbers found by a
+// consecutive-block search
+type NumberBlock struct {
+	FirstNumber string `json:"first_number"`
+	LastNumber  string `json:"last_number"`
+	Size        int    `json:"size"`
 }
 
 // NumberSearchResponse represents a paginated search response
 type NumberSearchResponse struct {
 	Numbers       []NumberSearchResult `json:"numbers"`
+	Blocks        []NumberBlock        `json:"blocks,omitempty"` // Consecutive-block searches only
 	TotalElements int64                `json:"total_elements"`
 	TotalPages    int                  `json:"total_pages"`
 	Page          int                  `json:"page"`
 	Size          int                  `json:"size"`
+	Cached        bool                 `json:"cached"`
+}
+
+// NumberRate is a list price for a number type (billing.number_rates).
+// State and RateCenter narrow a rate; the most specific match applies.
+type NumberRate struct {
+	NumberType  string  `json:"number_type" db:"number_type"`
+	State       *string `json:"state,omitempty" db:"state"`
+	RateCenter  *string `json:"rate_center,omitempty" db:"rate_center"`
+	MonthlyRate float64 `json:"monthly_rate" db:"monthly_rate"`
+	SetupFee    float64 `json:"setup_fee" db:"setup_fee"`
 }
 
 // NumberListResponse represents a paginated list of assigned numbers
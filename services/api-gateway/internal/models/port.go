@@ -0,0 +1,199 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PortRequest represents a port-in request (numbers.port_requests)
+type PortRequest struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	CustomerID uuid.UUID `json:"customer_id" db:"customer_id"`
+	PortType   string    `json:"port_type" db:"port_type"` // PORT_IN
+	Status     string    `json:"status" db:"status"`       // DRAFT, SUBMITTED, FOC_RECEIVED, ACTIVATED, REJECTED, CANCELLED
+
+	// Dates
+	RequestedFOCDate *time.Time `json:"requested_foc_date,omitempty" db:"requested_foc_date"`
+	ActualFOCDate    *time.Time `json:"actual_foc_date,omitempty" db:"actual_foc_date"`
+
+	// Carriers
+	LosingCarrier    *string `json:"losing_carrier,omitempty" db:"losing_carrier"`
+	LosingCarrierOCN *string `json:"losing_carrier_ocn,omitempty" db:"losing_carrier_ocn"`
+
+	// Authorization (must match the LOA)
+	AuthorizedPerson      string              `json:"authorized_person" db:"authorized_person"`
+	AuthorizedPersonTitle *string             `json:"authorized_person_title,omitempty" db:"authorized_person_title"`
+	EndUserName           *string             `json:"end_user_name,omitempty" db:"end_user_name"`
+	ServiceAddress        *PortServiceAddress `json:"service_address,omitempty" db:"service_address"`
+
+	// Routing applied to the numbers on activation
+	TrunkID      *uuid.UUID `json:"trunk_id,omitempty" db:"trunk_id"`
+	VoiceEnabled bool       `json:"voice_enabled" db:"voice_enabled"`
+	SMSEnabled   bool       `json:"sms_enabled" db:"sms_enabled"`
+
+	// Carrier order tracking
+	LSRID *string `json:"lsr_id,omitempty" db:"lsr_id"` // Local Service Request ID
+	PON   *string `json:"pon,omitempty" db:"pon"`       // Port Order Number
+
+	// Rejection handling
+	RejectionReason *string `json:"rejection_reason,omitempty" db:"rejection_reason"`
+	RejectionCount  int     `json:"rejection_count" db:"rejection_count"`
+
+	Notes *string `json:"notes,omitempty" db:"notes"`
+
+	// Audit
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	UpdatedBy     *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	SubmittedAt   *time.Time `json:"submitted_at,omitempty" db:"submitted_at"`
+	FOCReceivedAt *time.Time `json:"foc_received_at,omitempty" db:"foc_received_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+
+	Numbers   []PortRequestNumber   `json:"numbers,omitempty"`
+	Documents []PortRequestDocument `json:"documents,omitempty"`
+}
+
+// PortServiceAddress is the service address on the losing carrier's account
+type PortServiceAddress struct {
+	AddressLine1 string `json:"address_line1" binding:"required"`
+	AddressLine2 string `json:"address_line2,omitempty"`
+	City         string `json:"city" binding:"required"`
+	State        string `json:"state" binding:"required,len=2"`
+	PostalCode   string `json:"postal_code" binding:"required"`
+}
+
+// PortRequestNumber represents one telephone number in a port request
+type PortRequestNumber struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	PortRequestID uuid.UUID `json:"port_request_id" db:"port_request_id"`
+	Number        string    `json:"number" db:"number"` // E.164 format
+
+	// Losing carrier account details
+	IsBTN           bool    `json:"is_btn" db:"is_btn"`
+	BTN             *string `json:"btn,omitempty" db:"btn"`
+	CurrentProvider *string `json:"current_provider,omitempty" db:"current_provider"`
+	AccountNumber   *string `json:"account_number,omitempty" db:"account_number"`
+	PINSealed       *string `json:"-" db:"pin_passcode"` // Sealed with secrets.Cipher, never returned
+	PINOnFile       bool    `json:"pin_on_file" db:"-"`
+
+	// Portability pre-check
+	Portability       *string    `json:"portability,omitempty" db:"portability"` // PORTABLE, NOT_PORTABLE, UNVERIFIED
+	PortabilityReason *string    `json:"portability_reason,omitempty" db:"portability_reason"`
+	CurrentSPID       *string    `json:"current_spid,omitempty" db:"current_spid"`
+	CurrentLRN        *string    `json:"current_lrn,omitempty" db:"current_lrn"`
+	RateCenter        *string    `json:"rate_center,omitempty" db:"rate_center"`
+	State             *string    `json:"state,omitempty" db:"state"`
+	CheckedAt         *time.Time `json:"checked_at,omitempty" db:"checked_at"`
+
+	// Activation
+	PortedSuccessfully *bool      `json:"ported_successfully,omitempty" db:"ported_successfully"`
+	ErrorMessage       *string    `json:"error_message,omitempty" db:"error_message"`
+	AssignedNumberID   *uuid.UUID `json:"assigned_number_id,omitempty" db:"assigned_number_id"`
+	ActivatedAt        *time.Time `json:"activated_at,omitempty" db:"activated_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// PortRequestDocument represents an LOA, CSR or bill copy attached to a port request
+type PortRequestDocument struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	PortRequestID uuid.UUID  `json:"port_request_id" db:"port_request_id"`
+	DocumentType  string     `json:"document_type" db:"document_type"` // LOA, CSR, BILL, OTHER
+	FileName      string     `json:"file_name" db:"file_name"`
+	FileURL       string     `json:"file_url" db:"file_url"`
+	ContentType   *string    `json:"content_type,omitempty" db:"content_type"`
+	SizeBytes     *int64     `json:"size_bytes,omitempty" db:"size_bytes"`
+	UploadedBy    *uuid.UUID `json:"uploaded_by,omitempty" db:"uploaded_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PortabilityCheckResult is the pre-check outcome for one telephone number
+type PortabilityCheckResult struct {
+	Number      string  `json:"number"`
+	Portability string  `json:"portability"` // PORTABLE, NOT_PORTABLE, UNVERIFIED
+	Reason      string  `json:"reason,omitempty"`
+	CurrentSPID *string `json:"current_spid,omitempty"`
+	CurrentLRN  *string `json:"current_lrn,omitempty"`
+	RateCenter  *string `json:"rate_center,omitempty"`
+	State       *string `json:"state,omitempty"`
+}
+
+// ============================================================================
+// Request Types
+// ============================================================================
+
+// PortabilityCheckRequest represents a request to pre-check numbers for porting
+type PortabilityCheckRequest struct {
+	Numbers []string `json:"numbers" binding:"required,min=1,max=500"` // E.164 format
+}
+
+// PortNumberDetails carries losing carrier account details for the numbers in a request
+type PortNumberDetails struct {
+	BTN             *string `json:"btn,omitempty"`
+	CurrentProvider *string `json:"current_provider,omitempty"`
+	AccountNumber   *string `json:"account_number,omitempty"`
+	PINPasscode     *string `json:"pin_passcode,omitempty"`
+}
+
+// CreatePortRequestRequest represents a request to create a draft port-in request
+type CreatePortRequestRequest struct {
+	Numbers []string `json:"numbers" binding:"required,min=1,max=500"` // E.164 format
+	PortNumberDetails
+
+	RequestedFOCDate      *time.Time          `json:"requested_foc_date,omitempty"`
+	LosingCarrier         *string             `json:"losing_carrier,omitempty"`
+	AuthorizedPerson      string              `json:"authorized_person" binding:"required"`
+	AuthorizedPersonTitle *string             `json:"authorized_person_title,omitempty"`
+	EndUserName           *string             `json:"end_user_name,omitempty"`
+	ServiceAddress        *PortServiceAddress `json:"service_address,omitempty"`
+
+	TrunkID      *uuid.UUID `json:"trunk_id,omitempty"`
+	VoiceEnabled *bool      `json:"voice_enabled,omitempty"` // Defaults to true
+	SMSEnabled   bool       `json:"sms_enabled"`
+
+	Notes *string `json:"notes,omitempty"`
+}
+
+// UpdatePortRequestRequest represents changes to a DRAFT or REJECTED port request.
+// Numbers, when given, replace the request's numbers and their account details.
+type UpdatePortRequestRequest struct {
+	Numbers []string `json:"numbers,omitempty" binding:"omitempty,min=1,max=500"`
+	PortNumberDetails
+
+	RequestedFOCDate      *time.Time          `json:"requested_foc_date,omitempty"`
+	LosingCarrier         *string             `json:"losing_carrier,omitempty"`
+	AuthorizedPerson      *string             `json:"authorized_person,omitempty"`
+	AuthorizedPersonTitle *string             `json:"authorized_person_title,omitempty"`
+	EndUserName           *string             `json:"end_user_name,omitempty"`
+	ServiceAddress        *PortServiceAddress `json:"service_address,omitempty"`
+
+	TrunkID      *uuid.UUID `json:"trunk_id,omitempty"`
+	VoiceEnabled *bool      `json:"voice_enabled,omitempty"`
+	SMSEnabled   *bool      `json:"sms_enabled,omitempty"`
+
+	Notes *string `json:"notes,omitempty"`
+}
+
+// AttachPortDocumentRequest records a document uploaded to object storage
+type AttachPortDocumentRequest struct {
+	DocumentType string  `json:"document_type" binding:"required,oneof=LOA CSR BILL OTHER"`
+	FileName     string  `json:"file_name" binding:"required,max=255"`
+	FileURL      string  `json:"file_url" binding:"required,url"`
+	ContentType  *string `json:"content_type,omitempty"`
+	SizeBytes    *int64  `json:"size_bytes,omitempty"`
+}
+
+// RecordPortFOCRequest records the Firm Order Commitment from the losing carrier (admin)
+type RecordPortFOCRequest struct {
+	FOCDate          time.Time `json:"foc_date" binding:"required"`
+	PON              *string   `json:"pon,omitempty"`
+	LSRID            *string   `json:"lsr_id,omitempty"`
+	LosingCarrierOCN *string   `json:"losing_carrier_ocn,omitempty"`
+}
+
+// RejectPortRequestRequest records a rejection from the losing carrier (admin)
+type RejectPortRequestRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NumberQuarantine is a released number aging before it is returned to SOA
+type NumberQuarantine struct {
+	NumberID      uuid.UUID  `json:"number_id" db:"number_id"`
+	Number        string     `json:"number" db:"number"`
+	CustomerID    uuid.UUID  `json:"customer_id" db:"customer_id"`
+	Status        string     `json:"status" db:"status"` // QUARANTINE, RESTORED, RELEASED, FAILED
+	ReleaseReason *string    `json:"release_reason,omitempty" db:"release_reason"`
+	QuarantinedAt time.Time  `json:"quarantined_at" db:"quarantined_at"`
+	ReleaseAfter  time.Time  `json:"release_after" db:"release_after"` // Restorable until then
+	QuarantinedBy *uuid.UUID `json:"quarantined_by,omitempty" db:"quarantined_by"`
+	RestoredAt    *time.Time `json:"restored_at,omitempty" db:"restored_at"`
+	RestoredBy    *uuid.UUID `json:"restored_by,omitempty" db:"restored_by"`
+	ReleasedAt    *time.Time `json:"released_at,omitempty" db:"released_at"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	LastError     *string    `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
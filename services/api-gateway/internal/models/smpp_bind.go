@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SMPPBind represents a customer's SMPP system_id credential (messaging.customer_sms_auth)
+type SMPPBind struct {
+	ID                uuid.UUID  `json:"id" db:"id"`
+	CustomerID        uuid.UUID  `json:"customer_id" db:"customer_id"`
+	SystemID          string     `json:"system_id" db:"smpp_system_id"`
+	AllowedIPs        []string   `json:"allowed_ips" db:"smpp_allowed_ips"` // Empty = any source IP
+	MaxBinds          int        `json:"max_binds" db:"smpp_max_binds"`
+	Throughput        int        `json:"throughput" db:"smpp_throughput"` // msgs/sec
+	Active            bool       `json:"active" db:"active"`
+	PasswordRotatedAt time.Time  `json:"password_rotated_at" db:"password_rotated_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy         *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+}
+
+// SMPPBindWithPassword is returned once on create or rotate; the password is never stored in plaintext
+type SMPPBindWithPassword struct {
+	SMPPBind
+	Password string `json:"password"`
+}
+
+// CreateSMPPBindRequest represents the request to create a customer SMPP system_id
+type CreateSMPPBindRequest struct {
+	SystemID   string   `json:"system_id" binding:"omitempty,alphanum,min=3,max=15"` // Generated from the BAN if empty
+	AllowedIPs []string `json:"allowed_ips"`
+	MaxBinds   *int     `json:"max_binds,omitempty" binding:"omitempty,min=1,max=100"`
+	Throughput *int     `json:"throughput,omitempty" binding:"omitempty,min=1,max=10000"`
+}
+
+// UpdateSMPPBindRequest represents the request to update a customer SMPP system_id
+type UpdateSMPPBindRequest struct {
+	AllowedIPs *[]string `json:"allowed_ips,omitempty"`
+	MaxBinds   *int      `json:"max_binds,omitempty" binding:"omitempty,min=1,max=100"`
+	Throughput *int      `json:"throughput,omitempty" binding:"omitempty,min=1,max=10000"`
+	Active     *bool     `json:"active,omitempty"`
+}
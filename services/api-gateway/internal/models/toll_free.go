@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TollFreeNumber is a toll-free number reserved or provisioned under our
+// RespOrg in the SMS/800 registry
+type TollFreeNumber struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	CustomerID uuid.UUID  `json:"customer_id" db:"customer_id"`
+	Number     string     `json:"number" db:"number"`                 // E.164
+	NumberID   *uuid.UUID `json:"number_id,omitempty" db:"number_id"` // Assigned number while ACTIVE
+	Status     string     `json:"status" db:"status"`                 // RESERVED, ACTIVE, RELEASED, EXPIRED
+
+	// Registry
+	Provider          *string    `json:"provider,omitempty" db:"provider"`
+	RespOrgID         *string    `json:"resp_org_id,omitempty" db:"resp_org_id"`
+	RespOrgChangeDate *time.Time `json:"resp_org_change_date,omitempty" db:"resp_org_change_date"`
+	RegistryStatus    *string    `json:"registry_status,omitempty" db:"somos_status"` // SPARE, RESERVED, WORKING, ...
+	RegistryUpdatedAt *time.Time `json:"registry_updated_at,omitempty" db:"somos_last_updated"`
+	ReservedUntil     *time.Time `json:"reserved_until,omitempty" db:"reserved_until"`
+	LastError         *string    `json:"last_error,omitempty" db:"last_error"`
+
+	// Messaging verification
+	MessagingVerificationStatus string                       `json:"messaging_verification_status" db:"messaging_verification_status"` // NOT_SUBMITTED, PENDING, VERIFIED, REJECTED
+	MessagingVerificationID     *string                      `json:"messaging_verification_id,omitempty" db:"messaging_verification_id"`
+	MessagingVerification       *TollFreeVerificationRequest `json:"messaging_verification,omitempty" db:"messaging_verification"`
+	MessagingRejectionReason    *string                      `json:"messaging_rejection_reason,omitempty" db:"messaging_rejection_reason"`
+	MessagingSubmittedAt        *time.Time                   `json:"messaging_submitted_at,omitempty" db:"messaging_submitted_at"`
+	MessagingUpdatedAt          *time.Time                   `json:"messaging_updated_at,omitempty" db:"messaging_updated_at"`
+
+	// Audit
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+}
+
+// ============================================================================
+// Request Types
+// ============================================================================
+
+// SearchTollFreeRequest represents a search for spare toll-free numbers
+type SearchTollFreeRequest struct {
+	Prefix   string `json:"prefix,omitempty" form:"prefix"`     // 800, 833, 844, 855, 866, 877, 888
+	Contains string `json:"contains,omitempty" form:"contains"` // Digits or vanity letters
+	Quantity int    `json:"quantity,omitempty" form:"quantity"`
+}
+
+// ReserveTollFreeRequest represents a request to reserve toll-free numbers
+type ReserveTollFreeRequest struct {
+	Numbers []string `json:"numbers" binding:"required,min=1,max=100"` // E.164 format
+}
+
+// TollFreeReservationResult is the outcome of reserving one toll-free number
+type TollFreeReservationResult struct {
+	Number        string     `json:"number"`
+	Status        string     `json:"status"` // RESERVED, REJECTED
+	ReservedUntil *time.Time `json:"reserved_until,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// ProvisionTollFreeRequest represents a request to activate reserved
+// toll-free numbers on the customer's account
+type ProvisionTollFreeRequest struct {
+	Numbers []string `json:"numbers" binding:"required,min=1,max=100"` // E.164 format
+
+	// Optional initial configuration for all numbers
+	VoiceEnabled bool       `json:"voice_enabled"`
+	SMSEnabled   bool       `json:"sms_enabled"`
+	TrunkID      *uuid.UUID `json:"trunk_id"`
+}
+
+// TollFreeVerificationRequest is the business information submitted for
+// toll-free messaging verification
+type TollFreeVerificationRequest struct {
+	BusinessName     string `json:"business_name" binding:"required,max=255"`
+	BusinessWebsite  string `json:"business_website" binding:"required,url"`
+	ContactName      string `json:"contact_name" binding:"required,max=255"`
+	ContactEmail     string `json:"contact_email" binding:"required,email"`
+	ContactPhone     string `json:"contact_phone" binding:"required"`
+	UseCase          string `json:"use_case" binding:"required,max=100"` // e.g. 2FA, ACCOUNT_NOTIFICATIONS, MARKETING
+	UseCaseSummary   string `json:"use_case_summary" binding:"required,max=1000"`
+	SampleMessage    string `json:"sample_message" binding:"required,max=1000"`
+	OptInDescription string `json:"opt_in_description" binding:"required,max=1000"`
+	MonthlyVolume    int    `json:"monthly_volume" binding:"required,min=1"`
+}
+
+// ChangeRespOrgRequest represents a request to move a toll-free number to
+// another of our RespOrg IDs
+type ChangeRespOrgRequest struct {
+	RespOrgID string `json:"resp_org_id" binding:"required,len=5"`
+}
@@ -1,7 +1,6 @@
 package models
 
 import (
-	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,8 +25,10 @@ type TrunkGroup struct {
 type TrunkIP struct {
 	ID            uuid.UUID  `json:"id" db:"id"`
 	TrunkGroupID  uuid.UUID  `json:"trunk_group_id" db:"trunk_group_id"`
-	IPAddress     string     `json:"ip_address" db:"ip_address"` // INET type from PostgreSQL
-	Netmask       int        `json:"netmask" db:"netmask"`
+	IPAddress     string     `json:"ip_address" db:"ip_address"` // INET type from PostgreSQL (network address)
+	Netmask       int        `json:"netmask" db:"netmask"`       // Prefix length: 0-32 IPv4, 0-128 IPv6
+	Port          int        `json:"port" db:"port"`             // 0 = any port
+	Transport     string     `json:"transport" db:"transport"`   // any, udp, tcp, tls, sctp
 	Description   string     `json:"description" db:"description"`
 	Enabled       bool       `json:"enabled" db:"enabled"`
 	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
@@ -37,16 +38,22 @@ type TrunkIP struct {
 
 // CustomerDedicatedIP represents a premium tier dedicated LoadBalancer IP
 type CustomerDedicatedIP struct {
-	ID                  uuid.UUID      `json:"id" db:"id"`
-	CustomerID          uuid.UUID      `json:"customer_id" db:"customer_id"`
-	IPAddress           string         `json:"ip_address" db:"ip_address"`
-	Region              string         `json:"region" db:"region"`
-	GCPAddressName      sql.NullString `json:"gcp_address_name,omitempty" db:"gcp_address_name"`
-	GCPLoadBalancerName sql.NullString `json:"gcp_loadbalancer_name,omitempty" db:"gcp_loadbalancer_name"`
-	Status              string         `json:"status" db:"status"` // PROVISIONING, ACTIVE, DEPROVISIONING, DELETED
-	Enabled             bool           `json:"enabled" db:"enabled"`
-	CreatedAt           time.Time      `json:"created_at" db:"created_at"`
-	DeprovisionedAt     sql.NullTime   `json:"deprovisioned_at,omitempty" db:"deprovisioned_at"`
+	ID                  uuid.UUID  `json:"id" db:"id"`
+	CustomerID          uuid.UUID  `json:"customer_id" db:"customer_id"`
+	IPAddress           *string    `json:"ip_address,omitempty" db:"ip_address"` // Set once the provider allocates it
+	Region              string     `json:"region" db:"region"`
+	Provider            string     `json:"provider" db:"provider"`
+	GCPAddressName      *string    `json:"gcp_address_name,omitempty" db:"gcp_address_name"`
+	GCPLoadBalancerName *string    `json:"gcp_loadbalancer_name,omitempty" db:"gcp_loadbalancer_name"`
+	Status              string     `json:"status" db:"status"` // PROVISIONING, ACTIVE, DEPROVISIONING, DELETED, FAILED
+	Enabled             bool       `json:"enabled" db:"enabled"`
+	Attempts            int        `json:"attempts" db:"attempts"`
+	LastError           *string    `json:"last_error,omitempty" db:"last_error"`
+	RequestedBy         *uuid.UUID `json:"requested_by,omitempty" db:"requested_by"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
+	ActivatedAt         *time.Time `json:"activated_at,omitempty" db:"activated_at"`
+	DeprovisionedAt     *time.Time `json:"deprovisioned_at,omitempty" db:"deprovisioned_at"`
 }
 
 // TrunkGroupWithIPs represents a trunk group with its IP ACL entries
@@ -75,9 +82,13 @@ type UpdateTrunkGroupRequest struct {
 }
 
 // AddTrunkIPRequest represents the request to add an IP to a trunk's ACL
+// IPAddress may be an address ("203.0.113.10", "2001:db8::1") or a CIDR
+// ("203.0.113.0/28"); it is stored as the network address of the range.
 type AddTrunkIPRequest struct {
-	IPAddress   string  `json:"ip_address" binding:"required,ip"`
-	Netmask     *int    `json:"netmask,omitempty" binding:"omitempty,min=0,max=32"`
+	IPAddress   string  `json:"ip_address" binding:"required"`
+	Netmask     *int    `json:"netmask,omitempty" binding:"omitempty,min=0,max=128"`
+	Port        *int    `json:"port,omitempty" binding:"omitempty,min=0,max=65535"`
+	Transport   *string `json:"transport,omitempty" binding:"omitempty,oneof=any udp tcp tls sctp"`
 	Description string  `json:"description"`
 }
 
@@ -85,6 +96,8 @@ type AddTrunkIPRequest struct {
 type UpdateTrunkIPRequest struct {
 	Description *string `json:"description,omitempty"`
 	Enabled     *bool   `json:"enabled,omitempty"`
+	Port        *int    `json:"port,omitempty" binding:"omitempty,min=0,max=65535"`
+	Transport   *string `json:"transport,omitempty" binding:"omitempty,oneof=any udp tcp tls sctp"`
 }
 
 // TrunkIPListResponse represents a list of trunk IPs with customer context
@@ -94,9 +107,198 @@ type TrunkIPListResponse struct {
 	IPs          []TrunkIP  `json:"ips"`
 }
 
+// TrunkCredential represents a SIP digest credential for a DIGEST or BOTH trunk group.
+// Only HA1 hashes are stored; the password is returned once on create or rotate.
+type TrunkCredential struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	TrunkGroupID  uuid.UUID  `json:"trunk_group_id" db:"trunk_group_id"`
+	Username      string     `json:"username" db:"username"`
+	Realm         string     `json:"realm" db:"realm"`
+	Description   string     `json:"description" db:"description"`
+	Status        string     `json:"status" db:"status"` // ACTIVE, REVOKED
+	LastRotatedAt time.Time  `json:"last_rotated_at" db:"last_rotated_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+}
+
+// TrunkCredentialWithPassword is returned once on create or rotate
+type TrunkCredentialWithPassword struct {
+	TrunkCredential
+	Password string `json:"password"`
+}
+
+// CreateTrunkCredentialRequest represents the request to create a SIP digest credential
+type CreateTrunkCredentialRequest struct {
+	Username    string `json:"username" binding:"omitempty,min=4,max=64"` // Generated from the BAN if empty
+	Description string `json:"description"`
+}
+
 // VendorOriginationIPsResponse provides vendor egress IPs for customer documentation
 type VendorOriginationIPsResponse struct {
 	Region string   `json:"region"`
 	IPs    []string `json:"ips"`
 	Note   string   `json:"note"`
 }
+
+// TrunkAddressEntry is an enabled trunk IP with the customer it belongs to,
+// i.e. one row of the desired Kamailio permissions address table
+type TrunkAddressEntry struct {
+	IPID         uuid.UUID `json:"ip_id"`
+	TrunkGroupID uuid.UUID `json:"trunk_group_id"`
+	CustomerID   uuid.UUID `json:"customer_id"`
+	CustomerBAN  string    `json:"customer_ban"`
+	IPAddress    string    `json:"ip_address"`
+	Netmask      int       `json:"netmask"`
+	Port         int       `json:"port"`
+	Transport    string    `json:"transport"`
+}
+
+// TrunkCredentialEntry is an active credential on an enabled digest trunk
+// group, i.e. one row of the desired Kamailio auth_db subscriber table
+type TrunkCredentialEntry struct {
+	CredentialID uuid.UUID
+	TrunkGroupID uuid.UUID
+	CustomerBAN  string
+	Username     string
+	Realm        string
+	HA1          string
+	HA1B         string
+}
+
+// AddressReconcileReport summarizes one reconciliation of the Kamailio
+// address and subscriber tables in Redis against Postgres
+type AddressReconcileReport struct {
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	DurationMs  int64     `json:"duration_ms"`
+	Trigger     string    `json:"trigger"` // "manual" or "periodic"
+	DryRun      bool      `json:"dry_run"`
+	Desired     int       `json:"desired"`  // Entries expected from Postgres
+	Existing    int       `json:"existing"` // Entries found in Redis
+	Added       int       `json:"added"`
+	Updated     int       `json:"updated"`
+	Removed     int       `json:"removed"`
+	Unchanged   int       `json:"unchanged"`
+	Drift       int       `json:"drift"` // Added + Updated + Removed, addresses and subscribers
+	// GroupMembersFixed counts address:group:100 members added or removed
+	// without a matching entry change
+	GroupMembersFixed int      `json:"group_members_fixed"`
+	RemovedKeys       []string `json:"removed_keys,omitempty"`
+
+	// Subscriber (digest credential) entries
+	SubscribersDesired    int      `json:"subscribers_desired"`
+	SubscribersExisting   int      `json:"subscribers_existing"`
+	SubscribersAdded      int      `json:"subscribers_added"`
+	SubscribersUpdated    int      `json:"subscribers_updated"`
+	SubscribersRemoved    int      `json:"subscribers_removed"`
+	SubscribersUnchanged  int      `json:"subscribers_unchanged"`
+	RemovedSubscriberKeys []string `json:"removed_subscriber_keys,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// TrunkUtilization reports a trunk group's limits alongside the live usage
+// counted by the SIP edge
+type TrunkUtilization struct {
+	TrunkGroupID            uuid.UUID `json:"trunk_group_id"`
+	Name                    string    `json:"name"`
+	Enabled                 bool      `json:"enabled"`
+	CapacityCPS             int       `json:"capacity_cps"`
+	CapacityConcurrentCalls int       `json:"capacity_concurrent_calls"`
+	ActiveCalls             int64     `json:"active_calls"`
+	CurrentCPS              int64     `json:"current_cps"` // Last complete second
+	PeakCPS                 int64     `json:"peak_cps"`    // Highest second in the sample window
+	CallsUtilizationPct     float64   `json:"calls_utilization_pct"`
+	CPSUtilizationPct       float64   `json:"cps_utilization_pct"`
+}
+
+// CustomerTrunkUtilization reports a customer's aggregate (per-BAN) limits
+// and usage plus the breakdown per trunk group
+type CustomerTrunkUtilization struct {
+	CustomerBAN             string             `json:"customer_ban"`
+	CapacityCPS             int                `json:"capacity_cps"`
+	CapacityConcurrentCalls int                `json:"capacity_concurrent_calls"`
+	ActiveCalls             int64              `json:"active_calls"`
+	CurrentCPS              int64              `json:"current_cps"`
+	PeakCPS                 int64              `json:"peak_cps"`
+	CallsUtilizationPct     float64            `json:"calls_utilization_pct"`
+	CPSUtilizationPct       float64            `json:"cps_utilization_pct"`
+	WindowSeconds           int                `json:"window_seconds"`
+	SampledAt               time.Time          `json:"sampled_at"`
+	Trunks                  []TrunkUtilization `json:"trunks"`
+}
+
+// TrunkTarget is a SIP termination target inbound calls to a trunk are delivered to.
+// Targets are tried in ascending priority and load-balanced by weight within a priority.
+type TrunkTarget struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	TrunkGroupID uuid.UUID  `json:"trunk_group_id" db:"trunk_group_id"`
+	SIPURI       string     `json:"sip_uri" db:"sip_uri"`
+	Transport    string     `json:"transport" db:"transport"` // udp, tcp, tls
+	Priority     int        `json:"priority" db:"priority"`   // 1-100, lower first
+	Weight       int        `json:"weight" db:"weight"`       // 1-1000
+	Description  string     `json:"description" db:"description"`
+	Enabled      bool       `json:"enabled" db:"enabled"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+}
+
+// CreateTrunkTargetRequest represents the request to add a termination target
+type CreateTrunkTargetRequest struct {
+	SIPURI      string  `json:"sip_uri" binding:"required,max=255"`
+	Transport   *string `json:"transport,omitempty" binding:"omitempty,oneof=udp tcp tls"` // Default udp (tls for sips:)
+	Priority    *int    `json:"priority,omitempty" binding:"omitempty,min=1,max=100"`      // Default 10
+	Weight      *int    `json:"weight,omitempty" binding:"omitempty,min=1,max=1000"`       // Default 100
+	Description string  `json:"description"`
+}
+
+// UpdateTrunkTargetRequest represents the request to update a termination target
+type UpdateTrunkTargetRequest struct {
+	SIPURI      *string `json:"sip_uri,omitempty" binding:"omitempty,max=255"`
+	Transport   *string `json:"transport,omitempty" binding:"omitempty,oneof=udp tcp tls"`
+	Priority    *int    `json:"priority,omitempty" binding:"omitempty,min=1,max=100"`
+	Weight      *int    `json:"weight,omitempty" binding:"omitempty,min=1,max=1000"`
+	Description *string `json:"description,omitempty"`
+	Enabled     *bool   `json:"enabled,omitempty"`
+}
+
+// TrunkRoutingSettings holds failover and OPTIONS health settings for a trunk's targets
+type TrunkRoutingSettings struct {
+	TrunkGroupID             uuid.UUID  `json:"trunk_group_id" db:"trunk_group_id"`
+	FailoverResponseCodes    []int      `json:"failover_response_codes" db:"failover_response_codes"`
+	FailoverOnTimeout        bool       `json:"failover_on_timeout" db:"failover_on_timeout"`
+	InviteTimeoutSeconds     int        `json:"invite_timeout_seconds" db:"invite_timeout_seconds"`
+	OptionsEnabled           bool       `json:"options_enabled" db:"options_enabled"`
+	OptionsIntervalSeconds   int        `json:"options_interval_seconds" db:"options_interval_seconds"`
+	OptionsFailureThreshold  int        `json:"options_failure_threshold" db:"options_failure_threshold"`
+	OptionsRecoveryThreshold int        `json:"options_recovery_threshold" db:"options_recovery_threshold"`
+	UpdatedAt                *time.Time `json:"updated_at,omitempty" db:"updated_at"` // Nil while defaults apply
+}
+
+// UpdateTrunkRoutingSettingsRequest represents the request to change failover/health settings
+type UpdateTrunkRoutingSettingsRequest struct {
+	FailoverResponseCodes    []int `json:"failover_response_codes,omitempty" binding:"omitempty,max=20,dive,min=400,max=699"`
+	FailoverOnTimeout        *bool `json:"failover_on_timeout,omitempty"`
+	InviteTimeoutSeconds     *int  `json:"invite_timeout_seconds,omitempty" binding:"omitempty,min=2,max=60"`
+	OptionsEnabled           *bool `json:"options_enabled,omitempty"`
+	OptionsIntervalSeconds   *int  `json:"options_interval_seconds,omitempty" binding:"omitempty,min=10,max=300"`
+	OptionsFailureThreshold  *int  `json:"options_failure_threshold,omitempty" binding:"omitempty,min=1,max=10"`
+	OptionsRecoveryThreshold *int  `json:"options_recovery_threshold,omitempty" binding:"omitempty,min=1,max=10"`
+}
+
+// TrunkRouting is a trunk's full outbound (delivery) routing configuration
+type TrunkRouting struct {
+	TrunkGroupID uuid.UUID            `json:"trunk_group_id"`
+	Settings     TrunkRoutingSettings `json:"settings"`
+	Targets      []TrunkTarget        `json:"targets"`
+	Published    bool                 `json:"published"` // Resolved routing is live in Redis
+}
+
+// RequestDedicatedIPRequest represents an admin request for a dedicated ingress IP
+type RequestDedicatedIPRequest struct {
+	Region           string `json:"region" binding:"omitempty,min=3,max=50"` // Default us-central1
+	LoadBalancerName string `json:"loadbalancer_name" binding:"omitempty,max=100"`
+}
@@ -0,0 +1,26 @@
+// Package pgerr classifies errors returned by Postgres through pgx, so
+// services can map constraint violations to their own sentinel errors.
+package pgerr
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres error codes the services react to
+const (
+	UniqueViolation    = "23505"
+	ExclusionViolation = "23P01"
+)
+
+// Is reports whether err carries the given Postgres error code
+func Is(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
+
+// IsUniqueViolation reports whether err is a Postgres unique_violation
+func IsUniqueViolation(err error) bool {
+	return Is(err, UniqueViolation)
+}
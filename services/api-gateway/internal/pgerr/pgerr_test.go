@@ -0,0 +1,24 @@
+package pgerr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	if !IsUniqueViolation(fmt.Errorf("insert: %w", &pgconn.PgError{Code: UniqueViolation})) {
+		t.Error("unique_violation not detected")
+	}
+	if IsUniqueViolation(&pgconn.PgError{Code: "23503"}) {
+		t.Error("foreign_key_violation reported as unique_violation")
+	}
+	if IsUniqueViolation(errors.New(UniqueViolation)) {
+		t.Error("plain error reported as unique_violation")
+	}
+	if !Is(&pgconn.PgError{Code: ExclusionViolation}, ExclusionViolation) {
+		t.Error("exclusion_violation not detected")
+	}
+}
@@ -0,0 +1,69 @@
+// Package rediskeys supports reconcilers that keep a family of Redis keys
+// (one key per Postgres row, e.g. did:route:* or address:entry:*) in step
+// with the database: scanning the keys, reading them in batches and diffing
+// them against the desired set.
+package rediskeys
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Scan returns every key matching pattern, count at a time
+func Scan(ctx context.Context, client *redis.Client, pattern string, count int64) ([]string, error) {
+	var keys []string
+	iter := client.Scan(ctx, 0, pattern, count).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", pattern, err)
+	}
+	return keys, nil
+}
+
+// Batches splits keys into consecutive slices of at most size keys
+func Batches(keys []string, size int) [][]string {
+	batches := make([][]string, 0, (len(keys)+size-1)/size)
+	for start := 0; start < len(keys); start += size {
+		end := start + size
+		if end > len(keys) {
+			end = len(keys)
+		}
+		batches = append(batches, keys[start:end])
+	}
+	return batches
+}
+
+// Diff is the set of changes that makes the existing keys match the desired ones
+type Diff struct {
+	Added     []string // Desired, not in Redis
+	Updated   []string // In Redis with a different value
+	Unchanged []string
+	Removed   []string // In Redis, no longer desired
+}
+
+// Compare diffs the existing keys against the desired ones; same reports
+// whether an existing value already matches the desired value
+func Compare[E, D any](existing map[string]E, desired map[string]D, same func(E, D) bool) Diff {
+	var diff Diff
+	for key, want := range desired {
+		current, ok := existing[key]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, key)
+		case !same(current, want):
+			diff.Updated = append(diff.Updated, key)
+		default:
+			diff.Unchanged = append(diff.Unchanged, key)
+		}
+	}
+	for key := range existing {
+		if _, ok := desired[key]; !ok {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+	return diff
+}
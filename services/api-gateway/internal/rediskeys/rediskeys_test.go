@@ -0,0 +1,64 @@
+package rediskeys
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestBatches(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e"}
+
+	tests := []struct {
+		keys []string
+		size int
+		want [][]string
+	}{
+		{nil, 2, [][]string{}},
+		{keys, 2, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}},
+		{keys, 5, [][]string{keys}},
+		{keys, 10, [][]string{keys}},
+		{keys, 1, [][]string{{"a"}, {"b"}, {"c"}, {"d"}, {"e"}}},
+	}
+	for _, tt := range tests {
+		if got := Batches(tt.keys, tt.size); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Batches(%v, %d) = %v, want %v", tt.keys, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	existing := map[string]string{
+		"k:same":  "1",
+		"k:old":   "1",
+		"k:stale": "1",
+	}
+	desired := map[string]int{
+		"k:same": 1,
+		"k:old":  2,
+		"k:new":  3,
+	}
+	same := func(e string, d int) bool { return e == string(rune('0'+d)) }
+
+	diff := Compare(existing, desired, same)
+	for _, keys := range [][]string{diff.Added, diff.Updated, diff.Unchanged, diff.Removed} {
+		sort.Strings(keys)
+	}
+
+	want := Diff{
+		Added:     []string{"k:new"},
+		Updated:   []string{"k:old"},
+		Unchanged: []string{"k:same"},
+		Removed:   []string{"k:stale"},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("Compare() = %+v, want %+v", diff, want)
+	}
+}
+
+func TestCompareEmpty(t *testing.T) {
+	diff := Compare(map[string]string{}, map[string]string{}, func(a, b string) bool { return a == b })
+	if len(diff.Added)+len(diff.Updated)+len(diff.Unchanged)+len(diff.Removed) != 0 {
+		t.Errorf("Compare(empty) = %+v", diff)
+	}
+}
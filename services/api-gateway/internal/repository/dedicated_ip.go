@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ============================================================================
+// Customer Dedicated IPs
+// ============================================================================
+
+const dedicatedIPColumns = `
+	id, customer_id, host(ip_address), region, provider, gcp_address_name, gcp_loadbalancer_name,
+	status, enabled, attempts, last_error, requested_by, created_at, updated_at,
+	activated_at, deprovisioned_at
+`
+
+// CreateDedicatedIP stores a new dedicated IP request in PROVISIONING
+func (r *TrunkRepository) CreateDedicatedIP(ctx context.Context, ip *models.CustomerDedicatedIP) error {
+	query := `
+		INSERT INTO accounts.customer_dedicated_ips (
+			id, customer_id, region, provider, gcp_address_name, gcp_loadbalancer_name,
+			status, requested_by
+		) VALUES ($1, $2, $3, $4, $5, $6, 'PROVISIONING', $7)
+		RETURNING status, enabled, attempts, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		ip.ID, ip.CustomerID, ip.Region, ip.Provider, ip.GCPAddressName, ip.GCPLoadBalancerName,
+		ip.RequestedBy,
+	).Scan(&ip.Status, &ip.Enabled, &ip.Attempts, &ip.CreatedAt, &ip.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create dedicated IP: %w", err)
+	}
+
+	return nil
+}
+
+// GetDedicatedIP retrieves a dedicated IP by ID (nil if not found)
+func (r *TrunkRepository) GetDedicatedIP(ctx context.Context, id uuid.UUID) (*models.CustomerDedicatedIP, error) {
+	query := `SELECT ` + dedicatedIPColumns + ` FROM accounts.customer_dedicated_ips WHERE id = $1`
+
+	ip, err := scanDedicatedIP(r.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dedicated IP: %w", err)
+	}
+
+	return ip, nil
+}
+
+// ListDedicatedIPsByCustomer retrieves a customer's dedicated IPs, newest first
+func (r *TrunkRepository) ListDedicatedIPsByCustomer(ctx context.Context, customerID uuid.UUID) ([]models.CustomerDedicatedIP, error) {
+	query := `
+		SELECT ` + dedicatedIPColumns + `
+		FROM accounts.customer_dedicated_ips
+		WHERE customer_id = $1
+		ORDER BY created_at DESC
+	`
+	return r.queryDedicatedIPs(ctx, query, customerID)
+}
+
+// ListDedicatedIPsInProgress retrieves dedicated IPs waiting on the provider
+// (PROVISIONING or DEPROVISIONING), oldest first
+func (r *TrunkRepository) ListDedicatedIPsInProgress(ctx context.Context) ([]models.CustomerDedicatedIP, error) {
+	query := `
+		SELECT ` + dedicatedIPColumns + `
+		FROM accounts.customer_dedicated_ips
+		WHERE status IN ('PROVISIONING', 'DEPROVISIONING')
+		ORDER BY updated_at
+	`
+	return r.queryDedicatedIPs(ctx, query)
+}
+
+// ListActiveDedicatedIPs retrieves every ACTIVE dedicated IP with its customer BAN
+func (r *TrunkRepository) ListActiveDedicatedIPs(ctx context.Context) (map[string]string, error) {
+	query := `
+		SELECT host(d.ip_address), c.ban
+		FROM accounts.customer_dedicated_ips d
+		JOIN accounts.customers c ON c.id = d.customer_id
+		WHERE d.status = 'ACTIVE' AND d.enabled = true AND d.ip_address IS NOT NULL
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active dedicated IPs: %w", err)
+	}
+	defer rows.Close()
+
+	mappings := make(map[string]string)
+	for rows.Next() {
+		var ip, ban string
+		if err := rows.Scan(&ip, &ban); err != nil {
+			return nil, fmt.Errorf("failed to scan dedicated IP: %w", err)
+		}
+		mappings[ip] = ban
+	}
+
+	return mappings, rows.Err()
+}
+
+// TransitionDedicatedIP moves a dedicated IP from one status to another,
+// resetting attempts. It reports false if the row was not in the from status
+// (another worker or request got there first).
+func (r *TrunkRepository) TransitionDedicatedIP(ctx context.Context, id uuid.UUID, from, to string, ipAddress *string, lastError *string) (bool, error) {
+	query := `
+		UPDATE accounts.customer_dedicated_ips
+		SET status = $3,
+		    ip_address = COALESCE($4::inet, ip_address),
+		    last_error = $5,
+		    attempts = 0,
+		    enabled = $3 NOT IN ('DELETED', 'FAILED'),
+		    activated_at = CASE WHEN $3 = 'ACTIVE' THEN NOW() ELSE activated_at END,
+		    deprovisioned_at = CASE WHEN $3 = 'DELETED' THEN NOW() ELSE deprovisioned_at END
+		WHERE id = $1 AND status = $2
+	`
+
+	tag, err := r.db.Exec(ctx, query, id, from, to, ipAddress, lastError)
+	if err != nil {
+		return false, fmt.Errorf("failed to update dedicated IP status: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// RecordDedicatedIPAttempt counts a provider call for the current state and
+// records its error (nil clears it)
+func (r *TrunkRepository) RecordDedicatedIPAttempt(ctx context.Context, id uuid.UUID, lastError *string) error {
+	query := `
+		UPDATE accounts.customer_dedicated_ips
+		SET attempts = attempts + 1, last_error = $2
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, lastError); err != nil {
+		return fmt.Errorf("failed to record dedicated IP attempt: %w", err)
+	}
+	return nil
+}
+
+func (r *TrunkRepository) queryDedicatedIPs(ctx context.Context, query string, args ...interface{}) ([]models.CustomerDedicatedIP, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dedicated IPs: %w", err)
+	}
+	defer rows.Close()
+
+	ips := []models.CustomerDedicatedIP{}
+	for rows.Next() {
+		ip, err := scanDedicatedIP(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dedicated IP: %w", err)
+		}
+		ips = append(ips, *ip)
+	}
+
+	return ips, rows.Err()
+}
+
+// scanDedicatedIP scans one row selected with dedicatedIPColumns
+func scanDedicatedIP(row pgx.Row) (*models.CustomerDedicatedIP, error) {
+	ip := &models.CustomerDedicatedIP{}
+	err := row.Scan(
+		&ip.ID, &ip.CustomerID, &ip.IPAddress, &ip.Region, &ip.Provider, &ip.GCPAddressName,
+		&ip.GCPLoadBalancerName, &ip.Status, &ip.Enabled, &ip.Attempts, &ip.LastError,
+		&ip.RequestedBy, &ip.CreatedAt, &ip.UpdatedAt, &ip.ActivatedAt, &ip.DeprovisionedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return ip, nil
+}
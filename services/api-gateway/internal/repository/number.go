@@ -92,6 +92,77 @@ func (r *NumberRepository) Create(ctx context.Context, number *models.AssignedNu
 	return result, nil
 }
 
+// ReassignReleased reuses a released number's row for a new assignment,
+// replacing its configuration with number's. It returns nil if the row is
+// active or still in quarantine.
+func (r *NumberRepository) ReassignReleased(ctx context.Context, id uuid.UUID, number *models.AssignedNumber) (*models.AssignedNumber, error) {
+	query := `
+		UPDATE numbers.assigned_numbers
+		SET customer_id = $2, soa_number_id = $3, soa_sync_status = $4,
+		    number_type = $5, npa = $6, nxx = $7, rate_center = $8, state = $9,
+		    voice_enabled = $10, sms_enabled = $11, mms_enabled = $12, fax_enabled = $13,
+		    trunk_id = $14, voice_destination = $15, voice_failover_destination = $16, voice_routing_type = $17,
+		    campaign_id = $18, brand_id = $19, tcr_status = $20,
+		    e911_enabled = $21, e911_address_id = $22,
+		    cnam_enabled = $23, cnam_display_name = $24,
+		    friendly_name = $25, description = $26,
+		    active = true, monthly_charge = $27, billing_start_date = $28,
+		    activated_at = NOW(), released_at = NULL, release_reason = NULL,
+		    updated_by = $29
+		WHERE id = $1 AND active = false
+		  AND NOT EXISTS (
+		      SELECT 1 FROM numbers.number_quarantine q
+		      WHERE q.number_id = $1 AND q.status = 'QUARANTINE'
+		  )
+		RETURNING id, customer_id, number, soa_number_id, soa_last_synced, soa_sync_status,
+		          number_type, npa, nxx, rate_center, state,
+		          voice_enabled, sms_enabled, mms_enabled, fax_enabled,
+		          trunk_id, voice_destination, voice_failover_destination, voice_routing_type,
+		          campaign_id, brand_id, tcr_status,
+		          e911_enabled, e911_address_id,
+		          cnam_enabled, cnam_display_name,
+		          friendly_name, description,
+		          active, monthly_charge, billing_start_date,
+		          activated_at, released_at, release_reason,
+		          created_at, updated_at, created_by, updated_by
+	`
+
+	result := &models.AssignedNumber{}
+	err := r.db.QueryRow(ctx, query,
+		id, number.CustomerID, number.SOANumberID, coalesce(number.SOASyncStatus, "SYNCED"),
+		coalesce(number.NumberType, "DID"), number.NPA, number.NXX, number.RateCenter, number.State,
+		number.VoiceEnabled, number.SMSEnabled, number.MMSEnabled, number.FaxEnabled,
+		number.TrunkID, number.VoiceDestination, number.VoiceFailoverDestination, number.VoiceRoutingType,
+		number.CampaignID, number.BrandID, number.TCRStatus,
+		number.E911Enabled, number.E911AddressID,
+		number.CNAMEnabled, number.CNAMDisplayName,
+		number.FriendlyName, number.Description,
+		number.MonthlyCharge, number.BillingStartDate,
+		number.CreatedBy,
+	).Scan(
+		&result.ID, &result.CustomerID, &result.Number, &result.SOANumberID, &result.SOALastSynced, &result.SOASyncStatus,
+		&result.NumberType, &result.NPA, &result.NXX, &result.RateCenter, &result.State,
+		&result.VoiceEnabled, &result.SMSEnabled, &result.MMSEnabled, &result.FaxEnabled,
+		&result.TrunkID, &result.VoiceDestination, &result.VoiceFailoverDestination, &result.VoiceRoutingType,
+		&result.CampaignID, &result.BrandID, &result.TCRStatus,
+		&result.E911Enabled, &result.E911AddressID,
+		&result.CNAMEnabled, &result.CNAMDisplayName,
+		&result.FriendlyName, &result.Description,
+		&result.Active, &result.MonthlyCharge, &result.BillingStartDate,
+		&result.ActivatedAt, &result.ReleasedAt, &result.ReleaseReason,
+		&result.CreatedAt, &result.UpdatedAt, &result.CreatedBy, &result.UpdatedBy,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reassign released number: %w", err)
+	}
+
+	return result, nil
+}
+
 // GetByID retrieves an assigned number by its ID
 func (r *NumberRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AssignedNumber, error) {
 	query := `
@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ringer-warp/api-gateway/internal/models"
+)
+
+// ============================================================================
+// Bulk Number Orders
+// ============================================================================
+
+const bulkOrderColumns = `
+	o.id, o.customer_id, o.status, o.search_criteria, o.quantity, o.accept_partial,
+	o.voice_enabled, o.sms_enabled, o.trunk_id, o.campaign_id,
+	o.cancel_requested, o.error, o.created_by, o.reserved_by,
+	o.created_at, o.updated_at, o.started_at, o.completed_at
+`
+
+// bulkOrderWithProgress selects orders with their item counts by status
+const bulkOrderWithProgress = `
+	SELECT ` + bulkOrderColumns + `,
+	       p.pending, p.reserved, p.purchased, p.skipped, p.failed, p.cancelled, p.released
+	FROM numbers.bulk_orders o
+	LEFT JOIN LATERAL (
+		SELECT COUNT(*) FILTER (WHERE status = 'PENDING')   AS pending,
+		       COUNT(*) FILTER (WHERE status = 'RESERVED')  AS reserved,
+		       COUNT(*) FILTER (WHERE status = 'PURCHASED') AS purchased,
+		       COUNT(*) FILTER (WHERE status = 'SKIPPED')   AS skipped,
+		       COUNT(*) FILTER (WHERE status = 'FAILED')    AS failed,
+		       COUNT(*) FILTER (WHERE status = 'CANCELLED') AS cancelled,
+		       COUNT(*) FILTER (WHERE status = 'RELEASED')  AS released
+		FROM numbers.bulk_order_items
+		WHERE order_id = o.id
+	) p ON true
+`
+
+const bulkOrderItemColumns = `id, number, status, assigned_number_id, error, updated_at`
+
+// CreateBulkOrder stores a QUEUED order and, for explicit orders, its items
+func (r *NumberRepository) CreateBulkOrder(ctx context.Context, order *models.BulkNumberOrder, numbers []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO numbers.bulk_orders AS o (
+			customer_id, search_criteria, quantity, accept_partial,
+			voice_enabled, sms_enabled, trunk_id, campaign_id,
+			created_by, reserved_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + bulkOrderColumns
+
+	created, err := scanBulkOrder(tx.QueryRow(ctx, query,
+		order.CustomerID, order.SearchCriteria, order.Quantity, order.AcceptPartial,
+		order.VoiceEnabled, order.SMSEnabled, order.TrunkID, order.CampaignID,
+		order.CreatedBy, order.ReservedBy,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to create bulk order: %w", err)
+	}
+
+	if len(numbers) > 0 {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO numbers.bulk_order_items (order_id, number)
+			SELECT $1, unnest($2::varchar[])
+			ON CONFLICT (order_id, number) DO NOTHING
+		`, created.ID, numbers)
+		if err != nil {
+			return fmt.Errorf("failed to create bulk order items: %w", err)
+		}
+		created.Progress.Pending = len(numbers)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit bulk order: %w", err)
+	}
+
+	*order = *created
+	return nil
+}
+
+// GetBulkOrder retrieves an order with its progress (nil if not found)
+func (r *NumberRepository) GetBulkOrder(ctx context.Context, id uuid.UUID) (*models.BulkNumberOrder, error) {
+	order, err := scanBulkOrderWithProgress(r.db.QueryRow(ctx, bulkOrderWithProgress+` WHERE o.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bulk order: %w", err)
+	}
+	return order, nil
+}
+
+// ListBulkOrdersByCustomer retrieves a customer's orders with progress, newest first
+func (r *NumberRepository) ListBulkOrdersByCustomer(ctx context.Context, customerID uuid.UUID, limit int) ([]models.BulkNumberOrder, error) {
+	rows, err := r.db.Query(ctx, bulkOrderWithProgress+`
+		WHERE o.customer_id = $1
+		ORDER BY o.created_at DESC
+		LIMIT $2
+	`, customerID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bulk orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []models.BulkNumberOrder{}
+	for rows.Next() {
+		order, err := scanBulkOrderWithProgress(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bulk order: %w", err)
+		}
+		orders = append(orders, *order)
+	}
+
+	return orders, rows.Err()
+}
+
+// ListBulkOrderItems retrieves an order's items, optionally in one status
+func (r *NumberRepository) ListBulkOrderItems(ctx context.Context, orderID uuid.UUID, status string) ([]models.BulkOrderItem, error) {
+	query := `
+		SELECT ` + bulkOrderItemColumns + `
+		FROM numbers.bulk_order_items
+		WHERE order_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY number
+	`
+
+	rows, err := r.db.Query(ctx, query, orderID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bulk order items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.BulkOrderItem{}
+	for rows.Next() {
+		var item models.BulkOrderItem
+		if err := rows.Scan(&item.ID, &item.Number, &item.Status, &item.AssignedNumberID, &item.Error, &item.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan bulk order item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// ClaimBulkOrder marks the oldest QUEUED order, or a RUNNING order whose
+// heartbeat is older than staleBefore, as RUNNING for this worker (nil if none)
+func (r *NumberRepository) ClaimBulkOrder(ctx context.Context, staleBefore time.Time) (*models.BulkNumberOrder, error) {
+	query := `
+		UPDATE numbers.bulk_orders o
+		SET status = 'RUNNING', started_at = COALESCE(o.started_at, NOW()), heartbeat_at = NOW()
+		WHERE o.id = (
+			SELECT id FROM numbers.bulk_orders
+			WHERE status = 'QUEUED' OR (status = 'RUNNING' AND heartbeat_at < $1)
+			ORDER BY created_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING ` + bulkOrderColumns
+
+	order, err := scanBulkOrder(r.db.QueryRow(ctx, query, staleBefore))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim bulk order: %w", err)
+	}
+	return order, nil
+}
+
+// HeartbeatBulkOrder keeps a RUNNING order claimed and reports whether
+// cancellation was requested
+func (r *NumberRepository) HeartbeatBulkOrder(ctx context.Context, id uuid.UUID) (bool, error) {
+	var cancelRequested bool
+	err := r.db.QueryRow(ctx, `
+		UPDATE numbers.bulk_orders SET heartbeat_at = NOW()
+		WHERE id = $1
+		RETURNING cancel_requested
+	`, id).Scan(&cancelRequested)
+	if err != nil {
+		return false, fmt.Errorf("failed to heartbeat bulk order: %w", err)
+	}
+	return cancelRequested, nil
+}
+
+// RequestBulkOrderCancel flags an unfinished order for cancellation. A
+// QUEUED order is cancelled outright. It returns the order's resulting
+// status, or "" if the order had already finished.
+func (r *NumberRepository) RequestBulkOrderCancel(ctx context.Context, id uuid.UUID) (string, error) {
+	query := `
+		UPDATE numbers.bulk_orders
+		SET cancel_requested = true,
+		    status = CASE WHEN status = 'QUEUED' THEN 'CANCELLED' ELSE status END,
+		    completed_at = CASE WHEN status = 'QUEUED' THEN NOW() ELSE completed_at END
+		WHERE id = $1 AND status IN ('QUEUED', 'RUNNING')
+		RETURNING status
+	`
+
+	var status string
+	err := r.db.QueryRow(ctx, query, id).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to cancel bulk order: %w", err)
+	}
+	return status, nil
+}
+
+// AddBulkOrderCandidates adds search results to an order, ignoring numbers
+// it already holds, and returns how many were added
+func (r *NumberRepository) AddBulkOrderCandidates(ctx context.Context, orderID uuid.UUID, numbers []string) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO numbers.bulk_order_items (order_id, number)
+		SELECT $1, unnest($2::varchar[])
+		ON CONFLICT (order_id, number) DO NOTHING
+	`, orderID, numbers)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add bulk order candidates: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// UpdateBulkOrderItem records an item's progress
+func (r *NumberRepository) UpdateBulkOrderItem(ctx context.Context, id uuid.UUID, status string, assignedNumberID *uuid.UUID, errMsg *string) error {
+	query := `
+		UPDATE numbers.bulk_order_items
+		SET status = $2, assigned_number_id = COALESCE($3, assigned_number_id), error = $4
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, status, assignedNumberID, errMsg); err != nil {
+		return fmt.Errorf("failed to update bulk order item: %w", err)
+	}
+	return nil
+}
+
+// TransitionBulkOrderItems moves all of an order's items in one status to another
+func (r *NumberRepository) TransitionBulkOrderItems(ctx context.Context, orderID uuid.UUID, from, to string) error {
+	query := `
+		UPDATE numbers.bulk_order_items
+		SET status = $3
+		WHERE order_id = $1 AND status = $2
+	`
+
+	if _, err := r.db.Exec(ctx, query, orderID, from, to); err != nil {
+		return fmt.Errorf("failed to update bulk order items: %w", err)
+	}
+	return nil
+}
+
+// FinishBulkOrder stores an order's final status
+func (r *NumberRepository) FinishBulkOrder(ctx context.Context, id uuid.UUID, status string, errMsg *string) error {
+	query := `
+		UPDATE numbers.bulk_orders
+		SET status = $2, error = $3, completed_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, status, errMsg); err != nil {
+		return fmt.Errorf("failed to finish bulk order: %w", err)
+	}
+	return nil
+}
+
+// scanBulkOrder scans one row selected with bulkOrderColumns
+func scanBulkOrder(row pgx.Row) (*models.BulkNumberOrder, error) {
+	order := &models.BulkNumberOrder{}
+	err := row.Scan(bulkOrderFields(order)...)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// scanBulkOrderWithProgress scans one row selected with bulkOrderWithProgress
+func scanBulkOrderWithProgress(row pgx.Row) (*models.BulkNumberOrder, error) {
+	order := &models.BulkNumberOrder{}
+	p := &order.Progress
+	err := row.Scan(append(bulkOrderFields(order),
+		&p.Pending, &p.Reserved, &p.Purchased, &p.Skipped, &p.Failed, &p.Cancelled, &p.Released,
+	)...)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func bulkOrderFields(o *models.BulkNumberOrder) []interface{} {
+	return []interface{}{
+		&o.ID, &o.CustomerID, &o.Status, &o.SearchCriteria, &o.Quantity, &o.AcceptPartial,
+		&o.VoiceEnabled, &o.SMSEnabled, &o.TrunkID, &o.CampaignID,
+		&o.CancelRequested, &o.Error, &o.CreatedBy, &o.ReservedBy,
+		&o.CreatedAt, &o.UpdatedAt, &o.StartedAt, &o.CompletedAt,
+	}
+}
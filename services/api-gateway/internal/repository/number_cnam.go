@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ringer-warp/api-gateway/internal/models"
+)
+
+// ============================================================================
+// CNAM Provisioning
+// ============================================================================
+
+const cnamProvisioningColumns = `
+	p.number_id, n.number, p.action, p.display_name, p.status, p.provider,
+	p.attempts, p.last_error, p.next_attempt_at, p.provisioned_at, p.updated_by, p.updated_at
+`
+
+// QueueCNAM queues a number's CNAM change for the CNAM worker, replacing any
+// change not yet pushed and resetting the retry budget
+func (r *NumberRepository) QueueCNAM(ctx context.Context, numberID uuid.UUID, action string, displayName *string, updatedBy uuid.UUID) error {
+	query := `
+		INSERT INTO numbers.cnam_provisioning (number_id, action, display_name, status, updated_by)
+		VALUES ($1, $2, $3, 'PENDING', $4)
+		ON CONFLICT (number_id) DO UPDATE SET
+			action = EXCLUDED.action,
+			display_name = EXCLUDED.display_name,
+			status = 'PENDING',
+			attempts = 0,
+			last_error = NULL,
+			next_attempt_at = NOW(),
+			updated_by = EXCLUDED.updated_by
+	`
+
+	if _, err := r.db.Exec(ctx, query, numberID, action, displayName, updatedBy); err != nil {
+		return fmt.Errorf("failed to queue CNAM provisioning: %w", err)
+	}
+	return nil
+}
+
+// GetNumberCNAM retrieves a number's CNAM provisioning status (nil if never queued)
+func (r *NumberRepository) GetNumberCNAM(ctx context.Context, numberID uuid.UUID) (*models.NumberCNAMProvisioning, error) {
+	query := `
+		SELECT ` + cnamProvisioningColumns + `
+		FROM numbers.cnam_provisioning p
+		JOIN numbers.assigned_numbers n ON n.id = p.number_id
+		WHERE p.number_id = $1
+	`
+
+	p, err := scanNumberCNAM(r.db.QueryRow(ctx, query, numberID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CNAM provisioning: %w", err)
+	}
+	return p, nil
+}
+
+// ClaimCNAMDue claims up to limit PENDING changes whose attempt time has
+// passed, leasing them until leaseUntil so other workers skip them
+func (r *NumberRepository) ClaimCNAMDue(ctx context.Context, leaseUntil time.Time, limit int) ([]models.NumberCNAMProvisioning, error) {
+	query := `
+		UPDATE numbers.cnam_provisioning p
+		SET next_attempt_at = $1
+		FROM numbers.assigned_numbers n
+		WHERE n.id = p.number_id
+		AND p.number_id IN (
+			SELECT number_id FROM numbers.cnam_provisioning
+			WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + cnamProvisioningColumns
+
+	rows, err := r.db.Query(ctx, query, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim CNAM provisioning: %w", err)
+	}
+	defer rows.Close()
+
+	claimed := []models.NumberCNAMProvisioning{}
+	for rows.Next() {
+		p, err := scanNumberCNAM(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan CNAM provisioning: %w", err)
+		}
+		claimed = append(claimed, *p)
+	}
+
+	return claimed, rows.Err()
+}
+
+// ResolveCNAM records the final outcome of a claimed change. Nothing is
+// written if the change was replaced while it was being pushed.
+func (r *NumberRepository) ResolveCNAM(ctx context.Context, p *models.NumberCNAMProvisioning, status, provider string, lastError *string) error {
+	query := `
+		UPDATE numbers.cnam_provisioning
+		SET status = $4, provider = $5, last_error = $6,
+		    provisioned_at = CASE WHEN $4 = 'FAILED' THEN provisioned_at ELSE NOW() END
+		WHERE number_id = $1 AND status = 'PENDING'
+		AND action = $2 AND display_name IS NOT DISTINCT FROM $3
+	`
+
+	if _, err := r.db.Exec(ctx, query, p.NumberID, p.Action, p.DisplayName, status, provider, lastError); err != nil {
+		return fmt.Errorf("failed to resolve CNAM provisioning: %w", err)
+	}
+	return nil
+}
+
+// ScheduleCNAMRetry counts a failed attempt of a claimed change and sets its
+// next attempt. Nothing is written if the change was replaced meanwhile.
+func (r *NumberRepository) ScheduleCNAMRetry(ctx context.Context, p *models.NumberCNAMProvisioning, provider, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE numbers.cnam_provisioning
+		SET attempts = attempts + 1, provider = $4, last_error = $5, next_attempt_at = $6
+		WHERE number_id = $1 AND status = 'PENDING'
+		AND action = $2 AND display_name IS NOT DISTINCT FROM $3
+	`
+
+	if _, err := r.db.Exec(ctx, query, p.NumberID, p.Action, p.DisplayName, provider, lastError, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to schedule CNAM retry: %w", err)
+	}
+	return nil
+}
+
+func scanNumberCNAM(row pgx.Row) (*models.NumberCNAMProvisioning, error) {
+	p := &models.NumberCNAMProvisioning{}
+	err := row.Scan(
+		&p.NumberID, &p.Number, &p.Action, &p.DisplayName, &p.Status, &p.Provider,
+		&p.Attempts, &p.LastError, &p.NextAttemptAt, &p.ProvisionedAt, &p.UpdatedBy, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ringer-warp/api-gateway/internal/models"
+)
+
+// ============================================================================
+// E911 Addresses and Number Provisioning
+// ============================================================================
+
+const e911AddressColumns = `
+	id, customer_id, name, address_line1, address_line2, city, state, postal_code,
+	COALESCE(country, 'US'), location_info,
+	validation_status, validation_message, msag_valid, validation_date, provider, provider_address_id,
+	created_by, updated_by, created_at, updated_at
+`
+
+const e911ProvisioningColumns = `
+	number_id, address_id, status, provider, error, provisioned_at, updated_by, updated_at
+`
+
+// CreateE911Address stores a PENDING E911 address
+func (r *NumberRepository) CreateE911Address(ctx context.Context, addr *models.E911Address) error {
+	query := `
+		INSERT INTO numbers.e911_addresses (
+			customer_id, name, address_line1, address_line2, city, state, postal_code,
+			country, location_info, validation_status, created_by, updated_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 'PENDING', $10, $10)
+		RETURNING ` + e911AddressColumns
+
+	created, err := scanE911Address(r.db.QueryRow(ctx, query,
+		addr.CustomerID, addr.Name, addr.AddressLine1, addr.AddressLine2, addr.City, addr.State, addr.PostalCode,
+		addr.Country, addr.LocationInfo, addr.CreatedBy,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to create E911 address: %w", err)
+	}
+
+	*addr = *created
+	return nil
+}
+
+// GetE911Address retrieves an E911 address (nil if not found)
+func (r *NumberRepository) GetE911Address(ctx context.Context, id uuid.UUID) (*models.E911Address, error) {
+	query := `
+		SELECT ` + e911AddressColumns + `
+		FROM numbers.e911_addresses
+		WHERE id = $1 AND customer_id IS NOT NULL
+	`
+
+	addr, err := scanE911Address(r.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get E911 address: %w", err)
+	}
+	return addr, nil
+}
+
+// ListE911Addresses retrieves a customer's E911 addresses
+func (r *NumberRepository) ListE911Addresses(ctx context.Context, customerID uuid.UUID) ([]models.E911Address, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+e911AddressColumns+`
+		FROM numbers.e911_addresses
+		WHERE customer_id = $1
+		ORDER BY name, created_at
+	`, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list E911 addresses: %w", err)
+	}
+	defer rows.Close()
+
+	addresses := []models.E911Address{}
+	for rows.Next() {
+		addr, err := scanE911Address(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan E911 address: %w", err)
+		}
+		addresses = append(addresses, *addr)
+	}
+
+	return addresses, rows.Err()
+}
+
+// UpdateE911Address applies changes to an address and resets it to PENDING validation
+func (r *NumberRepository) UpdateE911Address(ctx context.Context, id uuid.UUID, req *models.UpdateE911AddressRequest, updatedBy uuid.UUID) (*models.E911Address, error) {
+	query := `
+		UPDATE numbers.e911_addresses SET
+			name = COALESCE($2, name),
+			address_line1 = COALESCE($3, address_line1),
+			address_line2 = COALESCE($4, address_line2),
+			city = COALESCE($5, city),
+			state = COALESCE($6, state),
+			postal_code = COALESCE($7, postal_code),
+			location_info = COALESCE($8, location_info),
+			validation_status = 'PENDING', validated = false,
+			updated_by = $9
+		WHERE id = $1
+		RETURNING ` + e911AddressColumns
+
+	addr, err := scanE911Address(r.db.QueryRow(ctx, query, id,
+		req.Name, req.AddressLine1, req.AddressLine2, req.City, req.State, req.PostalCode, req.LocationInfo,
+		updatedBy,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update E911 address: %w", err)
+	}
+	return addr, nil
+}
+
+// RecordE911Validation stores a validation outcome, including the provider's
+// normalized form of the address when it validated
+func (r *NumberRepository) RecordE911Validation(ctx context.Context, addr *models.E911Address) (*models.E911Address, error) {
+	query := `
+		UPDATE numbers.e911_addresses SET
+			name = $2, address_line1 = $3, address_line2 = $4, city = $5, state = $6,
+			postal_code = $7, country = $8, location_info = $9,
+			validation_status = $10, validation_message = $11, msag_valid = $12,
+			validated = ($10 = 'VALIDATED'), validation_date = NOW(),
+			provider = $13, provider_address_id = $14
+		WHERE id = $1
+		RETURNING ` + e911AddressColumns
+
+	updated, err := scanE911Address(r.db.QueryRow(ctx, query, addr.ID,
+		addr.Name, addr.AddressLine1, addr.AddressLine2, addr.City, addr.State,
+		addr.PostalCode, addr.Country, addr.LocationInfo,
+		addr.ValidationStatus, addr.ValidationMessage, addr.MSAGValid,
+		addr.Provider, addr.ProviderAddressID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record E911 validation: %w", err)
+	}
+	return updated, nil
+}
+
+// DeleteE911Address deletes an address no number is provisioned to,
+// reporting whether it was deleted
+func (r *NumberRepository) DeleteE911Address(ctx context.Context, id uuid.UUID) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM numbers.e911_addresses a
+		WHERE a.id = $1
+		AND NOT EXISTS (SELECT 1 FROM numbers.assigned_numbers n WHERE n.e911_address_id = a.id AND n.active)
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete E911 address: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListNumbersOnE911Address retrieves the active numbers provisioned to an address
+func (r *NumberRepository) ListNumbersOnE911Address(ctx context.Context, addressID uuid.UUID) ([]LocalNumberRef, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, customer_id, number, soa_sync_status
+		FROM numbers.assigned_numbers
+		WHERE e911_address_id = $1 AND active = true
+		ORDER BY number
+	`, addressID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list numbers on E911 address: %w", err)
+	}
+	defer rows.Close()
+
+	refs := []LocalNumberRef{}
+	for rows.Next() {
+		var ref LocalNumberRef
+		if err := rows.Scan(&ref.ID, &ref.CustomerID, &ref.Number, &ref.SOASyncStatus); err != nil {
+			return nil, fmt.Errorf("failed to scan number: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, rows.Err()
+}
+
+// GetNumberE911 retrieves a number's E911 provisioning status (nil if never provisioned)
+func (r *NumberRepository) GetNumberE911(ctx context.Context, numberID uuid.UUID) (*models.NumberE911Provisioning, error) {
+	query := `SELECT ` + e911ProvisioningColumns + ` FROM numbers.e911_provisioning WHERE number_id = $1`
+
+	p, err := scanNumberE911(r.db.QueryRow(ctx, query, numberID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get E911 provisioning: %w", err)
+	}
+	return p, nil
+}
+
+// RecordNumberE911 stores a number's provisioning outcome. PROVISIONED and
+// DEPROVISIONED also update the number's e911_enabled and e911_address_id;
+// a FAILED attempt leaves the number's current E911 record in place.
+func (r *NumberRepository) RecordNumberE911(ctx context.Context, p *models.NumberE911Provisioning) (*models.NumberE911Provisioning, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO numbers.e911_provisioning (number_id, address_id, status, provider, error, provisioned_at, updated_by)
+		VALUES ($1, $2, $3, $4, $5, CASE WHEN $3 = 'PROVISIONED' THEN NOW() END, $6)
+		ON CONFLICT (number_id) DO UPDATE SET
+			address_id = EXCLUDED.address_id,
+			status = EXCLUDED.status,
+			provider = EXCLUDED.provider,
+			error = EXCLUDED.error,
+			provisioned_at = COALESCE(EXCLUDED.provisioned_at, numbers.e911_provisioning.provisioned_at),
+			updated_by = EXCLUDED.updated_by
+		RETURNING ` + e911ProvisioningColumns
+
+	recorded, err := scanNumberE911(tx.QueryRow(ctx, query,
+		p.NumberID, p.AddressID, p.Status, p.Provider, p.Error, p.UpdatedBy,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record E911 provisioning: %w", err)
+	}
+
+	switch p.Status {
+	case "PROVISIONED":
+		_, err = tx.Exec(ctx, `
+			UPDATE numbers.assigned_numbers
+			SET e911_enabled = true, e911_address_id = $2, updated_by = $3
+			WHERE id = $1
+		`, p.NumberID, p.AddressID, p.UpdatedBy)
+	case "DEPROVISIONED":
+		_, err = tx.Exec(ctx, `
+			UPDATE numbers.assigned_numbers
+			SET e911_enabled = false, e911_address_id = NULL, updated_by = $2
+			WHERE id = $1
+		`, p.NumberID, p.UpdatedBy)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update number E911 configuration: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit E911 provisioning: %w", err)
+	}
+	return recorded, nil
+}
+
+func scanE911Address(row pgx.Row) (*models.E911Address, error) {
+	a := &models.E911Address{}
+	err := row.Scan(
+		&a.ID, &a.CustomerID, &a.Name, &a.AddressLine1, &a.AddressLine2, &a.City, &a.State, &a.PostalCode,
+		&a.Country, &a.LocationInfo,
+		&a.ValidationStatus, &a.ValidationMessage, &a.MSAGValid, &a.ValidatedAt, &a.Provider, &a.ProviderAddressID,
+		&a.CreatedBy, &a.UpdatedBy, &a.CreatedAt, &a.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func scanNumberE911(row pgx.Row) (*models.NumberE911Provisioning, error) {
+	p := &models.NumberE911Provisioning{}
+	err := row.Scan(
+		&p.NumberID, &p.AddressID, &p.Status, &p.Provider, &p.Error, &p.ProvisionedAt, &p.UpdatedBy, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
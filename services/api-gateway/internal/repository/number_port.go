@@ -0,0 +1,452 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ringer-warp/api-gateway/internal/models"
+)
+
+// ============================================================================
+// Port-In Requests
+// ============================================================================
+
+const portRequestColumns = `
+	id, customer_id, port_type, status, requested_foc_date, actual_foc_date,
+	losing_carrier, losing_carrier_ocn,
+	authorized_person, authorized_person_title, end_user_name, service_address,
+	trunk_id, voice_enabled, sms_enabled,
+	lsr_id, pon, rejection_reason, rejection_count, notes,
+	created_by, updated_by, created_at, updated_at, submitted_at, foc_received_at, completed_at
+`
+
+const portRequestNumberColumns = `
+	id, port_request_id, number, is_btn, btn, current_provider, account_number, pin_passcode,
+	portability, portability_reason, current_spid, current_lrn, rate_center, state, checked_at,
+	ported_successfully, error_message, assigned_number_id, activated_at, created_at
+`
+
+const portRequestDocumentColumns = `
+	id, port_request_id, document_type, file_name, file_url, content_type, size_bytes,
+	uploaded_by, created_at
+`
+
+// CreatePortRequest stores a DRAFT port request with its numbers
+func (r *NumberRepository) CreatePortRequest(ctx context.Context, pr *models.PortRequest, numbers []models.PortRequestNumber) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO numbers.port_requests (
+			customer_id, port_type, status, requested_foc_date, losing_carrier,
+			authorized_person, authorized_person_title, end_user_name, service_address,
+			trunk_id, voice_enabled, sms_enabled, notes, created_by, updated_by
+		) VALUES ($1, 'PORT_IN', 'DRAFT', $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12)
+		RETURNING ` + portRequestColumns
+
+	created, err := scanPortRequest(tx.QueryRow(ctx, query,
+		pr.CustomerID, pr.RequestedFOCDate, pr.LosingCarrier,
+		pr.AuthorizedPerson, pr.AuthorizedPersonTitle, pr.EndUserName, pr.ServiceAddress,
+		pr.TrunkID, pr.VoiceEnabled, pr.SMSEnabled, pr.Notes, pr.CreatedBy,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to create port request: %w", err)
+	}
+
+	if created.Numbers, err = insertPortRequestNumbers(ctx, tx, created.ID, numbers); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit port request: %w", err)
+	}
+
+	*pr = *created
+	return nil
+}
+
+// GetPortRequest retrieves a port request without its numbers (nil if not found)
+func (r *NumberRepository) GetPortRequest(ctx context.Context, id uuid.UUID) (*models.PortRequest, error) {
+	query := `SELECT ` + portRequestColumns + ` FROM numbers.port_requests WHERE id = $1 AND customer_id IS NOT NULL`
+
+	pr, err := scanPortRequest(r.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get port request: %w", err)
+	}
+	return pr, nil
+}
+
+// ListPortRequests retrieves port requests, newest first. A nil customerIDs
+// lists every customer's requests; status filters when non-empty.
+func (r *NumberRepository) ListPortRequests(ctx context.Context, customerIDs []uuid.UUID, status string, limit, offset int) ([]models.PortRequest, int64, error) {
+	// Legacy account-based rows (no customer_id) are not served by this API
+	where := `WHERE customer_id IS NOT NULL AND ($1::uuid[] IS NULL OR customer_id = ANY($1)) AND ($2 = '' OR status = $2)`
+
+	var total int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM numbers.port_requests `+where, customerIDs, status).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count port requests: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+portRequestColumns+`
+		FROM numbers.port_requests `+where+`
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`, customerIDs, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list port requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []models.PortRequest{}
+	for rows.Next() {
+		pr, err := scanPortRequest(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan port request: %w", err)
+		}
+		requests = append(requests, *pr)
+	}
+
+	return requests, total, rows.Err()
+}
+
+// UpdatePortRequest applies changes to a DRAFT or REJECTED port request.
+// It returns nil if the request is in any other status.
+func (r *NumberRepository) UpdatePortRequest(ctx context.Context, id uuid.UUID, req *models.UpdatePortRequestRequest, updatedBy uuid.UUID) (*models.PortRequest, error) {
+	query := `
+		UPDATE numbers.port_requests SET
+			requested_foc_date = COALESCE($2, requested_foc_date),
+			losing_carrier = COALESCE($3, losing_carrier),
+			authorized_person = COALESCE($4, authorized_person),
+			authorized_person_title = COALESCE($5, authorized_person_title),
+			end_user_name = COALESCE($6, end_user_name),
+			service_address = COALESCE($7, service_address),
+			trunk_id = COALESCE($8, trunk_id),
+			voice_enabled = COALESCE($9, voice_enabled),
+			sms_enabled = COALESCE($10, sms_enabled),
+			notes = COALESCE($11, notes),
+			updated_by = $12
+		WHERE id = $1 AND status IN ('DRAFT', 'REJECTED')
+		RETURNING ` + portRequestColumns
+
+	pr, err := scanPortRequest(r.db.QueryRow(ctx, query, id,
+		req.RequestedFOCDate, req.LosingCarrier, req.AuthorizedPerson, req.AuthorizedPersonTitle,
+		req.EndUserName, req.ServiceAddress, req.TrunkID, req.VoiceEnabled, req.SMSEnabled,
+		req.Notes, updatedBy,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update port request: %w", err)
+	}
+	return pr, nil
+}
+
+// ReplacePortRequestNumbers replaces the numbers of a port request
+func (r *NumberRepository) ReplacePortRequestNumbers(ctx context.Context, id uuid.UUID, numbers []models.PortRequestNumber) ([]models.PortRequestNumber, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM numbers.port_request_numbers WHERE port_request_id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to remove port request numbers: %w", err)
+	}
+
+	inserted, err := insertPortRequestNumbers(ctx, tx, id, numbers)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit port request numbers: %w", err)
+	}
+	return inserted, nil
+}
+
+// ListPortRequestNumbers retrieves the numbers of a port request
+func (r *NumberRepository) ListPortRequestNumbers(ctx context.Context, id uuid.UUID) ([]models.PortRequestNumber, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+portRequestNumberColumns+`
+		FROM numbers.port_request_numbers
+		WHERE port_request_id = $1
+		ORDER BY number
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list port request numbers: %w", err)
+	}
+	defer rows.Close()
+
+	numbers := []models.PortRequestNumber{}
+	for rows.Next() {
+		n, err := scanPortRequestNumber(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan port request number: %w", err)
+		}
+		numbers = append(numbers, *n)
+	}
+
+	return numbers, rows.Err()
+}
+
+// RecordPortabilityCheck stores a pre-check result on a port request number
+func (r *NumberRepository) RecordPortabilityCheck(ctx context.Context, numberID uuid.UUID, result *models.PortabilityCheckResult) error {
+	query := `
+		UPDATE numbers.port_request_numbers
+		SET portability = $2, portability_reason = NULLIF($3, ''),
+		    current_spid = $4, current_lrn = $5, rate_center = $6, state = $7,
+		    checked_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := r.db.Exec(ctx, query, numberID,
+		result.Portability, result.Reason,
+		result.CurrentSPID, result.CurrentLRN, result.RateCenter, result.State,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record portability check: %w", err)
+	}
+	return nil
+}
+
+// ListNumbersInOpenPorts returns which of numbers are already in another
+// SUBMITTED or FOC_RECEIVED port request
+func (r *NumberRepository) ListNumbersInOpenPorts(ctx context.Context, numbers []string, excludeID uuid.UUID) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT n.number
+		FROM numbers.port_request_numbers n
+		JOIN numbers.port_requests p ON p.id = n.port_request_id
+		WHERE n.number = ANY($1) AND p.id <> $2 AND p.status IN ('SUBMITTED', 'FOC_RECEIVED')
+	`, numbers, excludeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check open port requests: %w", err)
+	}
+	defer rows.Close()
+
+	conflicts := []string{}
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			return nil, fmt.Errorf("failed to scan number: %w", err)
+		}
+		conflicts = append(conflicts, number)
+	}
+
+	return conflicts, rows.Err()
+}
+
+// SubmitPortRequest moves a DRAFT or REJECTED request to SUBMITTED.
+// It returns false if the request is in any other status.
+func (r *NumberRepository) SubmitPortRequest(ctx context.Context, id uuid.UUID, updatedBy uuid.UUID) (bool, error) {
+	return r.transitionPortRequest(ctx, id, []string{"DRAFT", "REJECTED"}, `
+		status = 'SUBMITTED', submitted_at = NOW(), updated_by = $2
+	`, updatedBy)
+}
+
+// RecordPortFOC moves a SUBMITTED request to FOC_RECEIVED, or revises the FOC
+// of a request that already has one
+func (r *NumberRepository) RecordPortFOC(ctx context.Context, id uuid.UUID, req *models.RecordPortFOCRequest, updatedBy uuid.UUID) (bool, error) {
+	return r.transitionPortRequest(ctx, id, []string{"SUBMITTED", "FOC_RECEIVED"}, `
+		status = 'FOC_RECEIVED', actual_foc_date = $2,
+		pon = COALESCE($3, pon), lsr_id = COALESCE($4, lsr_id),
+		losing_carrier_ocn = COALESCE($5, losing_carrier_ocn),
+		foc_received_at = COALESCE(foc_received_at, NOW()),
+		rejection_reason = NULL, updated_by = $6
+	`, req.FOCDate, req.PON, req.LSRID, req.LosingCarrierOCN, updatedBy)
+}
+
+// RejectPortRequest records a losing carrier rejection of a SUBMITTED or FOC_RECEIVED request
+func (r *NumberRepository) RejectPortRequest(ctx context.Context, id uuid.UUID, reason string, updatedBy uuid.UUID) (bool, error) {
+	return r.transitionPortRequest(ctx, id, []string{"SUBMITTED", "FOC_RECEIVED"}, `
+		status = 'REJECTED', rejection_reason = $2, rejection_count = rejection_count + 1,
+		actual_foc_date = NULL, foc_received_at = NULL, updated_by = $3
+	`, reason, updatedBy)
+}
+
+// CancelPortRequest cancels a request that has not been activated
+func (r *NumberRepository) CancelPortRequest(ctx context.Context, id uuid.UUID, updatedBy uuid.UUID) (bool, error) {
+	return r.transitionPortRequest(ctx, id, []string{"DRAFT", "SUBMITTED", "FOC_RECEIVED", "REJECTED"}, `
+		status = 'CANCELLED', completed_at = NOW(), updated_by = $2
+	`, updatedBy)
+}
+
+// CompletePortRequest moves a FOC_RECEIVED request to ACTIVATED
+func (r *NumberRepository) CompletePortRequest(ctx context.Context, id uuid.UUID, updatedBy uuid.UUID) (bool, error) {
+	return r.transitionPortRequest(ctx, id, []string{"FOC_RECEIVED"}, `
+		status = 'ACTIVATED', completed_at = NOW(), updated_by = $2
+	`, updatedBy)
+}
+
+// transitionPortRequest applies set (whose placeholders start at $2) when the
+// request's status is one of from, reporting whether it did
+func (r *NumberRepository) transitionPortRequest(ctx context.Context, id uuid.UUID, from []string, set string, args ...interface{}) (bool, error) {
+	query := fmt.Sprintf(`
+		UPDATE numbers.port_requests SET %s
+		WHERE id = $1 AND status = ANY($%d)
+	`, set, len(args)+2)
+
+	tag, err := r.db.Exec(ctx, query, append(append([]interface{}{id}, args...), from)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update port request status: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// MarkPortNumberActivated links a ported number to the assigned number created for it
+func (r *NumberRepository) MarkPortNumberActivated(ctx context.Context, numberID, assignedNumberID uuid.UUID) error {
+	query := `
+		UPDATE numbers.port_request_numbers
+		SET ported_successfully = true, error_message = NULL,
+		    assigned_number_id = $2, activated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(ctx, query, numberID, assignedNumberID); err != nil {
+		return fmt.Errorf("failed to mark port number activated: %w", err)
+	}
+	return nil
+}
+
+// MarkPortNumberFailed records why a number could not be activated
+func (r *NumberRepository) MarkPortNumberFailed(ctx context.Context, numberID uuid.UUID, errMsg string) error {
+	query := `
+		UPDATE numbers.port_request_numbers
+		SET ported_successfully = false, error_message = $2
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(ctx, query, numberID, errMsg); err != nil {
+		return fmt.Errorf("failed to mark port number failed: %w", err)
+	}
+	return nil
+}
+
+// AddPortRequestDocument attaches a document to a port request
+func (r *NumberRepository) AddPortRequestDocument(ctx context.Context, doc *models.PortRequestDocument) error {
+	query := `
+		INSERT INTO numbers.port_request_documents (
+			port_request_id, document_type, file_name, file_url, content_type, size_bytes, uploaded_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + portRequestDocumentColumns
+
+	created, err := scanPortRequestDocument(r.db.QueryRow(ctx, query,
+		doc.PortRequestID, doc.DocumentType, doc.FileName, doc.FileURL,
+		doc.ContentType, doc.SizeBytes, doc.UploadedBy,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to add port request document: %w", err)
+	}
+
+	*doc = *created
+	return nil
+}
+
+// ListPortRequestDocuments retrieves the documents attached to a port request
+func (r *NumberRepository) ListPortRequestDocuments(ctx context.Context, id uuid.UUID) ([]models.PortRequestDocument, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+portRequestDocumentColumns+`
+		FROM numbers.port_request_documents
+		WHERE port_request_id = $1
+		ORDER BY created_at
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list port request documents: %w", err)
+	}
+	defer rows.Close()
+
+	docs := []models.PortRequestDocument{}
+	for rows.Next() {
+		doc, err := scanPortRequestDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan port request document: %w", err)
+		}
+		docs = append(docs, *doc)
+	}
+
+	return docs, rows.Err()
+}
+
+// DeletePortRequestDocument removes a document from a port request, reporting whether it existed
+func (r *NumberRepository) DeletePortRequestDocument(ctx context.Context, requestID, documentID uuid.UUID) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM numbers.port_request_documents
+		WHERE id = $1 AND port_request_id = $2
+	`, documentID, requestID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete port request document: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func insertPortRequestNumbers(ctx context.Context, tx pgx.Tx, requestID uuid.UUID, numbers []models.PortRequestNumber) ([]models.PortRequestNumber, error) {
+	query := `
+		INSERT INTO numbers.port_request_numbers (
+			port_request_id, number, is_btn, btn, current_provider, account_number, pin_passcode
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + portRequestNumberColumns
+
+	inserted := make([]models.PortRequestNumber, 0, len(numbers))
+	for _, n := range numbers {
+		created, err := scanPortRequestNumber(tx.QueryRow(ctx, query,
+			requestID, n.Number, n.IsBTN, n.BTN, n.CurrentProvider, n.AccountNumber, n.PINSealed,
+		))
+		if err != nil {
+			return nil, fmt.Errorf("failed to add port request number %s: %w", n.Number, err)
+		}
+		inserted = append(inserted, *created)
+	}
+	return inserted, nil
+}
+
+func scanPortRequest(row pgx.Row) (*models.PortRequest, error) {
+	pr := &models.PortRequest{}
+	err := row.Scan(
+		&pr.ID, &pr.CustomerID, &pr.PortType, &pr.Status, &pr.RequestedFOCDate, &pr.ActualFOCDate,
+		&pr.LosingCarrier, &pr.LosingCarrierOCN,
+		&pr.AuthorizedPerson, &pr.AuthorizedPersonTitle, &pr.EndUserName, &pr.ServiceAddress,
+		&pr.TrunkID, &pr.VoiceEnabled, &pr.SMSEnabled,
+		&pr.LSRID, &pr.PON, &pr.RejectionReason, &pr.RejectionCount, &pr.Notes,
+		&pr.CreatedBy, &pr.UpdatedBy, &pr.CreatedAt, &pr.UpdatedAt, &pr.SubmittedAt, &pr.FOCReceivedAt, &pr.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+func scanPortRequestNumber(row pgx.Row) (*models.PortRequestNumber, error) {
+	n := &models.PortRequestNumber{}
+	err := row.Scan(
+		&n.ID, &n.PortRequestID, &n.Number, &n.IsBTN, &n.BTN, &n.CurrentProvider, &n.AccountNumber, &n.PINSealed,
+		&n.Portability, &n.PortabilityReason, &n.CurrentSPID, &n.CurrentLRN, &n.RateCenter, &n.State, &n.CheckedAt,
+		&n.PortedSuccessfully, &n.ErrorMessage, &n.AssignedNumberID, &n.ActivatedAt, &n.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	n.PINOnFile = n.PINSealed != nil
+	return n, nil
+}
+
+func scanPortRequestDocument(row pgx.Row) (*models.PortRequestDocument, error) {
+	doc := &models.PortRequestDocument{}
+	err := row.Scan(
+		&doc.ID, &doc.PortRequestID, &doc.DocumentType, &doc.FileName, &doc.FileURL,
+		&doc.ContentType, &doc.SizeBytes, &doc.UploadedBy, &doc.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
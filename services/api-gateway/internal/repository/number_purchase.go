@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ringer-warp/api-gateway/internal/models"
+)
+
+// ============================================================================
+// Number Purchase Intents (purchase saga state)
+// ============================================================================
+
+const purchaseIntentColumns = `
+	id, customer_id, number, status,
+	voice_enabled, sms_enabled, trunk_id, campaign_id,
+	soa_number_id, assigned_number_id,
+	attempts, last_error, next_attempt_at,
+	created_by, created_at, updated_at, completed_at
+`
+
+// CreatePurchaseIntent persists a PENDING intent before SOA is called
+func (r *NumberRepository) CreatePurchaseIntent(ctx context.Context, intent *models.NumberPurchaseIntent) error {
+	query := `
+		INSERT INTO numbers.purchase_intents (
+			customer_id, number, voice_enabled, sms_enabled, trunk_id, campaign_id, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + purchaseIntentColumns
+
+	created, err := scanPurchaseIntent(r.db.QueryRow(ctx, query,
+		intent.CustomerID, intent.Number, intent.VoiceEnabled, intent.SMSEnabled,
+		intent.TrunkID, intent.CampaignID, intent.CreatedBy,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to create purchase intent: %w", err)
+	}
+
+	*intent = *created
+	return nil
+}
+
+// GetPurchaseIntent retrieves a purchase intent by ID (nil if not found)
+func (r *NumberRepository) GetPurchaseIntent(ctx context.Context, id uuid.UUID) (*models.NumberPurchaseIntent, error) {
+	query := `SELECT ` + purchaseIntentColumns + ` FROM numbers.purchase_intents WHERE id = $1`
+
+	intent, err := scanPurchaseIntent(r.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purchase intent: %w", err)
+	}
+
+	return intent, nil
+}
+
+// ListPurchaseIntents retrieves intents in a status (all statuses if empty), newest first
+func (r *NumberRepository) ListPurchaseIntents(ctx context.Context, status string, limit int) ([]models.NumberPurchaseIntent, error) {
+	query := `
+		SELECT ` + purchaseIntentColumns + `
+		FROM numbers.purchase_intents
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	return r.queryPurchaseIntents(ctx, query, status, limit)
+}
+
+// ListPurchaseIntentsDue retrieves intents the repair worker should act on:
+// REPAIR intents whose retry time has passed, and PENDING/SOA_ASSIGNED
+// intents untouched since staleBefore (the request died mid-saga)
+func (r *NumberRepository) ListPurchaseIntentsDue(ctx context.Context, staleBefore time.Time, limit int) ([]models.NumberPurchaseIntent, error) {
+	query := `
+		SELECT ` + purchaseIntentColumns + `
+		FROM numbers.purchase_intents
+		WHERE (status = 'REPAIR' AND next_attempt_at <= NOW())
+		   OR (status IN ('PENDING', 'SOA_ASSIGNED') AND updated_at < $1)
+		ORDER BY updated_at
+		LIMIT $2
+	`
+	return r.queryPurchaseIntents(ctx, query, staleBefore, limit)
+}
+
+// MarkPurchaseIntentAssigned records that SOA assigned the number
+func (r *NumberRepository) MarkPurchaseIntentAssigned(ctx context.Context, id uuid.UUID, soaNumberID string) error {
+	query := `
+		UPDATE numbers.purchase_intents
+		SET status = 'SOA_ASSIGNED', soa_number_id = $2
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, soaNumberID); err != nil {
+		return fmt.Errorf("failed to mark purchase intent assigned: %w", err)
+	}
+	return nil
+}
+
+// CompletePurchaseIntent links the intent to its local number and closes it
+func (r *NumberRepository) CompletePurchaseIntent(ctx context.Context, id, assignedNumberID uuid.UUID) error {
+	query := `
+		UPDATE numbers.purchase_intents
+		SET status = 'COMPLETED', assigned_number_id = $2, last_error = NULL,
+		    next_attempt_at = NULL, completed_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, assignedNumberID); err != nil {
+		return fmt.Errorf("failed to complete purchase intent: %w", err)
+	}
+	return nil
+}
+
+// ResolvePurchaseIntent moves an intent to a status that needs no further
+// repair (FAILED, COMPENSATED or REPAIR_FAILED)
+func (r *NumberRepository) ResolvePurchaseIntent(ctx context.Context, id uuid.UUID, status string, lastError *string) error {
+	query := `
+		UPDATE numbers.purchase_intents
+		SET status = $2, last_error = COALESCE($3, last_error), next_attempt_at = NULL,
+		    completed_at = CASE WHEN $2 = 'REPAIR_FAILED' THEN NULL ELSE NOW() END
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, status, lastError); err != nil {
+		return fmt.Errorf("failed to resolve purchase intent: %w", err)
+	}
+	return nil
+}
+
+// SchedulePurchaseRepair queues an intent for the repair worker, counting the attempt
+func (r *NumberRepository) SchedulePurchaseRepair(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE numbers.purchase_intents
+		SET status = 'REPAIR', attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, lastError, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to schedule purchase repair: %w", err)
+	}
+	return nil
+}
+
+// RetryPurchaseIntent requeues a REPAIR_FAILED intent with a fresh attempt
+// budget. It reports false if the intent was not REPAIR_FAILED.
+func (r *NumberRepository) RetryPurchaseIntent(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE numbers.purchase_intents
+		SET status = 'REPAIR', attempts = 0, next_attempt_at = NOW()
+		WHERE id = $1 AND status = 'REPAIR_FAILED'
+	`
+
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to retry purchase intent: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *NumberRepository) queryPurchaseIntents(ctx context.Context, query string, args ...interface{}) ([]models.NumberPurchaseIntent, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purchase intents: %w", err)
+	}
+	defer rows.Close()
+
+	intents := []models.NumberPurchaseIntent{}
+	for rows.Next() {
+		intent, err := scanPurchaseIntent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan purchase intent: %w", err)
+		}
+		intents = append(intents, *intent)
+	}
+
+	return intents, rows.Err()
+}
+
+// scanPurchaseIntent scans one row selected with purchaseIntentColumns
+func scanPurchaseIntent(row pgx.Row) (*models.NumberPurchaseIntent, error) {
+	intent := &models.NumberPurchaseIntent{}
+	err := row.Scan(
+		&intent.ID, &intent.CustomerID, &intent.Number, &intent.Status,
+		&intent.VoiceEnabled, &intent.SMSEnabled, &intent.TrunkID, &intent.CampaignID,
+		&intent.SOANumberID, &intent.AssignedNumberID,
+		&intent.Attempts, &intent.LastError, &intent.NextAttemptAt,
+		&intent.CreatedBy, &intent.CreatedAt, &intent.UpdatedAt, &intent.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return intent, nil
+}
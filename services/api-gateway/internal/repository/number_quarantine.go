@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ringer-warp/api-gateway/internal/models"
+)
+
+// ============================================================================
+// Release Quarantine
+// ============================================================================
+
+const quarantineColumns = `
+	q.number_id, n.number, q.customer_id, q.status, q.release_reason,
+	q.quarantined_at, q.release_after, q.quarantined_by,
+	q.restored_at, q.restored_by, q.released_at,
+	q.attempts, q.last_error, q.next_attempt_at, q.updated_at
+`
+
+// QuarantineNumber deactivates an active number and starts its quarantine,
+// in one transaction. It reports false if the number was not active.
+func (r *NumberRepository) QuarantineNumber(ctx context.Context, id uuid.UUID, reason string, releasedBy uuid.UUID, releaseAfter time.Time) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var customerID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE numbers.assigned_numbers
+		SET active = false, released_at = NOW(), release_reason = $2, updated_by = $3
+		WHERE id = $1 AND active = true
+		RETURNING customer_id
+	`, id, reason, releasedBy).Scan(&customerID)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to release number: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO numbers.number_quarantine (
+			number_id, customer_id, release_reason, release_after, quarantined_by
+		) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (number_id) DO UPDATE SET
+			customer_id = EXCLUDED.customer_id,
+			status = 'QUARANTINE',
+			release_reason = EXCLUDED.release_reason,
+			quarantined_at = NOW(),
+			release_after = EXCLUDED.release_after,
+			quarantined_by = EXCLUDED.quarantined_by,
+			restored_at = NULL,
+			restored_by = NULL,
+			released_at = NULL,
+			attempts = 0,
+			last_error = NULL,
+			next_attempt_at = NOW()
+	`, id, customerID, nullString(reason), releaseAfter, releasedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to quarantine number: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit quarantine: %w", err)
+	}
+	return true, nil
+}
+
+// ListQuarantinedNumbers retrieves numbers in quarantine, soonest release
+// first. A nil customerIDs lists every customer's.
+func (r *NumberRepository) ListQuarantinedNumbers(ctx context.Context, customerIDs []uuid.UUID) ([]models.NumberQuarantine, error) {
+	query := `
+		SELECT ` + quarantineColumns + `
+		FROM numbers.number_quarantine q
+		JOIN numbers.assigned_numbers n ON n.id = q.number_id
+		WHERE q.status = 'QUARANTINE'
+		AND ($1::uuid[] IS NULL OR q.customer_id = ANY($1))
+		ORDER BY q.release_after
+	`
+
+	rows, err := r.db.Query(ctx, query, customerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quarantined numbers: %w", err)
+	}
+	defer rows.Close()
+
+	return collectQuarantine(rows)
+}
+
+// ListQuarantinedTNs retrieves the telephone numbers currently in quarantine
+func (r *NumberRepository) ListQuarantinedTNs(ctx context.Context) (map[string]bool, error) {
+	query := `
+		SELECT n.number
+		FROM numbers.number_quarantine q
+		JOIN numbers.assigned_numbers n ON n.id = q.number_id
+		WHERE q.status = 'QUARANTINE'
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quarantined numbers: %w", err)
+	}
+	defer rows.Close()
+
+	tns := make(map[string]bool)
+	for rows.Next() {
+		var tn string
+		if err := rows.Scan(&tn); err != nil {
+			return nil, fmt.Errorf("failed to scan quarantined number: %w", err)
+		}
+		tns[tn] = true
+	}
+
+	return tns, rows.Err()
+}
+
+// RestoreNumber ends a quarantine still within its aging period and
+// reactivates the number unchanged. It reports false if the number is not
+// restorable.
+func (r *NumberRepository) RestoreNumber(ctx context.Context, id uuid.UUID, restoredBy uuid.UUID) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE numbers.number_quarantine
+		SET status = 'RESTORED', restored_at = NOW(), restored_by = $2
+		WHERE number_id = $1 AND status = 'QUARANTINE' AND release_after > NOW()
+	`, id, restoredBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to end quarantine: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE numbers.assigned_numbers
+		SET active = true, released_at = NULL, release_reason = NULL, updated_by = $2
+		WHERE id = $1
+	`, id, restoredBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to reactivate number: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit restore: %w", err)
+	}
+	return true, nil
+}
+
+// ClaimQuarantineDue claims up to limit quarantines whose aging period and
+// retry time have passed, leasing them until leaseUntil so other workers
+// skip them
+func (r *NumberRepository) ClaimQuarantineDue(ctx context.Context, leaseUntil time.Time, limit int) ([]models.NumberQuarantine, error) {
+	query := `
+		UPDATE numbers.number_quarantine q
+		SET next_attempt_at = $1
+		FROM numbers.assigned_numbers n
+		WHERE n.id = q.number_id
+		AND q.number_id IN (
+			SELECT number_id FROM numbers.number_quarantine
+			WHERE status = 'QUARANTINE' AND release_after <= NOW() AND next_attempt_at <= NOW()
+			ORDER BY release_after
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + quarantineColumns
+
+	rows, err := r.db.Query(ctx, query, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim quarantined numbers: %w", err)
+	}
+	defer rows.Close()
+
+	return collectQuarantine(rows)
+}
+
+// CompleteQuarantine records that a quarantined number was released upstream
+func (r *NumberRepository) CompleteQuarantine(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE numbers.number_quarantine
+		SET status = 'RELEASED', released_at = NOW(), last_error = NULL
+		WHERE number_id = $1 AND status = 'QUARANTINE'
+	`
+
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to complete quarantine: %w", err)
+	}
+	return nil
+}
+
+// ScheduleQuarantineRetry counts a failed upstream release and sets its next attempt
+func (r *NumberRepository) ScheduleQuarantineRetry(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE numbers.number_quarantine
+		SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE number_id = $1 AND status = 'QUARANTINE'
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, lastError, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to schedule quarantine retry: %w", err)
+	}
+	return nil
+}
+
+// FailQuarantine gives up on a quarantine's upstream release, leaving it
+// FAILED for manual follow-up
+func (r *NumberRepository) FailQuarantine(ctx context.Context, id uuid.UUID, lastError string) error {
+	query := `
+		UPDATE numbers.number_quarantine
+		SET status = 'FAILED', attempts = attempts + 1, last_error = $2
+		WHERE number_id = $1 AND status = 'QUARANTINE'
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, lastError); err != nil {
+		return fmt.Errorf("failed to mark quarantine failed: %w", err)
+	}
+	return nil
+}
+
+func collectQuarantine(rows pgx.Rows) ([]models.NumberQuarantine, error) {
+	quarantined := []models.NumberQuarantine{}
+	for rows.Next() {
+		q, err := scanQuarantine(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan quarantined number: %w", err)
+		}
+		quarantined = append(quarantined, *q)
+	}
+
+	return quarantined, rows.Err()
+}
+
+func scanQuarantine(row pgx.Row) (*models.NumberQuarantine, error) {
+	q := &models.NumberQuarantine{}
+	err := row.Scan(
+		&q.NumberID, &q.Number, &q.CustomerID, &q.Status, &q.ReleaseReason,
+		&q.QuarantinedAt, &q.ReleaseAfter, &q.QuarantinedBy,
+		&q.RestoredAt, &q.RestoredBy, &q.ReleasedAt,
+		&q.Attempts, &q.LastError, &q.NextAttemptAt, &q.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
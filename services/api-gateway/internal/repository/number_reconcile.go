@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ringer-warp/api-gateway/internal/models"
+)
+
+// ============================================================================
+// SOA Reconciliation (run history and drift findings)
+// ============================================================================
+
+// LocalNumberRef is the slice of an active assigned number the SOA reconciler compares
+type LocalNumberRef struct {
+	ID            uuid.UUID
+	CustomerID    uuid.UUID
+	Number        string
+	SOASyncStatus string
+}
+
+const reconcileRunColumns = `
+	id, trigger, status, dry_run, soa_numbers, local_numbers, matched, auto_fixed, flagged,
+	error, started_at, completed_at, duration_ms
+`
+
+const soaDriftColumns = `
+	id, number, drift_type, action, assigned_number_id, customer_id,
+	soa_status, soa_application_id, soa_lrn, soa_port_direction, COALESCE(detail, ''),
+	first_seen_run_id, last_seen_run_id, first_seen_at, last_seen_at, occurrences,
+	resolved_at, resolution, resolved_by, resolution_note
+`
+
+// ListActiveNumberRefs retrieves every active assigned number keyed by
+// telephone number. Toll-free numbers provisioned through the SMS/800
+// registry are not in SOA and are left out.
+func (r *NumberRepository) ListActiveNumberRefs(ctx context.Context) (map[string]LocalNumberRef, error) {
+	query := `
+		SELECT n.id, n.customer_id, n.number, n.soa_sync_status
+		FROM numbers.assigned_numbers n
+		WHERE n.active = true
+		  AND NOT EXISTS (SELECT 1 FROM numbers.toll_free t WHERE t.number_id = n.id)
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active numbers: %w", err)
+	}
+	defer rows.Close()
+
+	refs := make(map[string]LocalNumberRef)
+	for rows.Next() {
+		var ref LocalNumberRef
+		if err := rows.Scan(&ref.ID, &ref.CustomerID, &ref.Number, &ref.SOASyncStatus); err != nil {
+			return nil, fmt.Errorf("failed to scan active number: %w", err)
+		}
+		refs[ref.Number] = ref
+	}
+
+	return refs, rows.Err()
+}
+
+// MarkNumbersVerified stamps soa_last_synced on numbers found in sync with SOA.
+// FAILED numbers return to SYNCED; PENDING is left for the metadata push.
+func (r *NumberRepository) MarkNumbersVerified(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE numbers.assigned_numbers
+		SET soa_last_synced = NOW(),
+		    soa_sync_status = CASE WHEN soa_sync_status = 'FAILED' THEN 'SYNCED' ELSE soa_sync_status END
+		WHERE id = ANY($1)
+	`
+
+	if _, err := r.db.Exec(ctx, query, ids); err != nil {
+		return fmt.Errorf("failed to mark numbers verified: %w", err)
+	}
+	return nil
+}
+
+// ReleasePortedOut releases an active number that SOA reports ported away.
+// It reports false if the number was no longer active.
+func (r *NumberRepository) ReleasePortedOut(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE numbers.assigned_numbers
+		SET active = false,
+		    released_at = NOW(),
+		    release_reason = 'PORTED_OUT',
+		    soa_sync_status = 'SYNCED',
+		    soa_last_synced = NOW()
+		WHERE id = $1 AND active = true
+	`
+
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to release ported-out number: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// CreateReconcileRun starts a RUNNING reconciliation run
+func (r *NumberRepository) CreateReconcileRun(ctx context.Context, trigger string, dryRun bool) (*models.SOAReconcileRun, error) {
+	query := `
+		INSERT INTO numbers.soa_reconcile_runs (trigger, dry_run)
+		VALUES ($1, $2)
+		RETURNING ` + reconcileRunColumns
+
+	run, err := scanReconcileRun(r.db.QueryRow(ctx, query, trigger, dryRun))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reconcile run: %w", err)
+	}
+	return run, nil
+}
+
+// FinishReconcileRun stores a run's final status and counters
+func (r *NumberRepository) FinishReconcileRun(ctx context.Context, run *models.SOAReconcileRun) error {
+	query := `
+		UPDATE numbers.soa_reconcile_runs
+		SET status = $2, soa_numbers = $3, local_numbers = $4, matched = $5,
+		    auto_fixed = $6, flagged = $7, error = $8,
+		    completed_at = $9, duration_ms = $10
+		WHERE id = $1
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		run.ID, run.Status, run.SOANumbers, run.LocalNumbers, run.Matched,
+		run.AutoFixed, run.Flagged, run.Error,
+		run.CompletedAt, run.DurationMs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to finish reconcile run: %w", err)
+	}
+	return nil
+}
+
+// ListReconcileRuns retrieves the most recent reconciliation runs
+func (r *NumberRepository) ListReconcileRuns(ctx context.Context, limit int) ([]models.SOAReconcileRun, error) {
+	query := `
+		SELECT ` + reconcileRunColumns + `
+		FROM numbers.soa_reconcile_runs
+		ORDER BY started_at DESC
+		LIMIT $1
+	`
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reconcile runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.SOAReconcileRun{}
+	for rows.Next() {
+		run, err := scanReconcileRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reconcile run: %w", err)
+		}
+		runs = append(runs, *run)
+	}
+
+	return runs, rows.Err()
+}
+
+// RecordDrift stores a drift finding. A FLAGGED finding already open for the
+// same number and type is refreshed instead of duplicated; AUTO_FIXED
+// findings are stored resolved.
+func (r *NumberRepository) RecordDrift(ctx context.Context, drift *models.SOADrift) error {
+	query := `
+		INSERT INTO numbers.soa_drift (
+			number, drift_type, action, assigned_number_id, customer_id,
+			soa_status, soa_application_id, soa_lrn, soa_port_direction, detail,
+			first_seen_run_id, last_seen_run_id,
+			resolved_at, resolution
+		) VALUES (
+			$1, $2, $3, $4, $5,
+			$6, $7, $8, $9, $10,
+			$11, $11,
+			CASE WHEN $3 = 'AUTO_FIXED' THEN NOW() END,
+			CASE WHEN $3 = 'AUTO_FIXED' THEN 'AUTO_FIXED' END
+		)
+		ON CONFLICT (number, drift_type) WHERE resolved_at IS NULL
+		DO UPDATE SET
+			assigned_number_id = EXCLUDED.assigned_number_id,
+			customer_id = EXCLUDED.customer_id,
+			soa_status = EXCLUDED.soa_status,
+			soa_application_id = EXCLUDED.soa_application_id,
+			soa_lrn = EXCLUDED.soa_lrn,
+			soa_port_direction = EXCLUDED.soa_port_direction,
+			detail = EXCLUDED.detail,
+			last_seen_run_id = EXCLUDED.last_seen_run_id,
+			last_seen_at = NOW(),
+			occurrences = numbers.soa_drift.occurrences + 1
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		drift.Number, drift.DriftType, drift.Action, drift.AssignedNumberID, drift.CustomerID,
+		drift.SOAStatus, drift.SOAApplicationID, drift.SOALRN, drift.SOAPortDirection, drift.Detail,
+		drift.LastSeenRunID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record drift: %w", err)
+	}
+	return nil
+}
+
+// ClearStaleDrift resolves open findings not seen by a completed run
+func (r *NumberRepository) ClearStaleDrift(ctx context.Context, runID uuid.UUID) (int64, error) {
+	query := `
+		UPDATE numbers.soa_drift
+		SET resolved_at = NOW(), resolution = 'CLEARED'
+		WHERE resolved_at IS NULL AND last_seen_run_id <> $1
+	`
+
+	tag, err := r.db.Exec(ctx, query, runID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear stale drift: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListDrift retrieves drift findings, newest first. openOnly limits to
+// unresolved findings; driftType filters when non-empty.
+func (r *NumberRepository) ListDrift(ctx context.Context, openOnly bool, driftType string, limit int) ([]models.SOADrift, error) {
+	query := `
+		SELECT ` + soaDriftColumns + `
+		FROM numbers.soa_drift
+		WHERE (NOT $1 OR resolved_at IS NULL)
+		  AND ($2 = '' OR drift_type = $2)
+		ORDER BY last_seen_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(ctx, query, openOnly, driftType, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drift: %w", err)
+	}
+	defer rows.Close()
+
+	findings := []models.SOADrift{}
+	for rows.Next() {
+		drift, err := scanSOADrift(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan drift: %w", err)
+		}
+		findings = append(findings, *drift)
+	}
+
+	return findings, rows.Err()
+}
+
+// CountOpenDriftByType counts unresolved findings per drift type
+func (r *NumberRepository) CountOpenDriftByType(ctx context.Context) (map[string]int64, error) {
+	query := `
+		SELECT drift_type, COUNT(*)
+		FROM numbers.soa_drift
+		WHERE resolved_at IS NULL
+		GROUP BY drift_type
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count drift: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var driftType string
+		var count int64
+		if err := rows.Scan(&driftType, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan drift count: %w", err)
+		}
+		counts[driftType] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// AcknowledgeDrift closes an open finding on behalf of an operator (nil if
+// the finding does not exist or is already resolved)
+func (r *NumberRepository) AcknowledgeDrift(ctx context.Context, id, resolvedBy uuid.UUID, note string) (*models.SOADrift, error) {
+	query := `
+		UPDATE numbers.soa_drift
+		SET resolved_at = NOW(), resolution = 'ACKNOWLEDGED',
+		    resolved_by = $2, resolution_note = NULLIF($3, '')
+		WHERE id = $1 AND resolved_at IS NULL
+		RETURNING ` + soaDriftColumns
+
+	drift, err := scanSOADrift(r.db.QueryRow(ctx, query, id, resolvedBy, note))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to acknowledge drift: %w", err)
+	}
+	return drift, nil
+}
+
+// scanReconcileRun scans one row selected with reconcileRunColumns
+func scanReconcileRun(row pgx.Row) (*models.SOAReconcileRun, error) {
+	run := &models.SOAReconcileRun{}
+	err := row.Scan(
+		&run.ID, &run.Trigger, &run.Status, &run.DryRun, &run.SOANumbers, &run.LocalNumbers, &run.Matched,
+		&run.AutoFixed, &run.Flagged, &run.Error, &run.StartedAt, &run.CompletedAt, &run.DurationMs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// scanSOADrift scans one row selected with soaDriftColumns
+func scanSOADrift(row pgx.Row) (*models.SOADrift, error) {
+	drift := &models.SOADrift{}
+	err := row.Scan(
+		&drift.ID, &drift.Number, &drift.DriftType, &drift.Action, &drift.AssignedNumberID, &drift.CustomerID,
+		&drift.SOAStatus, &drift.SOAApplicationID, &drift.SOALRN, &drift.SOAPortDirection, &drift.Detail,
+		&drift.FirstSeenRunID, &drift.LastSeenRunID, &drift.FirstSeenAt, &drift.LastSeenAt, &drift.Occurrences,
+		&drift.ResolvedAt, &drift.Resolution, &drift.ResolvedBy, &drift.ResolutionNote,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return drift, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ringer-warp/api-gateway/internal/models"
+)
+
+// ============================================================================
+// Inbound DID Routing
+// ============================================================================
+
+// didRouteQuery selects the routing of active, voice-enabled numbers with the
+// customer BAN the SIP edge tags calls with
+const didRouteQuery = `
+	SELECT n.id, n.number, n.customer_id, c.ban, n.trunk_id,
+	       n.voice_destination, n.voice_failover_destination, n.voice_routing_type
+	FROM numbers.assigned_numbers n
+	JOIN accounts.customers c ON c.id = n.customer_id
+	WHERE n.active = true AND n.voice_enabled = true
+`
+
+// GetDIDRoute retrieves a number's inbound routing (nil if the number is
+// released or not voice-enabled)
+func (r *NumberRepository) GetDIDRoute(ctx context.Context, numberID uuid.UUID) (*models.DIDRouteEntry, error) {
+	entry, err := scanDIDRoute(r.db.QueryRow(ctx, didRouteQuery+` AND n.id = $1`, numberID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DID route: %w", err)
+	}
+	return entry, nil
+}
+
+// ListDIDRoutes retrieves the inbound routing of every active, voice-enabled
+// number across all customers
+func (r *NumberRepository) ListDIDRoutes(ctx context.Context) ([]models.DIDRouteEntry, error) {
+	rows, err := r.db.Query(ctx, didRouteQuery+` ORDER BY n.number`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DID routes: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.DIDRouteEntry
+	for rows.Next() {
+		entry, err := scanDIDRoute(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan DID route: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func scanDIDRoute(row pgx.Row) (*models.DIDRouteEntry, error) {
+	e := &models.DIDRouteEntry{}
+	err := row.Scan(
+		&e.NumberID, &e.Number, &e.CustomerID, &e.CustomerBAN, &e.TrunkID,
+		&e.VoiceDestination, &e.VoiceFailoverDestination, &e.VoiceRoutingType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/ringer-warp/api-gateway/internal/models"
+)
+
+// ============================================================================
+// Search Cache and Number Rates
+// ============================================================================
+
+// GetSearchCache retrieves unexpired cached search results; found is false on
+// a miss
+func (r *NumberRepository) GetSearchCache(ctx context.Context, searchType, key string) (results []models.NumberSearchResult, total int64, found bool, err error) {
+	var data []byte
+	err = r.db.QueryRow(ctx, `
+		SELECT numbers, available_count
+		FROM numbers.search_cache
+		WHERE search_type = $1 AND search_value = $2 AND expires_at > NOW()
+	`, searchType, key).Scan(&data, &total)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, 0, false, nil
+	}
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to get search cache: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, 0, false, fmt.Errorf("failed to decode search cache: %w", err)
+	}
+	return results, total, true, nil
+}
+
+// StoreSearchCache caches search results for ttl and purges expired entries
+func (r *NumberRepository) StoreSearchCache(ctx context.Context, searchType, key string, results []models.NumberSearchResult, total int64, ttl time.Duration) error {
+	data, err := json.Marshal(results)
+	if err != nil {
+		return fmt.Errorf("failed to encode search cache: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO numbers.search_cache (search_type, search_value, available_count, numbers, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, NOW(), $5)
+		ON CONFLICT (search_type, search_value) DO UPDATE SET
+			available_count = EXCLUDED.available_count,
+			numbers = EXCLUDED.numbers,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+	`, searchType, key, total, data, time.Now().Add(ttl))
+	if err != nil {
+		return fmt.Errorf("failed to store search cache: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, `DELETE FROM numbers.search_cache WHERE expires_at < NOW()`); err != nil {
+		return fmt.Errorf("failed to purge search cache: %w", err)
+	}
+	return nil
+}
+
+// ListNumberRates retrieves every US list price. Rates tied to a rate plan
+// are negotiated per customer and never returned.
+func (r *NumberRepository) ListNumberRates(ctx context.Context) ([]models.NumberRate, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT number_type::text, state, rate_center, monthly_rate, COALESCE(setup_fee, 0)
+		FROM billing.number_rates
+		WHERE COALESCE(country_code, 'USA') IN ('USA', 'US') AND rate_plan_id IS NULL
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list number rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []models.NumberRate
+	for rows.Next() {
+		var rate models.NumberRate
+		if err := rows.Scan(&rate.NumberType, &rate.State, &rate.RateCenter, &rate.MonthlyRate, &rate.SetupFee); err != nil {
+			return nil, fmt.Errorf("failed to scan number rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
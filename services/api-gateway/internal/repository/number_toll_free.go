@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ringer-warp/api-gateway/internal/models"
+)
+
+// ============================================================================
+// Toll-Free Numbers
+// ============================================================================
+
+const tollFreeColumns = `
+	id, customer_id, number, number_id, status,
+	provider, resp_org_id, resp_org_change_date, somos_status, somos_last_updated,
+	reserved_until, last_error,
+	messaging_verification_status, messaging_verification_id, messaging_verification,
+	messaging_rejection_reason, messaging_submitted_at, messaging_updated_at,
+	created_at, updated_at, created_by, updated_by
+`
+
+// ReserveTollFree records a registry reservation for a customer. A RELEASED or
+// EXPIRED record for the number is replaced; an ACTIVE one is left alone and
+// nil is returned.
+func (r *NumberRepository) ReserveTollFree(ctx context.Context, tf *models.TollFreeNumber) (*models.TollFreeNumber, error) {
+	query := `
+		INSERT INTO numbers.toll_free AS t (
+			customer_id, number, status, provider, resp_org_id, somos_status,
+			somos_last_updated, reserved_until, created_by, updated_by
+		) VALUES ($1, $2, 'RESERVED', $3, $4, $5, NOW(), $6, $7, $7)
+		ON CONFLICT (number) DO UPDATE SET
+			customer_id = EXCLUDED.customer_id,
+			number_id = NULL,
+			status = 'RESERVED',
+			provider = EXCLUDED.provider,
+			resp_org_id = EXCLUDED.resp_org_id,
+			somos_status = EXCLUDED.somos_status,
+			somos_last_updated = NOW(),
+			reserved_until = EXCLUDED.reserved_until,
+			last_error = NULL,
+			messaging_verification_status = 'NOT_SUBMITTED',
+			messaging_verification_id = NULL,
+			messaging_verification = NULL,
+			messaging_rejection_reason = NULL,
+			messaging_submitted_at = NULL,
+			messaging_updated_at = NULL,
+			created_by = EXCLUDED.created_by,
+			updated_by = EXCLUDED.updated_by
+		WHERE t.status <> 'ACTIVE'
+		RETURNING ` + tollFreeColumns
+
+	reserved, err := scanTollFree(r.db.QueryRow(ctx, query,
+		tf.CustomerID, tf.Number, tf.Provider, tf.RespOrgID, tf.RegistryStatus,
+		tf.ReservedUntil, tf.CreatedBy,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record toll-free reservation: %w", err)
+	}
+	return reserved, nil
+}
+
+// GetTollFreeByNumber retrieves a toll-free record by telephone number (nil if not found)
+func (r *NumberRepository) GetTollFreeByNumber(ctx context.Context, number string) (*models.TollFreeNumber, error) {
+	return r.getTollFree(ctx, `number = $1`, number)
+}
+
+// GetTollFreeByNumberID retrieves the toll-free record of an assigned number (nil if not found)
+func (r *NumberRepository) GetTollFreeByNumberID(ctx context.Context, numberID uuid.UUID) (*models.TollFreeNumber, error) {
+	return r.getTollFree(ctx, `number_id = $1`, numberID)
+}
+
+func (r *NumberRepository) getTollFree(ctx context.Context, where string, arg interface{}) (*models.TollFreeNumber, error) {
+	query := `SELECT ` + tollFreeColumns + ` FROM numbers.toll_free WHERE ` + where
+
+	tf, err := scanTollFree(r.db.QueryRow(ctx, query, arg))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get toll-free number: %w", err)
+	}
+	return tf, nil
+}
+
+// ListTollFree retrieves a customer's toll-free records, optionally by status
+func (r *NumberRepository) ListTollFree(ctx context.Context, customerID uuid.UUID, status string) ([]models.TollFreeNumber, error) {
+	query := `
+		SELECT ` + tollFreeColumns + `
+		FROM numbers.toll_free
+		WHERE customer_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+	`
+
+	return r.queryTollFree(ctx, query, customerID, status)
+}
+
+// ActivateTollFree links a provisioned reservation to its assigned number
+func (r *NumberRepository) ActivateTollFree(ctx context.Context, id, numberID uuid.UUID, respOrgID, registryStatus string, updatedBy uuid.UUID) error {
+	query := `
+		UPDATE numbers.toll_free
+		SET status = 'ACTIVE', number_id = $2, resp_org_id = $3, somos_status = $4,
+		    somos_last_updated = NOW(), reserved_until = NULL, last_error = NULL, updated_by = $5
+		WHERE id = $1 AND status = 'RESERVED'
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, numberID, respOrgID, registryStatus, updatedBy); err != nil {
+		return fmt.Errorf("failed to activate toll-free number: %w", err)
+	}
+	return nil
+}
+
+// RecordTollFreeError stores the last provisioning error of a toll-free record
+func (r *NumberRepository) RecordTollFreeError(ctx context.Context, id uuid.UUID, errMsg string) error {
+	query := `UPDATE numbers.toll_free SET last_error = $2 WHERE id = $1`
+
+	if _, err := r.db.Exec(ctx, query, id, errMsg); err != nil {
+		return fmt.Errorf("failed to record toll-free error: %w", err)
+	}
+	return nil
+}
+
+// UpdateTollFreeRegistry records a number's registry status and controlling
+// RespOrg, stamping the RespOrg change date when it moves
+func (r *NumberRepository) UpdateTollFreeRegistry(ctx context.Context, id uuid.UUID, respOrgID, registryStatus string, updatedBy *uuid.UUID) (*models.TollFreeNumber, error) {
+	query := `
+		UPDATE numbers.toll_free
+		SET resp_org_change_date = CASE
+		        WHEN resp_org_id IS DISTINCT FROM NULLIF($2, '') THEN CURRENT_DATE
+		        ELSE resp_org_change_date
+		    END,
+		    resp_org_id = NULLIF($2, ''),
+		    somos_status = $3,
+		    somos_last_updated = NOW(),
+		    updated_by = COALESCE($4, updated_by)
+		WHERE id = $1
+		RETURNING ` + tollFreeColumns
+
+	tf, err := scanTollFree(r.db.QueryRow(ctx, query, id, respOrgID, registryStatus, updatedBy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update toll-free registry status: %w", err)
+	}
+	return tf, nil
+}
+
+// ReleaseTollFree marks an assigned number's toll-free record RELEASED
+func (r *NumberRepository) ReleaseTollFree(ctx context.Context, numberID uuid.UUID, registryStatus string, updatedBy uuid.UUID) error {
+	query := `
+		UPDATE numbers.toll_free
+		SET status = 'RELEASED', somos_status = $2, somos_last_updated = NOW(), updated_by = $3
+		WHERE number_id = $1 AND status = 'ACTIVE'
+	`
+
+	if _, err := r.db.Exec(ctx, query, numberID, registryStatus, updatedBy); err != nil {
+		return fmt.Errorf("failed to release toll-free number: %w", err)
+	}
+	return nil
+}
+
+// ExpireTollFreeReservations marks reservations whose registry hold lapsed
+// EXPIRED and returns how many were expired
+func (r *NumberRepository) ExpireTollFreeReservations(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE numbers.toll_free
+		SET status = 'EXPIRED'
+		WHERE status = 'RESERVED' AND reserved_until < NOW()
+	`
+
+	tag, err := r.db.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire toll-free reservations: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// SubmitTollFreeVerification records a messaging verification submission as PENDING
+func (r *NumberRepository) SubmitTollFreeVerification(ctx context.Context, id uuid.UUID, verificationID string, req *models.TollFreeVerificationRequest, updatedBy uuid.UUID) (*models.TollFreeNumber, error) {
+	query := `
+		UPDATE numbers.toll_free
+		SET messaging_verification_status = 'PENDING',
+		    messaging_verification_id = $2,
+		    messaging_verification = $3,
+		    messaging_rejection_reason = NULL,
+		    messaging_submitted_at = NOW(),
+		    messaging_updated_at = NOW(),
+		    updated_by = $4
+		WHERE id = $1
+		RETURNING ` + tollFreeColumns
+
+	tf, err := scanTollFree(r.db.QueryRow(ctx, query, id, verificationID, req, updatedBy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record messaging verification: %w", err)
+	}
+	return tf, nil
+}
+
+// ListPendingTollFreeVerifications retrieves PENDING verifications, least recently checked first
+func (r *NumberRepository) ListPendingTollFreeVerifications(ctx context.Context, limit int) ([]models.TollFreeNumber, error) {
+	query := `
+		SELECT ` + tollFreeColumns + `
+		FROM numbers.toll_free
+		WHERE messaging_verification_status = 'PENDING' AND status = 'ACTIVE'
+		ORDER BY messaging_updated_at
+		LIMIT $1
+	`
+
+	return r.queryTollFree(ctx, query, limit)
+}
+
+// UpdateTollFreeVerification records a verification status check. The
+// verification ID guards against overwriting a newer submission.
+func (r *NumberRepository) UpdateTollFreeVerification(ctx context.Context, id uuid.UUID, verificationID, status string, rejectionReason *string) error {
+	query := `
+		UPDATE numbers.toll_free
+		SET messaging_verification_status = $3,
+		    messaging_rejection_reason = $4,
+		    messaging_updated_at = NOW()
+		WHERE id = $1 AND messaging_verification_id = $2
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, verificationID, status, rejectionReason); err != nil {
+		return fmt.Errorf("failed to update messaging verification: %w", err)
+	}
+	return nil
+}
+
+func (r *NumberRepository) queryTollFree(ctx context.Context, query string, args ...interface{}) ([]models.TollFreeNumber, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list toll-free numbers: %w", err)
+	}
+	defer rows.Close()
+
+	var numbers []models.TollFreeNumber
+	for rows.Next() {
+		tf, err := scanTollFree(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan toll-free number: %w", err)
+		}
+		numbers = append(numbers, *tf)
+	}
+
+	return numbers, rows.Err()
+}
+
+// scanTollFree scans one row selected with tollFreeColumns
+func scanTollFree(row pgx.Row) (*models.TollFreeNumber, error) {
+	tf := &models.TollFreeNumber{}
+	err := row.Scan(
+		&tf.ID, &tf.CustomerID, &tf.Number, &tf.NumberID, &tf.Status,
+		&tf.Provider, &tf.RespOrgID, &tf.RespOrgChangeDate, &tf.RegistryStatus, &tf.RegistryUpdatedAt,
+		&tf.ReservedUntil, &tf.LastError,
+		&tf.MessagingVerificationStatus, &tf.MessagingVerificationID, &tf.MessagingVerification,
+		&tf.MessagingRejectionReason, &tf.MessagingSubmittedAt, &tf.MessagingUpdatedAt,
+		&tf.CreatedAt, &tf.UpdatedAt, &tf.CreatedBy, &tf.UpdatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return tf, nil
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ringer-warp/api-gateway/internal/models"
+)
+
+// SMPPBindRepository handles customer SMPP credentials in messaging.customer_sms_auth
+type SMPPBindRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewSMPPBindRepository creates a new SMPPBindRepository
+func NewSMPPBindRepository(db *pgxpool.Pool) *SMPPBindRepository {
+	return &SMPPBindRepository{db: db}
+}
+
+// smppBindColumns is the SELECT list matching scanSMPPBind
+// INET[] is read back as text[] so addresses keep their CIDR notation
+const smppBindColumns = `
+	id, customer_id, smpp_system_id, smpp_allowed_ips::text[], smpp_max_binds, smpp_throughput,
+	active, password_rotated_at, created_at, updated_at, created_by
+`
+
+func scanSMPPBind(row pgx.Row) (*models.SMPPBind, error) {
+	bind := &models.SMPPBind{}
+	err := row.Scan(
+		&bind.ID, &bind.CustomerID, &bind.SystemID, &bind.AllowedIPs, &bind.MaxBinds, &bind.Throughput,
+		&bind.Active, &bind.PasswordRotatedAt, &bind.CreatedAt, &bind.UpdatedAt, &bind.CreatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return bind, nil
+}
+
+// Create inserts a new SMPP credential
+func (r *SMPPBindRepository) Create(ctx context.Context, bind *models.SMPPBind, passwordHash string) error {
+	query := `
+		INSERT INTO messaging.customer_sms_auth (
+			id, customer_id, smpp_system_id, smpp_password_hash, smpp_allowed_ips,
+			smpp_max_binds, smpp_throughput, active, created_by
+		) VALUES (
+			$1, $2, $3, $4, $5::inet[], $6, $7, $8, $9
+		)
+		RETURNING password_rotated_at, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		bind.ID, bind.CustomerID, bind.SystemID, passwordHash, bind.AllowedIPs,
+		bind.MaxBinds, bind.Throughput, bind.Active, bind.CreatedBy,
+	).Scan(&bind.PasswordRotatedAt, &bind.CreatedAt, &bind.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create SMPP bind: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an SMPP credential by ID (nil if not found)
+func (r *SMPPBindRepository) GetByID(ctx context.Context, bindID uuid.UUID) (*models.SMPPBind, error) {
+	query := `SELECT ` + smppBindColumns + ` FROM messaging.customer_sms_auth WHERE id = $1`
+
+	bind, err := scanSMPPBind(r.db.QueryRow(ctx, query, bindID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SMPP bind: %w", err)
+	}
+
+	return bind, nil
+}
+
+// SystemIDExists checks whether a system_id is already taken by any customer
+func (r *SMPPBindRepository) SystemIDExists(ctx context.Context, systemID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM messaging.customer_sms_auth WHERE smpp_system_id = $1)`
+
+	var exists bool
+	if err := r.db.QueryRow(ctx, query, systemID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check system_id: %w", err)
+	}
+
+	return exists, nil
+}
+
+// ListByCustomer retrieves all SMPP credentials for a customer
+func (r *SMPPBindRepository) ListByCustomer(ctx context.Context, customerID uuid.UUID) ([]models.SMPPBind, error) {
+	query := `SELECT ` + smppBindColumns + `
+		FROM messaging.customer_sms_auth
+		WHERE customer_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SMPP binds: %w", err)
+	}
+	defer rows.Close()
+
+	binds := []models.SMPPBind{}
+	for rows.Next() {
+		bind, err := scanSMPPBind(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan SMPP bind: %w", err)
+		}
+		binds = append(binds, *bind)
+	}
+
+	return binds, rows.Err()
+}
+
+// Update applies limit, IP and status changes to an SMPP credential
+func (r *SMPPBindRepository) Update(ctx context.Context, bindID uuid.UUID, req models.UpdateSMPPBindRequest) error {
+	// Build dynamic update query
+	updates := []string{}
+	args := []interface{}{bindID}
+	argCount := 2
+
+	if req.AllowedIPs != nil {
+		updates = append(updates, fmt.Sprintf("smpp_allowed_ips = $%d::inet[]", argCount))
+		args = append(args, *req.AllowedIPs)
+		argCount++
+	}
+	if req.MaxBinds != nil {
+		updates = append(updates, fmt.Sprintf("smpp_max_binds = $%d", argCount))
+		args = append(args, *req.MaxBinds)
+		argCount++
+	}
+	if req.Throughput != nil {
+		updates = append(updates, fmt.Sprintf("smpp_throughput = $%d", argCount))
+		args = append(args, *req.Throughput)
+		argCount++
+	}
+	if req.Active != nil {
+		updates = append(updates, fmt.Sprintf("active = $%d", argCount))
+		args = append(args, *req.Active)
+		argCount++
+	}
+
+	if len(updates) == 0 {
+		return nil // Nothing to update
+	}
+
+	query := fmt.Sprintf("UPDATE messaging.customer_sms_auth SET %s WHERE id = $1", join(updates, ", "))
+	_, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update SMPP bind: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePassword replaces the password hash and records the rotation time
+func (r *SMPPBindRepository) UpdatePassword(ctx context.Context, bindID uuid.UUID, passwordHash string) error {
+	query := `
+		UPDATE messaging.customer_sms_auth
+		SET smpp_password_hash = $2, password_rotated_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := r.db.Exec(ctx, query, bindID, passwordHash)
+	if err != nil {
+		return fmt.Errorf("failed to rotate SMPP password: %w", err)
+	}
+
+	return nil
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/ringer-warp/api-gateway/internal/models"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -116,6 +118,26 @@ func (r *TrunkRepository) ListTrunkGroupsByCustomer(ctx context.Context, custome
 	return trunks, nil
 }
 
+// ListTrunkCustomerIDs returns every customer that has at least one trunk group
+func (r *TrunkRepository) ListTrunkCustomerIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `SELECT DISTINCT customer_id FROM accounts.trunk_groups`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trunk customers: %w", err)
+	}
+	defer rows.Close()
+
+	var customerIDs []uuid.UUID
+	for rows.Next() {
+		var customerID uuid.UUID
+		if err := rows.Scan(&customerID); err != nil {
+			return nil, fmt.Errorf("failed to scan trunk customer: %w", err)
+		}
+		customerIDs = append(customerIDs, customerID)
+	}
+
+	return customerIDs, rows.Err()
+}
+
 // UpdateTrunkGroup updates a trunk group
 func (r *TrunkRepository) UpdateTrunkGroup(ctx context.Context, trunkID uuid.UUID, req models.UpdateTrunkGroupRequest) error {
 	// Build dynamic update query
@@ -192,12 +214,22 @@ func (r *TrunkRepository) AddTrunkIP(ctx context.Context, trunkID uuid.UUID, req
 	if req.Netmask != nil {
 		netmask = *req.Netmask
 	}
+	port := 5060
+	if req.Port != nil {
+		port = *req.Port
+	}
+	transport := "any"
+	if req.Transport != nil {
+		transport = *req.Transport
+	}
 
 	trunkIP := &models.TrunkIP{
 		ID:           uuid.New(),
 		TrunkGroupID: trunkID,
 		IPAddress:    req.IPAddress,
 		Netmask:      netmask,
+		Port:         port,
+		Transport:    transport,
 		Description:  req.Description,
 		Enabled:      true,
 		CreatedBy:    &createdBy,
@@ -205,16 +237,16 @@ func (r *TrunkRepository) AddTrunkIP(ctx context.Context, trunkID uuid.UUID, req
 
 	query := `
 		INSERT INTO accounts.trunk_ips (
-			id, trunk_group_id, ip_address, netmask, description, enabled, created_by
+			id, trunk_group_id, ip_address, netmask, port, transport, description, enabled, created_by
 		) VALUES (
-			$1, $2, $3::inet, $4, $5, $6, $7
+			$1, $2, $3::inet, $4, $5, $6, $7, $8, $9
 		)
 		RETURNING created_at, updated_at
 	`
 
 	err := r.db.QueryRow(ctx, query,
 		trunkIP.ID, trunkIP.TrunkGroupID, trunkIP.IPAddress, trunkIP.Netmask,
-		trunkIP.Description, trunkIP.Enabled, trunkIP.CreatedBy,
+		trunkIP.Port, trunkIP.Transport, trunkIP.Description, trunkIP.Enabled, trunkIP.CreatedBy,
 	).Scan(&trunkIP.CreatedAt, &trunkIP.UpdatedAt)
 
 	if err != nil {
@@ -227,7 +259,7 @@ func (r *TrunkRepository) AddTrunkIP(ctx context.Context, trunkID uuid.UUID, req
 // ListTrunkIPs retrieves all IP ACL entries for a trunk group
 func (r *TrunkRepository) ListTrunkIPs(ctx context.Context, trunkID uuid.UUID) ([]models.TrunkIP, error) {
 	query := `
-		SELECT id, trunk_group_id, ip_address::text, netmask, description, enabled,
+		SELECT id, trunk_group_id, host(ip_address), netmask, port, transport, description, enabled,
 		       created_at, updated_at, created_by
 		FROM accounts.trunk_ips
 		WHERE trunk_group_id = $1
@@ -244,7 +276,7 @@ func (r *TrunkRepository) ListTrunkIPs(ctx context.Context, trunkID uuid.UUID) (
 	for rows.Next() {
 		var ip models.TrunkIP
 		err := rows.Scan(
-			&ip.ID, &ip.TrunkGroupID, &ip.IPAddress, &ip.Netmask, &ip.Description,
+			&ip.ID, &ip.TrunkGroupID, &ip.IPAddress, &ip.Netmask, &ip.Port, &ip.Transport, &ip.Description,
 			&ip.Enabled, &ip.CreatedAt, &ip.UpdatedAt, &ip.CreatedBy,
 		)
 		if err != nil {
@@ -261,14 +293,14 @@ func (r *TrunkRepository) GetTrunkIP(ctx context.Context, ipID uuid.UUID) (*mode
 	ip := &models.TrunkIP{}
 
 	query := `
-		SELECT id, trunk_group_id, ip_address::text, netmask, description, enabled,
+		SELECT id, trunk_group_id, host(ip_address), netmask, port, transport, description, enabled,
 		       created_at, updated_at, created_by
 		FROM accounts.trunk_ips
 		WHERE id = $1
 	`
 
 	err := r.db.QueryRow(ctx, query, ipID).Scan(
-		&ip.ID, &ip.TrunkGroupID, &ip.IPAddress, &ip.Netmask, &ip.Description,
+		&ip.ID, &ip.TrunkGroupID, &ip.IPAddress, &ip.Netmask, &ip.Port, &ip.Transport, &ip.Description,
 		&ip.Enabled, &ip.CreatedAt, &ip.UpdatedAt, &ip.CreatedBy,
 	)
 
@@ -295,6 +327,16 @@ func (r *TrunkRepository) UpdateTrunkIP(ctx context.Context, ipID uuid.UUID, req
 		args = append(args, *req.Enabled)
 		argCount++
 	}
+	if req.Port != nil {
+		updates = append(updates, fmt.Sprintf("port = $%d", argCount))
+		args = append(args, *req.Port)
+		argCount++
+	}
+	if req.Transport != nil {
+		updates = append(updates, fmt.Sprintf("transport = $%d", argCount))
+		args = append(args, *req.Transport)
+		argCount++
+	}
 
 	if len(updates) == 0 {
 		return nil // No updates
@@ -369,6 +411,41 @@ func (r *TrunkRepository) ListTrunkGroupsWithIPsByCustomer(ctx context.Context,
 	return result, nil
 }
 
+// ListEnabledTrunkAddresses retrieves every enabled IP on an enabled trunk
+// group across all customers, with the customer BAN used as the Kamailio tag
+func (r *TrunkRepository) ListEnabledTrunkAddresses(ctx context.Context) ([]models.TrunkAddressEntry, error) {
+	query := `
+		SELECT ti.id, ti.trunk_group_id, tg.customer_id, c.ban, host(ti.ip_address), ti.netmask,
+		       ti.port, ti.transport
+		FROM accounts.trunk_ips ti
+		JOIN accounts.trunk_groups tg ON tg.id = ti.trunk_group_id
+		JOIN accounts.customers c ON c.id = tg.customer_id
+		WHERE ti.enabled = true AND tg.enabled = true
+		ORDER BY ti.id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trunk addresses: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.TrunkAddressEntry
+	for rows.Next() {
+		var entry models.TrunkAddressEntry
+		err := rows.Scan(
+			&entry.IPID, &entry.TrunkGroupID, &entry.CustomerID, &entry.CustomerBAN,
+			&entry.IPAddress, &entry.Netmask, &entry.Port, &entry.Transport,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trunk address: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
 // ============================================================================
 // Customer Access Verification
 // ============================================================================
@@ -410,6 +487,30 @@ func (r *TrunkRepository) VerifyTrunkIPAccess(ctx context.Context, ipID uuid.UUI
 	return exists, nil
 }
 
+// FindOverlappingTrunkIP returns a range (CIDR text) registered to another
+// customer that overlaps ipAddress/netmask, or "" if there is none
+func (r *TrunkRepository) FindOverlappingTrunkIP(ctx context.Context, ipAddress string, netmask int, customerID uuid.UUID) (string, error) {
+	query := `
+		SELECT set_masklen(ti.ip_address, ti.netmask)::text
+		FROM accounts.trunk_ips ti
+		JOIN accounts.trunk_groups tg ON tg.id = ti.trunk_group_id
+		WHERE tg.customer_id <> $3
+		  AND set_masklen(ti.ip_address, ti.netmask) && set_masklen($1::inet, $2)
+		LIMIT 1
+	`
+
+	var conflict string
+	err := r.db.QueryRow(ctx, query, ipAddress, netmask, customerID).Scan(&conflict)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check trunk IP overlap: %w", err)
+	}
+
+	return conflict, nil
+}
+
 // ============================================================================
 // Helper Functions
 // ============================================================================
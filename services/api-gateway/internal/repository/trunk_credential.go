@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ============================================================================
+// Trunk SIP Digest Credentials
+// ============================================================================
+
+const trunkCredentialColumns = `
+	id, trunk_group_id, username, realm, COALESCE(description, ''), status,
+	last_rotated_at, revoked_at, created_at, updated_at, created_by
+`
+
+// CreateTrunkCredential stores a new digest credential with its HA1 hashes
+func (r *TrunkRepository) CreateTrunkCredential(ctx context.Context, cred *models.TrunkCredential, ha1, ha1b string) error {
+	query := `
+		INSERT INTO accounts.trunk_credentials (
+			id, trunk_group_id, username, realm, ha1, ha1b, description, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING status, last_rotated_at, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		cred.ID, cred.TrunkGroupID, cred.Username, cred.Realm, ha1, ha1b, cred.Description, cred.CreatedBy,
+	).Scan(&cred.Status, &cred.LastRotatedAt, &cred.CreatedAt, &cred.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create trunk credential: %w", err)
+	}
+
+	return nil
+}
+
+// GetTrunkCredential retrieves a credential by ID (nil if not found)
+func (r *TrunkRepository) GetTrunkCredential(ctx context.Context, credID uuid.UUID) (*models.TrunkCredential, error) {
+	query := `SELECT ` + trunkCredentialColumns + ` FROM accounts.trunk_credentials WHERE id = $1`
+
+	cred, err := scanTrunkCredential(r.db.QueryRow(ctx, query, credID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trunk credential: %w", err)
+	}
+
+	return cred, nil
+}
+
+// ListTrunkCredentials retrieves all credentials (active and revoked) for a trunk group
+func (r *TrunkRepository) ListTrunkCredentials(ctx context.Context, trunkID uuid.UUID) ([]models.TrunkCredential, error) {
+	query := `
+		SELECT ` + trunkCredentialColumns + `
+		FROM accounts.trunk_credentials
+		WHERE trunk_group_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, trunkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trunk credentials: %w", err)
+	}
+	defer rows.Close()
+
+	creds := []models.TrunkCredential{}
+	for rows.Next() {
+		cred, err := scanTrunkCredential(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trunk credential: %w", err)
+		}
+		creds = append(creds, *cred)
+	}
+
+	return creds, rows.Err()
+}
+
+// GetTrunkCredentialHashes retrieves the HA1 hashes of a credential
+func (r *TrunkRepository) GetTrunkCredentialHashes(ctx context.Context, credID uuid.UUID) (string, string, error) {
+	var ha1, ha1b string
+	query := `SELECT ha1, ha1b FROM accounts.trunk_credentials WHERE id = $1`
+	if err := r.db.QueryRow(ctx, query, credID).Scan(&ha1, &ha1b); err != nil {
+		return "", "", fmt.Errorf("failed to get trunk credential hashes: %w", err)
+	}
+	return ha1, ha1b, nil
+}
+
+// UpdateTrunkCredentialHashes replaces the HA1 hashes of an active credential after a rotation
+func (r *TrunkRepository) UpdateTrunkCredentialHashes(ctx context.Context, credID uuid.UUID, ha1, ha1b string) error {
+	query := `
+		UPDATE accounts.trunk_credentials
+		SET ha1 = $2, ha1b = $3, last_rotated_at = NOW()
+		WHERE id = $1 AND status = 'ACTIVE'
+	`
+
+	tag, err := r.db.Exec(ctx, query, credID, ha1, ha1b)
+	if err != nil {
+		return fmt.Errorf("failed to rotate trunk credential: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("trunk credential %s is not active", credID)
+	}
+
+	return nil
+}
+
+// RevokeTrunkCredential marks a credential revoked
+func (r *TrunkRepository) RevokeTrunkCredential(ctx context.Context, credID uuid.UUID) error {
+	query := `
+		UPDATE accounts.trunk_credentials
+		SET status = 'REVOKED', revoked_at = NOW()
+		WHERE id = $1 AND status = 'ACTIVE'
+	`
+
+	if _, err := r.db.Exec(ctx, query, credID); err != nil {
+		return fmt.Errorf("failed to revoke trunk credential: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeTrunkCredentials revokes every active credential of a trunk group
+func (r *TrunkRepository) RevokeTrunkCredentials(ctx context.Context, trunkID uuid.UUID) error {
+	query := `
+		UPDATE accounts.trunk_credentials
+		SET status = 'REVOKED', revoked_at = NOW()
+		WHERE trunk_group_id = $1 AND status = 'ACTIVE'
+	`
+
+	if _, err := r.db.Exec(ctx, query, trunkID); err != nil {
+		return fmt.Errorf("failed to revoke trunk credentials: %w", err)
+	}
+
+	return nil
+}
+
+// TrunkCredentialUsernameExists checks whether an active credential already uses the username in a realm
+func (r *TrunkRepository) TrunkCredentialUsernameExists(ctx context.Context, username, realm string) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM accounts.trunk_credentials
+			WHERE username = $1 AND realm = $2 AND status = 'ACTIVE'
+		)
+	`
+
+	var exists bool
+	if err := r.db.QueryRow(ctx, query, username, realm).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check credential username: %w", err)
+	}
+
+	return exists, nil
+}
+
+// ListActiveTrunkCredentialEntries retrieves every active credential on an
+// enabled DIGEST or BOTH trunk group, with its hashes and the customer BAN
+func (r *TrunkRepository) ListActiveTrunkCredentialEntries(ctx context.Context) ([]models.TrunkCredentialEntry, error) {
+	query := `
+		SELECT tc.id, tc.trunk_group_id, c.ban, tc.username, tc.realm, tc.ha1, tc.ha1b
+		FROM accounts.trunk_credentials tc
+		JOIN accounts.trunk_groups tg ON tg.id = tc.trunk_group_id
+		JOIN accounts.customers c ON c.id = tg.customer_id
+		WHERE tc.status = 'ACTIVE' AND tg.enabled = true AND tg.auth_type <> 'IP_ACL'
+		ORDER BY tc.id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trunk credentials: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.TrunkCredentialEntry
+	for rows.Next() {
+		var entry models.TrunkCredentialEntry
+		err := rows.Scan(
+			&entry.CredentialID, &entry.TrunkGroupID, &entry.CustomerBAN,
+			&entry.Username, &entry.Realm, &entry.HA1, &entry.HA1B,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trunk credential: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// scanTrunkCredential scans a row selected with trunkCredentialColumns
+func scanTrunkCredential(row pgx.Row) (*models.TrunkCredential, error) {
+	var cred models.TrunkCredential
+	err := row.Scan(
+		&cred.ID, &cred.TrunkGroupID, &cred.Username, &cred.Realm, &cred.Description, &cred.Status,
+		&cred.LastRotatedAt, &cred.RevokedAt, &cred.CreatedAt, &cred.UpdatedAt, &cred.CreatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &cred, nil
+}
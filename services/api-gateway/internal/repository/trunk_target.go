@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ============================================================================
+// Trunk Termination Targets
+// ============================================================================
+
+const trunkTargetColumns = `
+	id, trunk_group_id, sip_uri, transport, priority, weight,
+	COALESCE(description, ''), enabled, created_at, updated_at, created_by
+`
+
+// CreateTrunkTarget stores a new termination target
+func (r *TrunkRepository) CreateTrunkTarget(ctx context.Context, target *models.TrunkTarget) error {
+	query := `
+		INSERT INTO accounts.trunk_targets (
+			id, trunk_group_id, sip_uri, transport, priority, weight, description, enabled, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		target.ID, target.TrunkGroupID, target.SIPURI, target.Transport, target.Priority,
+		target.Weight, target.Description, target.Enabled, target.CreatedBy,
+	).Scan(&target.CreatedAt, &target.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create trunk target: %w", err)
+	}
+
+	return nil
+}
+
+// GetTrunkTarget retrieves a target by ID (nil if not found)
+func (r *TrunkRepository) GetTrunkTarget(ctx context.Context, targetID uuid.UUID) (*models.TrunkTarget, error) {
+	query := `SELECT ` + trunkTargetColumns + ` FROM accounts.trunk_targets WHERE id = $1`
+
+	target, err := scanTrunkTarget(r.db.QueryRow(ctx, query, targetID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trunk target: %w", err)
+	}
+
+	return target, nil
+}
+
+// ListTrunkTargets retrieves all targets for a trunk group in routing order
+func (r *TrunkRepository) ListTrunkTargets(ctx context.Context, trunkID uuid.UUID) ([]models.TrunkTarget, error) {
+	query := `
+		SELECT ` + trunkTargetColumns + `
+		FROM accounts.trunk_targets
+		WHERE trunk_group_id = $1
+		ORDER BY priority, weight DESC, created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, trunkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trunk targets: %w", err)
+	}
+	defer rows.Close()
+
+	targets := []models.TrunkTarget{}
+	for rows.Next() {
+		target, err := scanTrunkTarget(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trunk target: %w", err)
+		}
+		targets = append(targets, *target)
+	}
+
+	return targets, rows.Err()
+}
+
+// UpdateTrunkTarget updates a termination target
+func (r *TrunkRepository) UpdateTrunkTarget(ctx context.Context, targetID uuid.UUID, req models.UpdateTrunkTargetRequest) error {
+	updates := []string{}
+	args := []interface{}{targetID}
+	argCount := 2
+
+	if req.SIPURI != nil {
+		updates = append(updates, fmt.Sprintf("sip_uri = $%d", argCount))
+		args = append(args, *req.SIPURI)
+		argCount++
+	}
+	if req.Transport != nil {
+		updates = append(updates, fmt.Sprintf("transport = $%d", argCount))
+		args = append(args, *req.Transport)
+		argCount++
+	}
+	if req.Priority != nil {
+		updates = append(updates, fmt.Sprintf("priority = $%d", argCount))
+		args = append(args, *req.Priority)
+		argCount++
+	}
+	if req.Weight != nil {
+		updates = append(updates, fmt.Sprintf("weight = $%d", argCount))
+		args = append(args, *req.Weight)
+		argCount++
+	}
+	if req.Description != nil {
+		updates = append(updates, fmt.Sprintf("description = $%d", argCount))
+		args = append(args, *req.Description)
+		argCount++
+	}
+	if req.Enabled != nil {
+		updates = append(updates, fmt.Sprintf("enabled = $%d", argCount))
+		args = append(args, *req.Enabled)
+		argCount++
+	}
+
+	if len(updates) == 0 {
+		return nil // No updates
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE accounts.trunk_targets
+		SET %s, updated_at = NOW()
+		WHERE id = $1
+	`, join(updates, ", "))
+
+	_, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update trunk target: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteTrunkTarget deletes a termination target
+func (r *TrunkRepository) DeleteTrunkTarget(ctx context.Context, targetID uuid.UUID) error {
+	query := `DELETE FROM accounts.trunk_targets WHERE id = $1`
+	_, err := r.db.Exec(ctx, query, targetID)
+	if err != nil {
+		return fmt.Errorf("failed to delete trunk target: %w", err)
+	}
+	return nil
+}
+
+// GetTrunkRoutingSettings retrieves a trunk's routing settings (nil if the
+// trunk still uses the defaults)
+func (r *TrunkRepository) GetTrunkRoutingSettings(ctx context.Context, trunkID uuid.UUID) (*models.TrunkRoutingSettings, error) {
+	settings := &models.TrunkRoutingSettings{}
+
+	query := `
+		SELECT trunk_group_id, failover_response_codes, failover_on_timeout, invite_timeout_seconds,
+		       options_enabled, options_interval_seconds, options_failure_threshold,
+		       options_recovery_threshold, updated_at
+		FROM accounts.trunk_routing_settings
+		WHERE trunk_group_id = $1
+	`
+
+	var codes []int32
+	err := r.db.QueryRow(ctx, query, trunkID).Scan(
+		&settings.TrunkGroupID, &codes, &settings.FailoverOnTimeout, &settings.InviteTimeoutSeconds,
+		&settings.OptionsEnabled, &settings.OptionsIntervalSeconds, &settings.OptionsFailureThreshold,
+		&settings.OptionsRecoveryThreshold, &settings.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trunk routing settings: %w", err)
+	}
+
+	settings.FailoverResponseCodes = make([]int, len(codes))
+	for i, code := range codes {
+		settings.FailoverResponseCodes[i] = int(code)
+	}
+
+	return settings, nil
+}
+
+// UpsertTrunkRoutingSettings stores a trunk's complete routing settings
+func (r *TrunkRepository) UpsertTrunkRoutingSettings(ctx context.Context, settings *models.TrunkRoutingSettings) error {
+	query := `
+		INSERT INTO accounts.trunk_routing_settings (
+			trunk_group_id, failover_response_codes, failover_on_timeout, invite_timeout_seconds,
+			options_enabled, options_interval_seconds, options_failure_threshold, options_recovery_threshold
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (trunk_group_id) DO UPDATE SET
+			failover_response_codes = EXCLUDED.failover_response_codes,
+			failover_on_timeout = EXCLUDED.failover_on_timeout,
+			invite_timeout_seconds = EXCLUDED.invite_timeout_seconds,
+			options_enabled = EXCLUDED.options_enabled,
+			options_interval_seconds = EXCLUDED.options_interval_seconds,
+			options_failure_threshold = EXCLUDED.options_failure_threshold,
+			options_recovery_threshold = EXCLUDED.options_recovery_threshold,
+			updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	codes := make([]int32, len(settings.FailoverResponseCodes))
+	for i, code := range settings.FailoverResponseCodes {
+		codes[i] = int32(code)
+	}
+
+	err := r.db.QueryRow(ctx, query,
+		settings.TrunkGroupID, codes, settings.FailoverOnTimeout, settings.InviteTimeoutSeconds,
+		settings.OptionsEnabled, settings.OptionsIntervalSeconds, settings.OptionsFailureThreshold,
+		settings.OptionsRecoveryThreshold,
+	).Scan(&settings.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save trunk routing settings: %w", err)
+	}
+
+	return nil
+}
+
+// scanTrunkTarget scans one row selected with trunkTargetColumns
+func scanTrunkTarget(row pgx.Row) (*models.TrunkTarget, error) {
+	target := &models.TrunkTarget{}
+	err := row.Scan(
+		&target.ID, &target.TrunkGroupID, &target.SIPURI, &target.Transport, &target.Priority,
+		&target.Weight, &target.Description, &target.Enabled, &target.CreatedAt, &target.UpdatedAt,
+		&target.CreatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
@@ -0,0 +1,74 @@
+// Package secrets seals small values, such as carrier account PINs, for
+// storage in PostgreSQL. Values are encrypted with AES-256-GCM under a key
+// supplied by the deployment, so a database dump alone does not reveal them.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the length of a sealing key in bytes (AES-256)
+const KeySize = 32
+
+// ErrInvalidSealedValue indicates a value that was not sealed with this key
+var ErrInvalidSealedValue = errors.New("invalid sealed value")
+
+// Cipher seals and opens values with one key
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a cipher from a KeySize-byte key
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("sealing key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// NewCipherFromBase64 creates a cipher from a base64-encoded key, as kept in
+// environment variables
+func NewCipherFromBase64(encoded string) (*Cipher, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("sealing key is not valid base64: %w", err)
+	}
+	return NewCipher(key)
+}
+
+// Seal encrypts plaintext under a random nonce and returns base64(nonce || ciphertext)
+func (c *Cipher) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value returned by Seal
+func (c *Cipher) Open(sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < c.aead.NonceSize() {
+		return "", ErrInvalidSealedValue
+	}
+	nonce, ciphertext := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrInvalidSealedValue
+	}
+	return string(plaintext), nil
+}
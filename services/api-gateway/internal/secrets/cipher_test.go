@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func testCipher(t *testing.T, fill byte) *Cipher {
+	t.Helper()
+	c, err := NewCipher(bytes.Repeat([]byte{fill}, KeySize))
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	return c
+}
+
+func TestSealOpen(t *testing.T) {
+	c := testCipher(t, 1)
+
+	for _, plaintext := range []string{"", "1234", "carrier PIN ✓"} {
+		sealed, err := c.Seal(plaintext)
+		if err != nil {
+			t.Fatalf("Seal(%q): %v", plaintext, err)
+		}
+		if plaintext != "" && bytes.Contains([]byte(sealed), []byte(plaintext)) {
+			t.Errorf("Seal(%q) = %q contains the plaintext", plaintext, sealed)
+		}
+		got, err := c.Open(sealed)
+		if err != nil || got != plaintext {
+			t.Errorf("Open(Seal(%q)) = %q, %v", plaintext, got, err)
+		}
+	}
+
+	// A fresh nonce per value
+	a, _ := c.Seal("1234")
+	b, _ := c.Seal("1234")
+	if a == b {
+		t.Error("Seal returned the same value twice")
+	}
+}
+
+func TestOpenRejectsForeignValues(t *testing.T) {
+	c := testCipher(t, 1)
+	sealed, err := c.Seal("1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := base64.StdEncoding.DecodeString(sealed)
+	raw[len(raw)-1] ^= 0xff
+	tampered := base64.StdEncoding.EncodeToString(raw)
+
+	tests := map[string]string{
+		"other key":  sealed,
+		"tampered":   tampered,
+		"not base64": "not base64!",
+		"too short":  base64.StdEncoding.EncodeToString([]byte("short")),
+	}
+	for name, value := range tests {
+		opener := c
+		if name == "other key" {
+			opener = testCipher(t, 2)
+		}
+		if _, err := opener.Open(value); !errors.Is(err, ErrInvalidSealedValue) {
+			t.Errorf("Open(%s) error = %v, want ErrInvalidSealedValue", name, err)
+		}
+	}
+}
+
+func TestNewCipherKeys(t *testing.T) {
+	if _, err := NewCipher(make([]byte, 16)); err == nil {
+		t.Error("NewCipher accepted a 16-byte key")
+	}
+	if _, err := NewCipherFromBase64("%%%"); err == nil {
+		t.Error("NewCipherFromBase64 accepted invalid base64")
+	}
+	if _, err := NewCipherFromBase64(base64.StdEncoding.EncodeToString(make([]byte, KeySize))); err != nil {
+		t.Errorf("NewCipherFromBase64(32 bytes): %v", err)
+	}
+}
@@ -6,12 +6,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/ringer-warp/api-gateway/internal/cnam"
+	"github.com/ringer-warp/api-gateway/internal/e911"
 	"github.com/ringer-warp/api-gateway/internal/models"
 	"github.com/ringer-warp/api-gateway/internal/repository"
+	"github.com/ringer-warp/api-gateway/internal/secrets"
 	"github.com/ringer-warp/api-gateway/internal/soa"
+	"github.com/ringer-warp/api-gateway/internal/tollfree"
 	"go.uber.org/zap"
 )
 
@@ -21,6 +27,9 @@ var ErrAccessDenied = errors.New("access denied")
 // ErrNumberNotFound indicates the number was not found
 var ErrNumberNotFound = errors.New("number not found")
 
+// ErrNumberInQuarantine indicates a released number is still in its quarantine period
+var ErrNumberInQuarantine = errors.New("number is in quarantine")
+
 // NumberService handles number inventory operations with JIT provisioning
 // All searches pass through to SOA in real-time - RNS does not maintain
 // a local pool of unassigned numbers.
@@ -28,6 +37,46 @@ type NumberService struct {
 	repo      *repository.NumberRepository
 	soaClient *soa.Client
 	logger    *zap.Logger
+
+	// Inventory reconciliation (see number_reconcile.go)
+	spid        string
+	homeLRNs    map[string]bool
+	reconcileMu sync.Mutex
+
+	// Bulk orders (see number_bulk_order.go)
+	bulkConcurrency int
+	bulkWake        chan struct{}
+	bulkMu          sync.Mutex
+	bulkCancels     map[uuid.UUID]context.CancelFunc
+
+	// E911 (see number_e911.go)
+	e911Provider        e911.Provider
+	requireE911ForVoice bool
+
+	// CNAM (see number_cnam.go)
+	cnamProvider cnam.Provider
+	cnamWake     chan struct{}
+
+	// Inbound DID routes for the SIP edge (see number_route.go)
+	redisClient     *redis.Client
+	routeMu         sync.Mutex
+	lastRouteReport *models.DIDRouteReconcileReport
+
+	// Toll-free numbers in the SMS/800 registry (see number_toll_free.go)
+	tollFreeProvider tollfree.Provider
+	respOrgIDs       []string
+
+	// Carrier account PINs on port requests (see number_port.go)
+	portPINCipher *secrets.Cipher
+
+	// Search cache and pricing (see number_search.go)
+	searchCacheTTL time.Duration
+	rateMu         sync.Mutex
+	rates          []models.NumberRate
+	ratesLoadedAt  time.Time
+
+	// Release aging (see number_quarantine.go)
+	quarantinePeriod time.Duration
 }
 
 // NewNumberService creates a new NumberService instance
@@ -37,72 +86,79 @@ func NewNumberService(
 	logger *zap.Logger,
 ) *NumberService {
 	return &NumberService{
-		repo:      repo,
-		soaClient: soaClient,
-		logger:    logger,
+		repo:             repo,
+		soaClient:        soaClient,
+		logger:           logger,
+		bulkConcurrency:  defaultBulkOrderConcurrency,
+		bulkWake:         make(chan struct{}, 1),
+		bulkCancels:      make(map[uuid.UUID]context.CancelFunc),
+		cnamWake:         make(chan struct{}, 1),
+		searchCacheTTL:   defaultSearchCacheTTL,
+		quarantinePeriod: defaultQuarantinePeriod,
 	}
 }
 
-// SearchAvailableNumbers searches SOA for available numbers in real-time (JIT)
-// This is a pass-through to SOA - no local caching
+// SearchAvailableNumbers searches SOA for available numbers in real-time (JIT).
+// Results are cached for a short TTL per search criteria (see number_search.go)
+// and priced from billing.number_rates on every request.
 func (s *NumberService) SearchAvailableNumbers(ctx context.Context, req *models.SearchNumbersRequest) (*models.NumberSearchResponse, error) {
-	// Build SOA search options
-	opts := soa.SearchOptions{
-		NPA:        req.NPA,
-		NXX:        req.NXX,
-		State:      req.State,
-		LATA:       req.LATA,
-		RateCenter: req.RateCenter,
-		Page:       req.Page,
-		Size:       req.Size,
-	}
-
-	// Default page size
-	if opts.Size == 0 {
-		opts.Size = 50
-	}
-
-	s.logger.Debug("Searching SOA for available numbers",
-		zap.String("npa", opts.NPA),
-		zap.String("state", opts.State),
-		zap.Int("page", opts.Page),
-		zap.Int("size", opts.Size),
-	)
-
-	// Query SOA API
-	soaResp, err := s.soaClient.SearchAvailableNumbers(ctx, opts)
+	opts, err := normalizeSearch(req)
 	if err != nil {
-		s.logger.Error("SOA search failed", zap.Error(err))
-		return nil, fmt.Errorf("failed to search available numbers: %w", err)
+		return nil, err
+	}
+
+	searchType := searchCacheQuery
+	if req.Consecutive > 0 {
+		searchType = searchCacheBlock
 	}
+	cacheKey := searchCacheKey(opts, req.Consecutive)
 
-	// Convert SOA response to our model
-	results := make([]models.NumberSearchResult, 0, len(soaResp.Content))
-	for _, n := range soaResp.Content {
-		results = append(results, models.NumberSearchResult{
-			TelephoneNumber: n.TelephoneNumber,
-			NPA:             n.NPA,
-			NXX:             n.NXX,
-			State:           n.State,
-			LATA:            n.LATA,
-			RateCenter:      n.Locality,
-			MonthlyRate:     0, // TODO: Get pricing from billing service
-		})
+	var results []models.NumberSearchResult
+	var total int64
+	cached := false
+
+	if s.searchCacheTTL > 0 {
+		results, total, cached, err = s.repo.GetSearchCache(ctx, searchType, cacheKey)
+		if err != nil {
+			s.logger.Warn("Failed to read number search cache", zap.Error(err))
+			cached = false
+		}
 	}
 
+	if !cached {
+		results, total, err = s.searchNumbers(ctx, opts, req.Consecutive)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.searchCacheTTL > 0 {
+			if err := s.repo.StoreSearchCache(ctx, searchType, cacheKey, results, total, s.searchCacheTTL); err != nil {
+				s.logger.Warn("Failed to store number search cache", zap.Error(err))
+			}
+		}
+	}
+
+	s.priceResults(ctx, results)
+
 	// Calculate pagination
 	totalPages := 1
-	if opts.Size > 0 && soaResp.TotalElements > 0 {
-		totalPages = int((soaResp.TotalElements + int64(opts.Size) - 1) / int64(opts.Size))
+	if opts.Size > 0 && total > 0 {
+		totalPages = int((total + int64(opts.Size) - 1) / int64(opts.Size))
 	}
 
-	return &models.NumberSearchResponse{
+	resp := &models.NumberSearchResponse{
 		Numbers:       results,
-		TotalElements: soaResp.TotalElements,
+		TotalElements: total,
 		TotalPages:    totalPages,
 		Page:          opts.Page,
 		Size:          opts.Size,
-	}, nil
+		Cached:        cached,
+	}
+	if req.Consecutive > 0 {
+		resp.Blocks = numberBlocks(results, req.Consecutive)
+		resp.TotalPages = 1 // Blocks are found by scanning, not paged
+	}
+	return resp, nil
 }
 
 // ReserveNumbers temporarily reserves numbers in SOA
@@ -128,73 +184,36 @@ func (s *NumberService) ReserveNumbers(ctx context.Context, numbers []string, re
 }
 
 // PurchaseNumbers purchases reserved numbers and assigns them to a customer
-// This is the main JIT provisioning operation:
-// 1. Assign numbers in SOA (RESERVED → IN_USE)
-// 2. Store in local database with customer link
+// This is the main JIT provisioning operation, run per number as a saga
+// (see purchaseNumber):
+// 1. Persist a purchase intent
+// 2. Assign numbers in SOA (RESERVED → IN_USE)
+// 3. Store in local database with customer link
+// Failures after step 2 are compensated by releasing in SOA or queued for
+// the purchase repair worker.
 func (s *NumberService) PurchaseNumbers(
 	ctx context.Context,
 	req *models.PurchaseNumberRequest,
 	customerID uuid.UUID,
 	createdBy uuid.UUID,
 ) ([]models.AssignedNumber, []error) {
+	// New numbers have no E911 address yet
+	if err := s.checkE911Policy(ctx, req.VoiceEnabled, false, nil); err != nil {
+		return nil, []error{err}
+	}
+
 	purchased := make([]models.AssignedNumber, 0, len(req.Numbers))
 	errs := make([]error, 0)
 
 	for _, tn := range req.Numbers {
-		// Step 1: Assign in SOA
-		soaReq := &soa.AssignRequest{
-			ApplicationID: customerID.String(),
-			Metadata: map[string]interface{}{
-				"customer_id": customerID.String(),
-				"created_by":  createdBy.String(),
-				"source":      "ringer-warp",
-			},
-		}
-
-		soaNumber, err := s.soaClient.AssignNumber(ctx, tn, soaReq)
-		if err != nil {
-			s.logger.Error("Failed to assign number in SOA",
-				zap.String("number", tn),
-				zap.Error(err),
-			)
-			errs = append(errs, fmt.Errorf("%s: %w", tn, err))
+		if s.tollFreeProvider != nil && isTollFree(tn) {
+			errs = append(errs, fmt.Errorf("%s: %w", tn, ErrUseTollFreeFlow))
 			continue
 		}
 
-		// Step 2: Create local record
-		localNumber := &models.AssignedNumber{
-			CustomerID:    customerID,
-			Number:        soaNumber.TelephoneNumber,
-			SOANumberID:   &soaNumber.ID,
-			SOASyncStatus: "SYNCED",
-			NumberType:    "DID", // Default, could be determined from TN format
-			NPA:           strPtr(soaNumber.NPA),
-			NXX:           strPtr(soaNumber.NXX),
-			RateCenter:    strPtr(soaNumber.Locality),
-			State:         strPtr(soaNumber.State),
-			VoiceEnabled:  req.VoiceEnabled,
-			SMSEnabled:    req.SMSEnabled,
-			TrunkID:       req.TrunkID,
-			CampaignID:    req.CampaignID,
-			Active:        true,
-			ActivatedAt:   time.Now(),
-			CreatedBy:     &createdBy,
-			UpdatedBy:     &createdBy,
-		}
-
-		// Check if toll-free
-		if isTollFree(tn) {
-			localNumber.NumberType = "TOLL_FREE"
-		}
-
-		created, err := s.repo.Create(ctx, localNumber)
+		created, err := s.purchaseNumber(ctx, tn, req, customerID, createdBy)
 		if err != nil {
-			s.logger.Error("Failed to create local number record",
-				zap.String("number", tn),
-				zap.Error(err),
-			)
-			// Number is assigned in SOA but failed locally - mark for reconciliation
-			errs = append(errs, fmt.Errorf("%s: local save failed: %w", tn, err))
+			errs = append(errs, fmt.Errorf("%s: %w", tn, err))
 			continue
 		}
 
@@ -294,6 +313,23 @@ func (s *NumberService) ListNumbers(
 	}, nil
 }
 
+// createAssignedNumber stores a new assignment. A TN keeps one row, so a
+// previously released row (existing) is reused rather than inserting another.
+func (s *NumberService) createAssignedNumber(ctx context.Context, existing, number *models.AssignedNumber) (*models.AssignedNumber, error) {
+	if existing == nil {
+		return s.repo.Create(ctx, number)
+	}
+
+	reused, err := s.repo.ReassignReleased(ctx, existing.ID, number)
+	if err != nil {
+		return nil, err
+	}
+	if reused == nil {
+		return nil, ErrNumberInQuarantine
+	}
+	return reused, nil
+}
+
 // UpdateNumber updates a number's configuration
 func (s *NumberService) UpdateNumber(
 	ctx context.Context,
@@ -308,31 +344,41 @@ func (s *NumberService) UpdateNumber(
 		return nil, err
 	}
 
+	cnamChange, err := s.prepareCNAMUpdate(number, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// E911 changes go through the provider before the local update
+	e911Changed, err := s.updateNumberE911(ctx, number, req, updatedBy)
+	if err != nil {
+		return nil, err
+	}
+
 	// Update local record
 	updated, err := s.repo.Update(ctx, numberID, req, updatedBy)
 	if err != nil {
+		if e911Changed {
+			// Don't leave the provider on an E911 configuration the rest of the update didn't get
+			if restoreErr := s.restoreNumberE911(ctx, number, updatedBy); restoreErr != nil {
+				s.logger.Error("Failed to restore E911 after failed number update",
+					zap.String("number", number.Number),
+					zap.Error(restoreErr),
+				)
+			}
+		}
 		return nil, err
 	}
 
-	// Sync metadata to SOA if we have SOA ID
-	if number.SOANumberID != nil {
-		metadata := map[string]interface{}{
-			"updated_by":    updatedBy.String(),
-			"updated_at":    time.Now().Format(time.RFC3339),
-			"voice_enabled": updated.VoiceEnabled,
-			"sms_enabled":   updated.SMSEnabled,
-			"mms_enabled":   updated.MMSEnabled,
-		}
+	// CNAM is pushed to the provider asynchronously
+	s.queueCNAM(ctx, cnamChange, updatedBy)
 
-		if updated.CampaignID != nil {
-			metadata["campaign_id"] = updated.CampaignID.String()
-		}
-		if updated.TrunkID != nil {
-			metadata["trunk_id"] = updated.TrunkID.String()
-		}
+	s.refreshDIDRoute(ctx, numberID, number.Number)
 
-		_, err := s.soaClient.UpdateMetadata(ctx, number.Number, metadata)
-		if err != nil {
+	// Sync metadata to SOA if we have SOA ID
+	if number.SOANumberID != nil {
+		updated.UpdatedBy = &updatedBy
+		if err := s.pushMetadata(ctx, updated); err != nil {
 			s.logger.Warn("Failed to sync metadata to SOA",
 				zap.String("number", number.Number),
 				zap.Error(err),
@@ -345,9 +391,34 @@ func (s *NumberService) UpdateNumber(
 	return updated, nil
 }
 
+// pushMetadata writes a number's configuration to its SOA application metadata
+func (s *NumberService) pushMetadata(ctx context.Context, number *models.AssignedNumber) error {
+	metadata := map[string]interface{}{
+		"updated_at":    time.Now().Format(time.RFC3339),
+		"voice_enabled": number.VoiceEnabled,
+		"sms_enabled":   number.SMSEnabled,
+		"mms_enabled":   number.MMSEnabled,
+	}
+
+	if number.UpdatedBy != nil {
+		metadata["updated_by"] = number.UpdatedBy.String()
+	}
+	if number.CampaignID != nil {
+		metadata["campaign_id"] = number.CampaignID.String()
+	}
+	if number.TrunkID != nil {
+		metadata["trunk_id"] = number.TrunkID.String()
+	}
+
+	_, err := s.soaClient.UpdateMetadata(ctx, number.Number, metadata)
+	return err
+}
+
 // ReleaseNumber releases a number back to SOA
 // Status in SOA: IN_USE → RESERVED (for audit/grooming, not AVAILABLE)
 // Local: Sets released_at timestamp, keeps record for billing
+// With a quarantine period the number is QUARANTINE until the period ends
+// and SOA is only updated then (see number_quarantine.go)
 func (s *NumberService) ReleaseNumber(
 	ctx context.Context,
 	numberID uuid.UUID,
@@ -365,24 +436,24 @@ func (s *NumberService) ReleaseNumber(
 		return fmt.Errorf("number already released")
 	}
 
-	// Step 1: Release in SOA (sets to RESERVED status)
-	_, err = s.soaClient.ReleaseNumber(ctx, number.Number)
-	if err != nil {
-		// Check if it's already released in SOA (idempotent)
-		if !soa.IsNotFound(err) {
-			s.logger.Error("Failed to release number in SOA",
-				zap.String("number", number.Number),
-				zap.Error(err),
-			)
-			return fmt.Errorf("failed to release in upstream: %w", err)
-		}
-		s.logger.Warn("Number not found in SOA (may be already released)",
-			zap.String("number", number.Number),
-		)
+	// With an aging period the number is only deactivated here; it stays
+	// ours upstream and the quarantine worker releases it once the period ends
+	if s.quarantinePeriod > 0 {
+		return s.quarantineNumber(ctx, number, req.Reason, releasedBy)
 	}
 
-	// Step 2: Mark as released locally (keep record for billing proration)
-	_, err = s.repo.Release(ctx, numberID, req.Reason, releasedBy)
+	return s.releaseNumberNow(ctx, number, req.Reason, releasedBy)
+}
+
+// releaseNumberNow releases a number upstream and locally without an aging
+// period
+func (s *NumberService) releaseNumberNow(ctx context.Context, number *models.AssignedNumber, reason string, releasedBy uuid.UUID) error {
+	if err := s.releaseUpstream(ctx, number, releasedBy); err != nil {
+		return err
+	}
+
+	// Mark as released locally (keep record for billing proration)
+	_, err := s.repo.Release(ctx, number.ID, reason, releasedBy)
 	if err != nil {
 		s.logger.Error("Failed to mark number as released locally",
 			zap.String("number", number.Number),
@@ -391,15 +462,64 @@ func (s *NumberService) ReleaseNumber(
 		return fmt.Errorf("released in SOA but failed to update local: %w", err)
 	}
 
+	s.refreshDIDRoute(ctx, number.ID, number.Number)
+
+	if number.CNAMEnabled {
+		s.queueCNAM(ctx, &models.NumberCNAMProvisioning{NumberID: number.ID, Action: CNAMActionRemove}, releasedBy)
+	}
+
 	s.logger.Info("Number released",
 		zap.String("number", number.Number),
-		zap.String("reason", req.Reason),
+		zap.String("reason", reason),
 		zap.String("released_by", releasedBy.String()),
 	)
 
 	return nil
 }
 
+// releaseUpstream returns a number to the carrier: toll-free numbers to the
+// SMS/800 registry, everything else to SOA (sets to RESERVED status), and
+// removes its emergency record
+func (s *NumberService) releaseUpstream(ctx context.Context, number *models.AssignedNumber, releasedBy uuid.UUID) error {
+	releasedTollFree, err := s.releaseTollFree(ctx, number, releasedBy)
+	if err != nil {
+		return err
+	}
+	if !releasedTollFree {
+		_, err := s.soaClient.ReleaseNumber(ctx, number.Number)
+		if err != nil {
+			// Check if it's already released in SOA (idempotent)
+			if !soa.IsNotFound(err) {
+				s.logger.Error("Failed to release number in SOA",
+					zap.String("number", number.Number),
+					zap.Error(err),
+				)
+				return fmt.Errorf("failed to release in upstream: %w", err)
+			}
+			s.logger.Warn("Number not found in SOA (may be already released)",
+				zap.String("number", number.Number),
+			)
+		}
+	}
+
+	s.deprovisionReleasedE911(ctx, number, releasedBy)
+	return nil
+}
+
+// deprovisionReleasedE911 removes a released number's emergency record; a
+// stale one is logged rather than blocking release
+func (s *NumberService) deprovisionReleasedE911(ctx context.Context, number *models.AssignedNumber, releasedBy uuid.UUID) {
+	if !number.E911Enabled || s.e911Provider == nil {
+		return
+	}
+	if _, err := s.deprovisionE911(ctx, number, releasedBy); err != nil {
+		s.logger.Warn("Failed to deprovision E911 for released number",
+			zap.String("number", number.Number),
+			zap.Error(err),
+		)
+	}
+}
+
 // GetInventorySummary retrieves aggregate statistics for a customer's numbers
 func (s *NumberService) GetInventorySummary(ctx context.Context, customerID uuid.UUID) (*models.NumberInventorySummary, error) {
 	return s.repo.GetInventorySummary(ctx, customerID)
@@ -476,12 +596,6 @@ func strPtr(s string) *string {
 }
 
 func isTollFree(tn string) bool {
-	// Toll-free NPAs: 800, 833, 844, 855, 866, 877, 888
-	tollFreeNPAs := map[string]bool{
-		"800": true, "833": true, "844": true,
-		"855": true, "866": true, "877": true, "888": true,
-	}
-
 	// Extract NPA from E.164 format (+1NPANXXXXXX)
 	if len(tn) >= 5 {
 		npa := ""
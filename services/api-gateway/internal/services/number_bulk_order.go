@@ -0,0 +1,601 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/soa"
+	"github.com/ringer-warp/api-gateway/internal/worker"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidBulkOrder indicates a malformed bulk order request
+var ErrInvalidBulkOrder = errors.New("invalid bulk order")
+
+// ErrBulkOrderNotFound indicates the bulk order does not exist
+var ErrBulkOrderNotFound = errors.New("bulk order not found")
+
+// ErrBulkOrderFinished indicates the bulk order can no longer be cancelled
+var ErrBulkOrderFinished = errors.New("bulk order has already finished")
+
+// Bulk order statuses (see schemas/24-bulk-number-orders.sql)
+const (
+	BulkOrderQueued    = "QUEUED"
+	BulkOrderRunning   = "RUNNING"
+	BulkOrderCompleted = "COMPLETED"
+	BulkOrderPartial   = "PARTIAL"
+	BulkOrderFailed    = "FAILED"
+	BulkOrderCancelled = "CANCELLED"
+)
+
+// Bulk order item statuses
+const (
+	BulkItemPending   = "PENDING"
+	BulkItemReserved  = "RESERVED"
+	BulkItemPurchased = "PURCHASED"
+	BulkItemSkipped   = "SKIPPED"
+	BulkItemFailed    = "FAILED"
+	BulkItemCancelled = "CANCELLED"
+	BulkItemReleased  = "RELEASED"
+)
+
+const (
+	// defaultBulkOrderConcurrency is the number of SOA calls in flight per order
+	defaultBulkOrderConcurrency = 5
+
+	// maxRunningBulkOrders is the number of orders one instance processes at once
+	maxRunningBulkOrders = 2
+
+	// bulkOrderHeartbeat is how often a running order is re-claimed and
+	// checked for cancellation; after bulkOrderStaleAfter without one,
+	// another instance may take the order over
+	bulkOrderHeartbeat  = 15 * time.Second
+	bulkOrderStaleAfter = 2 * time.Minute
+
+	// bulkSearchMaxRounds bounds the search → reserve rounds of a search order
+	bulkSearchMaxRounds = 50
+)
+
+// SetBulkOrderConcurrency sets the number of SOA calls in flight per bulk order
+func (s *NumberService) SetBulkOrderConcurrency(n int) {
+	if n > 0 {
+		s.bulkConcurrency = n
+	}
+}
+
+// CreateBulkOrder queues an asynchronous number order. Explicit orders list
+// their TNs; search orders give criteria and a quantity, and candidates are
+// found while the order runs.
+func (s *NumberService) CreateBulkOrder(
+	ctx context.Context,
+	req *models.CreateBulkOrderRequest,
+	customerID uuid.UUID,
+	createdBy uuid.UUID,
+	reservedBy string,
+) (*models.BulkNumberOrder, error) {
+	numbers := uniqueNumbers(req.Numbers)
+
+	switch {
+	case len(numbers) > 0 && req.Search != nil:
+		return nil, fmt.Errorf("%w: give either numbers or search, not both", ErrInvalidBulkOrder)
+	case len(numbers) == 0 && req.Search == nil:
+		return nil, fmt.Errorf("%w: numbers or search is required", ErrInvalidBulkOrder)
+	case req.Search != nil && req.Quantity == 0:
+		return nil, fmt.Errorf("%w: quantity is required with search", ErrInvalidBulkOrder)
+	case req.Search != nil && req.Search.Consecutive > 0:
+		return nil, fmt.Errorf("%w: consecutive search is not supported for bulk orders", ErrInvalidBulkOrder)
+	}
+	if s.tollFreeProvider != nil {
+		for _, tn := range numbers {
+			if isTollFree(tn) {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBulkOrder, tn, ErrUseTollFreeFlow)
+			}
+		}
+	}
+	if req.Search != nil {
+		if _, err := normalizeSearch(req.Search); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBulkOrder, err)
+		}
+	}
+	if err := s.checkE911Policy(ctx, req.VoiceEnabled, false, nil); err != nil {
+		return nil, err
+	}
+
+	order := &models.BulkNumberOrder{
+		CustomerID:     customerID,
+		SearchCriteria: req.Search,
+		Quantity:       req.Quantity,
+		AcceptPartial:  true,
+		VoiceEnabled:   req.VoiceEnabled,
+		SMSEnabled:     req.SMSEnabled,
+		TrunkID:        req.TrunkID,
+		CampaignID:     req.CampaignID,
+		CreatedBy:      &createdBy,
+		ReservedBy:     &reservedBy,
+	}
+	if len(numbers) > 0 {
+		order.Quantity = len(numbers)
+	}
+	if req.AcceptPartial != nil {
+		order.AcceptPartial = *req.AcceptPartial
+	}
+
+	if err := s.repo.CreateBulkOrder(ctx, order, numbers); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Bulk number order queued",
+		zap.String("order_id", order.ID.String()),
+		zap.String("customer_id", customerID.String()),
+		zap.Int("quantity", order.Quantity),
+		zap.Bool("search", order.SearchCriteria != nil),
+	)
+
+	// Wake the dispatcher instead of waiting for its next poll
+	select {
+	case s.bulkWake <- struct{}{}:
+	default:
+	}
+
+	return order, nil
+}
+
+// GetBulkOrder retrieves a bulk order with per-TN progress
+func (s *NumberService) GetBulkOrder(ctx context.Context, id uuid.UUID, customerFilter []uuid.UUID) (*models.BulkNumberOrder, error) {
+	order, err := s.repo.GetBulkOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, ErrBulkOrderNotFound
+	}
+	if !s.hasCustomerAccess(order.CustomerID, customerFilter) {
+		return nil, ErrAccessDenied
+	}
+
+	order.Items, err = s.repo.ListBulkOrderItems(ctx, id, "")
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// ListBulkOrders lists a customer's bulk orders with progress counts
+func (s *NumberService) ListBulkOrders(ctx context.Context, customerID uuid.UUID, limit int) ([]models.BulkNumberOrder, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.ListBulkOrdersByCustomer(ctx, customerID, limit)
+}
+
+// CancelBulkOrder cancels a queued order outright or asks the worker running
+// it to stop. Numbers already purchased are kept; reservations are released.
+func (s *NumberService) CancelBulkOrder(ctx context.Context, id uuid.UUID, customerFilter []uuid.UUID) (*models.BulkNumberOrder, error) {
+	if _, err := s.GetBulkOrder(ctx, id, customerFilter); err != nil {
+		return nil, err
+	}
+
+	status, err := s.repo.RequestBulkOrderCancel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case "":
+		return nil, ErrBulkOrderFinished
+	case BulkOrderCancelled:
+		// Never started: nothing reserved
+		if err := s.repo.TransitionBulkOrderItems(ctx, id, BulkItemPending, BulkItemCancelled); err != nil {
+			return nil, err
+		}
+	default:
+		// Running here: stop now rather than at the next heartbeat
+		s.bulkMu.Lock()
+		if cancel, ok := s.bulkCancels[id]; ok {
+			cancel()
+		}
+		s.bulkMu.Unlock()
+	}
+
+	return s.GetBulkOrder(ctx, id, customerFilter)
+}
+
+// StartBulkOrderDispatcher claims queued bulk orders every pollInterval (or
+// as soon as one is submitted here) and processes up to maxRunningBulkOrders
+// at a time until ctx is cancelled
+func (s *NumberService) StartBulkOrderDispatcher(ctx context.Context, pollInterval time.Duration) {
+	slots := make(chan struct{}, maxRunningBulkOrders)
+
+	worker.Every(ctx, pollInterval, s.bulkWake, func(ctx context.Context) {
+		for {
+			select {
+			case slots <- struct{}{}:
+			default:
+				return // All slots busy
+			}
+
+			order, err := s.repo.ClaimBulkOrder(ctx, time.Now().Add(-bulkOrderStaleAfter))
+			if err != nil || order == nil {
+				<-slots
+				if err != nil {
+					s.logger.Warn("Failed to claim bulk order", zap.Error(err))
+				}
+				return
+			}
+
+			go func() {
+				defer func() { <-slots }()
+				s.processBulkOrder(ctx, order)
+			}()
+		}
+	})
+}
+
+// processBulkOrder runs a claimed order in two phases with bounded
+// concurrency: reserve every TN in SOA, then purchase the reserved TNs
+// through the purchase saga. An all-or-nothing order (accept_partial false)
+// that comes up short is rolled back: reservations and purchases are
+// released and the order FAILS. Item progress is persisted as it happens,
+// so an order taken over after a crash resumes where it stopped.
+func (s *NumberService) processBulkOrder(parent context.Context, order *models.BulkNumberOrder) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	s.bulkMu.Lock()
+	s.bulkCancels[order.ID] = cancel
+	s.bulkMu.Unlock()
+	defer func() {
+		s.bulkMu.Lock()
+		delete(s.bulkCancels, order.ID)
+		s.bulkMu.Unlock()
+	}()
+
+	if order.CancelRequested {
+		cancel()
+	}
+	go s.heartbeatBulkOrder(ctx, order.ID, cancel)
+
+	// Cleanup (releases, final status) must run even after cancellation
+	cleanupCtx := context.WithoutCancel(ctx)
+
+	log := s.logger.With(zap.String("order_id", order.ID.String()))
+	log.Info("Processing bulk number order", zap.Int("quantity", order.Quantity))
+
+	// Phase 1: reserve
+	if order.SearchCriteria != nil {
+		s.reserveBulkSearch(ctx, order)
+	} else {
+		pending, err := s.repo.ListBulkOrderItems(ctx, order.ID, BulkItemPending)
+		if err == nil {
+			s.reserveBulkItems(ctx, order, pending, BulkItemFailed)
+		}
+	}
+	if ctx.Err() != nil {
+		s.cancelBulkOrder(cleanupCtx, order)
+		return
+	}
+
+	reserved, err := s.repo.ListBulkOrderItems(cleanupCtx, order.ID, BulkItemReserved)
+	if err != nil {
+		s.failBulkOrder(cleanupCtx, order, err.Error())
+		return
+	}
+	purchased, err := s.repo.ListBulkOrderItems(cleanupCtx, order.ID, BulkItemPurchased)
+	if err != nil {
+		s.failBulkOrder(cleanupCtx, order, err.Error())
+		return
+	}
+
+	have := len(reserved) + len(purchased)
+	if have < order.Quantity && !order.AcceptPartial {
+		s.rollbackBulkOrder(cleanupCtx, order, fmt.Sprintf("only %d of %d numbers could be reserved", have, order.Quantity))
+		return
+	}
+
+	// Phase 2: purchase
+	s.purchaseBulkItems(ctx, order, reserved)
+	if ctx.Err() != nil {
+		s.cancelBulkOrder(cleanupCtx, order)
+		return
+	}
+
+	purchased, err = s.repo.ListBulkOrderItems(cleanupCtx, order.ID, BulkItemPurchased)
+	if err != nil {
+		s.failBulkOrder(cleanupCtx, order, err.Error())
+		return
+	}
+
+	status := BulkOrderCompleted
+	var msg *string
+	switch {
+	case len(purchased) >= order.Quantity:
+	case !order.AcceptPartial:
+		s.rollbackBulkOrder(cleanupCtx, order, fmt.Sprintf("only %d of %d numbers could be purchased", len(purchased), order.Quantity))
+		return
+	case len(purchased) > 0:
+		status = BulkOrderPartial
+		m := fmt.Sprintf("%d of %d numbers purchased", len(purchased), order.Quantity)
+		msg = &m
+	default:
+		status = BulkOrderFailed
+		m := "no numbers could be purchased"
+		msg = &m
+	}
+
+	// Search candidates never needed
+	_ = s.repo.TransitionBulkOrderItems(cleanupCtx, order.ID, BulkItemPending, BulkItemSkipped)
+
+	if err := s.repo.FinishBulkOrder(cleanupCtx, order.ID, status, msg); err != nil {
+		log.Error("Failed to finish bulk order", zap.Error(err))
+	}
+	log.Info("Bulk number order finished",
+		zap.String("status", status),
+		zap.Int("purchased", len(purchased)),
+	)
+}
+
+// reserveBulkSearch finds candidates for a search order and reserves them
+// until the quantity is reached or the search is exhausted. Candidates that
+// cannot be reserved (taken meanwhile) are SKIPPED, not failures.
+func (s *NumberService) reserveBulkSearch(ctx context.Context, order *models.BulkNumberOrder) {
+	page := 0
+	for round := 0; round < bulkSearchMaxRounds && ctx.Err() == nil; round++ {
+		// Resumed orders may already hold pending candidates
+		pending, err := s.repo.ListBulkOrderItems(ctx, order.ID, BulkItemPending)
+		if err != nil {
+			return
+		}
+		if len(pending) > 0 {
+			s.reserveBulkItems(ctx, order, pending, BulkItemSkipped)
+			continue
+		}
+
+		need, err := s.bulkOrderShortfall(ctx, order)
+		if err != nil || need <= 0 {
+			return
+		}
+
+		// Our reservations drop out of the available set, so page 0 keeps
+		// yielding fresh numbers; move on only when a page adds nothing new
+		criteria := *order.SearchCriteria
+		criteria.Page = page
+		criteria.Size = need
+		if criteria.Size > 500 {
+			criteria.Size = 500
+		}
+
+		// Straight to SOA: cached results would hand back numbers already taken
+		opts, err := normalizeSearch(&criteria)
+		if err != nil {
+			return
+		}
+		results, _, err := s.searchNumbers(ctx, opts, 0)
+		if err != nil {
+			s.logger.Warn("Bulk order search failed", zap.String("order_id", order.ID.String()), zap.Error(err))
+			return
+		}
+		if len(results) == 0 {
+			return // Search exhausted
+		}
+
+		candidates := make([]string, 0, len(results))
+		for _, n := range results {
+			candidates = append(candidates, n.TelephoneNumber)
+		}
+		added, err := s.repo.AddBulkOrderCandidates(ctx, order.ID, candidates)
+		if err != nil {
+			return
+		}
+		if added == 0 {
+			page++
+		}
+	}
+}
+
+// bulkOrderShortfall returns how many more numbers the order needs reserved
+func (s *NumberService) bulkOrderShortfall(ctx context.Context, order *models.BulkNumberOrder) (int, error) {
+	current, err := s.repo.GetBulkOrder(ctx, order.ID)
+	if err != nil {
+		return 0, err
+	}
+	if current == nil {
+		return 0, ErrBulkOrderNotFound
+	}
+	return order.Quantity - current.Progress.Reserved - current.Progress.Purchased, nil
+}
+
+// reserveBulkItems reserves items in SOA; failures are marked failStatus
+func (s *NumberService) reserveBulkItems(ctx context.Context, order *models.BulkNumberOrder, items []models.BulkOrderItem, failStatus string) {
+	reservedBy := "warp-bulk-order"
+	if order.ReservedBy != nil && *order.ReservedBy != "" {
+		reservedBy = *order.ReservedBy
+	}
+
+	s.forEachBulkItem(ctx, items, func(item models.BulkOrderItem) {
+		status := BulkItemReserved
+		var errMsg *string
+		if _, err := s.soaClient.ReserveNumber(ctx, item.Number, reservedBy); err != nil {
+			if ctx.Err() != nil {
+				return // Cancelled: leave PENDING for cleanup
+			}
+			status = failStatus
+			msg := err.Error()
+			errMsg = &msg
+		}
+		if err := s.repo.UpdateBulkOrderItem(ctx, item.ID, status, nil, errMsg); err != nil {
+			s.logger.Warn("Failed to record bulk order item", zap.String("number", item.Number), zap.Error(err))
+		}
+	})
+}
+
+// purchaseBulkItems purchases reserved items through the purchase saga
+func (s *NumberService) purchaseBulkItems(ctx context.Context, order *models.BulkNumberOrder, items []models.BulkOrderItem) {
+	req := &models.PurchaseNumberRequest{
+		VoiceEnabled: order.VoiceEnabled,
+		SMSEnabled:   order.SMSEnabled,
+		TrunkID:      order.TrunkID,
+		CampaignID:   order.CampaignID,
+	}
+	createdBy := uuid.Nil
+	if order.CreatedBy != nil {
+		createdBy = *order.CreatedBy
+	}
+	// Item updates must land even if the order is cancelled mid-purchase
+	recordCtx := context.WithoutCancel(ctx)
+
+	s.forEachBulkItem(ctx, items, func(item models.BulkOrderItem) {
+		created, err := s.purchaseNumber(ctx, item.Number, req, order.CustomerID, createdBy)
+		if err != nil {
+			msg := err.Error()
+			_ = s.repo.UpdateBulkOrderItem(recordCtx, item.ID, BulkItemFailed, nil, &msg)
+			return
+		}
+		if err := s.repo.UpdateBulkOrderItem(recordCtx, item.ID, BulkItemPurchased, &created.ID, nil); err != nil {
+			s.logger.Warn("Failed to record bulk order item", zap.String("number", item.Number), zap.Error(err))
+		}
+	})
+}
+
+// forEachBulkItem calls fn for each item with at most bulkConcurrency in
+// flight, stopping early once ctx is cancelled
+func (s *NumberService) forEachBulkItem(ctx context.Context, items []models.BulkOrderItem, fn func(models.BulkOrderItem)) {
+	sem := make(chan struct{}, s.bulkConcurrency)
+	var wg sync.WaitGroup
+
+	for _, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(item models.BulkOrderItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(item)
+		}(item)
+	}
+
+	wg.Wait()
+}
+
+// cancelBulkOrder releases reservations not yet purchased and finishes the
+// order CANCELLED; purchased numbers are kept
+func (s *NumberService) cancelBulkOrder(ctx context.Context, order *models.BulkNumberOrder) {
+	s.releaseBulkReservations(ctx, order, BulkItemCancelled)
+	_ = s.repo.TransitionBulkOrderItems(ctx, order.ID, BulkItemPending, BulkItemCancelled)
+
+	if err := s.repo.FinishBulkOrder(ctx, order.ID, BulkOrderCancelled, nil); err != nil {
+		s.logger.Error("Failed to finish cancelled bulk order", zap.String("order_id", order.ID.String()), zap.Error(err))
+	}
+	s.logger.Info("Bulk number order cancelled", zap.String("order_id", order.ID.String()))
+}
+
+// rollbackBulkOrder undoes an all-or-nothing order that came up short
+func (s *NumberService) rollbackBulkOrder(ctx context.Context, order *models.BulkNumberOrder, reason string) {
+	s.releaseBulkReservations(ctx, order, BulkItemReleased)
+
+	purchased, err := s.repo.ListBulkOrderItems(ctx, order.ID, BulkItemPurchased)
+	if err == nil {
+		releasedBy := uuid.Nil
+		if order.CreatedBy != nil {
+			releasedBy = *order.CreatedBy
+		}
+		s.forEachBulkItem(ctx, purchased, func(item models.BulkOrderItem) {
+			if item.AssignedNumberID == nil {
+				return
+			}
+			// The customer never had the numbers, so they skip quarantine
+			if err := s.rollbackBulkItem(ctx, *item.AssignedNumberID, releasedBy); err != nil {
+				msg := fmt.Sprintf("rollback failed: %v", err)
+				_ = s.repo.UpdateBulkOrderItem(ctx, item.ID, BulkItemPurchased, nil, &msg)
+				return
+			}
+			_ = s.repo.UpdateBulkOrderItem(ctx, item.ID, BulkItemReleased, nil, nil)
+		})
+	}
+
+	_ = s.repo.TransitionBulkOrderItems(ctx, order.ID, BulkItemPending, BulkItemSkipped)
+	s.failBulkOrder(ctx, order, reason+" (all-or-nothing order rolled back)")
+}
+
+// rollbackBulkItem releases a number purchased by a rolled-back order
+func (s *NumberService) rollbackBulkItem(ctx context.Context, numberID, releasedBy uuid.UUID) error {
+	number, err := s.repo.GetByID(ctx, numberID)
+	if err != nil {
+		return err
+	}
+	if number == nil || !number.Active {
+		return nil
+	}
+	return s.releaseNumberNow(ctx, number, "BULK_ORDER_ROLLBACK", releasedBy)
+}
+
+// releaseBulkReservations releases RESERVED items in SOA, marking them status
+func (s *NumberService) releaseBulkReservations(ctx context.Context, order *models.BulkNumberOrder, status string) {
+	reserved, err := s.repo.ListBulkOrderItems(ctx, order.ID, BulkItemReserved)
+	if err != nil {
+		s.logger.Warn("Failed to list bulk order reservations", zap.String("order_id", order.ID.String()), zap.Error(err))
+		return
+	}
+
+	s.forEachBulkItem(ctx, reserved, func(item models.BulkOrderItem) {
+		var errMsg *string
+		if _, err := s.soaClient.ReleaseNumber(ctx, item.Number); err != nil && !soa.IsNotFound(err) {
+			// The SOA reservation hold expires on its own
+			msg := fmt.Sprintf("release failed: %v", err)
+			errMsg = &msg
+		}
+		_ = s.repo.UpdateBulkOrderItem(ctx, item.ID, status, nil, errMsg)
+	})
+}
+
+func (s *NumberService) failBulkOrder(ctx context.Context, order *models.BulkNumberOrder, reason string) {
+	if err := s.repo.FinishBulkOrder(ctx, order.ID, BulkOrderFailed, &reason); err != nil {
+		s.logger.Error("Failed to finish bulk order", zap.String("order_id", order.ID.String()), zap.Error(err))
+	}
+	s.logger.Warn("Bulk number order failed", zap.String("order_id", order.ID.String()), zap.String("reason", reason))
+}
+
+// heartbeatBulkOrder keeps the order claimed and cancels it when another
+// instance records a cancellation request
+func (s *NumberService) heartbeatBulkOrder(ctx context.Context, id uuid.UUID, cancel context.CancelFunc) {
+	ticker := time.NewTicker(bulkOrderHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cancelRequested, err := s.repo.HeartbeatBulkOrder(ctx, id)
+		if err != nil {
+			s.logger.Warn("Bulk order heartbeat failed", zap.String("order_id", id.String()), zap.Error(err))
+			continue
+		}
+		if cancelRequested {
+			cancel()
+			return
+		}
+	}
+}
+
+// uniqueNumbers drops duplicate and empty TNs, keeping order
+func uniqueNumbers(numbers []string) []string {
+	seen := make(map[string]bool, len(numbers))
+	unique := make([]string, 0, len(numbers))
+	for _, n := range numbers {
+		if n == "" || seen[n] {
+			continue
+		}
+		seen[n] = true
+		unique = append(unique, n)
+	}
+	return unique
+}
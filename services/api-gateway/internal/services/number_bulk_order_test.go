@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/soa"
+)
+
+// bulkTestNumbers puts n new numbers in the fake SOA
+func bulkTestNumbers(fake *fakeSOA, n int) []string {
+	tns := make([]string, 0, n)
+	for len(tns) < n {
+		tn := testTN("719")
+		if _, ok := fake.numbers[tn]; ok {
+			continue
+		}
+		fake.add(tn, soa.StatusAvailable)
+		tns = append(tns, tn)
+	}
+	return tns
+}
+
+// queueBulkOrder creates an explicit order for tns
+func queueBulkOrder(t *testing.T, s *NumberService, tns []string, acceptPartial bool, customerID uuid.UUID) *models.BulkNumberOrder {
+	t.Helper()
+
+	order, err := s.CreateBulkOrder(context.Background(), &models.CreateBulkOrderRequest{
+		Numbers:       tns,
+		AcceptPartial: &acceptPartial,
+	}, customerID, uuid.New(), "bulk@test.invalid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+// finishBulkOrder processes order as the dispatcher would and returns it
+// with its items keyed by number
+func finishBulkOrder(t *testing.T, s *NumberService, order *models.BulkNumberOrder) (*models.BulkNumberOrder, map[string]models.BulkOrderItem) {
+	t.Helper()
+
+	s.processBulkOrder(context.Background(), order)
+	return bulkOrderItems(t, s, order.ID)
+}
+
+// bulkOrderItems returns an order with its items keyed by number
+func bulkOrderItems(t *testing.T, s *NumberService, id uuid.UUID) (*models.BulkNumberOrder, map[string]models.BulkOrderItem) {
+	t.Helper()
+
+	order, err := s.GetBulkOrder(context.Background(), id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := make(map[string]models.BulkOrderItem, len(order.Items))
+	for _, item := range order.Items {
+		items[item.Number] = item
+	}
+	return order, items
+}
+
+func TestProcessBulkOrder(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	customerID := testCustomer(t, pool)
+
+	tests := []struct {
+		name          string
+		acceptPartial bool
+		setup         func(tns []string)
+		wantStatus    string
+		wantItems     []string
+	}{
+		{
+			name:          "all purchased",
+			acceptPartial: true,
+			setup:         func(tns []string) {},
+			wantStatus:    BulkOrderCompleted,
+			wantItems:     []string{BulkItemPurchased, BulkItemPurchased, BulkItemPurchased},
+		},
+		{
+			name:          "reservation refused",
+			acceptPartial: true,
+			setup:         func(tns []string) { fake.failWith("reserve", tns[2], http.StatusConflict) },
+			wantStatus:    BulkOrderPartial,
+			wantItems:     []string{BulkItemPurchased, BulkItemPurchased, BulkItemFailed},
+		},
+		{
+			name:          "assignment refused",
+			acceptPartial: true,
+			setup:         func(tns []string) { fake.failWith("assign", tns[1], http.StatusConflict) },
+			wantStatus:    BulkOrderPartial,
+			wantItems:     []string{BulkItemPurchased, BulkItemFailed, BulkItemPurchased},
+		},
+		{
+			name:          "nothing purchased",
+			acceptPartial: true,
+			setup: func(tns []string) {
+				for _, tn := range tns {
+					fake.failWith("reserve", tn, http.StatusConflict)
+				}
+			},
+			wantStatus: BulkOrderFailed,
+			wantItems:  []string{BulkItemFailed, BulkItemFailed, BulkItemFailed},
+		},
+		{
+			name:          "all or nothing short of reservations",
+			acceptPartial: false,
+			setup:         func(tns []string) { fake.failWith("reserve", tns[2], http.StatusConflict) },
+			wantStatus:    BulkOrderFailed,
+			wantItems:     []string{BulkItemReleased, BulkItemReleased, BulkItemFailed},
+		},
+		{
+			name:          "all or nothing short of purchases",
+			acceptPartial: false,
+			setup:         func(tns []string) { fake.failWith("assign", tns[2], http.StatusConflict) },
+			wantStatus:    BulkOrderFailed,
+			wantItems:     []string{BulkItemReleased, BulkItemReleased, BulkItemFailed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tns := bulkTestNumbers(fake, 3)
+			tt.setup(tns)
+
+			order, items := finishBulkOrder(t, s, queueBulkOrder(t, s, tns, tt.acceptPartial, customerID))
+			if order.Status != tt.wantStatus {
+				t.Errorf("order status = %s (%v), want %s", order.Status, order.Error, tt.wantStatus)
+			}
+			for i, tn := range tns {
+				if got := items[tn].Status; got != tt.wantItems[i] {
+					t.Errorf("item %d = %s (%v), want %s", i, got, items[tn].Error, tt.wantItems[i])
+				}
+				if tt.wantItems[i] == BulkItemReleased {
+					// Rolled back numbers go straight back to SOA, not into quarantine
+					if inv := fake.get(tn); inv.Status != soa.StatusReserved || fake.released(tn) != 1 {
+						t.Errorf("item %d SOA status = %s after %d releases, want RESERVED after 1", i, inv.Status, fake.released(tn))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestProcessBulkOrderCancelled(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	customerID := testCustomer(t, pool)
+
+	tns := bulkTestNumbers(fake, 2)
+	order := queueBulkOrder(t, s, tns, true, customerID)
+	order.CancelRequested = true
+
+	order, items := finishBulkOrder(t, s, order)
+	if order.Status != BulkOrderCancelled {
+		t.Errorf("order status = %s, want %s", order.Status, BulkOrderCancelled)
+	}
+	for _, tn := range tns {
+		if items[tn].Status != BulkItemCancelled || fake.called("reserve", tn) != 0 {
+			t.Errorf("%s = %s after %d reservations, want CANCELLED after none", tn, items[tn].Status, fake.called("reserve", tn))
+		}
+	}
+}
+
+func TestProcessBulkOrderResume(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	ctx := context.Background()
+	customerID := testCustomer(t, pool)
+
+	tns := bulkTestNumbers(fake, 3)
+	queued := queueBulkOrder(t, s, tns, false, customerID)
+	_, items := bulkOrderItems(t, s, queued.ID)
+
+	// A previous worker purchased the first number and reserved the second
+	purchased, errs := s.PurchaseNumbers(ctx, &models.PurchaseNumberRequest{Numbers: tns[:1]}, customerID, uuid.New())
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if err := s.repo.UpdateBulkOrderItem(ctx, items[tns[0]].ID, BulkItemPurchased, &purchased[0].ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	fake.set(tns[1], soa.StatusReserved, "")
+	if err := s.repo.UpdateBulkOrderItem(ctx, items[tns[1]].ID, BulkItemReserved, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	order, items := finishBulkOrder(t, s, queued)
+	if order.Status != BulkOrderCompleted {
+		t.Errorf("order status = %s (%v), want %s", order.Status, order.Error, BulkOrderCompleted)
+	}
+	if items[tns[0]].AssignedNumberID == nil || *items[tns[0]].AssignedNumberID != purchased[0].ID {
+		t.Errorf("first item number = %v, want %s kept", items[tns[0]].AssignedNumberID, purchased[0].ID)
+	}
+
+	wantReserves := []int{0, 0, 1}
+	wantAssigns := []int{1, 1, 1}
+	for i, tn := range tns {
+		if items[tn].Status != BulkItemPurchased {
+			t.Errorf("item %d = %s (%v), want PURCHASED", i, items[tn].Status, items[tn].Error)
+		}
+		if got := fake.called("reserve", tn); got != wantReserves[i] {
+			t.Errorf("item %d reserved %d times, want %d", i, got, wantReserves[i])
+		}
+		if got := fake.called("assign", tn); got != wantAssigns[i] {
+			t.Errorf("item %d assigned %d times, want %d", i, got, wantAssigns[i])
+		}
+	}
+}
+
+func TestProcessBulkOrderConcurrency(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	s.SetBulkOrderConcurrency(2)
+	customerID := testCustomer(t, pool)
+
+	tns := bulkTestNumbers(fake, 8)
+	fake.delay = 10 * time.Millisecond
+
+	order, _ := finishBulkOrder(t, s, queueBulkOrder(t, s, tns, true, customerID))
+	if order.Status != BulkOrderCompleted {
+		t.Errorf("order status = %s (%v), want %s", order.Status, order.Error, BulkOrderCompleted)
+	}
+	if peak := fake.peakInFlight(); peak != 2 {
+		t.Errorf("SOA calls in flight peaked at %d, want 2", peak)
+	}
+}
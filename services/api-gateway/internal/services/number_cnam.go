@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/cnam"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/worker"
+	"go.uber.org/zap"
+)
+
+// ErrCNAMNotConfigured indicates no CNAM provider is configured
+var ErrCNAMNotConfigured = errors.New("CNAM provider not configured")
+
+// ErrCNAMStatusNotFound indicates CNAM was never provisioned for the number
+var ErrCNAMStatusNotFound = errors.New("CNAM has not been provisioned for this number")
+
+// CNAM provisioning actions and statuses (see schemas/27-cnam-provisioning.sql)
+const (
+	CNAMActionSet    = "SET"
+	CNAMActionRemove = "REMOVE"
+
+	CNAMPending     = "PENDING"
+	CNAMProvisioned = "PROVISIONED"
+	CNAMRemoved     = "REMOVED"
+	CNAMFailed      = "FAILED"
+
+	// Bulk update outcomes that are not provisioning statuses
+	CNAMUnchanged = "UNCHANGED"
+	CNAMRejected  = "REJECTED"
+)
+
+const (
+	// maxCNAMAttempts bounds provider retries before a change is FAILED
+	maxCNAMAttempts = 8
+
+	// cnamBatch is the number of changes pushed per worker pass
+	cnamBatch = 100
+
+	// cnamLease is how long a claimed change is hidden from other workers
+	cnamLease = 2 * time.Minute
+)
+
+// SetCNAMProvider sets the provider CNAM display names are pushed to
+func (s *NumberService) SetCNAMProvider(provider cnam.Provider) {
+	s.cnamProvider = provider
+}
+
+// GetNumberCNAM retrieves a number's CNAM provisioning status
+func (s *NumberService) GetNumberCNAM(ctx context.Context, numberID uuid.UUID, customerFilter []uuid.UUID) (*models.NumberCNAMProvisioning, error) {
+	if _, err := s.GetNumber(ctx, numberID, customerFilter); err != nil {
+		return nil, err
+	}
+
+	p, err := s.repo.GetNumberCNAM(ctx, numberID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrCNAMStatusNotFound
+	}
+	return p, nil
+}
+
+// BulkUpdateCNAM changes CNAM on many numbers. Each number is validated and
+// saved on its own, so one bad display name does not fail the rest; accepted
+// changes are provisioned asynchronously. Numbers whose CNAM is unchanged but
+// enabled are queued again, which retries a FAILED provisioning.
+func (s *NumberService) BulkUpdateCNAM(
+	ctx context.Context,
+	req *models.BulkCNAMUpdateRequest,
+	customerFilter []uuid.UUID,
+	updatedBy uuid.UUID,
+) ([]models.CNAMUpdateResult, error) {
+	if s.cnamProvider == nil {
+		return nil, ErrCNAMNotConfigured
+	}
+
+	results := make([]models.CNAMUpdateResult, 0, len(req.Updates))
+	for _, u := range req.Updates {
+		result := models.CNAMUpdateResult{NumberID: u.NumberID, Status: CNAMPending}
+		if err := s.bulkUpdateNumberCNAM(ctx, u, customerFilter, updatedBy, &result); err != nil {
+			result.Status = CNAMRejected
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	s.wakeCNAMWorker()
+	return results, nil
+}
+
+func (s *NumberService) bulkUpdateNumberCNAM(
+	ctx context.Context,
+	u models.CNAMUpdate,
+	customerFilter []uuid.UUID,
+	updatedBy uuid.UUID,
+	result *models.CNAMUpdateResult,
+) error {
+	number, err := s.GetNumber(ctx, u.NumberID, customerFilter)
+	if err != nil {
+		return err
+	}
+	if !number.Active {
+		return fmt.Errorf("number is released")
+	}
+
+	req := &models.UpdateNumberRequest{CNAMEnabled: u.Enabled, CNAMDisplayName: u.DisplayName}
+	change, err := s.prepareCNAMUpdate(number, req)
+	if err != nil {
+		return err
+	}
+	if change == nil && number.CNAMEnabled {
+		change = &models.NumberCNAMProvisioning{NumberID: number.ID, Action: CNAMActionSet, DisplayName: number.CNAMDisplayName}
+	}
+	if change == nil {
+		result.Status = CNAMUnchanged
+		return nil
+	}
+
+	if _, err := s.repo.Update(ctx, number.ID, req, updatedBy); err != nil {
+		return err
+	}
+	return s.repo.QueueCNAM(ctx, number.ID, change.Action, change.DisplayName, updatedBy)
+}
+
+// StartCNAMWorker pushes queued CNAM changes to the provider every interval,
+// or sooner when changes are queued, until ctx is cancelled
+func (s *NumberService) StartCNAMWorker(ctx context.Context, interval time.Duration) {
+	worker.Every(ctx, interval, s.cnamWake, func(ctx context.Context) {
+		// Drain the backlog rather than one batch per tick
+		for s.ProcessCNAMQueue(ctx) == cnamBatch {
+		}
+	})
+}
+
+// ProcessCNAMQueue makes one provisioning pass and returns the number of
+// changes pushed
+func (s *NumberService) ProcessCNAMQueue(ctx context.Context) int {
+	if s.cnamProvider == nil {
+		return 0
+	}
+
+	claimed, err := s.repo.ClaimCNAMDue(ctx, time.Now().Add(cnamLease), cnamBatch)
+	if err != nil {
+		s.logger.Warn("Failed to claim CNAM changes", zap.Error(err))
+		return 0
+	}
+
+	for i := range claimed {
+		s.pushCNAM(ctx, &claimed[i])
+	}
+	return len(claimed)
+}
+
+func (s *NumberService) pushCNAM(ctx context.Context, p *models.NumberCNAMProvisioning) {
+	provider := s.cnamProvider.Name()
+
+	var err error
+	status := CNAMProvisioned
+	if p.Action == CNAMActionRemove {
+		status = CNAMRemoved
+		err = s.cnamProvider.RemoveDisplayName(ctx, p.Number)
+	} else {
+		err = s.cnamProvider.SetDisplayName(ctx, p.Number, *p.DisplayName)
+	}
+
+	if err == nil {
+		if err := s.repo.ResolveCNAM(ctx, p, status, provider, nil); err != nil {
+			s.logger.Error("Failed to record CNAM provisioning", zap.String("number", p.Number), zap.Error(err))
+		}
+		return
+	}
+
+	msg := err.Error()
+	if p.Attempts+1 >= maxCNAMAttempts {
+		s.logger.Error("CNAM provisioning failed, retries exhausted",
+			zap.String("number", p.Number),
+			zap.String("action", p.Action),
+			zap.Error(err),
+		)
+		if err := s.repo.ResolveCNAM(ctx, p, CNAMFailed, provider, &msg); err != nil {
+			s.logger.Error("Failed to record CNAM failure", zap.String("number", p.Number), zap.Error(err))
+		}
+		return
+	}
+
+	backoff := worker.Backoff(p.Attempts)
+
+	s.logger.Warn("CNAM provisioning failed, will retry",
+		zap.String("number", p.Number),
+		zap.Duration("backoff", backoff),
+		zap.Error(err),
+	)
+	if err := s.repo.ScheduleCNAMRetry(ctx, p, provider, msg, time.Now().Add(backoff)); err != nil {
+		// Still covered: the lease expires and the change is claimed again
+		s.logger.Error("Failed to schedule CNAM retry", zap.String("number", p.Number), zap.Error(err))
+	}
+}
+
+// prepareCNAMUpdate validates the CNAM part of an update, normalizing
+// req.CNAMDisplayName in place. It returns the provider change the update
+// calls for, or nil if the number's CNAM is unchanged.
+func (s *NumberService) prepareCNAMUpdate(number *models.AssignedNumber, req *models.UpdateNumberRequest) (*models.NumberCNAMProvisioning, error) {
+	if req.CNAMEnabled == nil && req.CNAMDisplayName == nil {
+		return nil, nil
+	}
+
+	enabled := number.CNAMEnabled
+	if req.CNAMEnabled != nil {
+		enabled = *req.CNAMEnabled
+	}
+
+	var current, name string
+	if number.CNAMDisplayName != nil {
+		current = *number.CNAMDisplayName
+	}
+	name = current
+
+	if req.CNAMDisplayName != nil {
+		name = *req.CNAMDisplayName
+		// An empty name clears it, which is only allowed with CNAM off
+		if name != "" || enabled {
+			normalized, err := cnam.NormalizeDisplayName(name)
+			if err != nil {
+				return nil, err
+			}
+			name = normalized
+			req.CNAMDisplayName = &normalized
+		}
+	}
+	if enabled && name == "" {
+		return nil, fmt.Errorf("%w: a display name is required to enable CNAM", cnam.ErrInvalidDisplayName)
+	}
+
+	switch {
+	case enabled && (!number.CNAMEnabled || name != current):
+		return &models.NumberCNAMProvisioning{NumberID: number.ID, Action: CNAMActionSet, DisplayName: &name}, nil
+	case !enabled && number.CNAMEnabled:
+		return &models.NumberCNAMProvisioning{NumberID: number.ID, Action: CNAMActionRemove}, nil
+	}
+	return nil, nil
+}
+
+// queueCNAM queues a change for the CNAM worker. Without a provider CNAM is
+// only stored locally.
+func (s *NumberService) queueCNAM(ctx context.Context, change *models.NumberCNAMProvisioning, updatedBy uuid.UUID) {
+	if change == nil || s.cnamProvider == nil {
+		return
+	}
+
+	if err := s.repo.QueueCNAM(ctx, change.NumberID, change.Action, change.DisplayName, updatedBy); err != nil {
+		s.logger.Error("Failed to queue CNAM provisioning",
+			zap.String("number_id", change.NumberID.String()),
+			zap.String("action", change.Action),
+			zap.Error(err),
+		)
+		return
+	}
+	s.wakeCNAMWorker()
+}
+
+func (s *NumberService) wakeCNAMWorker() {
+	select {
+	case s.cnamWake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/cnam"
+	"github.com/ringer-warp/api-gateway/internal/models"
+)
+
+// pushTestCNAM pushes a number's queued CNAM change as the worker would and
+// returns the outcome
+func pushTestCNAM(t *testing.T, s *NumberService, numberID uuid.UUID) *models.NumberCNAMProvisioning {
+	t.Helper()
+	ctx := context.Background()
+
+	p, err := s.repo.GetNumberCNAM(ctx, numberID)
+	if err != nil || p == nil {
+		t.Fatalf("GetNumberCNAM() = %v, %v", p, err)
+	}
+	s.pushCNAM(ctx, p)
+
+	p, err = s.repo.GetNumberCNAM(ctx, numberID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestBulkUpdateCNAM(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	s.SetCNAMProvider(cnam.NewFakeProvider())
+	ctx := context.Background()
+	customerID := testCustomer(t, pool)
+
+	number := purchaseTestNumber(t, s, fake, customerID)
+	other := purchaseTestNumber(t, s, fake, customerID)
+
+	enabled, disabled := true, false
+	name, badName := "  Acme   Widgets ", "Acme!"
+
+	results, err := s.BulkUpdateCNAM(ctx, &models.BulkCNAMUpdateRequest{Updates: []models.CNAMUpdate{
+		{NumberID: number.ID, Enabled: &enabled, DisplayName: &name},
+		{NumberID: other.ID, Enabled: &enabled, DisplayName: &badName},
+		{NumberID: other.ID, Enabled: &disabled},
+		{NumberID: uuid.New(), Enabled: &enabled, DisplayName: &name},
+	}}, []uuid.UUID{customerID}, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{CNAMPending, CNAMRejected, CNAMUnchanged, CNAMRejected}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("update %d = %s (%s), want %s", i, r.Status, r.Error, want[i])
+		}
+	}
+
+	updated, err := s.repo.GetByID(ctx, number.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.CNAMEnabled || updated.CNAMDisplayName == nil || *updated.CNAMDisplayName != "Acme Widgets" {
+		t.Errorf("number CNAM = %v %v, want enabled as \"Acme Widgets\"", updated.CNAMEnabled, updated.CNAMDisplayName)
+	}
+
+	if p := pushTestCNAM(t, s, number.ID); p.Status != CNAMProvisioned {
+		t.Errorf("CNAM status after push = %s, want %s", p.Status, CNAMProvisioned)
+	}
+
+	// Turning CNAM off removes the name from the provider
+	results, err = s.BulkUpdateCNAM(ctx, &models.BulkCNAMUpdateRequest{Updates: []models.CNAMUpdate{
+		{NumberID: number.ID, Enabled: &disabled},
+	}}, []uuid.UUID{customerID}, uuid.New())
+	if err != nil || results[0].Status != CNAMPending {
+		t.Fatalf("BulkUpdateCNAM() = %+v, %v", results, err)
+	}
+	if p := pushTestCNAM(t, s, number.ID); p.Action != CNAMActionRemove || p.Status != CNAMRemoved {
+		t.Errorf("CNAM after push = %s %s, want %s %s", p.Action, p.Status, CNAMActionRemove, CNAMRemoved)
+	}
+}
+
+func TestPushCNAMFailure(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	provider := cnam.NewFakeProvider()
+	s.SetCNAMProvider(provider)
+	ctx := context.Background()
+	customerID := testCustomer(t, pool)
+
+	number := purchaseTestNumber(t, s, fake, customerID)
+	enabled, name := true, "Ringer"
+	if _, err := s.BulkUpdateCNAM(ctx, &models.BulkCNAMUpdateRequest{Updates: []models.CNAMUpdate{
+		{NumberID: number.ID, Enabled: &enabled, DisplayName: &name},
+	}}, nil, uuid.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	provider.FailSet = errors.New("provider unavailable")
+
+	// A failed push is retried later
+	p := pushTestCNAM(t, s, number.ID)
+	if p.Status != CNAMPending || p.Attempts != 1 || p.LastError == nil {
+		t.Errorf("CNAM after failure = %s after %d attempts (error %v), want PENDING after 1 with the error", p.Status, p.Attempts, p.LastError)
+	}
+
+	// The last attempt gives up
+	p.Attempts = maxCNAMAttempts - 1
+	s.pushCNAM(ctx, p)
+	if p, _ := s.repo.GetNumberCNAM(ctx, number.ID); p.Status != CNAMFailed {
+		t.Errorf("CNAM after retries exhausted = %s, want %s", p.Status, CNAMFailed)
+	}
+}
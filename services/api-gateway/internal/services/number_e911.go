@@ -0,0 +1,500 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/e911"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"go.uber.org/zap"
+)
+
+// ErrE911NotConfigured indicates no E911 provider is configured
+var ErrE911NotConfigured = errors.New("E911 provider not configured")
+
+// ErrE911AddressNotFound indicates the E911 address does not exist for the customer
+var ErrE911AddressNotFound = errors.New("E911 address not found")
+
+// ErrE911AddressInvalid indicates the E911 provider rejected the address
+var ErrE911AddressInvalid = errors.New("E911 address failed validation")
+
+// ErrE911AddressNotValidated indicates the address has not passed validation
+var ErrE911AddressNotValidated = errors.New("E911 address is not validated")
+
+// ErrE911AddressInUse indicates numbers are still provisioned to the address
+var ErrE911AddressInUse = errors.New("E911 address has numbers provisioned to it")
+
+// ErrE911Required indicates policy requires a validated E911 address for voice
+var ErrE911Required = errors.New("voice-enabled numbers require a provisioned, validated E911 address")
+
+// ErrE911ProvisionFailed indicates the E911 provider rejected a (de)provisioning request
+var ErrE911ProvisionFailed = errors.New("E911 provisioning failed")
+
+// E911 address validation statuses (see schemas/26-e911-addresses.sql)
+const (
+	E911AddressPending   = "PENDING"
+	E911AddressValidated = "VALIDATED"
+	E911AddressInvalid   = "INVALID"
+)
+
+// E911 number provisioning statuses
+const (
+	E911Provisioned   = "PROVISIONED"
+	E911Failed        = "FAILED"
+	E911Deprovisioned = "DEPROVISIONED"
+)
+
+// SetE911Provider sets the provider that validates addresses and provisions numbers
+func (s *NumberService) SetE911Provider(provider e911.Provider) {
+	s.e911Provider = provider
+}
+
+// SetE911Policy sets whether voice-enabled numbers require a provisioned,
+// validated E911 address
+func (s *NumberService) SetE911Policy(requireForVoice bool) {
+	s.requireE911ForVoice = requireForVoice
+}
+
+// CreateE911Address stores a customer address and validates it with the
+// provider; an address the provider rejects is kept as INVALID with the reason
+func (s *NumberService) CreateE911Address(
+	ctx context.Context,
+	req *models.CreateE911AddressRequest,
+	customerID uuid.UUID,
+	createdBy uuid.UUID,
+) (*models.E911Address, error) {
+	addr := &models.E911Address{
+		CustomerID:   customerID,
+		Name:         req.Name,
+		AddressLine1: req.AddressLine1,
+		AddressLine2: req.AddressLine2,
+		City:         req.City,
+		State:        req.State,
+		PostalCode:   req.PostalCode,
+		Country:      req.Country,
+		LocationInfo: req.LocationInfo,
+		CreatedBy:    &createdBy,
+	}
+	if addr.Country == "" {
+		addr.Country = "US"
+	}
+
+	if err := s.repo.CreateE911Address(ctx, addr); err != nil {
+		return nil, err
+	}
+
+	if s.e911Provider == nil {
+		return addr, nil // Stays PENDING until a provider validates it
+	}
+	return s.validateE911Address(ctx, addr)
+}
+
+// ListE911Addresses lists a customer's E911 addresses
+func (s *NumberService) ListE911Addresses(ctx context.Context, customerID uuid.UUID) ([]models.E911Address, error) {
+	return s.repo.ListE911Addresses(ctx, customerID)
+}
+
+// GetE911Address retrieves an E911 address
+func (s *NumberService) GetE911Address(ctx context.Context, id uuid.UUID, customerFilter []uuid.UUID) (*models.E911Address, error) {
+	addr, err := s.repo.GetE911Address(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if addr == nil || !s.hasCustomerAccess(addr.CustomerID, customerFilter) {
+		return nil, ErrE911AddressNotFound
+	}
+	return addr, nil
+}
+
+// UpdateE911Address changes an address and validates it again. Numbers
+// provisioned to the address are re-provisioned; a change the provider
+// rejects is refused while numbers still depend on the address.
+func (s *NumberService) UpdateE911Address(
+	ctx context.Context,
+	id uuid.UUID,
+	req *models.UpdateE911AddressRequest,
+	customerFilter []uuid.UUID,
+	updatedBy uuid.UUID,
+) (*models.E911Address, error) {
+	current, err := s.GetE911Address(ctx, id, customerFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	numbers, err := s.repo.ListNumbersOnE911Address(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(numbers) > 0 {
+		if s.e911Provider == nil {
+			return nil, ErrE911NotConfigured
+		}
+		candidate := applyE911AddressUpdate(*current, req)
+		result, err := s.e911Provider.ValidateAddress(ctx, toE911ProviderAddress(&candidate))
+		if err != nil {
+			return nil, fmt.Errorf("E911 address validation failed: %w", err)
+		}
+		if !result.Valid {
+			return nil, fmt.Errorf("%w: %s", ErrE911AddressInvalid, result.Message)
+		}
+	}
+
+	updated, err := s.repo.UpdateE911Address(ctx, id, req, updatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if s.e911Provider == nil {
+		return updated, nil
+	}
+
+	validated, err := s.validateE911Address(ctx, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	// Move dependent numbers to the corrected address
+	for _, n := range numbers {
+		number := &models.AssignedNumber{ID: n.ID, CustomerID: n.CustomerID, Number: n.Number}
+		if _, err := s.provisionE911(ctx, number, validated, updatedBy); err != nil {
+			s.logger.Warn("Failed to re-provision number after E911 address change",
+				zap.String("address_id", id.String()),
+				zap.String("number", n.Number),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return validated, nil
+}
+
+// ValidateE911Address runs provider validation on an address again
+func (s *NumberService) ValidateE911Address(ctx context.Context, id uuid.UUID, customerFilter []uuid.UUID) (*models.E911Address, error) {
+	addr, err := s.GetE911Address(ctx, id, customerFilter)
+	if err != nil {
+		return nil, err
+	}
+	if s.e911Provider == nil {
+		return nil, ErrE911NotConfigured
+	}
+	return s.validateE911Address(ctx, addr)
+}
+
+// DeleteE911Address deletes an address no active number is provisioned to
+func (s *NumberService) DeleteE911Address(ctx context.Context, id uuid.UUID, customerFilter []uuid.UUID) error {
+	if _, err := s.GetE911Address(ctx, id, customerFilter); err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.DeleteE911Address(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrE911AddressInUse
+	}
+	return nil
+}
+
+// GetNumberE911 retrieves a number's E911 provisioning status
+func (s *NumberService) GetNumberE911(ctx context.Context, numberID uuid.UUID, customerFilter []uuid.UUID) (*models.NumberE911Provisioning, error) {
+	if _, err := s.GetNumber(ctx, numberID, customerFilter); err != nil {
+		return nil, err
+	}
+
+	p, err := s.repo.GetNumberE911(ctx, numberID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return &models.NumberE911Provisioning{NumberID: numberID, Status: E911Deprovisioned}, nil
+	}
+	return p, nil
+}
+
+// ProvisionNumberE911 provisions a number to one of its customer's validated addresses
+func (s *NumberService) ProvisionNumberE911(
+	ctx context.Context,
+	numberID uuid.UUID,
+	addressID uuid.UUID,
+	customerFilter []uuid.UUID,
+	updatedBy uuid.UUID,
+) (*models.NumberE911Provisioning, error) {
+	number, err := s.GetNumber(ctx, numberID, customerFilter)
+	if err != nil {
+		return nil, err
+	}
+	if !number.Active {
+		return nil, fmt.Errorf("number is released")
+	}
+
+	addr, err := s.numberE911Address(ctx, number, addressID)
+	if err != nil {
+		return nil, err
+	}
+	return s.provisionE911(ctx, number, addr, updatedBy)
+}
+
+// DeprovisionNumberE911 removes a number's E911 record. Under the voice
+// policy, voice must be disabled first.
+func (s *NumberService) DeprovisionNumberE911(
+	ctx context.Context,
+	numberID uuid.UUID,
+	customerFilter []uuid.UUID,
+	updatedBy uuid.UUID,
+) (*models.NumberE911Provisioning, error) {
+	number, err := s.GetNumber(ctx, numberID, customerFilter)
+	if err != nil {
+		return nil, err
+	}
+	if s.requireE911ForVoice && number.VoiceEnabled && number.Active {
+		return nil, fmt.Errorf("%w: disable voice before removing E911", ErrE911Required)
+	}
+	return s.deprovisionE911(ctx, number, updatedBy)
+}
+
+// e911Exempt reports whether a number is outside the E911 voice policy.
+// Toll-free numbers have no ALI record for a PSAP and E911 providers do not
+// accept them, so the policy cannot apply.
+func e911Exempt(number *models.AssignedNumber) bool {
+	return number.NumberType == "TOLL_FREE"
+}
+
+// checkE911Policy enforces the voice policy on a number's resulting configuration
+func (s *NumberService) checkE911Policy(ctx context.Context, voiceEnabled, e911Enabled bool, addressID *uuid.UUID) error {
+	if !s.requireE911ForVoice || !voiceEnabled {
+		return nil
+	}
+	if !e911Enabled || addressID == nil {
+		return ErrE911Required
+	}
+
+	addr, err := s.repo.GetE911Address(ctx, *addressID)
+	if err != nil {
+		return err
+	}
+	if addr == nil || addr.ValidationStatus != E911AddressValidated {
+		return ErrE911Required
+	}
+	return nil
+}
+
+// updateNumberE911 applies the E911 part of an UpdateNumber request through
+// the provider before the rest of the update is saved. It reports whether the
+// provider was changed, in which case a failed save must be compensated with
+// restoreNumberE911.
+func (s *NumberService) updateNumberE911(ctx context.Context, number *models.AssignedNumber, req *models.UpdateNumberRequest, updatedBy uuid.UUID) (bool, error) {
+	e911Enabled, addressID := number.E911Enabled, number.E911AddressID
+
+	var addr *models.E911Address
+	switch {
+	case req.E911Enabled != nil && !*req.E911Enabled:
+		e911Enabled, addressID = false, nil
+	case req.E911AddressID != nil:
+		var err error
+		if addr, err = s.numberE911Address(ctx, number, *req.E911AddressID); err != nil {
+			return false, err
+		}
+		e911Enabled, addressID = true, &addr.ID
+	case req.E911Enabled != nil && *req.E911Enabled && number.E911AddressID == nil:
+		return false, fmt.Errorf("%w: e911_address_id is required to enable E911", ErrE911AddressNotValidated)
+	}
+
+	// Only changes to voice or E911 are held to the policy, so unrelated edits
+	// to numbers that predate it still go through
+	if !e911Exempt(number) && (req.VoiceEnabled != nil || req.E911Enabled != nil || req.E911AddressID != nil) {
+		voiceEnabled := number.VoiceEnabled
+		if req.VoiceEnabled != nil {
+			voiceEnabled = *req.VoiceEnabled
+		}
+		if err := s.checkE911Policy(ctx, voiceEnabled, e911Enabled, addressID); err != nil {
+			return false, err
+		}
+	}
+
+	// The provisioning flow owns these columns
+	req.E911Enabled = nil
+	req.E911AddressID = nil
+
+	switch {
+	case addr != nil:
+		if _, err := s.provisionE911(ctx, number, addr, updatedBy); err != nil {
+			return false, err
+		}
+		return true, nil
+	case !e911Enabled && number.E911Enabled:
+		if _, err := s.deprovisionE911(ctx, number, updatedBy); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// restoreNumberE911 puts a number's E911 provisioning back to its state in
+// number, undoing updateNumberE911 when the rest of the update failed to save
+func (s *NumberService) restoreNumberE911(ctx context.Context, number *models.AssignedNumber, updatedBy uuid.UUID) error {
+	if !number.E911Enabled || number.E911AddressID == nil {
+		_, err := s.deprovisionE911(ctx, number, updatedBy)
+		return err
+	}
+
+	addr, err := s.repo.GetE911Address(ctx, *number.E911AddressID)
+	if err != nil {
+		return err
+	}
+	if addr == nil || addr.ProviderAddressID == nil {
+		return ErrE911AddressNotValidated
+	}
+	_, err = s.provisionE911(ctx, number, addr, updatedBy)
+	return err
+}
+
+// numberE911Address loads addressID for provisioning number: it must belong
+// to the number's customer and be validated
+func (s *NumberService) numberE911Address(ctx context.Context, number *models.AssignedNumber, addressID uuid.UUID) (*models.E911Address, error) {
+	addr, err := s.repo.GetE911Address(ctx, addressID)
+	if err != nil {
+		return nil, err
+	}
+	if addr == nil || addr.CustomerID != number.CustomerID {
+		return nil, ErrE911AddressNotFound
+	}
+	if addr.ValidationStatus != E911AddressValidated || addr.ProviderAddressID == nil {
+		return nil, ErrE911AddressNotValidated
+	}
+	return addr, nil
+}
+
+func (s *NumberService) provisionE911(ctx context.Context, number *models.AssignedNumber, addr *models.E911Address, updatedBy uuid.UUID) (*models.NumberE911Provisioning, error) {
+	if s.e911Provider == nil {
+		return nil, ErrE911NotConfigured
+	}
+
+	record := &models.NumberE911Provisioning{
+		NumberID:  number.ID,
+		AddressID: &addr.ID,
+		Status:    E911Provisioned,
+		Provider:  s.e911Provider.Name(),
+		UpdatedBy: &updatedBy,
+	}
+
+	provisionErr := s.e911Provider.ProvisionNumber(ctx, number.Number, *addr.ProviderAddressID, addr.Name)
+	if provisionErr != nil {
+		msg := provisionErr.Error()
+		record.Status = E911Failed
+		record.Error = &msg
+	}
+
+	recorded, err := s.repo.RecordNumberE911(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	if provisionErr != nil {
+		s.logger.Warn("E911 provisioning failed",
+			zap.String("number", number.Number),
+			zap.String("address_id", addr.ID.String()),
+			zap.Error(provisionErr),
+		)
+		return nil, fmt.Errorf("%w: %v", ErrE911ProvisionFailed, provisionErr)
+	}
+
+	s.logger.Info("Number provisioned for E911",
+		zap.String("number", number.Number),
+		zap.String("address_id", addr.ID.String()),
+	)
+	return recorded, nil
+}
+
+func (s *NumberService) deprovisionE911(ctx context.Context, number *models.AssignedNumber, updatedBy uuid.UUID) (*models.NumberE911Provisioning, error) {
+	if s.e911Provider == nil {
+		return nil, ErrE911NotConfigured
+	}
+
+	if err := s.e911Provider.DeprovisionNumber(ctx, number.Number); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrE911ProvisionFailed, err)
+	}
+
+	return s.repo.RecordNumberE911(ctx, &models.NumberE911Provisioning{
+		NumberID:  number.ID,
+		Status:    E911Deprovisioned,
+		Provider:  s.e911Provider.Name(),
+		UpdatedBy: &updatedBy,
+	})
+}
+
+// validateE911Address validates addr with the provider and stores the outcome
+func (s *NumberService) validateE911Address(ctx context.Context, addr *models.E911Address) (*models.E911Address, error) {
+	result, err := s.e911Provider.ValidateAddress(ctx, toE911ProviderAddress(addr))
+	if err != nil {
+		return nil, fmt.Errorf("E911 address validation failed: %w", err)
+	}
+
+	record := *addr
+	record.Provider = strPtr(s.e911Provider.Name())
+	record.MSAGValid = &result.MSAGValid
+	record.ValidationMessage = strPtr(result.Message)
+	record.ProviderAddressID = nil
+	record.ValidationStatus = E911AddressInvalid
+
+	if result.Valid {
+		record.ValidationStatus = E911AddressValidated
+		record.ProviderAddressID = strPtr(result.ProviderAddressID)
+		if n := result.Normalized; n != nil {
+			record.Name = n.Name
+			record.AddressLine1 = n.AddressLine1
+			record.AddressLine2 = strPtr(n.AddressLine2)
+			record.City = n.City
+			record.State = n.State
+			record.PostalCode = n.PostalCode
+			record.Country = n.Country
+			record.LocationInfo = strPtr(n.LocationInfo)
+		}
+	}
+
+	return s.repo.RecordE911Validation(ctx, &record)
+}
+
+func applyE911AddressUpdate(addr models.E911Address, req *models.UpdateE911AddressRequest) models.E911Address {
+	if req.Name != nil {
+		addr.Name = *req.Name
+	}
+	if req.AddressLine1 != nil {
+		addr.AddressLine1 = *req.AddressLine1
+	}
+	if req.AddressLine2 != nil {
+		addr.AddressLine2 = req.AddressLine2
+	}
+	if req.City != nil {
+		addr.City = *req.City
+	}
+	if req.State != nil {
+		addr.State = *req.State
+	}
+	if req.PostalCode != nil {
+		addr.PostalCode = *req.PostalCode
+	}
+	if req.LocationInfo != nil {
+		addr.LocationInfo = req.LocationInfo
+	}
+	return addr
+}
+
+func toE911ProviderAddress(addr *models.E911Address) e911.Address {
+	a := e911.Address{
+		Name:         addr.Name,
+		AddressLine1: addr.AddressLine1,
+		City:         addr.City,
+		State:        addr.State,
+		PostalCode:   addr.PostalCode,
+		Country:      addr.Country,
+	}
+	if addr.AddressLine2 != nil {
+		a.AddressLine2 = *addr.AddressLine2
+	}
+	if addr.LocationInfo != nil {
+		a.LocationInfo = *addr.LocationInfo
+	}
+	return a
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/e911"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/soa"
+)
+
+// newTestE911Address creates an address for customerID; a street containing
+// INVALID is rejected by the fake provider
+func newTestE911Address(t *testing.T, s *NumberService, customerID uuid.UUID, street string) *models.E911Address {
+	t.Helper()
+
+	addr, err := s.CreateE911Address(context.Background(), &models.CreateE911AddressRequest{
+		Name:         "Test Site",
+		AddressLine1: street,
+		City:         "Denver",
+		State:        "co",
+		PostalCode:   "80202",
+	}, customerID, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+func TestCreateE911Address(t *testing.T) {
+	s, _, pool := newTestNumberService(t)
+	s.SetE911Provider(e911.NewFakeProvider())
+	customerID := testCustomer(t, pool)
+
+	valid := newTestE911Address(t, s, customerID, "1600 Glenarm Pl")
+	if valid.ValidationStatus != E911AddressValidated || valid.ProviderAddressID == nil {
+		t.Errorf("valid address = %s (provider ID %v), want VALIDATED with a provider ID", valid.ValidationStatus, valid.ProviderAddressID)
+	}
+	if valid.State != "CO" || valid.Country != "US" {
+		t.Errorf("address not normalized: state %q, country %q", valid.State, valid.Country)
+	}
+
+	invalid := newTestE911Address(t, s, customerID, "1 Invalid Way")
+	if invalid.ValidationStatus != E911AddressInvalid || invalid.ValidationMessage == nil {
+		t.Errorf("invalid address = %s (message %v), want INVALID with a reason", invalid.ValidationStatus, invalid.ValidationMessage)
+	}
+}
+
+func TestProvisionNumberE911(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	provider := e911.NewFakeProvider()
+	s.SetE911Provider(provider)
+	ctx := context.Background()
+	customerID := testCustomer(t, pool)
+	otherID := testCustomer(t, pool)
+
+	number := purchaseTestNumber(t, s, fake, customerID)
+	valid := newTestE911Address(t, s, customerID, "1600 Glenarm Pl")
+	invalid := newTestE911Address(t, s, customerID, "1 Invalid Way")
+	foreign := newTestE911Address(t, s, otherID, "1600 Glenarm Pl")
+
+	if _, err := s.ProvisionNumberE911(ctx, number.ID, invalid.ID, nil, uuid.New()); !errors.Is(err, ErrE911AddressNotValidated) {
+		t.Errorf("invalid address error = %v, want ErrE911AddressNotValidated", err)
+	}
+	if _, err := s.ProvisionNumberE911(ctx, number.ID, foreign.ID, nil, uuid.New()); !errors.Is(err, ErrE911AddressNotFound) {
+		t.Errorf("other customer's address error = %v, want ErrE911AddressNotFound", err)
+	}
+
+	provider.FailProvision = errors.New("provider unavailable")
+	if _, err := s.ProvisionNumberE911(ctx, number.ID, valid.ID, nil, uuid.New()); !errors.Is(err, ErrE911ProvisionFailed) {
+		t.Errorf("provider failure error = %v, want ErrE911ProvisionFailed", err)
+	}
+	if p, _ := s.GetNumberE911(ctx, number.ID, nil); p.Status != E911Failed || p.Error == nil {
+		t.Errorf("provisioning after failure = %s (error %v), want FAILED with the error", p.Status, p.Error)
+	}
+
+	provider.FailProvision = nil
+	p, err := s.ProvisionNumberE911(ctx, number.ID, valid.ID, nil, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != E911Provisioned || p.AddressID == nil || *p.AddressID != valid.ID {
+		t.Errorf("provisioning = %s to %v, want PROVISIONED to %s", p.Status, p.AddressID, valid.ID)
+	}
+
+	if err := s.DeleteE911Address(ctx, valid.ID, nil); !errors.Is(err, ErrE911AddressInUse) {
+		t.Errorf("delete error = %v, want ErrE911AddressInUse", err)
+	}
+
+	p, err = s.DeprovisionNumberE911(ctx, number.ID, nil, uuid.New())
+	if err != nil || p.Status != E911Deprovisioned {
+		t.Errorf("DeprovisionNumberE911() = %+v, %v", p, err)
+	}
+}
+
+func TestE911PolicyPurchase(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	s.SetE911Provider(e911.NewFakeProvider())
+	s.SetE911Policy(true)
+	customerID := testCustomer(t, pool)
+
+	tn := testTN("720")
+	fake.add(tn, soa.StatusAvailable)
+
+	_, errs := s.PurchaseNumbers(context.Background(), &models.PurchaseNumberRequest{Numbers: []string{tn}, VoiceEnabled: true}, customerID, uuid.New())
+	if len(errs) != 1 || !errors.Is(errs[0], ErrE911Required) {
+		t.Errorf("errors = %v, want ErrE911Required", errs)
+	}
+	if inv := fake.get(tn); inv.Status != soa.StatusAvailable {
+		t.Errorf("SOA status = %s, want AVAILABLE", inv.Status)
+	}
+
+	// Numbers without voice are not covered by the policy
+	purchaseTestNumber(t, s, fake, customerID)
+}
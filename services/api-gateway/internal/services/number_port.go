@@ -0,0 +1,641 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/secrets"
+	"github.com/ringer-warp/api-gateway/internal/soa"
+	"go.uber.org/zap"
+)
+
+// ErrPortRequestNotFound indicates the port request does not exist
+var ErrPortRequestNotFound = errors.New("port request not found")
+
+// ErrPortRequestInvalidState indicates the port request's status does not allow the operation
+var ErrPortRequestInvalidState = errors.New("port request status does not allow this operation")
+
+// ErrPortRequestIncomplete indicates the port request cannot be submitted yet
+var ErrPortRequestIncomplete = errors.New("port request is not ready to submit")
+
+// ErrPortActivationIncomplete indicates some numbers could not be activated
+var ErrPortActivationIncomplete = errors.New("not all numbers could be activated")
+
+// ErrPortDocumentNotFound indicates the document is not attached to the port request
+var ErrPortDocumentNotFound = errors.New("port request document not found")
+
+// ErrPortPINNotConfigured indicates a carrier PIN was given but PINs cannot be sealed
+var ErrPortPINNotConfigured = errors.New("port PIN storage is not configured")
+
+// Port request statuses (see schemas/25-port-in-requests.sql)
+const (
+	PortDraft       = "DRAFT"
+	PortSubmitted   = "SUBMITTED"
+	PortFOCReceived = "FOC_RECEIVED"
+	PortActivated   = "ACTIVATED"
+	PortRejected    = "REJECTED"
+	PortCancelled   = "CANCELLED"
+)
+
+// Portability pre-check results
+const (
+	PortabilityPortable    = "PORTABLE"
+	PortabilityNotPortable = "NOT_PORTABLE"
+	PortabilityUnverified  = "UNVERIFIED"
+)
+
+// CheckPortability pre-checks numbers for porting in using SOA's LERG/LSMS
+// data. Numbers SOA does not know are UNVERIFIED: they may still port, and
+// the losing carrier has the final word.
+func (s *NumberService) CheckPortability(ctx context.Context, numbers []string) ([]models.PortabilityCheckResult, error) {
+	numbers = uniqueNumbers(numbers)
+	results := make([]models.PortabilityCheckResult, len(numbers))
+	errs := make([]error, len(numbers))
+
+	sem := make(chan struct{}, s.bulkConcurrency)
+	var wg sync.WaitGroup
+	for i, tn := range numbers {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, tn string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = s.checkPortability(ctx, tn)
+		}(i, tn)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *NumberService) checkPortability(ctx context.Context, tn string) (models.PortabilityCheckResult, error) {
+	result := models.PortabilityCheckResult{Number: tn, Portability: PortabilityPortable}
+
+	if isTollFree(tn) {
+		result.Portability = PortabilityNotPortable
+		result.Reason = "toll-free numbers move by RespOrg change, not local number porting"
+		return result, nil
+	}
+
+	local, err := s.repo.GetByNumber(ctx, tn)
+	if err != nil {
+		return result, err
+	}
+	if local != nil && local.Active {
+		result.Portability = PortabilityNotPortable
+		result.Reason = "number is already active on WARP"
+		return result, nil
+	}
+
+	inv, err := s.soaClient.GetNumberDetails(ctx, tn)
+	if err != nil {
+		if soa.IsNotFound(err) {
+			result.Portability = PortabilityUnverified
+			result.Reason = "number not found in SOA LERG/LSMS data"
+			return result, nil
+		}
+		return result, fmt.Errorf("SOA lookup for %s failed: %w", tn, err)
+	}
+
+	owner := inv.CurrentOwnerSPID
+	if owner == "" {
+		owner = inv.SPID
+	}
+	result.CurrentSPID = strPtr(owner)
+	result.CurrentLRN = strPtr(inv.CurrentLRN)
+	result.RateCenter = strPtr(inv.Locality)
+	result.State = strPtr(inv.State)
+
+	// Our own numbers are purchased, not ported (unless they ported away)
+	if s.spid != "" && owner == s.spid && inv.Status != soa.StatusPortedOut {
+		result.Portability = PortabilityNotPortable
+		result.Reason = "number is already served by our SPID; purchase it instead"
+	}
+
+	return result, nil
+}
+
+// CreatePortRequest creates a DRAFT port-in request and pre-checks its numbers
+func (s *NumberService) CreatePortRequest(
+	ctx context.Context,
+	req *models.CreatePortRequestRequest,
+	customerID uuid.UUID,
+	createdBy uuid.UUID,
+) (*models.PortRequest, error) {
+	pr := &models.PortRequest{
+		CustomerID:            customerID,
+		RequestedFOCDate:      req.RequestedFOCDate,
+		LosingCarrier:         req.LosingCarrier,
+		AuthorizedPerson:      req.AuthorizedPerson,
+		AuthorizedPersonTitle: req.AuthorizedPersonTitle,
+		EndUserName:           req.EndUserName,
+		ServiceAddress:        req.ServiceAddress,
+		TrunkID:               req.TrunkID,
+		VoiceEnabled:          true,
+		SMSEnabled:            req.SMSEnabled,
+		Notes:                 req.Notes,
+		CreatedBy:             &createdBy,
+	}
+	if req.VoiceEnabled != nil {
+		pr.VoiceEnabled = *req.VoiceEnabled
+	}
+
+	numbers, err := s.portRequestNumbers(req.Numbers, &req.PortNumberDetails)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreatePortRequest(ctx, pr, numbers); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Port-in request created",
+		zap.String("port_request_id", pr.ID.String()),
+		zap.String("customer_id", customerID.String()),
+		zap.Int("numbers", len(pr.Numbers)),
+	)
+
+	// Pre-check failures are not fatal: submit checks again
+	if err := s.recordPortabilityChecks(ctx, pr.Numbers); err != nil {
+		s.logger.Warn("Portability pre-check failed", zap.String("port_request_id", pr.ID.String()), zap.Error(err))
+	}
+
+	return s.GetPortRequest(ctx, pr.ID, nil)
+}
+
+// GetPortRequest retrieves a port request with its numbers and documents
+func (s *NumberService) GetPortRequest(ctx context.Context, id uuid.UUID, customerFilter []uuid.UUID) (*models.PortRequest, error) {
+	pr, err := s.repo.GetPortRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pr == nil {
+		return nil, ErrPortRequestNotFound
+	}
+	if !s.hasCustomerAccess(pr.CustomerID, customerFilter) {
+		return nil, ErrAccessDenied
+	}
+
+	if pr.Numbers, err = s.repo.ListPortRequestNumbers(ctx, id); err != nil {
+		return nil, err
+	}
+	if pr.Documents, err = s.repo.ListPortRequestDocuments(ctx, id); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// ListPortRequests lists port requests visible to customerFilter (nil for all)
+func (s *NumberService) ListPortRequests(ctx context.Context, customerFilter []uuid.UUID, status string, page, perPage int) ([]models.PortRequest, int64, error) {
+	return s.repo.ListPortRequests(ctx, customerFilter, status, perPage, (page-1)*perPage)
+}
+
+// UpdatePortRequest changes a DRAFT or REJECTED port request. New numbers
+// replace the old ones and are pre-checked again.
+func (s *NumberService) UpdatePortRequest(
+	ctx context.Context,
+	id uuid.UUID,
+	req *models.UpdatePortRequestRequest,
+	customerFilter []uuid.UUID,
+	updatedBy uuid.UUID,
+) (*models.PortRequest, error) {
+	if _, err := s.GetPortRequest(ctx, id, customerFilter); err != nil {
+		return nil, err
+	}
+
+	var numbers []models.PortRequestNumber
+	if len(req.Numbers) > 0 {
+		var err error
+		if numbers, err = s.portRequestNumbers(req.Numbers, &req.PortNumberDetails); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.repo.UpdatePortRequest(ctx, id, req, updatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrPortRequestInvalidState
+	}
+
+	if len(numbers) > 0 {
+		numbers, err := s.repo.ReplacePortRequestNumbers(ctx, id, numbers)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.recordPortabilityChecks(ctx, numbers); err != nil {
+			s.logger.Warn("Portability pre-check failed", zap.String("port_request_id", id.String()), zap.Error(err))
+		}
+	}
+
+	return s.GetPortRequest(ctx, id, customerFilter)
+}
+
+// AttachPortDocument records an LOA, CSR or bill copy on a port request that
+// has not finished
+func (s *NumberService) AttachPortDocument(
+	ctx context.Context,
+	id uuid.UUID,
+	req *models.AttachPortDocumentRequest,
+	customerFilter []uuid.UUID,
+	uploadedBy uuid.UUID,
+) (*models.PortRequestDocument, error) {
+	pr, err := s.GetPortRequest(ctx, id, customerFilter)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Status == PortActivated || pr.Status == PortCancelled {
+		return nil, ErrPortRequestInvalidState
+	}
+
+	doc := &models.PortRequestDocument{
+		PortRequestID: id,
+		DocumentType:  req.DocumentType,
+		FileName:      req.FileName,
+		FileURL:       req.FileURL,
+		ContentType:   req.ContentType,
+		SizeBytes:     req.SizeBytes,
+		UploadedBy:    &uploadedBy,
+	}
+	if err := s.repo.AddPortRequestDocument(ctx, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// DeletePortDocument removes a document from a DRAFT or REJECTED port request
+func (s *NumberService) DeletePortDocument(ctx context.Context, id, documentID uuid.UUID, customerFilter []uuid.UUID) error {
+	pr, err := s.GetPortRequest(ctx, id, customerFilter)
+	if err != nil {
+		return err
+	}
+	if pr.Status != PortDraft && pr.Status != PortRejected {
+		return ErrPortRequestInvalidState
+	}
+
+	deleted, err := s.repo.DeletePortRequestDocument(ctx, id, documentID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrPortDocumentNotFound
+	}
+	return nil
+}
+
+// SubmitPortRequest submits a DRAFT (or corrected REJECTED) request. It
+// needs an LOA, re-runs the portability check, and refuses numbers that are
+// not portable or already in another open port.
+func (s *NumberService) SubmitPortRequest(ctx context.Context, id uuid.UUID, customerFilter []uuid.UUID, submittedBy uuid.UUID) (*models.PortRequest, error) {
+	pr, err := s.GetPortRequest(ctx, id, customerFilter)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Status != PortDraft && pr.Status != PortRejected {
+		return nil, ErrPortRequestInvalidState
+	}
+
+	hasLOA := false
+	for _, doc := range pr.Documents {
+		if doc.DocumentType == "LOA" {
+			hasLOA = true
+		}
+	}
+	if !hasLOA {
+		return nil, fmt.Errorf("%w: a signed LOA document is required", ErrPortRequestIncomplete)
+	}
+	if len(pr.Numbers) == 0 {
+		return nil, fmt.Errorf("%w: no numbers to port", ErrPortRequestIncomplete)
+	}
+
+	if err := s.recordPortabilityChecks(ctx, pr.Numbers); err != nil {
+		return nil, err
+	}
+	numbers, err := s.repo.ListPortRequestNumbers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tns := make([]string, 0, len(numbers))
+	var notPortable []string
+	for _, n := range numbers {
+		tns = append(tns, n.Number)
+		if n.Portability != nil && *n.Portability == PortabilityNotPortable {
+			notPortable = append(notPortable, n.Number)
+		}
+	}
+	if len(notPortable) > 0 {
+		return nil, fmt.Errorf("%w: numbers not portable: %s", ErrPortRequestIncomplete, strings.Join(notPortable, ", "))
+	}
+
+	conflicts, err := s.repo.ListNumbersInOpenPorts(ctx, tns, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: numbers already in another open port request: %s", ErrPortRequestIncomplete, strings.Join(conflicts, ", "))
+	}
+
+	ok, err := s.repo.SubmitPortRequest(ctx, id, submittedBy)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPortRequestInvalidState
+	}
+
+	s.logger.Info("Port-in request submitted",
+		zap.String("port_request_id", id.String()),
+		zap.Int("numbers", len(numbers)),
+	)
+
+	return s.GetPortRequest(ctx, id, customerFilter)
+}
+
+// CancelPortRequest cancels a port request that has not been activated
+func (s *NumberService) CancelPortRequest(ctx context.Context, id uuid.UUID, customerFilter []uuid.UUID, cancelledBy uuid.UUID) (*models.PortRequest, error) {
+	if _, err := s.GetPortRequest(ctx, id, customerFilter); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.CancelPortRequest(ctx, id, cancelledBy)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPortRequestInvalidState
+	}
+
+	return s.GetPortRequest(ctx, id, customerFilter)
+}
+
+// RecordPortFOC records the losing carrier's Firm Order Commitment (admin)
+func (s *NumberService) RecordPortFOC(ctx context.Context, id uuid.UUID, req *models.RecordPortFOCRequest, updatedBy uuid.UUID) (*models.PortRequest, error) {
+	if _, err := s.GetPortRequest(ctx, id, nil); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.RecordPortFOC(ctx, id, req, updatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPortRequestInvalidState
+	}
+
+	s.logger.Info("Port-in FOC received",
+		zap.String("port_request_id", id.String()),
+		zap.Time("foc_date", req.FOCDate),
+	)
+
+	return s.GetPortRequest(ctx, id, nil)
+}
+
+// RejectPortRequest records a losing carrier rejection (admin). The customer
+// can correct the request and submit it again.
+func (s *NumberService) RejectPortRequest(ctx context.Context, id uuid.UUID, reason string, updatedBy uuid.UUID) (*models.PortRequest, error) {
+	if _, err := s.GetPortRequest(ctx, id, nil); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.RejectPortRequest(ctx, id, reason, updatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPortRequestInvalidState
+	}
+
+	s.logger.Info("Port-in request rejected",
+		zap.String("port_request_id", id.String()),
+		zap.String("reason", reason),
+	)
+
+	return s.GetPortRequest(ctx, id, nil)
+}
+
+// ActivatePortRequest completes a port once the numbers are live on our
+// network (admin): each number is assigned in SOA and gets an assigned
+// number with the request's routing. Numbers that fail keep the request in
+// FOC_RECEIVED with ErrPortActivationIncomplete; activating again retries
+// only those.
+func (s *NumberService) ActivatePortRequest(ctx context.Context, id uuid.UUID, activatedBy uuid.UUID) (*models.PortRequest, error) {
+	pr, err := s.GetPortRequest(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Status != PortFOCReceived {
+		return nil, ErrPortRequestInvalidState
+	}
+
+	failed := 0
+	for i := range pr.Numbers {
+		n := &pr.Numbers[i]
+		if n.AssignedNumberID != nil {
+			continue // Activated on an earlier attempt
+		}
+
+		assigned, err := s.activatePortedNumber(ctx, pr, n, activatedBy)
+		if err != nil {
+			failed++
+			s.logger.Error("Failed to activate ported number",
+				zap.String("port_request_id", id.String()),
+				zap.String("number", n.Number),
+				zap.Error(err),
+			)
+			if markErr := s.repo.MarkPortNumberFailed(ctx, n.ID, err.Error()); markErr != nil {
+				s.logger.Warn("Failed to record port number failure", zap.Error(markErr))
+			}
+			continue
+		}
+
+		if err := s.repo.MarkPortNumberActivated(ctx, n.ID, assigned.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if failed > 0 {
+		return nil, fmt.Errorf("%w: %d of %d numbers failed", ErrPortActivationIncomplete, failed, len(pr.Numbers))
+	}
+
+	ok, err := s.repo.CompletePortRequest(ctx, id, activatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPortRequestInvalidState
+	}
+
+	s.logger.Info("Port-in request activated",
+		zap.String("port_request_id", id.String()),
+		zap.String("customer_id", pr.CustomerID.String()),
+		zap.Int("numbers", len(pr.Numbers)),
+	)
+
+	return s.GetPortRequest(ctx, id, nil)
+}
+
+// activatePortedNumber assigns a ported-in number in SOA and creates its
+// local record. SOA may not list the number until LSMS catches up with the
+// port; the local record is then created PENDING sync.
+func (s *NumberService) activatePortedNumber(
+	ctx context.Context,
+	pr *models.PortRequest,
+	n *models.PortRequestNumber,
+	activatedBy uuid.UUID,
+) (*models.AssignedNumber, error) {
+	existing, err := s.repo.GetByNumber(ctx, n.Number)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.Active && existing.CustomerID == pr.CustomerID {
+			return existing, nil
+		}
+		if existing.Active {
+			return nil, fmt.Errorf("number is active on another customer")
+		}
+		// Released earlier: the row is reused below
+	}
+
+	// A port request carries no E911 address, so under the E911 policy the
+	// number arrives with voice off until the customer provisions one
+	voiceEnabled := pr.VoiceEnabled
+	if err := s.checkE911Policy(ctx, voiceEnabled, false, nil); err != nil {
+		if !errors.Is(err, ErrE911Required) {
+			return nil, err
+		}
+		s.logger.Info("Activating ported number with voice disabled pending E911",
+			zap.String("number", n.Number),
+			zap.String("port_request_id", pr.ID.String()),
+		)
+		voiceEnabled = false
+	}
+
+	number := &models.AssignedNumber{
+		CustomerID:    pr.CustomerID,
+		Number:        n.Number,
+		SOASyncStatus: "SYNCED",
+		NumberType:    "DID",
+		RateCenter:    n.RateCenter,
+		State:         n.State,
+		VoiceEnabled:  voiceEnabled,
+		SMSEnabled:    pr.SMSEnabled,
+		TrunkID:       pr.TrunkID,
+		Active:        true,
+		ActivatedAt:   time.Now(),
+		CreatedBy:     &activatedBy,
+		Description:   strPtr(fmt.Sprintf("Ported in (port request %s)", pr.ID)),
+	}
+	if len(n.Number) == 12 && strings.HasPrefix(n.Number, "+1") {
+		number.NPA = strPtr(n.Number[2:5])
+		number.NXX = strPtr(n.Number[5:8])
+	}
+
+	soaReq := &soa.AssignRequest{
+		ApplicationID: pr.CustomerID.String(),
+		Metadata: map[string]interface{}{
+			"customer_id":     pr.CustomerID.String(),
+			"created_by":      activatedBy.String(),
+			"source":          "ringer-warp",
+			"port_request_id": pr.ID.String(),
+		},
+	}
+	if pr.TrunkID != nil {
+		soaReq.Metadata["trunk_id"] = pr.TrunkID.String()
+	}
+
+	soaNumber, err := s.soaClient.AssignNumber(ctx, n.Number, soaReq)
+	if err != nil {
+		s.logger.Warn("SOA assignment of ported number failed, creating local record pending sync",
+			zap.String("number", n.Number),
+			zap.Error(err),
+		)
+		number.SOASyncStatus = "PENDING"
+	} else {
+		number.SOANumberID = &soaNumber.ID
+		number.NPA = strPtr(soaNumber.NPA)
+		number.NXX = strPtr(soaNumber.NXX)
+		number.RateCenter = strPtr(soaNumber.Locality)
+		number.State = strPtr(soaNumber.State)
+	}
+
+	created, err := s.createAssignedNumber(ctx, existing, number)
+	if err != nil {
+		return nil, err
+	}
+
+	s.refreshDIDRoute(ctx, created.ID, created.Number)
+	return created, nil
+}
+
+// recordPortabilityChecks pre-checks port request numbers and stores the results
+func (s *NumberService) recordPortabilityChecks(ctx context.Context, numbers []models.PortRequestNumber) error {
+	tns := make([]string, len(numbers))
+	for i, n := range numbers {
+		tns[i] = n.Number
+	}
+
+	results, err := s.CheckPortability(ctx, tns)
+	if err != nil {
+		return err
+	}
+
+	byNumber := make(map[string]*models.PortabilityCheckResult, len(results))
+	for i := range results {
+		byNumber[results[i].Number] = &results[i]
+	}
+	for _, n := range numbers {
+		if result, ok := byNumber[n.Number]; ok {
+			if err := s.repo.RecordPortabilityCheck(ctx, n.ID, result); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SetPortPINCipher sets the cipher carrier account PINs are sealed with.
+// Without one, port requests that carry a PIN are refused.
+func (s *NumberService) SetPortPINCipher(cipher *secrets.Cipher) {
+	s.portPINCipher = cipher
+}
+
+// portRequestNumbers builds port request numbers sharing the same losing
+// carrier account details; the BTN, if listed, is flagged and the PIN sealed
+func (s *NumberService) portRequestNumbers(numbers []string, details *models.PortNumberDetails) ([]models.PortRequestNumber, error) {
+	var pin *string
+	if details.PINPasscode != nil && *details.PINPasscode != "" {
+		if s.portPINCipher == nil {
+			return nil, ErrPortPINNotConfigured
+		}
+		sealed, err := s.portPINCipher.Seal(*details.PINPasscode)
+		if err != nil {
+			return nil, err
+		}
+		pin = &sealed
+	}
+
+	unique := uniqueNumbers(numbers)
+	result := make([]models.PortRequestNumber, len(unique))
+	for i, tn := range unique {
+		result[i] = models.PortRequestNumber{
+			Number:          tn,
+			IsBTN:           details.BTN != nil && *details.BTN == tn,
+			BTN:             details.BTN,
+			CurrentProvider: details.CurrentProvider,
+			AccountNumber:   details.AccountNumber,
+			PINSealed:       pin,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/pgerr"
+	"github.com/ringer-warp/api-gateway/internal/soa"
+	"github.com/ringer-warp/api-gateway/internal/worker"
+	"go.uber.org/zap"
+)
+
+// ErrPurchaseInProgress indicates another purchase of the same number has not finished
+var ErrPurchaseInProgress = errors.New("a purchase of this number is already in progress")
+
+// ErrPurchaseIntentNotFound indicates the purchase intent does not exist
+var ErrPurchaseIntentNotFound = errors.New("purchase intent not found")
+
+// ErrPurchaseIntentNotRetryable indicates the intent is not in REPAIR_FAILED
+var ErrPurchaseIntentNotRetryable = errors.New("only REPAIR_FAILED purchase intents can be retried")
+
+// Purchase intent statuses (see schemas/22-number-purchase-intents.sql)
+const (
+	PurchasePending      = "PENDING"
+	PurchaseSOAAssigned  = "SOA_ASSIGNED"
+	PurchaseCompleted    = "COMPLETED"
+	PurchaseFailed       = "FAILED"
+	PurchaseCompensated  = "COMPENSATED"
+	PurchaseRepair       = "REPAIR"
+	PurchaseRepairFailed = "REPAIR_FAILED"
+)
+
+const (
+	// purchaseStaleAfter is how long a PENDING/SOA_ASSIGNED intent may sit
+	// before the repair worker assumes its request died. It must exceed the
+	// worst-case SOA client latency (timeout x retries plus backoff).
+	purchaseStaleAfter = 10 * time.Minute
+
+	// maxPurchaseRepairAttempts bounds repair retries before REPAIR_FAILED
+	maxPurchaseRepairAttempts = 10
+
+	// purchaseRepairBatch is the number of intents handled per worker pass
+	purchaseRepairBatch = 100
+)
+
+// purchaseNumber runs the purchase saga for one telephone number:
+//
+//  1. Persist a PENDING intent (at most one in flight per number)
+//  2. Assign in SOA (RESERVED → IN_USE)              → SOA_ASSIGNED
+//  3. Create the local assigned_numbers row          → COMPLETED
+//
+// A definitive SOA refusal marks the intent FAILED. If the SOA outcome is
+// unknown, or the local write fails and the compensating SOA release also
+// fails, the intent is queued for the repair worker instead, so SOA and
+// numbers.assigned_numbers always converge.
+func (s *NumberService) purchaseNumber(
+	ctx context.Context,
+	tn string,
+	req *models.PurchaseNumberRequest,
+	customerID uuid.UUID,
+	createdBy uuid.UUID,
+) (*models.AssignedNumber, error) {
+	// Step 1: Persist intent
+	intent := &models.NumberPurchaseIntent{
+		CustomerID:   customerID,
+		Number:       tn,
+		VoiceEnabled: req.VoiceEnabled,
+		SMSEnabled:   req.SMSEnabled,
+		TrunkID:      req.TrunkID,
+		CampaignID:   req.CampaignID,
+		CreatedBy:    &createdBy,
+	}
+	if err := s.repo.CreatePurchaseIntent(ctx, intent); err != nil {
+		if pgerr.IsUniqueViolation(err) {
+			return nil, ErrPurchaseInProgress
+		}
+		return nil, err
+	}
+
+	// Step 2: Assign in SOA
+	soaReq := &soa.AssignRequest{
+		ApplicationID: customerID.String(),
+		Metadata: map[string]interface{}{
+			"customer_id":        customerID.String(),
+			"created_by":         createdBy.String(),
+			"source":             "ringer-warp",
+			"purchase_intent_id": intent.ID.String(),
+		},
+	}
+
+	soaNumber, err := s.soaClient.AssignNumber(ctx, tn, soaReq)
+	if err != nil {
+		s.logger.Error("Failed to assign number in SOA",
+			zap.String("number", tn),
+			zap.Error(err),
+		)
+		if isDefinitiveSOAError(err) {
+			msg := err.Error()
+			s.resolvePurchase(ctx, intent, PurchaseFailed, &msg)
+			return nil, err
+		}
+		// The assignment may have landed; let the repair worker find out
+		s.schedulePurchaseRepair(ctx, intent, fmt.Errorf("SOA assign outcome unknown: %w", err))
+		return nil, fmt.Errorf("SOA assignment outcome unknown, queued for repair: %w", err)
+	}
+
+	if err := s.repo.MarkPurchaseIntentAssigned(ctx, intent.ID, soaNumber.ID); err != nil {
+		// Not fatal: the intent goes stale and repair checks SOA directly
+		s.logger.Warn("Failed to record SOA assignment on purchase intent",
+			zap.String("intent_id", intent.ID.String()),
+			zap.Error(err),
+		)
+	}
+	intent.SOANumberID = &soaNumber.ID
+
+	// Step 3: Create local record
+	created, err := s.savePurchasedNumber(ctx, newAssignedNumber(soaNumber, intent))
+	if err != nil {
+		s.logger.Error("Failed to create local number record",
+			zap.String("number", tn),
+			zap.Error(err),
+		)
+		s.compensatePurchase(ctx, intent, fmt.Errorf("local save failed: %w", err))
+		return nil, fmt.Errorf("local save failed: %w", err)
+	}
+
+	if err := s.repo.CompletePurchaseIntent(ctx, intent.ID, created.ID); err != nil {
+		// Not fatal: repair finds the local row and completes the intent
+		s.logger.Warn("Failed to complete purchase intent",
+			zap.String("intent_id", intent.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	s.refreshDIDRoute(ctx, created.ID, created.Number)
+	return created, nil
+}
+
+// compensatePurchase releases a number in SOA after the local write failed,
+// queueing a repair if the release fails too
+func (s *NumberService) compensatePurchase(ctx context.Context, intent *models.NumberPurchaseIntent, cause error) {
+	if _, err := s.soaClient.ReleaseNumber(ctx, intent.Number); err != nil && !soa.IsNotFound(err) {
+		s.logger.Error("Failed to release number in SOA after local save failure",
+			zap.String("number", intent.Number),
+			zap.Error(err),
+		)
+		s.schedulePurchaseRepair(ctx, intent, fmt.Errorf("%v; compensating release failed: %w", cause, err))
+		return
+	}
+
+	msg := cause.Error()
+	s.resolvePurchase(ctx, intent, PurchaseCompensated, &msg)
+	s.logger.Info("Purchase compensated: number released in SOA",
+		zap.String("number", intent.Number),
+		zap.String("intent_id", intent.ID.String()),
+	)
+}
+
+// StartPurchaseRepairWorker repairs unfinished purchase intents every
+// interval until ctx is cancelled
+func (s *NumberService) StartPurchaseRepairWorker(ctx context.Context, interval time.Duration) {
+	worker.Every(ctx, interval, nil, func(ctx context.Context) {
+		s.RepairPurchaseIntents(ctx)
+	})
+}
+
+// RepairPurchaseIntents makes one repair pass and returns the number of
+// intents examined
+func (s *NumberService) RepairPurchaseIntents(ctx context.Context) int {
+	intents, err := s.repo.ListPurchaseIntentsDue(ctx, time.Now().Add(-purchaseStaleAfter), purchaseRepairBatch)
+	if err != nil {
+		s.logger.Warn("Failed to list purchase intents for repair", zap.Error(err))
+		return 0
+	}
+
+	for i := range intents {
+		s.repairPurchaseIntent(ctx, &intents[i])
+	}
+	return len(intents)
+}
+
+// repairPurchaseIntent converges one intent. A purchase that did not
+// complete in its request is never finished later (the caller was told it
+// failed): if the number is live locally the intent is completed, otherwise
+// any SOA assignment to the customer is released.
+func (s *NumberService) repairPurchaseIntent(ctx context.Context, intent *models.NumberPurchaseIntent) {
+	local, err := s.repo.GetByNumber(ctx, intent.Number)
+	if err != nil {
+		s.schedulePurchaseRepair(ctx, intent, err)
+		return
+	}
+	if local != nil && local.Active && local.CustomerID == intent.CustomerID {
+		if err := s.repo.CompletePurchaseIntent(ctx, intent.ID, local.ID); err != nil {
+			s.logger.Warn("Failed to complete repaired purchase intent", zap.String("intent_id", intent.ID.String()), zap.Error(err))
+		}
+		return
+	}
+
+	soaNumber, err := s.soaClient.GetNumberDetails(ctx, intent.Number)
+	if err != nil && !soa.IsNotFound(err) {
+		s.schedulePurchaseRepair(ctx, intent, fmt.Errorf("SOA lookup failed: %w", err))
+		return
+	}
+
+	assignedToUs := soaNumber != nil && soaNumber.Status == soa.StatusInUse &&
+		soaNumber.ApplicationID == intent.CustomerID.String()
+	if !assignedToUs {
+		// Nothing held in SOA for this purchase
+		status := PurchaseFailed
+		if intent.SOANumberID != nil {
+			status = PurchaseCompensated
+		}
+		s.resolvePurchase(ctx, intent, status, nil)
+		return
+	}
+
+	if _, err := s.soaClient.ReleaseNumber(ctx, intent.Number); err != nil && !soa.IsNotFound(err) {
+		s.schedulePurchaseRepair(ctx, intent, fmt.Errorf("compensating release failed: %w", err))
+		return
+	}
+
+	s.resolvePurchase(ctx, intent, PurchaseCompensated, nil)
+	s.logger.Info("Purchase repair released number in SOA",
+		zap.String("number", intent.Number),
+		zap.String("intent_id", intent.ID.String()),
+	)
+}
+
+// ListPurchaseIntents lists purchase intents, optionally filtered by status
+func (s *NumberService) ListPurchaseIntents(ctx context.Context, status string, limit int) ([]models.NumberPurchaseIntent, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListPurchaseIntents(ctx, status, limit)
+}
+
+// RetryPurchaseIntent requeues a REPAIR_FAILED intent and repairs it immediately
+func (s *NumberService) RetryPurchaseIntent(ctx context.Context, id uuid.UUID) (*models.NumberPurchaseIntent, error) {
+	intent, err := s.repo.GetPurchaseIntent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if intent == nil {
+		return nil, ErrPurchaseIntentNotFound
+	}
+
+	requeued, err := s.repo.RetryPurchaseIntent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !requeued {
+		return nil, ErrPurchaseIntentNotRetryable
+	}
+
+	intent.Status = PurchaseRepair
+	intent.Attempts = 0
+	s.repairPurchaseIntent(ctx, intent)
+
+	return s.repo.GetPurchaseIntent(ctx, id)
+}
+
+// schedulePurchaseRepair queues an intent for retry with exponential backoff,
+// giving up after maxPurchaseRepairAttempts
+func (s *NumberService) schedulePurchaseRepair(ctx context.Context, intent *models.NumberPurchaseIntent, cause error) {
+	msg := cause.Error()
+
+	if intent.Attempts+1 >= maxPurchaseRepairAttempts {
+		s.logger.Error("Purchase repair exhausted, manual action required",
+			zap.String("number", intent.Number),
+			zap.String("intent_id", intent.ID.String()),
+			zap.Error(cause),
+		)
+		s.resolvePurchase(ctx, intent, PurchaseRepairFailed, &msg)
+		return
+	}
+
+	backoff := worker.Backoff(intent.Attempts)
+
+	if err := s.repo.SchedulePurchaseRepair(ctx, intent.ID, msg, time.Now().Add(backoff)); err != nil {
+		// Still covered: a PENDING/SOA_ASSIGNED intent goes stale and is repaired
+		s.logger.Error("Failed to schedule purchase repair",
+			zap.String("intent_id", intent.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *NumberService) resolvePurchase(ctx context.Context, intent *models.NumberPurchaseIntent, status string, lastError *string) {
+	if err := s.repo.ResolvePurchaseIntent(ctx, intent.ID, status, lastError); err != nil {
+		s.logger.Error("Failed to update purchase intent",
+			zap.String("intent_id", intent.ID.String()),
+			zap.String("status", status),
+			zap.Error(err),
+		)
+	}
+}
+
+// savePurchasedNumber stores the local record for a purchased number, reusing
+// the row left by an earlier release of the same TN
+func (s *NumberService) savePurchasedNumber(ctx context.Context, number *models.AssignedNumber) (*models.AssignedNumber, error) {
+	existing, err := s.repo.GetByNumber(ctx, number.Number)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Active {
+		return nil, fmt.Errorf("number is already active locally")
+	}
+	return s.createAssignedNumber(ctx, existing, number)
+}
+
+// newAssignedNumber builds the local record for a number assigned in SOA
+func newAssignedNumber(soaNumber *soa.NumberInventory, intent *models.NumberPurchaseIntent) *models.AssignedNumber {
+	number := &models.AssignedNumber{
+		CustomerID:    intent.CustomerID,
+		Number:        soaNumber.TelephoneNumber,
+		SOANumberID:   &soaNumber.ID,
+		SOASyncStatus: "SYNCED",
+		NumberType:    "DID", // Default, could be determined from TN format
+		NPA:           strPtr(soaNumber.NPA),
+		NXX:           strPtr(soaNumber.NXX),
+		RateCenter:    strPtr(soaNumber.Locality),
+		State:         strPtr(soaNumber.State),
+		VoiceEnabled:  intent.VoiceEnabled,
+		SMSEnabled:    intent.SMSEnabled,
+		TrunkID:       intent.TrunkID,
+		CampaignID:    intent.CampaignID,
+		Active:        true,
+		ActivatedAt:   time.Now(),
+		CreatedBy:     intent.CreatedBy,
+		UpdatedBy:     intent.CreatedBy,
+	}
+
+	// Check if toll-free
+	if isTollFree(intent.Number) {
+		number.NumberType = "TOLL_FREE"
+	}
+
+	return number
+}
+
+// isDefinitiveSOAError reports whether SOA answered with a client error, so
+// the assignment certainly did not happen. Network errors and 5xx responses
+// leave the outcome unknown.
+func isDefinitiveSOAError(err error) bool {
+	var apiErr *soa.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode < 500
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/soa"
+)
+
+func TestIsDefinitiveSOAError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"bad request", &soa.APIError{StatusCode: 400}, true},
+		{"not found", &soa.APIError{StatusCode: 404}, true},
+		{"conflict", &soa.APIError{StatusCode: 409}, true},
+		{"wrapped conflict", fmt.Errorf("assign: %w", &soa.APIError{StatusCode: 409}), true},
+		{"server error", &soa.APIError{StatusCode: 500}, false},
+		{"unavailable", &soa.APIError{StatusCode: 503}, false},
+		{"network", errors.New("request failed after 4 attempts: connection refused"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := isDefinitiveSOAError(tt.err); got != tt.want {
+			t.Errorf("isDefinitiveSOAError(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// intentStatus returns the status of the latest purchase intent for tn
+func intentStatus(t *testing.T, pool *pgxpool.Pool, tn string) string {
+	t.Helper()
+	return queryString(t, pool, `
+		SELECT status FROM numbers.purchase_intents
+		WHERE number = $1 ORDER BY created_at DESC LIMIT 1`, tn)
+}
+
+func TestPurchaseNumbers(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	ctx := context.Background()
+	customerID := testCustomer(t, pool)
+	userID := uuid.New()
+
+	tn := testTN("303")
+	fake.add(tn, soa.StatusAvailable)
+
+	purchased, errs := s.PurchaseNumbers(ctx, &models.PurchaseNumberRequest{Numbers: []string{tn}, SMSEnabled: true}, customerID, userID)
+	if len(errs) != 0 || len(purchased) != 1 {
+		t.Fatalf("PurchaseNumbers() = %d purchased, errors %v", len(purchased), errs)
+	}
+
+	got := purchased[0]
+	if got.Number != tn || got.CustomerID != customerID || !got.Active || !got.SMSEnabled {
+		t.Errorf("purchased number = %+v", got)
+	}
+	if inv := fake.get(tn); inv.Status != soa.StatusInUse || inv.ApplicationID != customerID.String() {
+		t.Errorf("SOA status = %s owned by %q, want IN_USE owned by the customer", inv.Status, inv.ApplicationID)
+	}
+	if status := intentStatus(t, pool, tn); status != PurchaseCompleted {
+		t.Errorf("intent status = %s, want %s", status, PurchaseCompleted)
+	}
+
+	// The number is no longer assignable
+	_, errs = s.PurchaseNumbers(ctx, &models.PurchaseNumberRequest{Numbers: []string{tn}}, customerID, userID)
+	if len(errs) != 1 {
+		t.Errorf("second purchase errors = %v, want one", errs)
+	}
+}
+
+func TestPurchaseNumbersRefused(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	ctx := context.Background()
+	customerID := testCustomer(t, pool)
+
+	tn := testTN("303")
+	fake.add(tn, soa.StatusAvailable)
+	fake.failWith("assign", tn, http.StatusConflict)
+
+	purchased, errs := s.PurchaseNumbers(ctx, &models.PurchaseNumberRequest{Numbers: []string{tn}}, customerID, uuid.New())
+	if len(purchased) != 0 || len(errs) != 1 {
+		t.Fatalf("PurchaseNumbers() = %d purchased, errors %v", len(purchased), errs)
+	}
+
+	if status := intentStatus(t, pool, tn); status != PurchaseFailed {
+		t.Errorf("intent status = %s, want %s", status, PurchaseFailed)
+	}
+	if local, err := s.repo.GetByNumber(ctx, tn); err != nil || local != nil {
+		t.Errorf("local number = %+v, %v; want none", local, err)
+	}
+}
+
+func TestPurchaseNumbersCompensated(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	ctx := context.Background()
+	customerID := testCustomer(t, pool)
+	otherID := testCustomer(t, pool)
+
+	// The local row already exists for another customer, so the local
+	// write fails after SOA has assigned the number
+	tn := testTN("303")
+	fake.add(tn, soa.StatusAvailable)
+	if _, err := s.repo.Create(ctx, &models.AssignedNumber{CustomerID: otherID, Number: tn, Active: true, ActivatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	purchased, errs := s.PurchaseNumbers(ctx, &models.PurchaseNumberRequest{Numbers: []string{tn}}, customerID, uuid.New())
+	if len(purchased) != 0 || len(errs) != 1 {
+		t.Fatalf("PurchaseNumbers() = %d purchased, errors %v", len(purchased), errs)
+	}
+
+	if status := intentStatus(t, pool, tn); status != PurchaseCompensated {
+		t.Errorf("intent status = %s, want %s", status, PurchaseCompensated)
+	}
+	if inv := fake.get(tn); inv.Status != soa.StatusReserved || fake.released(tn) != 1 {
+		t.Errorf("SOA status = %s after %d releases, want RESERVED after 1", inv.Status, fake.released(tn))
+	}
+}
+
+func TestPurchaseNumbersAfterRelease(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	s.SetQuarantinePeriod(0)
+	ctx := context.Background()
+	customerID := testCustomer(t, pool)
+
+	number := purchaseTestNumber(t, s, fake, customerID)
+	if err := s.ReleaseNumber(ctx, number.ID, &models.ReleaseNumberRequest{Reason: "test"}, nil, uuid.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Buying the TN again reuses its released row
+	purchased, errs := s.PurchaseNumbers(ctx, &models.PurchaseNumberRequest{Numbers: []string{number.Number}}, customerID, uuid.New())
+	if len(errs) != 0 || len(purchased) != 1 {
+		t.Fatalf("PurchaseNumbers() = %d purchased, errors %v", len(purchased), errs)
+	}
+	if again := purchased[0]; again.ID != number.ID || !again.Active {
+		t.Errorf("repurchased number = %s (active %v), want %s reused", again.ID, again.Active, number.ID)
+	}
+	if status := intentStatus(t, pool, number.Number); status != PurchaseCompleted {
+		t.Errorf("intent status = %s, want %s", status, PurchaseCompleted)
+	}
+}
+
+func TestRepairPurchaseIntent(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	ctx := context.Background()
+	customerID := testCustomer(t, pool)
+
+	newIntent := func(tn string) *models.NumberPurchaseIntent {
+		intent := &models.NumberPurchaseIntent{CustomerID: customerID, Number: tn}
+		if err := s.repo.CreatePurchaseIntent(ctx, intent); err != nil {
+			t.Fatal(err)
+		}
+		return intent
+	}
+
+	t.Run("assigned in SOA only", func(t *testing.T) {
+		tn := testTN("303")
+		fake.add(tn, soa.StatusInUse)
+		fake.set(tn, soa.StatusInUse, customerID.String())
+
+		s.repairPurchaseIntent(ctx, newIntent(tn))
+
+		if status := intentStatus(t, pool, tn); status != PurchaseCompensated {
+			t.Errorf("intent status = %s, want %s", status, PurchaseCompensated)
+		}
+		if fake.released(tn) != 1 {
+			t.Errorf("SOA releases = %d, want 1", fake.released(tn))
+		}
+	})
+
+	t.Run("assigned to someone else", func(t *testing.T) {
+		tn := testTN("303")
+		fake.add(tn, soa.StatusInUse)
+		fake.set(tn, soa.StatusInUse, uuid.NewString())
+
+		s.repairPurchaseIntent(ctx, newIntent(tn))
+
+		if status := intentStatus(t, pool, tn); status != PurchaseFailed {
+			t.Errorf("intent status = %s, want %s", status, PurchaseFailed)
+		}
+		if fake.released(tn) != 0 {
+			t.Errorf("SOA releases = %d, want 0", fake.released(tn))
+		}
+	})
+
+	t.Run("live locally", func(t *testing.T) {
+		tn := testTN("303")
+		fake.add(tn, soa.StatusInUse)
+		fake.set(tn, soa.StatusInUse, customerID.String())
+		intent := newIntent(tn)
+		if _, err := s.repo.Create(ctx, &models.AssignedNumber{CustomerID: customerID, Number: tn, Active: true, ActivatedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+
+		s.repairPurchaseIntent(ctx, intent)
+
+		if status := intentStatus(t, pool, tn); status != PurchaseCompleted {
+			t.Errorf("intent status = %s, want %s", status, PurchaseCompleted)
+		}
+		if fake.released(tn) != 0 {
+			t.Errorf("SOA releases = %d, want 0", fake.released(tn))
+		}
+	})
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/soa"
+	"github.com/ringer-warp/api-gateway/internal/worker"
+	"go.uber.org/zap"
+)
+
+// ErrNotQuarantined indicates the number is not in quarantine or its aging
+// period has ended
+var ErrNotQuarantined = errors.New("number is not in quarantine or its aging period has ended")
+
+// ErrNumberPortedOut indicates the number has been ported to another carrier
+var ErrNumberPortedOut = errors.New("number has been ported to another carrier")
+
+// Quarantine statuses (see schemas/30-number-quarantine.sql)
+const (
+	NumberQuarantined      = string(soa.StatusQuarantine)
+	NumberRestored         = "RESTORED"
+	NumberReleased         = "RELEASED"
+	NumberQuarantineFailed = "FAILED"
+)
+
+const (
+	// defaultQuarantinePeriod is how long a released number can be restored
+	defaultQuarantinePeriod = 30 * 24 * time.Hour
+
+	// quarantineBatch is the number of releases made per worker pass
+	quarantineBatch = 100
+
+	// quarantineLease is how long a claimed release is hidden from other workers
+	quarantineLease = 5 * time.Minute
+
+	// maxQuarantineAttempts bounds upstream release retries before a
+	// quarantine is marked FAILED for manual follow-up
+	maxQuarantineAttempts = 20
+)
+
+// SetQuarantinePeriod sets how long released numbers age before they are
+// returned to SOA (0 releases immediately)
+func (s *NumberService) SetQuarantinePeriod(period time.Duration) {
+	s.quarantinePeriod = period
+}
+
+// quarantineNumber deactivates a number for the aging period. Routing stops
+// now; the upstream release, E911 and CNAM removal wait for the worker so a
+// restored number comes back unchanged.
+func (s *NumberService) quarantineNumber(ctx context.Context, number *models.AssignedNumber, reason string, releasedBy uuid.UUID) error {
+	releaseAfter := time.Now().Add(s.quarantinePeriod)
+
+	ok, err := s.repo.QuarantineNumber(ctx, number.ID, reason, releasedBy, releaseAfter)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("number already released")
+	}
+
+	s.refreshDIDRoute(ctx, number.ID, number.Number)
+	s.setQuarantineSOAStatus(ctx, number, soa.StatusQuarantine)
+
+	s.logger.Info("Number quarantined",
+		zap.String("number", number.Number),
+		zap.String("reason", reason),
+		zap.Time("release_after", releaseAfter),
+		zap.String("released_by", releasedBy.String()),
+	)
+
+	return nil
+}
+
+// RestoreNumber reactivates a quarantined number for the customer that
+// released it, with its routing, campaign, E911 and CNAM intact
+func (s *NumberService) RestoreNumber(ctx context.Context, numberID uuid.UUID, customerFilter []uuid.UUID, restoredBy uuid.UUID) (*models.AssignedNumber, error) {
+	// Verify access; the number keeps its customer while quarantined
+	number, err := s.GetNumber(ctx, numberID, customerFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	// A number ported away while quarantined is no longer ours to restore
+	if number.NumberType != "TOLL_FREE" {
+		portedOut, err := s.soaPortedOut(ctx, number.Number)
+		if err != nil {
+			return nil, err
+		}
+		if portedOut {
+			return nil, ErrNumberPortedOut
+		}
+	}
+
+	ok, err := s.repo.RestoreNumber(ctx, numberID, restoredBy)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotQuarantined
+	}
+
+	s.refreshDIDRoute(ctx, numberID, number.Number)
+	s.setQuarantineSOAStatus(ctx, number, soa.StatusInUse)
+
+	s.logger.Info("Number restored from quarantine",
+		zap.String("number", number.Number),
+		zap.String("restored_by", restoredBy.String()),
+	)
+
+	return s.repo.GetByID(ctx, numberID)
+}
+
+// ListQuarantinedNumbers lists the numbers in quarantine (nil filter = all customers)
+func (s *NumberService) ListQuarantinedNumbers(ctx context.Context, customerFilter []uuid.UUID) ([]models.NumberQuarantine, error) {
+	return s.repo.ListQuarantinedNumbers(ctx, customerFilter)
+}
+
+// StartQuarantineWorker releases numbers upstream once their aging period
+// ends, every interval, until ctx is cancelled
+func (s *NumberService) StartQuarantineWorker(ctx context.Context, interval time.Duration) {
+	worker.Every(ctx, interval, nil, func(ctx context.Context) {
+		for s.ProcessQuarantine(ctx) == quarantineBatch {
+		}
+	})
+}
+
+// ProcessQuarantine makes one release pass and returns the number of
+// quarantines claimed
+func (s *NumberService) ProcessQuarantine(ctx context.Context) int {
+	claimed, err := s.repo.ClaimQuarantineDue(ctx, time.Now().Add(quarantineLease), quarantineBatch)
+	if err != nil {
+		s.logger.Warn("Failed to claim quarantined numbers", zap.Error(err))
+		return 0
+	}
+
+	for i := range claimed {
+		s.releaseQuarantined(ctx, &claimed[i])
+	}
+	return len(claimed)
+}
+
+func (s *NumberService) releaseQuarantined(ctx context.Context, q *models.NumberQuarantine) {
+	releasedBy := uuid.Nil
+	if q.QuarantinedBy != nil {
+		releasedBy = *q.QuarantinedBy
+	}
+
+	err := s.releaseQuarantinedUpstream(ctx, q.NumberID, releasedBy)
+	if err == nil {
+		if err := s.repo.CompleteQuarantine(ctx, q.NumberID); err != nil {
+			s.logger.Error("Failed to record quarantine release", zap.String("number", q.Number), zap.Error(err))
+			return
+		}
+		s.logger.Info("Quarantined number released", zap.String("number", q.Number))
+		return
+	}
+
+	// Rejections SOA will repeat, and releases that keep failing, need a person
+	if isDefinitiveSOAError(err) || q.Attempts+1 >= maxQuarantineAttempts {
+		s.logger.Error("Quarantine release failed, giving up",
+			zap.String("number", q.Number),
+			zap.Int("attempts", q.Attempts+1),
+			zap.Error(err),
+		)
+		if err := s.repo.FailQuarantine(ctx, q.NumberID, err.Error()); err != nil {
+			s.logger.Error("Failed to record quarantine failure", zap.String("number", q.Number), zap.Error(err))
+		}
+		return
+	}
+
+	backoff := worker.Backoff(q.Attempts)
+
+	s.logger.Warn("Quarantine release failed, will retry",
+		zap.String("number", q.Number),
+		zap.Duration("backoff", backoff),
+		zap.Error(err),
+	)
+	if err := s.repo.ScheduleQuarantineRetry(ctx, q.NumberID, err.Error(), time.Now().Add(backoff)); err != nil {
+		s.logger.Error("Failed to schedule quarantine retry", zap.String("number", q.Number), zap.Error(err))
+	}
+}
+
+func (s *NumberService) releaseQuarantinedUpstream(ctx context.Context, numberID uuid.UUID, releasedBy uuid.UUID) error {
+	number, err := s.repo.GetByID(ctx, numberID)
+	if err != nil {
+		return err
+	}
+	if number == nil {
+		return ErrNumberNotFound
+	}
+
+	// A number ported away during quarantine has nothing left to release in SOA
+	portedOut := false
+	if number.NumberType != "TOLL_FREE" {
+		if portedOut, err = s.soaPortedOut(ctx, number.Number); err != nil {
+			return err
+		}
+	}
+
+	if portedOut {
+		s.logger.Info("Quarantined number was ported out, skipping SOA release", zap.String("number", number.Number))
+		s.deprovisionReleasedE911(ctx, number, releasedBy)
+	} else if err := s.releaseUpstream(ctx, number, releasedBy); err != nil {
+		return err
+	}
+
+	if number.CNAMEnabled {
+		s.queueCNAM(ctx, &models.NumberCNAMProvisioning{NumberID: number.ID, Action: CNAMActionRemove}, releasedBy)
+	}
+	return nil
+}
+
+// soaPortedOut reports whether SOA shows tn ported to another carrier. A
+// number SOA does not know is not considered ported out.
+func (s *NumberService) soaPortedOut(ctx context.Context, tn string) (bool, error) {
+	inv, err := s.soaClient.GetNumberDetails(ctx, tn)
+	if soa.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read SOA status: %w", err)
+	}
+	return inv.Status == soa.StatusPortedOut, nil
+}
+
+// setQuarantineSOAStatus mirrors a quarantine or restore into SOA. Toll-free
+// numbers are not in SOA. A failure leaves the number PENDING for the SOA sync.
+func (s *NumberService) setQuarantineSOAStatus(ctx context.Context, number *models.AssignedNumber, status soa.NumberStatus) {
+	if number.NumberType == "TOLL_FREE" {
+		return
+	}
+	if _, err := s.soaClient.SetStatus(ctx, number.Number, status); err != nil {
+		s.logger.Warn("Failed to set SOA status",
+			zap.String("number", number.Number),
+			zap.String("status", string(status)),
+			zap.Error(err),
+		)
+		_ = s.repo.UpdateSOASyncStatus(ctx, number.ID, "PENDING")
+	}
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/soa"
+)
+
+// quarantineTestNumber purchases a number for customerID and releases it
+// into quarantine
+func quarantineTestNumber(t *testing.T, s *NumberService, fake *fakeSOA, customerID uuid.UUID) *models.AssignedNumber {
+	t.Helper()
+
+	number := purchaseTestNumber(t, s, fake, customerID)
+	if err := s.ReleaseNumber(context.Background(), number.ID, &models.ReleaseNumberRequest{Reason: "test"}, nil, uuid.New()); err != nil {
+		t.Fatal(err)
+	}
+	return number
+}
+
+// quarantineStatus returns the quarantine status of a number
+func quarantineStatus(t *testing.T, pool *pgxpool.Pool, numberID uuid.UUID) string {
+	t.Helper()
+	return queryString(t, pool, `SELECT status FROM numbers.number_quarantine WHERE number_id = $1`, numberID)
+}
+
+// endQuarantine moves a number's aging period into the past and returns its
+// quarantine as the release worker would claim it
+func endQuarantine(t *testing.T, s *NumberService, pool *pgxpool.Pool, numberID uuid.UUID) *models.NumberQuarantine {
+	t.Helper()
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `
+		UPDATE numbers.number_quarantine
+		SET release_after = NOW() - interval '1 minute'
+		WHERE number_id = $1`, numberID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quarantined, err := s.ListQuarantinedNumbers(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range quarantined {
+		if quarantined[i].NumberID == numberID {
+			return &quarantined[i]
+		}
+	}
+	t.Fatalf("number %s not in quarantine", numberID)
+	return nil
+}
+
+func TestReleaseNumberQuarantine(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	ctx := context.Background()
+	customerID := testCustomer(t, pool)
+
+	number := quarantineTestNumber(t, s, fake, customerID)
+
+	released, err := s.repo.GetByID(ctx, number.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released.Active {
+		t.Error("number still active after release")
+	}
+	if status := quarantineStatus(t, pool, number.ID); status != NumberQuarantined {
+		t.Errorf("quarantine status = %s, want %s", status, NumberQuarantined)
+	}
+	if inv := fake.get(number.Number); inv.Status != soa.StatusQuarantine || fake.released(number.Number) != 0 {
+		t.Errorf("SOA status = %s after %d releases, want QUARANTINE and no release", inv.Status, fake.released(number.Number))
+	}
+
+	restored, err := s.RestoreNumber(ctx, number.ID, []uuid.UUID{customerID}, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !restored.Active || restored.CustomerID != customerID {
+		t.Errorf("restored number = %+v", restored)
+	}
+	if status := quarantineStatus(t, pool, number.ID); status != NumberRestored {
+		t.Errorf("quarantine status = %s, want %s", status, NumberRestored)
+	}
+	if inv := fake.get(number.Number); inv.Status != soa.StatusInUse {
+		t.Errorf("SOA status = %s, want IN_USE", inv.Status)
+	}
+
+	if _, err := s.RestoreNumber(ctx, number.ID, nil, uuid.New()); !errors.Is(err, ErrNotQuarantined) {
+		t.Errorf("second restore error = %v, want ErrNotQuarantined", err)
+	}
+}
+
+func TestRestoreNumberAccess(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	customerID := testCustomer(t, pool)
+
+	number := quarantineTestNumber(t, s, fake, customerID)
+
+	_, err := s.RestoreNumber(context.Background(), number.ID, []uuid.UUID{uuid.New()}, uuid.New())
+	if !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("error = %v, want ErrAccessDenied", err)
+	}
+}
+
+func TestRestoreNumberAgingEnded(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	customerID := testCustomer(t, pool)
+
+	number := quarantineTestNumber(t, s, fake, customerID)
+	endQuarantine(t, s, pool, number.ID)
+
+	if _, err := s.RestoreNumber(context.Background(), number.ID, nil, uuid.New()); !errors.Is(err, ErrNotQuarantined) {
+		t.Errorf("error = %v, want ErrNotQuarantined", err)
+	}
+}
+
+func TestRestoreNumberPortedOut(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	customerID := testCustomer(t, pool)
+
+	number := quarantineTestNumber(t, s, fake, customerID)
+	fake.set(number.Number, soa.StatusPortedOut, "")
+
+	if _, err := s.RestoreNumber(context.Background(), number.ID, nil, uuid.New()); !errors.Is(err, ErrNumberPortedOut) {
+		t.Errorf("error = %v, want ErrNumberPortedOut", err)
+	}
+	if status := quarantineStatus(t, pool, number.ID); status != NumberQuarantined {
+		t.Errorf("quarantine status = %s, want %s", status, NumberQuarantined)
+	}
+}
+
+func TestReleaseQuarantined(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	ctx := context.Background()
+	customerID := testCustomer(t, pool)
+
+	tests := []struct {
+		name         string
+		setup        func(tn string)
+		wantStatus   string
+		wantReleases int
+	}{
+		{
+			name:         "aging ended",
+			setup:        func(tn string) {},
+			wantStatus:   NumberReleased,
+			wantReleases: 1,
+		},
+		{
+			name:         "ported out",
+			setup:        func(tn string) { fake.set(tn, soa.StatusPortedOut, "") },
+			wantStatus:   NumberReleased,
+			wantReleases: 0,
+		},
+		{
+			name:         "refused by SOA",
+			setup:        func(tn string) { fake.failWith("release", tn, http.StatusConflict) },
+			wantStatus:   NumberQuarantineFailed,
+			wantReleases: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			number := quarantineTestNumber(t, s, fake, customerID)
+			q := endQuarantine(t, s, pool, number.ID)
+			tt.setup(number.Number)
+
+			s.releaseQuarantined(ctx, q)
+
+			if status := quarantineStatus(t, pool, number.ID); status != tt.wantStatus {
+				t.Errorf("quarantine status = %s, want %s", status, tt.wantStatus)
+			}
+			if got := fake.released(number.Number); got != tt.wantReleases {
+				t.Errorf("SOA releases = %d, want %d", got, tt.wantReleases)
+			}
+		})
+	}
+}
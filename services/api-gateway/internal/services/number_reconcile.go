@@ -0,0 +1,456 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/repository"
+	"github.com/ringer-warp/api-gateway/internal/soa"
+	"github.com/ringer-warp/api-gateway/internal/worker"
+	"go.uber.org/zap"
+)
+
+// ErrReconcileNotConfigured indicates no SPID is configured for inventory reconciliation
+var ErrReconcileNotConfigured = errors.New("SOA reconciliation is not configured (SOA_SPID not set)")
+
+// ErrReconcileInProgress indicates a reconciliation run is already in progress
+var ErrReconcileInProgress = errors.New("an SOA reconciliation run is already in progress")
+
+// ErrDriftNotFound indicates the drift finding does not exist or is already resolved
+var ErrDriftNotFound = errors.New("open drift finding not found")
+
+// Reconcile triggers recorded on the run
+const (
+	ReconcileTriggerManual   = "manual"
+	ReconcileTriggerPeriodic = "periodic"
+)
+
+// Drift types (see schemas/23-soa-reconciliation.sql)
+const (
+	DriftPortedOut     = "PORTED_OUT"
+	DriftNotInUse      = "NOT_IN_USE"
+	DriftOwnerMismatch = "OWNER_MISMATCH"
+	DriftLRNMismatch   = "LRN_MISMATCH"
+	DriftMissingLocal  = "MISSING_LOCAL"
+	DriftMissingInSOA  = "MISSING_IN_SOA"
+)
+
+// reconcilePageSize is the SOA inventory page size used by the reconciler
+const reconcilePageSize = 1000
+
+// SetReconcileIdentity enables inventory reconciliation for our SPID.
+// homeLRNs, when non-empty, are the LRNs our in-use numbers must route to.
+func (s *NumberService) SetReconcileIdentity(spid string, homeLRNs []string) {
+	s.spid = spid
+	s.homeLRNs = make(map[string]bool, len(homeLRNs))
+	for _, lrn := range homeLRNs {
+		if lrn = strings.TrimSpace(lrn); lrn != "" {
+			s.homeLRNs[lrn] = true
+		}
+	}
+}
+
+// StartInventoryReconciler reconciles SOA inventory every interval until ctx
+// is cancelled. It does nothing if no SPID is configured.
+func (s *NumberService) StartInventoryReconciler(ctx context.Context, interval time.Duration) {
+	if s.spid == "" {
+		return
+	}
+
+	worker.Every(ctx, interval, nil, func(ctx context.Context) {
+		if _, err := s.ReconcileInventory(ctx, ReconcileTriggerPeriodic, false); err != nil && !errors.Is(err, ErrReconcileInProgress) {
+			s.logger.Warn("Periodic SOA reconciliation failed", zap.Error(err))
+		}
+	})
+}
+
+// ReconcileInventory runs one reconciliation and returns the finished run.
+// With dryRun drift is recorded but no number is changed.
+func (s *NumberService) ReconcileInventory(ctx context.Context, trigger string, dryRun bool) (*models.SOAReconcileRun, error) {
+	run, err := s.beginReconcile(ctx, trigger, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	defer s.reconcileMu.Unlock()
+
+	err = s.runReconcile(ctx, run)
+	return run, err
+}
+
+// StartReconcile begins a reconciliation in the background and returns the
+// RUNNING run; poll ListReconcileRuns for the outcome
+func (s *NumberService) StartReconcile(ctx context.Context, trigger string, dryRun bool) (*models.SOAReconcileRun, error) {
+	run, err := s.beginReconcile(ctx, trigger, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	started := *run
+	go func() {
+		defer s.reconcileMu.Unlock()
+		s.runReconcile(context.Background(), run)
+	}()
+
+	return &started, nil
+}
+
+// ListReconcileRuns returns recent reconciliation runs, newest first
+func (s *NumberService) ListReconcileRuns(ctx context.Context, limit int) ([]models.SOAReconcileRun, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 20
+	}
+	return s.repo.ListReconcileRuns(ctx, limit)
+}
+
+// GetDriftReport returns open drift counts by type, the last run and the
+// matching findings
+func (s *NumberService) GetDriftReport(ctx context.Context, openOnly bool, driftType string, limit int) (*models.SOADriftReport, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	counts, err := s.repo.CountOpenDriftByType(ctx)
+	if err != nil {
+		return nil, err
+	}
+	findings, err := s.repo.ListDrift(ctx, openOnly, driftType, limit)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := s.repo.ListReconcileRuns(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.SOADriftReport{OpenByType: counts, Drift: findings}
+	for _, count := range counts {
+		report.OpenTotal += count
+	}
+	if len(runs) > 0 {
+		report.LastRun = &runs[0]
+	}
+	return report, nil
+}
+
+// AcknowledgeDrift closes a flagged drift finding after operator review
+func (s *NumberService) AcknowledgeDrift(ctx context.Context, id, resolvedBy uuid.UUID, note string) (*models.SOADrift, error) {
+	drift, err := s.repo.AcknowledgeDrift(ctx, id, resolvedBy, note)
+	if err != nil {
+		return nil, err
+	}
+	if drift == nil {
+		return nil, ErrDriftNotFound
+	}
+	return drift, nil
+}
+
+// beginReconcile takes the reconcile lock and records a RUNNING run. The
+// caller must unlock reconcileMu once the run finishes.
+func (s *NumberService) beginReconcile(ctx context.Context, trigger string, dryRun bool) (*models.SOAReconcileRun, error) {
+	if s.spid == "" {
+		return nil, ErrReconcileNotConfigured
+	}
+	if !s.reconcileMu.TryLock() {
+		return nil, ErrReconcileInProgress
+	}
+
+	run, err := s.repo.CreateReconcileRun(ctx, trigger, dryRun)
+	if err != nil {
+		s.reconcileMu.Unlock()
+		return nil, err
+	}
+	return run, nil
+}
+
+// runReconcile pages our SPID's SOA inventory and compares each entry with
+// the active local rows:
+//
+//   - ported away in SOA           → released locally (safe auto-fix)
+//   - any other status/owner/LRN   → flagged for review, soa_sync_status FAILED
+//   - in sync                      → soa_last_synced stamped, PENDING metadata re-pushed
+//
+// Active local numbers never seen in SOA are flagged MISSING_IN_SOA, and
+// open findings not seen again are cleared, only when every page was read.
+// A dry run records the same findings (PORTED_OUT flagged rather than
+// fixed) without touching any number.
+func (s *NumberService) runReconcile(ctx context.Context, run *models.SOAReconcileRun) error {
+	err := s.reconcileInventory(ctx, run)
+
+	now := time.Now()
+	duration := now.Sub(run.StartedAt).Milliseconds()
+	run.CompletedAt = &now
+	run.DurationMs = &duration
+	run.Status = "COMPLETED"
+	if err != nil {
+		msg := err.Error()
+		run.Status = "FAILED"
+		run.Error = &msg
+	}
+
+	if finishErr := s.repo.FinishReconcileRun(ctx, run); finishErr != nil {
+		s.logger.Error("Failed to record SOA reconcile run", zap.Error(finishErr))
+	}
+
+	fields := []zap.Field{
+		zap.String("run_id", run.ID.String()),
+		zap.String("trigger", run.Trigger),
+		zap.Bool("dry_run", run.DryRun),
+		zap.Int("soa_numbers", run.SOANumbers),
+		zap.Int("local_numbers", run.LocalNumbers),
+		zap.Int("matched", run.Matched),
+		zap.Int("auto_fixed", run.AutoFixed),
+		zap.Int("flagged", run.Flagged),
+		zap.Int64("duration_ms", duration),
+	}
+	switch {
+	case err != nil:
+		s.logger.Error("SOA reconciliation failed", append(fields, zap.Error(err))...)
+	case run.AutoFixed > 0 || run.Flagged > 0:
+		s.logger.Warn("SOA inventory drift detected", fields...)
+	default:
+		s.logger.Info("SOA inventory in sync", fields...)
+	}
+
+	return err
+}
+
+func (s *NumberService) reconcileInventory(ctx context.Context, run *models.SOAReconcileRun) error {
+	// Local first: numbers purchased during the run are absent here and so
+	// never reported MISSING_IN_SOA
+	local, err := s.repo.ListActiveNumberRefs(ctx)
+	if err != nil {
+		return err
+	}
+	run.LocalNumbers = len(local)
+
+	// Quarantined numbers are inactive locally but still held (QUARANTINE) in
+	// SOA until the aging period ends
+	quarantined, err := s.repo.ListQuarantinedTNs(ctx)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(local))
+	recentCutoff := run.StartedAt.Add(-purchaseStaleAfter)
+
+	for page := 0; ; page++ {
+		resp, err := s.soaClient.QueryInventory(ctx, &soa.QueryRequest{
+			Query:         fmt.Sprintf("spid=%s", s.spid),
+			Page:          page,
+			Size:          reconcilePageSize,
+			SortBy:        "telephoneNumber",
+			SortDirection: "ASC",
+		})
+		if err != nil {
+			return fmt.Errorf("failed to query SOA inventory page %d: %w", page, err)
+		}
+
+		verified := make([]uuid.UUID, 0, len(resp.Content))
+		for i := range resp.Content {
+			entry := &resp.Content[i]
+			run.SOANumbers++
+			seen[entry.TelephoneNumber] = true
+
+			ref, isLocal := local[entry.TelephoneNumber]
+			if !isLocal {
+				if !quarantined[entry.TelephoneNumber] {
+					s.checkUnclaimed(ctx, run, entry, recentCutoff)
+				}
+				continue
+			}
+
+			if s.checkAssigned(ctx, run, entry, ref) {
+				run.Matched++
+				verified = append(verified, ref.ID)
+				if ref.SOASyncStatus == "PENDING" && !run.DryRun {
+					s.retryMetadataSync(ctx, ref.ID)
+				}
+			}
+		}
+
+		if !run.DryRun {
+			if err := s.repo.MarkNumbersVerified(ctx, verified); err != nil {
+				s.logger.Warn("Failed to stamp verified numbers", zap.Error(err))
+			}
+		}
+
+		if resp.Last || len(resp.Content) == 0 || page+1 >= resp.TotalPages {
+			break
+		}
+	}
+
+	// Complete pass: anything active locally but not in our inventory
+	for tn, ref := range local {
+		if seen[tn] {
+			continue
+		}
+		ref := ref
+		s.flagDrift(ctx, run, &models.SOADrift{
+			Number:           tn,
+			DriftType:        DriftMissingInSOA,
+			AssignedNumberID: &ref.ID,
+			CustomerID:       &ref.CustomerID,
+			Detail:           fmt.Sprintf("active locally but not in SOA inventory for SPID %s", s.spid),
+		}, &ref)
+	}
+
+	cleared, err := s.repo.ClearStaleDrift(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+	if cleared > 0 {
+		s.logger.Info("Cleared resolved SOA drift findings", zap.Int64("count", cleared))
+	}
+
+	return nil
+}
+
+// checkAssigned compares an SOA entry with its active local row and reports
+// whether they agree
+func (s *NumberService) checkAssigned(ctx context.Context, run *models.SOAReconcileRun, entry *soa.NumberInventory, ref repository.LocalNumberRef) bool {
+	drift := soaDrift(entry)
+	drift.AssignedNumberID = &ref.ID
+	drift.CustomerID = &ref.CustomerID
+
+	switch {
+	case s.isPortedOut(entry):
+		drift.DriftType = DriftPortedOut
+		drift.Detail = fmt.Sprintf("ported out to SPID %s", entry.CurrentOwnerSPID)
+		if !run.DryRun {
+			s.releasePortedOut(ctx, run, drift, ref)
+			return false
+		}
+
+	case entry.Status != soa.StatusInUse:
+		drift.DriftType = DriftNotInUse
+		drift.Detail = fmt.Sprintf("SOA status %s but active locally", entry.Status)
+
+	case entry.ApplicationID != ref.CustomerID.String():
+		drift.DriftType = DriftOwnerMismatch
+		drift.Detail = fmt.Sprintf("SOA assigned to %q, local customer %s", entry.ApplicationID, ref.CustomerID)
+
+	case len(s.homeLRNs) > 0 && entry.CurrentLRN != "" && !s.homeLRNs[entry.CurrentLRN]:
+		drift.DriftType = DriftLRNMismatch
+		drift.Detail = fmt.Sprintf("LRN %s is not a home LRN", entry.CurrentLRN)
+
+	default:
+		return true
+	}
+
+	s.flagDrift(ctx, run, drift, &ref)
+	return false
+}
+
+// checkUnclaimed flags SOA numbers in use by one of our customers that have
+// no active local row. Numbers assigned within the purchase window are
+// skipped: the purchase saga owns those.
+func (s *NumberService) checkUnclaimed(ctx context.Context, run *models.SOAReconcileRun, entry *soa.NumberInventory, recentCutoff time.Time) {
+	if entry.Status != soa.StatusInUse || s.isPortedOut(entry) {
+		return
+	}
+	customerID, err := uuid.Parse(entry.ApplicationID)
+	if err != nil {
+		return // Not assigned through WARP
+	}
+	if entry.LastAssignedAt != nil && entry.LastAssignedAt.After(recentCutoff) {
+		return
+	}
+
+	drift := soaDrift(entry)
+	drift.DriftType = DriftMissingLocal
+	drift.CustomerID = &customerID
+	drift.Detail = "SOA IN_USE for customer but no active local number"
+	s.flagDrift(ctx, run, drift, nil)
+}
+
+// releasePortedOut applies the one safe auto-fix: a number ported away in
+// SOA is released locally
+func (s *NumberService) releasePortedOut(ctx context.Context, run *models.SOAReconcileRun, drift *models.SOADrift, ref repository.LocalNumberRef) {
+	released, err := s.repo.ReleasePortedOut(ctx, ref.ID)
+	if err != nil {
+		s.logger.Warn("Failed to release ported-out number", zap.String("number", ref.Number), zap.Error(err))
+		s.flagDrift(ctx, run, drift, nil)
+		return
+	}
+	if !released {
+		return // Released concurrently
+	}
+	s.refreshDIDRoute(ctx, ref.ID, ref.Number)
+
+	drift.Action = "AUTO_FIXED"
+	drift.LastSeenRunID = run.ID
+	if err := s.repo.RecordDrift(ctx, drift); err != nil {
+		s.logger.Warn("Failed to record drift", zap.String("number", drift.Number), zap.Error(err))
+	}
+	run.AutoFixed++
+
+	s.logger.Info("Released ported-out number",
+		zap.String("number", ref.Number),
+		zap.String("customer_id", ref.CustomerID.String()),
+		zap.String("detail", drift.Detail),
+	)
+}
+
+// flagDrift records a finding for review. With a local ref, the row is
+// re-read first so a number released during the run is not flagged, and
+// outside a dry run is marked soa_sync_status FAILED.
+func (s *NumberService) flagDrift(ctx context.Context, run *models.SOAReconcileRun, drift *models.SOADrift, ref *repository.LocalNumberRef) {
+	if ref != nil {
+		current, err := s.repo.GetByID(ctx, ref.ID)
+		if err != nil || current == nil || !current.Active {
+			return
+		}
+	}
+	if ref != nil && !run.DryRun {
+		if err := s.repo.UpdateSOASyncStatus(ctx, ref.ID, "FAILED"); err != nil {
+			s.logger.Warn("Failed to mark number sync failed", zap.String("number", ref.Number), zap.Error(err))
+		}
+	}
+
+	drift.Action = "FLAGGED"
+	drift.LastSeenRunID = run.ID
+	if err := s.repo.RecordDrift(ctx, drift); err != nil {
+		s.logger.Warn("Failed to record drift", zap.String("number", drift.Number), zap.Error(err))
+		return
+	}
+	run.Flagged++
+}
+
+// retryMetadataSync re-pushes metadata for a number whose last push to SOA failed
+func (s *NumberService) retryMetadataSync(ctx context.Context, id uuid.UUID) {
+	number, err := s.repo.GetByID(ctx, id)
+	if err != nil || number == nil {
+		return
+	}
+	if err := s.pushMetadata(ctx, number); err != nil {
+		s.logger.Debug("Metadata re-push to SOA failed", zap.String("number", number.Number), zap.Error(err))
+		return
+	}
+	_ = s.repo.UpdateSOASyncStatus(ctx, id, "SYNCED")
+}
+
+// isPortedOut reports whether SOA shows the number ported to another carrier
+func (s *NumberService) isPortedOut(entry *soa.NumberInventory) bool {
+	if entry.Status == soa.StatusPortedOut {
+		return true
+	}
+	return entry.PortDirection == soa.PortDirectionOut &&
+		entry.CurrentOwnerSPID != "" && entry.CurrentOwnerSPID != s.spid
+}
+
+// soaDrift returns a finding pre-filled with the SOA side of an entry
+func soaDrift(entry *soa.NumberInventory) *models.SOADrift {
+	status := string(entry.Status)
+	return &models.SOADrift{
+		Number:           entry.TelephoneNumber,
+		SOAStatus:        &status,
+		SOAApplicationID: strPtr(entry.ApplicationID),
+		SOALRN:           strPtr(entry.CurrentLRN),
+		SOAPortDirection: strPtr(string(entry.PortDirection)),
+	}
+}
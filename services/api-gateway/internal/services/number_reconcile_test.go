@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ringer-warp/api-gateway/internal/soa"
+)
+
+// runDrift returns the findings a run recorded for tn as "TYPE ACTION"
+func runDrift(t *testing.T, pool *pgxpool.Pool, tn string, runID uuid.UUID) string {
+	t.Helper()
+	return queryString(t, pool, `
+		SELECT COALESCE(string_agg(drift_type || ' ' || action, ','), '')
+		FROM numbers.soa_drift
+		WHERE number = $1 AND last_seen_run_id = $2`, tn, runID)
+}
+
+// The reconciler reads every active number in the test database, so runs
+// also flag numbers left behind by other tests; assertions only look at the
+// numbers each case sets up.
+func TestReconcileInventory(t *testing.T) {
+	s, fake, pool := newTestNumberService(t)
+	s.SetReconcileIdentity("TEST1", nil)
+	ctx := context.Background()
+	customerID := testCustomer(t, pool)
+
+	// local purchases a number, then lets change alter its SOA record
+	local := func(change func(tn string)) func() (string, *uuid.UUID) {
+		return func() (string, *uuid.UUID) {
+			number := purchaseTestNumber(t, s, fake, customerID)
+			change(number.Number)
+			return number.Number, &number.ID
+		}
+	}
+
+	tests := []struct {
+		name       string
+		dryRun     bool
+		setup      func() (string, *uuid.UUID)
+		wantDrift  string
+		wantActive bool
+		wantSync   string
+	}{
+		{
+			name:       "in sync",
+			setup:      local(func(tn string) {}),
+			wantActive: true,
+			wantSync:   "SYNCED",
+		},
+		{
+			name: "SOA only",
+			setup: func() (string, *uuid.UUID) {
+				tn := testTN("970")
+				fake.add(tn, soa.StatusInUse)
+				fake.set(tn, soa.StatusInUse, customerID.String())
+				return tn, nil
+			},
+			wantDrift: DriftMissingLocal + " FLAGGED",
+		},
+		{
+			name:       "DB only",
+			setup:      local(fake.remove),
+			wantDrift:  DriftMissingInSOA + " FLAGGED",
+			wantActive: true,
+			wantSync:   "FAILED",
+		},
+		{
+			name:       "status mismatch",
+			setup:      local(func(tn string) { fake.set(tn, soa.StatusReserved, customerID.String()) }),
+			wantDrift:  DriftNotInUse + " FLAGGED",
+			wantActive: true,
+			wantSync:   "FAILED",
+		},
+		{
+			name:       "owner mismatch",
+			setup:      local(func(tn string) { fake.set(tn, soa.StatusInUse, uuid.NewString()) }),
+			wantDrift:  DriftOwnerMismatch + " FLAGGED",
+			wantActive: true,
+			wantSync:   "FAILED",
+		},
+		{
+			name:      "ported out repaired",
+			setup:     local(func(tn string) { fake.set(tn, soa.StatusPortedOut, "") }),
+			wantDrift: DriftPortedOut + " AUTO_FIXED",
+			wantSync:  "SYNCED",
+		},
+		{
+			name:       "ported out dry run",
+			dryRun:     true,
+			setup:      local(func(tn string) { fake.set(tn, soa.StatusPortedOut, "") }),
+			wantDrift:  DriftPortedOut + " FLAGGED",
+			wantActive: true,
+			wantSync:   "SYNCED",
+		},
+		{
+			name:       "status mismatch dry run",
+			dryRun:     true,
+			setup:      local(func(tn string) { fake.set(tn, soa.StatusReserved, customerID.String()) }),
+			wantDrift:  DriftNotInUse + " FLAGGED",
+			wantActive: true,
+			wantSync:   "SYNCED",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tn, numberID := tt.setup()
+
+			run, err := s.ReconcileInventory(ctx, ReconcileTriggerManual, tt.dryRun)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if run.Status != "COMPLETED" || run.DryRun != tt.dryRun {
+				t.Errorf("run = %s (dry run %v), want COMPLETED (dry run %v)", run.Status, run.DryRun, tt.dryRun)
+			}
+
+			if drift := runDrift(t, pool, tn, run.ID); drift != tt.wantDrift {
+				t.Errorf("drift = %q, want %q", drift, tt.wantDrift)
+			}
+			if numberID == nil {
+				return
+			}
+
+			number, err := s.repo.GetByID(ctx, *numberID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if number.Active != tt.wantActive || number.SOASyncStatus != tt.wantSync {
+				t.Errorf("number active %v, sync %s; want active %v, sync %s", number.Active, number.SOASyncStatus, tt.wantActive, tt.wantSync)
+			}
+		})
+	}
+}
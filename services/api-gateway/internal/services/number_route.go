@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/rediskeys"
+	"github.com/ringer-warp/api-gateway/internal/trunk"
+	"github.com/ringer-warp/api-gateway/internal/worker"
+	"go.uber.org/zap"
+)
+
+// ErrRouteStoreNotConfigured indicates DID routes are not published to Redis
+var ErrRouteStoreNotConfigured = errors.New("DID route publishing is not configured")
+
+// Inbound routing published for the SIP edge:
+// did:route:{number} -> JSON publishedDIDRoute, for every active,
+// voice-enabled number (absent otherwise, and the call is rejected)
+const didRoutePrefix = "did:route:"
+
+// didRouteScanCount is the SCAN/MGET batch size used when reading routes
+const didRouteScanCount = 500
+
+// publishedDIDRoute is the document the SIP edge reads from did:route:{number}.
+// A number on a trunk is sent to the targets published at TrunkRoutingKey;
+// Destination and FailoverDestination apply when the trunk has none.
+type publishedDIDRoute struct {
+	Number              string    `json:"number"`
+	NumberID            string    `json:"number_id"`
+	CustomerBAN         string    `json:"customer_ban"`
+	RoutingType         string    `json:"routing_type,omitempty"`
+	TrunkID             string    `json:"trunk_id,omitempty"`
+	TrunkRoutingKey     string    `json:"trunk_routing_key,omitempty"` // trunk:routing:{trunk_id}
+	Destination         string    `json:"destination,omitempty"`
+	FailoverDestination string    `json:"failover_destination,omitempty"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// SetRouteStore sets the Redis client DID routes are published to
+func (s *NumberService) SetRouteStore(redisClient *redis.Client) {
+	s.redisClient = redisClient
+}
+
+// didRouteKey returns the Redis key of a number's inbound route
+func didRouteKey(number string) string {
+	return didRoutePrefix + number
+}
+
+func newPublishedDIDRoute(entry *models.DIDRouteEntry) publishedDIDRoute {
+	doc := publishedDIDRoute{
+		Number:      entry.Number,
+		NumberID:    entry.NumberID.String(),
+		CustomerBAN: entry.CustomerBAN,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	if entry.VoiceRoutingType != nil {
+		doc.RoutingType = *entry.VoiceRoutingType
+	}
+	if entry.TrunkID != nil {
+		doc.TrunkID = entry.TrunkID.String()
+		doc.TrunkRoutingKey = trunk.RoutingKey(*entry.TrunkID)
+	}
+	if entry.VoiceDestination != nil {
+		doc.Destination = *entry.VoiceDestination
+	}
+	if entry.VoiceFailoverDestination != nil {
+		doc.FailoverDestination = *entry.VoiceFailoverDestination
+	}
+	return doc
+}
+
+// PublishDIDRoute writes a number's inbound route to Redis, or removes it
+// when the number is released or not voice-enabled
+func (s *NumberService) PublishDIDRoute(ctx context.Context, numberID uuid.UUID, number string) error {
+	if s.redisClient == nil {
+		return nil
+	}
+
+	entry, err := s.repo.GetDIDRoute(ctx, numberID)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return s.removeDIDRoute(ctx, number)
+	}
+
+	data, err := json.Marshal(newPublishedDIDRoute(entry))
+	if err != nil {
+		return fmt.Errorf("failed to encode DID route: %w", err)
+	}
+	if err := s.redisClient.Set(ctx, didRouteKey(entry.Number), data, 0).Err(); err != nil { // No expiration
+		return fmt.Errorf("failed to publish DID route: %w", err)
+	}
+	return nil
+}
+
+// removeDIDRoute removes a number's published route
+func (s *NumberService) removeDIDRoute(ctx context.Context, number string) error {
+	if err := s.redisClient.Del(ctx, didRouteKey(number)).Err(); err != nil {
+		return fmt.Errorf("failed to remove DID route: %w", err)
+	}
+	return nil
+}
+
+// refreshDIDRoute republishes a number's route, logging instead of failing
+// the operation that triggered it; the route reconciler repairs misses
+func (s *NumberService) refreshDIDRoute(ctx context.Context, numberID uuid.UUID, number string) {
+	if err := s.PublishDIDRoute(ctx, numberID, number); err != nil {
+		s.logger.Warn("Failed to publish DID route to Redis", zap.String("number", number), zap.Error(err))
+	}
+}
+
+// ReconcileDIDRoutes makes the DID routes in Redis match the active,
+// voice-enabled numbers in Postgres: missing routes are added, changed routes
+// rewritten and stale routes removed, in one MULTI/EXEC transaction. With
+// dryRun the drift is reported but nothing is changed.
+//
+// Redis is read before Postgres so a route published concurrently is never
+// removed; a route removed concurrently may be re-added and is removed on
+// the next run.
+func (s *NumberService) ReconcileDIDRoutes(ctx context.Context, trigger string, dryRun bool) (*models.DIDRouteReconcileReport, error) {
+	if s.redisClient == nil {
+		return nil, ErrRouteStoreNotConfigured
+	}
+
+	s.routeMu.Lock()
+	defer s.routeMu.Unlock()
+
+	report := &models.DIDRouteReconcileReport{
+		StartedAt: time.Now().UTC(),
+		Trigger:   trigger,
+		DryRun:    dryRun,
+	}
+
+	err := s.reconcileDIDRoutes(ctx, report)
+
+	report.CompletedAt = time.Now().UTC()
+	report.DurationMs = report.CompletedAt.Sub(report.StartedAt).Milliseconds()
+	report.Drift = report.Added + report.Updated + report.Removed
+	if err != nil {
+		report.Error = err.Error()
+	}
+	s.lastRouteReport = report
+
+	fields := []zap.Field{
+		zap.String("trigger", trigger),
+		zap.Bool("dry_run", dryRun),
+		zap.Int("desired", report.Desired),
+		zap.Int("existing", report.Existing),
+		zap.Int("added", report.Added),
+		zap.Int("updated", report.Updated),
+		zap.Int("removed", report.Removed),
+		zap.Int64("duration_ms", report.DurationMs),
+	}
+	switch {
+	case err != nil:
+		s.logger.Error("DID route reconciliation failed", append(fields, zap.Error(err))...)
+	case report.Drift > 0:
+		s.logger.Warn("DID route drift detected", fields...)
+	default:
+		s.logger.Debug("DID routes in sync", fields...)
+	}
+
+	return report, err
+}
+
+// LastDIDRouteReconcileReport returns the report of the most recent route
+// reconciliation, or nil if none has run yet
+func (s *NumberService) LastDIDRouteReconcileReport() *models.DIDRouteReconcileReport {
+	s.routeMu.Lock()
+	defer s.routeMu.Unlock()
+
+	if s.lastRouteReport == nil {
+		return nil
+	}
+	report := *s.lastRouteReport
+	return &report
+}
+
+// StartDIDRouteReconciler reconciles DID routes immediately and then every
+// interval until ctx is cancelled
+func (s *NumberService) StartDIDRouteReconciler(ctx context.Context, interval time.Duration) {
+	worker.NowAndEvery(ctx, interval, nil, func(ctx context.Context) {
+		s.ReconcileDIDRoutes(ctx, ReconcileTriggerPeriodic, false)
+	})
+}
+
+// reconcileDIDRoutes fills in report and applies the diff
+func (s *NumberService) reconcileDIDRoutes(ctx context.Context, report *models.DIDRouteReconcileReport) error {
+	existing, err := s.loadDIDRoutes(ctx)
+	if err != nil {
+		return err
+	}
+	report.Existing = len(existing)
+
+	entries, err := s.repo.ListDIDRoutes(ctx)
+	if err != nil {
+		return err
+	}
+	desired := make(map[string]publishedDIDRoute, len(entries))
+	for i := range entries {
+		desired[didRouteKey(entries[i].Number)] = newPublishedDIDRoute(&entries[i])
+	}
+	report.Desired = len(desired)
+
+	diff := rediskeys.Compare(existing, desired, sameDIDRoute)
+	report.Added = len(diff.Added)
+	report.Updated = len(diff.Updated)
+	report.Unchanged = len(diff.Unchanged)
+	report.Removed = len(diff.Removed)
+	report.RemovedKeys = diff.Removed
+
+	pipe := s.redisClient.TxPipeline()
+	queued := 0
+
+	for _, key := range append(diff.Added, diff.Updated...) {
+		data, err := json.Marshal(desired[key])
+		if err != nil {
+			return fmt.Errorf("failed to encode DID route: %w", err)
+		}
+		pipe.Set(ctx, key, data, 0)
+		queued++
+	}
+	for _, key := range diff.Removed {
+		pipe.Del(ctx, key)
+		queued++
+	}
+
+	if report.DryRun || queued == 0 {
+		pipe.Discard()
+		return nil
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to apply DID route changes: %w", err)
+	}
+
+	return nil
+}
+
+// loadDIDRoutes reads every did:route:* document from Redis. A document that
+// does not decode is returned empty, so it is rewritten or removed.
+func (s *NumberService) loadDIDRoutes(ctx context.Context) (map[string]publishedDIDRoute, error) {
+	keys, err := rediskeys.Scan(ctx, s.redisClient, didRoutePrefix+"*", didRouteScanCount)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make(map[string]publishedDIDRoute, len(keys))
+	for _, batch := range rediskeys.Batches(keys, didRouteScanCount) {
+		values, err := s.redisClient.MGet(ctx, batch...).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read DID routes: %w", err)
+		}
+
+		for i, value := range values {
+			data, ok := value.(string)
+			if !ok {
+				continue // Deleted since the scan
+			}
+			var doc publishedDIDRoute
+			_ = json.Unmarshal([]byte(data), &doc)
+			routes[batch[i]] = doc
+		}
+	}
+
+	return routes, nil
+}
+
+// sameDIDRoute reports whether a stored route matches the desired one,
+// ignoring when it was written
+func sameDIDRoute(current, desired publishedDIDRoute) bool {
+	current.UpdatedAt = time.Time{}
+	desired.UpdatedAt = time.Time{}
+	return current == desired
+}
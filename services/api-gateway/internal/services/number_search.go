@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/soa"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidSearch indicates the search criteria are not usable
+var ErrInvalidSearch = errors.New("invalid number search")
+
+// Search cache types (numbers.search_cache.search_type)
+const (
+	searchCacheQuery = "QUERY"
+	searchCacheBlock = "BLOCK"
+)
+
+const (
+	// defaultSearchCacheTTL keeps cached availability short-lived: numbers
+	// found here can be taken by anyone at any moment
+	defaultSearchCacheTTL = time.Minute
+
+	// numberRatesTTL is how long list prices are kept in memory
+	numberRatesTTL = 5 * time.Minute
+
+	// maxContainsDigits and maxConsecutive bound the search criteria
+	maxContainsDigits = 10
+	maxConsecutive    = 100
+
+	// Consecutive-block searches scan up to blockSearchMaxPages pages of
+	// blockSearchPageSize numbers, in telephone number order
+	blockSearchPageSize = 500
+	blockSearchMaxPages = 4
+
+	// defaultBlockResults is the number of blocks returned when no size is given
+	defaultBlockResults = 10
+)
+
+// keypad maps letters to phone keypad digits for vanity searches
+var keypad = map[rune]byte{
+	'A': '2', 'B': '2', 'C': '2',
+	'D': '3', 'E': '3', 'F': '3',
+	'G': '4', 'H': '4', 'I': '4',
+	'J': '5', 'K': '5', 'L': '5',
+	'M': '6', 'N': '6', 'O': '6',
+	'P': '7', 'Q': '7', 'R': '7', 'S': '7',
+	'T': '8', 'U': '8', 'V': '8',
+	'W': '9', 'X': '9', 'Y': '9', 'Z': '9',
+}
+
+// SetSearchCacheTTL sets how long search results are cached (0 disables the cache)
+func (s *NumberService) SetSearchCacheTTL(ttl time.Duration) {
+	s.searchCacheTTL = ttl
+}
+
+// vanityDigits converts a contains/vanity pattern to the digits to search
+// for: digits are kept, letters mapped to the keypad, and separators dropped
+func vanityDigits(pattern string) (string, error) {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(pattern) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case keypad[r] != 0:
+			b.WriteByte(keypad[r])
+		case r == '-' || r == ' ' || r == '.':
+		default:
+			return "", fmt.Errorf("%w: contains may only use digits and letters", ErrInvalidSearch)
+		}
+	}
+
+	digits := b.String()
+	if digits == "" || len(digits) > maxContainsDigits {
+		return "", fmt.Errorf("%w: contains must be 1 to %d digits or letters", ErrInvalidSearch, maxContainsDigits)
+	}
+	return digits, nil
+}
+
+// normalizeSearch validates req and returns the SOA options it maps to
+func normalizeSearch(req *models.SearchNumbersRequest) (soa.SearchOptions, error) {
+	opts := soa.SearchOptions{
+		NPA:        req.NPA,
+		NXX:        req.NXX,
+		State:      strings.ToUpper(req.State),
+		LATA:       req.LATA,
+		RateCenter: req.RateCenter,
+		Page:       req.Page,
+		Size:       req.Size,
+	}
+
+	if req.Contains != "" {
+		digits, err := vanityDigits(req.Contains)
+		if err != nil {
+			return opts, err
+		}
+		opts.Contains = digits
+	}
+	if req.Consecutive != 0 && (req.Consecutive < 2 || req.Consecutive > maxConsecutive) {
+		return opts, fmt.Errorf("%w: consecutive must be between 2 and %d", ErrInvalidSearch, maxConsecutive)
+	}
+
+	// Default page size
+	if opts.Size == 0 {
+		opts.Size = 50
+		if req.Consecutive > 0 {
+			opts.Size = defaultBlockResults
+		}
+	}
+	return opts, nil
+}
+
+// searchCacheKey identifies normalized search criteria in numbers.search_cache
+func searchCacheKey(opts soa.SearchOptions, consecutive int) string {
+	key := strings.Join([]string{
+		opts.NPA, opts.NXX, opts.State, opts.LATA, strings.ToLower(opts.RateCenter), opts.Contains,
+		strconv.Itoa(consecutive), strconv.Itoa(opts.Page), strconv.Itoa(opts.Size),
+	}, "|")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// searchNumbers runs a search against SOA without the cache or pricing.
+// Block searches return the numbers of each block in order.
+func (s *NumberService) searchNumbers(ctx context.Context, opts soa.SearchOptions, consecutive int) ([]models.NumberSearchResult, int64, error) {
+	if consecutive > 0 {
+		return s.searchBlocks(ctx, opts, consecutive)
+	}
+
+	s.logger.Debug("Searching SOA for available numbers",
+		zap.String("npa", opts.NPA),
+		zap.String("state", opts.State),
+		zap.String("contains", opts.Contains),
+		zap.Int("page", opts.Page),
+		zap.Int("size", opts.Size),
+	)
+
+	// Query SOA API
+	soaResp, err := s.soaClient.SearchAvailableNumbers(ctx, opts)
+	if err != nil {
+		s.logger.Error("SOA search failed", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to search available numbers: %w", err)
+	}
+
+	results := make([]models.NumberSearchResult, 0, len(soaResp.Content))
+	for _, n := range soaResp.Content {
+		results = append(results, newSearchResult(n))
+	}
+	return results, soaResp.TotalElements, nil
+}
+
+// searchBlocks finds up to opts.Size non-overlapping runs of consecutive
+// available numbers by scanning SOA results in number order
+func (s *NumberService) searchBlocks(ctx context.Context, opts soa.SearchOptions, consecutive int) ([]models.NumberSearchResult, int64, error) {
+	var results []models.NumberSearchResult
+	var run []models.NumberSearchResult
+	blocks := 0
+
+	scan := opts
+	scan.Size = blockSearchPageSize
+	for page := 0; page < blockSearchMaxPages && blocks < opts.Size; page++ {
+		scan.Page = page
+		soaResp, err := s.soaClient.SearchAvailableNumbers(ctx, scan)
+		if err != nil {
+			s.logger.Error("SOA block search failed", zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to search available numbers: %w", err)
+		}
+
+		for _, n := range soaResp.Content {
+			result := newSearchResult(n)
+			if len(run) > 0 && !nextNumber(run[len(run)-1].TelephoneNumber, result.TelephoneNumber) {
+				run = run[:0]
+			}
+			run = append(run, result)
+
+			if len(run) == consecutive {
+				results = append(results, run...)
+				run = nil
+				if blocks++; blocks == opts.Size {
+					break
+				}
+			}
+		}
+
+		if len(soaResp.Content) < blockSearchPageSize {
+			break // Last page
+		}
+	}
+
+	return results, int64(blocks), nil
+}
+
+// nextNumber reports whether b directly follows a
+func nextNumber(a, b string) bool {
+	x, errA := strconv.ParseInt(strings.TrimPrefix(a, "+"), 10, 64)
+	y, errB := strconv.ParseInt(strings.TrimPrefix(b, "+"), 10, 64)
+	return errA == nil && errB == nil && y == x+1
+}
+
+func newSearchResult(n soa.NumberInventory) models.NumberSearchResult {
+	numberType := "DID"
+	if isTollFree(n.TelephoneNumber) {
+		numberType = "TOLL_FREE"
+	}
+
+	return models.NumberSearchResult{
+		TelephoneNumber: n.TelephoneNumber,
+		NPA:             n.NPA,
+		NXX:             n.NXX,
+		State:           n.State,
+		LATA:            n.LATA,
+		RateCenter:      n.Locality,
+		NumberType:      numberType,
+	}
+}
+
+// numberBlocks splits block search results into their blocks
+func numberBlocks(results []models.NumberSearchResult, consecutive int) []models.NumberBlock {
+	blocks := make([]models.NumberBlock, 0, len(results)/consecutive)
+	for start := 0; start+consecutive <= len(results); start += consecutive {
+		blocks = append(blocks, models.NumberBlock{
+			FirstNumber: results[start].TelephoneNumber,
+			LastNumber:  results[start+consecutive-1].TelephoneNumber,
+			Size:        consecutive,
+		})
+	}
+	return blocks
+}
+
+// priceResults sets each result's list price. Missing rates leave the price
+// at zero rather than failing the search.
+func (s *NumberService) priceResults(ctx context.Context, results []models.NumberSearchResult) {
+	rates, err := s.numberRates(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to load number rates, search results are unpriced", zap.Error(err))
+		return
+	}
+
+	for i := range results {
+		if rate := matchNumberRate(rates, &results[i]); rate != nil {
+			results[i].MonthlyRate = rate.MonthlyRate
+			results[i].SetupFee = rate.SetupFee
+		}
+	}
+}
+
+// numberRates returns the number rates, reloading them every numberRatesTTL
+func (s *NumberService) numberRates(ctx context.Context) ([]models.NumberRate, error) {
+	s.rateMu.Lock()
+	defer s.rateMu.Unlock()
+
+	if s.rates != nil && time.Since(s.ratesLoadedAt) < numberRatesTTL {
+		return s.rates, nil
+	}
+
+	rates, err := s.repo.ListNumberRates(ctx)
+	if err != nil {
+		if s.rates != nil {
+			return s.rates, nil // Serve stale prices over none
+		}
+		return nil, err
+	}
+	if rates == nil {
+		rates = []models.NumberRate{}
+	}
+
+	s.rates = rates
+	s.ratesLoadedAt = time.Now()
+	return rates, nil
+}
+
+// matchNumberRate picks the most specific rate for a result: rate center
+// over state over number type alone
+func matchNumberRate(rates []models.NumberRate, result *models.NumberSearchResult) *models.NumberRate {
+	var best *models.NumberRate
+	bestScore := -1
+
+	for i := range rates {
+		rate := &rates[i]
+		if rate.NumberType != result.NumberType {
+			continue
+		}
+
+		score := 0
+		if rate.State != nil {
+			if !strings.EqualFold(*rate.State, result.State) {
+				continue
+			}
+			score += 2
+		}
+		if rate.RateCenter != nil {
+			if !strings.EqualFold(*rate.RateCenter, result.RateCenter) {
+				continue
+			}
+			score += 4
+		}
+
+		if score > bestScore {
+			best, bestScore = rate, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/soa"
+	"go.uber.org/zap"
+)
+
+func TestVanityDigits(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "555", want: "555"},
+		{in: "FLOWERS", want: "3569377"},
+		{in: "flowers", want: "3569377"},
+		{in: "1-800-go.fast", want: "1800463278"},
+		{in: "PIZZA 4U", want: "7499248"},
+		{in: "0123456789", want: "0123456789"},
+
+		{in: "", wantErr: true},
+		{in: " - ", wantErr: true},
+		{in: "12345678901", wantErr: true}, // Over maxContainsDigits
+		{in: "55*", wantErr: true},
+		{in: "%25", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := vanityDigits(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidSearch) {
+				t.Errorf("vanityDigits(%q) error = %v, want ErrInvalidSearch", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("vanityDigits(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestNextNumber(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"+13035550100", "+13035550101", true},
+		{"13035550109", "13035550110", true},
+		{"+13035550199", "+13035550200", true},
+		{"+13035550100", "+13035550100", false},
+		{"+13035550100", "+13035550102", false},
+		{"+13035550101", "+13035550100", false},
+		{"+1303555010x", "+13035550101", false},
+	}
+	for _, tt := range tests {
+		if got := nextNumber(tt.a, tt.b); got != tt.want {
+			t.Errorf("nextNumber(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMatchNumberRate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	rates := []models.NumberRate{
+		{NumberType: "DID", MonthlyRate: 1.00},
+		{NumberType: "DID", State: str("CO"), MonthlyRate: 1.50},
+		{NumberType: "DID", State: str("CO"), RateCenter: str("DENVER"), MonthlyRate: 2.00},
+		{NumberType: "DID", RateCenter: str("BOULDER"), MonthlyRate: 2.50},
+		{NumberType: "TOLL_FREE", MonthlyRate: 3.00},
+	}
+
+	tests := []struct {
+		name   string
+		result models.NumberSearchResult
+		want   float64 // 0 = no rate
+	}{
+		{"rate center", models.NumberSearchResult{NumberType: "DID", State: "CO", RateCenter: "Denver"}, 2.00},
+		{"rate center without state", models.NumberSearchResult{NumberType: "DID", State: "CO", RateCenter: "BOULDER"}, 2.50},
+		{"state", models.NumberSearchResult{NumberType: "DID", State: "co", RateCenter: "PUEBLO"}, 1.50},
+		{"type only", models.NumberSearchResult{NumberType: "DID", State: "TX", RateCenter: "AUSTIN"}, 1.00},
+		{"toll-free", models.NumberSearchResult{NumberType: "TOLL_FREE"}, 3.00},
+		{"no rate", models.NumberSearchResult{NumberType: "SHORT_CODE"}, 0},
+	}
+	for _, tt := range tests {
+		rate := matchNumberRate(rates, &tt.result)
+		switch {
+		case tt.want == 0 && rate != nil:
+			t.Errorf("%s: matched %+v, want none", tt.name, *rate)
+		case tt.want != 0 && (rate == nil || rate.MonthlyRate != tt.want):
+			t.Errorf("%s: matched %+v, want monthly rate %.2f", tt.name, rate, tt.want)
+		}
+	}
+}
+
+// fakeSOASearch serves available-number queries from a sorted list of
+// numbers, a page at a time, as the SOA inventory API does
+func fakeSOASearch(t *testing.T, numbers []string) *soa.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req soa.QueryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp := soa.QueryResponse{TotalElements: int64(len(numbers)), Page: req.Page, Size: req.Size}
+		for i := req.Page * req.Size; i < len(numbers) && i < (req.Page+1)*req.Size; i++ {
+			resp.Content = append(resp.Content, soa.NumberInventory{TelephoneNumber: numbers[i]})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	return soa.NewClient(soa.Config{BaseURL: server.URL})
+}
+
+// numberRange returns count numbers starting at first
+func numberRange(first int64, count int) []string {
+	numbers := make([]string, count)
+	for i := range numbers {
+		numbers[i] = "+" + strconv.FormatInt(first+int64(i), 10)
+	}
+	return numbers
+}
+
+func TestSearchBlocks(t *testing.T) {
+	var inventory []string
+	inventory = append(inventory, numberRange(13035550100, 2)...) // Too short
+	inventory = append(inventory, numberRange(13035550200, 7)...) // One block of 3, remainder 1
+	inventory = append(inventory, numberRange(13035550300, 3)...) // Exactly one block
+
+	s := &NumberService{soaClient: fakeSOASearch(t, inventory), logger: zap.NewNop()}
+
+	results, blocks, err := s.searchBlocks(context.Background(), soa.SearchOptions{NPA: "303", Size: 10}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocks != 3 {
+		t.Errorf("blocks = %d, want 3", blocks)
+	}
+
+	got := numberBlocks(results, 3)
+	want := []models.NumberBlock{
+		{FirstNumber: "+13035550200", LastNumber: "+13035550202", Size: 3},
+		{FirstNumber: "+13035550203", LastNumber: "+13035550205", Size: 3},
+		{FirstNumber: "+13035550300", LastNumber: "+13035550302", Size: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("blocks = %+v, want %+v", got, want)
+	}
+}
+
+func TestSearchBlocksAcrossPages(t *testing.T) {
+	// A first page with no runs, then a block straddling the page boundary
+	var inventory []string
+	for i := int64(0); i < blockSearchPageSize-3; i++ {
+		inventory = append(inventory, "+"+strconv.FormatInt(13035000000+2*i, 10))
+	}
+	inventory = append(inventory, numberRange(13035590000, 5)...)
+
+	s := &NumberService{soaClient: fakeSOASearch(t, inventory), logger: zap.NewNop()}
+
+	results, blocks, err := s.searchBlocks(context.Background(), soa.SearchOptions{Size: 1}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocks != 1 || len(results) != 5 {
+		t.Fatalf("got %d blocks, %d numbers", blocks, len(results))
+	}
+	if results[0].TelephoneNumber != "+13035590000" {
+		t.Errorf("block starts at %s, want +13035590000", results[0].TelephoneNumber)
+	}
+}
+
+func TestSearchBlocksLimit(t *testing.T) {
+	s := &NumberService{soaClient: fakeSOASearch(t, numberRange(13035550000, 40)), logger: zap.NewNop()}
+
+	_, blocks, err := s.searchBlocks(context.Background(), soa.SearchOptions{Size: 2}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocks != 2 {
+		t.Errorf("blocks = %d, want the requested 2", blocks)
+	}
+}
+
+func TestSearchBlocksSOAError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"message":"bad query"}`)
+	}))
+	defer server.Close()
+
+	s := &NumberService{soaClient: soa.NewClient(soa.Config{BaseURL: server.URL}), logger: zap.NewNop()}
+	if _, _, err := s.searchBlocks(context.Background(), soa.SearchOptions{Size: 1}, 2); err == nil {
+		t.Error("searchBlocks succeeded on an SOA error")
+	}
+}
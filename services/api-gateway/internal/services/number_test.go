@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/repository"
+	"github.com/ringer-warp/api-gateway/internal/soa"
+	"go.uber.org/zap"
+)
+
+// Service tests run against the database named by TEST_DATABASE_URL, which
+// must have infrastructure/database/schemas applied. They are skipped when it
+// is not set. Each test works on its own customer and random numbers, so
+// they are safe to run against a shared database.
+
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// testCustomer creates a customer removed, with everything it owns, when the
+// test ends
+func testCustomer(t *testing.T, pool *pgxpool.Pool) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+
+	var id uuid.UUID
+	ban := fmt.Sprintf("TEST%08d", rand.Intn(1e8))
+	err := pool.QueryRow(ctx, `
+		INSERT INTO accounts.customers (ban, company_name, customer_type)
+		VALUES ($1, $2, 'POSTPAID')
+		RETURNING id`, ban, "Test "+t.Name()).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to create test customer: %v", err)
+	}
+
+	t.Cleanup(func() {
+		for _, query := range []string{
+			`DELETE FROM numbers.bulk_orders WHERE customer_id = $1`,
+			`DELETE FROM numbers.number_quarantine WHERE customer_id = $1`,
+			`DELETE FROM numbers.toll_free WHERE customer_id = $1`,
+			`DELETE FROM numbers.purchase_intents WHERE customer_id = $1`,
+			`DELETE FROM numbers.soa_drift WHERE customer_id = $1`,
+			`DELETE FROM numbers.assigned_numbers WHERE customer_id = $1`,
+			`DELETE FROM numbers.e911_addresses WHERE customer_id = $1`,
+			`DELETE FROM accounts.customers WHERE id = $1`,
+		} {
+			if _, err := pool.Exec(ctx, query, id); err != nil {
+				t.Errorf("cleanup %q: %v", query, err)
+			}
+		}
+	})
+	return id
+}
+
+// testTN returns a random E.164 number in npa
+func testTN(npa string) string {
+	return fmt.Sprintf("+1%s555%04d", npa, rand.Intn(10000))
+}
+
+// queryString returns the single text value of query
+func queryString(t *testing.T, pool *pgxpool.Pool, query string, args ...interface{}) string {
+	t.Helper()
+
+	var value string
+	if err := pool.QueryRow(context.Background(), query, args...).Scan(&value); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return value
+}
+
+// purchaseTestNumber puts a new number in the fake SOA and purchases it for
+// customerID
+func purchaseTestNumber(t *testing.T, s *NumberService, fake *fakeSOA, customerID uuid.UUID) *models.AssignedNumber {
+	t.Helper()
+
+	tn := testTN("720")
+	fake.add(tn, soa.StatusAvailable)
+	purchased, errs := s.PurchaseNumbers(context.Background(), &models.PurchaseNumberRequest{Numbers: []string{tn}}, customerID, uuid.New())
+	if len(errs) != 0 {
+		t.Fatalf("PurchaseNumbers() errors = %v", errs)
+	}
+	return &purchased[0]
+}
+
+// newTestNumberService creates a NumberService on the test database and a
+// fake SOA
+func newTestNumberService(t *testing.T) (*NumberService, *fakeSOA, *pgxpool.Pool) {
+	t.Helper()
+
+	pool := testPool(t)
+	fake := newFakeSOA(t)
+	s := NewNumberService(repository.NewNumberRepository(pool), fake.client, zap.NewNop())
+	return s, fake, pool
+}
+
+// fakeSOA is an in-memory SOA inventory. Reserve moves AVAILABLE numbers to
+// RESERVED, assign moves AVAILABLE or RESERVED numbers to IN_USE and release
+// moves them back to RESERVED, as SOA does. A query answers the whole
+// inventory on one page.
+type fakeSOA struct {
+	client *soa.Client
+
+	mu          sync.Mutex
+	numbers     map[string]*soa.NumberInventory
+	fail        map[string]int // "<op> <tn>" -> HTTP status to answer with
+	calls       map[string]int // "<op> <tn>" -> successful calls
+	delay       time.Duration  // Added to every request
+	inFlight    int
+	maxInFlight int
+}
+
+func newFakeSOA(t *testing.T) *fakeSOA {
+	t.Helper()
+
+	f := &fakeSOA{
+		numbers: make(map[string]*soa.NumberInventory),
+		fail:    make(map[string]int),
+		calls:   make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /inventory/query", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		resp := soa.QueryResponse{TotalPages: 1, First: true, Last: true}
+		for _, n := range f.numbers {
+			resp.Content = append(resp.Content, *n)
+		}
+		resp.TotalElements = int64(len(resp.Content))
+		json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("GET /inventory/numbers/{tn}", f.handle("get", nil))
+	mux.HandleFunc("POST /inventory/numbers/{tn}/reserve", f.handle("reserve", func(n *soa.NumberInventory, r *http.Request) int {
+		if n.Status != soa.StatusAvailable {
+			return http.StatusConflict
+		}
+		n.Status = soa.StatusReserved
+		return http.StatusOK
+	}))
+	mux.HandleFunc("POST /inventory/numbers/{tn}/assign", f.handle("assign", func(n *soa.NumberInventory, r *http.Request) int {
+		var req soa.AssignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return http.StatusBadRequest
+		}
+		if n.Status != soa.StatusAvailable && n.Status != soa.StatusReserved {
+			return http.StatusConflict
+		}
+		n.Status = soa.StatusInUse
+		n.ApplicationID = req.ApplicationID
+		return http.StatusOK
+	}))
+	mux.HandleFunc("POST /inventory/numbers/{tn}/release", f.handle("release", func(n *soa.NumberInventory, r *http.Request) int {
+		n.Status = soa.StatusReserved
+		n.ApplicationID = ""
+		return http.StatusOK
+	}))
+	mux.HandleFunc("PUT /inventory/numbers/{tn}/status", f.handle("status", func(n *soa.NumberInventory, r *http.Request) int {
+		var req struct {
+			Status soa.NumberStatus `json:"status"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return http.StatusBadRequest
+		}
+		n.Status = req.Status
+		return http.StatusOK
+	}))
+	mux.HandleFunc("PUT /inventory/numbers/{tn}/metadata", f.handle("metadata", nil))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	f.client = soa.NewClient(soa.Config{BaseURL: server.URL})
+	return f
+}
+
+// handle serves one inventory operation on the number in the path, answering
+// 404 for unknown numbers and the configured status for failing operations
+func (f *fakeSOA) handle(op string, apply func(n *soa.NumberInventory, r *http.Request) int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.enter()
+		defer f.leave()
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		tn := r.PathValue("tn")
+		if status := f.fail[op+" "+tn]; status != 0 {
+			http.Error(w, op+" refused", status)
+			return
+		}
+		n := f.numbers[tn]
+		if n == nil {
+			http.Error(w, "number not found", http.StatusNotFound)
+			return
+		}
+		if apply != nil {
+			if status := apply(n, r); status != http.StatusOK {
+				http.Error(w, op+" failed", status)
+				return
+			}
+		}
+		f.calls[op+" "+tn]++
+		json.NewEncoder(w).Encode(n)
+	}
+}
+
+// enter counts a request in flight and waits out the configured delay
+func (f *fakeSOA) enter() {
+	f.mu.Lock()
+	f.inFlight++
+	if f.inFlight > f.maxInFlight {
+		f.maxInFlight = f.inFlight
+	}
+	delay := f.delay
+	f.mu.Unlock()
+
+	time.Sleep(delay)
+}
+
+// leave ends a request counted by enter
+func (f *fakeSOA) leave() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.inFlight--
+}
+
+// add puts tn in the inventory with status
+func (f *fakeSOA) add(tn string, status soa.NumberStatus) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.numbers[tn] = &soa.NumberInventory{
+		ID:              "soa-" + tn,
+		TelephoneNumber: tn,
+		NPA:             tn[2:5],
+		NXX:             tn[5:8],
+		Status:          status,
+	}
+}
+
+// get returns a copy of tn's inventory record
+func (f *fakeSOA) get(tn string) soa.NumberInventory {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return *f.numbers[tn]
+}
+
+// set changes tn's status and owner directly, as another system would
+func (f *fakeSOA) set(tn string, status soa.NumberStatus, applicationID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.numbers[tn].Status = status
+	f.numbers[tn].ApplicationID = applicationID
+}
+
+// remove drops tn from the inventory
+func (f *fakeSOA) remove(tn string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.numbers, tn)
+}
+
+// failWith makes op on tn answer with an HTTP status
+func (f *fakeSOA) failWith(op, tn string, status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.fail[op+" "+tn] = status
+}
+
+// released returns the number of releases of tn
+func (f *fakeSOA) released(tn string) int {
+	return f.called("release", tn)
+}
+
+// called returns the number of successful op calls on tn
+func (f *fakeSOA) called(op, tn string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[op+" "+tn]
+}
+
+// peakInFlight returns the most requests the fake has served at once
+func (f *fakeSOA) peakInFlight() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.maxInFlight
+}
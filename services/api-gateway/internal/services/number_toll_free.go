@@ -0,0 +1,570 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/tollfree"
+	"github.com/ringer-warp/api-gateway/internal/worker"
+	"go.uber.org/zap"
+)
+
+// ErrTollFreeNotConfigured indicates no toll-free (SMS/800) provider is configured
+var ErrTollFreeNotConfigured = errors.New("toll-free provider not configured")
+
+// ErrNotTollFree indicates the number is not a toll-free number
+var ErrNotTollFree = errors.New("number is not a toll-free number")
+
+// ErrTollFreeNotFound indicates the toll-free number has no registry record
+var ErrTollFreeNotFound = errors.New("toll-free record not found")
+
+// ErrTollFreeNotReserved indicates the number is not reserved for the customer
+var ErrTollFreeNotReserved = errors.New("toll-free number is not reserved for this customer")
+
+// ErrTollFreeNotActive indicates the toll-free number is not provisioned
+var ErrTollFreeNotActive = errors.New("toll-free number is not active")
+
+// ErrTollFreeVerificationInProgress indicates messaging verification is PENDING or VERIFIED
+var ErrTollFreeVerificationInProgress = errors.New("messaging verification is already pending or verified")
+
+// ErrInvalidRespOrg indicates the RespOrg ID is not one of ours
+var ErrInvalidRespOrg = errors.New("RespOrg ID is not configured for this platform")
+
+// ErrUseTollFreeFlow indicates a toll-free number was sent to the DID purchase path
+var ErrUseTollFreeFlow = errors.New("toll-free numbers are reserved and provisioned through /numbers/toll-free")
+
+// Toll-free record statuses (see schemas/29-toll-free-numbers.sql)
+const (
+	TollFreeReserved = "RESERVED"
+	TollFreeActive   = "ACTIVE"
+	TollFreeReleased = "RELEASED"
+	TollFreeExpired  = "EXPIRED"
+
+	// Reservation outcome that is not a record status
+	TollFreeRejected = "REJECTED"
+
+	TollFreeVerificationNotSubmitted = "NOT_SUBMITTED"
+)
+
+const (
+	// maxTollFreeContainsDigits is the line after the 3-digit prefix
+	maxTollFreeContainsDigits = 7
+
+	defaultTollFreeSearchQuantity = 10
+	maxTollFreeSearchQuantity     = 100
+
+	// tollFreeVerificationBatch is the number of PENDING verifications
+	// checked per worker pass
+	tollFreeVerificationBatch = 100
+)
+
+// tollFreeNPAs are the toll-free area codes
+var tollFreeNPAs = map[string]bool{
+	"800": true, "833": true, "844": true,
+	"855": true, "866": true, "877": true, "888": true,
+}
+
+// SetTollFreeProvider sets the SMS/800 provider toll-free numbers are managed
+// through. respOrgIDs are our RespOrg IDs; the first is used for new numbers.
+func (s *NumberService) SetTollFreeProvider(provider tollfree.Provider, respOrgIDs []string) {
+	s.tollFreeProvider = provider
+	s.respOrgIDs = respOrgIDs
+}
+
+// defaultRespOrg returns the RespOrg new toll-free numbers are reserved under
+func (s *NumberService) defaultRespOrg() string {
+	if len(s.respOrgIDs) == 0 {
+		return ""
+	}
+	return s.respOrgIDs[0]
+}
+
+// ownRespOrg reports whether respOrgID is one of ours
+func (s *NumberService) ownRespOrg(respOrgID string) bool {
+	for _, id := range s.respOrgIDs {
+		if id == respOrgID {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchTollFreeNumbers searches the registry for spare toll-free numbers,
+// priced like local search results
+func (s *NumberService) SearchTollFreeNumbers(ctx context.Context, req *models.SearchTollFreeRequest) ([]models.NumberSearchResult, error) {
+	if s.tollFreeProvider == nil {
+		return nil, ErrTollFreeNotConfigured
+	}
+
+	search := tollfree.SearchRequest{Prefix: req.Prefix, Quantity: req.Quantity}
+	if search.Prefix != "" && !tollFreeNPAs[search.Prefix] {
+		return nil, fmt.Errorf("%w: prefix must be a toll-free area code", ErrInvalidSearch)
+	}
+	if req.Contains != "" {
+		digits, err := vanityDigits(req.Contains)
+		if err != nil {
+			return nil, err
+		}
+		if len(digits) > maxTollFreeContainsDigits {
+			return nil, fmt.Errorf("%w: contains must be at most %d digits or letters", ErrInvalidSearch, maxTollFreeContainsDigits)
+		}
+		search.Contains = digits
+	}
+	if search.Quantity == 0 {
+		search.Quantity = defaultTollFreeSearchQuantity
+	}
+	if search.Quantity < 1 || search.Quantity > maxTollFreeSearchQuantity {
+		return nil, fmt.Errorf("%w: quantity must be between 1 and %d", ErrInvalidSearch, maxTollFreeSearchQuantity)
+	}
+
+	numbers, err := s.tollFreeProvider.SearchNumbers(ctx, search)
+	if err != nil {
+		s.logger.Error("Toll-free search failed", zap.Error(err))
+		return nil, fmt.Errorf("failed to search toll-free numbers: %w", err)
+	}
+
+	results := make([]models.NumberSearchResult, 0, len(numbers))
+	for _, tn := range numbers {
+		result := models.NumberSearchResult{TelephoneNumber: tn, NumberType: "TOLL_FREE"}
+		if len(tn) == 12 {
+			result.NPA = tn[2:5]
+			result.NXX = tn[5:8]
+		}
+		results = append(results, result)
+	}
+
+	s.priceResults(ctx, results)
+	return results, nil
+}
+
+// ReserveTollFreeNumbers reserves spare toll-free numbers in the registry for
+// a customer. Each number is reserved on its own; reserving a number the
+// customer already holds is a no-op.
+func (s *NumberService) ReserveTollFreeNumbers(
+	ctx context.Context,
+	req *models.ReserveTollFreeRequest,
+	customerID uuid.UUID,
+	createdBy uuid.UUID,
+) ([]models.TollFreeReservationResult, error) {
+	if s.tollFreeProvider == nil {
+		return nil, ErrTollFreeNotConfigured
+	}
+
+	numbers := uniqueNumbers(req.Numbers)
+	results := make([]models.TollFreeReservationResult, 0, len(numbers))
+	for _, tn := range numbers {
+		result := models.TollFreeReservationResult{Number: tn, Status: TollFreeReserved}
+		tf, err := s.reserveTollFree(ctx, tn, customerID, createdBy)
+		if err != nil {
+			result.Status = TollFreeRejected
+			result.Error = err.Error()
+		} else {
+			result.ReservedUntil = tf.ReservedUntil
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (s *NumberService) reserveTollFree(ctx context.Context, tn string, customerID, createdBy uuid.UUID) (*models.TollFreeNumber, error) {
+	if len(tn) != 12 || !isTollFree(tn) {
+		return nil, ErrNotTollFree
+	}
+
+	existing, err := s.repo.GetTollFreeByNumber(ctx, tn)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		switch {
+		case existing.Status == TollFreeReserved && existing.CustomerID == customerID:
+			return existing, nil
+		case existing.Status == TollFreeActive,
+			existing.Status == TollFreeReserved && existing.ReservedUntil != nil && existing.ReservedUntil.After(time.Now()):
+			return nil, tollfree.ErrNumberUnavailable
+		}
+	}
+
+	status, err := s.tollFreeProvider.ReserveNumber(ctx, tn, s.defaultRespOrg())
+	if err != nil {
+		return nil, err
+	}
+
+	provider := s.tollFreeProvider.Name()
+	tf, err := s.repo.ReserveTollFree(ctx, &models.TollFreeNumber{
+		CustomerID:     customerID,
+		Number:         tn,
+		Provider:       &provider,
+		RespOrgID:      &status.RespOrgID,
+		RegistryStatus: &status.Status,
+		ReservedUntil:  status.ReservedUntil,
+		CreatedBy:      &createdBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil {
+		return nil, tollfree.ErrNumberUnavailable // Activated concurrently
+	}
+
+	s.logger.Info("Toll-free number reserved",
+		zap.String("number", tn),
+		zap.String("customer_id", customerID.String()),
+	)
+	return tf, nil
+}
+
+// ListTollFreeNumbers retrieves a customer's toll-free records, optionally by status
+func (s *NumberService) ListTollFreeNumbers(ctx context.Context, customerID uuid.UUID, status string) ([]models.TollFreeNumber, error) {
+	return s.repo.ListTollFree(ctx, customerID, status)
+}
+
+// ProvisionTollFreeNumbers activates reserved toll-free numbers: each is
+// moved to WORKING in the registry and assigned to the customer.
+// Provisioning is idempotent in the registry, so a number that failed after
+// the registry step is completed by provisioning it again.
+func (s *NumberService) ProvisionTollFreeNumbers(
+	ctx context.Context,
+	req *models.ProvisionTollFreeRequest,
+	customerID uuid.UUID,
+	createdBy uuid.UUID,
+) ([]models.AssignedNumber, []error) {
+	if s.tollFreeProvider == nil {
+		return nil, []error{ErrTollFreeNotConfigured}
+	}
+
+	provisioned := make([]models.AssignedNumber, 0, len(req.Numbers))
+	errs := make([]error, 0)
+
+	for _, tn := range uniqueNumbers(req.Numbers) {
+		created, err := s.provisionTollFree(ctx, tn, req, customerID, createdBy)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tn, err))
+			continue
+		}
+
+		s.logger.Info("Toll-free number provisioned and assigned",
+			zap.String("number", tn),
+			zap.String("customer_id", customerID.String()),
+			zap.String("local_id", created.ID.String()),
+		)
+
+		provisioned = append(provisioned, *created)
+	}
+
+	return provisioned, errs
+}
+
+func (s *NumberService) provisionTollFree(
+	ctx context.Context,
+	tn string,
+	req *models.ProvisionTollFreeRequest,
+	customerID uuid.UUID,
+	createdBy uuid.UUID,
+) (*models.AssignedNumber, error) {
+	tf, err := s.repo.GetTollFreeByNumber(ctx, tn)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil || tf.CustomerID != customerID || tf.Status != TollFreeReserved {
+		return nil, ErrTollFreeNotReserved
+	}
+
+	existing, err := s.repo.GetByNumber(ctx, tn)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Active && existing.CustomerID != customerID {
+		return nil, fmt.Errorf("number is active on another customer")
+	}
+
+	respOrgID := s.defaultRespOrg()
+	if tf.RespOrgID != nil {
+		respOrgID = *tf.RespOrgID
+	}
+	status, err := s.tollFreeProvider.ProvisionNumber(ctx, tn, respOrgID)
+	if err != nil {
+		s.recordTollFreeError(ctx, tf, err)
+		return nil, fmt.Errorf("failed to provision in registry: %w", err)
+	}
+
+	// A previous attempt may have created the number before failing; a
+	// released row from an earlier assignment is reused. Toll-free numbers
+	// are exempt from the E911 voice policy (see e911Exempt).
+	number := existing
+	if number == nil || !number.Active {
+		number, err = s.createAssignedNumber(ctx, existing, &models.AssignedNumber{
+			CustomerID:    customerID,
+			Number:        tn,
+			SOASyncStatus: "SYNCED",
+			NumberType:    "TOLL_FREE",
+			NPA:           strPtr(tn[2:5]),
+			NXX:           strPtr(tn[5:8]),
+			VoiceEnabled:  req.VoiceEnabled,
+			SMSEnabled:    req.SMSEnabled,
+			TrunkID:       req.TrunkID,
+			Active:        true,
+			ActivatedAt:   time.Now(),
+			CreatedBy:     &createdBy,
+		})
+		if err != nil {
+			s.recordTollFreeError(ctx, tf, err)
+			return nil, err
+		}
+	}
+
+	if err := s.repo.ActivateTollFree(ctx, tf.ID, number.ID, status.RespOrgID, status.Status, createdBy); err != nil {
+		return nil, err
+	}
+
+	s.refreshDIDRoute(ctx, number.ID, number.Number)
+	return number, nil
+}
+
+func (s *NumberService) recordTollFreeError(ctx context.Context, tf *models.TollFreeNumber, cause error) {
+	if err := s.repo.RecordTollFreeError(ctx, tf.ID, cause.Error()); err != nil {
+		s.logger.Warn("Failed to record toll-free error", zap.String("number", tf.Number), zap.Error(err))
+	}
+}
+
+// GetNumberTollFree retrieves an assigned toll-free number's registry record
+func (s *NumberService) GetNumberTollFree(ctx context.Context, numberID uuid.UUID, customerFilter []uuid.UUID) (*models.TollFreeNumber, error) {
+	number, err := s.GetNumber(ctx, numberID, customerFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	tf, err := s.repo.GetTollFreeByNumberID(ctx, numberID)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil {
+		if number.NumberType != "TOLL_FREE" {
+			return nil, ErrNotTollFree
+		}
+		return nil, ErrTollFreeNotFound
+	}
+	return tf, nil
+}
+
+// SubmitTollFreeVerification submits an active toll-free number for
+// messaging verification. A REJECTED number may be resubmitted.
+func (s *NumberService) SubmitTollFreeVerification(
+	ctx context.Context,
+	numberID uuid.UUID,
+	req *models.TollFreeVerificationRequest,
+	customerFilter []uuid.UUID,
+	updatedBy uuid.UUID,
+) (*models.TollFreeNumber, error) {
+	if s.tollFreeProvider == nil {
+		return nil, ErrTollFreeNotConfigured
+	}
+
+	tf, err := s.GetNumberTollFree(ctx, numberID, customerFilter)
+	if err != nil {
+		return nil, err
+	}
+	if tf.Status != TollFreeActive {
+		return nil, ErrTollFreeNotActive
+	}
+	if tf.MessagingVerificationStatus == tollfree.VerificationPending || tf.MessagingVerificationStatus == tollfree.VerificationVerified {
+		return nil, ErrTollFreeVerificationInProgress
+	}
+
+	verificationID, err := s.tollFreeProvider.SubmitVerification(ctx, tf.Number, tollfree.Verification{
+		BusinessName:     req.BusinessName,
+		BusinessWebsite:  req.BusinessWebsite,
+		ContactName:      req.ContactName,
+		ContactEmail:     req.ContactEmail,
+		ContactPhone:     req.ContactPhone,
+		UseCase:          req.UseCase,
+		UseCaseSummary:   req.UseCaseSummary,
+		SampleMessage:    req.SampleMessage,
+		OptInDescription: req.OptInDescription,
+		MonthlyVolume:    req.MonthlyVolume,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit messaging verification: %w", err)
+	}
+
+	s.logger.Info("Toll-free messaging verification submitted",
+		zap.String("number", tf.Number),
+		zap.String("verification_id", verificationID),
+	)
+	return s.repo.SubmitTollFreeVerification(ctx, tf.ID, verificationID, req, updatedBy)
+}
+
+// SyncTollFreeRegistry refreshes a toll-free number's registry status and
+// controlling RespOrg from the provider
+func (s *NumberService) SyncTollFreeRegistry(ctx context.Context, numberID uuid.UUID, customerFilter []uuid.UUID, updatedBy uuid.UUID) (*models.TollFreeNumber, error) {
+	if s.tollFreeProvider == nil {
+		return nil, ErrTollFreeNotConfigured
+	}
+
+	tf, err := s.GetNumberTollFree(ctx, numberID, customerFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := s.tollFreeProvider.GetNumber(ctx, tf.Number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry: %w", err)
+	}
+
+	updated, err := s.repo.UpdateTollFreeRegistry(ctx, tf.ID, status.RespOrgID, status.Status, &updatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Moving to another RespOrg is how toll-free numbers leave us: release the
+	// number so it stops routing to the customer
+	if tf.Status == TollFreeActive && !s.ownRespOrg(status.RespOrgID) {
+		s.logger.Warn("Active toll-free number is controlled by another RespOrg, releasing",
+			zap.String("number", tf.Number),
+			zap.String("resp_org_id", status.RespOrgID),
+			zap.String("registry_status", status.Status),
+		)
+		if err := s.releaseTollFreePortedOut(ctx, numberID, tf.Number, status.Status, updatedBy); err != nil {
+			return nil, err
+		}
+		return s.repo.GetTollFreeByNumberID(ctx, numberID)
+	}
+
+	return updated, nil
+}
+
+// releaseTollFreePortedOut releases a toll-free number another RespOrg has
+// taken and removes its route
+func (s *NumberService) releaseTollFreePortedOut(ctx context.Context, numberID uuid.UUID, tn, registryStatus string, updatedBy uuid.UUID) error {
+	if _, err := s.repo.ReleasePortedOut(ctx, numberID); err != nil {
+		return err
+	}
+	if err := s.repo.ReleaseTollFree(ctx, numberID, registryStatus, updatedBy); err != nil {
+		return err
+	}
+	s.refreshDIDRoute(ctx, numberID, tn)
+	return nil
+}
+
+// ChangeTollFreeRespOrg moves an active toll-free number to another of our RespOrg IDs
+func (s *NumberService) ChangeTollFreeRespOrg(ctx context.Context, numberID uuid.UUID, respOrgID string, updatedBy uuid.UUID) (*models.TollFreeNumber, error) {
+	if s.tollFreeProvider == nil {
+		return nil, ErrTollFreeNotConfigured
+	}
+	if !s.ownRespOrg(respOrgID) {
+		return nil, ErrInvalidRespOrg
+	}
+
+	tf, err := s.GetNumberTollFree(ctx, numberID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if tf.Status != TollFreeActive {
+		return nil, ErrTollFreeNotActive
+	}
+
+	status, err := s.tollFreeProvider.ChangeRespOrg(ctx, tf.Number, respOrgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to change RespOrg: %w", err)
+	}
+
+	s.logger.Info("Toll-free RespOrg changed",
+		zap.String("number", tf.Number),
+		zap.String("resp_org_id", status.RespOrgID),
+	)
+	return s.repo.UpdateTollFreeRegistry(ctx, tf.ID, status.RespOrgID, status.Status, &updatedBy)
+}
+
+// StartTollFreeWorker expires lapsed reservations and checks PENDING
+// messaging verifications every interval until ctx is cancelled
+func (s *NumberService) StartTollFreeWorker(ctx context.Context, interval time.Duration) {
+	worker.Every(ctx, interval, nil, s.ProcessTollFree)
+}
+
+// ProcessTollFree makes one toll-free maintenance pass
+func (s *NumberService) ProcessTollFree(ctx context.Context) {
+	if s.tollFreeProvider == nil {
+		return
+	}
+
+	if expired, err := s.repo.ExpireTollFreeReservations(ctx); err != nil {
+		s.logger.Warn("Failed to expire toll-free reservations", zap.Error(err))
+	} else if expired > 0 {
+		s.logger.Info("Expired toll-free reservations", zap.Int64("count", expired))
+	}
+
+	pending, err := s.repo.ListPendingTollFreeVerifications(ctx, tollFreeVerificationBatch)
+	if err != nil {
+		s.logger.Warn("Failed to list pending messaging verifications", zap.Error(err))
+		return
+	}
+	for i := range pending {
+		s.checkTollFreeVerification(ctx, &pending[i])
+	}
+}
+
+func (s *NumberService) checkTollFreeVerification(ctx context.Context, tf *models.TollFreeNumber) {
+	if tf.MessagingVerificationID == nil {
+		return
+	}
+
+	v, err := s.tollFreeProvider.GetVerification(ctx, *tf.MessagingVerificationID)
+	if err != nil {
+		s.logger.Warn("Failed to check messaging verification", zap.String("number", tf.Number), zap.Error(err))
+		return
+	}
+
+	var reason *string
+	if v.RejectionReason != "" {
+		reason = &v.RejectionReason
+	}
+	if err := s.repo.UpdateTollFreeVerification(ctx, tf.ID, *tf.MessagingVerificationID, v.Status, reason); err != nil {
+		s.logger.Warn("Failed to record messaging verification", zap.String("number", tf.Number), zap.Error(err))
+		return
+	}
+
+	if v.Status != tollfree.VerificationPending {
+		s.logger.Info("Toll-free messaging verification completed",
+			zap.String("number", tf.Number),
+			zap.String("status", v.Status),
+		)
+	}
+}
+
+// releaseTollFree disconnects a registry-managed toll-free number during
+// release. It reports false for numbers not provisioned through the
+// registry, which are released in SOA instead.
+func (s *NumberService) releaseTollFree(ctx context.Context, number *models.AssignedNumber, releasedBy uuid.UUID) (bool, error) {
+	if number.NumberType != "TOLL_FREE" {
+		return false, nil
+	}
+
+	tf, err := s.repo.GetTollFreeByNumberID(ctx, number.ID)
+	if err != nil {
+		return false, err
+	}
+	if tf == nil || tf.Status != TollFreeActive {
+		return false, nil
+	}
+	if s.tollFreeProvider == nil {
+		return true, ErrTollFreeNotConfigured
+	}
+
+	if err := s.tollFreeProvider.ReleaseNumber(ctx, number.Number); err != nil {
+		s.logger.Error("Failed to release toll-free number in registry",
+			zap.String("number", number.Number),
+			zap.Error(err),
+		)
+		return true, fmt.Errorf("failed to release in registry: %w", err)
+	}
+
+	if err := s.repo.ReleaseTollFree(ctx, number.ID, tollfree.StatusDisconnect, releasedBy); err != nil {
+		s.logger.Warn("Failed to mark toll-free record released", zap.String("number", number.Number), zap.Error(err))
+	}
+	return true, nil
+}
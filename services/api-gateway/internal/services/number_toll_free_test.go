@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/tollfree"
+	"go.uber.org/zap"
+)
+
+// testRespOrg is the RespOrg ID toll-free tests run under
+const testRespOrg = "TST01"
+
+// newTestTollFreeService creates a NumberService on the test database with a
+// fake SMS/800 registry
+func newTestTollFreeService(t *testing.T) (*NumberService, *tollfree.FakeProvider, uuid.UUID) {
+	t.Helper()
+
+	s, _, pool := newTestNumberService(t)
+	provider := tollfree.NewFakeProvider()
+	s.SetTollFreeProvider(provider, []string{testRespOrg})
+	s.SetQuarantinePeriod(0)
+	return s, provider, testCustomer(t, pool)
+}
+
+// testTollFreeTN returns a random 833 number
+func testTollFreeTN() string {
+	return fmt.Sprintf("+1833%07d", rand.Intn(1e7))
+}
+
+// reserveAndProvision takes tn through the toll-free flow for customerID
+func reserveAndProvision(t *testing.T, s *NumberService, tn string, customerID uuid.UUID) *models.AssignedNumber {
+	t.Helper()
+	ctx := context.Background()
+
+	results, err := s.ReserveTollFreeNumbers(ctx, &models.ReserveTollFreeRequest{Numbers: []string{tn}}, customerID, uuid.New())
+	if err != nil || len(results) != 1 || results[0].Status != TollFreeReserved {
+		t.Fatalf("ReserveTollFreeNumbers() = %+v, %v", results, err)
+	}
+
+	provisioned, errs := s.ProvisionTollFreeNumbers(ctx, &models.ProvisionTollFreeRequest{Numbers: []string{tn}, VoiceEnabled: true}, customerID, uuid.New())
+	if len(errs) != 0 || len(provisioned) != 1 {
+		t.Fatalf("ProvisionTollFreeNumbers() = %d provisioned, errors %v", len(provisioned), errs)
+	}
+	return &provisioned[0]
+}
+
+func TestProvisionTollFreeNumbers(t *testing.T) {
+	s, provider, customerID := newTestTollFreeService(t)
+	ctx := context.Background()
+
+	tn := testTollFreeTN()
+	number := reserveAndProvision(t, s, tn, customerID)
+	if number.NumberType != "TOLL_FREE" || !number.Active || number.CustomerID != customerID {
+		t.Errorf("provisioned number = %+v", number)
+	}
+
+	tf, err := s.repo.GetTollFreeByNumber(ctx, tn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tf.Status != TollFreeActive || tf.NumberID == nil || *tf.NumberID != number.ID {
+		t.Errorf("toll-free record = %s for %v, want ACTIVE for %s", tf.Status, tf.NumberID, number.ID)
+	}
+	if status, _ := provider.GetNumber(ctx, tn); status.Status != tollfree.StatusWorking || status.RespOrgID != testRespOrg {
+		t.Errorf("registry = %s under %s, want WORKING under %s", status.Status, status.RespOrgID, testRespOrg)
+	}
+
+	// Releasing returns the number to the registry
+	if err := s.ReleaseNumber(ctx, number.ID, &models.ReleaseNumberRequest{Reason: "test"}, nil, uuid.New()); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := provider.GetNumber(ctx, tn); status.Status != tollfree.StatusSpare {
+		t.Errorf("registry after release = %s, want SPARE", status.Status)
+	}
+	if tf, _ := s.repo.GetTollFreeByNumber(ctx, tn); tf.Status != TollFreeReleased {
+		t.Errorf("toll-free record after release = %s, want RELEASED", tf.Status)
+	}
+
+	// Provisioning it again reuses the released row
+	again := reserveAndProvision(t, s, tn, customerID)
+	if again.ID != number.ID || !again.Active {
+		t.Errorf("reprovisioned number = %s (active %v), want %s reused", again.ID, again.Active, number.ID)
+	}
+}
+
+func TestProvisionTollFreeNumbersNotReserved(t *testing.T) {
+	s, _, customerID := newTestTollFreeService(t)
+
+	_, errs := s.ProvisionTollFreeNumbers(context.Background(), &models.ProvisionTollFreeRequest{Numbers: []string{testTollFreeTN()}}, customerID, uuid.New())
+	if len(errs) != 1 || !errors.Is(errs[0], ErrTollFreeNotReserved) {
+		t.Errorf("errors = %v, want ErrTollFreeNotReserved", errs)
+	}
+}
+
+func TestProvisionTollFreeNumbersRegistryFailure(t *testing.T) {
+	s, provider, customerID := newTestTollFreeService(t)
+	ctx := context.Background()
+
+	tn := testTollFreeTN()
+	if _, err := s.ReserveTollFreeNumbers(ctx, &models.ReserveTollFreeRequest{Numbers: []string{tn}}, customerID, uuid.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	provider.FailProvision = errors.New("registry unavailable")
+	_, errs := s.ProvisionTollFreeNumbers(ctx, &models.ProvisionTollFreeRequest{Numbers: []string{tn}}, customerID, uuid.New())
+	if len(errs) != 1 {
+		t.Fatalf("errors = %v, want one", errs)
+	}
+
+	tf, err := s.repo.GetTollFreeByNumber(ctx, tn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tf.Status != TollFreeReserved || tf.LastError == nil {
+		t.Errorf("toll-free record = %s with error %v, want RESERVED with the registry error", tf.Status, tf.LastError)
+	}
+	if local, err := s.repo.GetByNumber(ctx, tn); err != nil || local != nil {
+		t.Errorf("local number = %+v, %v; want none", local, err)
+	}
+
+	// A later attempt completes it
+	provider.FailProvision = nil
+	provisioned, errs := s.ProvisionTollFreeNumbers(ctx, &models.ProvisionTollFreeRequest{Numbers: []string{tn}}, customerID, uuid.New())
+	if len(errs) != 0 || len(provisioned) != 1 {
+		t.Errorf("retry = %d provisioned, errors %v", len(provisioned), errs)
+	}
+}
+
+func TestSyncTollFreeRegistryRespOrgChange(t *testing.T) {
+	s, provider, customerID := newTestTollFreeService(t)
+	ctx := context.Background()
+
+	tn := testTollFreeTN()
+	number := reserveAndProvision(t, s, tn, customerID)
+
+	// Another RespOrg takes the number
+	if _, err := provider.ChangeRespOrg(ctx, tn, "OTH01"); err != nil {
+		t.Fatal(err)
+	}
+
+	tf, err := s.SyncTollFreeRegistry(ctx, number.ID, nil, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tf.Status != TollFreeReleased {
+		t.Errorf("toll-free record = %s, want RELEASED", tf.Status)
+	}
+	if local, _ := s.repo.GetByID(ctx, number.ID); local.Active {
+		t.Error("number still active after its RespOrg changed")
+	}
+}
+
+func TestPurchaseNumbersTollFree(t *testing.T) {
+	s := &NumberService{tollFreeProvider: tollfree.NewFakeProvider(), logger: zap.NewNop()}
+
+	_, errs := s.PurchaseNumbers(context.Background(), &models.PurchaseNumberRequest{Numbers: []string{"+18332000000"}}, uuid.New(), uuid.New())
+	if len(errs) != 1 || !errors.Is(errs[0], ErrUseTollFreeFlow) {
+		t.Errorf("errors = %v, want ErrUseTollFreeFlow", errs)
+	}
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/repository"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrSMPPBindNotFound indicates the SMPP credential was not found
+var ErrSMPPBindNotFound = errors.New("SMPP bind not found")
+
+// ErrSystemIDTaken indicates the requested system_id belongs to another credential
+var ErrSystemIDTaken = errors.New("system_id already in use")
+
+// ErrInvalidAllowedIP indicates an allowed_ips entry is not a valid IP or CIDR
+var ErrInvalidAllowedIP = errors.New("invalid IP or CIDR in allowed_ips")
+
+// SMPP v3.4 limits system_id to 15 characters and password to 8 (C-Octet Strings of 16 and 9)
+const (
+	smppSystemIDMaxLen = 15
+	smppPasswordLen    = 8
+	smppPasswordChars  = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789"
+)
+
+// Defaults applied when a create request omits limits (match the table defaults)
+const (
+	defaultSMPPMaxBinds   = 2
+	defaultSMPPThroughput = 100
+)
+
+// SMPPBindService manages customer SMPP system_ids. Passwords are generated
+// server-side, stored as bcrypt hashes and returned only on create or rotate.
+type SMPPBindService struct {
+	repo         *repository.SMPPBindRepository
+	customerRepo *repository.CustomerRepository
+	logger       *zap.Logger
+}
+
+// NewSMPPBindService creates a new SMPPBindService instance
+func NewSMPPBindService(
+	repo *repository.SMPPBindRepository,
+	customerRepo *repository.CustomerRepository,
+	logger *zap.Logger,
+) *SMPPBindService {
+	return &SMPPBindService{
+		repo:         repo,
+		customerRepo: customerRepo,
+		logger:       logger,
+	}
+}
+
+// CreateBind creates a system_id for a customer and returns it with its initial password
+func (s *SMPPBindService) CreateBind(ctx context.Context, customerID uuid.UUID, req models.CreateSMPPBindRequest, createdBy uuid.UUID) (*models.SMPPBindWithPassword, error) {
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+	if !strings.EqualFold(customer.Status, "active") {
+		return nil, fmt.Errorf("customer is not active: %s", customer.Status)
+	}
+
+	allowedIPs, err := normalizeAllowedIPs(req.AllowedIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	systemID := req.SystemID
+	if systemID == "" {
+		systemID, err = s.generateSystemID(ctx, customer.BAN)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		taken, err := s.repo.SystemIDExists(ctx, systemID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrSystemIDTaken
+		}
+	}
+
+	password, hash, err := generateSMPPPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	bind := &models.SMPPBind{
+		ID:         uuid.New(),
+		CustomerID: customerID,
+		SystemID:   systemID,
+		AllowedIPs: allowedIPs,
+		MaxBinds:   defaultSMPPMaxBinds,
+		Throughput: defaultSMPPThroughput,
+		Active:     true,
+		CreatedBy:  &createdBy,
+	}
+	if req.MaxBinds != nil {
+		bind.MaxBinds = *req.MaxBinds
+	}
+	if req.Throughput != nil {
+		bind.Throughput = *req.Throughput
+	}
+
+	if err := s.repo.Create(ctx, bind, hash); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("SMPP bind created",
+		zap.String("customer_id", customerID.String()),
+		zap.String("system_id", systemID),
+	)
+
+	return &models.SMPPBindWithPassword{SMPPBind: *bind, Password: password}, nil
+}
+
+// ListBinds retrieves all SMPP credentials for a customer
+func (s *SMPPBindService) ListBinds(ctx context.Context, customerID uuid.UUID) ([]models.SMPPBind, error) {
+	return s.repo.ListByCustomer(ctx, customerID)
+}
+
+// GetBind retrieves an SMPP credential with access verification
+func (s *SMPPBindService) GetBind(ctx context.Context, bindID uuid.UUID, customerID uuid.UUID) (*models.SMPPBind, error) {
+	bind, err := s.repo.GetByID(ctx, bindID)
+	if err != nil {
+		return nil, err
+	}
+	if bind == nil {
+		return nil, ErrSMPPBindNotFound
+	}
+	if bind.CustomerID != customerID {
+		return nil, ErrAccessDenied
+	}
+
+	return bind, nil
+}
+
+// UpdateBind updates allowed IPs, limits or active status
+func (s *SMPPBindService) UpdateBind(ctx context.Context, bindID uuid.UUID, customerID uuid.UUID, req models.UpdateSMPPBindRequest) (*models.SMPPBind, error) {
+	if _, err := s.GetBind(ctx, bindID, customerID); err != nil {
+		return nil, err
+	}
+
+	if req.AllowedIPs != nil {
+		allowedIPs, err := normalizeAllowedIPs(*req.AllowedIPs)
+		if err != nil {
+			return nil, err
+		}
+		req.AllowedIPs = &allowedIPs
+	}
+
+	if err := s.repo.Update(ctx, bindID, req); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, bindID)
+}
+
+// RotatePassword replaces the password and returns the new one.
+// Existing sessions stay bound until they reconnect or are force-unbound.
+func (s *SMPPBindService) RotatePassword(ctx context.Context, bindID uuid.UUID, customerID uuid.UUID) (*models.SMPPBindWithPassword, error) {
+	if _, err := s.GetBind(ctx, bindID, customerID); err != nil {
+		return nil, err
+	}
+
+	password, hash, err := generateSMPPPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdatePassword(ctx, bindID, hash); err != nil {
+		return nil, err
+	}
+
+	bind, err := s.repo.GetByID(ctx, bindID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("SMPP password rotated",
+		zap.String("customer_id", customerID.String()),
+		zap.String("system_id", bind.SystemID),
+	)
+
+	return &models.SMPPBindWithPassword{SMPPBind: *bind, Password: password}, nil
+}
+
+// DisableBind deactivates a system_id so new binds are rejected
+func (s *SMPPBindService) DisableBind(ctx context.Context, bindID uuid.UUID, customerID uuid.UUID) (*models.SMPPBind, error) {
+	active := false
+	return s.UpdateBind(ctx, bindID, customerID, models.UpdateSMPPBindRequest{Active: &active})
+}
+
+// generateSystemID derives a free system_id from the customer's BAN (e.g. "AC123456789", "AC1234567892")
+func (s *SMPPBindService) generateSystemID(ctx context.Context, ban string) (string, error) {
+	base := strings.ReplaceAll(ban, "-", "")
+	if len(base) > smppSystemIDMaxLen-2 {
+		base = base[:smppSystemIDMaxLen-2]
+	}
+
+	for n := 1; n < 100; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = fmt.Sprintf("%s%d", base, n)
+		}
+
+		taken, err := s.repo.SystemIDExists(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("no free system_id for BAN %s; specify one explicitly", ban)
+}
+
+// generateSMPPPassword returns a random password and its bcrypt hash
+func generateSMPPPassword() (string, string, error) {
+	password := make([]byte, smppPasswordLen)
+	max := big.NewInt(int64(len(smppPasswordChars)))
+	for i := range password {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to generate password: %w", err)
+		}
+		password[i] = smppPasswordChars[n.Int64()]
+	}
+
+	hash, err := bcrypt.GenerateFromPassword(password, bcrypt.DefaultCost)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return string(password), string(hash), nil
+}
+
+// normalizeAllowedIPs validates IPs/CIDRs and returns them in canonical form
+func normalizeAllowedIPs(ips []string) ([]string, error) {
+	normalized := make([]string, 0, len(ips))
+	for _, entry := range ips {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidAllowedIP, entry)
+			}
+			normalized = append(normalized, network.String())
+			continue
+		}
+
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAllowedIP, entry)
+		}
+		normalized = append(normalized, ip.String())
+	}
+
+	return normalized, nil
+}
@@ -261,6 +261,25 @@ func (c *Client) UpdateMetadata(ctx context.Context, telephoneNumber string, met
 	return &result, nil
 }
 
+// SetStatus moves a number to another status without releasing it, e.g.
+// IN_USE → QUARANTINE while a released number ages, and back on restore
+func (c *Client) SetStatus(ctx context.Context, telephoneNumber string, status NumberStatus) (*NumberInventory, error) {
+	path := fmt.Sprintf("/inventory/numbers/%s/status", telephoneNumber)
+	body := map[string]interface{}{"status": status}
+
+	resp, err := c.doRequest(ctx, http.MethodPut, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result NumberInventory
+	if err := c.handleResponse(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 // ReleaseNumber releases a number back to the pool
 // Status transition: RESERVED or IN_USE → RESERVED (per plan - not AVAILABLE)
 // Note: We set to RESERVED for grooming/audit purposes, not AVAILABLE
@@ -300,6 +319,9 @@ func (c *Client) SearchAvailableNumbers(ctx context.Context, opts SearchOptions)
 	if opts.RateCenter != "" {
 		query += fmt.Sprintf(" and locality contains \"%s\"", opts.RateCenter)
 	}
+	if opts.Contains != "" {
+		query += fmt.Sprintf(" and telephoneNumber contains \"%s\"", opts.Contains)
+	}
 
 	req := &QueryRequest{
 		Query:         query,
@@ -323,6 +345,7 @@ type SearchOptions struct {
 	State      string // State code (e.g., "CO")
 	LATA       string // LATA number (e.g., "656")
 	RateCenter string // Rate center name (partial match)
+	Contains   string // Digits anywhere in the telephone number
 	Page       int    // Page number (0-indexed)
 	Size       int    // Page size (default 50)
 }
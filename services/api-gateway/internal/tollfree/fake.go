@@ -0,0 +1,205 @@
+package tollfree
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeReservationTTL matches the SMS/800 hold on reserved numbers
+const fakeReservationTTL = 45 * 24 * time.Hour
+
+// fakePrefixes are searched in order when no prefix is given
+var fakePrefixes = []string{"833", "844", "855", "866", "877", "888", "800"}
+
+// FakeProvider is an in-memory Provider for local development and tests.
+// Every number not reserved or provisioned through it is spare. Verification
+// submissions are PENDING until first read, then VERIFIED, except that a
+// business name containing "REJECT" is rejected, so rejections can be exercised.
+type FakeProvider struct {
+	// FailProvision, when set, is returned by every ProvisionNumber call
+	FailProvision error
+
+	mu            sync.Mutex
+	numbers       map[string]*NumberStatus // tn -> registry record
+	verifications map[string]*fakeVerification
+	nextID        int
+}
+
+type fakeVerification struct {
+	reject bool
+	read   bool
+}
+
+// NewFakeProvider creates an empty fake provider
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		numbers:       make(map[string]*NumberStatus),
+		verifications: make(map[string]*fakeVerification),
+	}
+}
+
+// Name implements Provider
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+// SearchNumbers implements Provider
+func (f *FakeProvider) SearchNumbers(ctx context.Context, req SearchRequest) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	prefixes := fakePrefixes
+	if req.Prefix != "" {
+		prefixes = []string{req.Prefix}
+	}
+
+	var found []string
+	for _, prefix := range prefixes {
+		for line := 2000000; line < 2100000 && len(found) < req.Quantity; line++ {
+			tn := fmt.Sprintf("+1%s%07d", prefix, line)
+			if req.Contains != "" && !strings.Contains(tn[5:], req.Contains) {
+				continue
+			}
+			if f.spare(tn) {
+				found = append(found, tn)
+			}
+		}
+	}
+	return found, nil
+}
+
+// ReserveNumber implements Provider
+func (f *FakeProvider) ReserveNumber(ctx context.Context, tn string, respOrgID string) (*NumberStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if n := f.numbers[tn]; n != nil && n.Status == StatusReserved && n.RespOrgID == respOrgID {
+		return f.copy(n), nil
+	}
+	if !f.spare(tn) {
+		return nil, ErrNumberUnavailable
+	}
+
+	until := time.Now().Add(fakeReservationTTL)
+	f.numbers[tn] = &NumberStatus{
+		Number:        tn,
+		Status:        StatusReserved,
+		RespOrgID:     respOrgID,
+		ReservedUntil: &until,
+		LastChanged:   time.Now(),
+	}
+	return f.copy(f.numbers[tn]), nil
+}
+
+// ProvisionNumber implements Provider
+func (f *FakeProvider) ProvisionNumber(ctx context.Context, tn string, respOrgID string) (*NumberStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.FailProvision != nil {
+		return nil, f.FailProvision
+	}
+
+	n := f.numbers[tn]
+	if n == nil || n.RespOrgID != respOrgID || (n.Status != StatusReserved && n.Status != StatusWorking) {
+		return nil, ErrNumberUnavailable
+	}
+	if n.Status == StatusReserved {
+		n.Status = StatusWorking
+		n.ReservedUntil = nil
+		n.LastChanged = time.Now()
+	}
+	return f.copy(n), nil
+}
+
+// ChangeRespOrg implements Provider
+func (f *FakeProvider) ChangeRespOrg(ctx context.Context, tn string, respOrgID string) (*NumberStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := f.numbers[tn]
+	if n == nil || n.Status == StatusSpare {
+		return nil, ErrNumberUnavailable
+	}
+	n.RespOrgID = respOrgID
+	n.LastChanged = time.Now()
+	return f.copy(n), nil
+}
+
+// ReleaseNumber implements Provider
+func (f *FakeProvider) ReleaseNumber(ctx context.Context, tn string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.numbers, tn)
+	return nil
+}
+
+// GetNumber implements Provider
+func (f *FakeProvider) GetNumber(ctx context.Context, tn string) (*NumberStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.spare(tn) {
+		return &NumberStatus{Number: tn, Status: StatusSpare}, nil
+	}
+	return f.copy(f.numbers[tn]), nil
+}
+
+// SubmitVerification implements Provider
+func (f *FakeProvider) SubmitVerification(ctx context.Context, tn string, v Verification) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if n := f.numbers[tn]; n == nil || n.Status != StatusWorking {
+		return "", fmt.Errorf("%s is not a working number", tn)
+	}
+
+	f.nextID++
+	id := fmt.Sprintf("fake-tfv-%d", f.nextID)
+	f.verifications[id] = &fakeVerification{
+		reject: strings.Contains(strings.ToUpper(v.BusinessName), "REJECT"),
+	}
+	return id, nil
+}
+
+// GetVerification implements Provider
+func (f *FakeProvider) GetVerification(ctx context.Context, verificationID string) (*VerificationStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v := f.verifications[verificationID]
+	if v == nil {
+		return nil, fmt.Errorf("unknown verification %s", verificationID)
+	}
+
+	if !v.read {
+		v.read = true
+		return &VerificationStatus{Status: VerificationPending}, nil
+	}
+	if v.reject {
+		return &VerificationStatus{Status: VerificationRejected, RejectionReason: "business information could not be verified"}, nil
+	}
+	return &VerificationStatus{Status: VerificationVerified}, nil
+}
+
+// spare reports whether tn is free, treating lapsed reservations as spare
+func (f *FakeProvider) spare(tn string) bool {
+	n := f.numbers[tn]
+	if n == nil {
+		return true
+	}
+	if n.Status == StatusReserved && n.ReservedUntil != nil && time.Now().After(*n.ReservedUntil) {
+		delete(f.numbers, tn)
+		return true
+	}
+	return false
+}
+
+func (f *FakeProvider) copy(n *NumberStatus) *NumberStatus {
+	c := *n
+	return &c
+}
@@ -0,0 +1,95 @@
+// Package tollfree reserves and provisions toll-free numbers in the SMS/800
+// registry as a Responsible Organization (RespOrg), and submits them for
+// toll-free messaging verification. Toll-free numbers are not in NPAC, so
+// they never go through SOA.
+package tollfree
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNumberUnavailable indicates the number is not spare in the registry,
+// or is reserved or working under another RespOrg
+var ErrNumberUnavailable = errors.New("toll-free number is not available")
+
+// Registry statuses of a toll-free number
+const (
+	StatusSpare       = "SPARE"
+	StatusReserved    = "RESERVED"
+	StatusAssigned    = "ASSIGNED"
+	StatusWorking     = "WORKING"
+	StatusDisconnect  = "DISCONNECT"
+	StatusUnavailable = "UNAVAILABLE"
+)
+
+// Messaging verification statuses
+const (
+	VerificationPending  = "PENDING"
+	VerificationVerified = "VERIFIED"
+	VerificationRejected = "REJECTED"
+)
+
+// SearchRequest selects spare numbers in the registry
+type SearchRequest struct {
+	Prefix   string // Toll-free NPA (800, 833, ...); any if empty
+	Contains string // Digits anywhere after the prefix
+	Quantity int
+}
+
+// NumberStatus is a number's registry record
+type NumberStatus struct {
+	Number        string // E.164
+	Status        string
+	RespOrgID     string     // Controlling RespOrg, empty when spare
+	ReservedUntil *time.Time // Set while RESERVED
+	LastChanged   time.Time
+}
+
+// Verification is the business information submitted for toll-free
+// messaging verification
+type Verification struct {
+	BusinessName     string
+	BusinessWebsite  string
+	ContactName      string
+	ContactEmail     string
+	ContactPhone     string
+	UseCase          string
+	UseCaseSummary   string
+	SampleMessage    string
+	OptInDescription string
+	MonthlyVolume    int
+}
+
+// VerificationStatus is the outcome of a verification submission
+type VerificationStatus struct {
+	Status          string
+	RejectionReason string
+}
+
+// Provider manages toll-free numbers under our RespOrg. ProvisionNumber is
+// idempotent for numbers already working under respOrgID, and ReleaseNumber
+// succeeds for numbers we do not control.
+type Provider interface {
+	// Name identifies the provider in records and logs (e.g. "fake")
+	Name() string
+	// SearchNumbers returns spare numbers matching req
+	SearchNumbers(ctx context.Context, req SearchRequest) ([]string, error)
+	// ReserveNumber reserves a spare number for respOrgID
+	ReserveNumber(ctx context.Context, tn string, respOrgID string) (*NumberStatus, error)
+	// ProvisionNumber moves a number reserved by respOrgID to WORKING
+	ProvisionNumber(ctx context.Context, tn string, respOrgID string) (*NumberStatus, error)
+	// ChangeRespOrg moves a number we control to another of our RespOrg IDs
+	ChangeRespOrg(ctx context.Context, tn string, respOrgID string) (*NumberStatus, error)
+	// ReleaseNumber disconnects a number and returns it to the spare pool
+	ReleaseNumber(ctx context.Context, tn string) error
+	// GetNumber returns a number's registry record
+	GetNumber(ctx context.Context, tn string) (*NumberStatus, error)
+
+	// SubmitVerification submits tn for messaging verification and returns
+	// the provider's verification ID
+	SubmitVerification(ctx context.Context, tn string, v Verification) (string, error)
+	// GetVerification returns the status of a verification submission
+	GetVerification(ctx context.Context, verificationID string) (*VerificationStatus, error)
+}
@@ -0,0 +1,117 @@
+package trunk
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+var (
+	// ErrInvalidTrunkIP indicates the address or prefix length could not be parsed
+	ErrInvalidTrunkIP = errors.New("invalid trunk IP address or prefix")
+	// ErrTrunkIPNotRoutable indicates the range covers private, reserved or otherwise bogon space
+	ErrTrunkIPNotRoutable = errors.New("trunk IP range overlaps private or reserved address space")
+	// ErrTrunkIPOverlap indicates another customer already holds an overlapping range
+	ErrTrunkIPOverlap = errors.New("trunk IP range overlaps a range registered to another customer")
+	// ErrTrunkIPExists indicates the range is already on the trunk group
+	ErrTrunkIPExists = errors.New("trunk IP range already exists on this trunk group")
+)
+
+// bogonPrefixes are ranges never accepted as customer SIP sources in
+// production: private, loopback, link-local, CGNAT, documentation,
+// multicast and reserved space for both families
+var bogonPrefixes = mustParsePrefixes(
+	// IPv4
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	// IPv6
+	"::/8",
+	"100::/64",
+	"2001:db8::/32",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func mustParsePrefixes(cidrs ...string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefixes = append(prefixes, netip.MustParsePrefix(cidr))
+	}
+	return prefixes
+}
+
+// ParseTrunkIPRange parses an address ("203.0.113.10", "2001:db8::1") or CIDR
+// ("203.0.113.0/28") plus an optional explicit prefix length, and returns the
+// canonical range: the network address with host bits cleared. Without either
+// a CIDR suffix or netmask the range is a single host (/32 or /128).
+func ParseTrunkIPRange(ipAddress string, netmask *int) (netip.Prefix, error) {
+	ipAddress = strings.TrimSpace(ipAddress)
+
+	var addr netip.Addr
+	bits := -1
+	if strings.Contains(ipAddress, "/") {
+		prefix, err := netip.ParsePrefix(ipAddress)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("%w: %s", ErrInvalidTrunkIP, ipAddress)
+		}
+		addr, bits = prefix.Addr(), prefix.Bits()
+	} else {
+		var err error
+		addr, err = netip.ParseAddr(ipAddress)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("%w: %s", ErrInvalidTrunkIP, ipAddress)
+		}
+	}
+	if addr.Zone() != "" {
+		return netip.Prefix{}, fmt.Errorf("%w: zoned addresses are not allowed", ErrInvalidTrunkIP)
+	}
+	// IPv4-mapped IPv6 (::ffff:1.2.3.4) is matched as IPv4 by the SIP edge
+	if addr.Is4In6() {
+		if bits >= 96 {
+			bits -= 96
+		} else if bits >= 0 {
+			return netip.Prefix{}, fmt.Errorf("%w: %s", ErrInvalidTrunkIP, ipAddress)
+		}
+		addr = addr.Unmap()
+	}
+
+	if netmask != nil {
+		if bits >= 0 && bits != *netmask {
+			return netip.Prefix{}, fmt.Errorf("%w: prefix /%d conflicts with netmask %d", ErrInvalidTrunkIP, bits, *netmask)
+		}
+		bits = *netmask
+	}
+	if bits < 0 {
+		bits = addr.BitLen()
+	}
+	if bits > addr.BitLen() {
+		return netip.Prefix{}, fmt.Errorf("%w: netmask %d exceeds %d for %s", ErrInvalidTrunkIP, bits, addr.BitLen(), addr)
+	}
+
+	return netip.PrefixFrom(addr, bits).Masked(), nil
+}
+
+// isBogonRange reports whether the range overlaps any bogon prefix, so broad
+// ranges such as 0.0.0.0/0 are rejected as well
+func isBogonRange(prefix netip.Prefix) bool {
+	for _, bogon := range bogonPrefixes {
+		if bogon.Overlaps(prefix) {
+			return true
+		}
+	}
+	return false
+}
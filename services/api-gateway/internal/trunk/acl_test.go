@@ -0,0 +1,79 @@
+package trunk
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestParseTrunkIPRange(t *testing.T) {
+	mask := func(n int) *int { return &n }
+
+	tests := []struct {
+		ip      string
+		netmask *int
+		want    string
+		wantErr bool
+	}{
+		{ip: "8.8.8.8", want: "8.8.8.8/32"},
+		{ip: " 8.8.8.8 ", want: "8.8.8.8/32"},
+		{ip: "8.8.8.0/24", want: "8.8.8.0/24"},
+		{ip: "8.8.8.77/24", want: "8.8.8.0/24"}, // Host bits cleared
+		{ip: "8.8.8.77", netmask: mask(28), want: "8.8.8.64/28"},
+		{ip: "8.8.8.0/24", netmask: mask(24), want: "8.8.8.0/24"},
+		{ip: "2001:4860::8888", want: "2001:4860::8888/128"},
+		{ip: "2001:4860::/32", want: "2001:4860::/32"},
+		{ip: "::ffff:8.8.8.8", want: "8.8.8.8/32"},
+		{ip: "::ffff:8.8.8.0/120", want: "8.8.8.0/24"},
+
+		{ip: "not-an-ip", wantErr: true},
+		{ip: "8.8.8.8/33", wantErr: true},
+		{ip: "8.8.8.0/24", netmask: mask(28), wantErr: true}, // Conflicting prefix
+		{ip: "8.8.8.8", netmask: mask(33), wantErr: true},
+		{ip: "fe80::1%eth0", wantErr: true},
+		{ip: "::ffff:8.8.8.0/64", wantErr: true}, // Mapped prefix shorter than /96
+	}
+	for _, tt := range tests {
+		got, err := ParseTrunkIPRange(tt.ip, tt.netmask)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTrunkIPRange(%q) error = %v, wantErr %v", tt.ip, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidTrunkIP) {
+				t.Errorf("ParseTrunkIPRange(%q) error = %v, want ErrInvalidTrunkIP", tt.ip, err)
+			}
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("ParseTrunkIPRange(%q) = %s, want %s", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestIsBogonRange(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   bool
+	}{
+		{"8.8.8.8/32", false},
+		{"8.8.8.0/24", false},
+		{"2001:4860::/32", false},
+		{"10.1.2.3/32", true},
+		{"192.168.0.0/24", true},
+		{"100.64.1.0/24", true},
+		{"203.0.113.10/32", true}, // Documentation
+		{"224.0.0.1/32", true},
+		{"0.0.0.0/0", true}, // Covers bogon space
+		{"8.0.0.0/4", true}, // Contains 10.0.0.0/8
+		{"fe80::1/128", true},
+		{"fd00::/8", true},
+		{"2001:db8::1/128", true},
+		{"::/0", true},
+	}
+	for _, tt := range tests {
+		if got := isBogonRange(netip.MustParsePrefix(tt.prefix)); got != tt.want {
+			t.Errorf("isBogonRange(%s) = %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,486 @@
+package trunk
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/pgerr"
+	"github.com/ringer-warp/api-gateway/internal/rediskeys"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrTrunkAccessDenied indicates the trunk group does not belong to the customer
+	ErrTrunkAccessDenied = errors.New("access denied to trunk group")
+	// ErrDigestNotAllowed indicates a credential was requested for an IP_ACL-only trunk
+	ErrDigestNotAllowed = errors.New("trunk group uses IP_ACL authentication; digest credentials require auth_type DIGEST or BOTH")
+	// ErrCredentialNotFound indicates the credential does not exist on the trunk group
+	ErrCredentialNotFound = errors.New("trunk credential not found")
+	// ErrCredentialRevoked indicates the credential was already revoked
+	ErrCredentialRevoked = errors.New("trunk credential is revoked")
+	// ErrCredentialUsernameTaken indicates another active credential uses the username
+	ErrCredentialUsernameTaken = errors.New("SIP username already in use")
+	// ErrInvalidCredentialUsername indicates the username has characters SIP clients mishandle
+	ErrInvalidCredentialUsername = errors.New("SIP username must start with a letter or digit and contain only letters, digits, '.', '_' or '-'")
+)
+
+// DefaultSIPRealm is the digest realm Kamailio challenges customer trunks with
+const DefaultSIPRealm = "sip.ringer.tel"
+
+// Kamailio auth_db subscriber table layout in Redis:
+// subscriber:entry:{username}:{realm} -> {username, domain, ha1, ha1b, password, tag, trunk_id}
+const subscriberEntryPrefix = "subscriber:entry:"
+
+const (
+	sipUsernameAttempts = 5
+	sipPasswordLen      = 24
+	sipPasswordChars    = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789"
+	sipUsernameChars    = "abcdefghijkmnpqrstuvwxyz23456789"
+)
+
+var sipUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{3,63}$`)
+
+// ============================================================================
+// Trunk Digest Credential Operations
+// ============================================================================
+
+// CreateTrunkCredential creates a SIP digest credential for a DIGEST or BOTH
+// trunk and syncs it to Redis. The password is returned only here.
+func (s *Service) CreateTrunkCredential(ctx context.Context, trunkID uuid.UUID, customerID uuid.UUID, req models.CreateTrunkCredentialRequest, createdBy uuid.UUID) (*models.TrunkCredentialWithPassword, error) {
+	trunk, err := s.getTrunkForCustomer(ctx, trunkID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if trunk.AuthType == "IP_ACL" {
+		return nil, ErrDigestNotAllowed
+	}
+
+	customer, err := s.getCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	username := req.Username
+	if username == "" {
+		username, err = s.generateSIPUsername(ctx, customer.BAN)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if !sipUsernamePattern.MatchString(username) {
+			return nil, ErrInvalidCredentialUsername
+		}
+		taken, err := s.repo.TrunkCredentialUsernameExists(ctx, username, DefaultSIPRealm)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrCredentialUsernameTaken
+		}
+	}
+
+	password, err := generateSIPPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	// The existence check above races with concurrent creates; the partial
+	// unique index decides. A generated username is simply drawn again.
+	var cred *models.TrunkCredential
+	var ha1, ha1b string
+	for attempt := 1; ; attempt++ {
+		ha1, ha1b = digestHA1(username, DefaultSIPRealm, password)
+		cred = &models.TrunkCredential{
+			ID:           uuid.New(),
+			TrunkGroupID: trunkID,
+			Username:     username,
+			Realm:        DefaultSIPRealm,
+			Description:  req.Description,
+			CreatedBy:    &createdBy,
+		}
+		err = s.repo.CreateTrunkCredential(ctx, cred, ha1, ha1b)
+		if err == nil {
+			break
+		}
+		if !pgerr.IsUniqueViolation(err) {
+			return nil, err
+		}
+		if req.Username != "" || attempt == sipUsernameAttempts {
+			return nil, ErrCredentialUsernameTaken
+		}
+		if username, err = s.generateSIPUsername(ctx, customer.BAN); err != nil {
+			return nil, err
+		}
+	}
+
+	if trunk.Enabled {
+		if err := s.syncCredentialToRedis(ctx, *cred, ha1, ha1b, customer.BAN); err != nil {
+			// Log error but don't fail the create (resynced on rotate or trunk re-enable)
+			s.logger.Warn("Failed to sync trunk credential to Redis", zap.String("username", username), zap.Error(err))
+		}
+	}
+
+	s.logger.Info("Trunk credential created",
+		zap.String("trunk_id", trunkID.String()),
+		zap.String("username", username),
+	)
+
+	return &models.TrunkCredentialWithPassword{TrunkCredential: *cred, Password: password}, nil
+}
+
+// ListTrunkCredentials lists a trunk's credentials (never their hashes) with access verification
+func (s *Service) ListTrunkCredentials(ctx context.Context, trunkID uuid.UUID, customerID uuid.UUID) ([]models.TrunkCredential, error) {
+	if _, err := s.getTrunkForCustomer(ctx, trunkID, customerID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListTrunkCredentials(ctx, trunkID)
+}
+
+// RotateTrunkCredential replaces a credential's password, keeping its username.
+// Registered devices must be updated with the new password.
+func (s *Service) RotateTrunkCredential(ctx context.Context, trunkID, credID, customerID uuid.UUID) (*models.TrunkCredentialWithPassword, error) {
+	trunk, err := s.getTrunkForCustomer(ctx, trunkID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if trunk.AuthType == "IP_ACL" {
+		return nil, ErrDigestNotAllowed
+	}
+
+	cred, err := s.getCredentialForTrunk(ctx, trunkID, credID)
+	if err != nil {
+		return nil, err
+	}
+	if cred.Status != "ACTIVE" {
+		return nil, ErrCredentialRevoked
+	}
+
+	password, err := generateSIPPassword()
+	if err != nil {
+		return nil, err
+	}
+	ha1, ha1b := digestHA1(cred.Username, cred.Realm, password)
+
+	if err := s.repo.UpdateTrunkCredentialHashes(ctx, credID, ha1, ha1b); err != nil {
+		return nil, err
+	}
+
+	// Re-read for the new last_rotated_at
+	if updated, err := s.repo.GetTrunkCredential(ctx, credID); err == nil && updated != nil {
+		cred = updated
+	}
+
+	if trunk.Enabled {
+		customer, err := s.getCustomer(ctx, customerID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.syncCredentialToRedis(ctx, *cred, ha1, ha1b, customer.BAN); err != nil {
+			s.logger.Warn("Failed to sync rotated trunk credential to Redis", zap.String("username", cred.Username), zap.Error(err))
+		}
+	}
+
+	s.logger.Info("Trunk credential rotated",
+		zap.String("trunk_id", trunkID.String()),
+		zap.String("username", cred.Username),
+	)
+
+	return &models.TrunkCredentialWithPassword{TrunkCredential: *cred, Password: password}, nil
+}
+
+// RevokeTrunkCredential revokes a credential and removes it from Redis
+func (s *Service) RevokeTrunkCredential(ctx context.Context, trunkID, credID, customerID uuid.UUID) error {
+	if _, err := s.getTrunkForCustomer(ctx, trunkID, customerID); err != nil {
+		return err
+	}
+
+	cred, err := s.getCredentialForTrunk(ctx, trunkID, credID)
+	if err != nil {
+		return err
+	}
+	if cred.Status != "ACTIVE" {
+		return ErrCredentialRevoked
+	}
+
+	if err := s.repo.RevokeTrunkCredential(ctx, credID); err != nil {
+		return err
+	}
+
+	if err := s.removeCredentialFromRedis(ctx, *cred); err != nil {
+		s.logger.Warn("Failed to remove trunk credential from Redis", zap.String("username", cred.Username), zap.Error(err))
+	}
+
+	s.logger.Info("Trunk credential revoked",
+		zap.String("trunk_id", trunkID.String()),
+		zap.String("username", cred.Username),
+	)
+
+	return nil
+}
+
+// applyCredentialPolicy keeps credentials consistent with a trunk group update.
+// before holds the trunk's credentials as they were prior to the update.
+func (s *Service) applyCredentialPolicy(ctx context.Context, trunkID uuid.UUID, before []models.TrunkCredential, req models.UpdateTrunkGroupRequest) {
+	if req.AuthType == nil && req.Enabled == nil {
+		return
+	}
+
+	active := make([]models.TrunkCredential, 0, len(before))
+	for _, cred := range before {
+		if cred.Status == "ACTIVE" {
+			active = append(active, cred)
+		}
+	}
+	if len(active) == 0 {
+		return
+	}
+
+	// IP_ACL-only trunks never keep credentials
+	if req.AuthType != nil && *req.AuthType == "IP_ACL" {
+		if err := s.repo.RevokeTrunkCredentials(ctx, trunkID); err != nil {
+			s.logger.Error("Failed to revoke credentials of IP_ACL trunk", zap.String("trunk_id", trunkID.String()), zap.Error(err))
+		}
+		s.removeCredentialsFromRedis(ctx, active)
+		s.logger.Info("Revoked digest credentials after switch to IP_ACL",
+			zap.String("trunk_id", trunkID.String()),
+			zap.Int("count", len(active)),
+		)
+		return
+	}
+
+	if req.Enabled == nil {
+		return
+	}
+	if !*req.Enabled {
+		s.removeCredentialsFromRedis(ctx, active)
+		return
+	}
+
+	// Re-enabled: publish credentials again
+	trunk, err := s.repo.GetTrunkGroup(ctx, trunkID)
+	if err != nil {
+		s.logger.Warn("Failed to load trunk for credential resync", zap.String("trunk_id", trunkID.String()), zap.Error(err))
+		return
+	}
+	customer, err := s.getCustomer(ctx, trunk.CustomerID)
+	if err != nil {
+		s.logger.Warn("Failed to load customer for credential resync", zap.String("trunk_id", trunkID.String()), zap.Error(err))
+		return
+	}
+	for _, cred := range active {
+		ha1, ha1b, err := s.repo.GetTrunkCredentialHashes(ctx, cred.ID)
+		if err == nil {
+			err = s.syncCredentialToRedis(ctx, cred, ha1, ha1b, customer.BAN)
+		}
+		if err != nil {
+			s.logger.Warn("Failed to resync trunk credential to Redis", zap.String("username", cred.Username), zap.Error(err))
+		}
+	}
+}
+
+// getTrunkForCustomer loads a trunk group, verifying it belongs to the customer
+func (s *Service) getTrunkForCustomer(ctx context.Context, trunkID, customerID uuid.UUID) (*models.TrunkGroup, error) {
+	hasAccess, err := s.repo.VerifyTrunkAccess(ctx, trunkID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasAccess {
+		return nil, ErrTrunkAccessDenied
+	}
+	return s.repo.GetTrunkGroup(ctx, trunkID)
+}
+
+// getCredentialForTrunk loads a credential, verifying it belongs to the trunk group
+func (s *Service) getCredentialForTrunk(ctx context.Context, trunkID, credID uuid.UUID) (*models.TrunkCredential, error) {
+	cred, err := s.repo.GetTrunkCredential(ctx, credID)
+	if err != nil {
+		return nil, err
+	}
+	if cred == nil || cred.TrunkGroupID != trunkID {
+		return nil, ErrCredentialNotFound
+	}
+	return cred, nil
+}
+
+// generateSIPUsername creates an unused username derived from the customer BAN
+func (s *Service) generateSIPUsername(ctx context.Context, ban string) (string, error) {
+	prefix := strings.ToLower(strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, ban))
+	if prefix == "" {
+		prefix = "trunk"
+	}
+
+	for attempt := 0; attempt < sipUsernameAttempts; attempt++ {
+		suffix, err := randomString(sipUsernameChars, 6)
+		if err != nil {
+			return "", err
+		}
+		username := prefix + "-" + suffix
+
+		taken, err := s.repo.TrunkCredentialUsernameExists(ctx, username, DefaultSIPRealm)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return username, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not generate a unique SIP username for %s", ban)
+}
+
+// ============================================================================
+// Redis Synchronization (Kamailio auth_db)
+// ============================================================================
+
+// subscriberEntryKey returns the Redis key of a credential's subscriber entry
+func subscriberEntryKey(username, realm string) string {
+	return subscriberEntryPrefix + username + ":" + realm
+}
+
+// subscriberFields returns the hash stored for a credential.
+// Kamailio auth_db expects these field names (password stays empty; HA1 is used).
+func subscriberFields(username, realm, ha1, ha1b, customerBAN string, trunkID uuid.UUID) map[string]interface{} {
+	return map[string]interface{}{
+		"username": username,
+		"domain":   realm,
+		"ha1":      ha1,
+		"ha1b":     ha1b,
+		"password": "",
+		"tag":      customerBAN, // Customer BAN, like address entries
+		"trunk_id": trunkID.String(),
+	}
+}
+
+// syncCredentialToRedis publishes a credential for Kamailio digest authentication
+func (s *Service) syncCredentialToRedis(ctx context.Context, cred models.TrunkCredential, ha1, ha1b, customerBAN string) error {
+	if cred.Status != "" && cred.Status != "ACTIVE" {
+		return nil // Never publish revoked credentials
+	}
+
+	data := subscriberFields(cred.Username, cred.Realm, ha1, ha1b, customerBAN, cred.TrunkGroupID)
+	if err := s.redisClient.HSet(ctx, subscriberEntryKey(cred.Username, cred.Realm), data).Err(); err != nil {
+		return fmt.Errorf("failed to sync credential to Redis: %w", err)
+	}
+
+	return nil
+}
+
+// removeCredentialFromRedis removes a credential's subscriber entry
+func (s *Service) removeCredentialFromRedis(ctx context.Context, cred models.TrunkCredential) error {
+	if err := s.redisClient.Del(ctx, subscriberEntryKey(cred.Username, cred.Realm)).Err(); err != nil {
+		return fmt.Errorf("failed to remove credential from Redis: %w", err)
+	}
+	return nil
+}
+
+// removeCredentialsFromRedis removes the subscriber entries of active credentials,
+// logging failures. Revoked ones are skipped: their username may since belong
+// to a new credential.
+func (s *Service) removeCredentialsFromRedis(ctx context.Context, creds []models.TrunkCredential) {
+	for _, cred := range creds {
+		if cred.Status != "ACTIVE" {
+			continue
+		}
+		if err := s.removeCredentialFromRedis(ctx, cred); err != nil {
+			s.logger.Warn("Failed to remove trunk credential from Redis", zap.String("username", cred.Username), zap.Error(err))
+		}
+	}
+}
+
+// reconcileSubscribers makes the subscriber entries in Redis match the active
+// credentials of enabled digest trunks, filling in the subscriber counts of
+// report. Only entries written by the gateway (those carrying a trunk_id) are
+// managed; other auth_db subscribers are left alone.
+func (s *Service) reconcileSubscribers(ctx context.Context, report *models.AddressReconcileReport) error {
+	// Redis first, as for addresses, so a credential created concurrently is never removed
+	entries, err := s.loadHashes(ctx, subscriberEntryPrefix+"*")
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]map[string]string, len(entries))
+	for key, fields := range entries {
+		if fields["trunk_id"] != "" {
+			existing[key] = fields
+		}
+	}
+	report.SubscribersExisting = len(existing)
+
+	creds, err := s.repo.ListActiveTrunkCredentialEntries(ctx)
+	if err != nil {
+		return err
+	}
+	desired := make(map[string]map[string]interface{}, len(creds))
+	for _, cred := range creds {
+		desired[subscriberEntryKey(cred.Username, cred.Realm)] = subscriberFields(cred.Username, cred.Realm, cred.HA1, cred.HA1B, cred.CustomerBAN, cred.TrunkGroupID)
+	}
+	report.SubscribersDesired = len(desired)
+
+	diff := rediskeys.Compare(existing, desired, fieldsEqual)
+	report.SubscribersAdded = len(diff.Added)
+	report.SubscribersUpdated = len(diff.Updated)
+	report.SubscribersUnchanged = len(diff.Unchanged)
+	report.SubscribersRemoved = len(diff.Removed)
+	report.RemovedSubscriberKeys = diff.Removed
+
+	if report.DryRun || len(diff.Added)+len(diff.Updated)+len(diff.Removed) == 0 {
+		return nil
+	}
+
+	pipe := s.redisClient.TxPipeline()
+	for _, key := range diff.Added {
+		pipe.HSet(ctx, key, desired[key])
+	}
+	for _, key := range diff.Updated {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, desired[key])
+	}
+	for _, key := range diff.Removed {
+		pipe.Del(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to apply subscriber table changes: %w", err)
+	}
+
+	return nil
+}
+
+// digestHA1 returns HA1 = MD5(username:realm:password) and
+// HA1B = MD5(username@realm:realm:password) as used by Kamailio auth_db
+func digestHA1(username, realm, password string) (string, string) {
+	ha1 := md5.Sum([]byte(username + ":" + realm + ":" + password))
+	ha1b := md5.Sum([]byte(username + "@" + realm + ":" + realm + ":" + password))
+	return hex.EncodeToString(ha1[:]), hex.EncodeToString(ha1b[:])
+}
+
+// generateSIPPassword creates a random digest password
+func generateSIPPassword() (string, error) {
+	return randomString(sipPasswordChars, sipPasswordLen)
+}
+
+// randomString returns n characters drawn uniformly from charset
+func randomString(charset string, n int) (string, error) {
+	buf := make([]byte, n)
+	max := big.NewInt(int64(len(charset)))
+	for i := range buf {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random string: %w", err)
+		}
+		buf[i] = charset[idx.Int64()]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,59 @@
+package trunk
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDigestHA1(t *testing.T) {
+	tests := []struct {
+		username, realm, password string
+		ha1, ha1b                 string
+	}{
+		{"acme-x7k2p9", "sip.ringer.tel", "s3cret", "861161e771de915fca5cd822ac373a2b", "0dbd0254e3c5dd090e113c22427d78a2"},
+		{"alice", "example.com", "pw", "981a4ef40955b75633f737a3ece44230", "94e77326454c7347185d0106b03216ff"},
+	}
+	for _, tt := range tests {
+		ha1, ha1b := digestHA1(tt.username, tt.realm, tt.password)
+		if ha1 != tt.ha1 || ha1b != tt.ha1b {
+			t.Errorf("digestHA1(%s, %s) = %s, %s; want %s, %s", tt.username, tt.realm, ha1, ha1b, tt.ha1, tt.ha1b)
+		}
+	}
+}
+
+func TestSIPUsernamePattern(t *testing.T) {
+	tests := []struct {
+		username string
+		want     bool
+	}{
+		{"acme-x7k2p9", true},
+		{"Trunk.01_main", true},
+		{"abcd", true},
+		{"abc", false},   // Too short
+		{"-acme", false}, // Leading punctuation
+		{"acme@host", false},
+		{"acme trunk", false},
+		{strings.Repeat("a", 64), true},
+		{strings.Repeat("a", 65), false},
+	}
+	for _, tt := range tests {
+		if got := sipUsernamePattern.MatchString(tt.username); got != tt.want {
+			t.Errorf("sipUsernamePattern(%q) = %v, want %v", tt.username, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateSIPPassword(t *testing.T) {
+	password, err := generateSIPPassword()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(password) != sipPasswordLen {
+		t.Errorf("len(password) = %d, want %d", len(password), sipPasswordLen)
+	}
+	for _, c := range password {
+		if !strings.ContainsRune(sipPasswordChars, c) {
+			t.Errorf("password contains %q outside the charset", c)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package trunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ringer-warp/api-gateway/internal/cloudip"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/pgerr"
+	"github.com/ringer-warp/api-gateway/internal/worker"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrDedicatedIPUnavailable indicates no cloud address provider is configured
+	ErrDedicatedIPUnavailable = errors.New("dedicated IP provisioning is not configured")
+	// ErrDedicatedIPExists indicates the customer already has a live dedicated IP in the region
+	ErrDedicatedIPExists = errors.New("customer already has a dedicated IP in this region")
+	// ErrDedicatedIPNotFound indicates the dedicated IP does not exist for the customer
+	ErrDedicatedIPNotFound = errors.New("dedicated IP not found")
+	// ErrDedicatedIPInvalidState indicates the requested transition is not allowed from the current status
+	ErrDedicatedIPInvalidState = errors.New("dedicated IP cannot be deprovisioned in its current status")
+)
+
+// Dedicated IP lifecycle:
+//
+//	PROVISIONING --(provider READY)--> ACTIVE --(deprovision)--> DEPROVISIONING --(released)--> DELETED
+//	PROVISIONING --(too many failed attempts)--> FAILED --(deprovision)--> DEPROVISIONING
+//
+// The Redis mapping customer:dedicated_ip:{ip} is written on ACTIVE and removed
+// before DEPROVISIONING, so the SIP edge never attributes traffic to a released IP.
+const (
+	DedicatedIPProvisioning   = "PROVISIONING"
+	DedicatedIPActive         = "ACTIVE"
+	DedicatedIPDeprovisioning = "DEPROVISIONING"
+	DedicatedIPDeleted        = "DELETED"
+	DedicatedIPFailed         = "FAILED"
+)
+
+// DefaultDedicatedIPRegion is used when a request names no region
+const DefaultDedicatedIPRegion = "us-central1"
+
+// maxDedicatedIPAttempts bounds provider polls/errors before PROVISIONING fails
+const maxDedicatedIPAttempts = 40
+
+// SetAddressProvider enables dedicated IP provisioning with a cloud address provider
+func (s *Service) SetAddressProvider(provider cloudip.Provider) {
+	s.addressProvider = provider
+}
+
+// ============================================================================
+// Dedicated IP Operations
+// ============================================================================
+
+// RequestDedicatedIP starts provisioning a dedicated ingress IP for a customer
+func (s *Service) RequestDedicatedIP(ctx context.Context, customerID uuid.UUID, req models.RequestDedicatedIPRequest, requestedBy uuid.UUID) (*models.CustomerDedicatedIP, error) {
+	if s.addressProvider == nil {
+		return nil, ErrDedicatedIPUnavailable
+	}
+
+	customer, err := s.getCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(customer.Status, "active") {
+		return nil, fmt.Errorf("customer is not active: %s", customer.Status)
+	}
+
+	region := req.Region
+	if region == "" {
+		region = DefaultDedicatedIPRegion
+	}
+
+	id := uuid.New()
+	addressName := dedicatedAddressName(customer.BAN, id)
+	ip := &models.CustomerDedicatedIP{
+		ID:             id,
+		CustomerID:     customerID,
+		Region:         region,
+		Provider:       s.addressProvider.Name(),
+		GCPAddressName: &addressName,
+		RequestedBy:    &requestedBy,
+	}
+	if req.LoadBalancerName != "" {
+		ip.GCPLoadBalancerName = &req.LoadBalancerName
+	}
+
+	if err := s.repo.CreateDedicatedIP(ctx, ip); err != nil {
+		if pgerr.IsUniqueViolation(err) {
+			return nil, ErrDedicatedIPExists
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Dedicated IP requested",
+		zap.String("customer_ban", customer.BAN),
+		zap.String("region", region),
+		zap.String("address_name", addressName),
+	)
+
+	// Start the reservation now; the worker finishes it
+	s.advanceDedicatedIP(ctx, *ip)
+	return s.getDedicatedIPForCustomer(ctx, id, customerID)
+}
+
+// ListDedicatedIPs lists a customer's dedicated IPs, including history
+func (s *Service) ListDedicatedIPs(ctx context.Context, customerID uuid.UUID) ([]models.CustomerDedicatedIP, error) {
+	return s.repo.ListDedicatedIPsByCustomer(ctx, customerID)
+}
+
+// GetDedicatedIP retrieves one of a customer's dedicated IPs
+func (s *Service) GetDedicatedIP(ctx context.Context, id, customerID uuid.UUID) (*models.CustomerDedicatedIP, error) {
+	return s.getDedicatedIPForCustomer(ctx, id, customerID)
+}
+
+// DeprovisionDedicatedIP stops routing a dedicated IP and releases it
+func (s *Service) DeprovisionDedicatedIP(ctx context.Context, id, customerID uuid.UUID) (*models.CustomerDedicatedIP, error) {
+	if s.addressProvider == nil {
+		return nil, ErrDedicatedIPUnavailable
+	}
+
+	ip, err := s.getDedicatedIPForCustomer(ctx, id, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ip.Status {
+	case DedicatedIPActive, DedicatedIPProvisioning, DedicatedIPFailed:
+	default:
+		return nil, ErrDedicatedIPInvalidState
+	}
+
+	// Stop attributing traffic before the address can be reused elsewhere
+	if ip.IPAddress != nil {
+		if err := s.RemoveDedicatedIPMapping(ctx, *ip.IPAddress); err != nil {
+			return nil, fmt.Errorf("failed to remove dedicated IP mapping: %w", err)
+		}
+	}
+
+	moved, err := s.repo.TransitionDedicatedIP(ctx, id, ip.Status, DedicatedIPDeprovisioning, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !moved {
+		return nil, ErrDedicatedIPInvalidState
+	}
+	ip.Status = DedicatedIPDeprovisioning
+	ip.Attempts = 0
+
+	s.logger.Info("Dedicated IP deprovisioning", zap.String("id", id.String()), zap.Stringp("ip", ip.IPAddress))
+
+	s.advanceDedicatedIP(ctx, *ip)
+	return s.getDedicatedIPForCustomer(ctx, id, customerID)
+}
+
+// StartDedicatedIPWorker advances in-progress dedicated IPs every interval
+// until ctx is cancelled, and re-asserts the Redis mappings of ACTIVE ones
+func (s *Service) StartDedicatedIPWorker(ctx context.Context, interval time.Duration) {
+	if s.addressProvider == nil {
+		return
+	}
+
+	worker.Every(ctx, interval, nil, func(ctx context.Context) {
+		ips, err := s.repo.ListDedicatedIPsInProgress(ctx)
+		if err != nil {
+			s.logger.Warn("Failed to list in-progress dedicated IPs", zap.Error(err))
+			return
+		}
+		for _, ip := range ips {
+			s.advanceDedicatedIP(ctx, ip)
+		}
+
+		s.syncDedicatedIPMappings(ctx)
+	})
+}
+
+// advanceDedicatedIP performs one provider step for a PROVISIONING or
+// DEPROVISIONING dedicated IP. Failures are recorded on the row and retried
+// by the worker.
+func (s *Service) advanceDedicatedIP(ctx context.Context, ip models.CustomerDedicatedIP) {
+	if ip.Provider != s.addressProvider.Name() {
+		s.logger.Warn("Skipping dedicated IP held by another provider",
+			zap.String("id", ip.ID.String()),
+			zap.String("provider", ip.Provider),
+		)
+		return
+	}
+	if ip.GCPAddressName == nil {
+		return
+	}
+	name := *ip.GCPAddressName
+
+	switch ip.Status {
+	case DedicatedIPProvisioning:
+		addr, err := s.addressProvider.Get(ctx, name, ip.Region)
+		if errors.Is(err, cloudip.ErrNotFound) {
+			err = s.addressProvider.Reserve(ctx, name, ip.Region)
+		}
+		if err != nil || addr == nil || addr.Status != cloudip.StatusReady {
+			s.recordDedicatedIPAttempt(ctx, ip, err)
+			return
+		}
+
+		moved, err := s.repo.TransitionDedicatedIP(ctx, ip.ID, DedicatedIPProvisioning, DedicatedIPActive, &addr.IP, nil)
+		if err != nil {
+			// Counts toward FAILED, e.g. an address still held by another live row
+			s.recordDedicatedIPAttempt(ctx, ip, fmt.Errorf("failed to activate: %w", err))
+			return
+		}
+		if !moved {
+			return
+		}
+		if err := s.CacheDedicatedIPMapping(ctx, ip.CustomerID, addr.IP); err != nil {
+			// The worker re-asserts mappings of ACTIVE IPs
+			s.logger.Warn("Failed to cache dedicated IP mapping", zap.String("ip", addr.IP), zap.Error(err))
+		}
+		s.logger.Info("Dedicated IP active", zap.String("id", ip.ID.String()), zap.String("ip", addr.IP))
+
+	case DedicatedIPDeprovisioning:
+		if ip.IPAddress != nil {
+			// Idempotent; covers a mapping re-asserted concurrently by the worker
+			s.RemoveDedicatedIPMapping(ctx, *ip.IPAddress)
+		}
+
+		err := s.addressProvider.Release(ctx, name, ip.Region)
+		if err == nil {
+			_, err = s.addressProvider.Get(ctx, name, ip.Region)
+		}
+		if !errors.Is(err, cloudip.ErrNotFound) {
+			s.recordDedicatedIPAttempt(ctx, ip, err) // Release still in progress
+			return
+		}
+
+		if _, err := s.repo.TransitionDedicatedIP(ctx, ip.ID, DedicatedIPDeprovisioning, DedicatedIPDeleted, nil, nil); err != nil {
+			s.logger.Warn("Failed to mark dedicated IP deleted", zap.String("id", ip.ID.String()), zap.Error(err))
+			return
+		}
+		s.logger.Info("Dedicated IP released", zap.String("id", ip.ID.String()), zap.Stringp("ip", ip.IPAddress))
+	}
+}
+
+// recordDedicatedIPAttempt counts a provider step that did not complete.
+// PROVISIONING fails after maxDedicatedIPAttempts; DEPROVISIONING retries
+// indefinitely since the address must not leak.
+func (s *Service) recordDedicatedIPAttempt(ctx context.Context, ip models.CustomerDedicatedIP, stepErr error) {
+	var lastError *string
+	if stepErr != nil {
+		msg := stepErr.Error()
+		lastError = &msg
+		s.logger.Warn("Dedicated IP provider step failed",
+			zap.String("id", ip.ID.String()),
+			zap.String("status", ip.Status),
+			zap.Error(stepErr),
+		)
+	}
+
+	if ip.Status == DedicatedIPProvisioning && ip.Attempts+1 >= maxDedicatedIPAttempts {
+		if lastError == nil {
+			msg := "timed out waiting for the provider to allocate the address"
+			lastError = &msg
+		}
+		if _, err := s.repo.TransitionDedicatedIP(ctx, ip.ID, DedicatedIPProvisioning, DedicatedIPFailed, nil, lastError); err != nil {
+			s.logger.Warn("Failed to mark dedicated IP failed", zap.String("id", ip.ID.String()), zap.Error(err))
+		}
+		s.logger.Error("Dedicated IP provisioning failed", zap.String("id", ip.ID.String()), zap.Stringp("error", lastError))
+		return
+	}
+
+	if err := s.repo.RecordDedicatedIPAttempt(ctx, ip.ID, lastError); err != nil {
+		s.logger.Warn("Failed to record dedicated IP attempt", zap.String("id", ip.ID.String()), zap.Error(err))
+	}
+}
+
+// syncDedicatedIPMappings rewrites the Redis mapping of every ACTIVE dedicated IP
+func (s *Service) syncDedicatedIPMappings(ctx context.Context) {
+	mappings, err := s.repo.ListActiveDedicatedIPs(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to list active dedicated IPs", zap.Error(err))
+		return
+	}
+	if len(mappings) == 0 {
+		return
+	}
+
+	pipe := s.redisClient.Pipeline()
+	for ip, ban := range mappings {
+		pipe.Set(ctx, dedicatedIPKey(ip), ban, 0) // No expiration
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Warn("Failed to sync dedicated IP mappings", zap.Error(err))
+	}
+}
+
+// getDedicatedIPForCustomer loads a dedicated IP, verifying it belongs to the customer
+func (s *Service) getDedicatedIPForCustomer(ctx context.Context, id, customerID uuid.UUID) (*models.CustomerDedicatedIP, error) {
+	ip, err := s.repo.GetDedicatedIP(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ip == nil || ip.CustomerID != customerID {
+		return nil, ErrDedicatedIPNotFound
+	}
+	return ip, nil
+}
+
+// dedicatedAddressName returns a provider resource name for a dedicated IP:
+// lower-case letters, digits and dashes, starting with a letter, at most 63 chars
+func dedicatedAddressName(ban string, id uuid.UUID) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '-'
+		}
+	}, ban)
+	slug = strings.Trim(slug, "-")
+	if len(slug) > 30 {
+		slug = slug[:30]
+	}
+	return fmt.Sprintf("warp-ded-%s-%s", slug, id.String()[:8])
+}
+
+// dedicatedIPKey returns the Redis key mapping a dedicated IP to a customer BAN
+func dedicatedIPKey(ipAddress string) string {
+	return fmt.Sprintf("customer:dedicated_ip:%s", ipAddress)
+}
@@ -0,0 +1,204 @@
+package trunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/ringer-warp/api-gateway/internal/cloudip"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/repository"
+	"go.uber.org/zap"
+)
+
+// dedicatedIPTest is a trunk Service on the test database and Redis (named by
+// TEST_DATABASE_URL and TEST_REDIS_URL) with a fake address provider, and a
+// customer and user removed when the test ends
+type dedicatedIPTest struct {
+	s          *Service
+	provider   *cloudip.FakeProvider
+	redis      *redis.Client
+	customerID uuid.UUID
+	ban        string
+	userID     uuid.UUID
+}
+
+func newDedicatedIPTest(t *testing.T) *dedicatedIPTest {
+	t.Helper()
+	ctx := context.Background()
+
+	dbURL, redisURL := os.Getenv("TEST_DATABASE_URL"), os.Getenv("TEST_REDIS_URL")
+	if dbURL == "" || redisURL == "" {
+		t.Skip("TEST_DATABASE_URL and TEST_REDIS_URL not set")
+	}
+
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		t.Fatalf("invalid TEST_REDIS_URL: %v", err)
+	}
+	redisClient := redis.NewClient(opts)
+	t.Cleanup(func() { redisClient.Close() })
+
+	dt := &dedicatedIPTest{
+		s:        NewService(repository.NewTrunkRepository(pool), repository.NewCustomerRepository(pool), redisClient, zap.NewNop()),
+		provider: cloudip.NewFakeProvider(),
+		redis:    redisClient,
+		ban:      fmt.Sprintf("TEST%08d", rand.Intn(1e8)),
+	}
+	dt.s.SetAddressProvider(dt.provider)
+
+	err = pool.QueryRow(ctx, `
+		INSERT INTO accounts.customers (ban, company_name, customer_type)
+		VALUES ($1, $2, 'POSTPAID')
+		RETURNING id`, dt.ban, "Test "+t.Name()).Scan(&dt.customerID)
+	if err != nil {
+		t.Fatalf("failed to create test customer: %v", err)
+	}
+	err = pool.QueryRow(ctx, `
+		INSERT INTO auth.users (firebase_uid, email, user_type_id)
+		SELECT $1, $1 || '@test.invalid', id FROM auth.user_types WHERE type_name = 'superAdmin'
+		RETURNING id`, "test-"+dt.ban).Scan(&dt.userID)
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	t.Cleanup(func() {
+		// Dedicated IPs are removed with the customer
+		if _, err := pool.Exec(ctx, `DELETE FROM accounts.customers WHERE id = $1`, dt.customerID); err != nil {
+			t.Errorf("failed to remove test customer: %v", err)
+		}
+		if _, err := pool.Exec(ctx, `DELETE FROM auth.users WHERE id = $1`, dt.userID); err != nil {
+			t.Errorf("failed to remove test user: %v", err)
+		}
+	})
+	return dt
+}
+
+// advance runs worker steps on a dedicated IP until it leaves status, and
+// returns it
+func (dt *dedicatedIPTest) advance(t *testing.T, id uuid.UUID, status string) *models.CustomerDedicatedIP {
+	t.Helper()
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		ip, err := dt.s.GetDedicatedIP(ctx, id, dt.customerID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ip.Status != status {
+			return ip
+		}
+		dt.s.advanceDedicatedIP(ctx, *ip)
+	}
+	t.Fatalf("dedicated IP still %s", status)
+	return nil
+}
+
+// mapping returns the BAN the SIP edge attributes ip to ("" if none)
+func (dt *dedicatedIPTest) mapping(t *testing.T, ip string) string {
+	t.Helper()
+
+	ban, err := dt.redis.Get(context.Background(), dedicatedIPKey(ip)).Result()
+	if errors.Is(err, redis.Nil) {
+		return ""
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ban
+}
+
+func TestDedicatedIPLifecycle(t *testing.T) {
+	dt := newDedicatedIPTest(t)
+	dt.provider.PendingPolls = 1
+	ctx := context.Background()
+
+	requested, err := dt.s.RequestDedicatedIP(ctx, dt.customerID, models.RequestDedicatedIPRequest{}, dt.userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requested.Status != DedicatedIPProvisioning || requested.Region != DefaultDedicatedIPRegion {
+		t.Errorf("requested = %s in %s, want PROVISIONING in %s", requested.Status, requested.Region, DefaultDedicatedIPRegion)
+	}
+
+	if _, err := dt.s.RequestDedicatedIP(ctx, dt.customerID, models.RequestDedicatedIPRequest{}, dt.userID); !errors.Is(err, ErrDedicatedIPExists) {
+		t.Errorf("second request error = %v, want ErrDedicatedIPExists", err)
+	}
+	if _, err := dt.s.GetDedicatedIP(ctx, requested.ID, uuid.New()); !errors.Is(err, ErrDedicatedIPNotFound) {
+		t.Errorf("other customer's lookup error = %v, want ErrDedicatedIPNotFound", err)
+	}
+
+	active := dt.advance(t, requested.ID, DedicatedIPProvisioning)
+	if active.Status != DedicatedIPActive || active.IPAddress == nil {
+		t.Fatalf("dedicated IP = %s (%v), want ACTIVE with an address", active.Status, active.IPAddress)
+	}
+	address := *active.IPAddress
+	t.Cleanup(func() { dt.redis.Del(context.Background(), dedicatedIPKey(address)) })
+
+	if ban := dt.mapping(t, address); ban != dt.ban {
+		t.Errorf("mapping for %s = %q, want %q", address, ban, dt.ban)
+	}
+
+	deleted, err := dt.s.DeprovisionDedicatedIP(ctx, requested.ID, dt.customerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted.Status != DedicatedIPDeleted {
+		t.Errorf("after deprovisioning = %s, want DELETED", deleted.Status)
+	}
+	if ban := dt.mapping(t, address); ban != "" {
+		t.Errorf("mapping for %s = %q after deprovisioning, want none", address, ban)
+	}
+	if _, err := dt.provider.Get(ctx, *requested.GCPAddressName, requested.Region); !errors.Is(err, cloudip.ErrNotFound) {
+		t.Errorf("provider address after deprovisioning: %v, want ErrNotFound", err)
+	}
+
+	if _, err := dt.s.DeprovisionDedicatedIP(ctx, requested.ID, dt.customerID); !errors.Is(err, ErrDedicatedIPInvalidState) {
+		t.Errorf("second deprovision error = %v, want ErrDedicatedIPInvalidState", err)
+	}
+}
+
+func TestDedicatedIPProvisioningFails(t *testing.T) {
+	dt := newDedicatedIPTest(t)
+	dt.provider.FailReserve = errors.New("quota exceeded")
+	ctx := context.Background()
+
+	requested, err := dt.s.RequestDedicatedIP(ctx, dt.customerID, models.RequestDedicatedIPRequest{Region: "us-east4"}, dt.userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requested.Status != DedicatedIPProvisioning || requested.Attempts != 1 || requested.LastError == nil {
+		t.Errorf("requested = %s after %d attempts (error %v), want PROVISIONING after 1 with the error",
+			requested.Status, requested.Attempts, requested.LastError)
+	}
+
+	// The last attempt gives up
+	requested.Attempts = maxDedicatedIPAttempts - 1
+	dt.s.advanceDedicatedIP(ctx, *requested)
+
+	failed, err := dt.s.GetDedicatedIP(ctx, requested.ID, dt.customerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed.Status != DedicatedIPFailed || failed.LastError == nil {
+		t.Errorf("dedicated IP = %s (error %v), want FAILED with the error", failed.Status, failed.LastError)
+	}
+
+	// A failed request does not block another in the region
+	dt.provider.FailReserve = nil
+	if _, err := dt.s.RequestDedicatedIP(ctx, dt.customerID, models.RequestDedicatedIPRequest{Region: "us-east4"}, dt.userID); err != nil {
+		t.Errorf("request after failure: %v", err)
+	}
+}
@@ -0,0 +1,311 @@
+package trunk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/rediskeys"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Trunk capacity limits published for the SIP edge:
+//
+//	trunk:limits:{trunk_id}        -> {customer_ban, cps, concurrent_calls}
+//	customer:ban:{ban}:limits      -> {cps, concurrent_calls, trunks} (sum of enabled trunks)
+//
+// Disabled or deleted trunks have no limits entry, and a BAN without enabled
+// trunks has no aggregate entry.
+//
+// Live counters maintained by the SIP edge and read back for utilisation:
+//
+//	trunk:calls:{trunk_id}         concurrent calls (INCR on dialog start, DECR on end)
+//	customer:ban:{ban}:calls       concurrent calls across the BAN
+//	trunk:cps:{trunk_id}:{unix}    INVITEs in that second (INCR, EXPIRE a few seconds)
+//	customer:ban:{ban}:cps:{unix}  INVITEs in that second across the BAN
+const (
+	trunkLimitsPrefix = "trunk:limits:"
+	trunkCallsPrefix  = "trunk:calls:"
+	trunkCPSPrefix    = "trunk:cps:"
+)
+
+// utilizationWindow is how many complete seconds of CPS buckets are sampled
+const utilizationWindow = 5
+
+func trunkLimitsKey(trunkID uuid.UUID) string {
+	return trunkLimitsPrefix + trunkID.String()
+}
+
+func customerLimitsKey(ban string) string {
+	return fmt.Sprintf("customer:ban:%s:limits", ban)
+}
+
+func trunkCallsKey(trunkID uuid.UUID) string {
+	return trunkCallsPrefix + trunkID.String()
+}
+
+func customerCallsKey(ban string) string {
+	return fmt.Sprintf("customer:ban:%s:calls", ban)
+}
+
+func trunkCPSKey(trunkID uuid.UUID, second int64) string {
+	return fmt.Sprintf("%s%s:%d", trunkCPSPrefix, trunkID, second)
+}
+
+func customerCPSKey(ban string, second int64) string {
+	return fmt.Sprintf("customer:ban:%s:cps:%d", ban, second)
+}
+
+// ============================================================================
+// Limit Publishing
+// ============================================================================
+
+// SyncTrunkLimits republishes the limits of every trunk group of a customer,
+// and the customer's aggregate, in one MULTI/EXEC transaction
+func (s *Service) SyncTrunkLimits(ctx context.Context, customerID uuid.UUID) error {
+	customer, err := s.getCustomer(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	trunks, err := s.repo.ListTrunkGroupsByCustomer(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	return s.publishTrunkLimits(ctx, customer, trunks)
+}
+
+// publishTrunkLimits writes the limits of a customer's trunk groups and its
+// aggregate in one MULTI/EXEC transaction
+func (s *Service) publishTrunkLimits(ctx context.Context, customer *models.Customer, trunks []models.TrunkGroup) error {
+	pipe := s.redisClient.TxPipeline()
+	totalCPS, totalCalls, enabled := 0, 0, 0
+	for _, trunk := range trunks {
+		key := trunkLimitsKey(trunk.ID)
+		if !trunk.Enabled {
+			pipe.Del(ctx, key)
+			continue
+		}
+		pipe.HSet(ctx, key, map[string]interface{}{
+			"customer_ban":     customer.BAN,
+			"cps":              strconv.Itoa(trunk.CapacityCPS),
+			"concurrent_calls": strconv.Itoa(trunk.CapacityConcurrentCalls),
+		})
+		totalCPS += trunk.CapacityCPS
+		totalCalls += trunk.CapacityConcurrentCalls
+		enabled++
+	}
+
+	banKey := customerLimitsKey(customer.BAN)
+	pipe.Del(ctx, banKey)
+	if enabled > 0 {
+		pipe.HSet(ctx, banKey, map[string]interface{}{
+			"cps":              strconv.Itoa(totalCPS),
+			"concurrent_calls": strconv.Itoa(totalCalls),
+			"trunks":           strconv.Itoa(enabled),
+		})
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to sync trunk limits to Redis: %w", err)
+	}
+	return nil
+}
+
+// ReconcileTrunkLimits republishes the limits of every customer with trunk
+// groups and removes trunk:limits entries of trunks that are gone or disabled.
+// Limits written before the gateway published them, or lost with Redis, are
+// backfilled this way.
+func (s *Service) ReconcileTrunkLimits(ctx context.Context) error {
+	customerIDs, err := s.repo.ListTrunkCustomerIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	enabled := make(map[string]bool)
+	failed := false
+	for _, customerID := range customerIDs {
+		trunks, err := s.repo.ListTrunkGroupsByCustomer(ctx, customerID)
+		if err != nil {
+			return err
+		}
+		for _, trunk := range trunks {
+			if trunk.Enabled {
+				enabled[trunkLimitsKey(trunk.ID)] = true
+			}
+		}
+
+		customer, err := s.getCustomer(ctx, customerID)
+		if err == nil {
+			err = s.publishTrunkLimits(ctx, customer, trunks)
+		}
+		if err != nil {
+			failed = true
+			s.logger.Warn("Failed to reconcile trunk limits", zap.String("customer_id", customerID.String()), zap.Error(err))
+		}
+	}
+
+	if err := s.removeStaleTrunkLimits(ctx, enabled); err != nil {
+		return err
+	}
+
+	if failed {
+		return fmt.Errorf("failed to reconcile trunk limits for some customers")
+	}
+	return nil
+}
+
+// removeStaleTrunkLimits deletes the trunk:limits entries whose key is not
+// in enabled
+func (s *Service) removeStaleTrunkLimits(ctx context.Context, enabled map[string]bool) error {
+	keys, err := rediskeys.Scan(ctx, s.redisClient, trunkLimitsPrefix+"*", reconcileScanCount)
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for _, key := range keys {
+		if !enabled[key] {
+			stale = append(stale, key)
+		}
+	}
+	if len(stale) > 0 {
+		if err := s.redisClient.Del(ctx, stale...).Err(); err != nil {
+			return fmt.Errorf("failed to remove stale trunk limits: %w", err)
+		}
+	}
+	return nil
+}
+
+// refreshTrunkLimits syncs a customer's limits, logging instead of failing
+// the trunk operation that triggered it
+func (s *Service) refreshTrunkLimits(ctx context.Context, customerID uuid.UUID) {
+	if err := s.SyncTrunkLimits(ctx, customerID); err != nil {
+		s.logger.Warn("Failed to sync trunk limits to Redis", zap.String("customer_id", customerID.String()), zap.Error(err))
+	}
+}
+
+// removeTrunkLimits drops a deleted trunk's limits and refreshes the
+// customer's aggregate
+func (s *Service) removeTrunkLimits(ctx context.Context, trunkID, customerID uuid.UUID) {
+	if err := s.redisClient.Del(ctx, trunkLimitsKey(trunkID)).Err(); err != nil {
+		s.logger.Warn("Failed to remove trunk limits from Redis", zap.String("trunk_id", trunkID.String()), zap.Error(err))
+	}
+	s.refreshTrunkLimits(ctx, customerID)
+}
+
+// ============================================================================
+// Utilisation
+// ============================================================================
+
+// GetTrunkUtilization reports a customer's limits and the live usage counted
+// by the SIP edge, in aggregate and per trunk group
+func (s *Service) GetTrunkUtilization(ctx context.Context, customerID uuid.UUID) (*models.CustomerTrunkUtilization, error) {
+	customer, err := s.getCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	trunks, err := s.repo.ListTrunkGroupsByCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	report := &models.CustomerTrunkUtilization{
+		CustomerBAN:   customer.BAN,
+		WindowSeconds: utilizationWindow,
+		SampledAt:     now,
+		Trunks:        make([]models.TrunkUtilization, 0, len(trunks)),
+	}
+
+	pipe := s.redisClient.Pipeline()
+	banUsage := queueUsage(ctx, pipe, customerCallsKey(customer.BAN), now, func(sec int64) string {
+		return customerCPSKey(customer.BAN, sec)
+	})
+	trunkUsage := make([]usageCmds, len(trunks))
+	for i, trunk := range trunks {
+		trunkID := trunk.ID
+		trunkUsage[i] = queueUsage(ctx, pipe, trunkCallsKey(trunkID), now, func(sec int64) string {
+			return trunkCPSKey(trunkID, sec)
+		})
+	}
+	// Missing counters (redis.Nil) simply mean no traffic
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to read trunk usage counters: %w", err)
+	}
+
+	for i, trunk := range trunks {
+		usage := models.TrunkUtilization{
+			TrunkGroupID:            trunk.ID,
+			Name:                    trunk.Name,
+			Enabled:                 trunk.Enabled,
+			CapacityCPS:             trunk.CapacityCPS,
+			CapacityConcurrentCalls: trunk.CapacityConcurrentCalls,
+		}
+		usage.ActiveCalls, usage.CurrentCPS, usage.PeakCPS = trunkUsage[i].values()
+		usage.CallsUtilizationPct = utilizationPct(usage.ActiveCalls, usage.CapacityConcurrentCalls)
+		usage.CPSUtilizationPct = utilizationPct(usage.CurrentCPS, usage.CapacityCPS)
+		report.Trunks = append(report.Trunks, usage)
+
+		if trunk.Enabled {
+			report.CapacityCPS += trunk.CapacityCPS
+			report.CapacityConcurrentCalls += trunk.CapacityConcurrentCalls
+		}
+	}
+
+	report.ActiveCalls, report.CurrentCPS, report.PeakCPS = banUsage.values()
+	report.CallsUtilizationPct = utilizationPct(report.ActiveCalls, report.CapacityConcurrentCalls)
+	report.CPSUtilizationPct = utilizationPct(report.CurrentCPS, report.CapacityCPS)
+
+	return report, nil
+}
+
+// usageCmds holds the queued reads of one calls counter and its CPS buckets,
+// newest second first
+type usageCmds struct {
+	calls *redis.StringCmd
+	cps   []*redis.StringCmd
+}
+
+// queueUsage queues reads of a calls counter and the last utilizationWindow
+// complete seconds of CPS buckets. The current second is still being counted
+// and is skipped.
+func queueUsage(ctx context.Context, pipe redis.Pipeliner, callsKey string, now time.Time, cpsKey func(int64) string) usageCmds {
+	cmds := usageCmds{
+		calls: pipe.Get(ctx, callsKey),
+		cps:   make([]*redis.StringCmd, 0, utilizationWindow),
+	}
+	for i := int64(1); i <= utilizationWindow; i++ {
+		cmds.cps = append(cmds.cps, pipe.Get(ctx, cpsKey(now.Unix()-i)))
+	}
+	return cmds
+}
+
+// values returns active calls, the last complete second's CPS and the peak CPS
+func (u usageCmds) values() (calls, current, peak int64) {
+	calls, _ = u.calls.Int64()
+	if calls < 0 {
+		calls = 0 // Counter skew after an edge restart
+	}
+	for i, cmd := range u.cps {
+		n, _ := cmd.Int64()
+		if i == 0 {
+			current = n
+		}
+		if n > peak {
+			peak = n
+		}
+	}
+	return calls, current, peak
+}
+
+// utilizationPct returns used/capacity as a percentage rounded to 0.1
+func utilizationPct(used int64, capacity int) float64 {
+	if capacity <= 0 {
+		return 0
+	}
+	return math.Round(float64(used)*1000/float64(capacity)) / 10
+}
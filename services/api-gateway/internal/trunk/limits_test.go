@@ -0,0 +1,76 @@
+package trunk
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ringer-warp/api-gateway/internal/models"
+)
+
+func TestPublishTrunkLimits(t *testing.T) {
+	s, mr := newRedisTestService(t)
+	ctx := context.Background()
+	customer := &models.Customer{BAN: "BAN1"}
+
+	first := models.TrunkGroup{ID: uuid.New(), Enabled: true, CapacityCPS: 10, CapacityConcurrentCalls: 100}
+	second := models.TrunkGroup{ID: uuid.New(), Enabled: true, CapacityCPS: 5, CapacityConcurrentCalls: 20}
+	disabled := models.TrunkGroup{ID: uuid.New(), Enabled: false, CapacityCPS: 50, CapacityConcurrentCalls: 500}
+
+	// The disabled trunk was published before it was disabled
+	mr.HSet(trunkLimitsKey(disabled.ID), "cps", "50")
+
+	if err := s.publishTrunkLimits(ctx, customer, []models.TrunkGroup{first, second, disabled}); err != nil {
+		t.Fatal(err)
+	}
+
+	wantFirst := map[string]string{"customer_ban": "BAN1", "cps": "10", "concurrent_calls": "100"}
+	if got := hashOf(t, mr, trunkLimitsKey(first.ID)); !reflect.DeepEqual(got, wantFirst) {
+		t.Errorf("first trunk limits = %v, want %v", got, wantFirst)
+	}
+	if hashOf(t, mr, trunkLimitsKey(disabled.ID)) != nil {
+		t.Error("disabled trunk still has limits")
+	}
+	wantBAN := map[string]string{"cps": "15", "concurrent_calls": "120", "trunks": "2"}
+	if got := hashOf(t, mr, customerLimitsKey("BAN1")); !reflect.DeepEqual(got, wantBAN) {
+		t.Errorf("customer limits = %v, want %v", got, wantBAN)
+	}
+
+	// Without enabled trunks the aggregate goes too
+	first.Enabled, second.Enabled = false, false
+	if err := s.publishTrunkLimits(ctx, customer, []models.TrunkGroup{first, second, disabled}); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{trunkLimitsKey(first.ID), trunkLimitsKey(second.ID), customerLimitsKey("BAN1")} {
+		if mr.Exists(key) {
+			t.Errorf("%s still published", key)
+		}
+	}
+}
+
+func TestRemoveStaleTrunkLimits(t *testing.T) {
+	s, mr := newRedisTestService(t)
+
+	live := trunkLimitsKey(uuid.New())
+	stale := []string{trunkLimitsKey(uuid.New()), trunkLimitsKey(uuid.New())}
+	for _, key := range append(stale, live) {
+		mr.HSet(key, "cps", "1")
+	}
+	// Live counters share the trunk: prefix but are not limits
+	calls := trunkCallsKey(uuid.New())
+	mr.Set(calls, "3")
+
+	if err := s.removeStaleTrunkLimits(context.Background(), map[string]bool{live: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !mr.Exists(live) || !mr.Exists(calls) {
+		t.Error("enabled trunk limits or call counter removed")
+	}
+	for _, key := range stale {
+		if mr.Exists(key) {
+			t.Errorf("stale %s not removed", key)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package trunk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/rediskeys"
+	"github.com/ringer-warp/api-gateway/internal/worker"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Kamailio permissions address table layout in Redis
+const (
+	addressEntryPrefix = "address:entry:"
+	addressGroup       = "100" // Group 100 = customer trunks
+	addressGroupKey    = "address:group:" + addressGroup
+)
+
+// Reconcile triggers recorded in the report
+const (
+	ReconcileTriggerManual   = "manual"
+	ReconcileTriggerPeriodic = "periodic"
+)
+
+// reconcileScanCount is the SCAN batch size used when reading the address table
+const reconcileScanCount = 500
+
+// addressEntryKey returns the Redis key of a trunk IP's address entry
+func addressEntryKey(ipID uuid.UUID) string {
+	return addressEntryPrefix + ipID.String()
+}
+
+// addressFields returns the hash stored for a trunk IP.
+// Kamailio permissions module expects these exact field names.
+func addressFields(ipAddress string, netmask, port int, transport, customerBAN string) map[string]interface{} {
+	return map[string]interface{}{
+		"grp":          addressGroup,
+		"ip_addr":      ipAddress,
+		"mask":         fmt.Sprintf("%d", netmask),
+		"port":         fmt.Sprintf("%d", port), // SIP source port (0 = any)
+		"proto":        transport,               // any, udp, tcp, tls, sctp
+		"pattern":      "",                      // Not used
+		"context_info": "",                      // Not used
+		"tag":          customerBAN,             // Customer BAN (returned in $avp(peer_tag))
+	}
+}
+
+// ReconcileAddresses makes the Kamailio address table in Redis match the
+// enabled trunk IPs in Postgres for all customers: missing entries are added,
+// changed entries rewritten and stale entries removed, in one MULTI/EXEC
+// transaction. The auth_db subscriber table is then reconciled against the
+// active digest credentials the same way. With dryRun the drift is reported
+// but nothing is changed.
+//
+// Only entries in the customer trunk group are managed here; address entries
+// of other groups (carriers, internal peers) are provisioned elsewhere and
+// are never touched.
+//
+// Redis is read before Postgres so an IP added concurrently is never removed;
+// an IP deleted concurrently may be re-added and is removed on the next run.
+func (s *Service) ReconcileAddresses(ctx context.Context, trigger string, dryRun bool) (*models.AddressReconcileReport, error) {
+	s.reconcileMu.Lock()
+	defer s.reconcileMu.Unlock()
+
+	report := &models.AddressReconcileReport{
+		StartedAt: time.Now().UTC(),
+		Trigger:   trigger,
+		DryRun:    dryRun,
+	}
+
+	err := s.reconcileAddresses(ctx, report)
+	if err == nil {
+		err = s.reconcileSubscribers(ctx, report)
+	}
+
+	report.CompletedAt = time.Now().UTC()
+	report.DurationMs = report.CompletedAt.Sub(report.StartedAt).Milliseconds()
+	report.Drift = report.Added + report.Updated + report.Removed +
+		report.SubscribersAdded + report.SubscribersUpdated + report.SubscribersRemoved
+	if err != nil {
+		report.Error = err.Error()
+	}
+	s.lastReport = report
+
+	fields := []zap.Field{
+		zap.String("trigger", trigger),
+		zap.Bool("dry_run", dryRun),
+		zap.Int("desired", report.Desired),
+		zap.Int("existing", report.Existing),
+		zap.Int("added", report.Added),
+		zap.Int("updated", report.Updated),
+		zap.Int("removed", report.Removed),
+		zap.Int("group_members_fixed", report.GroupMembersFixed),
+		zap.Int("subscribers_added", report.SubscribersAdded),
+		zap.Int("subscribers_updated", report.SubscribersUpdated),
+		zap.Int("subscribers_removed", report.SubscribersRemoved),
+		zap.Int64("duration_ms", report.DurationMs),
+	}
+	switch {
+	case err != nil:
+		s.logger.Error("Kamailio address reconciliation failed", append(fields, zap.Error(err))...)
+	case report.Drift > 0 || report.GroupMembersFixed > 0:
+		s.logger.Warn("Kamailio address table drift detected", fields...)
+	default:
+		s.logger.Debug("Kamailio address table in sync", fields...)
+	}
+
+	return report, err
+}
+
+// LastAddressReconcileReport returns the report of the most recent
+// reconciliation, or nil if none has run yet
+func (s *Service) LastAddressReconcileReport() *models.AddressReconcileReport {
+	s.reconcileMu.Lock()
+	defer s.reconcileMu.Unlock()
+
+	if s.lastReport == nil {
+		return nil
+	}
+	report := *s.lastReport
+	return &report
+}
+
+// StartAddressReconciler reconciles the address table and the trunk limits
+// immediately and then every interval until ctx is cancelled
+func (s *Service) StartAddressReconciler(ctx context.Context, interval time.Duration) {
+	worker.NowAndEvery(ctx, interval, nil, func(ctx context.Context) {
+		s.ReconcileAddresses(ctx, ReconcileTriggerPeriodic, false)
+		if err := s.ReconcileTrunkLimits(ctx); err != nil {
+			s.logger.Warn("Trunk limits reconciliation failed", zap.Error(err))
+		}
+	})
+}
+
+// reconcileAddresses fills in report and applies the diff against the
+// enabled trunk IPs in Postgres
+func (s *Service) reconcileAddresses(ctx context.Context, report *models.AddressReconcileReport) error {
+	return s.syncAddressTable(ctx, report, s.repo.ListEnabledTrunkAddresses)
+}
+
+// syncAddressTable diffs the address table in Redis against the entries
+// returned by listEntries, which is called only once Redis has been read
+func (s *Service) syncAddressTable(
+	ctx context.Context,
+	report *models.AddressReconcileReport,
+	listEntries func(context.Context) ([]models.TrunkAddressEntry, error),
+) error {
+	// Current state: every address entry hash plus the group set
+	entries, err := s.loadHashes(ctx, addressEntryPrefix+"*")
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]map[string]string, len(entries))
+	foreign := make(map[string]bool)
+	for key, fields := range entries {
+		if fields["grp"] == addressGroup {
+			existing[key] = fields
+		} else {
+			foreign[key] = true
+		}
+	}
+	groupMembers, err := s.redisClient.SMembers(ctx, addressGroupKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to read address group: %w", err)
+	}
+	inGroup := make(map[string]bool, len(groupMembers))
+	for _, member := range groupMembers {
+		inGroup[member] = true
+	}
+	report.Existing = len(existing)
+
+	// Desired state from Postgres
+	trunkAddresses, err := listEntries(ctx)
+	if err != nil {
+		return err
+	}
+	desired := make(map[string]map[string]interface{}, len(trunkAddresses))
+	for _, entry := range trunkAddresses {
+		desired[addressEntryKey(entry.IPID)] = addressFields(entry.IPAddress, entry.Netmask, entry.Port, entry.Transport, entry.CustomerBAN)
+	}
+	report.Desired = len(desired)
+
+	diff := rediskeys.Compare(existing, desired, fieldsEqual)
+	report.Added = len(diff.Added)
+	report.Updated = len(diff.Updated)
+	report.Unchanged = len(diff.Unchanged)
+	report.Removed = len(diff.Removed)
+	report.RemovedKeys = diff.Removed
+
+	pipe := s.redisClient.TxPipeline()
+	queued := 0
+
+	for _, key := range diff.Added {
+		pipe.HSet(ctx, key, desired[key])
+		pipe.SAdd(ctx, addressGroupKey, key)
+		queued += 2
+	}
+	for _, key := range diff.Updated {
+		// Delete first so fields no longer written are dropped too
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, desired[key])
+		queued += 2
+	}
+	for _, key := range append(diff.Updated, diff.Unchanged...) {
+		if !inGroup[key] {
+			report.GroupMembersFixed++
+			pipe.SAdd(ctx, addressGroupKey, key)
+			queued++
+		}
+	}
+	for _, key := range diff.Removed {
+		pipe.Del(ctx, key)
+		pipe.SRem(ctx, addressGroupKey, key)
+		queued += 2
+	}
+
+	// Group members whose entry no longer exists at all
+	for member := range inGroup {
+		if _, ok := desired[member]; ok {
+			continue
+		}
+		if _, ok := existing[member]; ok {
+			continue // Removed above
+		}
+		if foreign[member] {
+			continue // Not ours to remove
+		}
+		report.GroupMembersFixed++
+		pipe.SRem(ctx, addressGroupKey, member)
+		queued++
+	}
+
+	if report.DryRun || queued == 0 {
+		pipe.Discard()
+		return nil
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to apply address table changes: %w", err)
+	}
+
+	return nil
+}
+
+// loadHashes reads every hash whose key matches pattern from Redis
+func (s *Service) loadHashes(ctx context.Context, pattern string) (map[string]map[string]string, error) {
+	keys, err := rediskeys.Scan(ctx, s.redisClient, pattern, reconcileScanCount)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]map[string]string, len(keys))
+	for _, batch := range rediskeys.Batches(keys, reconcileScanCount) {
+		pipe := s.redisClient.Pipeline()
+		cmds := make([]*redis.MapStringStringCmd, 0, len(batch))
+		for _, key := range batch {
+			cmds = append(cmds, pipe.HGetAll(ctx, key))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", pattern, err)
+		}
+
+		for i, cmd := range cmds {
+			// A key deleted since the scan returns an empty hash
+			if len(cmd.Val()) > 0 {
+				entries[batch[i]] = cmd.Val()
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// fieldsEqual reports whether a stored hash matches the desired fields exactly
+func fieldsEqual(current map[string]string, desired map[string]interface{}) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	for field, value := range desired {
+		if current[field] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package trunk
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"go.uber.org/zap"
+)
+
+// newRedisTestService creates a Service on an in-memory Redis. It has no
+// repositories, so only code paths that take their Postgres state as
+// arguments can run on it.
+func newRedisTestService(t *testing.T) (*Service, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewService(nil, nil, client, zap.NewNop()), mr
+}
+
+// hashOf returns the hash stored at key, or nil if there is none
+func hashOf(t *testing.T, mr *miniredis.Miniredis, key string) map[string]string {
+	t.Helper()
+
+	if !mr.Exists(key) {
+		return nil
+	}
+	fields, err := mr.HKeys(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := make(map[string]string, len(fields))
+	for _, field := range fields {
+		hash[field] = mr.HGet(key, field)
+	}
+	return hash
+}
+
+// seedHash stores fields as the hash at key
+func seedHash(mr *miniredis.Miniredis, key string, fields map[string]interface{}) {
+	for field, value := range fields {
+		mr.HSet(key, field, value.(string))
+	}
+}
+
+// stringFields converts desired hash fields to their stored form
+func stringFields(fields map[string]interface{}) map[string]string {
+	hash := make(map[string]string, len(fields))
+	for field, value := range fields {
+		hash[field] = value.(string)
+	}
+	return hash
+}
+
+func TestSyncAddressTable(t *testing.T) {
+	s, mr := newRedisTestService(t)
+	ctx := context.Background()
+
+	added := models.TrunkAddressEntry{IPID: uuid.New(), CustomerBAN: "BAN1", IPAddress: "203.0.113.10", Netmask: 32, Transport: "udp"}
+	changed := models.TrunkAddressEntry{IPID: uuid.New(), CustomerBAN: "BAN1", IPAddress: "203.0.113.0", Netmask: 24, Port: 5060, Transport: "tcp"}
+	ungrouped := models.TrunkAddressEntry{IPID: uuid.New(), CustomerBAN: "BAN2", IPAddress: "198.51.100.7", Netmask: 32, Transport: "any"}
+	entries := []models.TrunkAddressEntry{added, changed, ungrouped}
+
+	fieldsOf := func(e models.TrunkAddressEntry) map[string]interface{} {
+		return addressFields(e.IPAddress, e.Netmask, e.Port, e.Transport, e.CustomerBAN)
+	}
+
+	// changed: stale fields plus one no longer written
+	stale := fieldsOf(changed)
+	stale["ip_addr"] = "203.0.113.99"
+	stale["legacy"] = "x"
+	seedHash(mr, addressEntryKey(changed.IPID), stale)
+	mr.SAdd(addressGroupKey, addressEntryKey(changed.IPID))
+
+	// ungrouped: correct entry missing from the group set
+	seedHash(mr, addressEntryKey(ungrouped.IPID), fieldsOf(ungrouped))
+
+	// removed: a deleted trunk IP still published
+	removedKey := addressEntryKey(uuid.New())
+	seedHash(mr, removedKey, addressFields("192.0.2.1", 32, 0, "udp", "BAN3"))
+	mr.SAdd(addressGroupKey, removedKey)
+
+	// orphan: a group member without an entry
+	orphanKey := addressEntryKey(uuid.New())
+	mr.SAdd(addressGroupKey, orphanKey)
+
+	// foreign: a carrier entry in another group
+	foreignKey := addressEntryKey(uuid.New())
+	foreign := map[string]interface{}{"grp": "200", "ip_addr": "192.0.2.200", "mask": "32"}
+	seedHash(mr, foreignKey, foreign)
+	mr.SAdd("address:group:200", foreignKey)
+
+	list := func(context.Context) ([]models.TrunkAddressEntry, error) { return entries, nil }
+	want := models.AddressReconcileReport{
+		Desired:           3,
+		Existing:          3,
+		Added:             1,
+		Updated:           1,
+		Removed:           1,
+		Unchanged:         1,
+		GroupMembersFixed: 2,
+		RemovedKeys:       []string{removedKey},
+	}
+	counts := func(r *models.AddressReconcileReport) models.AddressReconcileReport {
+		return models.AddressReconcileReport{
+			Desired: r.Desired, Existing: r.Existing, Added: r.Added, Updated: r.Updated,
+			Removed: r.Removed, Unchanged: r.Unchanged, GroupMembersFixed: r.GroupMembersFixed,
+			RemovedKeys: r.RemovedKeys,
+		}
+	}
+
+	// A dry run reports the drift without changing anything
+	before := mr.Dump()
+	dryRun := &models.AddressReconcileReport{DryRun: true}
+	if err := s.syncAddressTable(ctx, dryRun, list); err != nil {
+		t.Fatal(err)
+	}
+	if got := counts(dryRun); !reflect.DeepEqual(got, want) {
+		t.Errorf("dry run report = %+v, want %+v", got, want)
+	}
+	if mr.Dump() != before {
+		t.Error("dry run changed Redis")
+	}
+
+	report := &models.AddressReconcileReport{}
+	if err := s.syncAddressTable(ctx, report, list); err != nil {
+		t.Fatal(err)
+	}
+	if got := counts(report); !reflect.DeepEqual(got, want) {
+		t.Errorf("report = %+v, want %+v", got, want)
+	}
+
+	for _, e := range entries {
+		key := addressEntryKey(e.IPID)
+		if got, want := hashOf(t, mr, key), stringFields(fieldsOf(e)); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %v, want %v", e.IPAddress, got, want)
+		}
+	}
+	if hashOf(t, mr, removedKey) != nil {
+		t.Error("removed trunk IP still published")
+	}
+	if got, want := hashOf(t, mr, foreignKey), stringFields(foreign); !reflect.DeepEqual(got, want) {
+		t.Errorf("foreign entry = %v, want it untouched", got)
+	}
+
+	members, err := mr.Members(addressGroupKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantMembers := []string{addressEntryKey(added.IPID), addressEntryKey(changed.IPID), addressEntryKey(ungrouped.IPID)}
+	sort.Strings(wantMembers)
+	if !reflect.DeepEqual(members, wantMembers) {
+		t.Errorf("group members = %v, want %v", members, wantMembers)
+	}
+
+	// Nothing is left to do
+	again := &models.AddressReconcileReport{}
+	if err := s.syncAddressTable(ctx, again, list); err != nil {
+		t.Fatal(err)
+	}
+	if again.Added+again.Updated+again.Removed+again.GroupMembersFixed != 0 || again.Unchanged != 3 {
+		t.Errorf("second run = %+v, want all 3 unchanged", counts(again))
+	}
+}
@@ -0,0 +1,514 @@
+package trunk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/pgerr"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrTrunkTargetNotFound indicates the target does not exist on the trunk group
+	ErrTrunkTargetNotFound = errors.New("trunk target not found")
+	// ErrInvalidSIPURI indicates the target is not a plain sip:/sips: URI
+	ErrInvalidSIPURI = errors.New("invalid SIP URI: expected sip:[user@]host[:port] or sips:[user@]host[:port] without parameters")
+	// ErrTrunkTargetNotRoutable indicates the target host is a private or reserved address
+	ErrTrunkTargetNotRoutable = errors.New("trunk target address is private or reserved")
+	// ErrTrunkTargetExists indicates the same URI and transport is already a target of the trunk
+	ErrTrunkTargetExists = errors.New("trunk target already exists on this trunk group")
+	// ErrTooManyTrunkTargets indicates the trunk already has the maximum number of targets
+	ErrTooManyTrunkTargets = fmt.Errorf("a trunk group can have at most %d targets", maxTrunkTargets)
+)
+
+// Resolved routing published for the SIP edge:
+// trunk:routing:{trunk_id} -> JSON publishedRouting (absent when the trunk is
+// disabled or has no enabled targets; numbers then use their own destination)
+const trunkRoutingPrefix = "trunk:routing:"
+
+const (
+	maxTrunkTargets        = 16
+	defaultTargetPriority  = 10
+	defaultTargetWeight    = 100
+	defaultTargetTransport = "udp"
+)
+
+var sipHostnamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+var sipUserPattern = regexp.MustCompile(`^[A-Za-z0-9_.!~*'()&=+$,%-]+$`)
+
+// defaultRoutingSettings returns the settings of a trunk without a stored row;
+// they mirror the column defaults in accounts.trunk_routing_settings
+func defaultRoutingSettings(trunkID uuid.UUID) models.TrunkRoutingSettings {
+	return models.TrunkRoutingSettings{
+		TrunkGroupID:             trunkID,
+		FailoverResponseCodes:    []int{500, 502, 503, 504},
+		FailoverOnTimeout:        true,
+		InviteTimeoutSeconds:     8,
+		OptionsEnabled:           true,
+		OptionsIntervalSeconds:   30,
+		OptionsFailureThreshold:  3,
+		OptionsRecoveryThreshold: 2,
+	}
+}
+
+// publishedRouting is the document the SIP edge reads from trunk:routing:{trunk_id}
+type publishedRouting struct {
+	TrunkID     string            `json:"trunk_id"`
+	CustomerBAN string            `json:"customer_ban"`
+	Targets     []publishedTarget `json:"targets"` // Ascending priority, then descending weight
+	Failover    struct {
+		ResponseCodes []int `json:"response_codes"`
+		OnTimeout     bool  `json:"on_timeout"`
+		InviteTimeout int   `json:"invite_timeout"` // Seconds before trying the next target
+	} `json:"failover"`
+	Health struct {
+		OptionsEnabled    bool `json:"options_enabled"`
+		Interval          int  `json:"interval"` // Seconds between OPTIONS pings
+		FailureThreshold  int  `json:"failure_threshold"`
+		RecoveryThreshold int  `json:"recovery_threshold"`
+	} `json:"health"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type publishedTarget struct {
+	ID        string `json:"id"`
+	URI       string `json:"uri"`
+	Transport string `json:"transport"`
+	Priority  int    `json:"priority"`
+	Weight    int    `json:"weight"`
+}
+
+// ============================================================================
+// Trunk Routing Operations
+// ============================================================================
+
+// GetTrunkRouting returns a trunk's targets and effective routing settings
+func (s *Service) GetTrunkRouting(ctx context.Context, trunkID, customerID uuid.UUID) (*models.TrunkRouting, error) {
+	if _, err := s.getTrunkForCustomer(ctx, trunkID, customerID); err != nil {
+		return nil, err
+	}
+
+	targets, err := s.repo.ListTrunkTargets(ctx, trunkID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.routingSettings(ctx, trunkID)
+	if err != nil {
+		return nil, err
+	}
+
+	published, err := s.redisClient.Exists(ctx, trunkRoutingKey(trunkID)).Result()
+	if err != nil {
+		s.logger.Warn("Failed to check published trunk routing", zap.String("trunk_id", trunkID.String()), zap.Error(err))
+	}
+
+	return &models.TrunkRouting{
+		TrunkGroupID: trunkID,
+		Settings:     settings,
+		Targets:      targets,
+		Published:    published > 0,
+	}, nil
+}
+
+// CreateTrunkTarget adds a termination target and republishes the trunk's routing
+func (s *Service) CreateTrunkTarget(ctx context.Context, trunkID, customerID uuid.UUID, req models.CreateTrunkTargetRequest, createdBy uuid.UUID) (*models.TrunkTarget, error) {
+	if _, err := s.getTrunkForCustomer(ctx, trunkID, customerID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.ListTrunkTargets(ctx, trunkID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxTrunkTargets {
+		return nil, ErrTooManyTrunkTargets
+	}
+
+	transport := ""
+	if req.Transport != nil {
+		transport = *req.Transport
+	}
+	uri, transport, err := s.normalizeTargetURI(req.SIPURI, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	target := &models.TrunkTarget{
+		ID:           uuid.New(),
+		TrunkGroupID: trunkID,
+		SIPURI:       uri,
+		Transport:    transport,
+		Priority:     defaultTargetPriority,
+		Weight:       defaultTargetWeight,
+		Description:  req.Description,
+		Enabled:      true,
+		CreatedBy:    &createdBy,
+	}
+	if req.Priority != nil {
+		target.Priority = *req.Priority
+	}
+	if req.Weight != nil {
+		target.Weight = *req.Weight
+	}
+
+	if err := s.repo.CreateTrunkTarget(ctx, target); err != nil {
+		if pgerr.IsUniqueViolation(err) {
+			return nil, ErrTrunkTargetExists
+		}
+		return nil, err
+	}
+
+	s.refreshTrunkRouting(ctx, trunkID)
+	return target, nil
+}
+
+// UpdateTrunkTarget updates a termination target and republishes the trunk's routing
+func (s *Service) UpdateTrunkTarget(ctx context.Context, trunkID, targetID, customerID uuid.UUID, req models.UpdateTrunkTargetRequest) (*models.TrunkTarget, error) {
+	if _, err := s.getTrunkForCustomer(ctx, trunkID, customerID); err != nil {
+		return nil, err
+	}
+	target, err := s.getTargetForTrunk(ctx, trunkID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	// URI and transport are validated together
+	if req.SIPURI != nil || req.Transport != nil {
+		uri, transport := target.SIPURI, target.Transport
+		if req.SIPURI != nil {
+			uri = *req.SIPURI
+		}
+		if req.Transport != nil {
+			transport = *req.Transport
+		} else if req.SIPURI != nil {
+			transport = "" // Re-derive from the new URI's scheme
+		}
+		uri, transport, err = s.normalizeTargetURI(uri, transport)
+		if err != nil {
+			return nil, err
+		}
+		req.SIPURI, req.Transport = &uri, &transport
+	}
+
+	if err := s.repo.UpdateTrunkTarget(ctx, targetID, req); err != nil {
+		if pgerr.IsUniqueViolation(err) {
+			return nil, ErrTrunkTargetExists
+		}
+		return nil, err
+	}
+
+	s.refreshTrunkRouting(ctx, trunkID)
+	return s.getTargetForTrunk(ctx, trunkID, targetID)
+}
+
+// DeleteTrunkTarget removes a termination target and republishes the trunk's routing
+func (s *Service) DeleteTrunkTarget(ctx context.Context, trunkID, targetID, customerID uuid.UUID) error {
+	if _, err := s.getTrunkForCustomer(ctx, trunkID, customerID); err != nil {
+		return err
+	}
+	if _, err := s.getTargetForTrunk(ctx, trunkID, targetID); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteTrunkTarget(ctx, targetID); err != nil {
+		return err
+	}
+
+	s.refreshTrunkRouting(ctx, trunkID)
+	return nil
+}
+
+// UpdateTrunkRoutingSettings changes failover and OPTIONS health settings
+func (s *Service) UpdateTrunkRoutingSettings(ctx context.Context, trunkID, customerID uuid.UUID, req models.UpdateTrunkRoutingSettingsRequest) (*models.TrunkRoutingSettings, error) {
+	if _, err := s.getTrunkForCustomer(ctx, trunkID, customerID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.routingSettings(ctx, trunkID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.FailoverResponseCodes != nil {
+		settings.FailoverResponseCodes = uniqueSortedCodes(req.FailoverResponseCodes)
+	}
+	if req.FailoverOnTimeout != nil {
+		settings.FailoverOnTimeout = *req.FailoverOnTimeout
+	}
+	if req.InviteTimeoutSeconds != nil {
+		settings.InviteTimeoutSeconds = *req.InviteTimeoutSeconds
+	}
+	if req.OptionsEnabled != nil {
+		settings.OptionsEnabled = *req.OptionsEnabled
+	}
+	if req.OptionsIntervalSeconds != nil {
+		settings.OptionsIntervalSeconds = *req.OptionsIntervalSeconds
+	}
+	if req.OptionsFailureThreshold != nil {
+		settings.OptionsFailureThreshold = *req.OptionsFailureThreshold
+	}
+	if req.OptionsRecoveryThreshold != nil {
+		settings.OptionsRecoveryThreshold = *req.OptionsRecoveryThreshold
+	}
+
+	if err := s.repo.UpsertTrunkRoutingSettings(ctx, &settings); err != nil {
+		return nil, err
+	}
+
+	s.refreshTrunkRouting(ctx, trunkID)
+	return &settings, nil
+}
+
+// routingSettings returns a trunk's stored settings or the defaults
+func (s *Service) routingSettings(ctx context.Context, trunkID uuid.UUID) (models.TrunkRoutingSettings, error) {
+	stored, err := s.repo.GetTrunkRoutingSettings(ctx, trunkID)
+	if err != nil {
+		return models.TrunkRoutingSettings{}, err
+	}
+	if stored == nil {
+		return defaultRoutingSettings(trunkID), nil
+	}
+	return *stored, nil
+}
+
+// getTargetForTrunk loads a target, verifying it belongs to the trunk group
+func (s *Service) getTargetForTrunk(ctx context.Context, trunkID, targetID uuid.UUID) (*models.TrunkTarget, error) {
+	target, err := s.repo.GetTrunkTarget(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil || target.TrunkGroupID != trunkID {
+		return nil, ErrTrunkTargetNotFound
+	}
+	return target, nil
+}
+
+// normalizeTargetURI validates a sip:/sips: URI and returns it in canonical
+// form (lower-case scheme and host, bracketed IPv6) with the effective
+// transport: sips: always uses tls, otherwise transport defaults to udp.
+// IP hosts in private or reserved space are rejected unless allowed.
+func (s *Service) normalizeTargetURI(raw, transport string) (string, string, error) {
+	raw = strings.TrimSpace(raw)
+	scheme, rest, ok := strings.Cut(raw, ":")
+	if !ok || strings.ContainsAny(rest, ";?> \t") {
+		return "", "", ErrInvalidSIPURI
+	}
+	scheme = strings.ToLower(scheme)
+	switch scheme {
+	case "sip":
+		if transport == "" {
+			transport = defaultTargetTransport
+		}
+	case "sips":
+		if transport != "" && transport != "tls" {
+			return "", "", fmt.Errorf("%w: sips: URIs require transport tls", ErrInvalidSIPURI)
+		}
+		transport = "tls"
+	default:
+		return "", "", ErrInvalidSIPURI
+	}
+
+	user := ""
+	if at := strings.LastIndex(rest, "@"); at >= 0 {
+		user, rest = rest[:at], rest[at+1:]
+		if !sipUserPattern.MatchString(user) {
+			return "", "", ErrInvalidSIPURI
+		}
+		user += "@"
+	}
+
+	host, port, err := splitSIPHostPort(rest)
+	if err != nil {
+		return "", "", err
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addr = addr.Unmap()
+		if addr.Zone() != "" {
+			return "", "", ErrInvalidSIPURI
+		}
+		if !s.allowPrivateIPs && isBogonRange(netip.PrefixFrom(addr, addr.BitLen())) {
+			return "", "", fmt.Errorf("%w: %s", ErrTrunkTargetNotRoutable, addr)
+		}
+		host = addr.String()
+		if addr.Is6() {
+			host = "[" + host + "]"
+		}
+	} else {
+		host = strings.ToLower(strings.TrimSuffix(host, "."))
+		if len(host) > 253 || !sipHostnamePattern.MatchString(host) {
+			return "", "", fmt.Errorf("%w: bad host %q", ErrInvalidSIPURI, host)
+		}
+	}
+
+	uri := scheme + ":" + user + host
+	if port != "" {
+		uri += ":" + port
+	}
+	return uri, transport, nil
+}
+
+// splitSIPHostPort splits host[:port] or [ipv6][:port]
+func splitSIPHostPort(hostport string) (string, string, error) {
+	var host, port string
+	if strings.HasPrefix(hostport, "[") {
+		end := strings.Index(hostport, "]")
+		if end < 0 {
+			return "", "", ErrInvalidSIPURI
+		}
+		host = hostport[1:end]
+		if after := hostport[end+1:]; after != "" {
+			if !strings.HasPrefix(after, ":") {
+				return "", "", ErrInvalidSIPURI
+			}
+			port = after[1:]
+		}
+		if _, err := netip.ParseAddr(host); err != nil {
+			return "", "", ErrInvalidSIPURI
+		}
+	} else {
+		switch strings.Count(hostport, ":") {
+		case 0:
+			host = hostport
+		case 1:
+			host, port, _ = strings.Cut(hostport, ":")
+		default:
+			return "", "", fmt.Errorf("%w: IPv6 hosts must be in brackets", ErrInvalidSIPURI)
+		}
+	}
+
+	if host == "" {
+		return "", "", ErrInvalidSIPURI
+	}
+	if port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil || n < 1 || n > 65535 {
+			return "", "", fmt.Errorf("%w: bad port %q", ErrInvalidSIPURI, port)
+		}
+		port = strconv.Itoa(n)
+	}
+	return host, port, nil
+}
+
+// uniqueSortedCodes removes duplicate response codes and sorts them
+func uniqueSortedCodes(codes []int) []int {
+	seen := make(map[int]bool, len(codes))
+	out := make([]int, 0, len(codes))
+	for _, code := range codes {
+		if !seen[code] {
+			seen[code] = true
+			out = append(out, code)
+		}
+	}
+	sort.Ints(out)
+	return out
+}
+
+// ============================================================================
+// Redis Synchronization (trunk routing)
+// ============================================================================
+
+// trunkRoutingKey returns the Redis key of a trunk's resolved routing
+func trunkRoutingKey(trunkID uuid.UUID) string {
+	return trunkRoutingPrefix + trunkID.String()
+}
+
+// RoutingKey returns the Redis key the SIP edge reads a trunk's resolved
+// routing from; published DID routes reference it
+func RoutingKey(trunkID uuid.UUID) string {
+	return trunkRoutingKey(trunkID)
+}
+
+// PublishTrunkRouting writes a trunk's resolved routing to Redis, or removes
+// it when the trunk is disabled or has no enabled targets
+func (s *Service) PublishTrunkRouting(ctx context.Context, trunkID uuid.UUID) error {
+	trunk, err := s.repo.GetTrunkGroup(ctx, trunkID)
+	if err != nil {
+		return err
+	}
+
+	var targets []models.TrunkTarget
+	if trunk.Enabled {
+		all, err := s.repo.ListTrunkTargets(ctx, trunkID)
+		if err != nil {
+			return err
+		}
+		for _, target := range all {
+			if target.Enabled {
+				targets = append(targets, target)
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return s.removeTrunkRouting(ctx, trunkID)
+	}
+
+	settings, err := s.routingSettings(ctx, trunkID)
+	if err != nil {
+		return err
+	}
+	customer, err := s.getCustomer(ctx, trunk.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	doc := publishedRouting{
+		TrunkID:     trunkID.String(),
+		CustomerBAN: customer.BAN,
+		Targets:     make([]publishedTarget, 0, len(targets)),
+		UpdatedAt:   time.Now().UTC(),
+	}
+	for _, target := range targets { // Already in routing order
+		doc.Targets = append(doc.Targets, publishedTarget{
+			ID:        target.ID.String(),
+			URI:       target.SIPURI,
+			Transport: target.Transport,
+			Priority:  target.Priority,
+			Weight:    target.Weight,
+		})
+	}
+	doc.Failover.ResponseCodes = settings.FailoverResponseCodes
+	doc.Failover.OnTimeout = settings.FailoverOnTimeout
+	doc.Failover.InviteTimeout = settings.InviteTimeoutSeconds
+	doc.Health.OptionsEnabled = settings.OptionsEnabled
+	doc.Health.Interval = settings.OptionsIntervalSeconds
+	doc.Health.FailureThreshold = settings.OptionsFailureThreshold
+	doc.Health.RecoveryThreshold = settings.OptionsRecoveryThreshold
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode trunk routing: %w", err)
+	}
+	if err := s.redisClient.Set(ctx, trunkRoutingKey(trunkID), data, 0).Err(); err != nil { // No expiration
+		return fmt.Errorf("failed to publish trunk routing: %w", err)
+	}
+
+	return nil
+}
+
+// removeTrunkRouting removes a trunk's published routing
+func (s *Service) removeTrunkRouting(ctx context.Context, trunkID uuid.UUID) error {
+	if err := s.redisClient.Del(ctx, trunkRoutingKey(trunkID)).Err(); err != nil {
+		return fmt.Errorf("failed to remove trunk routing: %w", err)
+	}
+	return nil
+}
+
+// refreshTrunkRouting republishes a trunk's routing, logging instead of
+// failing the operation that triggered it
+func (s *Service) refreshTrunkRouting(ctx context.Context, trunkID uuid.UUID) {
+	if err := s.PublishTrunkRouting(ctx, trunkID); err != nil {
+		s.logger.Warn("Failed to publish trunk routing to Redis", zap.String("trunk_id", trunkID.String()), zap.Error(err))
+	}
+}
@@ -0,0 +1,62 @@
+package trunk
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeTargetURI(t *testing.T) {
+	tests := []struct {
+		uri, transport string
+		wantURI        string
+		wantTransport  string
+		wantErr        error
+	}{
+		{uri: "sip:pbx.example.com", wantURI: "sip:pbx.example.com", wantTransport: "udp"},
+		{uri: " SIP:PBX.Example.COM.:5060 ", wantURI: "sip:pbx.example.com:5060", wantTransport: "udp"},
+		{uri: "sip:trunk@pbx.example.com", transport: "tcp", wantURI: "sip:trunk@pbx.example.com", wantTransport: "tcp"},
+		{uri: "sips:pbx.example.com:5061", wantURI: "sips:pbx.example.com:5061", wantTransport: "tls"},
+		{uri: "sip:8.8.8.8:05060", wantURI: "sip:8.8.8.8:5060", wantTransport: "udp"},
+		{uri: "sip:[2001:4860::8888]:5060", wantURI: "sip:[2001:4860::8888]:5060", wantTransport: "udp"},
+		{uri: "sip:[::ffff:8.8.8.8]", wantURI: "sip:8.8.8.8", wantTransport: "udp"},
+
+		{uri: "sips:pbx.example.com", transport: "udp", wantErr: ErrInvalidSIPURI},
+		{uri: "tel:+13035551234", wantErr: ErrInvalidSIPURI},
+		{uri: "pbx.example.com", wantErr: ErrInvalidSIPURI},
+		{uri: "sip:pbx.example.com;transport=tcp", wantErr: ErrInvalidSIPURI},
+		{uri: "sip:bad user@pbx.example.com", wantErr: ErrInvalidSIPURI},
+		{uri: "sip:2001:4860::8888", wantErr: ErrInvalidSIPURI}, // Unbracketed IPv6
+		{uri: "sip:pbx.example.com:70000", wantErr: ErrInvalidSIPURI},
+		{uri: "sip:[2001:4860::8888", wantErr: ErrInvalidSIPURI},
+		{uri: "sip:under_score.example.com", wantErr: ErrInvalidSIPURI},
+		{uri: "sip:localhost", wantErr: ErrInvalidSIPURI},
+		{uri: "sip:10.0.0.5", wantErr: ErrTrunkTargetNotRoutable},
+		{uri: "sip:[fe80::1]", wantErr: ErrTrunkTargetNotRoutable},
+	}
+
+	s := &Service{}
+	for _, tt := range tests {
+		uri, transport, err := s.normalizeTargetURI(tt.uri, tt.transport)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("normalizeTargetURI(%q) error = %v, want %v", tt.uri, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("normalizeTargetURI(%q) error = %v", tt.uri, err)
+			continue
+		}
+		if uri != tt.wantURI || transport != tt.wantTransport {
+			t.Errorf("normalizeTargetURI(%q) = %q, %q; want %q, %q", tt.uri, uri, transport, tt.wantURI, tt.wantTransport)
+		}
+	}
+}
+
+func TestNormalizeTargetURIAllowPrivate(t *testing.T) {
+	s := &Service{allowPrivateIPs: true}
+	uri, _, err := s.normalizeTargetURI("sip:10.0.0.5:5080", "")
+	if err != nil || uri != "sip:10.0.0.5:5080" {
+		t.Errorf("normalizeTargetURI(private, allowed) = %q, %v", uri, err)
+	}
+}
@@ -2,32 +2,71 @@ package trunk
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
+	"github.com/ringer-warp/api-gateway/internal/cloudip"
 	"github.com/ringer-warp/api-gateway/internal/models"
+	"github.com/ringer-warp/api-gateway/internal/pgerr"
 	"github.com/ringer-warp/api-gateway/internal/repository"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 )
 
+// ErrCustomerNotFound is returned when a trunk operation names a customer that does not exist
+var ErrCustomerNotFound = errors.New("customer not found")
+
 type Service struct {
-	repo        *repository.TrunkRepository
+	repo         *repository.TrunkRepository
 	customerRepo *repository.CustomerRepository
-	redisClient *redis.Client
+	redisClient  *redis.Client
+	logger       *zap.Logger
+
+	// Address reconciliation state (see reconcile.go)
+	reconcileMu sync.Mutex
+	lastReport  *models.AddressReconcileReport
+
+	// Accept private/reserved ACL ranges (development and lab environments only)
+	allowPrivateIPs bool
+
+	// Cloud address provider for dedicated IPs (nil disables provisioning, see dedicated_ip.go)
+	addressProvider cloudip.Provider
 }
 
 func NewService(
 	repo *repository.TrunkRepository,
 	customerRepo *repository.CustomerRepository,
 	redisClient *redis.Client,
+	logger *zap.Logger,
 ) *Service {
 	return &Service{
-		repo:        repo,
+		repo:         repo,
 		customerRepo: customerRepo,
-		redisClient: redisClient,
+		redisClient:  redisClient,
+		logger:       logger,
 	}
 }
 
+// getCustomer loads a customer, returning ErrCustomerNotFound if it does not exist
+func (s *Service) getCustomer(ctx context.Context, customerID uuid.UUID) (*models.Customer, error) {
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, ErrCustomerNotFound
+	}
+	return customer, nil
+}
+
+// SetAllowPrivateTrunkIPs allows private and reserved ranges in trunk ACLs.
+// Production rejects them; lab environments with RFC 1918 PBXs need them.
+func (s *Service) SetAllowPrivateTrunkIPs(allow bool) {
+	s.allowPrivateIPs = allow
+}
+
 // ============================================================================
 // Trunk Group Operations
 // ============================================================================
@@ -35,9 +74,9 @@ func NewService(
 // CreateTrunkGroup creates a new trunk group and initializes Redis cache
 func (s *Service) CreateTrunkGroup(ctx context.Context, customerID uuid.UUID, req models.CreateTrunkGroupRequest, createdBy uuid.UUID) (*models.TrunkGroup, error) {
 	// Verify customer exists and is active
-	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	customer, err := s.getCustomer(ctx, customerID)
 	if err != nil {
-		return nil, fmt.Errorf("customer not found: %w", err)
+		return nil, err
 	}
 
 	if customer.Status != "active" {
@@ -50,6 +89,9 @@ func (s *Service) CreateTrunkGroup(ctx context.Context, customerID uuid.UUID, re
 		return nil, err
 	}
 
+	// Publish CPS / concurrent call limits for the SIP edge
+	s.refreshTrunkLimits(ctx, customerID)
+
 	return trunk, nil
 }
 
@@ -83,7 +125,27 @@ func (s *Service) UpdateTrunkGroup(ctx context.Context, trunkID uuid.UUID, custo
 		return fmt.Errorf("access denied to trunk group")
 	}
 
-	return s.repo.UpdateTrunkGroup(ctx, trunkID, req)
+	// Credentials before the update, to revoke or republish them afterwards
+	var creds []models.TrunkCredential
+	if req.AuthType != nil || req.Enabled != nil {
+		creds, err = s.repo.ListTrunkCredentials(ctx, trunkID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.UpdateTrunkGroup(ctx, trunkID, req); err != nil {
+		return err
+	}
+
+	s.applyCredentialPolicy(ctx, trunkID, creds, req)
+	if req.CapacityCPS != nil || req.CapacityConcurrentCalls != nil || req.Enabled != nil {
+		s.refreshTrunkLimits(ctx, customerID)
+	}
+	if req.Enabled != nil {
+		s.refreshTrunkRouting(ctx, trunkID)
+	}
+	return nil
 }
 
 // DeleteTrunkGroup deletes a trunk group and cleans up Redis entries
@@ -97,11 +159,15 @@ func (s *Service) DeleteTrunkGroup(ctx context.Context, trunkID uuid.UUID, custo
 		return fmt.Errorf("access denied to trunk group")
 	}
 
-	// Get all IPs for this trunk to clean up Redis
+	// Get all IPs and credentials for this trunk to clean up Redis
 	ips, err := s.repo.ListTrunkIPs(ctx, trunkID)
 	if err != nil {
 		return err
 	}
+	creds, err := s.repo.ListTrunkCredentials(ctx, trunkID)
+	if err != nil {
+		return err
+	}
 
 	// Delete trunk group (cascades to trunk_ips)
 	err = s.repo.DeleteTrunkGroup(ctx, trunkID)
@@ -112,10 +178,15 @@ func (s *Service) DeleteTrunkGroup(ctx context.Context, trunkID uuid.UUID, custo
 	// Clean up Redis entries for all IPs
 	for _, ip := range ips {
 		if err := s.removeIPFromRedis(ctx, ip.ID); err != nil {
-			// Log error but don't fail the delete
-			fmt.Printf("Warning: failed to remove IP %s from Redis: %v\n", ip.IPAddress, err)
+			// Log error but don't fail the delete (the reconciler removes it later)
+			s.logger.Warn("Failed to remove IP from Redis", zap.String("ip", ip.IPAddress), zap.Error(err))
 		}
 	}
+	s.removeCredentialsFromRedis(ctx, creds)
+	s.removeTrunkLimits(ctx, trunkID, customerID)
+	if err := s.removeTrunkRouting(ctx, trunkID); err != nil {
+		s.logger.Warn("Failed to remove trunk routing from Redis", zap.String("trunk_id", trunkID.String()), zap.Error(err))
+	}
 
 	return nil
 }
@@ -135,22 +206,53 @@ func (s *Service) AddTrunkIP(ctx context.Context, trunkID uuid.UUID, customerID
 		return nil, fmt.Errorf("access denied to trunk group")
 	}
 
+	// Canonicalize to the network address of the range (IPv4 or IPv6)
+	prefix, err := ParseTrunkIPRange(req.IPAddress, req.Netmask)
+	if err != nil {
+		return nil, err
+	}
+	if !s.allowPrivateIPs && isBogonRange(prefix) {
+		return nil, fmt.Errorf("%w: %s", ErrTrunkIPNotRoutable, prefix)
+	}
+	ipAddress, netmask := prefix.Addr().String(), prefix.Bits()
+	req.IPAddress, req.Netmask = ipAddress, &netmask
+
+	// Another customer holding an overlapping range would make the
+	// Kamailio tag (BAN) ambiguous for calls from that range
+	conflict, err := s.repo.FindOverlappingTrunkIP(ctx, ipAddress, netmask, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if conflict != "" {
+		s.logger.Warn("Rejected overlapping trunk IP range",
+			zap.String("range", prefix.String()),
+			zap.String("conflict", conflict),
+			zap.String("customer_id", customerID.String()),
+		)
+		return nil, fmt.Errorf("%w: %s", ErrTrunkIPOverlap, prefix)
+	}
+
 	// Get customer to fetch BAN for tag
-	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	customer, err := s.getCustomer(ctx, customerID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Add IP to database
+	// Add IP to database (the overlap trigger closes the race with concurrent adds)
 	ip, err := s.repo.AddTrunkIP(ctx, trunkID, req, createdBy)
-	if err != nil {
+	switch {
+	case pgerr.Is(err, pgerr.ExclusionViolation):
+		return nil, fmt.Errorf("%w: %s", ErrTrunkIPOverlap, prefix)
+	case pgerr.IsUniqueViolation(err):
+		return nil, fmt.Errorf("%w: %s", ErrTrunkIPExists, prefix)
+	case err != nil:
 		return nil, err
 	}
 
 	// Sync to Redis for Kamailio permissions module
 	if err := s.syncIPToRedis(ctx, *ip, customer.BAN); err != nil {
-		// Log error but don't fail the add (can sync later)
-		fmt.Printf("Warning: failed to sync IP to Redis: %v\n", err)
+		// Log error but don't fail the add (the reconciler syncs it later)
+		s.logger.Warn("Failed to sync IP to Redis", zap.String("ip", ip.IPAddress), zap.Error(err))
 	}
 
 	return ip, nil
@@ -187,8 +289,8 @@ func (s *Service) UpdateTrunkIP(ctx context.Context, ipID uuid.UUID, customerID
 		return err
 	}
 
-	// If enabled status changed, update Redis
-	if req.Enabled != nil {
+	// If enabled status, port or transport changed, update Redis
+	if req.Enabled != nil || req.Port != nil || req.Transport != nil {
 		ip, err := s.repo.GetTrunkIP(ctx, ipID)
 		if err != nil {
 			return err
@@ -200,20 +302,20 @@ func (s *Service) UpdateTrunkIP(ctx context.Context, ipID uuid.UUID, customerID
 			return err
 		}
 
-		customer, err := s.customerRepo.GetByID(ctx, trunk.CustomerID)
+		customer, err := s.getCustomer(ctx, trunk.CustomerID)
 		if err != nil {
 			return err
 		}
 
-		if *req.Enabled {
-			// Add to Redis
+		if ip.Enabled {
+			// Add (or rewrite) in Redis
 			if err := s.syncIPToRedis(ctx, *ip, customer.BAN); err != nil {
-				fmt.Printf("Warning: failed to add IP to Redis: %v\n", err)
+				s.logger.Warn("Failed to add IP to Redis", zap.String("ip", ip.IPAddress), zap.Error(err))
 			}
 		} else {
 			// Remove from Redis
 			if err := s.removeIPFromRedis(ctx, ip.ID); err != nil {
-				fmt.Printf("Warning: failed to remove IP from Redis: %v\n", err)
+				s.logger.Warn("Failed to remove IP from Redis", zap.String("ip", ip.IPAddress), zap.Error(err))
 			}
 		}
 	}
@@ -241,7 +343,7 @@ func (s *Service) DeleteTrunkIP(ctx context.Context, ipID uuid.UUID, customerID
 	// Remove from Redis
 	if err := s.removeIPFromRedis(ctx, ipID); err != nil {
 		// Log error but don't fail the delete
-		fmt.Printf("Warning: failed to remove IP from Redis: %v\n", err)
+		s.logger.Warn("Failed to remove IP from Redis", zap.String("ip_id", ipID.String()), zap.Error(err))
 	}
 
 	return nil
@@ -253,36 +355,23 @@ func (s *Service) DeleteTrunkIP(ctx context.Context, ipID uuid.UUID, customerID
 
 // syncIPToRedis syncs a trunk IP to Redis for Kamailio permissions module
 // Redis key format: address:entry:{id}
-// Hash fields: {grp: "100", ip_addr: "1.2.3.4", mask: "32", port: "5060", proto: "any", tag: "AC-12345"}
+// Hash fields: {grp: "100", ip_addr: "1.2.3.0", mask: "24", port: "5060", proto: "udp", tag: "AC-12345"}
 func (s *Service) syncIPToRedis(ctx context.Context, ip models.TrunkIP, customerBAN string) error {
 	if !ip.Enabled {
 		return nil // Don't sync disabled IPs
 	}
 
-	redisKey := fmt.Sprintf("address:entry:%s", ip.ID.String())
-
-	// Kamailio permissions module expects these exact field names
-	data := map[string]interface{}{
-		"grp":      "100",                 // Group 100 = customer trunks
-		"ip_addr":  ip.IPAddress,
-		"mask":     fmt.Sprintf("%d", ip.Netmask),
-		"port":     "5060",                // SIP port
-		"proto":    "any",                 // Match any protocol (UDP/TCP)
-		"pattern":  "",                    // Not used
-		"context_info": "",                // Not used
-		"tag":      customerBAN,           // Customer BAN (returned in $avp(peer_tag))
-	}
+	redisKey := addressEntryKey(ip.ID)
 
 	// Store as Redis hash
-	err := s.redisClient.HSet(ctx, redisKey, data).Err()
+	err := s.redisClient.HSet(ctx, redisKey, addressFields(ip.IPAddress, ip.Netmask, ip.Port, ip.Transport, customerBAN)).Err()
 	if err != nil {
 		return fmt.Errorf("failed to sync IP to Redis: %w", err)
 	}
 
 	// Also add to sorted set for efficient lookups
 	// This allows Kamailio to quickly enumerate all addresses in group 100
-	grpKey := "address:group:100"
-	err = s.redisClient.SAdd(ctx, grpKey, redisKey).Err()
+	err = s.redisClient.SAdd(ctx, addressGroupKey, redisKey).Err()
 	if err != nil {
 		return fmt.Errorf("failed to add to group set: %w", err)
 	}
@@ -292,11 +381,10 @@ func (s *Service) syncIPToRedis(ctx context.Context, ip models.TrunkIP, customer
 
 // removeIPFromRedis removes a trunk IP from Redis
 func (s *Service) removeIPFromRedis(ctx context.Context, ipID uuid.UUID) error {
-	redisKey := fmt.Sprintf("address:entry:%s", ipID.String())
+	redisKey := addressEntryKey(ipID)
 
 	// Remove from group set
-	grpKey := "address:group:100"
-	s.redisClient.SRem(ctx, grpKey, redisKey)
+	s.redisClient.SRem(ctx, addressGroupKey, redisKey)
 
 	// Delete the hash
 	err := s.redisClient.Del(ctx, redisKey).Err()
@@ -307,56 +395,6 @@ func (s *Service) removeIPFromRedis(ctx context.Context, ipID uuid.UUID) error {
 	return nil
 }
 
-// SyncAllTrunkIPsToRedis syncs all enabled trunk IPs to Redis (for initialization or repair)
-func (s *Service) SyncAllTrunkIPsToRedis(ctx context.Context) error {
-	// This would query all trunk_ips and sync to Redis
-	// Useful for:
-	// 1. Initial deployment
-	// 2. Redis cache rebuild after failure
-	// 3. Data reconciliation
-
-	// Get all customers (SuperAdmin access for sync - no customer filter)
-	customers, _, err := s.customerRepo.List(ctx, nil, "", "", 1, 1000) // Get first 1000 customers
-	if err != nil {
-		return fmt.Errorf("failed to list customers: %w", err)
-	}
-
-	synced := 0
-	failed := 0
-
-	for _, customer := range customers {
-		// Get all trunk groups for this customer
-		trunks, err := s.repo.ListTrunkGroupsWithIPsByCustomer(ctx, customer.ID)
-		if err != nil {
-			fmt.Printf("Warning: failed to list trunks for customer %s: %v\n", customer.BAN, err)
-			continue
-		}
-
-		// Sync each IP
-		for _, trunk := range trunks {
-			if !trunk.Enabled {
-				continue
-			}
-
-			for _, ip := range trunk.IPs {
-				if !ip.Enabled {
-					continue
-				}
-
-				if err := s.syncIPToRedis(ctx, ip, customer.BAN); err != nil {
-					fmt.Printf("Warning: failed to sync IP %s for customer %s: %v\n", ip.IPAddress, customer.BAN, err)
-					failed++
-				} else {
-					synced++
-				}
-			}
-		}
-	}
-
-	fmt.Printf("Redis sync complete: %d synced, %d failed\n", synced, failed)
-	return nil
-}
-
 // ============================================================================
 // Premium Customer Operations
 // ============================================================================
@@ -364,13 +402,12 @@ func (s *Service) SyncAllTrunkIPsToRedis(ctx context.Context) error {
 // CacheDedicatedIPMapping caches dedicated IP → customer BAN mapping in Redis
 // Used by Kamailio for premium tier authentication (destination IP-based)
 func (s *Service) CacheDedicatedIPMapping(ctx context.Context, customerID uuid.UUID, ipAddress string) error {
-	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	customer, err := s.getCustomer(ctx, customerID)
 	if err != nil {
 		return err
 	}
 
-	redisKey := fmt.Sprintf("customer:dedicated_ip:%s", ipAddress)
-	err = s.redisClient.Set(ctx, redisKey, customer.BAN, 0).Err() // No expiration
+	err = s.redisClient.Set(ctx, dedicatedIPKey(ipAddress), customer.BAN, 0).Err() // No expiration
 	if err != nil {
 		return fmt.Errorf("failed to cache dedicated IP mapping: %w", err)
 	}
@@ -380,8 +417,7 @@ func (s *Service) CacheDedicatedIPMapping(ctx context.Context, customerID uuid.U
 
 // RemoveDedicatedIPMapping removes dedicated IP mapping from Redis
 func (s *Service) RemoveDedicatedIPMapping(ctx context.Context, ipAddress string) error {
-	redisKey := fmt.Sprintf("customer:dedicated_ip:%s", ipAddress)
-	return s.redisClient.Del(ctx, redisKey).Err()
+	return s.redisClient.Del(ctx, dedicatedIPKey(ipAddress)).Err()
 }
 
 // ============================================================================
@@ -390,7 +426,7 @@ func (s *Service) RemoveDedicatedIPMapping(ctx context.Context, ipAddress string
 
 // CacheCustomerStatus caches customer status in Redis for fast Kamailio lookups
 func (s *Service) CacheCustomerStatus(ctx context.Context, customerID uuid.UUID) error {
-	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	customer, err := s.getCustomer(ctx, customerID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,61 @@
+// Package worker runs the API gateway's periodic background jobs: the
+// provisioning, repair and reconciliation loops started from main.
+package worker
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// baseBackoff is the delay before the first retry
+	baseBackoff = 30 * time.Second
+
+	// maxBackoff caps the delay between retries
+	maxBackoff = time.Hour
+)
+
+// Every runs fn in its own goroutine every interval until ctx is cancelled.
+// A receive on wake (which may be nil) runs fn early.
+func Every(ctx context.Context, interval time.Duration, wake <-chan struct{}, fn func(context.Context)) {
+	go loop(ctx, interval, wake, false, fn)
+}
+
+// NowAndEvery is Every, with a first run as soon as the goroutine starts
+func NowAndEvery(ctx context.Context, interval time.Duration, wake <-chan struct{}, fn func(context.Context)) {
+	go loop(ctx, interval, wake, true, fn)
+}
+
+func loop(ctx context.Context, interval time.Duration, wake <-chan struct{}, now bool, fn func(context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	if now {
+		fn(ctx)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+		fn(ctx)
+	}
+}
+
+// Backoff returns the delay before retrying a step that has failed attempts
+// times: 30s doubling per attempt, capped at an hour
+func Backoff(attempts int) time.Duration {
+	if attempts < 0 {
+		attempts = 0
+	}
+	if attempts >= 7 { // 30s << 7 already exceeds the cap
+		return maxBackoff
+	}
+	backoff := baseBackoff << uint(attempts)
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
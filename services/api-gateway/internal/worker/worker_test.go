@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{-1, 30 * time.Second},
+		{0, 30 * time.Second},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{6, 32 * time.Minute},
+		{7, time.Hour},
+		{63, time.Hour},
+		{1000, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestEveryWake(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	wake := make(chan struct{})
+	runs := make(chan struct{}, 1)
+	Every(ctx, time.Hour, wake, func(context.Context) { runs <- struct{}{} })
+
+	select {
+	case <-runs:
+		t.Fatal("Every ran before the first tick or wake")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	wake <- struct{}{}
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("Every did not run on wake")
+	}
+}
+
+func TestNowAndEvery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runs := make(chan struct{}, 10)
+	NowAndEvery(ctx, 10*time.Millisecond, nil, func(context.Context) { runs <- struct{}{} })
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatalf("run %d did not happen", i+1)
+		}
+	}
+
+	cancel()
+	time.Sleep(30 * time.Millisecond)
+	for len(runs) > 0 {
+		<-runs
+	}
+	time.Sleep(50 * time.Millisecond)
+	if len(runs) > 0 {
+		t.Error("NowAndEvery kept running after cancel")
+	}
+}
@@ -0,0 +1,181 @@
+// smpp-gateway accepts SMPP binds from customers and routes their messages to
+// upstream vendor SMSCs, tracking delivery receipts and status callbacks.
+//
+// Configuration is read from the environment (see internal/config). On SIGTERM
+// or SIGINT the gateway drains: it stops accepting binds, unbinds customers so
+// they reconnect to another replica and persists undelivered receipts before
+// shutting down.
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/ringer-warp/smpp-gateway/internal/api"
+	"github.com/ringer-warp/smpp-gateway/internal/auth"
+	"github.com/ringer-warp/smpp-gateway/internal/capture"
+	"github.com/ringer-warp/smpp-gateway/internal/cluster"
+	"github.com/ringer-warp/smpp-gateway/internal/config"
+	"github.com/ringer-warp/smpp-gateway/internal/connectors"
+	"github.com/ringer-warp/smpp-gateway/internal/dlr"
+	"github.com/ringer-warp/smpp-gateway/internal/metrics"
+	"github.com/ringer-warp/smpp-gateway/internal/ratelimit"
+	"github.com/ringer-warp/smpp-gateway/internal/routing"
+	"github.com/ringer-warp/smpp-gateway/internal/scheduler"
+	"github.com/ringer-warp/smpp-gateway/internal/server"
+	"github.com/ringer-warp/smpp-gateway/internal/webhook"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// drainTimeout bounds the SIGTERM drain; terminationGracePeriodSeconds
+	// (90s) leaves room for the preStop sleep and shutdown
+	drainTimeout = 60 * time.Second
+
+	// shutdownTimeout bounds stopping the HTTP servers and cluster registry
+	shutdownTimeout = 10 * time.Second
+)
+
+func main() {
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load configuration")
+	}
+	configureLogging(cfg)
+
+	log.WithFields(log.Fields{
+		"instance_id": cfg.InstanceID,
+		"environment": cfg.Environment,
+	}).Info("Starting SMPP gateway")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Redis: DLR tracking, rate limits, scheduling, callback retries and the cluster registry
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisAddr(),
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+	})
+	defer redisClient.Close()
+
+	if err := redisClient.Ping(ctx).Err(); err != nil {
+		log.WithError(err).Fatal("Failed to connect to Redis")
+	}
+	log.Info("Connected to Redis")
+
+	// Vendor connectors (also owns the PostgreSQL pool)
+	captures := capture.NewManager()
+	connMgr, err := connectors.NewManager(ctx, cfg)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to create connector manager")
+	}
+	connMgr.SetCaptureManager(captures)
+
+	// Status tracking and customer callbacks
+	dlrTracker := dlr.NewTracker(redisClient)
+	webhooks := webhook.NewDispatcher(connMgr.DB(), redisClient, cfg)
+	dlrTracker.SetStatusListener(webhooks)
+	connMgr.SetDLRHandler(dlrTracker)
+
+	// Customer-facing SMPP server
+	smppServer, err := server.NewSMPPServer(cfg, connMgr)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to create SMPP server")
+	}
+	smppServer.SetAuthenticator(auth.NewAuthenticator(connMgr.DB()))
+	router := routing.NewRouter(connMgr.DB(), connMgr)
+	smppServer.SetRouter(router)
+	smppServer.SetNumberResolver(router)
+	smppServer.SetDLRTracker(dlrTracker)
+	dlrTracker.SetReceiptHandler(smppServer)
+	smppServer.SetRateLimiter(ratelimit.NewLimiter(redisClient))
+	smppServer.SetCaptureManager(captures)
+
+	connMgr.SetMOHandler(smppServer)
+
+	sched := scheduler.NewScheduler(redisClient)
+	sched.SetHandler(smppServer)
+	smppServer.SetScheduler(sched)
+
+	registry := cluster.NewRegistry(redisClient, cfg.InstanceID, cfg.MaxBindsPerCustomer)
+	smppServer.SetRegistry(registry)
+	if err := registry.Start(ctx); err != nil {
+		log.WithError(err).Fatal("Failed to join gateway cluster")
+	}
+
+	// Background workers
+	webhooks.Start(ctx)
+	sched.Start(ctx)
+
+	// HTTP: metrics and management API
+	metricsServer := metrics.NewServer(cfg.MetricsPort)
+	if err := metricsServer.Start(ctx); err != nil {
+		log.WithError(err).Fatal("Failed to start metrics server")
+	}
+
+	apiServer := api.NewServer(cfg.APIPort, smppServer, connMgr, dlrTracker)
+	apiServer.SetWebhookDispatcher(webhooks)
+	apiServer.SetCaptureManager(captures)
+	if err := apiServer.Start(ctx); err != nil {
+		log.WithError(err).Fatal("Failed to start management API")
+	}
+
+	// Vendors first, so customers can submit as soon as they bind
+	if err := connMgr.StartAll(ctx); err != nil {
+		log.WithError(err).Fatal("Failed to start vendor connections")
+	}
+
+	drained := smppServer.DrainOnSignal(drainTimeout)
+	if err := smppServer.Start(ctx); err != nil {
+		log.WithError(err).Fatal("Failed to start SMPP server")
+	}
+
+	log.Info("SMPP gateway started")
+	<-drained
+
+	// Stop components in reverse dependency order once the server has drained
+	shutdownCtx, stop := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer stop()
+
+	if err := smppServer.Shutdown(shutdownCtx); err != nil {
+		log.WithError(err).Warn("SMPP server shutdown error")
+	}
+
+	// Stop background loops, then deregister and let workers finish before
+	// the database pool closes
+	cancel()
+	registry.Stop(shutdownCtx)
+	sched.Wait()
+	webhooks.Wait()
+
+	if err := apiServer.Stop(shutdownCtx); err != nil {
+		log.WithError(err).Warn("Management API shutdown error")
+	}
+	if err := metricsServer.Stop(shutdownCtx); err != nil {
+		log.WithError(err).Warn("Metrics server shutdown error")
+	}
+	if err := connMgr.StopAll(shutdownCtx); err != nil {
+		log.WithError(err).Warn("Vendor shutdown error")
+	}
+
+	log.Info("SMPP gateway stopped")
+}
+
+// configureLogging sets the log level and uses JSON output outside development
+func configureLogging(cfg *config.Config) {
+	log.SetOutput(os.Stdout)
+
+	level, err := log.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		log.WithField("log_level", cfg.LogLevel).Warn("Unknown log level, using info")
+		level = log.InfoLevel
+	}
+	log.SetLevel(level)
+
+	if cfg.Environment != "development" {
+		log.SetFormatter(&log.JSONFormatter{})
+	}
+}
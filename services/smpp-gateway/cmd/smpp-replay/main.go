@@ -0,0 +1,231 @@
+// smpp-replay re-drives a PDU capture downloaded from the SMPP gateway admin API
+// against a test server.
+//
+// Customer captures are replayed with -direction=in (the customer's PDUs, sent to
+// a test gateway); vendor captures with -direction=out (the gateway's PDUs, sent
+// to a test SMSC such as the simulator).
+//
+//	smpp-replay -file capture.pcap -target localhost:2775 -password secret
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/ringer-warp/smpp-gateway/internal/capture"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	file := flag.String("file", "", "Capture file (.pcap or .jsonl)")
+	target := flag.String("target", "localhost:2775", "Test server address")
+	direction := flag.String("direction", "in", "Which side's PDUs to replay: in (peer->gateway) or out (gateway->peer)")
+	speed := flag.Float64("speed", 1.0, "Replay speed multiplier (0 = no delays)")
+	systemID := flag.String("system-id", "", "Override system_id in bind PDUs")
+	password := flag.String("password", "", "Override password in bind PDUs (captured passwords are redacted)")
+	wait := flag.Duration("wait", 5*time.Second, "Time to wait for responses after the last PDU")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	records, err := loadRecords(*file)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load capture")
+	}
+
+	conn, err := net.Dial("tcp", *target)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to connect to target")
+	}
+	defer conn.Close()
+
+	log.WithFields(log.Fields{
+		"file":    *file,
+		"target":  *target,
+		"records": len(records),
+	}).Info("Replaying capture")
+
+	var writeMu sync.Mutex
+	write := func(b []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_, err := conn.Write(b)
+		return err
+	}
+
+	stats := &replayStats{responses: make(map[string]int)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		readResponses(conn, write, stats)
+	}()
+
+	var last time.Time
+	for _, r := range records {
+		if string(r.Direction) != *direction {
+			continue
+		}
+
+		if *speed > 0 && !last.IsZero() {
+			time.Sleep(time.Duration(float64(r.Time.Sub(last)) / *speed))
+		}
+		last = r.Time
+
+		raw, err := rewriteBind(r.Data, *systemID, *password)
+		if err != nil {
+			log.WithError(err).Warn("Failed to rewrite bind PDU, sending as captured")
+			raw = r.Data
+		}
+
+		if err := write(raw); err != nil {
+			log.WithError(err).Fatal("Failed to write PDU")
+		}
+		stats.add(&stats.sent)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(*wait):
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	log.WithFields(log.Fields{
+		"sent":      stats.sent,
+		"received":  stats.received,
+		"responses": stats.responses,
+	}).Info("Replay complete")
+}
+
+// replayStats tallies PDUs sent and responses received
+type replayStats struct {
+	sent      int
+	received  int
+	responses map[string]int
+	mu        sync.Mutex
+}
+
+func (s *replayStats) add(n *int) {
+	s.mu.Lock()
+	*n++
+	s.mu.Unlock()
+}
+
+// loadRecords reads a pcap or JSON-lines capture
+func loadRecords(path string) ([]capture.Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if !strings.HasSuffix(path, ".jsonl") && !strings.HasSuffix(path, ".json") {
+		return capture.ReadPCAP(bufio.NewReader(f))
+	}
+
+	var records []capture.Record
+	dec := json.NewDecoder(f)
+	for {
+		var r capture.Record
+		if err := dec.Decode(&r); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode record: %w", err)
+		}
+		records = append(records, r)
+	}
+}
+
+// rewriteBind replaces bind credentials when overrides are given
+func rewriteBind(raw []byte, systemID, password string) ([]byte, error) {
+	if systemID == "" && password == "" {
+		return raw, nil
+	}
+
+	p, err := pdu.Parse(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	bindReq, ok := p.(*pdu.BindRequest)
+	if !ok {
+		return raw, nil
+	}
+	if systemID != "" {
+		bindReq.SystemID = systemID
+	}
+	if password != "" {
+		bindReq.Password = password
+	}
+
+	buf := pdu.NewBuffer(nil)
+	bindReq.Marshal(buf)
+	return buf.Bytes(), nil
+}
+
+// readResponses logs PDUs from the target and answers enquire_link and deliver_sm
+func readResponses(conn net.Conn, write func([]byte) error, stats *replayStats) {
+	header := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			if err != io.EOF {
+				log.WithError(err).Debug("Read loop ended")
+			}
+			return
+		}
+
+		length := binary.BigEndian.Uint32(header[0:4])
+		if length < 16 || length > 65536 {
+			log.WithField("length", length).Error("Invalid PDU length from target")
+			return
+		}
+		body := make([]byte, length-16)
+		if _, err := io.ReadFull(conn, body); err != nil {
+			return
+		}
+
+		p, err := pdu.Parse(bytes.NewReader(append(header, body...)))
+		if err != nil {
+			log.WithError(err).Warn("Failed to parse PDU from target")
+			continue
+		}
+
+		h := p.GetHeader()
+		log.WithFields(log.Fields{
+			"command_id": h.CommandID,
+			"status":     h.CommandStatus,
+			"seq_num":    h.SequenceNumber,
+		}).Info("PDU from target")
+
+		stats.mu.Lock()
+		stats.received++
+		stats.responses[h.CommandID.String()+"/"+h.CommandStatus.String()]++
+		stats.mu.Unlock()
+
+		if h.CommandID == data.ENQUIRE_LINK || h.CommandID == data.DELIVER_SM {
+			buf := pdu.NewBuffer(nil)
+			p.GetResponse().Marshal(buf)
+			if err := write(buf.Bytes()); err != nil {
+				return
+			}
+		}
+		if h.CommandID == data.UNBIND_RESP {
+			return
+		}
+	}
+}
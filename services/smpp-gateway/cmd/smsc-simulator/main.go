@@ -0,0 +1,111 @@
+// smsc-simulator runs the built-in SMSC simulator as a standalone vendor
+// endpoint, so a locally running gateway can bind to it as if it were a carrier.
+//
+//	smsc-simulator -addr :2777 -latency 50ms -error-rate 0.05 -dlr-delay 2s
+//
+// MO messages can be scheduled per bind with repeated -mo flags
+// ("after,source,dest,text"), e.g. -mo "5s,15551230000,15559870000,STOP".
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ringer-warp/smpp-gateway/internal/simulator"
+	log "github.com/sirupsen/logrus"
+)
+
+// moFlags collects repeated -mo flags
+type moFlags []simulator.ScheduledMO
+
+func (m *moFlags) String() string {
+	return fmt.Sprintf("%d scheduled MO", len(*m))
+}
+
+func (m *moFlags) Set(value string) error {
+	parts := strings.SplitN(value, ",", 4)
+	if len(parts) != 4 {
+		return fmt.Errorf("expected after,source,dest,text")
+	}
+
+	after, err := time.ParseDuration(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid delay %q: %w", parts[0], err)
+	}
+
+	*m = append(*m, simulator.ScheduledMO{
+		After:      after,
+		SourceAddr: parts[1],
+		DestAddr:   parts[2],
+		Text:       parts[3],
+	})
+	return nil
+}
+
+func main() {
+	var mos moFlags
+
+	addr := flag.String("addr", ":2777", "Listen address")
+	systemID := flag.String("system-id", "", "Required system_id (empty accepts any)")
+	password := flag.String("password", "", "Required password (empty accepts any)")
+	latency := flag.Duration("latency", 0, "Delay before each submit_sm_resp")
+	errorRate := flag.Float64("error-rate", 0, "Fraction (0-1) of submits rejected with ESME_RSYSERR")
+	noDLR := flag.Bool("no-dlr", false, "Never send delivery receipts")
+	dlrDelay := flag.Duration("dlr-delay", time.Second, "Delay between submit and delivery receipt")
+	dlrFailureRate := flag.Float64("dlr-failure-rate", 0, "Fraction (0-1) of receipts reporting UNDELIV")
+	statsInterval := flag.Duration("stats-interval", 30*time.Second, "How often to log counters (0 = never)")
+	flag.Var(&mos, "mo", "Scheduled MO after bind as after,source,dest,text (repeatable)")
+	flag.Parse()
+
+	smsc := simulator.NewSMSC(simulator.SMSCConfig{
+		Addr:            *addr,
+		SystemID:        *systemID,
+		Password:        *password,
+		SubmitLatency:   *latency,
+		SubmitErrorRate: *errorRate,
+		DisableDLR:      *noDLR,
+		DLRDelay:        *dlrDelay,
+		DLRFailureRate:  *dlrFailureRate,
+		MOSchedule:      mos,
+	})
+	if err := smsc.Start(); err != nil {
+		log.WithError(err).Fatal("Failed to start SMSC simulator")
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	var tick <-chan time.Time
+	if *statsInterval > 0 {
+		ticker := time.NewTicker(*statsInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-tick:
+			logStats(smsc.Stats())
+		case <-sigChan:
+			log.Info("Shutting down SMSC simulator")
+			smsc.Close()
+			logStats(smsc.Stats())
+			return
+		}
+	}
+}
+
+func logStats(stats simulator.SMSCStats) {
+	log.WithFields(log.Fields{
+		"binds":         stats.Binds,
+		"submits":       stats.Submits,
+		"submit_errors": stats.SubmitErrors,
+		"dlrs_sent":     stats.DLRsSent,
+		"mos_sent":      stats.MOsSent,
+	}).Info("SMSC simulator stats")
+}
@@ -5,15 +5,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/ringer-warp/smpp-gateway/internal/capture"
 	"github.com/ringer-warp/smpp-gateway/internal/connectors"
 	"github.com/ringer-warp/smpp-gateway/internal/dlr"
+	"github.com/ringer-warp/smpp-gateway/internal/metrics"
 	"github.com/ringer-warp/smpp-gateway/internal/server"
+	"github.com/ringer-warp/smpp-gateway/internal/webhook"
 	log "github.com/sirupsen/logrus"
 )
 
+// defaultDrainTimeoutSec bounds an admin-triggered drain when no timeout is given
+const defaultDrainTimeoutSec = 60
+
 // Server provides HTTP management API
 type Server struct {
 	port         int
@@ -21,6 +27,8 @@ type Server struct {
 	smppServer   *server.SMPPServer
 	connectorMgr *connectors.Manager
 	dlrTracker   *dlr.Tracker
+	webhooks     *webhook.Dispatcher
+	captures     *capture.Manager
 }
 
 // NewServer creates a new management API server
@@ -33,6 +41,16 @@ func NewServer(port int, smppSrv *server.SMPPServer, connMgr *connectors.Manager
 	}
 }
 
+// SetWebhookDispatcher sets the status callback dispatcher
+func (s *Server) SetWebhookDispatcher(dispatcher *webhook.Dispatcher) {
+	s.webhooks = dispatcher
+}
+
+// SetCaptureManager sets the PDU capture manager
+func (s *Server) SetCaptureManager(captures *capture.Manager) {
+	s.captures = captures
+}
+
 // Start starts the management API server
 func (s *Server) Start(ctx context.Context) error {
 	mux := http.NewServeMux()
@@ -41,8 +59,8 @@ func (s *Server) Start(ctx context.Context) error {
 	mux.HandleFunc("/health", s.handleHealth)
 	mux.HandleFunc("/ready", s.handleReady)
 
-	// Metrics (Prometheus)
-	mux.Handle("/metrics", promhttp.Handler())
+	// Metrics (Prometheus) - also served on the dedicated metrics port
+	mux.Handle("/metrics", metrics.Handler())
 
 	// Vendor management
 	mux.HandleFunc("/api/v1/vendors", s.handleListVendors)
@@ -56,7 +74,21 @@ func (s *Server) Start(ctx context.Context) error {
 
 	// Admin operations
 	mux.HandleFunc("/api/v1/admin/stats", s.handleStats)
-	mux.HandleFunc("/api/v1/admin/sessions", s.handleSessions)
+	mux.HandleFunc("/api/v1/admin/sessions", s.handleSessions)            // GET ?customer_id=&system_id=a,b&scope=cluster
+	mux.HandleFunc("/api/v1/admin/sessions/unbind/", s.handleForceUnbind) // POST /api/v1/admin/sessions/unbind/{system_id}
+	mux.HandleFunc("/api/v1/admin/drain", s.handleDrain)                  // GET status, POST {timeout_sec} to start
+	mux.HandleFunc("/api/v1/admin/cluster", s.handleCluster)              // GET live gateway instances
+
+	// Status callback webhooks
+	mux.HandleFunc("/api/v1/admin/webhooks/config/", s.handleWebhookConfig)        // GET/PUT /api/v1/admin/webhooks/config/{customer_id}
+	mux.HandleFunc("/api/v1/admin/webhooks/dead-letters", s.handleListDeadLetters) // GET ?customer_id=&limit=
+	mux.HandleFunc("/api/v1/admin/webhooks/replay/", s.handleReplayDeadLetter)     // POST /api/v1/admin/webhooks/replay/{id}
+
+	// PDU capture (troubleshooting)
+	mux.HandleFunc("/api/v1/admin/captures", s.handleCaptures)                 // GET, DELETE ?scope=&id=
+	mux.HandleFunc("/api/v1/admin/captures/start", s.handleStartCapture)       // POST {scope, id, max_records, max_bytes, duration_sec}
+	mux.HandleFunc("/api/v1/admin/captures/stop", s.handleStopCapture)         // POST {scope, id}
+	mux.HandleFunc("/api/v1/admin/captures/download", s.handleDownloadCapture) // GET ?scope=&id=&format=pcap|json
 
 	s.httpServer = &http.Server{
 		Addr:         fmt.Sprintf(":%d", s.port),
@@ -111,6 +143,16 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // handleReady returns readiness status
 func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	// A draining instance must be taken out of the load balancer first
+	if s.smppServer.IsDraining() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"ready":  false,
+			"reason": "draining",
+		})
+		return
+	}
+
 	// Check if at least one vendor is connected
 	health := s.connectorMgr.HealthCheck()
 	connected := 0
@@ -218,18 +260,152 @@ func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleSessions returns active customer sessions
+// handleSessions returns active customer sessions on this instance, or with
+// scope=cluster a customer's sessions on every instance
 func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("scope") == "cluster" {
+		s.handleClusterSessions(w, r)
+		return
+	}
+
+	var systemIDs []string
+	if ids := r.URL.Query().Get("system_id"); ids != "" {
+		systemIDs = strings.Split(ids, ",")
+	}
+
+	sessions := s.smppServer.ListSessions(r.URL.Query().Get("customer_id"), systemIDs)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    sessions,
+		"count":   len(sessions),
+	})
+}
+
+// handleClusterSessions returns a customer's sessions across all instances
+func (s *Server) handleClusterSessions(w http.ResponseWriter, r *http.Request) {
+	customerID := r.URL.Query().Get("customer_id")
+	if customerID == "" {
+		writeError(w, http.StatusBadRequest, "customer_id required for scope=cluster")
+		return
+	}
+
+	sessions, err := s.smppServer.ClusterSessions(r.Context(), customerID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    sessions,
+		"count":   len(sessions),
+	})
+}
+
+// handleCluster returns this instance's ID and all live gateway instances
+func (s *Server) handleCluster(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	instanceID, instances, err := s.smppServer.ClusterInstances(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
 		"data": map[string]interface{}{
-			"active_count": s.smppServer.GetMetrics()["active_sessions"],
-			"note":         "Detailed session info coming soon",
+			"instance_id": instanceID,
+			"instances":   instances,
 		},
+		"count": len(instances),
+	})
+}
+
+// handleForceUnbind unbinds a customer session and closes its connection
+func (s *Server) handleForceUnbind(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	systemID := r.URL.Path[len("/api/v1/admin/sessions/unbind/"):]
+	if systemID == "" {
+		writeError(w, http.StatusBadRequest, "system_id required")
+		return
+	}
+
+	if err := s.smppServer.ForceUnbind(systemID); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"message":   "Session unbound",
+		"system_id": systemID,
 	})
 }
 
+// handleDrain starts a drain (POST) or reports its progress (GET)
+func (s *Server) handleDrain(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"data":    s.smppServer.DrainStatus(),
+		})
+
+	case http.MethodPost:
+		var req struct {
+			TimeoutSec int `json:"timeout_sec"`
+		}
+		if r.ContentLength > 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid JSON body")
+				return
+			}
+		}
+		if req.TimeoutSec <= 0 {
+			req.TimeoutSec = defaultDrainTimeoutSec
+		}
+
+		if s.smppServer.IsDraining() {
+			writeError(w, http.StatusConflict, server.ErrAlreadyDraining.Error())
+			return
+		}
+
+		// Drain outlives the request; progress is reported by GET
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(req.TimeoutSec)*time.Second)
+			defer cancel()
+			if _, err := s.smppServer.Drain(ctx); err != nil {
+				log.WithError(err).Warn("Drain did not start")
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"message":     "Drain started",
+			"timeout_sec": req.TimeoutSec,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 // handleVendorReconnect reloads vendor config and reconnects
 func (s *Server) handleVendorReconnect(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// captureRequest identifies a capture and its limits
+type captureRequest struct {
+	Scope       string `json:"scope"` // "customer" or "vendor"
+	ID          string `json:"id"`    // customer ID or vendor ID
+	MaxRecords  int    `json:"max_records"`
+	MaxBytes    int    `json:"max_bytes"`
+	DurationSec int    `json:"duration_sec"`
+}
+
+// handleCaptures lists PDU captures or deletes one
+func (s *Server) handleCaptures(w http.ResponseWriter, r *http.Request) {
+	if !s.requireCaptures(w) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		captures := s.captures.List()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"data":    captures,
+			"count":   len(captures),
+		})
+
+	case http.MethodDelete:
+		c, err := s.captures.Delete(r.URL.Query().Get("scope"), r.URL.Query().Get("id"))
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"message": "Capture deleted",
+			"data":    c.Info(),
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleStartCapture starts a PDU capture for a customer or vendor
+func (s *Server) handleStartCapture(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.requireCaptures(w) {
+		return
+	}
+
+	var req captureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	c, err := s.captures.Start(req.Scope, req.ID, req.MaxRecords, req.MaxBytes, time.Duration(req.DurationSec)*time.Second)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Capture started",
+		"data":    c.Info(),
+	})
+}
+
+// handleStopCapture stops a running PDU capture
+func (s *Server) handleStopCapture(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.requireCaptures(w) {
+		return
+	}
+
+	var req captureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	c, err := s.captures.Stop(req.Scope, req.ID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Capture stopped",
+		"data":    c.Info(),
+	})
+}
+
+// handleDownloadCapture downloads a capture as pcap (default) or JSON lines.
+// A finished capture is discarded once downloaded.
+func (s *Server) handleDownloadCapture(w http.ResponseWriter, r *http.Request) {
+	if !s.requireCaptures(w) {
+		return
+	}
+
+	scope := r.URL.Query().Get("scope")
+	id := r.URL.Query().Get("id")
+
+	c, exists := s.captures.Get(scope, id)
+	if !exists {
+		writeError(w, http.StatusNotFound, "Capture not found")
+		return
+	}
+
+	filename := fmt.Sprintf("smpp-%s-%s-%s", scope, id, time.Now().UTC().Format("20060102T150405Z"))
+
+	var err error
+	switch r.URL.Query().Get("format") {
+	case "json":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".jsonl"))
+		err = c.WriteJSON(w)
+	default:
+		w.Header().Set("Content-Type", "application/vnd.tcpdump.pcap")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".pcap"))
+		err = c.WritePCAP(w)
+	}
+
+	if err != nil {
+		log.WithError(err).Error("Failed to write capture download")
+		return
+	}
+	if !c.Recording() {
+		s.captures.Remove(c)
+	}
+}
+
+// requireCaptures writes 503 if the capture manager is not configured
+func (s *Server) requireCaptures(w http.ResponseWriter) bool {
+	if s.captures == nil {
+		writeError(w, http.StatusServiceUnavailable, "PDU capture not configured")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/ringer-warp/smpp-gateway/internal/models"
+	"github.com/ringer-warp/smpp-gateway/internal/webhook"
+)
+
+// handleWebhookConfig gets or replaces a customer's status callback config
+func (s *Server) handleWebhookConfig(w http.ResponseWriter, r *http.Request) {
+	if !s.requireWebhooks(w) {
+		return
+	}
+
+	customerID := r.URL.Path[len("/api/v1/admin/webhooks/config/"):]
+	if customerID == "" {
+		http.Error(w, "Customer ID required", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		cfg, err := s.webhooks.GetConfig(ctx, customerID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if cfg == nil {
+			writeError(w, http.StatusNotFound, "Webhook config not found")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"data":    cfg,
+		})
+
+	case http.MethodPut:
+		var req struct {
+			URL      string `json:"url"`
+			Secret   string `json:"secret"`
+			IsActive *bool  `json:"is_active"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+		if err := webhook.ValidateURL(ctx, req.URL); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(req.Secret) < 16 {
+			writeError(w, http.StatusBadRequest, "secret must be at least 16 characters")
+			return
+		}
+
+		cfg := &models.WebhookConfig{
+			CustomerID: customerID,
+			URL:        req.URL,
+			Secret:     req.Secret,
+			IsActive:   req.IsActive == nil || *req.IsActive,
+		}
+		if err := s.webhooks.SaveConfig(ctx, cfg); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"message":     "Webhook config saved",
+			"customer_id": customerID,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleListDeadLetters lists callbacks that exhausted their retries
+func (s *Server) handleListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if !s.requireWebhooks(w) {
+		return
+	}
+
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 1000 {
+			limit = n
+		}
+	}
+
+	letters, err := s.webhooks.ListDeadLetters(r.Context(), r.URL.Query().Get("customer_id"), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    letters,
+		"count":   len(letters),
+	})
+}
+
+// handleReplayDeadLetter re-queues a dead-lettered callback
+func (s *Server) handleReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.requireWebhooks(w) {
+		return
+	}
+
+	id := r.URL.Path[len("/api/v1/admin/webhooks/replay/"):]
+	if id == "" {
+		http.Error(w, "Dead letter ID required", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.webhooks.Replay(r.Context(), id); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Callback replay queued",
+		"id":      id,
+	})
+}
+
+// requireWebhooks writes 503 if the webhook dispatcher is not configured
+func (s *Server) requireWebhooks(w http.ResponseWriter) bool {
+	if s.webhooks == nil {
+		writeError(w, http.StatusServiceUnavailable, "Webhook dispatcher not configured")
+		return false
+	}
+	return true
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   message,
+	})
+}
@@ -0,0 +1,110 @@
+// Package auth authenticates customer SMPP binds against the system_id
+// credentials managed through the API gateway (messaging.customer_sms_auth).
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrUnknownSystemID is returned when no customer credential has the system_id
+	ErrUnknownSystemID = errors.New("unknown system_id")
+	// ErrInvalidPassword is returned when the password does not match
+	ErrInvalidPassword = errors.New("invalid password")
+	// ErrDisabled is returned when the credential has been deactivated
+	ErrDisabled = errors.New("system_id is disabled")
+	// ErrIPNotAllowed is returned when the bind comes from an address outside the allow list
+	ErrIPNotAllowed = errors.New("source address not allowed for system_id")
+)
+
+// Credential is an authenticated system_id with its bind limits
+type Credential struct {
+	ID         string
+	CustomerID string
+	SystemID   string
+	MaxBinds   int // Concurrent binds across the cluster
+	Throughput int // submit_sm per second
+}
+
+// Authenticator verifies bind credentials in PostgreSQL
+type Authenticator struct {
+	db *pgxpool.Pool
+}
+
+// NewAuthenticator creates a new authenticator
+func NewAuthenticator(db *pgxpool.Pool) *Authenticator {
+	return &Authenticator{db: db}
+}
+
+// Authenticate checks a bind's system_id, password and source address
+func (a *Authenticator) Authenticate(ctx context.Context, systemID, password string, remoteIP net.IP) (*Credential, error) {
+	query := `
+		SELECT id, customer_id, smpp_password_hash, smpp_allowed_ips::text[],
+		       smpp_max_binds, smpp_throughput, active
+		FROM messaging.customer_sms_auth
+		WHERE smpp_system_id = $1 AND customer_id IS NOT NULL AND smpp_password_hash IS NOT NULL
+	`
+
+	cred := &Credential{SystemID: systemID}
+	var (
+		passwordHash string
+		allowedIPs   []string
+		active       bool
+	)
+	err := a.db.QueryRow(ctx, query, systemID).Scan(
+		&cred.ID, &cred.CustomerID, &passwordHash, &allowedIPs,
+		&cred.MaxBinds, &cred.Throughput, &active,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUnknownSystemID
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load credential: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidPassword
+	}
+	if !active {
+		return nil, ErrDisabled
+	}
+	if !IPAllowed(allowedIPs, remoteIP) {
+		return nil, ErrIPNotAllowed
+	}
+
+	return cred, nil
+}
+
+// IPAllowed reports whether ip matches an entry of the allow list. Entries are
+// addresses or CIDRs as rendered by PostgreSQL inet; an empty list allows any address.
+func IPAllowed(allowed []string, ip net.IP) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+
+	for _, entry := range allowed {
+		if strings.Contains(entry, "/") {
+			_, network, err := net.ParseCIDR(entry)
+			if err == nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if allowedIP := net.ParseIP(entry); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,423 @@
+package capture
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Capture scopes
+const (
+	ScopeCustomer = "customer"
+	ScopeVendor   = "vendor"
+)
+
+// Direction of a captured PDU relative to the gateway
+type Direction string
+
+const (
+	DirectionIn  Direction = "in"  // Received by the gateway from the peer
+	DirectionOut Direction = "out" // Sent by the gateway to the peer
+)
+
+const (
+	DefaultMaxRecords = 10000
+	MaxRecords        = 50000
+	DefaultMaxBytes   = 16 << 20 // Raw PDU bytes kept per capture
+	MaxBytes          = 64 << 20 // Bounds memory: PDUs are up to 64KB each
+	DefaultDuration   = 15 * time.Minute
+	MaxDuration       = time.Hour
+	Retention         = time.Hour // Finished captures are discarded after this
+)
+
+// Record is a single raw PDU with its capture timestamp
+type Record struct {
+	Time      time.Time `json:"time"`
+	Direction Direction `json:"direction"`
+	Remote    string    `json:"remote"`
+	Data      []byte    `json:"data"` // Raw PDU bytes including the 16-byte header
+}
+
+// Info summarizes a capture for listing
+type Info struct {
+	Scope      string     `json:"scope"`
+	Key        string     `json:"id"`
+	Recording  bool       `json:"recording"`
+	Records    int        `json:"records"`
+	MaxRecords int        `json:"max_records"`
+	Bytes      int        `json:"bytes"`
+	MaxBytes   int        `json:"max_bytes"`
+	Dropped    int64      `json:"dropped"`
+	StartedAt  time.Time  `json:"started_at"`
+	StoppedAt  *time.Time `json:"stopped_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // When a finished capture is discarded
+}
+
+// Capture is a ring buffer of PDUs for one customer or vendor, bounded by
+// record count and by total PDU bytes
+type Capture struct {
+	scope     string
+	key       string
+	max       int
+	maxBytes  int
+	startedAt time.Time
+	stoppedAt *time.Time
+	expiresAt *time.Time
+	recording bool
+	ring      []Record
+	start     int // Index of the oldest record
+	count     int
+	bytes     int
+	dropped   int64
+	timer     *time.Timer
+	mu        sync.Mutex
+}
+
+// Manager tracks active and completed captures
+type Manager struct {
+	captures  map[string]*Capture
+	active    atomic.Int32
+	retention time.Duration
+	mu        sync.RWMutex
+}
+
+// NewManager creates a new capture manager
+func NewManager() *Manager {
+	return &Manager{
+		captures:  make(map[string]*Capture),
+		retention: Retention,
+	}
+}
+
+func captureKey(scope, key string) string {
+	return scope + ":" + key
+}
+
+// Start begins capturing PDUs for a customer or vendor, replacing any previous capture
+func (m *Manager) Start(scope, key string, maxRecords, maxBytes int, duration time.Duration) (*Capture, error) {
+	if scope != ScopeCustomer && scope != ScopeVendor {
+		return nil, fmt.Errorf("invalid capture scope: %s", scope)
+	}
+	if key == "" {
+		return nil, fmt.Errorf("capture id required")
+	}
+	if maxRecords <= 0 {
+		maxRecords = DefaultMaxRecords
+	}
+	if maxRecords > MaxRecords {
+		maxRecords = MaxRecords
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if maxBytes > MaxBytes {
+		maxBytes = MaxBytes
+	}
+	if duration <= 0 {
+		duration = DefaultDuration
+	}
+	if duration > MaxDuration {
+		duration = MaxDuration
+	}
+
+	c := &Capture{
+		scope:     scope,
+		key:       key,
+		max:       maxRecords,
+		maxBytes:  maxBytes,
+		startedAt: time.Now(),
+		recording: true,
+		ring:      make([]Record, maxRecords),
+	}
+
+	m.mu.Lock()
+	if prev, exists := m.captures[captureKey(scope, key)]; exists {
+		if prev.stop(0, nil) {
+			m.active.Add(-1)
+		}
+		prev.discard()
+	}
+	m.captures[captureKey(scope, key)] = c
+	m.active.Add(1)
+	m.mu.Unlock()
+
+	// Auto-stop so a forgotten capture doesn't run forever
+	c.mu.Lock()
+	c.timer = time.AfterFunc(duration, func() {
+		m.Stop(scope, key)
+	})
+	c.mu.Unlock()
+
+	log.WithFields(log.Fields{
+		"scope":       scope,
+		"id":          key,
+		"max_records": maxRecords,
+		"max_bytes":   maxBytes,
+		"duration":    duration,
+	}).Info("PDU capture started")
+
+	return c, nil
+}
+
+// Stop ends a capture; its records remain available for download until
+// exported or for Retention, whichever comes first
+func (m *Manager) Stop(scope, key string) (*Capture, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, exists := m.captures[captureKey(scope, key)]
+	if !exists {
+		return nil, fmt.Errorf("capture not found: %s %s", scope, key)
+	}
+
+	if c.stop(m.retention, func() { m.Remove(c) }) {
+		m.active.Add(-1)
+		log.WithFields(log.Fields{
+			"scope": scope,
+			"id":    key,
+		}).Info("PDU capture stopped")
+	}
+
+	return c, nil
+}
+
+// Delete stops a capture and discards its records
+func (m *Manager) Delete(scope, key string) (*Capture, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, exists := m.captures[captureKey(scope, key)]
+	if !exists {
+		return nil, fmt.Errorf("capture not found: %s %s", scope, key)
+	}
+
+	if c.stop(0, nil) {
+		m.active.Add(-1)
+	}
+	delete(m.captures, captureKey(scope, key))
+	c.discard()
+
+	log.WithFields(log.Fields{
+		"scope": scope,
+		"id":    key,
+	}).Info("PDU capture deleted")
+
+	return c, nil
+}
+
+// Remove discards a finished capture if it has not been replaced by a newer one
+func (m *Manager) Remove(c *Capture) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	k := captureKey(c.scope, c.key)
+	if m.captures[k] != c || c.Recording() {
+		return
+	}
+	delete(m.captures, k)
+	c.discard()
+}
+
+// Get returns a capture by scope and key
+func (m *Manager) Get(scope, key string) (*Capture, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, exists := m.captures[captureKey(scope, key)]
+	return c, exists
+}
+
+// List returns a summary of all captures
+func (m *Manager) List() []*Info {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	infos := make([]*Info, 0, len(m.captures))
+	for _, c := range m.captures {
+		infos = append(infos, c.Info())
+	}
+
+	return infos
+}
+
+// Active reports whether any capture is recording (cheap check for hot paths)
+func (m *Manager) Active() bool {
+	return m != nil && m.active.Load() > 0
+}
+
+// Record stores a raw PDU if a capture is recording for the scope and key
+func (m *Manager) Record(scope, key string, dir Direction, remote string, data []byte) {
+	if !m.Active() || key == "" {
+		return
+	}
+
+	m.mu.RLock()
+	c, exists := m.captures[captureKey(scope, key)]
+	m.mu.RUnlock()
+
+	if !exists {
+		return
+	}
+
+	c.add(Record{
+		Time:      time.Now(),
+		Direction: dir,
+		Remote:    remote,
+		Data:      redactBindPassword(data),
+	})
+}
+
+// stop marks the capture stopped, returning true if it was recording. With a
+// positive retention, expire runs once the finished capture has been kept that long.
+func (c *Capture) stop(retention time.Duration, expire func()) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.recording {
+		return false
+	}
+
+	now := time.Now()
+	c.recording = false
+	c.stoppedAt = &now
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	if retention > 0 && expire != nil {
+		expiresAt := now.Add(retention)
+		c.expiresAt = &expiresAt
+		c.timer = time.AfterFunc(retention, expire)
+	}
+
+	return true
+}
+
+// discard releases a removed capture's records
+func (c *Capture) discard() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.ring = nil
+	c.start, c.count, c.bytes = 0, 0, 0
+}
+
+// Recording reports whether the capture is still recording
+func (c *Capture) Recording() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.recording
+}
+
+// add appends a record, evicting the oldest while the ring is over its record
+// or byte limit. A single PDU larger than the byte limit is dropped.
+func (c *Capture) add(r Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.recording {
+		return
+	}
+	if len(r.Data) > c.maxBytes {
+		c.dropped++
+		return
+	}
+
+	for c.count > 0 && (c.count == c.max || c.bytes+len(r.Data) > c.maxBytes) {
+		c.bytes -= len(c.ring[c.start].Data)
+		c.ring[c.start] = Record{}
+		c.start = (c.start + 1) % c.max
+		c.count--
+		c.dropped++
+	}
+
+	c.ring[(c.start+c.count)%c.max] = r
+	c.count++
+	c.bytes += len(r.Data)
+}
+
+// Records returns captured PDUs in chronological order
+func (c *Capture) Records() []Record {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	records := make([]Record, 0, c.count)
+	for i := 0; i < c.count; i++ {
+		records = append(records, c.ring[(c.start+i)%c.max])
+	}
+	return records
+}
+
+// Info returns a summary of the capture
+func (c *Capture) Info() *Info {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return &Info{
+		Scope:      c.scope,
+		Key:        c.key,
+		Recording:  c.recording,
+		Records:    c.count,
+		MaxRecords: c.max,
+		Bytes:      c.bytes,
+		MaxBytes:   c.maxBytes,
+		Dropped:    c.dropped,
+		StartedAt:  c.startedAt,
+		StoppedAt:  c.stoppedAt,
+		ExpiresAt:  c.expiresAt,
+	}
+}
+
+// WriteJSON writes the capture as JSON lines, one record per line
+func (c *Capture) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, r := range c.Records() {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WritePCAP writes the capture as a pcap file (see pcap.go)
+func (c *Capture) WritePCAP(w io.Writer) error {
+	return WritePCAP(w, c.Records())
+}
+
+// Bind command IDs whose body starts with system_id\0password\0
+var bindCommandIDs = map[uint32]bool{
+	0x00000001: true, // bind_receiver
+	0x00000002: true, // bind_transmitter
+	0x00000009: true, // bind_transceiver
+}
+
+// redactBindPassword masks the password in bind PDUs without changing the PDU length
+func redactBindPassword(data []byte) []byte {
+	if len(data) < 16 || !bindCommandIDs[binary.BigEndian.Uint32(data[4:8])] {
+		return data
+	}
+
+	out := make([]byte, len(data))
+	copy(out, data)
+
+	// Skip system_id C-string
+	i := 16
+	for i < len(out) && out[i] != 0 {
+		i++
+	}
+
+	// Mask password C-string
+	for i++; i < len(out) && out[i] != 0; i++ {
+		out[i] = '*'
+	}
+
+	return out
+}
@@ -0,0 +1,114 @@
+package capture
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// pdu returns n bytes tagged with b so records can be told apart
+func pdu(b byte, n int) []byte {
+	return bytes.Repeat([]byte{b}, n)
+}
+
+func TestCaptureLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxRecords  int
+		maxBytes    int
+		sizes       []int
+		wantKept    string // Tags of the records kept, oldest first
+		wantDropped int64
+	}{
+		{"within limits", 10, 100, []int{10, 10, 10}, "abc", 0},
+		{"record limit", 2, 100, []int{10, 10, 10}, "bc", 1},
+		{"byte limit", 10, 25, []int{10, 10, 10}, "bc", 1},
+		{"large PDU evicts several", 10, 25, []int{10, 10, 20}, "c", 2},
+		{"PDU over byte limit", 10, 25, []int{10, 30}, "a", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager()
+			c, err := m.Start(ScopeVendor, "v1", tt.maxRecords, tt.maxBytes, time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer m.Delete(ScopeVendor, "v1")
+
+			for i, size := range tt.sizes {
+				m.Record(ScopeVendor, "v1", DirectionOut, "peer", pdu(byte('a'+i), size))
+			}
+
+			var kept []byte
+			total := 0
+			for _, r := range c.Records() {
+				kept = append(kept, r.Data[0])
+				total += len(r.Data)
+			}
+			info := c.Info()
+			if string(kept) != tt.wantKept || info.Dropped != tt.wantDropped {
+				t.Errorf("kept %q dropped %d, want %q dropped %d", kept, info.Dropped, tt.wantKept, tt.wantDropped)
+			}
+			if info.Bytes != total || info.Bytes > tt.maxBytes {
+				t.Errorf("bytes = %d, want %d within %d", info.Bytes, total, tt.maxBytes)
+			}
+		})
+	}
+}
+
+func TestFinishedCaptureExpires(t *testing.T) {
+	m := NewManager()
+	m.retention = 20 * time.Millisecond
+
+	if _, err := m.Start(ScopeCustomer, "c1", 0, 0, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	c, err := m.Stop(ScopeCustomer, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Info().ExpiresAt == nil {
+		t.Error("stopped capture has no expiry")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, exists := m.Get(ScopeCustomer, "c1"); !exists {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("finished capture was not discarded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRemoveAndDelete(t *testing.T) {
+	m := NewManager()
+
+	// A recording capture survives Remove (e.g. downloaded mid-capture)
+	c, _ := m.Start(ScopeVendor, "v1", 0, 0, time.Minute)
+	m.Remove(c)
+	if _, exists := m.Get(ScopeVendor, "v1"); !exists {
+		t.Fatal("Remove discarded a recording capture")
+	}
+
+	// Removing a replaced capture leaves its successor alone
+	m.Stop(ScopeVendor, "v1")
+	next, _ := m.Start(ScopeVendor, "v1", 0, 0, time.Minute)
+	m.Remove(c)
+	if got, _ := m.Get(ScopeVendor, "v1"); got != next {
+		t.Fatal("Remove discarded the replacement capture")
+	}
+
+	if _, err := m.Delete(ScopeVendor, "v1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := m.Get(ScopeVendor, "v1"); exists || m.Active() {
+		t.Error("deleted capture still present or recording")
+	}
+	if _, err := m.Delete(ScopeVendor, "v1"); err == nil {
+		t.Error("deleting a missing capture succeeded")
+	}
+}
@@ -0,0 +1,85 @@
+package capture
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+)
+
+// Conn wraps a net.Conn and records every PDU read from or written to it.
+// Bytes are reassembled into whole PDUs using the command_length header.
+type Conn struct {
+	net.Conn
+	mgr   *Manager
+	scope string
+	key   string
+
+	readMu   sync.Mutex
+	readBuf  []byte
+	writeMu  sync.Mutex
+	writeBuf []byte
+}
+
+// WrapConn returns a connection that records PDUs for the given scope and key.
+// Recording only happens while a capture is active, so wrapping is always safe.
+func (m *Manager) WrapConn(conn net.Conn, scope, key string) net.Conn {
+	if m == nil {
+		return conn
+	}
+	return &Conn{
+		Conn:  conn,
+		mgr:   m,
+		scope: scope,
+		key:   key,
+	}
+}
+
+// Read implements net.Conn
+func (c *Conn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.readMu.Lock()
+		c.readBuf = c.frame(c.readBuf, b[:n], DirectionIn)
+		c.readMu.Unlock()
+	}
+	return n, err
+}
+
+// Write implements net.Conn
+func (c *Conn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.writeMu.Lock()
+		c.writeBuf = c.frame(c.writeBuf, b[:n], DirectionOut)
+		c.writeMu.Unlock()
+	}
+	return n, err
+}
+
+// frame appends data to buf, records each complete PDU and returns the remainder
+func (c *Conn) frame(buf, data []byte, dir Direction) []byte {
+	if !c.mgr.Active() {
+		return nil // Drop partial state while idle; resync on the next PDU boundary
+	}
+
+	buf = append(buf, data...)
+	for len(buf) >= 4 {
+		length := int(binary.BigEndian.Uint32(buf[0:4]))
+		if length < 16 || length > 65536 {
+			return nil // Lost framing (e.g. capture started mid-PDU); wait for resync
+		}
+		if len(buf) < length {
+			break
+		}
+
+		pdu := make([]byte, length)
+		copy(pdu, buf[:length])
+		c.mgr.Record(c.scope, c.key, dir, c.Conn.RemoteAddr().String(), pdu)
+		buf = buf[length:]
+	}
+
+	if len(buf) == 0 {
+		return nil
+	}
+	return buf
+}
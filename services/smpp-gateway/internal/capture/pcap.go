@@ -0,0 +1,162 @@
+package capture
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Captures are exported as pcap (LINKTYPE_RAW) with synthetic IPv4/TCP headers
+// so Wireshark's SMPP dissector decodes them on port 2775. The peer is always
+// 10.0.0.1:40000 and the gateway 10.0.0.2:2775; direction is recovered from
+// the destination port on read.
+
+const (
+	pcapMagic       = 0xa1b2c3d4
+	pcapVersionMaj  = 2
+	pcapVersionMin  = 4
+	pcapSnapLen     = 65535
+	linkTypeRaw     = 101
+	ipv4HeaderLen   = 20
+	tcpHeaderLen    = 20
+	gatewayPort     = 2775
+	peerPort        = 40000
+	tcpFlagsPSHACK  = 0x18
+	ipProtocolTCP   = 6
+	defaultIPv4TTL  = 64
+	pcapRecHdrLen   = 16
+	pcapFileHdrLen  = 24
+	syntheticHdrLen = ipv4HeaderLen + tcpHeaderLen
+)
+
+var (
+	peerIP    = net.IPv4(10, 0, 0, 1).To4()
+	gatewayIP = net.IPv4(10, 0, 0, 2).To4()
+)
+
+// WritePCAP writes records as a pcap stream
+func WritePCAP(w io.Writer, records []Record) error {
+	hdr := make([]byte, pcapFileHdrLen)
+	binary.LittleEndian.PutUint32(hdr[0:4], pcapMagic)
+	binary.LittleEndian.PutUint16(hdr[4:6], pcapVersionMaj)
+	binary.LittleEndian.PutUint16(hdr[6:8], pcapVersionMin)
+	binary.LittleEndian.PutUint32(hdr[16:20], pcapSnapLen)
+	binary.LittleEndian.PutUint32(hdr[20:24], linkTypeRaw)
+	if _, err := w.Write(hdr); err != nil {
+		return fmt.Errorf("failed to write pcap header: %w", err)
+	}
+
+	// Track TCP sequence numbers per direction so reassembly works
+	var seqIn, seqOut uint32 = 1, 1
+
+	for _, r := range records {
+		packet := make([]byte, syntheticHdrLen+len(r.Data))
+		copy(packet[syntheticHdrLen:], r.Data)
+
+		src, dst := peerIP, gatewayIP
+		srcPort, dstPort := uint16(peerPort), uint16(gatewayPort)
+		seq, ack := &seqIn, seqOut
+		if r.Direction == DirectionOut {
+			src, dst = gatewayIP, peerIP
+			srcPort, dstPort = gatewayPort, peerPort
+			seq, ack = &seqOut, seqIn
+		}
+
+		// IPv4 header
+		ip := packet[:ipv4HeaderLen]
+		ip[0] = 0x45
+		binary.BigEndian.PutUint16(ip[2:4], uint16(len(packet)))
+		ip[8] = defaultIPv4TTL
+		ip[9] = ipProtocolTCP
+		copy(ip[12:16], src)
+		copy(ip[16:20], dst)
+		binary.BigEndian.PutUint16(ip[10:12], ipChecksum(ip))
+
+		// TCP header (checksum left zero)
+		tcp := packet[ipv4HeaderLen:syntheticHdrLen]
+		binary.BigEndian.PutUint16(tcp[0:2], srcPort)
+		binary.BigEndian.PutUint16(tcp[2:4], dstPort)
+		binary.BigEndian.PutUint32(tcp[4:8], *seq)
+		binary.BigEndian.PutUint32(tcp[8:12], ack)
+		tcp[12] = (tcpHeaderLen / 4) << 4
+		tcp[13] = tcpFlagsPSHACK
+		binary.BigEndian.PutUint16(tcp[14:16], 0xffff)
+		*seq += uint32(len(r.Data))
+
+		rec := make([]byte, pcapRecHdrLen)
+		binary.LittleEndian.PutUint32(rec[0:4], uint32(r.Time.Unix()))
+		binary.LittleEndian.PutUint32(rec[4:8], uint32(r.Time.Nanosecond()/1000))
+		binary.LittleEndian.PutUint32(rec[8:12], uint32(len(packet)))
+		binary.LittleEndian.PutUint32(rec[12:16], uint32(len(packet)))
+
+		if _, err := w.Write(rec); err != nil {
+			return fmt.Errorf("failed to write pcap record: %w", err)
+		}
+		if _, err := w.Write(packet); err != nil {
+			return fmt.Errorf("failed to write pcap packet: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ReadPCAP reads records from a pcap stream written by WritePCAP
+func ReadPCAP(r io.Reader) ([]Record, error) {
+	hdr := make([]byte, pcapFileHdrLen)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, fmt.Errorf("failed to read pcap header: %w", err)
+	}
+	if binary.LittleEndian.Uint32(hdr[0:4]) != pcapMagic {
+		return nil, fmt.Errorf("unsupported pcap format (expected little-endian microsecond pcap)")
+	}
+	if binary.LittleEndian.Uint32(hdr[20:24]) != linkTypeRaw {
+		return nil, fmt.Errorf("unsupported pcap link type: %d", binary.LittleEndian.Uint32(hdr[20:24]))
+	}
+
+	var records []Record
+	rec := make([]byte, pcapRecHdrLen)
+	for {
+		if _, err := io.ReadFull(r, rec); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read pcap record: %w", err)
+		}
+
+		sec := binary.LittleEndian.Uint32(rec[0:4])
+		usec := binary.LittleEndian.Uint32(rec[4:8])
+		inclLen := binary.LittleEndian.Uint32(rec[8:12])
+		if inclLen < syntheticHdrLen || inclLen > pcapSnapLen {
+			return nil, fmt.Errorf("invalid pcap record length: %d", inclLen)
+		}
+
+		packet := make([]byte, inclLen)
+		if _, err := io.ReadFull(r, packet); err != nil {
+			return nil, fmt.Errorf("failed to read pcap packet: %w", err)
+		}
+
+		dir := DirectionIn
+		if binary.BigEndian.Uint16(packet[ipv4HeaderLen+2:ipv4HeaderLen+4]) != gatewayPort {
+			dir = DirectionOut
+		}
+
+		records = append(records, Record{
+			Time:      time.Unix(int64(sec), int64(usec)*1000),
+			Direction: dir,
+			Data:      packet[syntheticHdrLen:],
+		})
+	}
+}
+
+// ipChecksum computes the IPv4 header checksum
+func ipChecksum(header []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(header); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(header[i : i+2]))
+	}
+	for sum > 0xffff {
+		sum = (sum >> 16) + (sum & 0xffff)
+	}
+	return ^uint16(sum)
+}
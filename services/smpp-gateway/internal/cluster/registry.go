@@ -0,0 +1,585 @@
+// Package cluster lets several SMPP gateway replicas behave as one: it keeps a
+// shared registry of customer binds in Redis, enforces per-customer bind limits
+// across all replicas, and forwards DLRs, MO messages and unbind requests to
+// the replica that holds the customer's bind.
+//
+// Redis layout:
+//
+//	smpp:instances                  SET     IDs of replicas that have joined and not left
+//	smpp:instance:{id}              STRING  heartbeat, expires if the replica dies
+//	smpp:instance:{id}:sessions     SET     "customer_id|session_id|system_id" bound on the replica
+//	smpp:binds:{customer_id}        HASH    session ID -> session JSON (with instance_id)
+//	smpp:systemid:{system_id}       HASH    session ID -> customer ID
+//	smpp:forward:{id}               LIST    envelopes for the replica to deliver locally
+//	smpp:forward:{id}:processing    LIST    envelopes taken from the queue, not yet delivered
+//
+// Bind entries whose replica heartbeat has expired are treated as gone and are
+// pruned the next time the customer binds. Forwarded envelopes are queued
+// rather than published, so they survive a busy or restarting receiver; the
+// queues of a replica that dies are taken over by a live replica.
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/ringer-warp/smpp-gateway/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Redis keys and key prefixes
+	InstancesKey       = "smpp:instances"
+	InstanceKeyPrefix  = "smpp:instance:"
+	BindsKeyPrefix     = "smpp:binds:"
+	SystemIDKeyPrefix  = "smpp:systemid:"
+	ForwardQueuePrefix = "smpp:forward:"
+
+	heartbeatTTL      = 30 * time.Second
+	heartbeatInterval = 10 * time.Second
+	forwardPollWait   = time.Second
+)
+
+// Envelope kinds
+const (
+	KindDLR    = "dlr"
+	KindMO     = "mo"
+	KindUnbind = "unbind"
+)
+
+var (
+	// ErrBindLimit is returned by Register when the customer is at its bind limit
+	ErrBindLimit = errors.New("customer bind limit reached")
+	// ErrNoSession is returned when no replica holds a suitable bind
+	ErrNoSession = errors.New("no bound session in cluster")
+)
+
+// Envelope is an item forwarded to the replica holding a customer bind
+type Envelope struct {
+	Kind       string                  `json:"kind"`
+	CustomerID string                  `json:"customer_id,omitempty"`
+	SystemID   string                  `json:"system_id,omitempty"`
+	DLR        *models.DeliveryReceipt `json:"dlr,omitempty"`
+	MO         *models.MOMessage       `json:"mo,omitempty"`
+	From       string                  `json:"from"` // Sending instance ID
+}
+
+// Handler delivers forwarded envelopes to locally bound sessions
+type Handler interface {
+	DeliverForwarded(ctx context.Context, env *Envelope) error
+}
+
+// registerScript prunes binds held by dead replicas from KEYS[1] and adds
+// session ARGV[1] (JSON ARGV[2]) unless ARGV[3] live binds already exist for
+// the customer or ARGV[6] for system_id ARGV[5]. ARGV[4] is the instance key
+// prefix. The session is indexed under its system_id in KEYS[2] with customer
+// ARGV[7]. Returns 1 if registered, 0 at a limit.
+var registerScript = redis.NewScript(`
+local entries = redis.call('HGETALL', KEYS[1])
+local live = 0
+local liveSystemID = 0
+for i = 1, #entries, 2 do
+	local entry = cjson.decode(entries[i + 1])
+	if redis.call('EXISTS', ARGV[4] .. entry.instance_id) == 1 then
+		live = live + 1
+		if entry.system_id == ARGV[5] then
+			liveSystemID = liveSystemID + 1
+		end
+	else
+		redis.call('HDEL', KEYS[1], entries[i])
+	end
+end
+local limit = tonumber(ARGV[3])
+if limit > 0 and live >= limit then
+	return 0
+end
+local systemIDLimit = tonumber(ARGV[6])
+if systemIDLimit > 0 and liveSystemID >= systemIDLimit then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[7])
+return 1
+`)
+
+// reclaimScript moves the forward queues of dead replica ARGV[1] (KEYS[1]
+// queue, KEYS[2] processing) onto KEYS[3] and removes it from KEYS[4], unless
+// its heartbeat KEYS[5] has reappeared. Returns the number of envelopes moved,
+// or -1 if the replica is alive.
+var reclaimScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[5]) == 1 then
+	return -1
+end
+local moved = 0
+while redis.call('LMOVE', KEYS[2], KEYS[3], 'LEFT', 'RIGHT') do
+	moved = moved + 1
+end
+while redis.call('LMOVE', KEYS[1], KEYS[3], 'LEFT', 'RIGHT') do
+	moved = moved + 1
+end
+redis.call('SREM', KEYS[4], ARGV[1])
+return moved
+`)
+
+// Registry is the cluster-wide view of customer binds for one replica
+type Registry struct {
+	redis      *redis.Client
+	instanceID string
+	maxBinds   int
+	handler    Handler
+	wg         sync.WaitGroup
+}
+
+// NewRegistry creates a registry for the replica instanceID. maxBinds limits
+// concurrent binds per customer across the cluster (0 = unlimited).
+func NewRegistry(redisClient *redis.Client, instanceID string, maxBinds int) *Registry {
+	return &Registry{
+		redis:      redisClient,
+		instanceID: instanceID,
+		maxBinds:   maxBinds,
+	}
+}
+
+// SetHandler sets the handler that delivers envelopes forwarded to this replica
+func (r *Registry) SetHandler(handler Handler) {
+	r.handler = handler
+}
+
+// InstanceID returns this replica's ID
+func (r *Registry) InstanceID() string {
+	return r.instanceID
+}
+
+// Start announces the replica and starts the heartbeat and forwarding loops
+func (r *Registry) Start(ctx context.Context) error {
+	if err := r.heartbeat(ctx); err != nil {
+		return err
+	}
+	if err := r.redis.SAdd(ctx, InstancesKey, r.instanceID).Err(); err != nil {
+		return fmt.Errorf("failed to join instance set: %w", err)
+	}
+
+	// Envelopes taken but not delivered before a restart under the same ID
+	if err := r.requeueProcessing(ctx); err != nil {
+		return err
+	}
+
+	r.wg.Add(2)
+	go r.heartbeatLoop(ctx)
+	go r.forwardLoop(ctx)
+
+	log.WithField("instance_id", r.instanceID).Info("Cluster registry started")
+	return nil
+}
+
+// Stop removes this replica and its binds from the registry, then hands
+// envelopes still queued for it to the handler (which holds receipts for the
+// customer's next bind). Call after the loops' context is cancelled, so other
+// replicas stop forwarding immediately rather than waiting for the heartbeat
+// to expire.
+func (r *Registry) Stop(ctx context.Context) {
+	r.wg.Wait()
+
+	if err := r.removeInstance(ctx, r.instanceID); err != nil {
+		log.WithError(err).Warn("Failed to deregister instance")
+		return
+	}
+	if err := r.requeueProcessing(ctx); err != nil {
+		log.WithError(err).Warn("Failed to requeue forwarded envelopes")
+	}
+	for {
+		payload, err := r.redis.LPop(ctx, r.forwardKey()).Result()
+		if errors.Is(err, redis.Nil) {
+			break
+		}
+		if err != nil {
+			log.WithError(err).Warn("Failed to drain forwarded envelopes")
+			break
+		}
+		r.deliver(ctx, payload)
+	}
+
+	// Envelopes queued after this point are reclaimed by another replica
+	if err := r.redis.SRem(ctx, InstancesKey, r.instanceID).Err(); err != nil {
+		log.WithError(err).Warn("Failed to leave instance set")
+	}
+
+	log.WithField("instance_id", r.instanceID).Info("Cluster registry stopped")
+}
+
+// removeInstance deletes a replica's heartbeat and the binds it registered
+func (r *Registry) removeInstance(ctx context.Context, instanceID string) error {
+	sessionsKey := InstanceKeyPrefix + instanceID + ":sessions"
+	members, err := r.redis.SMembers(ctx, sessionsKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list registered sessions: %w", err)
+	}
+
+	pipe := r.redis.TxPipeline()
+	for _, member := range members {
+		parts := strings.Split(member, "|")
+		if len(parts) < 2 {
+			continue
+		}
+		pipe.HDel(ctx, BindsKeyPrefix+parts[0], parts[1])
+		if len(parts) == 3 {
+			pipe.HDel(ctx, SystemIDKeyPrefix+parts[2], parts[1])
+		}
+	}
+	pipe.Del(ctx, sessionsKey, InstanceKeyPrefix+instanceID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to remove instance %s: %w", instanceID, err)
+	}
+	return nil
+}
+
+// Register records a new bind, enforcing the cluster-wide per-customer limit
+// and maxBinds for the session's system_id (0 = unlimited)
+func (r *Registry) Register(ctx context.Context, session *models.SMPPSession, maxBinds int) error {
+	session.InstanceID = r.instanceID
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	ok, err := registerScript.Run(ctx, r.redis,
+		[]string{BindsKeyPrefix + session.CustomerID, SystemIDKeyPrefix + session.SystemID},
+		session.SessionID, data, r.maxBinds, InstanceKeyPrefix, session.SystemID, maxBinds, session.CustomerID).Int()
+	if err != nil {
+		return fmt.Errorf("failed to register session: %w", err)
+	}
+	if ok == 0 {
+		return ErrBindLimit
+	}
+
+	if err := r.redis.SAdd(ctx, r.sessionsKey(), sessionMember(session.CustomerID, session.SessionID, session.SystemID)).Err(); err != nil {
+		log.WithError(err).WithField("session_id", session.SessionID).Warn("Failed to index registered session")
+	}
+
+	return nil
+}
+
+// Unregister removes a bind from the registry
+func (r *Registry) Unregister(ctx context.Context, customerID, systemID, sessionID string) {
+	pipe := r.redis.TxPipeline()
+	pipe.HDel(ctx, BindsKeyPrefix+customerID, sessionID)
+	pipe.HDel(ctx, SystemIDKeyPrefix+systemID, sessionID)
+	pipe.SRem(ctx, r.sessionsKey(), sessionMember(customerID, sessionID, systemID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.WithError(err).WithField("session_id", sessionID).Warn("Failed to unregister session")
+	}
+}
+
+// Sessions returns the live binds for a customer across all replicas
+func (r *Registry) Sessions(ctx context.Context, customerID string) ([]*models.SMPPSession, error) {
+	entries, err := r.redis.HGetAll(ctx, BindsKeyPrefix+customerID).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	sessions := make([]*models.SMPPSession, 0, len(entries))
+	alive := make(map[string]bool)
+	for _, data := range entries {
+		var session models.SMPPSession
+		if err := json.Unmarshal([]byte(data), &session); err != nil {
+			log.WithError(err).WithField("customer_id", customerID).Warn("Skipping malformed session entry")
+			continue
+		}
+
+		live, checked := alive[session.InstanceID]
+		if !checked {
+			n, err := r.redis.Exists(ctx, InstanceKeyPrefix+session.InstanceID).Result()
+			if err != nil {
+				return nil, fmt.Errorf("failed to check instance: %w", err)
+			}
+			live = n == 1
+			alive[session.InstanceID] = live
+		}
+		if live {
+			sessions = append(sessions, &session)
+		}
+	}
+
+	return sessions, nil
+}
+
+// Instances returns the IDs of live replicas
+func (r *Registry) Instances(ctx context.Context) ([]string, error) {
+	var instances []string
+	iter := r.redis.Scan(ctx, 0, InstanceKeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		id := strings.TrimPrefix(iter.Val(), InstanceKeyPrefix)
+		if !strings.Contains(id, ":") { // Skip the per-instance session sets
+			instances = append(instances, id)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+	return instances, nil
+}
+
+// ForwardDLR sends a receipt to another replica holding a receiver-capable bind
+func (r *Registry) ForwardDLR(ctx context.Context, customerID string, dlr *models.DeliveryReceipt) error {
+	return r.forwardToCustomer(ctx, &Envelope{Kind: KindDLR, CustomerID: customerID, DLR: dlr})
+}
+
+// ForwardMO sends an MO message to another replica holding a receiver-capable bind
+func (r *Registry) ForwardMO(ctx context.Context, customerID string, mo *models.MOMessage) error {
+	return r.forwardToCustomer(ctx, &Envelope{Kind: KindMO, CustomerID: customerID, MO: mo})
+}
+
+// ForwardUnbind asks the replica holding systemID's bind to unbind it
+func (r *Registry) ForwardUnbind(ctx context.Context, systemID string) error {
+	owners, err := r.redis.HVals(ctx, SystemIDKeyPrefix+systemID).Result()
+	if err != nil {
+		return fmt.Errorf("failed to look up system_id binds: %w", err)
+	}
+
+	checked := make(map[string]bool)
+	for _, customerID := range owners {
+		if checked[customerID] {
+			continue
+		}
+		checked[customerID] = true
+
+		sessions, err := r.Sessions(ctx, customerID)
+		if err != nil {
+			return err
+		}
+		for _, session := range sessions {
+			if session.SystemID != systemID || session.InstanceID == r.instanceID {
+				continue
+			}
+			delivered, err := r.publish(ctx, session.InstanceID, &Envelope{Kind: KindUnbind, CustomerID: customerID, SystemID: systemID})
+			if err != nil {
+				return err
+			}
+			if delivered {
+				return nil
+			}
+		}
+	}
+
+	return ErrNoSession
+}
+
+// forwardToCustomer queues env for the first other live replica with a
+// receiver-capable bind for env.CustomerID
+func (r *Registry) forwardToCustomer(ctx context.Context, env *Envelope) error {
+	sessions, err := r.Sessions(ctx, env.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	tried := make(map[string]bool)
+	for _, session := range sessions {
+		if session.InstanceID == r.instanceID || tried[session.InstanceID] {
+			continue
+		}
+		if session.BindType != "transceiver" && session.BindType != "receiver" {
+			continue
+		}
+		tried[session.InstanceID] = true
+
+		delivered, err := r.publish(ctx, session.InstanceID, env)
+		if err != nil {
+			return err
+		}
+		if delivered {
+			return nil
+		}
+	}
+
+	return ErrNoSession
+}
+
+// publish queues env for a replica and reports whether the replica is alive.
+// Envelopes queued for a replica that dies are reclaimed by another replica.
+func (r *Registry) publish(ctx context.Context, instanceID string, env *Envelope) (bool, error) {
+	alive, err := r.redis.Exists(ctx, InstanceKeyPrefix+instanceID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check instance: %w", err)
+	}
+	if alive == 0 {
+		return false, nil
+	}
+
+	env.From = r.instanceID
+	data, err := json.Marshal(env)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+
+	if err := r.redis.RPush(ctx, ForwardQueuePrefix+instanceID, data).Err(); err != nil {
+		return false, fmt.Errorf("failed to forward to %s: %w", instanceID, err)
+	}
+
+	log.WithFields(log.Fields{
+		"kind":        env.Kind,
+		"customer_id": env.CustomerID,
+		"instance_id": instanceID,
+	}).Debug("Forwarded to instance")
+
+	return true, nil
+}
+
+// heartbeat refreshes this replica's liveness key
+func (r *Registry) heartbeat(ctx context.Context) error {
+	if err := r.redis.Set(ctx, InstanceKeyPrefix+r.instanceID, time.Now().Unix(), heartbeatTTL).Err(); err != nil {
+		return fmt.Errorf("failed to write heartbeat: %w", err)
+	}
+	return nil
+}
+
+// heartbeatLoop keeps the replica alive in the registry until ctx is cancelled
+func (r *Registry) heartbeatLoop(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.heartbeat(ctx); err != nil {
+				log.WithError(err).Error("Cluster heartbeat failed")
+				continue
+			}
+			if err := r.reclaimDead(ctx); err != nil {
+				log.WithError(err).Warn("Failed to reclaim dead instances")
+			}
+		}
+	}
+}
+
+// reclaimDead takes over the forward queues and bind entries of replicas
+// whose heartbeat has expired without a clean Stop
+func (r *Registry) reclaimDead(ctx context.Context) error {
+	instances, err := r.redis.SMembers(ctx, InstancesKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	for _, instanceID := range instances {
+		if instanceID == r.instanceID {
+			continue
+		}
+
+		deadQueue := ForwardQueuePrefix + instanceID
+		moved, err := reclaimScript.Run(ctx, r.redis,
+			[]string{deadQueue, deadQueue + ":processing", r.forwardKey(), InstancesKey, InstanceKeyPrefix + instanceID},
+			instanceID).Int()
+		if err != nil {
+			return fmt.Errorf("failed to reclaim %s: %w", instanceID, err)
+		}
+		if moved < 0 {
+			continue // Alive
+		}
+
+		if err := r.removeInstance(ctx, instanceID); err != nil {
+			log.WithError(err).WithField("instance_id", instanceID).Warn("Failed to remove dead instance binds")
+		}
+
+		log.WithFields(log.Fields{
+			"instance_id": instanceID,
+			"envelopes":   moved,
+		}).Warn("Reclaimed dead gateway instance")
+	}
+
+	return nil
+}
+
+// forwardLoop hands envelopes queued for this replica to the handler. Each
+// envelope is moved to the processing list while it is delivered, so one taken
+// just before a crash is delivered by whichever replica reclaims the queue.
+func (r *Registry) forwardLoop(ctx context.Context) {
+	defer r.wg.Done()
+
+	processingKey := r.forwardKey() + ":processing"
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		payload, err := r.redis.BLMove(ctx, r.forwardKey(), processingKey, "LEFT", "RIGHT", forwardPollWait).Result()
+		if errors.Is(err, redis.Nil) {
+			continue // Nothing queued
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.WithError(err).Error("Failed to read forwarded envelopes")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(forwardPollWait):
+			}
+			continue
+		}
+
+		r.deliver(ctx, payload)
+		if err := r.redis.LRem(context.WithoutCancel(ctx), processingKey, 1, payload).Err(); err != nil {
+			log.WithError(err).Warn("Failed to acknowledge forwarded envelope")
+		}
+	}
+}
+
+// deliver decodes an envelope and hands it to the handler
+func (r *Registry) deliver(ctx context.Context, payload string) {
+	var env Envelope
+	if err := json.Unmarshal([]byte(payload), &env); err != nil {
+		log.WithError(err).Warn("Discarding malformed forwarded envelope")
+		return
+	}
+	if r.handler == nil {
+		return
+	}
+
+	if err := r.handler.DeliverForwarded(context.WithoutCancel(ctx), &env); err != nil {
+		log.WithError(err).WithFields(log.Fields{
+			"kind":        env.Kind,
+			"customer_id": env.CustomerID,
+			"from":        env.From,
+		}).Warn("Failed to deliver forwarded envelope")
+	}
+}
+
+// requeueProcessing returns envelopes left in this replica's processing list
+// to the front of its queue
+func (r *Registry) requeueProcessing(ctx context.Context) error {
+	processingKey := r.forwardKey() + ":processing"
+	for {
+		err := r.redis.LMove(ctx, processingKey, r.forwardKey(), "RIGHT", "LEFT").Err()
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to requeue forwarded envelopes: %w", err)
+		}
+	}
+}
+
+// forwardKey is the queue of envelopes forwarded to this replica
+func (r *Registry) forwardKey() string {
+	return ForwardQueuePrefix + r.instanceID
+}
+
+// sessionMember is a bind's entry in its replica's session set
+func sessionMember(customerID, sessionID, systemID string) string {
+	return customerID + "|" + sessionID + "|" + systemID
+}
+
+// sessionsKey is the set of binds registered by this replica
+func (r *Registry) sessionsKey() string {
+	return InstanceKeyPrefix + r.instanceID + ":sessions"
+}
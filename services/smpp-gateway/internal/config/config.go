@@ -36,6 +36,22 @@ type Config struct {
 	RabbitMQPassword string
 	RabbitMQVHost    string
 
+	// Status Callback Webhook Config
+	WebhookWorkers     int
+	WebhookMaxAttempts int
+	WebhookTimeoutSec  int
+
+	// Vendor Submit / Retry Config
+	VendorSubmitTimeoutSec int // Wait for submit_sm_resp
+	SubmitMaxAttempts      int // Attempts per message across all vendors
+	SubmitRetryBudgetMs    int // Total time allowed for retries of one message
+	SubmitWorkers          int // Concurrent submit_sm handled per customer connection
+	SubmitQueueSize        int // submit_sm queued per connection before ESME_RTHROTTLED
+
+	// Cluster Config
+	InstanceID          string // Unique per replica; defaults to the pod hostname
+	MaxBindsPerCustomer int    // Cluster-wide bind limit per customer (0 = unlimited)
+
 	// Service Config
 	APIPort     int
 	MetricsPort int
@@ -74,6 +90,22 @@ func LoadFromEnv() (*Config, error) {
 		RabbitMQPassword: getEnv("RABBITMQ_PASSWORD", ""),
 		RabbitMQVHost:    getEnv("RABBITMQ_VHOST", "/smpp"),
 
+		// Status Callback Webhooks
+		WebhookWorkers:     getEnvInt("WEBHOOK_WORKERS", 4),
+		WebhookMaxAttempts: getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookTimeoutSec:  getEnvInt("WEBHOOK_TIMEOUT_SEC", 10),
+
+		// Vendor Submit / Retry
+		VendorSubmitTimeoutSec: getEnvInt("VENDOR_SUBMIT_TIMEOUT_SEC", 10),
+		SubmitMaxAttempts:      getEnvInt("SUBMIT_MAX_ATTEMPTS", 3),
+		SubmitRetryBudgetMs:    getEnvInt("SUBMIT_RETRY_BUDGET_MS", 5000),
+		SubmitWorkers:          getEnvInt("SUBMIT_WORKERS", 8),
+		SubmitQueueSize:        getEnvInt("SUBMIT_QUEUE_SIZE", 100),
+
+		// Cluster
+		InstanceID:          getEnv("POD_NAME", hostname()),
+		MaxBindsPerCustomer: getEnvInt("SMPP_MAX_BINDS_PER_CUSTOMER", 2),
+
 		// Service
 		APIPort:     getEnvInt("API_PORT", 8080),
 		MetricsPort: getEnvInt("METRICS_PORT", 9090),
@@ -135,3 +167,12 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// hostname returns the OS hostname, or "localhost" if it cannot be read
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "localhost"
+	}
+	return name
+}
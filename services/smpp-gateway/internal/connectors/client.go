@@ -5,15 +5,20 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/linxGnu/gosmpp"
 	"github.com/linxGnu/gosmpp/data"
 	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/ringer-warp/smpp-gateway/internal/capture"
 	"github.com/ringer-warp/smpp-gateway/internal/config"
+	"github.com/ringer-warp/smpp-gateway/internal/metrics"
 	"github.com/ringer-warp/smpp-gateway/internal/models"
+	"github.com/ringer-warp/smpp-gateway/internal/scheduler"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -23,6 +28,7 @@ type SMPPClient struct {
 	config  *config.Config
 	session *gosmpp.Session
 	connected atomic.Bool
+	rebinding atomic.Bool
 	mu      sync.RWMutex
 
 	// Metrics (atomic for thread-safety)
@@ -34,18 +40,36 @@ type SMPPClient struct {
 	connectedAt time.Time
 	lastError   string
 	dlrHandler  DLRHandler
+	moHandler   MOHandler
+	captures    *capture.Manager
+
+	// submit_sm awaiting submit_sm_resp, by sequence number
+	pending   map[int32]chan *pdu.SubmitSMResp
+	pendingMu sync.Mutex
 }
 
+// defaultSubmitTimeout bounds the wait for submit_sm_resp when not configured
+const defaultSubmitTimeout = 10 * time.Second
+
+// rebindInterval is the wait between bind attempts after a forced rebind
+const rebindInterval = 5 * time.Second
+
 // DLRHandler interface for handling delivery receipts
 type DLRHandler interface {
 	HandleDLR(ctx context.Context, dlr *models.DeliveryReceipt) error
 }
 
+// MOHandler interface for handling mobile-originated messages
+type MOHandler interface {
+	HandleMO(ctx context.Context, mo *models.MOMessage) error
+}
+
 // NewSMPPClient creates a new SMPP client for a vendor
 func NewSMPPClient(vendor *models.Vendor, cfg *config.Config) (*SMPPClient, error) {
 	client := &SMPPClient{
-		vendor: vendor,
-		config: cfg,
+		vendor:  vendor,
+		config:  cfg,
+		pending: make(map[int32]chan *pdu.SubmitSMResp),
 	}
 	client.connected.Store(false)
 
@@ -57,6 +81,16 @@ func (c *SMPPClient) SetDLRHandler(handler DLRHandler) {
 	c.dlrHandler = handler
 }
 
+// SetMOHandler sets the MO message handler
+func (c *SMPPClient) SetMOHandler(handler MOHandler) {
+	c.moHandler = handler
+}
+
+// SetCaptureManager sets the PDU capture manager
+func (c *SMPPClient) SetCaptureManager(captures *capture.Manager) {
+	c.captures = captures
+}
+
 // maskPassword masks a password for logging (shows first 2 and last 2 chars)
 func maskPassword(password string) string {
 	if password == "" {
@@ -128,10 +162,16 @@ func (c *SMPPClient) Connect(ctx context.Context) error {
 				"negotiated_proto": state.NegotiatedProtocol,
 			}).Debug("TLS connection state")
 
-			return conn, nil
+			return c.captures.WrapConn(conn, capture.ScopeVendor, c.vendor.ID), nil
 		}
 	} else {
-		dialer = gosmpp.NonTLSDialer
+		dialer = func(addr string) (net.Conn, error) {
+			conn, err := gosmpp.NonTLSDialer(addr)
+			if err != nil {
+				return nil, err
+			}
+			return c.captures.WrapConn(conn, capture.ScopeVendor, c.vendor.ID), nil
+		}
 	}
 	connector := gosmpp.TRXConnector(dialer, auth)
 
@@ -159,6 +199,10 @@ func (c *SMPPClient) Connect(ctx context.Context) error {
 			c.connected.Store(false)
 			logger.Warn("Connection closed by vendor - will retry")
 		},
+		OnRebind: func() {
+			c.connected.Store(true)
+			logger.Info("Rebound to vendor")
+		},
 	}
 
 	// Create session with auto-rebind every 5 seconds
@@ -194,7 +238,7 @@ func (c *SMPPClient) Connect(ctx context.Context) error {
 // Send sends a message through this vendor
 func (c *SMPPClient) Send(ctx context.Context, msg *models.Message) (string, error) {
 	if !c.connected.Load() {
-		return "", fmt.Errorf("not connected to vendor %s", c.vendor.InstanceName)
+		return "", fmt.Errorf("%w %s", ErrNotConnected, c.vendor.InstanceName)
 	}
 
 	c.messagesSent.Add(1)
@@ -232,35 +276,143 @@ func (c *SMPPClient) Send(ctx context.Context, msg *models.Message) (string, err
 	// Request DLR
 	submitSM.RegisteredDelivery = 1
 
-	// Submit to vendor (returns error only in v0.3.1)
-	err := c.session.Transceiver().Submit(submitSM)
-	if err != nil {
-		c.messagesFailed.Add(1)
-		c.mu.Lock()
-		c.lastError = err.Error()
-		c.mu.Unlock()
+	// Pass the customer's validity period through to the vendor
+	if msg.ExpiresAt != nil {
+		submitSM.ValidityPeriod = scheduler.FormatSMPPTime(*msg.ExpiresAt)
+	}
+
+	// Register for the response before submitting; NewSubmitSM assigned the sequence number
+	seq := submitSM.GetSequenceNumber()
+	respCh := make(chan *pdu.SubmitSMResp, 1)
+	c.pendingMu.Lock()
+	c.pending[seq] = respCh
+	c.pendingMu.Unlock()
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, seq)
+		c.pendingMu.Unlock()
+	}()
+
+	// Submit to vendor (Submit only queues the PDU in v0.3.1; the outcome
+	// arrives as submit_sm_resp via handlePDU)
+	c.mu.RLock()
+	session := c.session
+	c.mu.RUnlock()
+	if session == nil {
+		return "", fmt.Errorf("%w %s", ErrNotConnected, c.vendor.InstanceName)
+	}
 
+	start := time.Now()
+	if err := session.Transceiver().Submit(submitSM); err != nil {
+		// Submit only fails when the bind is closing, before anything is written
+		c.recordFailure(err)
 		logger.WithError(err).Error("Failed to submit message to vendor")
-		return "", fmt.Errorf("submit failed: %w", err)
+		return "", fmt.Errorf("%w %s: %w", ErrNotConnected, c.vendor.InstanceName, err)
 	}
 
-	c.messagesSuccess.Add(1)
+	var resp *pdu.SubmitSMResp
+	select {
+	case resp = <-respCh:
+	case <-time.After(c.submitTimeout()):
+		c.recordFailure(ErrSubmitTimeout)
+		logger.Warn("Timed out waiting for submit_sm_resp from vendor")
+		return "", ErrSubmitTimeout
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	metrics.VendorSubmitDuration.WithLabelValues(msg.CustomerID, c.vendor.ID).Observe(time.Since(start).Seconds())
+
+	if resp.CommandStatus != data.ESME_ROK {
+		err := &SubmitError{VendorID: c.vendor.ID, Status: resp.CommandStatus}
+		c.recordFailure(err)
+		logger.WithField("command_status", resp.CommandStatus.String()).Warn("Vendor rejected message")
+		if RequiresRebind(resp.CommandStatus) {
+			c.rebind()
+		}
+		return "", err
+	}
 
-	// Generate vendor message ID (we don't get it back from Submit in this API version)
-	vendorMsgID := fmt.Sprintf("%s-%d", c.vendor.InstanceName, time.Now().UnixNano())
+	c.messagesSuccess.Add(1)
 
+	vendorMsgID := resp.MessageID
 	logger.WithField("vendor_msg_id", vendorMsgID).Info("Message submitted to vendor")
 	return vendorMsgID, nil
 }
 
-// handlePDU handles incoming PDUs from vendor (primarily DLRs)
-// Signature for v0.3.1: func(pdu.PDU, bool)
-func (c *SMPPClient) handlePDU(p pdu.PDU, responded bool) {
-	// Skip if already responded to
-	if responded {
+// rebind drops the vendor bind and binds again in the background, retrying
+// every rebindInterval until bound or Disconnect is called. The client reports
+// disconnected meanwhile so the router sends traffic elsewhere.
+func (c *SMPPClient) rebind() {
+	if !c.rebinding.CompareAndSwap(false, true) {
+		return // Already rebinding
+	}
+
+	logger := log.WithField("vendor", c.vendor.InstanceName)
+	logger.Warn("Vendor reported invalid bind status, rebinding")
+
+	c.connected.Store(false)
+	c.mu.Lock()
+	session := c.session
+	c.session = nil
+	c.mu.Unlock()
+
+	go func() {
+		defer c.rebinding.Store(false)
+
+		if session != nil {
+			session.Close()
+		}
+		for c.rebinding.Load() {
+			err := c.Connect(context.Background())
+			if err == nil {
+				return
+			}
+			logger.WithError(err).Warn("Rebind failed, will retry")
+			time.Sleep(rebindInterval)
+		}
+	}()
+}
+
+// submitTimeout returns how long Send waits for submit_sm_resp
+func (c *SMPPClient) submitTimeout() time.Duration {
+	if c.config != nil && c.config.VendorSubmitTimeoutSec > 0 {
+		return time.Duration(c.config.VendorSubmitTimeoutSec) * time.Second
+	}
+	return defaultSubmitTimeout
+}
+
+// recordFailure counts a failed submit and keeps the error for health reporting
+func (c *SMPPClient) recordFailure(err error) {
+	c.messagesFailed.Add(1)
+	c.mu.Lock()
+	c.lastError = err.Error()
+	c.mu.Unlock()
+}
+
+// resolveSubmit hands a submit_sm_resp to the Send call waiting for it
+func (c *SMPPClient) resolveSubmit(resp *pdu.SubmitSMResp) {
+	c.pendingMu.Lock()
+	respCh, exists := c.pending[resp.SequenceNumber]
+	c.pendingMu.Unlock()
+
+	if !exists {
+		log.WithFields(log.Fields{
+			"vendor":  c.vendor.InstanceName,
+			"seq_num": resp.SequenceNumber,
+		}).Debug("submit_sm_resp for unknown or timed-out submit")
 		return
 	}
 
+	select {
+	case respCh <- resp:
+	default:
+	}
+}
+
+// handlePDU handles incoming PDUs from vendor (primarily DLRs)
+// Signature for v0.3.1: func(pdu.PDU, bool). responded only reports that gosmpp
+// already sent the response (e.g. deliver_sm_resp); the PDU still needs handling.
+func (c *SMPPClient) handlePDU(p pdu.PDU, responded bool) {
 	logger := log.WithFields(log.Fields{
 		"vendor":     c.vendor.InstanceName,
 		"command_id": p.GetHeader().CommandID,
@@ -277,6 +429,9 @@ func (c *SMPPClient) handlePDU(p pdu.PDU, responded bool) {
 	case data.SUBMIT_SM_RESP:
 		// Response to our submit_sm
 		logger.Debug("Submit response received")
+		if resp, ok := p.(*pdu.SubmitSMResp); ok {
+			c.resolveSubmit(resp)
+		}
 
 	case data.ENQUIRE_LINK:
 		// Handled automatically by gosmpp
@@ -314,8 +469,36 @@ func (c *SMPPClient) handleDeliverSM(deliverSM *pdu.DeliverSM) {
 		}
 	} else {
 		// Mobile-originated message (MO)
-		logger.Info("MO message received (not yet implemented)")
-		// TODO: Handle MO messages in future
+		mo := c.parseMO(deliverSM)
+		logger.WithField("mo_id", mo.ID).Info("MO message received from vendor")
+
+		if c.moHandler == nil {
+			logger.Warn("No MO handler configured; dropping MO message")
+			return
+		}
+		if err := c.moHandler.HandleMO(context.Background(), mo); err != nil {
+			logger.WithError(err).WithField("mo_id", mo.ID).Error("Failed to deliver MO message")
+		}
+	}
+}
+
+// parseMO converts a mobile-originated deliver_sm to an MO message
+func (c *SMPPClient) parseMO(deliverSM *pdu.DeliverSM) *models.MOMessage {
+	content, _ := deliverSM.Message.GetMessage()
+
+	encoding := "gsm7"
+	if deliverSM.Message.Encoding() == data.UCS2 {
+		encoding = "ucs2"
+	}
+
+	return &models.MOMessage{
+		ID:         uuid.New().String(),
+		VendorID:   c.vendor.ID,
+		SourceAddr: deliverSM.SourceAddr.Address(),
+		DestAddr:   deliverSM.DestAddr.Address(),
+		Content:    content,
+		Encoding:   encoding,
+		ReceivedAt: time.Now(),
 	}
 }
 
@@ -335,14 +518,33 @@ func (c *SMPPClient) parseDLR(deliverSM *pdu.DeliverSM) *models.DeliveryReceipt
 		Text:       msgText,
 	}
 
-	// TODO: Parse DLR text to extract fields
-	// For now, use basic extraction
-	dlr.MessageID = deliverSM.SourceAddr.Address()
-	dlr.Status = "DELIVRD"
+	// Standard receipt fields; the vendor's message ID is mapped to the
+	// gateway's by the DLR handler
+	dlr.VendorID = c.vendor.ID
+	dlr.VendorMsgID = receiptField(msgText, "id")
+	dlr.MessageID = dlr.VendorMsgID
+	dlr.Status = receiptField(msgText, "stat")
+	dlr.ErrorCode = receiptField(msgText, "err")
+	if dlr.MessageID == "" {
+		dlr.MessageID = deliverSM.SourceAddr.Address()
+	}
+	if dlr.Status == "" {
+		dlr.Status = "UNKNOWN"
+	}
 
 	return dlr
 }
 
+// receiptField returns the value of a "key:value" field of delivery receipt text
+func receiptField(text, key string) string {
+	for _, field := range strings.Fields(text) {
+		if k, v, ok := strings.Cut(field, ":"); ok && strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
 // GetHealth returns current health status
 func (c *SMPPClient) GetHealth() *models.ConnectorHealth {
 	c.mu.RLock()
@@ -369,6 +571,7 @@ func (c *SMPPClient) GetHealth() *models.ConnectorHealth {
 
 // Disconnect closes the SMPP connection gracefully
 func (c *SMPPClient) Disconnect(ctx context.Context) error {
+	c.rebinding.Store(false) // Stop any forced rebind
 	if !c.connected.Load() {
 		return nil
 	}
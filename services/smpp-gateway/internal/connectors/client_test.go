@@ -0,0 +1,77 @@
+package connectors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ringer-warp/smpp-gateway/internal/config"
+	"github.com/ringer-warp/smpp-gateway/internal/models"
+	"github.com/ringer-warp/smpp-gateway/internal/simulator"
+)
+
+// connectSimulator binds a client to an in-process SMSC
+func connectSimulator(t *testing.T, smscCfg simulator.SMSCConfig, cfg *config.Config) (*simulator.SMSC, *SMPPClient) {
+	t.Helper()
+
+	smsc := simulator.NewSMSC(smscCfg)
+	if err := smsc.Start(); err != nil {
+		t.Fatalf("start SMSC: %v", err)
+	}
+	t.Cleanup(func() { smsc.Close() })
+
+	host, port := smsc.HostPort()
+	client, err := NewSMPPClient(&models.Vendor{
+		ID:           "vendor-sim",
+		InstanceName: "simulator",
+		Host:         host,
+		Port:         port,
+	}, cfg)
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("bind to SMSC: %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	return smsc, client
+}
+
+func TestSendTimeoutAfterWriteIsFinal(t *testing.T) {
+	smsc, client := connectSimulator(t,
+		simulator.SMSCConfig{DisableDLR: true, SubmitLatency: 3 * time.Second},
+		&config.Config{VendorSubmitTimeoutSec: 1})
+
+	_, err := client.Send(context.Background(), &models.Message{
+		ID:         "msg-1",
+		SourceAddr: "15551230000",
+		DestAddr:   "15559870000",
+		Content:    "slow vendor",
+	})
+	if !errors.Is(err, ErrSubmitTimeout) {
+		t.Fatalf("Send error = %v, want %v", err, ErrSubmitTimeout)
+	}
+
+	// The vendor has the message, so failing over would send it twice
+	if _, err := smsc.WaitForSubmits(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if IsTransient(err) {
+		t.Error("IsTransient(timeout after write) = true")
+	}
+}
+
+func TestSendNotConnectedIsTransient(t *testing.T) {
+	_, client := connectSimulator(t, simulator.SMSCConfig{DisableDLR: true}, &config.Config{})
+	client.Disconnect(context.Background())
+
+	_, err := client.Send(context.Background(), &models.Message{ID: "msg-1", DestAddr: "15559870000"})
+	if !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("Send error = %v, want %v", err, ErrNotConnected)
+	}
+	if !IsTransient(err) {
+		t.Error("IsTransient(not connected) = false")
+	}
+}
@@ -0,0 +1,84 @@
+package connectors
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/linxGnu/gosmpp/data"
+)
+
+// ErrSubmitTimeout indicates the vendor did not answer a submit_sm in time.
+// The submit_sm may still have been accepted, so the outcome is unknown.
+var ErrSubmitTimeout = errors.New("timed out waiting for submit_sm_resp")
+
+// ErrNotConnected indicates the submit_sm was never written because the vendor
+// bind was down, rebinding or closing
+var ErrNotConnected = errors.New("not connected to vendor")
+
+// SubmitError is a non-zero command_status returned by a vendor in submit_sm_resp
+type SubmitError struct {
+	VendorID string
+	Status   data.CommandStatusType
+}
+
+func (e *SubmitError) Error() string {
+	return fmt.Sprintf("vendor %s rejected submit_sm: %s", e.VendorID, e.Status)
+}
+
+// transientStatuses are vendor command statuses worth retrying, on the same
+// vendor or another one. Everything else (bad addresses, message length, data
+// coding, permanent application errors) fails the same way on every attempt.
+var transientStatuses = map[data.CommandStatusType]bool{
+	data.ESME_RTHROTTLED:       true, // Vendor throughput exceeded
+	data.ESME_RMSGQFUL:         true, // Vendor message queue full
+	data.ESME_RSYSERR:          true, // Vendor system error
+	data.ESME_RSUBMITFAIL:      true, // Generic submit failure
+	data.ESME_RX_T_APPN:        true, // ESME receiver temporary app error
+	data.ESME_RUNKNOWNERR:      true,
+	data.ESME_RDELIVERYFAILURE: true,
+}
+
+// rebindStatuses mean our bind to the vendor is unusable: the client rebinds
+// and the message fails over to another vendor instead of retrying this one
+var rebindStatuses = map[data.CommandStatusType]bool{
+	data.ESME_RINVBNDSTS: true, // Our bind is in the wrong state
+}
+
+// RequiresRebind reports whether a vendor command_status means the bind must be re-established
+func RequiresRebind(status data.CommandStatusType) bool {
+	return rebindStatuses[status]
+}
+
+// IsTransientStatus reports whether a vendor command_status is worth retrying.
+// Rebind statuses are retried on another vendor only.
+func IsTransientStatus(status data.CommandStatusType) bool {
+	return transientStatuses[status] || rebindStatuses[status]
+}
+
+// IsTransient reports whether a Send error is worth retrying. Only errors that
+// prove the vendor did not take the message qualify: no bind to write on, or a
+// submit_sm_resp with a transient command_status. Anything else (timeouts,
+// cancellation, a failure after the submit_sm was queued) has an unknown
+// outcome and is final, since a retry could deliver the message twice.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var submitErr *SubmitError
+	if errors.As(err, &submitErr) {
+		return IsTransientStatus(submitErr.Status)
+	}
+
+	return errors.Is(err, ErrNotConnected)
+}
+
+// ErrorStatus returns the command_status carried by a Send error, or
+// ESME_RSUBMITFAIL when the error did not come from a vendor response
+func ErrorStatus(err error) data.CommandStatusType {
+	var submitErr *SubmitError
+	if errors.As(err, &submitErr) {
+		return submitErr.Status
+	}
+	return data.ESME_RSUBMITFAIL
+}
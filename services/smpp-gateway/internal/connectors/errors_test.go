@@ -0,0 +1,60 @@
+package connectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/linxGnu/gosmpp"
+	"github.com/linxGnu/gosmpp/data"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"not connected", fmt.Errorf("%w v1", ErrNotConnected), true},
+		{"bind closing", fmt.Errorf("%w v1: %w", ErrNotConnected, gosmpp.ErrConnectionClosing), true},
+		{"timeout after write", ErrSubmitTimeout, false},
+		{"deadline exceeded", context.DeadlineExceeded, false},
+		{"cancelled", context.Canceled, false},
+		{"connection error", io.EOF, false},
+		{"throttled", &SubmitError{VendorID: "v1", Status: data.ESME_RTHROTTLED}, true},
+		{"queue full", &SubmitError{VendorID: "v1", Status: data.ESME_RMSGQFUL}, true},
+		{"system error", &SubmitError{VendorID: "v1", Status: data.ESME_RSYSERR}, true},
+		{"invalid bind status", &SubmitError{VendorID: "v1", Status: data.ESME_RINVBNDSTS}, true},
+		{"wrapped throttled", fmt.Errorf("send: %w", &SubmitError{VendorID: "v1", Status: data.ESME_RTHROTTLED}), true},
+		{"invalid destination", &SubmitError{VendorID: "v1", Status: data.ESME_RINVDSTADR}, false},
+		{"invalid message length", &SubmitError{VendorID: "v1", Status: data.ESME_RINVMSGLEN}, false},
+		{"wrapped invalid source", fmt.Errorf("send: %w", &SubmitError{VendorID: "v1", Status: data.ESME_RINVSRCADR}), false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRequiresRebind(t *testing.T) {
+	if !RequiresRebind(data.ESME_RINVBNDSTS) {
+		t.Error("RequiresRebind(ESME_RINVBNDSTS) = false")
+	}
+	if RequiresRebind(data.ESME_RTHROTTLED) {
+		t.Error("RequiresRebind(ESME_RTHROTTLED) = true")
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	err := fmt.Errorf("send: %w", &SubmitError{VendorID: "v1", Status: data.ESME_RINVDSTADR})
+	if got := ErrorStatus(err); got != data.ESME_RINVDSTADR {
+		t.Errorf("ErrorStatus(vendor rejection) = %v", got)
+	}
+	if got := ErrorStatus(errors.New("connection reset")); got != data.ESME_RSUBMITFAIL {
+		t.Errorf("ErrorStatus(connection error) = %v", got)
+	}
+}
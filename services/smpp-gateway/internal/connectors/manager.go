@@ -6,7 +6,9 @@ import (
 	"sync"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ringer-warp/smpp-gateway/internal/capture"
 	"github.com/ringer-warp/smpp-gateway/internal/config"
+	"github.com/ringer-warp/smpp-gateway/internal/metrics"
 	"github.com/ringer-warp/smpp-gateway/internal/models"
 	log "github.com/sirupsen/logrus"
 )
@@ -16,6 +18,9 @@ type Manager struct {
 	connectors map[string]*SMPPClient
 	db         *pgxpool.Pool
 	config     *config.Config
+	captures   *capture.Manager
+	dlrHandler DLRHandler
+	moHandler  MOHandler
 	mu         sync.RWMutex
 }
 
@@ -45,9 +50,54 @@ func NewManager(ctx context.Context, cfg *config.Config) (*Manager, error) {
 		return nil, fmt.Errorf("failed to load vendors: %w", err)
 	}
 
+	// Export connector state on scrape
+	if err := metrics.RegisterConnectorHealth(mgr.HealthCheck); err != nil {
+		log.WithError(err).Warn("Failed to register connector metrics")
+	}
+
 	return mgr, nil
 }
 
+// SetCaptureManager sets the PDU capture manager on all current and future connectors.
+// Takes effect on the next (re)connect of each vendor.
+func (m *Manager) SetCaptureManager(captures *capture.Manager) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.captures = captures
+	for _, client := range m.connectors {
+		client.SetCaptureManager(captures)
+	}
+}
+
+// SetDLRHandler sets the delivery receipt handler on all current and future connectors
+func (m *Manager) SetDLRHandler(handler DLRHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.dlrHandler = handler
+	for _, client := range m.connectors {
+		client.SetDLRHandler(handler)
+	}
+}
+
+// SetMOHandler sets the MO message handler on all current and future connectors
+func (m *Manager) SetMOHandler(handler MOHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.moHandler = handler
+	for _, client := range m.connectors {
+		client.SetMOHandler(handler)
+	}
+}
+
+// DB returns the PostgreSQL pool shared with the rest of the gateway.
+// It is closed by StopAll.
+func (m *Manager) DB() *pgxpool.Pool {
+	return m.db
+}
+
 // LoadVendors loads active vendors from PostgreSQL
 func (m *Manager) LoadVendors(ctx context.Context) error {
 	query := `
@@ -99,6 +149,9 @@ func (m *Manager) LoadVendors(ctx context.Context) error {
 		}
 
 		m.mu.Lock()
+		client.SetCaptureManager(m.captures)
+		client.SetDLRHandler(m.dlrHandler)
+		client.SetMOHandler(m.moHandler)
 		m.connectors[vendor.ID] = client
 		m.mu.Unlock()
 
@@ -235,6 +288,9 @@ func (m *Manager) ReconnectVendor(ctx context.Context, vendorID string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create new client: %w", err)
 	}
+	newClient.SetCaptureManager(m.captures)
+	newClient.SetDLRHandler(m.dlrHandler)
+	newClient.SetMOHandler(m.moHandler)
 
 	// Replace old client
 	m.connectors[vendorID] = newClient
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/ringer-warp/smpp-gateway/internal/metrics"
 	"github.com/ringer-warp/smpp-gateway/internal/models"
 	log "github.com/sirupsen/logrus"
 )
@@ -16,11 +17,40 @@ const (
 	DLRKeyPrefix     = "dlr:msg:"
 	DLRDefaultTTL    = 7 * 24 * time.Hour // 7 days
 	MessageKeyPrefix = "msg:"
+	PendingKeyPrefix = "dlr:pending:" // Receipts awaiting a customer bind (list per customer)
+	VendorKeyPrefix  = "dlr:vendor:"  // Vendor message ID -> gateway message ID
 )
 
+// Message status values reported to status listeners
+const (
+	StatusQueued    = "queued"
+	StatusSent      = "sent"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// Message status values that are only stored, never reported as events
+const (
+	StatusScheduled = "scheduled"
+	StatusExpired   = "expired"
+)
+
+// StatusListener is notified when a tracked message changes status
+type StatusListener interface {
+	OnStatusChange(ctx context.Context, msg *models.Message, status string, errorCode string)
+}
+
+// ReceiptHandler relays vendor receipts, once mapped to the gateway message,
+// to the customer that submitted the message
+type ReceiptHandler interface {
+	DeliverReceipt(ctx context.Context, msg *models.Message, dlr *models.DeliveryReceipt) error
+}
+
 // Tracker manages delivery receipt tracking in Redis
 type Tracker struct {
-	redis *redis.Client
+	redis    *redis.Client
+	listener StatusListener
+	receipts ReceiptHandler
 }
 
 // NewTracker creates a new DLR tracker
@@ -30,32 +60,138 @@ func NewTracker(redisClient *redis.Client) *Tracker {
 	}
 }
 
+// SetStatusListener sets the listener notified on message status changes
+func (t *Tracker) SetStatusListener(listener StatusListener) {
+	t.listener = listener
+}
+
+// SetReceiptHandler sets the handler that relays vendor receipts to customers
+func (t *Tracker) SetReceiptHandler(handler ReceiptHandler) {
+	t.receipts = handler
+}
+
 // StoreMessage stores message metadata for DLR correlation
 func (t *Tracker) StoreMessage(ctx context.Context, msg *models.Message) error {
-	key := MessageKeyPrefix + msg.ID
+	if err := t.saveMessage(ctx, msg); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"msg_id":      msg.ID,
+		"vendor_id":   msg.VendorID,
+		"customer_id": msg.CustomerID,
+	}).Debug("Message stored for DLR tracking")
+
+	t.notify(ctx, msg, StatusQueued, "")
+	return nil
+}
+
+// UpdateMessage stores changes to a tracked message without a status event
+func (t *Tracker) UpdateMessage(ctx context.Context, msg *models.Message) error {
+	return t.saveMessage(ctx, msg)
+}
+
+// MarkSent records that a message was accepted by the vendor
+func (t *Tracker) MarkSent(ctx context.Context, messageID, vendorMsgID string) error {
+	msg, err := t.GetMessageStatus(ctx, messageID)
+	if err != nil {
+		return err
+	}
+
+	msg.Status = StatusSent
+	msg.VendorMsgID = vendorMsgID
+
+	if err := t.saveMessage(ctx, msg); err != nil {
+		return err
+	}
+
+	// Vendor receipts carry the vendor's ID; customers only ever see ours
+	if vendorMsgID != "" {
+		if err := t.redis.Set(ctx, VendorKeyPrefix+msg.VendorID+":"+vendorMsgID, messageID, DLRDefaultTTL).Err(); err != nil {
+			return fmt.Errorf("failed to index vendor message ID: %w", err)
+		}
+	}
+
+	t.notify(ctx, msg, StatusSent, "")
+	return nil
+}
+
+// MarkFailed records that a message could not be submitted to any vendor
+func (t *Tracker) MarkFailed(ctx context.Context, messageID, errorCode, reason string) error {
+	msg, err := t.GetMessageStatus(ctx, messageID)
+	if err != nil {
+		return err
+	}
+
+	msg.Status = StatusFailed
+	msg.FailureReason = reason
+
+	if err := t.saveMessage(ctx, msg); err != nil {
+		return err
+	}
+
+	t.notify(ctx, msg, StatusFailed, errorCode)
+	return nil
+}
+
+// MarkExpired records that a message's validity period ended before a final
+// receipt arrived. Returns nil if the message already reached a final state.
+func (t *Tracker) MarkExpired(ctx context.Context, messageID string) (*models.Message, error) {
+	msg, err := t.GetMessageStatus(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if isFinalStatus(msg.Status) {
+		return nil, nil
+	}
+
+	msg.Status = StatusExpired
+	msg.DLRStatus = "EXPIRED"
+	msg.FailureReason = "Validity period elapsed"
+
+	if err := t.saveMessage(ctx, msg); err != nil {
+		return nil, err
+	}
 
-	// Serialize message to JSON
+	metrics.DLRReceived.WithLabelValues(msg.VendorID, "EXPIRED").Inc()
+	t.notify(ctx, msg, StatusFailed, "")
+	return msg, nil
+}
+
+// saveMessage serializes and stores a message record with the default TTL
+func (t *Tracker) saveMessage(ctx context.Context, msg *models.Message) error {
 	data, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	// Store in Redis with TTL
-	if err := t.redis.Set(ctx, key, data, DLRDefaultTTL).Err(); err != nil {
+	if err := t.redis.Set(ctx, MessageKeyPrefix+msg.ID, data, DLRDefaultTTL).Err(); err != nil {
 		return fmt.Errorf("failed to store message in Redis: %w", err)
 	}
 
-	log.WithFields(log.Fields{
-		"msg_id":      msg.ID,
-		"vendor_id":   msg.VendorID,
-		"customer_id": msg.CustomerID,
-	}).Debug("Message stored for DLR tracking")
-
 	return nil
 }
 
-// HandleDLR processes a delivery receipt from vendor
+// notify passes a status change to the listener, if one is configured
+func (t *Tracker) notify(ctx context.Context, msg *models.Message, status, errorCode string) {
+	if t.listener == nil {
+		return
+	}
+	t.listener.OnStatusChange(ctx, msg, status, errorCode)
+}
+
+// HandleDLR processes a delivery receipt from vendor. Receipts carrying a
+// vendor message ID are mapped back to the gateway message ID.
 func (t *Tracker) HandleDLR(ctx context.Context, dlr *models.DeliveryReceipt) error {
+	if dlr.VendorMsgID != "" {
+		messageID, err := t.redis.Get(ctx, VendorKeyPrefix+dlr.VendorID+":"+dlr.VendorMsgID).Result()
+		if err == nil {
+			dlr.MessageID = messageID
+		} else if err != redis.Nil {
+			return fmt.Errorf("failed to look up vendor message ID: %w", err)
+		}
+	}
+
 	logger := log.WithFields(log.Fields{
 		"msg_id":       dlr.MessageID,
 		"vendor_msg_id": dlr.VendorMsgID,
@@ -78,8 +214,17 @@ func (t *Tracker) HandleDLR(ctx context.Context, dlr *models.DeliveryReceipt) er
 		return fmt.Errorf("failed to unmarshal message: %w", err)
 	}
 
+	// A locally expired message has already been reported to the customer
+	if msg.Status == StatusExpired {
+		logger.Debug("Ignoring DLR for expired message")
+		return nil
+	}
+
 	// Update message with DLR info
 	now := time.Now()
+	metrics.DLRReceived.WithLabelValues(msg.VendorID, dlr.Status).Inc()
+	metrics.DLRLatency.WithLabelValues(msg.VendorID, dlr.Status).Observe(now.Sub(msg.SubmittedAt).Seconds())
+
 	msg.DLRStatus = dlr.Status
 	msg.Status = mapDLRStatusToMessageStatus(dlr.Status)
 	msg.DeliveredAt = &now
@@ -107,11 +252,71 @@ func (t *Tracker) HandleDLR(ctx context.Context, dlr *models.DeliveryReceipt) er
 
 	logger.WithField("final_status", msg.Status).Info("DLR processed and message updated")
 
-	// TODO: Push DLR to RabbitMQ for async processing (BigQuery, customer notification)
+	if status := callbackStatus(dlr.Status); status != "" {
+		t.notify(ctx, &msg, status, dlr.ErrorCode)
+	}
+
+	// Relay the receipt to the customer's bind if one was requested
+	if msg.WantsReceipt && t.receipts != nil {
+		if err := t.receipts.DeliverReceipt(ctx, &msg, dlr); err != nil {
+			return fmt.Errorf("failed to deliver DLR to customer: %w", err)
+		}
+	}
 
 	return nil
 }
 
+// SavePendingDLRs holds receipts for a customer with no bound session (e.g. after
+// a drain) so the next receiver-capable bind on any instance delivers them
+func (t *Tracker) SavePendingDLRs(ctx context.Context, customerID string, receipts []*models.DeliveryReceipt) error {
+	if len(receipts) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, 0, len(receipts))
+	for _, receipt := range receipts {
+		data, err := json.Marshal(receipt)
+		if err != nil {
+			return fmt.Errorf("failed to marshal DLR: %w", err)
+		}
+		values = append(values, data)
+	}
+
+	key := PendingKeyPrefix + customerID
+	pipe := t.redis.TxPipeline()
+	pipe.RPush(ctx, key, values...)
+	pipe.Expire(ctx, key, DLRDefaultTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save pending DLRs: %w", err)
+	}
+
+	return nil
+}
+
+// TakePendingDLRs removes and returns the receipts held for a customer
+func (t *Tracker) TakePendingDLRs(ctx context.Context, customerID string) ([]*models.DeliveryReceipt, error) {
+	key := PendingKeyPrefix + customerID
+
+	pipe := t.redis.TxPipeline()
+	rangeCmd := pipe.LRange(ctx, key, 0, -1)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to take pending DLRs: %w", err)
+	}
+
+	receipts := make([]*models.DeliveryReceipt, 0, len(rangeCmd.Val()))
+	for _, data := range rangeCmd.Val() {
+		var receipt models.DeliveryReceipt
+		if err := json.Unmarshal([]byte(data), &receipt); err != nil {
+			log.WithError(err).WithField("customer_id", customerID).Warn("Skipping malformed pending DLR")
+			continue
+		}
+		receipts = append(receipts, &receipt)
+	}
+
+	return receipts, nil
+}
+
 // GetMessageStatus retrieves current status of a message
 func (t *Tracker) GetMessageStatus(ctx context.Context, messageID string) (*models.Message, error) {
 	key := MessageKeyPrefix + messageID
@@ -172,6 +377,29 @@ func mapDLRStatusToMessageStatus(dlrStatus string) string {
 	}
 }
 
+// isFinalStatus reports whether a message status can no longer change
+func isFinalStatus(status string) bool {
+	switch status {
+	case "delivered", "failed", "expired", "deleted", "rejected":
+		return true
+	default:
+		return false
+	}
+}
+
+// callbackStatus maps an SMPP DLR status to a status listener event.
+// Intermediate states (ACCEPTD, UNKNOWN) produce no event.
+func callbackStatus(dlrStatus string) string {
+	switch dlrStatus {
+	case "DELIVRD":
+		return StatusDelivered
+	case "EXPIRED", "DELETED", "UNDELIV", "REJECTD":
+		return StatusFailed
+	default:
+		return ""
+	}
+}
+
 // CleanupExpired removes expired message records (optional maintenance task)
 func (t *Tracker) CleanupExpired(ctx context.Context) error {
 	// Redis TTL handles expiration automatically
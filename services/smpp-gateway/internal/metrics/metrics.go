@@ -0,0 +1,224 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/ringer-warp/smpp-gateway/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+const namespace = "smpp_gateway"
+
+// Registry holds all SMPP gateway metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// SubmitReceived counts submit_sm PDUs received from customers
+	SubmitReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "submit_sm_received_total",
+		Help:      "submit_sm PDUs received from customer binds",
+	}, []string{"customer_id"})
+
+	// SubmitResponses counts submit_sm_resp command statuses returned to customers
+	SubmitResponses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "submit_sm_resp_total",
+		Help:      "submit_sm_resp PDUs returned to customers by command status",
+	}, []string{"customer_id", "command_status"})
+
+	// VendorSubmits counts submits to vendors by outcome
+	VendorSubmits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "vendor_submit_total",
+		Help:      "Messages submitted to vendors by outcome",
+	}, []string{"customer_id", "vendor_id", "result"})
+
+	// SubmitRetries counts vendor submits retried after a transient error
+	SubmitRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "vendor_submit_retries_total",
+		Help:      "Vendor submits retried after a transient command status or connection error",
+	}, []string{"customer_id", "vendor_id", "command_status"})
+
+	// VendorSubmitDuration observes the time taken to submit a message to a vendor
+	VendorSubmitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "vendor_submit_duration_seconds",
+		Help:      "Latency of submit_sm to the vendor",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12), // 5ms .. ~10s
+	}, []string{"customer_id", "vendor_id"})
+
+	// DLRReceived counts delivery receipts by vendor and DLR status
+	DLRReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dlr_received_total",
+		Help:      "Delivery receipts received from vendors by status",
+	}, []string{"vendor_id", "status"})
+
+	// DLRLatency observes time from customer submit to delivery receipt
+	DLRLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "dlr_latency_seconds",
+		Help:      "Time from customer submit to vendor delivery receipt",
+		Buckets:   []float64{1, 2, 5, 10, 30, 60, 120, 300, 900, 3600, 14400, 86400},
+	}, []string{"vendor_id", "status"})
+
+	// DLRQueueDepth tracks DLRs queued for delivery to customer binds
+	DLRQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "dlr_queue_depth",
+		Help:      "Delivery receipts queued for delivery to customer binds",
+	}, []string{"customer_id"})
+
+	// ActiveBinds tracks bound customer sessions
+	ActiveBinds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_binds",
+		Help:      "Active customer SMPP binds",
+	}, []string{"customer_id", "bind_type"})
+
+	// ClusterForwards counts items forwarded to the instance holding a customer bind
+	ClusterForwards = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cluster_forwards_total",
+		Help:      "DLRs, MO messages and unbinds forwarded to another gateway instance",
+	}, []string{"kind"})
+
+	// BindsRejected counts binds refused because the customer hit its cluster-wide limit
+	BindsRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "binds_rejected_total",
+		Help:      "Customer binds rejected by the cluster-wide bind limit",
+	}, []string{"customer_id"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		SubmitReceived,
+		SubmitResponses,
+		VendorSubmits,
+		SubmitRetries,
+		VendorSubmitDuration,
+		DLRReceived,
+		DLRLatency,
+		DLRQueueDepth,
+		ActiveBinds,
+		ClusterForwards,
+		BindsRejected,
+	)
+}
+
+// CommandStatus returns the label value for an SMPP command status
+func CommandStatus(status data.CommandStatusType) string {
+	return status.String()
+}
+
+// connectorCollector exports vendor connector health at scrape time
+type connectorCollector struct {
+	source func() map[string]*models.ConnectorHealth
+
+	up       *prometheus.Desc
+	messages *prometheus.Desc
+	dlrs     *prometheus.Desc
+}
+
+// RegisterConnectorHealth registers a collector reading connector health from source
+func RegisterConnectorHealth(source func() map[string]*models.ConnectorHealth) error {
+	return Registry.Register(&connectorCollector{
+		source: source,
+		up: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "connector", "up"),
+			"Whether the vendor connector is bound (1) or not (0)",
+			[]string{"vendor_id", "vendor_name"}, nil,
+		),
+		messages: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "connector", "messages_total"),
+			"Messages handled by the vendor connector by result",
+			[]string{"vendor_id", "result"}, nil,
+		),
+		dlrs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "connector", "dlrs_received_total"),
+			"Delivery receipts received on the vendor connector",
+			[]string{"vendor_id"}, nil,
+		),
+	})
+}
+
+// Describe implements prometheus.Collector
+func (c *connectorCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.up
+	ch <- c.messages
+	ch <- c.dlrs
+}
+
+// Collect implements prometheus.Collector
+func (c *connectorCollector) Collect(ch chan<- prometheus.Metric) {
+	for id, h := range c.source() {
+		up := 0.0
+		if h.Status == "connected" {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, up, id, h.VendorName)
+		ch <- prometheus.MustNewConstMetric(c.messages, prometheus.CounterValue, float64(h.MessagesSent), id, "sent")
+		ch <- prometheus.MustNewConstMetric(c.messages, prometheus.CounterValue, float64(h.MessagesSuccess), id, "success")
+		ch <- prometheus.MustNewConstMetric(c.messages, prometheus.CounterValue, float64(h.MessagesFailed), id, "failed")
+		ch <- prometheus.MustNewConstMetric(c.dlrs, prometheus.CounterValue, float64(h.DLRsReceived), id)
+	}
+}
+
+// Handler returns an HTTP handler exposing the registry
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Server exposes metrics on the dedicated metrics port
+type Server struct {
+	port       int
+	httpServer *http.Server
+}
+
+// NewServer creates a new metrics server
+func NewServer(port int) *Server {
+	return &Server{port: port}
+}
+
+// Start starts the metrics server
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+
+	s.httpServer = &http.Server{
+		Addr:         fmt.Sprintf(":%d", s.port),
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	log.WithField("port", s.port).Info("Starting metrics server")
+
+	go func() {
+		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("Metrics server error")
+		}
+	}()
+
+	return nil
+}
+
+// Stop gracefully stops the metrics server
+func (s *Server) Stop(ctx context.Context) error {
+	if s.httpServer != nil {
+		return s.httpServer.Shutdown(ctx)
+	}
+	return nil
+}
@@ -27,26 +27,44 @@ type Vendor struct {
 
 // Message represents an SMS message
 type Message struct {
-	ID            string     `json:"id"`
-	SourceAddr    string     `json:"source_addr"`
-	DestAddr      string     `json:"dest_addr"`
-	Content       string     `json:"content"`
-	Encoding      string     `json:"encoding"` // "gsm7" or "ucs2"
-	CustomerID    string     `json:"customer_id"`
-	VendorID      string     `json:"vendor_id"`
-	Status        string     `json:"status"` // "pending", "sent", "delivered", "failed"
-	DLRStatus     string     `json:"dlr_status"`
-	Segments      int        `json:"segments"`
-	Cost          float64    `json:"cost"`
-	SubmittedAt   time.Time  `json:"submitted_at"`
-	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
-	FailureReason string     `json:"failure_reason,omitempty"`
+	ID            string          `json:"id"`
+	SourceAddr    string          `json:"source_addr"`
+	DestAddr      string          `json:"dest_addr"`
+	Content       string          `json:"content"`
+	Encoding      string          `json:"encoding"` // "gsm7" or "ucs2"
+	CustomerID    string          `json:"customer_id"`
+	VendorID      string          `json:"vendor_id"`
+	Status        string          `json:"status"` // "pending", "sent", "delivered", "failed"
+	DLRStatus     string          `json:"dlr_status"`
+	Segments      int             `json:"segments"`
+	Cost          float64         `json:"cost"`
+	SubmittedAt   time.Time       `json:"submitted_at"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	FailureReason string          `json:"failure_reason,omitempty"`
+	VendorMsgID   string          `json:"vendor_msg_id,omitempty"`
+	CallbackURL   string          `json:"callback_url,omitempty"`  // Per-message status callback override
+	ScheduledAt   *time.Time      `json:"scheduled_at,omitempty"`  // Deferred delivery time (schedule_delivery_time)
+	ExpiresAt     *time.Time      `json:"expires_at,omitempty"`    // End of validity period (validity_period)
+	WantsReceipt  bool            `json:"wants_receipt,omitempty"` // Customer requested a delivery receipt
+	Attempts      []SubmitAttempt `json:"attempts,omitempty"`      // Vendor submit history (retries and failover)
+}
+
+// SubmitAttempt records one try at handing a message to a vendor
+type SubmitAttempt struct {
+	Attempt       int       `json:"attempt"`
+	VendorID      string    `json:"vendor_id"`
+	CommandStatus string    `json:"command_status"` // Vendor submit_sm_resp status, or ESME_RTHROTTLED for local vendor throttling
+	Error         string    `json:"error,omitempty"`
+	Transient     bool      `json:"transient"`
+	At            time.Time `json:"at"`
+	DurationMs    int64     `json:"duration_ms"`
 }
 
 // DeliveryReceipt represents an SMPP DLR
 type DeliveryReceipt struct {
 	MessageID    string    `json:"message_id"`
 	VendorMsgID  string    `json:"vendor_msg_id"`
+	VendorID     string    `json:"vendor_id,omitempty"`
 	Status       string    `json:"status"` // "DELIVRD", "EXPIRED", "DELETED", "UNDELIV", "ACCEPTD", "UNKNOWN", "REJECTD"
 	ErrorCode    string    `json:"error_code"`
 	ReceivedAt   time.Time `json:"received_at"`
@@ -55,16 +73,80 @@ type DeliveryReceipt struct {
 	Text         string    `json:"text"` // Optional DLR text
 }
 
+// StatusCallback represents a message status event delivered to a customer webhook
+type StatusCallback struct {
+	EventID     string    `json:"event_id"`
+	MessageID   string    `json:"message_id"`
+	CustomerID  string    `json:"customer_id"`
+	Status      string    `json:"status"` // "queued", "sent", "delivered", "failed"
+	DLRStatus   string    `json:"dlr_status,omitempty"`
+	ErrorCode   string    `json:"error_code,omitempty"`
+	SourceAddr  string    `json:"source_addr"`
+	DestAddr    string    `json:"dest_addr"`
+	VendorID    string    `json:"vendor_id,omitempty"`
+	VendorMsgID string    `json:"vendor_msg_id,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// WebhookConfig represents a customer's status callback endpoint
+type WebhookConfig struct {
+	CustomerID string    `json:"customer_id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"-"` // HMAC signing secret (not serialized in JSON)
+	IsActive   bool      `json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WebhookDeadLetter represents a status callback that exhausted its retries
+type WebhookDeadLetter struct {
+	ID         string          `json:"id"`
+	CustomerID string          `json:"customer_id"`
+	MessageID  string          `json:"message_id"`
+	URL        string          `json:"url"`
+	Payload    *StatusCallback `json:"payload"`
+	Attempts   int             `json:"attempts"`
+	LastError  string          `json:"last_error"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ReplayedAt *time.Time      `json:"replayed_at,omitempty"`
+}
+
 // SMPPSession represents a client SMPP session
 type SMPPSession struct {
-	SessionID    string
-	SystemID     string
-	CustomerID   string
-	BindType     string
-	BoundAt      time.Time
-	LastActivity time.Time
-	MessageCount int64
-	ErrorCount   int64
+	SessionID     string    `json:"session_id"`
+	SystemID      string    `json:"system_id"`
+	CustomerID    string    `json:"customer_id"`
+	BindType      string    `json:"bind_type"`
+	RemoteAddr    string    `json:"remote_addr"`
+	BoundAt       time.Time `json:"bound_at"`
+	LastActivity  time.Time `json:"last_activity"`
+	MessageCount  int64     `json:"message_count"`
+	ErrorCount    int64     `json:"error_count"`
+	DLRQueueDepth int       `json:"dlr_queue_depth"`
+	InstanceID    string    `json:"instance_id,omitempty"` // Gateway replica holding the bind
+}
+
+// MOMessage represents a mobile-originated message destined for a customer bind
+type MOMessage struct {
+	ID         string    `json:"id"`
+	CustomerID string    `json:"customer_id"`
+	VendorID   string    `json:"vendor_id"`
+	SourceAddr string    `json:"source_addr"`
+	DestAddr   string    `json:"dest_addr"`
+	Content    string    `json:"content"`
+	Encoding   string    `json:"encoding"` // "gsm7" or "ucs2"
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// DrainStatus reports progress of an SMPP server drain
+type DrainStatus struct {
+	Draining          bool       `json:"draining"`
+	StartedAt         *time.Time `json:"started_at,omitempty"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+	SessionsRemaining int        `json:"sessions_remaining"`
+	InflightSubmits   int64      `json:"inflight_submits"`
+	ForcedCloses      int        `json:"forced_closes"`  // Sessions closed without unbind_resp
+	DLRsPersisted     int        `json:"dlrs_persisted"` // Queued DLRs saved for the next bind
 }
 
 // ConnectorHealth represents vendor connector health status
@@ -48,6 +48,19 @@ func (l *Limiter) CheckCustomerLimit(ctx context.Context, customerID string, lim
 	return l.checkLimit(ctx, key, limitPerMin, window)
 }
 
+// CheckSystemIDLimit checks if a bind credential's per-second throughput is exceeded
+func (l *Limiter) CheckSystemIDLimit(ctx context.Context, systemID string, limitPerSec int) (bool, int, error) {
+	if limitPerSec <= 0 {
+		return true, 0, nil // No limit configured
+	}
+
+	// Use per-second window, shared by all binds of the system_id
+	window := time.Second
+	key := fmt.Sprintf("rate:systemid:%s:%d", systemID, time.Now().Unix())
+
+	return l.checkLimit(ctx, key, limitPerSec, window)
+}
+
 // Check10DLCLimit checks 10DLC hourly and daily limits
 func (l *Limiter) Check10DLCLimit(ctx context.Context, sourceAddr string, hourlyLimit, dailyLimit int) (bool, error) {
 	now := time.Now()
@@ -2,9 +2,12 @@ package routing
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ringer-warp/smpp-gateway/internal/connectors"
 	"github.com/ringer-warp/smpp-gateway/internal/models"
@@ -27,6 +30,12 @@ func NewRouter(db *pgxpool.Pool, connMgr *connectors.Manager) *Router {
 
 // RouteMessage selects the appropriate vendor for a message
 func (r *Router) RouteMessage(ctx context.Context, msg *models.Message) (*connectors.SMPPClient, error) {
+	return r.RouteMessageExcluding(ctx, msg, nil)
+}
+
+// RouteMessageExcluding selects the best connected vendor not in exclude,
+// used to fail a message over after a transient vendor error
+func (r *Router) RouteMessageExcluding(ctx context.Context, msg *models.Message, exclude map[string]bool) (*connectors.SMPPClient, error) {
 	logger := log.WithFields(log.Fields{
 		"msg_id": msg.ID,
 		"source": msg.SourceAddr,
@@ -66,6 +75,10 @@ func (r *Router) RouteMessage(ctx context.Context, msg *models.Message) (*connec
 			continue
 		}
 
+		if exclude[vendorID] {
+			continue
+		}
+
 		// Get connector from manager
 		connector, err := r.connectorMgr.GetConnector(vendorID)
 		if err != nil {
@@ -166,6 +179,46 @@ func (r *Router) selectVendorByRules(ctx context.Context, msg *models.Message) (
 	return "", fmt.Errorf("no routing rule matched")
 }
 
+// ErrNumberNotAssigned is returned when no customer has SMS enabled on a number
+var ErrNumberNotAssigned = errors.New("number not assigned for SMS")
+
+// CustomerForNumber returns the customer with SMS enabled on an inbound
+// destination number, for routing MO messages
+func (r *Router) CustomerForNumber(ctx context.Context, number string) (string, error) {
+	query := `
+		SELECT customer_id
+		FROM numbers.assigned_numbers
+		WHERE number = $1 AND active = TRUE AND sms_enabled = TRUE
+	`
+
+	var customerID string
+	err := r.db.QueryRow(ctx, query, normalizeE164(number)).Scan(&customerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("%w: %s", ErrNumberNotAssigned, number)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up number: %w", err)
+	}
+
+	return customerID, nil
+}
+
+// normalizeE164 converts a vendor address (with or without "+", NANP numbers
+// possibly without the country code) to the E.164 form numbers are stored in
+func normalizeE164(addr string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, addr)
+
+	if len(digits) == 10 {
+		digits = "1" + digits
+	}
+	return "+" + digits
+}
+
 // GetRoutingStats returns routing statistics
 func (r *Router) GetRoutingStats(ctx context.Context) (map[string]int64, error) {
 	// TODO: Implement routing statistics
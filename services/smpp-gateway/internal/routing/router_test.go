@@ -0,0 +1,23 @@
+package routing
+
+import "testing"
+
+func TestNormalizeE164(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"+13035551234", "+13035551234"},
+		{"13035551234", "+13035551234"},
+		{"3035551234", "+13035551234"}, // NANP without country code
+		{"(303) 555-1234", "+13035551234"},
+		{"+1 303.555.1234", "+13035551234"},
+		{"+447911123456", "+447911123456"},
+		{"447911123456", "+447911123456"},
+	}
+	for _, tt := range tests {
+		if got := normalizeE164(tt.in); got != tt.want {
+			t.Errorf("normalizeE164(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
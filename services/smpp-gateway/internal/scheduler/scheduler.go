@@ -0,0 +1,272 @@
+// Package scheduler holds deferred (scheduled) messages in Redis and releases
+// them on time, and enforces validity periods on messages already sent.
+//
+// State lives entirely in Redis so deferred messages survive restarts and any
+// gateway instance can release them:
+//
+//	sched:msgs      HASH  message ID -> message JSON
+//	sched:due       ZSET  message ID scored by release time (unix ms)
+//	sched:inflight  ZSET  message ID scored by lease expiry (claimed, not yet released)
+//	sched:expiry    ZSET  message ID scored by validity period end
+//
+// Claiming moves entries atomically from sched:due to sched:inflight, so each
+// message is released by exactly one instance; an instance that dies mid-release
+// leaves an expired lease which is claimed again on a later poll.
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/ringer-warp/smpp-gateway/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Redis keys
+	MessagesKey = "sched:msgs"
+	DueKey      = "sched:due"
+	InflightKey = "sched:inflight"
+	ExpiryKey   = "sched:expiry"
+
+	pollInterval = time.Second
+	batchSize    = 100
+	leaseTTL     = 30 * time.Second
+	retryDelay   = 5 * time.Second
+)
+
+// ErrRetryLater is returned by Handler.ReleaseMessage when a message cannot be
+// sent right now (e.g. vendor throttled) and should be retried shortly
+var ErrRetryLater = errors.New("retry later")
+
+// Handler sends released messages and expires undelivered ones
+type Handler interface {
+	// ReleaseMessage sends a deferred message whose schedule time has arrived
+	ReleaseMessage(ctx context.Context, msg *models.Message) error
+	// ExpireMessage is called when a sent message's validity period ends
+	ExpireMessage(ctx context.Context, messageID string)
+}
+
+// claimScript moves up to ARGV[2] entries due at or before ARGV[1] from KEYS[1]
+// to KEYS[2] with lease expiry ARGV[3], reclaiming expired leases first
+var claimScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2] - #ids)
+for _, id in ipairs(due) do
+	redis.call('ZREM', KEYS[1], id)
+	table.insert(ids, id)
+end
+for _, id in ipairs(ids) do
+	redis.call('ZADD', KEYS[2], ARGV[3], id)
+end
+return ids
+`)
+
+// claimExpiryScript removes and returns up to ARGV[2] expiry entries due at or before ARGV[1]
+var claimExpiryScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, id in ipairs(ids) do
+	redis.call('ZREM', KEYS[1], id)
+end
+return ids
+`)
+
+// Scheduler releases deferred messages and fires validity expiries
+type Scheduler struct {
+	redis   *redis.Client
+	handler Handler
+	wg      sync.WaitGroup
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler(redisClient *redis.Client) *Scheduler {
+	return &Scheduler{
+		redis: redisClient,
+	}
+}
+
+// SetHandler sets the handler that sends released messages and expires undelivered ones
+func (s *Scheduler) SetHandler(handler Handler) {
+	s.handler = handler
+}
+
+// Schedule stores a message for release at msg.ScheduledAt
+func (s *Scheduler) Schedule(ctx context.Context, msg *models.Message) error {
+	if msg.ScheduledAt == nil {
+		return fmt.Errorf("message %s has no schedule time", msg.ID)
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	pipe := s.redis.TxPipeline()
+	pipe.HSet(ctx, MessagesKey, msg.ID, data)
+	pipe.ZAdd(ctx, DueKey, redis.Z{Score: score(*msg.ScheduledAt), Member: msg.ID})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to schedule message: %w", err)
+	}
+
+	log.WithFields(log.Fields{
+		"msg_id":       msg.ID,
+		"customer_id":  msg.CustomerID,
+		"scheduled_at": msg.ScheduledAt,
+	}).Info("Message scheduled")
+
+	return nil
+}
+
+// Cancel removes a deferred message that has not been released yet
+func (s *Scheduler) Cancel(ctx context.Context, messageID string) (bool, error) {
+	removed, err := s.redis.ZRem(ctx, DueKey, messageID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel message: %w", err)
+	}
+	if removed == 0 {
+		return false, nil
+	}
+
+	s.redis.HDel(ctx, MessagesKey, messageID)
+	return true, nil
+}
+
+// WatchExpiry arranges for ExpireMessage to be called at expiresAt
+func (s *Scheduler) WatchExpiry(ctx context.Context, messageID string, expiresAt time.Time) error {
+	if err := s.redis.ZAdd(ctx, ExpiryKey, redis.Z{Score: score(expiresAt), Member: messageID}).Err(); err != nil {
+		return fmt.Errorf("failed to watch expiry: %w", err)
+	}
+	return nil
+}
+
+// Pending returns the number of deferred messages awaiting release
+func (s *Scheduler) Pending(ctx context.Context) (int64, error) {
+	return s.redis.HLen(ctx, MessagesKey).Result()
+}
+
+// Start starts the release loop
+func (s *Scheduler) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go s.run(ctx)
+
+	log.Info("Message scheduler started")
+}
+
+// Wait blocks until the release loop has exited
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// run polls for due messages and expiries until ctx is cancelled
+func (s *Scheduler) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Message scheduler stopping")
+			return
+		case <-ticker.C:
+			if s.handler == nil {
+				continue
+			}
+			if err := s.releaseDue(ctx); err != nil {
+				log.WithError(err).Error("Failed to release scheduled messages")
+			}
+			if err := s.expireDue(ctx); err != nil {
+				log.WithError(err).Error("Failed to process message expiries")
+			}
+		}
+	}
+}
+
+// releaseDue claims due messages and hands them to the handler
+func (s *Scheduler) releaseDue(ctx context.Context) error {
+	now := time.Now()
+	ids, err := claimScript.Run(ctx, s.redis, []string{DueKey, InflightKey},
+		score(now), batchSize, score(now.Add(leaseTTL))).StringSlice()
+	if err != nil {
+		return fmt.Errorf("failed to claim due messages: %w", err)
+	}
+
+	for _, id := range ids {
+		s.release(ctx, id)
+	}
+
+	return nil
+}
+
+// release sends one claimed message and clears or requeues it
+func (s *Scheduler) release(ctx context.Context, messageID string) {
+	logger := log.WithField("msg_id", messageID)
+
+	data, err := s.redis.HGet(ctx, MessagesKey, messageID).Result()
+	if err == redis.Nil {
+		logger.Warn("Scheduled message body missing; dropping")
+		s.redis.ZRem(ctx, InflightKey, messageID)
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("Failed to load scheduled message")
+		return // Lease expires and the message is claimed again
+	}
+
+	var msg models.Message
+	if err := json.Unmarshal([]byte(data), &msg); err != nil {
+		logger.WithError(err).Error("Failed to unmarshal scheduled message; dropping")
+		s.complete(ctx, messageID)
+		return
+	}
+
+	if err := s.handler.ReleaseMessage(ctx, &msg); errors.Is(err, ErrRetryLater) {
+		logger.Debug("Scheduled message deferred; retrying")
+		pipe := s.redis.TxPipeline()
+		pipe.ZRem(ctx, InflightKey, messageID)
+		pipe.ZAdd(ctx, DueKey, redis.Z{Score: score(time.Now().Add(retryDelay)), Member: messageID})
+		if _, err := pipe.Exec(ctx); err != nil {
+			logger.WithError(err).Error("Failed to requeue scheduled message")
+		}
+		return
+	} else if err != nil {
+		logger.WithError(err).Warn("Scheduled message release failed")
+	}
+
+	s.complete(ctx, messageID)
+}
+
+// complete removes a released message from the scheduler
+func (s *Scheduler) complete(ctx context.Context, messageID string) {
+	pipe := s.redis.TxPipeline()
+	pipe.ZRem(ctx, InflightKey, messageID)
+	pipe.HDel(ctx, MessagesKey, messageID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.WithError(err).WithField("msg_id", messageID).Error("Failed to clear released message")
+	}
+}
+
+// expireDue claims messages whose validity period has ended
+func (s *Scheduler) expireDue(ctx context.Context) error {
+	ids, err := claimExpiryScript.Run(ctx, s.redis, []string{ExpiryKey},
+		score(time.Now()), batchSize).StringSlice()
+	if err != nil {
+		return fmt.Errorf("failed to claim expiries: %w", err)
+	}
+
+	for _, id := range ids {
+		s.handler.ExpireMessage(ctx, id)
+	}
+
+	return nil
+}
+
+// score converts a time to a sorted set score (unix milliseconds)
+func score(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
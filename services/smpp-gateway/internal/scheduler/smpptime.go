@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// smppTimeLen is the length of an SMPP v3.4 time string ("YYMMDDhhmmsstnnp")
+const smppTimeLen = 16
+
+// ParseSMPPTime parses an SMPP v3.4 schedule_delivery_time or validity_period
+// (section 7.1.1). Absolute times ("YYMMDDhhmmsstnn+" / "-") carry an offset
+// from UTC in quarter hours; relative times ("YYMMDDhhmmss000R") are added to
+// now. An empty string returns the zero time.
+func ParseSMPPTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if len(s) != smppTimeLen {
+		return time.Time{}, fmt.Errorf("invalid SMPP time %q: expected %d characters", s, smppTimeLen)
+	}
+
+	fields := make([]int, 8) // YY MM DD hh mm ss t nn
+	for i, pos := range []int{0, 2, 4, 6, 8, 10, 12, 13} {
+		width := 2
+		if pos == 12 {
+			width = 1
+		}
+		n, err := strconv.Atoi(s[pos : pos+width])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid SMPP time %q: non-numeric field", s)
+		}
+		fields[i] = n
+	}
+	yy, mo, dd, hh, mi, ss, tenths, quarters := fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[6], fields[7]
+
+	switch s[15] {
+	case 'R':
+		if tenths != 0 || quarters != 0 {
+			return time.Time{}, fmt.Errorf("invalid relative SMPP time %q: must end in 000R", s)
+		}
+		rel := time.Duration(hh)*time.Hour + time.Duration(mi)*time.Minute + time.Duration(ss)*time.Second
+		return now.AddDate(yy, mo, dd).Add(rel), nil
+
+	case '+', '-':
+		if mo < 1 || mo > 12 || dd < 1 || dd > 31 || hh > 23 || mi > 59 || ss > 59 || quarters > 48 {
+			return time.Time{}, fmt.Errorf("invalid absolute SMPP time %q: field out of range", s)
+		}
+		offset := quarters * 15 * 60
+		if s[15] == '-' {
+			offset = -offset
+		}
+		loc := time.FixedZone("", offset)
+		t := time.Date(2000+yy, time.Month(mo), dd, hh, mi, ss, tenths*int(100*time.Millisecond), loc)
+		if t.Day() != dd {
+			return time.Time{}, fmt.Errorf("invalid absolute SMPP time %q: no such date", s)
+		}
+		return t.UTC(), nil
+
+	default:
+		return time.Time{}, fmt.Errorf("invalid SMPP time %q: unknown suffix %q", s, s[15])
+	}
+}
+
+// FormatSMPPTime formats t as an absolute SMPP time in UTC ("YYMMDDhhmmsst00+")
+func FormatSMPPTime(t time.Time) string {
+	t = t.UTC()
+	return fmt.Sprintf("%s%d00+", t.Format("060102150405"), t.Nanosecond()/int(100*time.Millisecond))
+}
+
+// ParseDeliveryTime parses a schedule or expiry time supplied over HTTP. It
+// accepts RFC 3339 timestamps as well as both SMPP formats, so HTTP and SMPP
+// senders can use the same values.
+func ParseDeliveryTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	return ParseSMPPTime(s, now)
+}
@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSMPPTime(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "", want: time.Time{}},
+
+		// Absolute, offsets in quarter hours
+		{in: "250310140000000+", want: time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)},
+		{in: "250310140000504+", want: time.Date(2025, 3, 10, 13, 0, 0, 500*int(time.Millisecond), time.UTC)},
+		{in: "250310140000020-", want: time.Date(2025, 3, 10, 19, 0, 0, 0, time.UTC)},
+		{in: "241231233000004+", want: time.Date(2024, 12, 31, 22, 30, 0, 0, time.UTC)},
+		{in: "240229000000000+", want: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+
+		// Relative to now
+		{in: "000000010000000R", want: now.Add(time.Hour)},
+		{in: "000001000030000R", want: now.AddDate(0, 0, 1).Add(30 * time.Second)},
+		{in: "010200000000000R", want: now.AddDate(1, 2, 0)},
+
+		// Malformed
+		{in: "2503101400000+", wantErr: true},     // Too short
+		{in: "25031014000000000+", wantErr: true}, // Too long
+		{in: "25031014000000x+", wantErr: true},   // Non-numeric
+		{in: "250310140000000Z", wantErr: true},   // Unknown suffix
+		{in: "000000010000100R", wantErr: true},   // Relative with tenths
+		{in: "251310140000000+", wantErr: true},   // Month 13
+		{in: "250310250000000+", wantErr: true},   // Hour 25
+		{in: "250310140000049+", wantErr: true},   // Offset over 12h
+		{in: "250230000000000+", wantErr: true},   // February 30th
+		{in: "250229000000000+", wantErr: true},   // Not a leap year
+	}
+	for _, tt := range tests {
+		got, err := ParseSMPPTime(tt.in, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSMPPTime(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !got.Equal(tt.want) {
+			t.Errorf("ParseSMPPTime(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSMPPTimeRoundTrip(t *testing.T) {
+	in := time.Date(2025, 7, 4, 9, 15, 30, 700*int(time.Millisecond), time.FixedZone("EDT", -4*3600))
+
+	s := FormatSMPPTime(in)
+	if s != "250704131530700+" {
+		t.Fatalf("FormatSMPPTime() = %q", s)
+	}
+	got, err := ParseSMPPTime(s, time.Now())
+	if err != nil {
+		t.Fatalf("ParseSMPPTime(%q): %v", s, err)
+	}
+	if !got.Equal(in) {
+		t.Errorf("round trip = %v, want %v", got, in)
+	}
+}
+
+func TestParseDeliveryTime(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	got, err := ParseDeliveryTime("2025-03-10T09:00:00-05:00", now)
+	if err != nil || !got.Equal(time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("RFC 3339: got %v, %v", got, err)
+	}
+
+	got, err = ParseDeliveryTime("000000000500000R", now)
+	if err != nil || !got.Equal(now.Add(5*time.Minute)) {
+		t.Errorf("relative SMPP: got %v, %v", got, err)
+	}
+
+	if _, err := ParseDeliveryTime("tomorrow", now); err == nil {
+		t.Error("ParseDeliveryTime(\"tomorrow\") succeeded")
+	}
+}
@@ -0,0 +1,215 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/ringer-warp/smpp-gateway/internal/cluster"
+	"github.com/ringer-warp/smpp-gateway/internal/metrics"
+	"github.com/ringer-warp/smpp-gateway/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// errNoLocalSession means no suitable session is bound to this instance
+var errNoLocalSession = errors.New("no bound session")
+
+// registryTimeout bounds registry calls made while handling a bind or unbind
+const registryTimeout = 2 * time.Second
+
+// registerSession records a new bind in the cluster registry and tracks it
+// locally. Binds over the customer's cluster-wide limit are refused with
+// ESME_RALYBND. If the registry cannot be reached the bind is refused with
+// ESME_RSYSERR: an unregistered bind would bypass the limit and never receive
+// forwarded receipts. Without a registry the limit is enforced per instance.
+func (s *SMPPServer) registerSession(ctx context.Context, session *Session) data.CommandStatusType {
+	if s.registry == nil {
+		if !s.addSession(session, session.MaxBinds) {
+			log.WithFields(log.Fields{
+				"system_id":   session.SystemID,
+				"customer_id": session.CustomerID,
+			}).Warn("Bind rejected: system_id at bind limit")
+			metrics.BindsRejected.WithLabelValues(session.CustomerID).Inc()
+			return data.ESME_RALYBND
+		}
+		return data.ESME_ROK
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, registryTimeout)
+	defer cancel()
+
+	err := s.registry.Register(ctx, &models.SMPPSession{
+		SessionID:  session.ID,
+		SystemID:   session.SystemID,
+		CustomerID: session.CustomerID,
+		BindType:   session.BindType,
+		RemoteAddr: session.RemoteAddr,
+		BoundAt:    session.BoundAt,
+	}, session.MaxBinds)
+
+	logger := log.WithFields(log.Fields{
+		"system_id":   session.SystemID,
+		"customer_id": session.CustomerID,
+	})
+
+	if errors.Is(err, cluster.ErrBindLimit) {
+		logger.Warn("Bind rejected: customer at cluster-wide bind limit")
+		metrics.BindsRejected.WithLabelValues(session.CustomerID).Inc()
+		return data.ESME_RALYBND
+	} else if err != nil {
+		logger.WithError(err).Error("Failed to register session; refusing bind")
+		return data.ESME_RSYSERR
+	}
+
+	s.addSession(session, 0)
+	return data.ESME_ROK
+}
+
+// unregisterSession removes a closed bind from the cluster registry
+func (s *SMPPServer) unregisterSession(session *Session) {
+	if s.registry == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), registryTimeout)
+	defer cancel()
+	s.registry.Unregister(ctx, session.CustomerID, session.SystemID, session.ID)
+}
+
+// DeliverForwarded delivers an envelope another instance forwarded because the
+// customer's bind is held here. Receipts whose bind has since gone are held
+// for the customer's next bind.
+func (s *SMPPServer) DeliverForwarded(ctx context.Context, env *cluster.Envelope) error {
+	switch env.Kind {
+	case cluster.KindDLR:
+		err := s.queueDLRLocal(env.CustomerID, env.DLR)
+		if errors.Is(err, errNoLocalSession) && s.dlrTracker != nil {
+			return s.dlrTracker.SavePendingDLRs(ctx, env.CustomerID, []*models.DeliveryReceipt{env.DLR})
+		}
+		return err
+
+	case cluster.KindMO:
+		return s.deliverMOLocal(env.CustomerID, env.MO)
+
+	case cluster.KindUnbind:
+		return s.forceUnbindLocal(env.SystemID)
+
+	default:
+		return fmt.Errorf("unknown envelope kind %q", env.Kind)
+	}
+}
+
+// HandleMO implements connectors.MOHandler: it finds the customer that owns
+// the destination number and delivers the message to one of its binds
+func (s *SMPPServer) HandleMO(ctx context.Context, mo *models.MOMessage) error {
+	if s.numbers == nil {
+		return fmt.Errorf("no number resolver configured")
+	}
+
+	customerID, err := s.numbers.CustomerForNumber(ctx, mo.DestAddr)
+	if err != nil {
+		return err
+	}
+	mo.CustomerID = customerID
+
+	return s.QueueMOForCustomer(customerID, mo)
+}
+
+// QueueMOForCustomer delivers a mobile-originated message to a customer's
+// receiver-capable bind on this or any other instance
+func (s *SMPPServer) QueueMOForCustomer(customerID string, mo *models.MOMessage) error {
+	err := s.deliverMOLocal(customerID, mo)
+	if !errors.Is(err, errNoLocalSession) || s.registry == nil {
+		return err
+	}
+
+	if ferr := s.registry.ForwardMO(context.Background(), customerID, mo); ferr != nil {
+		if !errors.Is(ferr, cluster.ErrNoSession) {
+			return fmt.Errorf("failed to forward MO: %w", ferr)
+		}
+		return err
+	}
+
+	metrics.ClusterForwards.WithLabelValues(cluster.KindMO).Inc()
+	return nil
+}
+
+// deliverMOLocal sends an MO message on a receiver-capable session held by this instance
+func (s *SMPPServer) deliverMOLocal(customerID string, mo *models.MOMessage) error {
+	s.sessionsMu.RLock()
+	var target *Session
+	for _, session := range s.sessions {
+		if session.CustomerID == customerID && (session.BindType == "transceiver" || session.BindType == "receiver") {
+			target = session
+			break
+		}
+	}
+	s.sessionsMu.RUnlock()
+
+	if target == nil {
+		return fmt.Errorf("%w for customer %s", errNoLocalSession, customerID)
+	}
+
+	s.totalDeliverSM.Add(1)
+	return s.sendMOToCustomer(target, mo)
+}
+
+// sendMOToCustomer sends an MO message to customer as deliver_sm
+func (s *SMPPServer) sendMOToCustomer(session *Session, mo *models.MOMessage) error {
+	deliverSM := pdu.NewDeliverSM().(*pdu.DeliverSM)
+
+	deliverSM.SourceAddr = pdu.NewAddress()
+	deliverSM.SourceAddr.SetTon(1) // International
+	deliverSM.SourceAddr.SetNpi(1) // E.164
+	deliverSM.SourceAddr.SetAddress(mo.SourceAddr)
+
+	deliverSM.DestAddr = pdu.NewAddress()
+	deliverSM.DestAddr.SetTon(1)
+	deliverSM.DestAddr.SetNpi(1)
+	deliverSM.DestAddr.SetAddress(mo.DestAddr)
+
+	var coding data.Encoding = data.GSM7BIT
+	if mo.Encoding == "ucs2" {
+		coding = data.UCS2
+	}
+	if err := deliverSM.Message.SetMessageWithEncoding(mo.Content, coding); err != nil {
+		return fmt.Errorf("failed to encode MO message: %w", err)
+	}
+
+	session.mu.Lock()
+	session.SequenceNum++
+	deliverSM.SequenceNumber = int32(session.SequenceNum)
+	session.mu.Unlock()
+
+	if err := s.writePDU(session.Conn, deliverSM); err != nil {
+		return fmt.Errorf("failed to send deliver_sm: %w", err)
+	}
+
+	log.WithFields(log.Fields{
+		"system_id":   session.SystemID,
+		"mo_id":       mo.ID,
+		"source_addr": mo.SourceAddr,
+	}).Info("MO message delivered to customer")
+
+	return nil
+}
+
+// ClusterSessions returns a customer's binds across all instances
+func (s *SMPPServer) ClusterSessions(ctx context.Context, customerID string) ([]*models.SMPPSession, error) {
+	if s.registry == nil {
+		return s.ListSessions(customerID, nil), nil
+	}
+	return s.registry.Sessions(ctx, customerID)
+}
+
+// ClusterInstances returns this instance's ID and the IDs of all live instances
+func (s *SMPPServer) ClusterInstances(ctx context.Context) (string, []string, error) {
+	if s.registry == nil {
+		return "", nil, nil
+	}
+	instances, err := s.registry.Instances(ctx)
+	return s.registry.InstanceID(), instances, err
+}
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/ringer-warp/smpp-gateway/internal/metrics"
+	"github.com/ringer-warp/smpp-gateway/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrAlreadyDraining is returned when Drain is called more than once
+var ErrAlreadyDraining = errors.New("server is already draining")
+
+// drainPollInterval is how often Drain checks for unbound sessions and in-flight submits
+const drainPollInterval = 100 * time.Millisecond
+
+// Drain takes the server out of service without losing traffic: it stops
+// accepting connections and binds, unbinds every session, waits for in-flight
+// vendor submits and unbind_resp until ctx ends, force-closes what is left and
+// saves undelivered DLRs to Redis so the customer's next bind (on any
+// instance) receives them. Call Shutdown afterwards.
+func (s *SMPPServer) Drain(ctx context.Context) (*models.DrainStatus, error) {
+	if !s.draining.CompareAndSwap(false, true) {
+		return s.DrainStatus(), ErrAlreadyDraining
+	}
+
+	startedAt := time.Now()
+	s.drainMu.Lock()
+	s.drainStatus = models.DrainStatus{Draining: true, StartedAt: &startedAt}
+	s.drainMu.Unlock()
+
+	log.WithField("sessions", s.activeSessionsCount.Load()).Info("Draining SMPP server")
+
+	// Stop accepting connections; the accept loop exits once it sees draining
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	if s.tlsListener != nil {
+		s.tlsListener.Close()
+	}
+
+	// Ask every customer to unbind so they reconnect to another instance.
+	// DLR delivery stops first; undelivered receipts stay queued for persistence.
+	s.sessionsMu.RLock()
+	sessions := make([]*Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.sessionsMu.RUnlock()
+
+	for _, session := range sessions {
+		session.cancel()
+		s.sendUnbind(session)
+	}
+
+	// Wait for unbind_resp (which removes the session) and in-flight submits
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+wait:
+	for {
+		s.sessionsMu.RLock()
+		remaining := len(s.sessions)
+		s.sessionsMu.RUnlock()
+		if remaining == 0 && s.inflightSubmits.Load() == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			log.WithFields(log.Fields{
+				"sessions_remaining": remaining,
+				"inflight_submits":   s.inflightSubmits.Load(),
+			}).Warn("Drain timeout reached; closing remaining sessions")
+			break wait
+		case <-ticker.C:
+		}
+	}
+
+	forced := 0
+	for _, session := range sessions {
+		if s.removeSession(session) {
+			session.Conn.Close()
+			forced++
+		}
+	}
+
+	// Persist whatever is still queued for each session
+	persistCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	persisted := 0
+	for _, session := range sessions {
+		persisted += s.persistDLRQueue(persistCtx, session)
+	}
+
+	completedAt := time.Now()
+	s.drainMu.Lock()
+	s.drainStatus.CompletedAt = &completedAt
+	s.drainStatus.ForcedCloses = forced
+	s.drainStatus.DLRsPersisted += persisted
+	s.drainMu.Unlock()
+
+	log.WithFields(log.Fields{
+		"duration":       completedAt.Sub(startedAt),
+		"forced_closes":  forced,
+		"dlrs_persisted": persisted,
+	}).Info("SMPP server drained")
+
+	return s.DrainStatus(), nil
+}
+
+// IsDraining reports whether Drain has been started
+func (s *SMPPServer) IsDraining() bool {
+	return s.draining.Load()
+}
+
+// DrainStatus returns the progress of an ongoing or completed drain
+func (s *SMPPServer) DrainStatus() *models.DrainStatus {
+	s.drainMu.Lock()
+	status := s.drainStatus
+	s.drainMu.Unlock()
+
+	s.sessionsMu.RLock()
+	status.SessionsRemaining = len(s.sessions)
+	s.sessionsMu.RUnlock()
+	status.InflightSubmits = s.inflightSubmits.Load()
+
+	return &status
+}
+
+// DrainOnSignal drains the server when SIGTERM or SIGINT arrives, allowing up
+// to timeout. The returned channel is closed once the drain has finished.
+func (s *SMPPServer) DrainOnSignal(timeout time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
+
+	go func() {
+		defer close(done)
+		sig := <-sigChan
+		signal.Stop(sigChan)
+
+		log.WithField("signal", sig.String()).Info("Received signal, draining")
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if _, err := s.Drain(ctx); err != nil {
+			log.WithError(err).Warn("Drain did not start")
+		}
+	}()
+
+	return done
+}
+
+// sendUnbind sends an unbind PDU to a session (best effort: the peer may be gone)
+func (s *SMPPServer) sendUnbind(session *Session) {
+	unbind := pdu.NewUnbind().(*pdu.Unbind)
+	session.mu.Lock()
+	session.SequenceNum++
+	unbind.SequenceNumber = int32(session.SequenceNum)
+	session.mu.Unlock()
+
+	if err := s.writePDU(session.Conn, unbind); err != nil {
+		log.WithError(err).WithField("system_id", session.SystemID).Warn("Failed to send unbind")
+	}
+}
+
+// rejectBind answers a bind request received while draining
+func (s *SMPPServer) rejectBind(conn net.Conn, p pdu.PDU) {
+	bindReq := p.(*pdu.BindRequest)
+	log.WithFields(log.Fields{
+		"system_id":   bindReq.SystemID,
+		"remote_addr": conn.RemoteAddr().String(),
+	}).Info("Rejecting bind while draining")
+
+	resp := bindReq.GetResponse().(*pdu.BindResp)
+	resp.CommandStatus = data.ESME_RBINDFAIL
+	s.writePDU(conn, resp)
+}
+
+// persistDLRQueue saves receipts still queued for a removed session
+func (s *SMPPServer) persistDLRQueue(ctx context.Context, session *Session) int {
+	var receipts []*models.DeliveryReceipt
+loop:
+	for {
+		select {
+		case receipt := <-session.dlrQueue:
+			receipts = append(receipts, receipt)
+		default:
+			break loop
+		}
+	}
+	if len(receipts) == 0 {
+		return 0
+	}
+
+	logger := log.WithFields(log.Fields{
+		"system_id":   session.SystemID,
+		"customer_id": session.CustomerID,
+		"count":       len(receipts),
+	})
+
+	if s.dlrTracker == nil {
+		logger.Warn("No DLR tracker configured; dropping queued DLRs")
+		return 0
+	}
+	if err := s.dlrTracker.SavePendingDLRs(ctx, session.CustomerID, receipts); err != nil {
+		logger.WithError(err).Error("Failed to persist queued DLRs")
+		return 0
+	}
+
+	logger.Info("Persisted queued DLRs for next bind")
+	return len(receipts)
+}
+
+// restorePendingDLRs queues receipts persisted by a drained instance for a newly bound session
+func (s *SMPPServer) restorePendingDLRs(ctx context.Context, session *Session) {
+	if s.dlrTracker == nil {
+		return
+	}
+
+	logger := log.WithFields(log.Fields{
+		"system_id":   session.SystemID,
+		"customer_id": session.CustomerID,
+	})
+
+	receipts, err := s.dlrTracker.TakePendingDLRs(ctx, session.CustomerID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to load pending DLRs")
+		return
+	}
+	if len(receipts) == 0 {
+		return
+	}
+
+	for i, receipt := range receipts {
+		select {
+		case session.dlrQueue <- receipt:
+			metrics.DLRQueueDepth.WithLabelValues(session.CustomerID).Inc()
+		default:
+			// Queue full: keep the rest for the next bind
+			if err := s.dlrTracker.SavePendingDLRs(ctx, session.CustomerID, receipts[i:]); err != nil {
+				logger.WithError(err).Error("Failed to re-persist pending DLRs")
+			}
+			logger.WithField("restored", i).Warn("DLR queue full while restoring pending DLRs")
+			return
+		}
+	}
+
+	logger.WithField("restored", len(receipts)).Info("Restored pending DLRs")
+}
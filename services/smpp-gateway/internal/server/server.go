@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -14,29 +15,76 @@ import (
 	"github.com/google/uuid"
 	"github.com/linxGnu/gosmpp/data"
 	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/ringer-warp/smpp-gateway/internal/auth"
+	"github.com/ringer-warp/smpp-gateway/internal/capture"
+	"github.com/ringer-warp/smpp-gateway/internal/cluster"
 	"github.com/ringer-warp/smpp-gateway/internal/config"
 	"github.com/ringer-warp/smpp-gateway/internal/connectors"
 	"github.com/ringer-warp/smpp-gateway/internal/dlr"
+	"github.com/ringer-warp/smpp-gateway/internal/metrics"
 	"github.com/ringer-warp/smpp-gateway/internal/models"
 	"github.com/ringer-warp/smpp-gateway/internal/ratelimit"
-	"github.com/ringer-warp/smpp-gateway/internal/routing"
+	"github.com/ringer-warp/smpp-gateway/internal/scheduler"
+	"github.com/ringer-warp/smpp-gateway/internal/webhook"
 	log "github.com/sirupsen/logrus"
 )
 
+// TagCallbackURL is a vendor-specific submit_sm TLV carrying a per-message
+// status callback URL that overrides the customer's configured webhook
+const TagCallbackURL pdu.Tag = 0x1400
+
+// submitJob is a submit_sm waiting for a connection's submit workers
+type submitJob struct {
+	p       pdu.PDU
+	session *Session
+}
+
+// maxScheduleAhead bounds schedule_delivery_time so deferred messages are
+// released before their tracking record expires
+const maxScheduleAhead = dlr.DLRDefaultTTL
+
+// Authenticator verifies customer bind credentials
+type Authenticator interface {
+	Authenticate(ctx context.Context, systemID, password string, remoteIP net.IP) (*auth.Credential, error)
+}
+
+// Router selects a connected vendor for a message and sets msg.VendorID
+type Router interface {
+	RouteMessage(ctx context.Context, msg *models.Message) (*connectors.SMPPClient, error)
+	RouteMessageExcluding(ctx context.Context, msg *models.Message, exclude map[string]bool) (*connectors.SMPPClient, error)
+}
+
+// NumberResolver maps an inbound destination number to the owning customer
+type NumberResolver interface {
+	CustomerForNumber(ctx context.Context, number string) (string, error)
+}
+
 // SMPPServer handles inbound SMPP connections from customers
 type SMPPServer struct {
 	config       *config.Config
 	connectorMgr *connectors.Manager
-	router       *routing.Router
+	auth         Authenticator
+	router       Router
+	numbers      NumberResolver
 	dlrTracker   *dlr.Tracker
 	rateLimiter  *ratelimit.Limiter
+	captures     *capture.Manager
+	scheduler    *scheduler.Scheduler
+	registry     *cluster.Registry
 	listener     net.Listener
 	tlsListener  net.Listener
-	sessions     map[string]*Session
+	sessions     map[string]*Session   // By session ID
+	bySystemID   map[string][]*Session // Binds per system_id, oldest first
 	sessionsMu   sync.RWMutex
 	shutdownChan chan struct{}
 	wg           sync.WaitGroup
 
+	// Drain state
+	draining        atomic.Bool
+	drainStatus     models.DrainStatus
+	drainMu         sync.Mutex
+	inflightSubmits atomic.Int64
+
 	// Metrics
 	activeSessionsCount atomic.Int64
 	totalBinds          atomic.Int64
@@ -46,10 +94,13 @@ type SMPPServer struct {
 
 // Session represents an active customer SMPP session
 type Session struct {
+	ID           string
 	SystemID     string
 	Password     string
 	CustomerID   string
 	BindType     string // "transceiver", "transmitter", "receiver"
+	MaxBinds     int    // Concurrent binds allowed for the system_id
+	Throughput   int    // submit_sm per second allowed for the system_id
 	Conn         net.Conn
 	RemoteAddr   string
 	BoundAt      time.Time
@@ -59,6 +110,8 @@ type Session struct {
 	ctx          context.Context
 	cancel       context.CancelFunc
 	dlrQueue     chan *models.DeliveryReceipt
+	messageCount atomic.Int64
+	errorCount   atomic.Int64
 }
 
 // NewSMPPServer creates a new SMPP server
@@ -67,15 +120,26 @@ func NewSMPPServer(cfg *config.Config, connMgr *connectors.Manager) (*SMPPServer
 		config:       cfg,
 		connectorMgr: connMgr,
 		sessions:     make(map[string]*Session),
+		bySystemID:   make(map[string][]*Session),
 		shutdownChan: make(chan struct{}),
 	}, nil
 }
 
+// SetAuthenticator sets the bind authenticator. Without one every bind is refused.
+func (s *SMPPServer) SetAuthenticator(authenticator Authenticator) {
+	s.auth = authenticator
+}
+
 // SetRouter sets the message router
-func (s *SMPPServer) SetRouter(router *routing.Router) {
+func (s *SMPPServer) SetRouter(router Router) {
 	s.router = router
 }
 
+// SetNumberResolver sets the lookup used to route vendor MO messages to customers
+func (s *SMPPServer) SetNumberResolver(numbers NumberResolver) {
+	s.numbers = numbers
+}
+
 // SetDLRTracker sets the DLR tracker
 func (s *SMPPServer) SetDLRTracker(tracker *dlr.Tracker) {
 	s.dlrTracker = tracker
@@ -86,6 +150,23 @@ func (s *SMPPServer) SetRateLimiter(limiter *ratelimit.Limiter) {
 	s.rateLimiter = limiter
 }
 
+// SetScheduler sets the scheduler for deferred delivery and validity periods
+func (s *SMPPServer) SetScheduler(sched *scheduler.Scheduler) {
+	s.scheduler = sched
+}
+
+// SetRegistry sets the cluster session registry and receives envelopes forwarded
+// to this instance. Without a registry the server only sees its own binds.
+func (s *SMPPServer) SetRegistry(registry *cluster.Registry) {
+	s.registry = registry
+	registry.SetHandler(s)
+}
+
+// SetCaptureManager sets the PDU capture manager
+func (s *SMPPServer) SetCaptureManager(captures *capture.Manager) {
+	s.captures = captures
+}
+
 // Start starts the SMPP server
 func (s *SMPPServer) Start(ctx context.Context) error {
 	// Start plain SMPP listener
@@ -117,6 +198,11 @@ func (s *SMPPServer) acceptLoop(ctx context.Context) {
 		case <-s.shutdownChan:
 			return
 		default:
+			if s.draining.Load() {
+				log.Info("Accept loop stopping for drain")
+				return
+			}
+
 			// Set accept deadline to allow periodic checking of context
 			s.listener.(*net.TCPListener).SetDeadline(time.Now().Add(1 * time.Second))
 
@@ -125,6 +211,9 @@ func (s *SMPPServer) acceptLoop(ctx context.Context) {
 				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 					continue // Deadline exceeded, check context again
 				}
+				if s.draining.Load() {
+					continue // Listener closed by Drain
+				}
 				log.WithError(err).Error("Failed to accept connection")
 				continue
 			}
@@ -137,9 +226,11 @@ func (s *SMPPServer) acceptLoop(ctx context.Context) {
 }
 
 // handleConnection handles a single SMPP connection
-func (s *SMPPServer) handleConnection(ctx context.Context, conn net.Conn) {
+func (s *SMPPServer) handleConnection(ctx context.Context, rawConn net.Conn) {
 	defer s.wg.Done()
-	defer conn.Close()
+	defer rawConn.Close()
+
+	conn := &customerConn{Conn: rawConn}
 
 	remoteAddr := conn.RemoteAddr().String()
 	logger := log.WithField("remote_addr", remoteAddr)
@@ -151,6 +242,12 @@ func (s *SMPPServer) handleConnection(ctx context.Context, conn net.Conn) {
 
 	var session *Session
 
+	// submit_sm is answered asynchronously so a slow vendor does not stall
+	// enquire_link and unbind. Workers run on the server context so unbind does
+	// not abort accepted submits; they finish (bounded) before the conn closes.
+	submits, stopSubmits := s.startSubmitWorkers(ctx, conn)
+	defer stopSubmits()
+
 	// Read and process PDUs
 	for {
 		select {
@@ -170,12 +267,20 @@ func (s *SMPPServer) handleConnection(ctx context.Context, conn net.Conn) {
 					logger.WithError(err).Warn("Error reading PDU")
 				}
 				if session != nil {
-					s.removeSession(session.SystemID)
+					s.removeSession(session)
 				}
 				return
 			}
 
 			// Handle PDU based on command ID (types may not be exported in v0.3.1)
+			switch p.GetHeader().CommandID {
+			case data.BIND_TRANSCEIVER, data.BIND_TRANSMITTER, data.BIND_RECEIVER:
+				if s.draining.Load() {
+					s.rejectBind(conn, p)
+					return
+				}
+			}
+
 			switch p.GetHeader().CommandID {
 			case data.BIND_TRANSCEIVER:
 				session = s.handleBindTransceiver(sessionCtx, conn, p, remoteAddr)
@@ -186,8 +291,14 @@ func (s *SMPPServer) handleConnection(ctx context.Context, conn net.Conn) {
 			case data.UNBIND:
 				s.handleUnbind(conn, p, session)
 				return
+			case data.UNBIND_RESP:
+				// Peer acknowledged our unbind (drain or force-unbind)
+				if session != nil {
+					s.removeSession(session)
+				}
+				return
 			case data.SUBMIT_SM:
-				s.handleSubmitSM(sessionCtx, conn, p, session)
+				s.enqueueSubmit(submits, conn, p, session)
 			case data.QUERY_SM:
 				s.handleQuerySM(conn, p, session)
 			case data.ENQUIRE_LINK:
@@ -230,6 +341,16 @@ func (s *SMPPServer) readPDU(conn net.Conn) (pdu.PDU, error) {
 		return nil, fmt.Errorf("failed to parse PDU: %w", err)
 	}
 
+	if s.captures.Active() {
+		if _, ok := p.(*pdu.BindRequest); ok {
+			// The customer is only known once the bind authenticates
+			if cc, ok := conn.(*customerConn); ok {
+				cc.pendingBind = fullPDU
+			}
+		} else {
+			s.captures.Record(capture.ScopeCustomer, customerForConn(conn), capture.DirectionIn, conn.RemoteAddr().String(), fullPDU)
+		}
+	}
 	return p, nil
 }
 
@@ -243,9 +364,46 @@ func (s *SMPPServer) writePDU(conn net.Conn, p pdu.PDU) error {
 		return fmt.Errorf("failed to write PDU: %w", err)
 	}
 
+	if s.captures.Active() {
+		s.captures.Record(capture.ScopeCustomer, customerForConn(conn), capture.DirectionOut, conn.RemoteAddr().String(), buf.Bytes())
+	}
+
 	return nil
 }
 
+// customerConn is a customer connection that remembers the customer bound on
+// it, so captured PDUs are attributed without searching the sessions
+type customerConn struct {
+	net.Conn
+	customerID  atomic.Pointer[string]
+	pendingBind []byte // Captured bind request awaiting authentication (read loop only)
+}
+
+// customerForConn returns the customer bound on a connection, or "" if unbound
+func customerForConn(conn net.Conn) string {
+	if cc, ok := conn.(*customerConn); ok {
+		if id := cc.customerID.Load(); id != nil {
+			return *id
+		}
+	}
+	return ""
+}
+
+// bindConn attributes a connection to the customer that authenticated on it
+// and captures the bind request, which arrived before the customer was known
+func (s *SMPPServer) bindConn(conn net.Conn, customerID string) {
+	cc, ok := conn.(*customerConn)
+	if !ok {
+		return
+	}
+	cc.customerID.Store(&customerID)
+
+	if cc.pendingBind != nil {
+		s.captures.Record(capture.ScopeCustomer, customerID, capture.DirectionIn, conn.RemoteAddr().String(), cc.pendingBind)
+		cc.pendingBind = nil
+	}
+}
+
 // handleBindTransceiver handles bind_transceiver requests
 func (s *SMPPServer) handleBindTransceiver(ctx context.Context, conn net.Conn, p pdu.PDU, remoteAddr string) *Session {
 	// Cast to BindRequest (all bind types use same struct)
@@ -261,11 +419,10 @@ func (s *SMPPServer) handleBindTransceiver(ctx context.Context, conn net.Conn, p
 
 	s.totalBinds.Add(1)
 
-	// Authenticate (placeholder - accept all for now)
-	if !s.authenticate(systemID, password) {
-		logger.Warn("Authentication failed")
+	cred, status := s.authenticate(ctx, systemID, password, remoteAddr)
+	if status != data.ESME_ROK {
 		resp := pdu.NewBindTransceiverResp().(*pdu.BindResp)
-		resp.CommandStatus = data.ESME_RINVPASWD
+		resp.CommandStatus = status
 		resp.SequenceNumber = p.GetHeader().SequenceNumber
 		s.writePDU(conn, resp)
 		return nil
@@ -274,10 +431,13 @@ func (s *SMPPServer) handleBindTransceiver(ctx context.Context, conn net.Conn, p
 	// Create session
 	sessionCtx, cancel := context.WithCancel(ctx)
 	session := &Session{
+		ID:           uuid.New().String(),
 		SystemID:     systemID,
 		Password:     password,
-		CustomerID:   systemID, // For now, system_id == customer_id
+		CustomerID:   cred.CustomerID,
 		BindType:     "transceiver",
+		MaxBinds:     cred.MaxBinds,
+		Throughput:   cred.Throughput,
 		Conn:         conn,
 		RemoteAddr:   remoteAddr,
 		BoundAt:      time.Now(),
@@ -287,8 +447,15 @@ func (s *SMPPServer) handleBindTransceiver(ctx context.Context, conn net.Conn, p
 		dlrQueue:     make(chan *models.DeliveryReceipt, 100),
 	}
 
-	s.addSession(systemID, session)
-	s.activeSessionsCount.Add(1)
+	// Enforce the bind limit across all instances and track the session
+	if status := s.registerSession(ctx, session); status != data.ESME_ROK {
+		cancel()
+		resp := pdu.NewBindTransceiverResp().(*pdu.BindResp)
+		resp.CommandStatus = status
+		resp.SequenceNumber = p.GetHeader().SequenceNumber
+		s.writePDU(conn, resp)
+		return nil
+	}
 
 	// Send bind response
 	resp := pdu.NewBindTransceiverResp().(*pdu.BindResp)
@@ -301,7 +468,8 @@ func (s *SMPPServer) handleBindTransceiver(ctx context.Context, conn net.Conn, p
 
 	logger.Info("Bind transceiver successful")
 
-	// Start DLR delivery goroutine
+	// Deliver receipts left by a drained instance, then start DLR delivery
+	s.restorePendingDLRs(ctx, session)
 	s.wg.Add(1)
 	go s.deliverDLRs(session)
 
@@ -320,10 +488,10 @@ func (s *SMPPServer) handleBindTransmitter(ctx context.Context, conn net.Conn, p
 
 	s.totalBinds.Add(1)
 
-	if !s.authenticate(systemID, bindReq.Password) {
-		logger.Warn("Authentication failed")
+	cred, status := s.authenticate(ctx, systemID, bindReq.Password, remoteAddr)
+	if status != data.ESME_ROK {
 		resp := pdu.NewBindTransmitterResp().(*pdu.BindResp)
-		resp.CommandStatus = data.ESME_RINVPASWD
+		resp.CommandStatus = status
 		resp.SequenceNumber = p.GetHeader().SequenceNumber
 		s.writePDU(conn, resp)
 		return nil
@@ -331,9 +499,12 @@ func (s *SMPPServer) handleBindTransmitter(ctx context.Context, conn net.Conn, p
 
 	sessionCtx, cancel := context.WithCancel(ctx)
 	session := &Session{
+		ID:           uuid.New().String(),
 		SystemID:     systemID,
-		CustomerID:   systemID,
+		CustomerID:   cred.CustomerID,
 		BindType:     "transmitter",
+		MaxBinds:     cred.MaxBinds,
+		Throughput:   cred.Throughput,
 		Conn:         conn,
 		RemoteAddr:   remoteAddr,
 		BoundAt:      time.Now(),
@@ -342,8 +513,15 @@ func (s *SMPPServer) handleBindTransmitter(ctx context.Context, conn net.Conn, p
 		cancel:       cancel,
 	}
 
-	s.addSession(systemID, session)
-	s.activeSessionsCount.Add(1)
+	// Enforce the bind limit across all instances and track the session
+	if status := s.registerSession(ctx, session); status != data.ESME_ROK {
+		cancel()
+		resp := pdu.NewBindTransmitterResp().(*pdu.BindResp)
+		resp.CommandStatus = status
+		resp.SequenceNumber = p.GetHeader().SequenceNumber
+		s.writePDU(conn, resp)
+		return nil
+	}
 
 	resp := pdu.NewBindTransmitterResp().(*pdu.BindResp)
 	resp.CommandStatus = data.ESME_ROK
@@ -369,10 +547,10 @@ func (s *SMPPServer) handleBindReceiver(ctx context.Context, conn net.Conn, p pd
 
 	s.totalBinds.Add(1)
 
-	if !s.authenticate(systemID, bindReq.Password) {
-		logger.Warn("Authentication failed")
+	cred, status := s.authenticate(ctx, systemID, bindReq.Password, remoteAddr)
+	if status != data.ESME_ROK {
 		resp := pdu.NewBindReceiverResp().(*pdu.BindResp)
-		resp.CommandStatus = data.ESME_RINVPASWD
+		resp.CommandStatus = status
 		resp.SequenceNumber = p.GetHeader().SequenceNumber
 		s.writePDU(conn, resp)
 		return nil
@@ -380,9 +558,12 @@ func (s *SMPPServer) handleBindReceiver(ctx context.Context, conn net.Conn, p pd
 
 	sessionCtx, cancel := context.WithCancel(ctx)
 	session := &Session{
+		ID:           uuid.New().String(),
 		SystemID:     systemID,
-		CustomerID:   systemID,
+		CustomerID:   cred.CustomerID,
 		BindType:     "receiver",
+		MaxBinds:     cred.MaxBinds,
+		Throughput:   cred.Throughput,
 		Conn:         conn,
 		RemoteAddr:   remoteAddr,
 		BoundAt:      time.Now(),
@@ -392,8 +573,15 @@ func (s *SMPPServer) handleBindReceiver(ctx context.Context, conn net.Conn, p pd
 		dlrQueue:     make(chan *models.DeliveryReceipt, 100),
 	}
 
-	s.addSession(systemID, session)
-	s.activeSessionsCount.Add(1)
+	// Enforce the bind limit across all instances and track the session
+	if status := s.registerSession(ctx, session); status != data.ESME_ROK {
+		cancel()
+		resp := pdu.NewBindReceiverResp().(*pdu.BindResp)
+		resp.CommandStatus = status
+		resp.SequenceNumber = p.GetHeader().SequenceNumber
+		s.writePDU(conn, resp)
+		return nil
+	}
 
 	resp := pdu.NewBindReceiverResp().(*pdu.BindResp)
 	resp.CommandStatus = data.ESME_ROK
@@ -405,34 +593,105 @@ func (s *SMPPServer) handleBindReceiver(ctx context.Context, conn net.Conn, p pd
 
 	logger.Info("Bind receiver successful")
 
-	// Start DLR delivery goroutine
+	// Deliver receipts left by a drained instance, then start DLR delivery
+	s.restorePendingDLRs(ctx, session)
 	s.wg.Add(1)
 	go s.deliverDLRs(session)
 
 	return session
 }
 
-// handleSubmitSM handles submit_sm PDUs from customers
-func (s *SMPPServer) handleSubmitSM(ctx context.Context, conn net.Conn, p pdu.PDU, session *Session) {
-	submitReq := p.(*pdu.SubmitSM)
+// startSubmitWorkers starts the bounded pool that handles a connection's
+// submit_sm. The returned stop function lets the workers empty the queue,
+// waiting up to submitDrainTimeout before cancelling the ones still running.
+func (s *SMPPServer) startSubmitWorkers(ctx context.Context, conn net.Conn) (chan<- submitJob, func()) {
+	workers := s.config.SubmitWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	queueSize := s.config.SubmitQueueSize
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	submits := make(chan submitJob, queueSize)
+	var running sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		s.wg.Add(1)
+		running.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer running.Done()
+			for job := range submits {
+				s.handleSubmitSM(ctx, conn, job.p, job.session)
+				s.inflightSubmits.Add(-1)
+			}
+		}()
+	}
+
+	stop := func() {
+		close(submits)
+		done := make(chan struct{})
+		go func() {
+			running.Wait()
+			close(done)
+		}()
 
+		select {
+		case <-done:
+		case <-time.After(s.submitDrainTimeout()):
+			log.WithField("remote_addr", conn.RemoteAddr().String()).Warn("Timed out finishing queued submits; cancelling")
+		}
+		cancel()
+	}
+
+	return submits, stop
+}
+
+// submitDrainTimeout bounds how long a closing connection waits for its queued
+// submits: a full retry budget plus one more wait for submit_sm_resp
+func (s *SMPPServer) submitDrainTimeout() time.Duration {
+	vendorTimeout := time.Duration(s.config.VendorSubmitTimeoutSec) * time.Second
+	if vendorTimeout <= 0 {
+		vendorTimeout = 10 * time.Second
+	}
+	return time.Duration(s.config.SubmitRetryBudgetMs)*time.Millisecond + vendorTimeout
+}
+
+// enqueueSubmit hands a submit_sm to the connection's workers, or throttles
+// the customer when the queue is full
+func (s *SMPPServer) enqueueSubmit(submits chan<- submitJob, conn net.Conn, p pdu.PDU, session *Session) {
 	if session == nil {
 		log.Warn("Received submit_sm without active session")
 		return
 	}
 
+	// Counted from here so Drain also waits for queued submits
+	s.inflightSubmits.Add(1)
+	select {
+	case submits <- submitJob{p: p, session: session}:
+	default:
+		s.inflightSubmits.Add(-1)
+		log.WithField("system_id", session.SystemID).Warn("Submit queue full")
+		s.writeSubmitSMResp(conn, p, session, data.ESME_RTHROTTLED, "")
+	}
+}
+
+// handleSubmitSM handles submit_sm PDUs from customers
+func (s *SMPPServer) handleSubmitSM(ctx context.Context, conn net.Conn, p pdu.PDU, session *Session) {
+	submitReq := p.(*pdu.SubmitSM)
+
 	if session.BindType == "receiver" {
 		log.WithField("system_id", session.SystemID).Warn("Receiver session cannot transmit")
 		// Send error response
-		resp := pdu.NewSubmitSMResp().(*pdu.SubmitSMResp)
-		resp.CommandStatus = data.ESME_RINVBNDSTS
-		resp.SequenceNumber = p.GetHeader().SequenceNumber
-		s.writePDU(conn, resp)
+		s.writeSubmitSMResp(conn, p, session, data.ESME_RINVBNDSTS, "")
 		return
 	}
 
 	session.UpdateActivity()
 	s.totalSubmitSM.Add(1)
+	metrics.SubmitReceived.WithLabelValues(session.CustomerID).Inc()
 
 	logger := log.WithFields(log.Fields{
 		"system_id":  session.SystemID,
@@ -444,17 +703,14 @@ func (s *SMPPServer) handleSubmitSM(ctx context.Context, conn net.Conn, p pdu.PD
 
 	logger.Info("Submit SM received")
 
-	// Check rate limit
+	// Check the system_id's throughput limit
 	if s.rateLimiter != nil {
-		allowed, _, err := s.rateLimiter.CheckCustomerLimit(ctx, session.CustomerID, 100) // 100 msgs/min default
+		allowed, _, err := s.rateLimiter.CheckSystemIDLimit(ctx, session.SystemID, session.Throughput)
 		if err != nil {
 			logger.WithError(err).Error("Rate limit check failed")
 		} else if !allowed {
 			logger.Warn("Rate limit exceeded")
-			resp := pdu.NewSubmitSMResp().(*pdu.SubmitSMResp)
-			resp.CommandStatus = data.ESME_RTHROTTLED
-			resp.SequenceNumber = p.GetHeader().SequenceNumber
-			s.writePDU(conn, resp)
+			s.writeSubmitSMResp(conn, p, session, data.ESME_RTHROTTLED, "")
 			return
 		}
 	}
@@ -475,82 +731,418 @@ func (s *SMPPServer) handleSubmitSM(ctx context.Context, conn net.Conn, p pdu.PD
 		Segments:   1, // TODO: Calculate actual segments
 		SubmittedAt: time.Now(),
 	}
+	if field, ok := submitReq.OptionalParameters[TagCallbackURL]; ok {
+		msg.CallbackURL = string(bytes.TrimRight(field.Data, "\x00"))
+		// Hostnames are checked again when the callback is dialed
+		if _, err := webhook.CheckURL(msg.CallbackURL); err != nil {
+			logger.WithError(err).WithField("callback_url", msg.CallbackURL).Warn("Invalid callback URL TLV")
+			s.writeSubmitSMResp(conn, p, session, data.ESME_RINVOPTPARAMVAL, "")
+			return
+		}
+	}
 
-	// Route message to vendor
-	if s.router == nil {
-		logger.Error("Router not configured")
-		resp := pdu.NewSubmitSMResp().(*pdu.SubmitSMResp)
-		resp.CommandStatus = data.ESME_RSYSERR
-		resp.SequenceNumber = p.GetHeader().SequenceNumber
-		s.writePDU(conn, resp)
+	// Deferred delivery and validity period
+	scheduledAt, expiresAt, status := deliveryWindow(submitReq, msg.SubmittedAt)
+	if status != data.ESME_ROK {
+		logger.WithFields(log.Fields{
+			"schedule_delivery_time": submitReq.ScheduleDeliveryTime,
+			"validity_period":        submitReq.ValidityPeriod,
+		}).Warn("Invalid schedule or validity period")
+		s.writeSubmitSMResp(conn, p, session, status, "")
 		return
 	}
+	msg.ScheduledAt = scheduledAt
+	msg.ExpiresAt = expiresAt
+	msg.WantsReceipt = submitReq.RegisteredDelivery&0x03 != 0
 
-	vendor, err := s.router.RouteMessage(ctx, msg)
-	if err != nil {
-		logger.WithError(err).Error("Failed to route message")
-		resp := pdu.NewSubmitSMResp().(*pdu.SubmitSMResp)
-		resp.CommandStatus = data.ESME_RSUBMITFAIL
-		resp.SequenceNumber = p.GetHeader().SequenceNumber
-		s.writePDU(conn, resp)
+	if scheduledAt != nil {
+		s.scheduleMessage(ctx, conn, p, session, msg, logger)
 		return
 	}
 
-	// Check vendor rate limit
-	if s.rateLimiter != nil {
-		allowed, _, err := s.rateLimiter.CheckVendorLimit(ctx, msg.VendorID, vendor.GetVendor().Throughput)
-		if err != nil {
-			logger.WithError(err).Error("Vendor rate limit check failed")
-		} else if !allowed {
-			logger.WithField("vendor_id", msg.VendorID).Warn("Vendor rate limit exceeded")
-			resp := pdu.NewSubmitSMResp().(*pdu.SubmitSMResp)
-			resp.CommandStatus = data.ESME_RTHROTTLED
-			resp.SequenceNumber = p.GetHeader().SequenceNumber
-			s.writePDU(conn, resp)
-			return
+	vendorMsgID, status := s.submitToVendor(ctx, msg, logger)
+	if status != data.ESME_ROK {
+		s.writeSubmitSMResp(conn, p, session, status, "")
+		return
+	}
+
+	// Customers always get the gateway message ID; receipts and query_sm use it
+	if err := s.writeSubmitSMResp(conn, p, session, data.ESME_ROK, msgID); err != nil {
+		logger.WithError(err).Error("Failed to send submit_sm_resp")
+		return
+	}
+
+	logger.WithFields(log.Fields{
+		"msg_id":        msgID,
+		"vendor_msg_id": vendorMsgID,
+		"vendor_id":     msg.VendorID,
+	}).Info("Message submitted successfully")
+}
+
+// deliveryWindow parses schedule_delivery_time and validity_period. Schedule
+// times in the past mean "now" and are returned as nil; an error status is
+// returned for malformed times, schedules beyond maxScheduleAhead and validity
+// periods that end before the message could be sent.
+func deliveryWindow(submitReq *pdu.SubmitSM, now time.Time) (*time.Time, *time.Time, data.CommandStatusType) {
+	var scheduledAt, expiresAt *time.Time
+
+	schedule, err := scheduler.ParseSMPPTime(submitReq.ScheduleDeliveryTime, now)
+	if err != nil || schedule.After(now.Add(maxScheduleAhead)) {
+		return nil, nil, data.ESME_RINVSCHED
+	}
+	if schedule.After(now) {
+		scheduledAt = &schedule
+	}
+
+	expiry, err := scheduler.ParseSMPPTime(submitReq.ValidityPeriod, now)
+	if err != nil {
+		return nil, nil, data.ESME_RINVEXPIRY
+	}
+	if !expiry.IsZero() {
+		if !expiry.After(now) || (scheduledAt != nil && !expiry.After(*scheduledAt)) {
+			return nil, nil, data.ESME_RINVEXPIRY
 		}
+		expiresAt = &expiry
+	}
+
+	return scheduledAt, expiresAt, data.ESME_ROK
+}
+
+// scheduleMessage stores a deferred message and acknowledges it with the gateway message ID
+func (s *SMPPServer) scheduleMessage(ctx context.Context, conn net.Conn, p pdu.PDU, session *Session, msg *models.Message, logger *log.Entry) {
+	if s.scheduler == nil {
+		logger.Warn("Scheduled delivery requested but no scheduler configured")
+		s.writeSubmitSMResp(conn, p, session, data.ESME_RINVSCHED, "")
+		return
 	}
 
-	// Store message for DLR tracking
-	if s.dlrTracker != nil && submitReq.RegisteredDelivery > 0 {
+	msg.Status = dlr.StatusScheduled
+	if s.dlrTracker != nil {
 		if err := s.dlrTracker.StoreMessage(ctx, msg); err != nil {
 			logger.WithError(err).Error("Failed to store message for DLR tracking")
 		}
 	}
 
-	// Send to vendor
-	vendorMsgID, err := vendor.Send(ctx, msg)
-	if err != nil {
-		logger.WithError(err).WithField("vendor_id", msg.VendorID).Error("Failed to send to vendor")
-		resp := pdu.NewSubmitSMResp().(*pdu.SubmitSMResp)
-		resp.CommandStatus = data.ESME_RSUBMITFAIL
-		resp.SequenceNumber = p.GetHeader().SequenceNumber
-		s.writePDU(conn, resp)
+	if err := s.scheduler.Schedule(ctx, msg); err != nil {
+		logger.WithError(err).Error("Failed to schedule message")
+		s.writeSubmitSMResp(conn, p, session, data.ESME_RSYSERR, "")
 		return
 	}
 
-	// Send successful response to customer
-	resp := pdu.NewSubmitSMResp().(*pdu.SubmitSMResp)
-	resp.CommandStatus = data.ESME_ROK
-	resp.SequenceNumber = p.GetHeader().SequenceNumber
-	resp.MessageID = vendorMsgID
-	if err := s.writePDU(conn, resp); err != nil {
+	if err := s.writeSubmitSMResp(conn, p, session, data.ESME_ROK, msg.ID); err != nil {
 		logger.WithError(err).Error("Failed to send submit_sm_resp")
 		return
 	}
 
 	logger.WithFields(log.Fields{
-		"msg_id":        msgID,
-		"vendor_msg_id": vendorMsgID,
-		"vendor_id":     msg.VendorID,
-	}).Info("Message submitted successfully")
+		"msg_id":       msg.ID,
+		"scheduled_at": msg.ScheduledAt,
+	}).Info("Message accepted for scheduled delivery")
+}
+
+// submitToVendor routes a message and sends it. Transient vendor errors are
+// retried on the next-best vendor (or the same one if no other is connected)
+// within the configured attempt and time budget; every attempt is recorded on
+// msg.Attempts. Returns the vendor message ID, or the command_status to report
+// once all attempts are exhausted.
+func (s *SMPPServer) submitToVendor(ctx context.Context, msg *models.Message, logger *log.Entry) (string, data.CommandStatusType) {
+	if s.router == nil {
+		logger.Error("Router not configured")
+		return "", data.ESME_RSYSERR
+	}
+
+	maxAttempts := s.config.SubmitMaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	deadline := time.Now().Add(time.Duration(s.config.SubmitRetryBudgetMs) * time.Millisecond)
+
+	tried := make(map[string]bool)
+	stored := false
+	status := data.ESME_RSUBMITFAIL
+	var lastErr error
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 && !retryBackoff(ctx, attempt, deadline) {
+			logger.WithField("attempts", attempt-1).Warn("Retry budget exhausted")
+			break
+		}
+
+		// Route message to vendor, preferring one not yet tried
+		vendor, err := s.router.RouteMessageExcluding(ctx, msg, tried)
+		if err != nil && len(tried) > 0 {
+			vendor, err = s.router.RouteMessage(ctx, msg)
+		}
+		if err != nil {
+			logger.WithError(err).Error("Failed to route message")
+			lastErr = err
+			break
+		}
+		vendorID := msg.VendorID
+		tried[vendorID] = true
+
+		// Check vendor rate limit
+		if s.rateLimiter != nil {
+			allowed, _, err := s.rateLimiter.CheckVendorLimit(ctx, vendorID, vendor.GetVendor().Throughput)
+			if err != nil {
+				logger.WithError(err).Error("Vendor rate limit check failed")
+			} else if !allowed {
+				logger.WithField("vendor_id", vendorID).Warn("Vendor rate limit exceeded")
+				status = data.ESME_RTHROTTLED
+				lastErr = fmt.Errorf("vendor %s rate limit exceeded", vendorID)
+				recordAttempt(msg, attempt, vendorID, status, lastErr, time.Now())
+				continue
+			}
+		}
+
+		// Store message for DLR tracking and status callbacks (released scheduled
+		// messages are already tracked; only the routing result is new)
+		if !stored && s.dlrTracker != nil {
+			store := s.dlrTracker.StoreMessage
+			if msg.Status == dlr.StatusScheduled {
+				store = s.dlrTracker.UpdateMessage
+			}
+			if err := store(ctx, msg); err != nil {
+				logger.WithError(err).Error("Failed to store message for DLR tracking")
+			}
+		}
+		stored = true
+
+		// Send to vendor
+		start := time.Now()
+		vendorMsgID, err := vendor.Send(ctx, msg)
+		if err == nil {
+			recordAttempt(msg, attempt, vendorID, data.ESME_ROK, nil, start)
+			metrics.VendorSubmits.WithLabelValues(msg.CustomerID, vendorID, "ok").Inc()
+			s.markSent(ctx, msg, vendorMsgID, logger)
+			return vendorMsgID, data.ESME_ROK
+		}
+
+		status = connectors.ErrorStatus(err)
+		lastErr = err
+		transient := connectors.IsTransient(err)
+		recordAttempt(msg, attempt, vendorID, status, err, start)
+		metrics.VendorSubmits.WithLabelValues(msg.CustomerID, vendorID, "error").Inc()
+
+		attemptLogger := logger.WithError(err).WithFields(log.Fields{
+			"vendor_id":      vendorID,
+			"attempt":        attempt,
+			"command_status": status.String(),
+		})
+		if !transient {
+			attemptLogger.Error("Vendor rejected message permanently")
+			break
+		}
+		attemptLogger.Warn("Transient vendor error, retrying")
+		metrics.SubmitRetries.WithLabelValues(msg.CustomerID, vendorID, metrics.CommandStatus(status)).Inc()
+	}
+
+	// Exhausted: a throttled scheduled message goes back to the scheduler rather than failing
+	if status == data.ESME_RTHROTTLED && msg.Status == dlr.StatusScheduled {
+		return "", status
+	}
+
+	if stored && s.dlrTracker != nil {
+		if err := s.dlrTracker.UpdateMessage(ctx, msg); err != nil {
+			logger.WithError(err).Warn("Failed to record submit attempts")
+		}
+		reason := fmt.Sprintf("%d attempt(s) failed: %v", len(msg.Attempts), lastErr)
+		if err := s.dlrTracker.MarkFailed(ctx, msg.ID, status.String(), reason); err != nil {
+			logger.WithError(err).Warn("Failed to mark message as failed")
+		}
+	}
+
+	// Relay permanent vendor rejections (e.g. invalid destination) as-is;
+	// exhausted transient errors other than throttling become a generic submit failure
+	if connectors.IsTransientStatus(status) && status != data.ESME_RTHROTTLED {
+		status = data.ESME_RSUBMITFAIL
+	}
+	return "", status
+}
+
+// markSent records a successful vendor submit and starts validity enforcement
+func (s *SMPPServer) markSent(ctx context.Context, msg *models.Message, vendorMsgID string, logger *log.Entry) {
+	if s.dlrTracker != nil {
+		if len(msg.Attempts) > 1 {
+			if err := s.dlrTracker.UpdateMessage(ctx, msg); err != nil {
+				logger.WithError(err).Warn("Failed to record submit attempts")
+			}
+		}
+		if err := s.dlrTracker.MarkSent(ctx, msg.ID, vendorMsgID); err != nil {
+			logger.WithError(err).Warn("Failed to mark message as sent")
+		}
+	}
+
+	// Enforce the validity period if no final receipt arrives in time
+	if msg.ExpiresAt != nil && s.scheduler != nil {
+		if err := s.scheduler.WatchExpiry(ctx, msg.ID, *msg.ExpiresAt); err != nil {
+			logger.WithError(err).Warn("Failed to watch message expiry")
+		}
+	}
+}
+
+// recordAttempt appends a vendor submit attempt to the message history
+func recordAttempt(msg *models.Message, attempt int, vendorID string, status data.CommandStatusType, err error, start time.Time) {
+	a := models.SubmitAttempt{
+		Attempt:       attempt,
+		VendorID:      vendorID,
+		CommandStatus: status.String(),
+		Transient:     err != nil && connectors.IsTransient(err),
+		At:            start,
+		DurationMs:    time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		a.Error = err.Error()
+	}
+	msg.Attempts = append(msg.Attempts, a)
+}
+
+// retryBackoff waits before a retry (100ms, 200ms, 400ms ... capped at 1s).
+// Returns false if the wait would overrun the retry deadline or ctx ends.
+func retryBackoff(ctx context.Context, attempt int, deadline time.Time) bool {
+	delay := 100 * time.Millisecond << (attempt - 2)
+	if delay > time.Second {
+		delay = time.Second
+	}
+	if time.Now().Add(delay).After(deadline) {
+		return false
+	}
+
+	select {
+	case <-time.After(delay):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// ReleaseMessage implements scheduler.Handler: sends a deferred message whose
+// schedule time has arrived, or expires it if its validity period has passed
+func (s *SMPPServer) ReleaseMessage(ctx context.Context, msg *models.Message) error {
+	logger := log.WithFields(log.Fields{
+		"msg_id":      msg.ID,
+		"customer_id": msg.CustomerID,
+		"source":      msg.SourceAddr,
+		"dest":        msg.DestAddr,
+	})
+
+	if msg.ExpiresAt != nil && time.Now().After(*msg.ExpiresAt) {
+		logger.Info("Scheduled message expired before release")
+		s.ExpireMessage(ctx, msg.ID)
+		return nil
+	}
+
+	vendorMsgID, status := s.submitToVendor(ctx, msg, logger)
+	switch status {
+	case data.ESME_ROK:
+		logger.WithFields(log.Fields{
+			"vendor_msg_id": vendorMsgID,
+			"vendor_id":     msg.VendorID,
+		}).Info("Scheduled message released")
+		return nil
+	case data.ESME_RTHROTTLED:
+		return scheduler.ErrRetryLater
+	}
+
+	// The customer already has an ESME_ROK for this message, so report the
+	// failure as a status event and receipt rather than a submit error
+	if s.dlrTracker != nil {
+		if tracked, err := s.dlrTracker.GetMessageStatus(ctx, msg.ID); err == nil && tracked.Status != dlr.StatusFailed {
+			if err := s.dlrTracker.MarkFailed(ctx, msg.ID, "", status.String()); err != nil {
+				logger.WithError(err).Warn("Failed to mark message as failed")
+			}
+		}
+	}
+	if msg.WantsReceipt {
+		s.queueReceipt(msg, "UNDELIV")
+	}
+
+	return fmt.Errorf("release failed: %s", status)
+}
+
+// ExpireMessage implements scheduler.Handler: marks a message whose validity
+// period ended without a final receipt as EXPIRED and notifies the customer
+func (s *SMPPServer) ExpireMessage(ctx context.Context, messageID string) {
+	if s.dlrTracker == nil {
+		return
+	}
+
+	msg, err := s.dlrTracker.MarkExpired(ctx, messageID)
+	if err != nil {
+		log.WithError(err).WithField("msg_id", messageID).Warn("Failed to expire message")
+		return
+	}
+	if msg == nil {
+		return // Final receipt arrived in time
+	}
+
+	log.WithFields(log.Fields{
+		"msg_id":      msg.ID,
+		"customer_id": msg.CustomerID,
+		"vendor_id":   msg.VendorID,
+	}).Info("Message validity period elapsed")
+
+	if msg.WantsReceipt {
+		s.queueReceipt(msg, "EXPIRED")
+	}
+}
+
+// DeliverReceipt implements dlr.ReceiptHandler: it relays a vendor receipt to
+// the customer's bind on this or another instance, under the gateway's message ID
+func (s *SMPPServer) DeliverReceipt(ctx context.Context, msg *models.Message, vendorDLR *models.DeliveryReceipt) error {
+	receipt := *vendorDLR
+	receipt.MessageID = msg.ID
+	receipt.SubmitDate = msg.SubmittedAt
+	receipt.DoneDate = vendorDLR.ReceivedAt
+	if receipt.ErrorCode == "" {
+		receipt.ErrorCode = "000"
+	}
+
+	return s.QueueDLRForCustomer(msg.CustomerID, &receipt)
+}
+
+// queueReceipt sends a gateway-generated delivery receipt to the customer
+func (s *SMPPServer) queueReceipt(msg *models.Message, stat string) {
+	now := time.Now()
+	receipt := &models.DeliveryReceipt{
+		MessageID:   msg.ID,
+		VendorMsgID: msg.VendorMsgID,
+		Status:      stat,
+		ErrorCode:   "000",
+		ReceivedAt:  now,
+		SubmitDate:  msg.SubmittedAt,
+		DoneDate:    now,
+	}
+
+	if err := s.QueueDLRForCustomer(msg.CustomerID, receipt); err != nil {
+		log.WithError(err).WithFields(log.Fields{
+			"msg_id": msg.ID,
+			"status": stat,
+		}).Warn("Failed to queue receipt for customer")
+	}
+}
+
+// writeSubmitSMResp sends a submit_sm_resp and records the returned command status
+func (s *SMPPServer) writeSubmitSMResp(conn net.Conn, p pdu.PDU, session *Session, status data.CommandStatusType, messageID string) error {
+	resp := pdu.NewSubmitSMResp().(*pdu.SubmitSMResp)
+	resp.CommandStatus = status
+	resp.SequenceNumber = p.GetHeader().SequenceNumber
+	resp.MessageID = messageID
+
+	session.messageCount.Add(1)
+	if status != data.ESME_ROK {
+		session.errorCount.Add(1)
+	}
+
+	metrics.SubmitResponses.WithLabelValues(session.CustomerID, metrics.CommandStatus(status)).Inc()
+	return s.writePDU(conn, resp)
 }
 
 // handleUnbind handles unbind requests
 func (s *SMPPServer) handleUnbind(conn net.Conn, p pdu.PDU, session *Session) {
 	if session != nil {
 		log.WithField("system_id", session.SystemID).Info("Unbind request")
-		s.removeSession(session.SystemID)
+		s.removeSession(session)
 		session.cancel()
 	}
 
@@ -606,6 +1198,7 @@ func (s *SMPPServer) deliverDLRs(session *Session) {
 			logger.Info("DLR delivery goroutine stopping")
 			return
 		case dlrMsg := <-session.dlrQueue:
+			metrics.DLRQueueDepth.WithLabelValues(session.CustomerID).Dec()
 			s.totalDeliverSM.Add(1)
 			if err := s.sendDLRToCustomer(session, dlrMsg); err != nil {
 				logger.WithError(err).Error("Failed to deliver DLR")
@@ -662,35 +1255,228 @@ func (s *SMPPServer) sendDLRToCustomer(session *Session, dlrMsg *models.Delivery
 	return nil
 }
 
-// authenticate authenticates a customer (placeholder)
-func (s *SMPPServer) authenticate(systemID, password string) bool {
-	// TODO: Implement real authentication against PostgreSQL
-	log.WithField("system_id", systemID).Debug("Authenticating (placeholder - accepting all)")
-	return true
+// authenticate verifies a bind's credentials and source address. The returned
+// status is the bind_resp command_status to send when authentication fails.
+func (s *SMPPServer) authenticate(ctx context.Context, systemID, password, remoteAddr string) (*auth.Credential, data.CommandStatusType) {
+	logger := log.WithFields(log.Fields{
+		"system_id":   systemID,
+		"remote_addr": remoteAddr,
+	})
+
+	if s.auth == nil {
+		logger.Error("No authenticator configured; refusing bind")
+		return nil, data.ESME_RBINDFAIL
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	cred, err := s.auth.Authenticate(ctx, systemID, password, net.ParseIP(host))
+	switch {
+	case err == nil:
+		return cred, data.ESME_ROK
+	case errors.Is(err, auth.ErrUnknownSystemID):
+		logger.Warn("Authentication failed: unknown system_id")
+		return nil, data.ESME_RINVSYSID
+	case errors.Is(err, auth.ErrInvalidPassword):
+		logger.Warn("Authentication failed: invalid password")
+		return nil, data.ESME_RINVPASWD
+	case errors.Is(err, auth.ErrDisabled), errors.Is(err, auth.ErrIPNotAllowed):
+		logger.WithError(err).Warn("Bind refused")
+		return nil, data.ESME_RBINDFAIL
+	default:
+		logger.WithError(err).Error("Authentication error")
+		return nil, data.ESME_RSYSERR
+	}
 }
 
-// addSession adds a session to the map
-func (s *SMPPServer) addSession(systemID string, session *Session) {
+// addSession tracks a bound session. With limit > 0 the bind is refused (false)
+// if the system_id already holds that many binds on this instance.
+func (s *SMPPServer) addSession(session *Session, limit int) bool {
 	s.sessionsMu.Lock()
-	defer s.sessionsMu.Unlock()
-	s.sessions[systemID] = session
+	if limit > 0 && len(s.bySystemID[session.SystemID]) >= limit {
+		s.sessionsMu.Unlock()
+		return false
+	}
+	s.sessions[session.ID] = session
+	s.bySystemID[session.SystemID] = append(s.bySystemID[session.SystemID], session)
+	s.activeSessionsCount.Add(1)
+	metrics.ActiveBinds.WithLabelValues(session.CustomerID, session.BindType).Inc()
+	s.sessionsMu.Unlock()
+
+	s.bindConn(session.Conn, session.CustomerID)
+	return true
 }
 
-// removeSession removes a session from the map
-func (s *SMPPServer) removeSession(systemID string) {
+// removeSession stops tracking a session. Returns false if it was already removed.
+func (s *SMPPServer) removeSession(session *Session) bool {
 	s.sessionsMu.Lock()
-	defer s.sessionsMu.Unlock()
-
-	if session, exists := s.sessions[systemID]; exists {
+	_, exists := s.sessions[session.ID]
+	if exists {
 		session.cancel()
-		delete(s.sessions, systemID)
+		delete(s.sessions, session.ID)
+		s.dropSystemIDSession(session)
 		s.activeSessionsCount.Add(-1)
-		log.WithField("system_id", systemID).Info("Session removed")
+		metrics.ActiveBinds.WithLabelValues(session.CustomerID, session.BindType).Dec()
+		metrics.DLRQueueDepth.WithLabelValues(session.CustomerID).Sub(float64(len(session.dlrQueue)))
+		log.WithFields(log.Fields{
+			"system_id":  session.SystemID,
+			"session_id": session.ID,
+		}).Info("Session removed")
+	}
+	s.sessionsMu.Unlock()
+
+	if exists {
+		s.unregisterSession(session)
+	}
+	return exists
+}
+
+// dropSystemIDSession removes a session from the system_id index; the caller holds sessionsMu
+func (s *SMPPServer) dropSystemIDSession(session *Session) {
+	binds := s.bySystemID[session.SystemID]
+	for i, bound := range binds {
+		if bound == session {
+			binds = append(binds[:i:i], binds[i+1:]...)
+			break
+		}
 	}
+	if len(binds) == 0 {
+		delete(s.bySystemID, session.SystemID)
+	} else {
+		s.bySystemID[session.SystemID] = binds
+	}
+}
+
+// systemIDSessions returns the sessions bound to a system_id on this instance
+func (s *SMPPServer) systemIDSessions(systemID string) []*Session {
+	s.sessionsMu.RLock()
+	defer s.sessionsMu.RUnlock()
+	return append([]*Session(nil), s.bySystemID[systemID]...)
 }
 
-// QueueDLRForCustomer queues a DLR for delivery to customer
+// ListSessions returns bound sessions, optionally filtered by customer ID and/or system IDs
+func (s *SMPPServer) ListSessions(customerID string, systemIDs []string) []*models.SMPPSession {
+	wanted := make(map[string]bool, len(systemIDs))
+	for _, id := range systemIDs {
+		wanted[id] = true
+	}
+
+	s.sessionsMu.RLock()
+	defer s.sessionsMu.RUnlock()
+
+	instanceID := ""
+	if s.registry != nil {
+		instanceID = s.registry.InstanceID()
+	}
+
+	sessions := make([]*models.SMPPSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		if customerID != "" && session.CustomerID != customerID {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[session.SystemID] {
+			continue
+		}
+
+		session.mu.Lock()
+		lastActivity := session.LastActivity
+		session.mu.Unlock()
+
+		sessions = append(sessions, &models.SMPPSession{
+			SessionID:     session.ID,
+			SystemID:      session.SystemID,
+			CustomerID:    session.CustomerID,
+			BindType:      session.BindType,
+			RemoteAddr:    session.RemoteAddr,
+			BoundAt:       session.BoundAt,
+			LastActivity:  lastActivity,
+			MessageCount:  session.messageCount.Load(),
+			ErrorCount:    session.errorCount.Load(),
+			DLRQueueDepth: len(session.dlrQueue),
+			InstanceID:    instanceID,
+		})
+	}
+
+	return sessions
+}
+
+// ForceUnbind sends an unbind to every session of a system_id and closes their
+// connections. Sessions bound to another instance are unbound by that instance.
+func (s *SMPPServer) ForceUnbind(systemID string) error {
+	err := s.forceUnbindLocal(systemID)
+	if !errors.Is(err, errNoLocalSession) || s.registry == nil {
+		return err
+	}
+
+	if ferr := s.registry.ForwardUnbind(context.Background(), systemID); ferr != nil {
+		if !errors.Is(ferr, cluster.ErrNoSession) {
+			log.WithError(ferr).WithField("system_id", systemID).Warn("Failed to forward unbind")
+		}
+		return err
+	}
+
+	metrics.ClusterForwards.WithLabelValues(cluster.KindUnbind).Inc()
+	log.WithField("system_id", systemID).Info("Unbind forwarded to owning instance")
+	return nil
+}
+
+// forceUnbindLocal unbinds the system_id's sessions held by this instance
+func (s *SMPPServer) forceUnbindLocal(systemID string) error {
+	sessions := s.systemIDSessions(systemID)
+	if len(sessions) == 0 {
+		return fmt.Errorf("%w for system_id %s", errNoLocalSession, systemID)
+	}
+
+	for _, session := range sessions {
+		s.sendUnbind(session)
+		s.removeSession(session)
+		session.Conn.Close()
+
+		log.WithFields(log.Fields{
+			"system_id":   systemID,
+			"session_id":  session.ID,
+			"customer_id": session.CustomerID,
+			"remote_addr": session.RemoteAddr,
+		}).Info("Session force-unbound")
+	}
+
+	return nil
+}
+
+// QueueDLRForCustomer queues a DLR for delivery to customer. If the customer is
+// bound to another instance the DLR is forwarded there; if it is not bound
+// anywhere (or this instance is draining) the DLR is held for its next bind.
 func (s *SMPPServer) QueueDLRForCustomer(customerID string, dlr *models.DeliveryReceipt) error {
+	err := s.queueDLRLocal(customerID, dlr)
+	if !errors.Is(err, errNoLocalSession) {
+		return err
+	}
+
+	ctx := context.Background()
+	if s.registry != nil {
+		ferr := s.registry.ForwardDLR(ctx, customerID, dlr)
+		if ferr == nil {
+			metrics.ClusterForwards.WithLabelValues(cluster.KindDLR).Inc()
+			return nil
+		}
+		if !errors.Is(ferr, cluster.ErrNoSession) {
+			log.WithError(ferr).WithField("customer_id", customerID).Warn("Failed to forward DLR")
+		}
+	}
+
+	// Hold receipts for the customer's next bind instead of dropping them
+	if (s.draining.Load() || s.registry != nil) && s.dlrTracker != nil {
+		return s.dlrTracker.SavePendingDLRs(ctx, customerID, []*models.DeliveryReceipt{dlr})
+	}
+
+	return err
+}
+
+// queueDLRLocal queues a DLR on a receiver-capable session held by this instance
+func (s *SMPPServer) queueDLRLocal(customerID string, dlr *models.DeliveryReceipt) error {
 	s.sessionsMu.RLock()
 	defer s.sessionsMu.RUnlock()
 
@@ -699,6 +1485,7 @@ func (s *SMPPServer) QueueDLRForCustomer(customerID string, dlr *models.Delivery
 		if session.CustomerID == customerID && (session.BindType == "transceiver" || session.BindType == "receiver") {
 			select {
 			case session.dlrQueue <- dlr:
+				metrics.DLRQueueDepth.WithLabelValues(customerID).Inc()
 				return nil
 			default:
 				return fmt.Errorf("DLR queue full for customer %s", customerID)
@@ -706,7 +1493,7 @@ func (s *SMPPServer) QueueDLRForCustomer(customerID string, dlr *models.Delivery
 		}
 	}
 
-	return fmt.Errorf("no receiver-capable session for customer %s", customerID)
+	return fmt.Errorf("%w for customer %s", errNoLocalSession, customerID)
 }
 
 // Shutdown gracefully shuts down the server
@@ -726,14 +1513,16 @@ func (s *SMPPServer) Shutdown(ctx context.Context) error {
 
 	// Close all sessions
 	s.sessionsMu.Lock()
-	for systemID, session := range s.sessions {
-		log.WithField("system_id", systemID).Info("Closing session")
+	for _, session := range s.sessions {
+		log.WithField("system_id", session.SystemID).Info("Closing session")
 		session.cancel()
+		metrics.ActiveBinds.WithLabelValues(session.CustomerID, session.BindType).Dec()
 		if session.Conn != nil {
 			session.Conn.Close()
 		}
 	}
 	s.sessions = make(map[string]*Session)
+	s.bySystemID = make(map[string][]*Session)
 	s.sessionsMu.Unlock()
 
 	// Wait for all goroutines
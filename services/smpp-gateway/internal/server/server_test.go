@@ -0,0 +1,443 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/redis/go-redis/v9"
+	"github.com/ringer-warp/smpp-gateway/internal/auth"
+	"github.com/ringer-warp/smpp-gateway/internal/config"
+	"github.com/ringer-warp/smpp-gateway/internal/connectors"
+	"github.com/ringer-warp/smpp-gateway/internal/dlr"
+	"github.com/ringer-warp/smpp-gateway/internal/models"
+	"github.com/ringer-warp/smpp-gateway/internal/simulator"
+)
+
+const (
+	testSystemID   = "acme"
+	testPassword   = "secret"
+	testCustomerID = "11111111-2222-3333-4444-555555555555"
+)
+
+// stubAuthenticator accepts testSystemID/testPassword only
+type stubAuthenticator struct{}
+
+func (stubAuthenticator) Authenticate(ctx context.Context, systemID, password string, remoteIP net.IP) (*auth.Credential, error) {
+	if systemID != testSystemID {
+		return nil, auth.ErrUnknownSystemID
+	}
+	if password != testPassword {
+		return nil, auth.ErrInvalidPassword
+	}
+	return &auth.Credential{
+		ID:         "cred-1",
+		CustomerID: testCustomerID,
+		SystemID:   systemID,
+		MaxBinds:   2,
+		Throughput: 100,
+	}, nil
+}
+
+// stubRouter routes every message to a single vendor client and every
+// inbound number to the test customer
+type stubRouter struct {
+	client *connectors.SMPPClient
+}
+
+func (r *stubRouter) CustomerForNumber(ctx context.Context, number string) (string, error) {
+	return testCustomerID, nil
+}
+
+func (r *stubRouter) RouteMessage(ctx context.Context, msg *models.Message) (*connectors.SMPPClient, error) {
+	return r.RouteMessageExcluding(ctx, msg, nil)
+}
+
+func (r *stubRouter) RouteMessageExcluding(ctx context.Context, msg *models.Message, exclude map[string]bool) (*connectors.SMPPClient, error) {
+	vendorID := r.client.GetVendor().ID
+	if exclude[vendorID] || !r.client.IsConnected() {
+		return nil, fmt.Errorf("no connected vendors available")
+	}
+	msg.VendorID = vendorID
+	return r.client, nil
+}
+
+// startLoop runs ESME -> SMPPServer -> SMPPClient -> SMSC in-process, with
+// receipts tracked in miniredis, and returns the simulator and the gateway's
+// listen address
+func startLoop(t *testing.T, smscCfg simulator.SMSCConfig) (*simulator.SMSC, string) {
+	t.Helper()
+	smsc, addr, _ := startLoopServer(t, smscCfg)
+	return smsc, addr
+}
+
+// startLoopServer is startLoop that also returns the gateway server
+func startLoopServer(t *testing.T, smscCfg simulator.SMSCConfig) (*simulator.SMSC, string, *SMPPServer) {
+	t.Helper()
+
+	smsc := simulator.NewSMSC(smscCfg)
+	if err := smsc.Start(); err != nil {
+		t.Fatalf("start SMSC: %v", err)
+	}
+	t.Cleanup(func() { smsc.Close() })
+
+	cfg := &config.Config{
+		SMPPHost:               "127.0.0.1",
+		VendorSubmitTimeoutSec: 5,
+		SubmitMaxAttempts:      1,
+		SubmitRetryBudgetMs:    1000,
+		SubmitWorkers:          4,
+		SubmitQueueSize:        16,
+	}
+
+	host, port := smsc.HostPort()
+	client, err := connectors.NewSMPPClient(&models.Vendor{
+		ID:           "vendor-sim",
+		InstanceName: "simulator",
+		Host:         host,
+		Port:         port,
+		Username:     "gateway",
+		Password:     "gwpass",
+		Throughput:   1000,
+	}, cfg)
+	if err != nil {
+		t.Fatalf("create vendor client: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := client.Connect(ctx); err != nil {
+		cancel()
+		t.Fatalf("bind to SMSC: %v", err)
+	}
+
+	srv, err := NewSMPPServer(cfg, nil)
+	if err != nil {
+		cancel()
+		t.Fatalf("create server: %v", err)
+	}
+	redisClient := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { redisClient.Close() })
+	tracker := dlr.NewTracker(redisClient)
+
+	router := &stubRouter{client: client}
+	srv.SetAuthenticator(stubAuthenticator{})
+	srv.SetRouter(router)
+	srv.SetNumberResolver(router)
+	srv.SetDLRTracker(tracker)
+	tracker.SetReceiptHandler(srv)
+	client.SetMOHandler(srv)
+	client.SetDLRHandler(tracker)
+	if err := srv.Start(ctx); err != nil {
+		cancel()
+		t.Fatalf("start server: %v", err)
+	}
+
+	t.Cleanup(func() {
+		cancel()
+		srv.Shutdown(context.Background())
+		client.Disconnect(context.Background())
+	})
+
+	return smsc, srv.listener.Addr().String(), srv
+}
+
+// dialESME binds a test customer and closes it when the test ends
+func dialESME(t *testing.T, addr string) *simulator.ESME {
+	t.Helper()
+
+	esme, err := simulator.DialESME(addr, simulator.BindTransceiver, testSystemID, testPassword)
+	if err != nil {
+		t.Fatalf("bind to gateway: %v", err)
+	}
+	t.Cleanup(func() { esme.Close() })
+	return esme
+}
+
+func TestLoopSubmitReachesVendor(t *testing.T) {
+	smsc, addr := startLoop(t, simulator.SMSCConfig{DisableDLR: true})
+	esme := dialESME(t, addr)
+
+	msgID, err := esme.Submit("15551230000", "15559870000", "hello loop", true)
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	// The customer gets the gateway's message ID, not the vendor's
+	if _, err := uuid.Parse(msgID); err != nil {
+		t.Errorf("message_id = %q, want a gateway UUID", msgID)
+	}
+
+	submits, err := smsc.WaitForSubmits(1, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := submits[0]
+	if got.SystemID != "gateway" || got.SourceAddr != "15551230000" || got.DestAddr != "15559870000" || got.Text != "hello loop" {
+		t.Errorf("vendor received %+v", got)
+	}
+	if got.MessageID == msgID {
+		t.Errorf("vendor message ID %q leaked to the customer", got.MessageID)
+	}
+}
+
+func TestLoopConcurrentSubmits(t *testing.T) {
+	const n = 20
+
+	smsc, addr := startLoop(t, simulator.SMSCConfig{DisableDLR: true, SubmitLatency: 20 * time.Millisecond})
+	esme := dialESME(t, addr)
+
+	var wg sync.WaitGroup
+	ids := make(chan string, n)
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id, err := esme.Submit("15551230000", "15559870000", fmt.Sprintf("msg %d", i), false)
+			if err != nil {
+				errs <- err
+				return
+			}
+			ids <- id
+		}(i)
+	}
+	wg.Wait()
+	close(ids)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("submit: %v", err)
+	}
+	seen := make(map[string]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("duplicate message_id %q", id)
+		}
+		seen[id] = true
+	}
+
+	if _, err := smsc.WaitForSubmits(n, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoopVendorRejection(t *testing.T) {
+	_, addr := startLoop(t, simulator.SMSCConfig{
+		DisableDLR:        true,
+		SubmitErrorRate:   1,
+		SubmitErrorStatus: data.ESME_RINVDSTADR,
+	})
+	esme := dialESME(t, addr)
+
+	_, err := esme.Submit("15551230000", "15559870000", "rejected", false)
+
+	// Permanent vendor rejections are relayed to the customer as-is
+	var statusErr *simulator.StatusError
+	if !errors.As(err, &statusErr) || statusErr.Status != data.ESME_RINVDSTADR {
+		t.Fatalf("submit error = %v, want %s", err, data.ESME_RINVDSTADR)
+	}
+}
+
+func TestLoopBindRejected(t *testing.T) {
+	_, addr := startLoop(t, simulator.SMSCConfig{})
+
+	tests := []struct {
+		name     string
+		systemID string
+		password string
+		want     data.CommandStatusType
+	}{
+		{"unknown system_id", "nobody", testPassword, data.ESME_RINVSYSID},
+		{"wrong password", testSystemID, "wrong", data.ESME_RINVPASWD},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := simulator.DialESME(addr, simulator.BindTransceiver, tt.systemID, tt.password)
+
+			var statusErr *simulator.StatusError
+			if !errors.As(err, &statusErr) || statusErr.Status != tt.want {
+				t.Fatalf("bind error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoopMOReachesCustomer(t *testing.T) {
+	smsc, addr := startLoop(t, simulator.SMSCConfig{})
+	esme := dialESME(t, addr)
+
+	if err := smsc.SendMO("gateway", "15557770000", "15551230000", "STOP"); err != nil {
+		t.Fatalf("send MO: %v", err)
+	}
+
+	delivery, err := esme.WaitForDelivery(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Receipt != nil || delivery.SourceAddr != "15557770000" || delivery.DestAddr != "15551230000" || delivery.Text != "STOP" {
+		t.Errorf("customer received %+v", delivery)
+	}
+}
+
+func TestLoopSecondBindSurvivesFirstClose(t *testing.T) {
+	smsc, addr, srv := startLoopServer(t, simulator.SMSCConfig{DisableDLR: true})
+
+	first := dialESME(t, addr)
+	second := dialESME(t, addr)
+
+	// A third bind exceeds the credential's MaxBinds of 2
+	var statusErr *simulator.StatusError
+	if _, err := simulator.DialESME(addr, simulator.BindTransceiver, testSystemID, testPassword); !errors.As(err, &statusErr) || statusErr.Status != data.ESME_RALYBND {
+		t.Fatalf("third bind error = %v, want %s", err, data.ESME_RALYBND)
+	}
+
+	first.Close()
+
+	// Only the first bind goes away
+	deadline := time.Now().Add(5 * time.Second)
+	for len(srv.ListSessions(testCustomerID, nil)) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("sessions = %d, want 1", len(srv.ListSessions(testCustomerID, nil)))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := srv.GetMetrics()["active_sessions"]; got != 1 {
+		t.Errorf("active_sessions = %d, want 1", got)
+	}
+
+	if _, err := second.Submit("15551230000", "15559870000", "still bound", false); err != nil {
+		t.Fatalf("submit on second bind: %v", err)
+	}
+	if _, err := smsc.WaitForSubmits(1, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := smsc.SendMO("gateway", "15557770000", "15551230000", "HELLO"); err != nil {
+		t.Fatalf("send MO: %v", err)
+	}
+	if _, err := second.WaitForDelivery(5 * time.Second); err != nil {
+		t.Fatalf("MO on second bind: %v", err)
+	}
+}
+
+func TestLoopUnbindFinishesQueuedSubmits(t *testing.T) {
+	smsc, addr, srv := startLoopServer(t, simulator.SMSCConfig{DisableDLR: true, SubmitLatency: 300 * time.Millisecond})
+	esme := dialESME(t, addr)
+
+	go esme.Submit("15551230000", "15559870000", "in flight", false)
+	if _, err := smsc.WaitForSubmits(1, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// The vendor has the message; unbinding must not abandon its submit_sm_resp
+	if err := esme.Unbind(); err != nil {
+		t.Fatalf("unbind: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.inflightSubmits.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("queued submit never finished")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	vendor := srv.router.(*stubRouter).client.GetHealth()
+	if vendor.MessagesSuccess != 1 {
+		t.Errorf("vendor submits succeeded = %d, want 1 (last error %q)", vendor.MessagesSuccess, vendor.LastError)
+	}
+}
+
+func TestLoopVendorReceiptReachesCustomer(t *testing.T) {
+	// Receipts need the vendor message ID indexed by MarkSent first
+	smsc, addr := startLoop(t, simulator.SMSCConfig{DLRDelay: 50 * time.Millisecond})
+	esme := dialESME(t, addr)
+
+	msgID, err := esme.Submit("15551230000", "15559870000", "receipt please", true)
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	submits, err := smsc.WaitForSubmits(1, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delivery, err := esme.WaitForDelivery(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Receipt == nil {
+		t.Fatalf("customer received %+v, want a delivery receipt", delivery)
+	}
+	// The receipt names the gateway's message ID, not the vendor's
+	if delivery.Receipt.ID != msgID || delivery.Receipt.Stat != "DELIVRD" {
+		t.Errorf("receipt = %+v, want id %s stat DELIVRD (vendor ID %s)", delivery.Receipt, msgID, submits[0].MessageID)
+	}
+}
+
+func TestLoopReceiptTracking(t *testing.T) {
+	tests := []struct {
+		name        string
+		failureRate float64
+		registered  bool
+		wantStat    string // Receipt relayed to the customer; empty for none
+		wantStatus  string // Tracked message status after the receipt
+	}{
+		{"delivered", 0, true, "DELIVRD", dlr.StatusDelivered},
+		{"undeliverable", 1, true, "UNDELIV", dlr.StatusFailed},
+		{"receipt not requested", 0, false, "", dlr.StatusDelivered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, addr, srv := startLoopServer(t, simulator.SMSCConfig{
+				DLRDelay:       50 * time.Millisecond,
+				DLRFailureRate: tt.failureRate,
+			})
+			esme := dialESME(t, addr)
+
+			// The gateway always asks the vendor for a receipt but only
+			// relays it if the customer asked for one
+			msgID, err := esme.Submit("15551230000", "15559870000", "tracked", tt.registered)
+			if err != nil {
+				t.Fatalf("submit: %v", err)
+			}
+
+			var msg *models.Message
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				msg, err = srv.dlrTracker.GetMessageStatus(context.Background(), msgID)
+				if err == nil && msg.DLRStatus != "" {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("receipt never tracked: %+v, %v", msg, err)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			if msg.Status != tt.wantStatus || msg.VendorMsgID == "" {
+				t.Errorf("tracked message = status %q vendor_msg_id %q, want status %q", msg.Status, msg.VendorMsgID, tt.wantStatus)
+			}
+
+			if tt.wantStat == "" {
+				if delivery, err := esme.WaitForDelivery(200 * time.Millisecond); err == nil {
+					t.Errorf("customer received %+v, want no receipt", delivery)
+				}
+				return
+			}
+			delivery, err := esme.WaitForDelivery(5 * time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if delivery.Receipt == nil || delivery.Receipt.ID != msgID || delivery.Receipt.Stat != tt.wantStat {
+				t.Errorf("customer received %+v, want receipt for %s with stat %s", delivery.Receipt, msgID, tt.wantStat)
+			}
+		})
+	}
+}
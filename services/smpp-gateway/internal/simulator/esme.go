@@ -0,0 +1,278 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultRequestTimeout bounds how long an ESME waits for a response PDU
+const DefaultRequestTimeout = 10 * time.Second
+
+// ErrClosed is returned for requests on a closed ESME connection
+var ErrClosed = errors.New("esme connection closed")
+
+// StatusError is returned when a response carries a non-zero command_status
+type StatusError struct {
+	Status data.CommandStatusType
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("command_status %s", e.Status)
+}
+
+// Delivery is a deliver_sm received by the ESME (receipt or MO)
+type Delivery struct {
+	SourceAddr string
+	DestAddr   string
+	Text       string
+	EsmClass   byte
+	Receipt    *Receipt // nil for MO messages
+	ReceivedAt time.Time
+	PDU        *pdu.DeliverSM
+}
+
+// ESME is a minimal SMPP client that plays the customer side of a session
+type ESME struct {
+	conn       net.Conn
+	seq        atomic.Int32
+	pending    map[int32]chan pdu.PDU
+	pendingMu  sync.Mutex
+	writeMu    sync.Mutex
+	deliveries chan *Delivery
+	done       chan struct{}
+	closeOnce  sync.Once
+
+	// RequestTimeout bounds each request/response exchange
+	RequestTimeout time.Duration
+}
+
+// DialESME connects and binds to addr
+func DialESME(addr, bindType, systemID, password string) (*ESME, error) {
+	conn, err := net.DialTimeout("tcp", addr, DefaultRequestTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+
+	e := &ESME{
+		conn:           conn,
+		pending:        make(map[int32]chan pdu.PDU),
+		deliveries:     make(chan *Delivery, 1000),
+		done:           make(chan struct{}),
+		RequestTimeout: DefaultRequestTimeout,
+	}
+	go e.readLoop()
+
+	bindingType := pdu.Transceiver
+	switch bindType {
+	case BindTransmitter:
+		bindingType = pdu.Transmitter
+	case BindReceiver:
+		bindingType = pdu.Receiver
+	}
+
+	bind := pdu.NewBindRequest(bindingType)
+	bind.SystemID = systemID
+	bind.Password = password
+
+	if _, err := e.request(bind); err != nil {
+		e.Close()
+		return nil, fmt.Errorf("bind failed: %w", err)
+	}
+
+	return e, nil
+}
+
+// Submit sends a GSM 7-bit submit_sm and returns the message ID
+func (e *ESME) Submit(sourceAddr, destAddr, text string, registeredDelivery bool) (string, error) {
+	submitSM := pdu.NewSubmitSM().(*pdu.SubmitSM)
+	submitSM.SourceAddr.SetTon(1)
+	submitSM.SourceAddr.SetNpi(1)
+	submitSM.SourceAddr.SetAddress(sourceAddr)
+	submitSM.DestAddr.SetTon(1)
+	submitSM.DestAddr.SetNpi(1)
+	submitSM.DestAddr.SetAddress(destAddr)
+	submitSM.Message.SetMessageWithEncoding(text, data.GSM7BIT)
+	if registeredDelivery {
+		submitSM.RegisteredDelivery = 1
+	}
+
+	resp, err := e.SubmitSM(submitSM)
+	if err != nil {
+		return "", err
+	}
+	return resp.MessageID, nil
+}
+
+// SubmitSM sends a caller-built submit_sm (e.g. with TLVs or scheduling fields)
+func (e *ESME) SubmitSM(submitSM *pdu.SubmitSM) (*pdu.SubmitSMResp, error) {
+	resp, err := e.request(submitSM)
+	if err != nil {
+		return nil, err
+	}
+
+	submitResp, ok := resp.(*pdu.SubmitSMResp)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to submit_sm: %s", resp.GetHeader().CommandID)
+	}
+	return submitResp, nil
+}
+
+// EnquireLink sends an enquire_link and waits for the response
+func (e *ESME) EnquireLink() error {
+	_, err := e.request(pdu.NewEnquireLink())
+	return err
+}
+
+// Deliveries returns received deliver_sm PDUs (already acknowledged)
+func (e *ESME) Deliveries() <-chan *Delivery {
+	return e.deliveries
+}
+
+// WaitForDelivery waits for the next deliver_sm
+func (e *ESME) WaitForDelivery(timeout time.Duration) (*Delivery, error) {
+	select {
+	case d := <-e.deliveries:
+		return d, nil
+	case <-e.done:
+		return nil, ErrClosed
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("timed out waiting for deliver_sm")
+	}
+}
+
+// Done is closed when the connection ends (including peer unbind or force-unbind)
+func (e *ESME) Done() <-chan struct{} {
+	return e.done
+}
+
+// Unbind sends unbind, waits for the response and closes the connection
+func (e *ESME) Unbind() error {
+	_, err := e.request(pdu.NewUnbind())
+	e.Close()
+	return err
+}
+
+// Close closes the connection without unbinding
+func (e *ESME) Close() error {
+	var err error
+	e.closeOnce.Do(func() {
+		err = e.conn.Close()
+		close(e.done)
+	})
+	return err
+}
+
+// request writes a PDU and waits for the response with the same sequence number
+func (e *ESME) request(p pdu.PDU) (pdu.PDU, error) {
+	seq := e.seq.Add(1)
+	p.SetSequenceNumber(seq)
+
+	ch := make(chan pdu.PDU, 1)
+	e.pendingMu.Lock()
+	e.pending[seq] = ch
+	e.pendingMu.Unlock()
+
+	defer func() {
+		e.pendingMu.Lock()
+		delete(e.pending, seq)
+		e.pendingMu.Unlock()
+	}()
+
+	if err := e.write(p); err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp := <-ch:
+		if status := resp.GetHeader().CommandStatus; status != data.ESME_ROK {
+			return resp, &StatusError{Status: status}
+		}
+		return resp, nil
+	case <-e.done:
+		return nil, ErrClosed
+	case <-time.After(e.RequestTimeout):
+		return nil, fmt.Errorf("timed out waiting for response to %s", p.GetHeader().CommandID)
+	}
+}
+
+// write serializes a PDU onto the connection
+func (e *ESME) write(p pdu.PDU) error {
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+
+	if _, err := e.conn.Write(marshalPDU(p)); err != nil {
+		return fmt.Errorf("failed to write PDU: %w", err)
+	}
+	return nil
+}
+
+// readLoop dispatches responses to waiting requests and answers peer requests
+func (e *ESME) readLoop() {
+	defer e.Close()
+
+	for {
+		p, err := readPDU(e.conn)
+		if err != nil {
+			return
+		}
+
+		h := p.GetHeader()
+		if uint32(h.CommandID)&0x80000000 != 0 {
+			e.pendingMu.Lock()
+			ch, exists := e.pending[h.SequenceNumber]
+			e.pendingMu.Unlock()
+			if exists {
+				ch <- p
+			}
+			continue
+		}
+
+		switch req := p.(type) {
+		case *pdu.DeliverSM:
+			e.write(req.GetResponse())
+			e.handleDeliverSM(req)
+		case *pdu.EnquireLink:
+			e.write(req.GetResponse())
+		case *pdu.Unbind:
+			e.write(req.GetResponse())
+			return
+		default:
+			log.WithField("command_id", h.CommandID).Debug("ESME ignoring PDU")
+		}
+	}
+}
+
+// handleDeliverSM queues a delivery, parsing receipt text when esm_class marks a receipt
+func (e *ESME) handleDeliverSM(deliverSM *pdu.DeliverSM) {
+	text, _ := deliverSM.Message.GetMessage()
+	d := &Delivery{
+		SourceAddr: deliverSM.SourceAddr.Address(),
+		DestAddr:   deliverSM.DestAddr.Address(),
+		Text:       text,
+		EsmClass:   deliverSM.EsmClass,
+		ReceivedAt: time.Now(),
+		PDU:        deliverSM,
+	}
+
+	if deliverSM.EsmClass&data.SM_SMSC_DLV_RCPT_TYPE != 0 {
+		receipt, err := ParseReceipt(text)
+		if err != nil {
+			log.WithError(err).Warn("ESME received unparseable receipt")
+		}
+		d.Receipt = receipt
+	}
+
+	select {
+	case e.deliveries <- d:
+	default:
+		log.Warn("ESME delivery buffer full, dropping deliver_sm")
+	}
+}
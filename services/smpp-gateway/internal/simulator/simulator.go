@@ -0,0 +1,146 @@
+// Package simulator provides an embeddable SMSC and an ESME test client so the
+// gateway can be exercised in-process without a real carrier.
+//
+// The SMSC stands in for a vendor: point a connectors.SMPPClient at SMSC.Addr()
+// and it will bind, acknowledge submits (with configurable latency and error
+// rate) and send delivery receipts and MO messages back. The ESME stands in for
+// a customer binding to SMPPServer.
+//
+//	smsc := simulator.NewSMSC(simulator.SMSCConfig{DLRDelay: 100 * time.Millisecond})
+//	smsc.Start()
+//	defer smsc.Close()
+//
+//	esme, _ := simulator.DialESME(gatewayAddr, simulator.BindTransceiver, "acme", "secret")
+//	msgID, _ := esme.Submit("15551230000", "15559870000", "hello", true)
+//	receipt, _ := esme.WaitForDelivery(5 * time.Second)
+package simulator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+// Bind types (match server.Session.BindType)
+const (
+	BindTransceiver = "transceiver"
+	BindTransmitter = "transmitter"
+	BindReceiver    = "receiver"
+)
+
+// receiptDateLayout is the YYMMDDhhmm format used in receipt text
+const receiptDateLayout = "0601021504"
+
+// message_state TLV values (SMPP v3.4 section 5.2.28)
+var messageStates = map[string]byte{
+	"DELIVRD": 2,
+	"EXPIRED": 3,
+	"DELETED": 4,
+	"UNDELIV": 5,
+	"ACCEPTD": 6,
+	"UNKNOWN": 7,
+	"REJECTD": 8,
+}
+
+// Receipt holds the fields of a standard delivery receipt
+type Receipt struct {
+	ID         string
+	Submitted  int
+	Delivered  int
+	SubmitDate time.Time
+	DoneDate   time.Time
+	Stat       string
+	Err        string
+	Text       string
+}
+
+// String formats the receipt as deliver_sm short_message text
+func (r *Receipt) String() string {
+	text := r.Text
+	if len(text) > 20 {
+		text = text[:20]
+	}
+	return fmt.Sprintf("id:%s sub:%03d dlvrd:%03d submit date:%s done date:%s stat:%s err:%s text:%s",
+		r.ID, r.Submitted, r.Delivered,
+		r.SubmitDate.UTC().Format(receiptDateLayout), r.DoneDate.UTC().Format(receiptDateLayout),
+		r.Stat, r.Err, text)
+}
+
+// ParseReceipt parses standard delivery receipt text
+// ("id:... sub:... dlvrd:... submit date:... done date:... stat:... err:... text:...")
+func ParseReceipt(s string) (*Receipt, error) {
+	if !strings.HasPrefix(s, "id:") {
+		return nil, fmt.Errorf("not a delivery receipt: %q", s)
+	}
+
+	// "submit date" and "done date" contain spaces; normalize before splitting
+	s = strings.Replace(s, "submit date:", "submit_date:", 1)
+	s = strings.Replace(s, "done date:", "done_date:", 1)
+
+	r := &Receipt{}
+	if i := strings.Index(s, " text:"); i >= 0 {
+		r.Text = s[i+len(" text:"):]
+		s = s[:i]
+	}
+
+	for _, field := range strings.Fields(s) {
+		key, value, ok := strings.Cut(field, ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "id":
+			r.ID = value
+		case "sub":
+			fmt.Sscanf(value, "%d", &r.Submitted)
+		case "dlvrd":
+			fmt.Sscanf(value, "%d", &r.Delivered)
+		case "submit_date":
+			r.SubmitDate, _ = time.Parse(receiptDateLayout, value)
+		case "done_date":
+			r.DoneDate, _ = time.Parse(receiptDateLayout, value)
+		case "stat":
+			r.Stat = value
+		case "err":
+			r.Err = value
+		}
+	}
+
+	if r.ID == "" || r.Stat == "" {
+		return nil, fmt.Errorf("delivery receipt missing id or stat: %q", s)
+	}
+
+	return r, nil
+}
+
+// readPDU reads and parses a single PDU
+func readPDU(r io.Reader) (pdu.PDU, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length < 16 || length > 65536 {
+		return nil, fmt.Errorf("invalid PDU length: %d", length)
+	}
+
+	body := make([]byte, length-16)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	return pdu.Parse(bytes.NewReader(append(header, body...)))
+}
+
+// marshalPDU serializes a PDU
+func marshalPDU(p pdu.PDU) []byte {
+	buf := pdu.NewBuffer(nil)
+	p.Marshal(buf)
+	return buf.Bytes()
+}
@@ -0,0 +1,515 @@
+package simulator
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+	log "github.com/sirupsen/logrus"
+)
+
+// SMSCConfig controls simulator behaviour. The zero value accepts any bind,
+// acknowledges every submit immediately and sends DELIVRD receipts at once.
+type SMSCConfig struct {
+	Addr     string // Listen address (default "127.0.0.1:0")
+	SystemID string // Required system_id; empty accepts any
+	Password string // Required password; empty accepts any
+
+	SubmitLatency     time.Duration          // Delay before each submit_sm_resp
+	SubmitErrorRate   float64                // Fraction (0-1) of submits rejected
+	SubmitErrorStatus data.CommandStatusType // Status for rejected submits (default ESME_RSYSERR)
+
+	DisableDLR       bool          // Never send delivery receipts
+	DLRDelay         time.Duration // Delay between submit and receipt
+	DLRFailureRate   float64       // Fraction (0-1) of receipts reporting failure
+	DLRFailureStatus string        // stat for failed receipts (default UNDELIV)
+
+	MOSchedule []ScheduledMO // MO messages sent to each receiver-capable bind
+
+	Seed int64 // Random seed for error/failure rates (0 = time-based)
+}
+
+// ScheduledMO is a mobile-originated message sent a fixed time after bind
+type ScheduledMO struct {
+	After      time.Duration
+	SourceAddr string
+	DestAddr   string
+	Text       string
+}
+
+// Submit is a submit_sm received by the simulator
+type Submit struct {
+	MessageID          string
+	SystemID           string
+	SourceAddr         string
+	DestAddr           string
+	Text               string
+	RegisteredDelivery byte
+	Status             data.CommandStatusType
+	ReceivedAt         time.Time
+	PDU                *pdu.SubmitSM
+}
+
+// SMSCStats counts simulator activity
+type SMSCStats struct {
+	Binds        int64 `json:"binds"`
+	Submits      int64 `json:"submits"`
+	SubmitErrors int64 `json:"submit_errors"`
+	DLRsSent     int64 `json:"dlrs_sent"`
+	MOsSent      int64 `json:"mos_sent"`
+	DeliverResps int64 `json:"deliver_sm_resps"`
+}
+
+// SMSC is an in-process SMPP server that behaves like a vendor SMSC
+type SMSC struct {
+	cfg      SMSCConfig
+	listener net.Listener
+	sessions map[*smscSession]struct{}
+	submits  []*Submit
+	rng      *rand.Rand
+	nextID   atomic.Uint64
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	closed   chan struct{}
+
+	binds        atomic.Int64
+	submitCount  atomic.Int64
+	submitErrors atomic.Int64
+	dlrsSent     atomic.Int64
+	mosSent      atomic.Int64
+	deliverResps atomic.Int64
+}
+
+// smscSession is one bound (or binding) ESME connection
+type smscSession struct {
+	conn     net.Conn
+	systemID string
+	bindType string
+	seq      atomic.Int32
+	writeMu  sync.Mutex
+	done     chan struct{}
+}
+
+// NewSMSC creates a simulator; call Start to begin listening
+func NewSMSC(cfg SMSCConfig) *SMSC {
+	if cfg.Addr == "" {
+		cfg.Addr = "127.0.0.1:0"
+	}
+	if cfg.SubmitErrorStatus == data.ESME_ROK {
+		cfg.SubmitErrorStatus = data.ESME_RSYSERR
+	}
+	if cfg.DLRFailureStatus == "" {
+		cfg.DLRFailureStatus = "UNDELIV"
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return &SMSC{
+		cfg:      cfg,
+		sessions: make(map[*smscSession]struct{}),
+		rng:      rand.New(rand.NewSource(seed)),
+		closed:   make(chan struct{}),
+	}
+}
+
+// Start begins accepting connections
+func (s *SMSC) Start() error {
+	listener, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.cfg.Addr, err)
+	}
+	s.listener = listener
+
+	s.wg.Add(1)
+	go s.acceptLoop()
+
+	log.WithField("addr", listener.Addr().String()).Info("SMSC simulator listening")
+	return nil
+}
+
+// Addr returns the listen address (host:port)
+func (s *SMSC) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// HostPort returns the listen host and port, e.g. for a models.Vendor
+func (s *SMSC) HostPort() (string, int) {
+	host, port, _ := net.SplitHostPort(s.Addr())
+	p, _ := strconv.Atoi(port)
+	return host, p
+}
+
+// Close stops the simulator and drops all sessions
+func (s *SMSC) Close() error {
+	select {
+	case <-s.closed:
+		return nil
+	default:
+		close(s.closed)
+	}
+
+	err := s.listener.Close()
+
+	s.mu.Lock()
+	for sess := range s.sessions {
+		sess.conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+// SetSubmitErrorRate changes the fraction of rejected submits at runtime
+func (s *SMSC) SetSubmitErrorRate(rate float64) {
+	s.mu.Lock()
+	s.cfg.SubmitErrorRate = rate
+	s.mu.Unlock()
+}
+
+// SetDLRFailureRate changes the fraction of failed receipts at runtime
+func (s *SMSC) SetDLRFailureRate(rate float64) {
+	s.mu.Lock()
+	s.cfg.DLRFailureRate = rate
+	s.mu.Unlock()
+}
+
+// Submits returns all submit_sm PDUs received so far
+func (s *SMSC) Submits() []*Submit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	submits := make([]*Submit, len(s.submits))
+	copy(submits, s.submits)
+	return submits
+}
+
+// WaitForSubmits waits until at least n submits have been received
+func (s *SMSC) WaitForSubmits(n int, timeout time.Duration) ([]*Submit, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if submits := s.Submits(); len(submits) >= n {
+			return submits, nil
+		}
+		if time.Now().After(deadline) {
+			return s.Submits(), fmt.Errorf("timed out waiting for %d submits (got %d)", n, len(s.Submits()))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Stats returns activity counters
+func (s *SMSC) Stats() SMSCStats {
+	return SMSCStats{
+		Binds:        s.binds.Load(),
+		Submits:      s.submitCount.Load(),
+		SubmitErrors: s.submitErrors.Load(),
+		DLRsSent:     s.dlrsSent.Load(),
+		MOsSent:      s.mosSent.Load(),
+		DeliverResps: s.deliverResps.Load(),
+	}
+}
+
+// SendMO sends a mobile-originated message to a receiver-capable session
+// bound as systemID (empty = any session)
+func (s *SMSC) SendMO(systemID, sourceAddr, destAddr, text string) error {
+	s.mu.Lock()
+	var target *smscSession
+	for sess := range s.sessions {
+		if sess.bindType != BindTransmitter && sess.bindType != "" && (systemID == "" || sess.systemID == systemID) {
+			target = sess
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if target == nil {
+		return fmt.Errorf("no receiver-capable session for system_id %q", systemID)
+	}
+
+	return s.sendMO(target, sourceAddr, destAddr, text)
+}
+
+// acceptLoop accepts ESME connections until Close
+func (s *SMSC) acceptLoop() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.closed:
+				return
+			default:
+				log.WithError(err).Warn("SMSC simulator accept error")
+				continue
+			}
+		}
+
+		sess := &smscSession{conn: conn, done: make(chan struct{})}
+		s.mu.Lock()
+		s.sessions[sess] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handleSession(sess)
+	}
+}
+
+// handleSession reads and answers PDUs for one connection
+func (s *SMSC) handleSession(sess *smscSession) {
+	defer s.wg.Done()
+	defer func() {
+		close(sess.done)
+		sess.conn.Close()
+		s.mu.Lock()
+		delete(s.sessions, sess)
+		s.mu.Unlock()
+	}()
+
+	for {
+		p, err := readPDU(sess.conn)
+		if err != nil {
+			return
+		}
+
+		switch req := p.(type) {
+		case *pdu.BindRequest:
+			s.handleBind(sess, req)
+		case *pdu.SubmitSM:
+			s.wg.Add(1)
+			go s.handleSubmit(sess, req)
+		case *pdu.DeliverSMResp:
+			s.deliverResps.Add(1)
+		case *pdu.EnquireLink:
+			sess.write(req.GetResponse())
+		case *pdu.Unbind:
+			sess.write(req.GetResponse())
+			return
+		default:
+			h := p.GetHeader()
+			if uint32(h.CommandID)&0x80000000 != 0 {
+				continue // Ignore other responses
+			}
+			nack := pdu.NewGenericNack().(*pdu.GenericNack)
+			nack.CommandStatus = data.ESME_RINVCMDID
+			nack.SequenceNumber = h.SequenceNumber
+			sess.write(nack)
+		}
+	}
+}
+
+// handleBind authenticates a bind and starts the MO schedule for receivers
+func (s *SMSC) handleBind(sess *smscSession, req *pdu.BindRequest) {
+	resp := pdu.NewBindResp(*req)
+	resp.SystemID = "SMSCSIM"
+
+	s.mu.Lock()
+	bindType := sess.bindType
+	s.mu.Unlock()
+
+	switch {
+	case bindType != "":
+		resp.CommandStatus = data.ESME_RALYBND
+	case s.cfg.SystemID != "" && req.SystemID != s.cfg.SystemID:
+		resp.CommandStatus = data.ESME_RINVSYSID
+	case s.cfg.Password != "" && req.Password != s.cfg.Password:
+		resp.CommandStatus = data.ESME_RINVPASWD
+	}
+
+	if resp.CommandStatus == data.ESME_ROK {
+		s.mu.Lock()
+		sess.systemID = req.SystemID
+		switch req.BindingType {
+		case pdu.Transmitter:
+			sess.bindType = BindTransmitter
+		case pdu.Receiver:
+			sess.bindType = BindReceiver
+		default:
+			sess.bindType = BindTransceiver
+		}
+		bindType = sess.bindType
+		s.mu.Unlock()
+		s.binds.Add(1)
+	}
+
+	if err := sess.write(resp); err != nil || resp.CommandStatus != data.ESME_ROK {
+		return
+	}
+
+	if bindType != BindTransmitter {
+		for _, mo := range s.cfg.MOSchedule {
+			s.wg.Add(1)
+			go func(mo ScheduledMO) {
+				defer s.wg.Done()
+				if !s.sleep(sess, mo.After) {
+					return
+				}
+				if err := s.sendMO(sess, mo.SourceAddr, mo.DestAddr, mo.Text); err != nil {
+					log.WithError(err).Debug("Scheduled MO not sent")
+				}
+			}(mo)
+		}
+	}
+}
+
+// handleSubmit acknowledges a submit_sm and schedules its receipt
+func (s *SMSC) handleSubmit(sess *smscSession, req *pdu.SubmitSM) {
+	defer s.wg.Done()
+
+	text, _ := req.Message.GetMessage()
+	submit := &Submit{
+		SystemID:           sess.systemID,
+		SourceAddr:         req.SourceAddr.Address(),
+		DestAddr:           req.DestAddr.Address(),
+		Text:               text,
+		RegisteredDelivery: req.RegisteredDelivery,
+		ReceivedAt:         time.Now(),
+		PDU:                req,
+	}
+	s.submitCount.Add(1)
+
+	resp := pdu.NewSubmitSMRespFromReq(req).(*pdu.SubmitSMResp)
+
+	s.mu.Lock()
+	bindType := sess.bindType
+	failSubmit := s.rng.Float64() < s.cfg.SubmitErrorRate
+	failDLR := s.rng.Float64() < s.cfg.DLRFailureRate
+	s.mu.Unlock()
+
+	switch {
+	case bindType == "" || bindType == BindReceiver:
+		resp.CommandStatus = data.ESME_RINVBNDSTS
+	case failSubmit:
+		resp.CommandStatus = s.cfg.SubmitErrorStatus
+	default:
+		submit.MessageID = fmt.Sprintf("SIM%013X", s.nextID.Add(1))
+		resp.MessageID = submit.MessageID
+	}
+	submit.Status = resp.CommandStatus
+
+	s.mu.Lock()
+	s.submits = append(s.submits, submit)
+	s.mu.Unlock()
+
+	if !s.sleep(sess, s.cfg.SubmitLatency) {
+		return
+	}
+	if err := sess.write(resp); err != nil {
+		return
+	}
+
+	if resp.CommandStatus != data.ESME_ROK {
+		s.submitErrors.Add(1)
+		return
+	}
+
+	// registered_delivery bits 0-1: 1 = receipt on success or failure, 2 = failure only
+	wanted := req.RegisteredDelivery & 0x03
+	if s.cfg.DisableDLR || wanted == 0 || (wanted == 2 && !failDLR) || bindType == BindTransmitter {
+		return
+	}
+
+	if !s.sleep(sess, s.cfg.DLRDelay) {
+		return
+	}
+
+	stat, errCode := "DELIVRD", "000"
+	if failDLR {
+		stat, errCode = s.cfg.DLRFailureStatus, "001"
+	}
+	if err := s.sendReceipt(sess, submit, stat, errCode); err != nil {
+		log.WithError(err).Debug("Simulator receipt not sent")
+	}
+}
+
+// sendReceipt sends a deliver_sm delivery receipt for a submit
+func (s *SMSC) sendReceipt(sess *smscSession, submit *Submit, stat, errCode string) error {
+	receipt := &Receipt{
+		ID:         submit.MessageID,
+		Submitted:  1,
+		SubmitDate: submit.ReceivedAt,
+		DoneDate:   time.Now(),
+		Stat:       stat,
+		Err:        errCode,
+		Text:       submit.Text,
+	}
+	if stat == "DELIVRD" {
+		receipt.Delivered = 1
+	}
+
+	deliverSM := newDeliverSM(submit.DestAddr, submit.SourceAddr, receipt.String())
+	deliverSM.EsmClass = data.SM_SMSC_DLV_RCPT_TYPE
+	deliverSM.RegisterOptionalParam(pdu.Field{Tag: pdu.TagReceiptedMessageID, Data: append([]byte(submit.MessageID), 0)})
+	deliverSM.RegisterOptionalParam(pdu.Field{Tag: pdu.TagMessageStateOption, Data: []byte{messageStates[stat]}})
+
+	if err := sess.writeRequest(deliverSM); err != nil {
+		return err
+	}
+	s.dlrsSent.Add(1)
+	return nil
+}
+
+// sendMO sends a mobile-originated deliver_sm
+func (s *SMSC) sendMO(sess *smscSession, sourceAddr, destAddr, text string) error {
+	if err := sess.writeRequest(newDeliverSM(sourceAddr, destAddr, text)); err != nil {
+		return err
+	}
+	s.mosSent.Add(1)
+	return nil
+}
+
+// sleep waits for d, returning false if the session or simulator closed first
+func (s *SMSC) sleep(sess *smscSession, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-sess.done:
+		return false
+	case <-s.closed:
+		return false
+	}
+}
+
+// newDeliverSM builds a deliver_sm with international E.164 addresses
+func newDeliverSM(sourceAddr, destAddr, text string) *pdu.DeliverSM {
+	deliverSM := pdu.NewDeliverSM().(*pdu.DeliverSM)
+	deliverSM.SourceAddr.SetTon(1)
+	deliverSM.SourceAddr.SetNpi(1)
+	deliverSM.SourceAddr.SetAddress(sourceAddr)
+	deliverSM.DestAddr.SetTon(1)
+	deliverSM.DestAddr.SetNpi(1)
+	deliverSM.DestAddr.SetAddress(destAddr)
+	deliverSM.Message.SetMessageWithEncoding(text, data.GSM7BIT)
+	return deliverSM
+}
+
+// writeRequest assigns the next session sequence number and writes the PDU
+func (sess *smscSession) writeRequest(p pdu.PDU) error {
+	p.SetSequenceNumber(sess.seq.Add(1))
+	return sess.write(p)
+}
+
+// write serializes a PDU onto the connection
+func (sess *smscSession) write(p pdu.PDU) error {
+	sess.writeMu.Lock()
+	defer sess.writeMu.Unlock()
+
+	_, err := sess.conn.Write(marshalPDU(p))
+	return err
+}
@@ -0,0 +1,655 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/ringer-warp/smpp-gateway/internal/config"
+	"github.com/ringer-warp/smpp-gateway/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// HTTP headers sent with every callback
+	SignatureHeader = "X-Warp-Signature"
+	EventIDHeader   = "X-Warp-Event-Id"
+
+	// Redis keys for callbacks awaiting retry
+	RetriesKey       = "webhook:retries"        // HASH  event ID -> retry JSON
+	RetryDueKey      = "webhook:retry:due"      // ZSET  event ID scored by next attempt (unix ms)
+	RetryInflightKey = "webhook:retry:inflight" // ZSET  event ID scored by lease expiry
+
+	initialBackoff = 2 * time.Second
+	maxBackoff     = 5 * time.Minute
+	queueSize      = 1000
+	configTTL      = time.Minute
+	pollInterval   = time.Second
+	retryBatchSize = 100
+	retryLeaseTTL  = time.Minute
+	flushTimeout   = 5 * time.Second
+)
+
+// claimRetriesScript moves up to ARGV[2] retries due at or before ARGV[1] from
+// KEYS[1] to KEYS[2] with lease expiry ARGV[3], reclaiming expired leases first
+var claimRetriesScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2] - #ids)
+for _, id in ipairs(due) do
+	redis.call('ZREM', KEYS[1], id)
+	table.insert(ids, id)
+end
+for _, id in ipairs(ids) do
+	redis.call('ZADD', KEYS[2], ARGV[3], id)
+end
+return ids
+`)
+
+// Dispatcher delivers signed status callbacks to customer webhooks.
+//
+// Callbacks are sent from an in-memory queue; failed attempts, queue overflow
+// and callbacks still queued at shutdown are stored in Redis and retried by
+// whichever instance claims them, so retries survive restarts.
+type Dispatcher struct {
+	db         *pgxpool.Pool
+	redis      *redis.Client
+	httpClient *http.Client
+	queue      chan *delivery
+	workers    int
+	maxAttempt int
+	wg         sync.WaitGroup
+
+	configMu sync.Mutex
+	configs  map[string]cachedConfig
+}
+
+// cachedConfig is a webhook config (nil if the customer has none) and when it expires
+type cachedConfig struct {
+	cfg     *models.WebhookConfig
+	expires time.Time
+}
+
+// delivery is a single callback awaiting (re)delivery
+type delivery struct {
+	url       string
+	secret    string
+	payload   *models.StatusCallback
+	attempts  int
+	persisted bool // Stored in Redis; removed once delivered or dead-lettered
+}
+
+// retryEntry is the Redis form of a delivery. The secret is not stored; it is
+// read from the customer's config when the retry is claimed.
+type retryEntry struct {
+	URL      string                 `json:"url"`
+	Payload  *models.StatusCallback `json:"payload"`
+	Attempts int                    `json:"attempts"`
+}
+
+// NewDispatcher creates a new webhook dispatcher
+func NewDispatcher(db *pgxpool.Pool, redisClient *redis.Client, cfg *config.Config) *Dispatcher {
+	return &Dispatcher{
+		db:         db,
+		redis:      redisClient,
+		httpClient: newHTTPClient(time.Duration(cfg.WebhookTimeoutSec) * time.Second),
+		queue:      make(chan *delivery, queueSize),
+		workers:    cfg.WebhookWorkers,
+		maxAttempt: cfg.WebhookMaxAttempts,
+		configs:    make(map[string]cachedConfig),
+	}
+}
+
+// Start starts the delivery workers and the retry poller
+func (d *Dispatcher) Start(ctx context.Context) {
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+		go d.worker(ctx)
+	}
+
+	d.wg.Add(1)
+	go d.pollRetries(ctx)
+
+	log.WithField("workers", d.workers).Info("Webhook dispatcher started")
+}
+
+// Wait blocks until all workers have exited, then stores callbacks still
+// queued in Redis so another instance delivers them
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	flushed := 0
+	for {
+		select {
+		case dl := <-d.queue:
+			d.scheduleRetry(ctx, dl, time.Now())
+			flushed++
+		default:
+			if flushed > 0 {
+				log.WithField("callbacks", flushed).Info("Queued status callbacks stored for retry")
+			}
+			return
+		}
+	}
+}
+
+// OnStatusChange implements dlr.StatusListener
+func (d *Dispatcher) OnStatusChange(ctx context.Context, msg *models.Message, status string, errorCode string) {
+	logger := log.WithFields(log.Fields{
+		"msg_id":      msg.ID,
+		"customer_id": msg.CustomerID,
+		"status":      status,
+	})
+
+	cfg, err := d.config(ctx, msg.CustomerID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to load webhook config")
+		return
+	}
+	if cfg == nil || !cfg.IsActive {
+		return // Customer has no status callbacks configured
+	}
+
+	url := cfg.URL
+	if msg.CallbackURL != "" {
+		if _, err := CheckURL(msg.CallbackURL); err != nil {
+			logger.WithError(err).WithField("url", msg.CallbackURL).Warn("Rejected per-message callback URL")
+			return
+		}
+		url = msg.CallbackURL
+	}
+
+	d.enqueue(ctx, &delivery{
+		url:    url,
+		secret: cfg.Secret,
+		payload: &models.StatusCallback{
+			EventID:     uuid.New().String(),
+			MessageID:   msg.ID,
+			CustomerID:  msg.CustomerID,
+			Status:      status,
+			DLRStatus:   msg.DLRStatus,
+			ErrorCode:   errorCode,
+			SourceAddr:  msg.SourceAddr,
+			DestAddr:    msg.DestAddr,
+			VendorID:    msg.VendorID,
+			VendorMsgID: msg.VendorMsgID,
+			Timestamp:   time.Now().UTC(),
+		},
+	})
+}
+
+// enqueue queues a delivery without blocking the caller, spilling to the
+// Redis retry set when the queue is full and dead-lettering if that fails
+func (d *Dispatcher) enqueue(ctx context.Context, dl *delivery) {
+	if err := d.tryEnqueue(ctx, dl); err != nil {
+		log.WithError(err).WithField("msg_id", dl.payload.MessageID).Error("Failed to store webhook retry, dead-lettering")
+		d.deadLetter(ctx, dl, err.Error())
+	}
+}
+
+// tryEnqueue queues a delivery or stores it for retry when the queue is full.
+// Returns an error if the delivery was accepted by neither.
+func (d *Dispatcher) tryEnqueue(ctx context.Context, dl *delivery) error {
+	select {
+	case d.queue <- dl:
+		return nil
+	default:
+		log.WithField("msg_id", dl.payload.MessageID).Warn("Webhook queue full, deferring callback")
+		return d.storeRetry(ctx, dl, time.Now())
+	}
+}
+
+// worker sends queued callbacks until the context is cancelled
+func (d *Dispatcher) worker(ctx context.Context) {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case dl := <-d.queue:
+			d.attempt(ctx, dl)
+		}
+	}
+}
+
+// attempt sends a callback once and schedules a retry with exponential backoff on failure
+func (d *Dispatcher) attempt(ctx context.Context, dl *delivery) {
+	dl.attempts++
+
+	logger := log.WithFields(log.Fields{
+		"msg_id":   dl.payload.MessageID,
+		"event_id": dl.payload.EventID,
+		"status":   dl.payload.Status,
+		"attempt":  dl.attempts,
+	})
+
+	err := d.send(ctx, dl)
+	if err == nil {
+		logger.Debug("Status callback delivered")
+		d.forgetRetry(ctx, dl)
+		return
+	}
+
+	if dl.attempts >= d.maxAttempt {
+		logger.WithError(err).Warn("Status callback retries exhausted")
+		d.deadLetter(context.WithoutCancel(ctx), dl, err.Error())
+		d.forgetRetry(ctx, dl)
+		return
+	}
+
+	backoff := initialBackoff << (dl.attempts - 1)
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	logger.WithError(err).WithField("retry_in", backoff).Info("Status callback failed, will retry")
+	d.scheduleRetry(ctx, dl, time.Now().Add(backoff))
+}
+
+// scheduleRetry stores a delivery in Redis for another attempt at due. If
+// Redis is unavailable the callback is dead-lettered so it can be replayed.
+func (d *Dispatcher) scheduleRetry(ctx context.Context, dl *delivery, due time.Time) {
+	if err := d.storeRetry(ctx, dl, due); err != nil {
+		log.WithError(err).WithField("msg_id", dl.payload.MessageID).Error("Failed to store webhook retry, dead-lettering")
+		d.deadLetter(ctx, dl, err.Error())
+	}
+}
+
+// storeRetry writes a delivery to the Redis retry set, due at the given time
+func (d *Dispatcher) storeRetry(ctx context.Context, dl *delivery, due time.Time) error {
+	ctx = context.WithoutCancel(ctx)
+
+	data, err := json.Marshal(&retryEntry{URL: dl.url, Payload: dl.payload, Attempts: dl.attempts})
+	if err != nil {
+		return fmt.Errorf("failed to marshal retry: %w", err)
+	}
+
+	pipe := d.redis.TxPipeline()
+	pipe.HSet(ctx, RetriesKey, dl.payload.EventID, data)
+	pipe.ZAdd(ctx, RetryDueKey, redis.Z{Score: score(due), Member: dl.payload.EventID})
+	pipe.ZRem(ctx, RetryInflightKey, dl.payload.EventID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store retry: %w", err)
+	}
+	dl.persisted = true
+	return nil
+}
+
+// forgetRetry removes a delivered or dead-lettered callback from Redis
+func (d *Dispatcher) forgetRetry(ctx context.Context, dl *delivery) {
+	if !dl.persisted {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	pipe := d.redis.TxPipeline()
+	pipe.HDel(ctx, RetriesKey, dl.payload.EventID)
+	pipe.ZRem(ctx, RetryDueKey, dl.payload.EventID)
+	pipe.ZRem(ctx, RetryInflightKey, dl.payload.EventID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.WithError(err).WithField("event_id", dl.payload.EventID).Warn("Failed to remove webhook retry")
+	}
+}
+
+// pollRetries claims due retries and queues them until ctx is cancelled
+func (d *Dispatcher) pollRetries(ctx context.Context) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.claimRetries(ctx); err != nil {
+				log.WithError(err).Error("Failed to claim webhook retries")
+			}
+		}
+	}
+}
+
+// claimRetries leases due retries, up to the free queue capacity, and queues
+// them. A lease that expires before the retry is resolved (e.g. the instance
+// died) is claimed again on a later poll.
+func (d *Dispatcher) claimRetries(ctx context.Context) error {
+	free := cap(d.queue) - len(d.queue)
+	if free <= 0 {
+		return nil
+	}
+	if free > retryBatchSize {
+		free = retryBatchSize
+	}
+
+	now := time.Now()
+	ids, err := claimRetriesScript.Run(ctx, d.redis, []string{RetryDueKey, RetryInflightKey},
+		score(now), free, score(now.Add(retryLeaseTTL))).StringSlice()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		dl, err := d.loadRetry(ctx, id)
+		if err != nil {
+			log.WithError(err).WithField("event_id", id).Error("Dropping unreadable webhook retry")
+			d.forgetRetry(ctx, &delivery{payload: &models.StatusCallback{EventID: id}, persisted: true})
+			continue
+		}
+		if dl == nil {
+			continue
+		}
+
+		select {
+		case d.queue <- dl:
+		case <-ctx.Done():
+			return nil // Lease expires and another instance claims it
+		}
+	}
+
+	return nil
+}
+
+// loadRetry reads a claimed retry and resolves its signing secret. It returns
+// nil if the customer's callbacks have since been removed or disabled.
+func (d *Dispatcher) loadRetry(ctx context.Context, eventID string) (*delivery, error) {
+	data, err := d.redis.HGet(ctx, RetriesKey, eventID).Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retry: %w", err)
+	}
+
+	var entry retryEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal retry: %w", err)
+	}
+	if entry.Payload == nil {
+		return nil, fmt.Errorf("retry has no payload")
+	}
+
+	dl := &delivery{url: entry.URL, payload: entry.Payload, attempts: entry.Attempts, persisted: true}
+
+	cfg, err := d.config(ctx, entry.Payload.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil || !cfg.IsActive {
+		log.WithField("msg_id", entry.Payload.MessageID).Info("Webhook disabled, dropping callback retry")
+		d.forgetRetry(ctx, dl)
+		return nil, nil
+	}
+	dl.secret = cfg.Secret
+
+	return dl, nil
+}
+
+// score converts a time to a sorted-set score
+func score(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
+
+// send POSTs the signed payload to the callback URL
+func (d *Dispatcher) send(ctx context.Context, dl *delivery) error {
+	body, err := json.Marshal(dl.payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dl.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIDHeader, dl.payload.EventID)
+	req.Header.Set(SignatureHeader, fmt.Sprintf("t=%s,v1=%s", timestamp, Sign(dl.secret, timestamp, body)))
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign computes the hex HMAC-SHA256 of "timestamp.body" with the customer secret
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deadLetter persists a callback that could not be delivered
+func (d *Dispatcher) deadLetter(ctx context.Context, dl *delivery, lastError string) {
+	payload, err := json.Marshal(dl.payload)
+	if err != nil {
+		log.WithError(err).Error("Failed to marshal dead-letter payload")
+		return
+	}
+
+	query := `
+		INSERT INTO messaging.webhook_dead_letters
+			(customer_id, message_id, url, payload, attempts, last_error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	if _, err := d.db.Exec(ctx, query,
+		dl.payload.CustomerID, dl.payload.MessageID, dl.url, payload, dl.attempts, lastError,
+	); err != nil {
+		log.WithError(err).WithField("msg_id", dl.payload.MessageID).Error("Failed to store dead-letter callback")
+	}
+}
+
+// GetConfig returns a customer's webhook config, or nil if none exists
+func (d *Dispatcher) GetConfig(ctx context.Context, customerID string) (*models.WebhookConfig, error) {
+	query := `
+		SELECT customer_id, url, secret, is_active, created_at, updated_at
+		FROM messaging.customer_webhooks
+		WHERE customer_id = $1
+	`
+
+	cfg := &models.WebhookConfig{}
+	err := d.db.QueryRow(ctx, query, customerID).Scan(
+		&cfg.CustomerID,
+		&cfg.URL,
+		&cfg.Secret,
+		&cfg.IsActive,
+		&cfg.CreatedAt,
+		&cfg.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// config returns a customer's webhook config from the cache, loading it on a
+// miss. Configs saved on another instance take effect within configTTL.
+func (d *Dispatcher) config(ctx context.Context, customerID string) (*models.WebhookConfig, error) {
+	d.configMu.Lock()
+	cached, ok := d.configs[customerID]
+	d.configMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.cfg, nil
+	}
+
+	cfg, err := d.GetConfig(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	d.configMu.Lock()
+	d.configs[customerID] = cachedConfig{cfg: cfg, expires: time.Now().Add(configTTL)}
+	d.configMu.Unlock()
+
+	return cfg, nil
+}
+
+// SaveConfig creates or replaces a customer's webhook config
+func (d *Dispatcher) SaveConfig(ctx context.Context, cfg *models.WebhookConfig) error {
+	query := `
+		INSERT INTO messaging.customer_webhooks (customer_id, url, secret, is_active)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (customer_id) DO UPDATE
+		SET url = EXCLUDED.url,
+		    secret = EXCLUDED.secret,
+		    is_active = EXCLUDED.is_active,
+		    updated_at = NOW()
+	`
+
+	if _, err := d.db.Exec(ctx, query, cfg.CustomerID, cfg.URL, cfg.Secret, cfg.IsActive); err != nil {
+		return fmt.Errorf("failed to save webhook config: %w", err)
+	}
+
+	d.configMu.Lock()
+	delete(d.configs, cfg.CustomerID)
+	d.configMu.Unlock()
+
+	return nil
+}
+
+// ListDeadLetters returns dead-lettered callbacks that have not been replayed
+func (d *Dispatcher) ListDeadLetters(ctx context.Context, customerID string, limit int) ([]*models.WebhookDeadLetter, error) {
+	query := `
+		SELECT id, customer_id, message_id, url, payload, attempts, last_error, created_at, replayed_at
+		FROM messaging.webhook_dead_letters
+		WHERE replayed_at IS NULL
+		  AND ($1 = '' OR customer_id = $1)
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := d.db.Query(ctx, query, customerID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var letters []*models.WebhookDeadLetter
+	for rows.Next() {
+		dl, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, dl)
+	}
+
+	return letters, rows.Err()
+}
+
+// Replay re-queues a dead-lettered callback with a fresh retry budget
+func (d *Dispatcher) Replay(ctx context.Context, id string) error {
+	query := `
+		SELECT id, customer_id, message_id, url, payload, attempts, last_error, created_at, replayed_at
+		FROM messaging.webhook_dead_letters
+		WHERE id = $1
+	`
+
+	letter, err := scanDeadLetter(d.db.QueryRow(ctx, query, id))
+	if err != nil {
+		return err
+	}
+	if letter.ReplayedAt != nil {
+		return fmt.Errorf("dead letter already replayed: %s", id)
+	}
+
+	cfg, err := d.GetConfig(ctx, letter.CustomerID)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		return fmt.Errorf("no webhook config for customer %s", letter.CustomerID)
+	}
+	if !cfg.IsActive {
+		return fmt.Errorf("webhook config for customer %s is inactive", letter.CustomerID)
+	}
+
+	// Claim the letter so concurrent replays cannot both requeue it
+	tag, err := d.db.Exec(ctx,
+		`UPDATE messaging.webhook_dead_letters SET replayed_at = NOW() WHERE id = $1 AND replayed_at IS NULL`, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark dead letter replayed: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("dead letter already replayed: %s", id)
+	}
+
+	err = d.tryEnqueue(ctx, &delivery{
+		url:     letter.URL,
+		secret:  cfg.Secret,
+		payload: letter.Payload,
+	})
+	if err != nil {
+		// Leave the letter replayable
+		if _, uerr := d.db.Exec(context.WithoutCancel(ctx),
+			`UPDATE messaging.webhook_dead_letters SET replayed_at = NULL WHERE id = $1`, id,
+		); uerr != nil {
+			log.WithError(uerr).WithField("dead_letter_id", id).Error("Failed to clear replayed_at after failed replay")
+		}
+		return fmt.Errorf("failed to requeue dead letter: %w", err)
+	}
+
+	log.WithFields(log.Fields{
+		"dead_letter_id": id,
+		"msg_id":         letter.MessageID,
+	}).Info("Dead-letter callback replayed")
+
+	return nil
+}
+
+// scanDeadLetter scans a single dead-letter row
+func scanDeadLetter(row pgx.Row) (*models.WebhookDeadLetter, error) {
+	dl := &models.WebhookDeadLetter{}
+	var payload []byte
+
+	err := row.Scan(
+		&dl.ID,
+		&dl.CustomerID,
+		&dl.MessageID,
+		&dl.URL,
+		&payload,
+		&dl.Attempts,
+		&dl.LastError,
+		&dl.CreatedAt,
+		&dl.ReplayedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("dead letter not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+	}
+
+	if err := json.Unmarshal(payload, &dl.Payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dead-letter payload: %w", err)
+	}
+
+	return dl, nil
+}